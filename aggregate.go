@@ -0,0 +1,149 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+)
+
+var errEmptyAggregate = errors.New("no input to aggregate")
+
+// aggregateFront is the domain separation octet of the weights of aggregate proofs, after the
+// suite string, apart from those of the other hashes.
+const aggregateFront = 0x15
+
+// ProveAggregate proves the outputs of the inputs under the same key with one proof, e.g. for an
+// oracle answering many requests per round: pi is Gamma_1 || ... || Gamma_n || c || s, the Gamma of
+// each input followed by a single DLEQ proof of 48 octets for P256 and secp256k1, instead of n
+// proofs. The DLEQ is proven between the generator and the public key, and the combinations of H
+// and of Gamma with weights hashed from all of them, like the batched DLEQ of RFC9497.
+// The betas are the ones of Prove. v must be a VRF object of this package.
+func ProveAggregate(v VRF, sk *ecdsa.PrivateKey, alphas [][]byte) (betas [][]byte, pi []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, nil, errUnsupportedVRF
+	}
+	if len(alphas) == 0 {
+		return nil, nil, errEmptyAggregate
+	}
+	if err = impl.checkPrivateKey(sk); err != nil {
+		return
+	}
+	core := impl.newCore(sk.Curve)
+	defer core.release()
+	Y := &point{sk.X, sk.Y}
+	if err = core.ValidateKey(Y); err != nil {
+		return
+	}
+	x := core.SecretOctets(sk.D)
+	defer wipe(x)
+	var (
+		hs     = make([]*point, len(alphas))
+		gammas = make([]*point, len(alphas))
+	)
+	for i, alpha := range alphas {
+		if hs[i], err = core.HashToCurve(Y, alpha); err != nil {
+			return
+		}
+		if gammas[i], err = core.BlindScalarMult(hs[i], x); err != nil {
+			return
+		}
+		pi = append(pi, core.Marshal(gammas[i])...)
+		betas = append(betas, core.GammaToHash(gammas[i]))
+	}
+	M, _ := aggregateComposites(core, Y, hs, gammas, false)
+	_, proof, err := dleqProve(core, Y, M, x)
+	if err != nil {
+		return nil, nil, err
+	}
+	return betas, append(pi, proof...), nil
+}
+
+// VerifyAggregate checks the aggregate proof pi of ProveAggregate of the inputs, in the same order,
+// against the public key pk, and returns their outputs. v must be a VRF object of this package.
+func VerifyAggregate(v VRF, pk *ecdsa.PublicKey, alphas [][]byte, pi []byte) (betas [][]byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if len(alphas) == 0 {
+		return nil, errEmptyAggregate
+	}
+	if err = impl.checkPublicKey(pk); err != nil {
+		return
+	}
+	core := impl.newCore(pk.Curve)
+	defer core.release()
+	Y := &point{pk.X, pk.Y}
+	if err = core.ValidateKey(Y); err != nil {
+		return
+	}
+	var (
+		ptlen  = (pk.Curve.Params().BitSize+7)/8 + 1
+		plen   = core.N() + (core.Q().BitLen()+7)/8
+		hs     = make([]*point, len(alphas))
+		gammas = make([]*point, len(alphas))
+	)
+	if len(pi) != len(alphas)*ptlen+plen {
+		return nil, ErrInvalidProofLength
+	}
+	for i, alpha := range alphas {
+		if gammas[i], err = core.Unmarshal(pi[i*ptlen : (i+1)*ptlen]); err != nil {
+			return
+		}
+		if hs[i], err = core.HashToCurve(Y, alpha); err != nil {
+			return
+		}
+	}
+	M, Z := aggregateComposites(core, Y, hs, gammas, true)
+	if err = dleqVerify(core, Y, M, Z, pi[len(alphas)*ptlen:]); err != nil {
+		return nil, err
+	}
+	for _, gamma := range gammas {
+		betas = append(betas, core.GammaToHash(gamma))
+	}
+	return betas, nil
+}
+
+// aggregateComposites returns M = sum(d_i * H_i), and Z = sum(d_i * Gamma_i) if withZ is set, for
+// the weights d_i of 128 bits hashed from the key and all the points, like ComputeComposites of RFC9497.
+func aggregateComposites(core *core, Y *point, hs, gammas []*point, withZ bool) (M, Z *point) {
+	hasher := core.getCachedHasher()
+	hasher.Reset()
+	hasher.Write([]byte{core.SuiteString, aggregateFront})
+	hasher.Write(core.Marshal(Y))
+	for i := range hs {
+		hasher.Write(core.Marshal(hs[i]))
+		hasher.Write(core.Marshal(gammas[i]))
+	}
+	seed := hasher.Sum(nil)
+
+	var ctr [4]byte
+	for i := range hs {
+		binary.BigEndian.PutUint32(ctr[:], uint32(i))
+		hasher.Reset()
+		hasher.Write([]byte{core.SuiteString, aggregateFront})
+		hasher.Write(seed)
+		hasher.Write(ctr[:])
+		d := bits2int(hasher.Sum(nil), core.N()*8)
+
+		dH := core.ScalarMultVartime(hs[i], d)
+		if M == nil {
+			M = dH
+		} else {
+			M = core.Add(M, dH)
+		}
+		if withZ {
+			dG := core.ScalarMultVartime(gammas[i], d)
+			if Z == nil {
+				Z = dG
+			} else {
+				Z = core.Add(Z, dG)
+			}
+		}
+	}
+	return M, Z
+}
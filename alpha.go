@@ -0,0 +1,40 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import "encoding/binary"
+
+// alphaTag starts the inputs of ComposeAlpha, apart from inputs composed otherwise.
+const alphaTag = "go-ecvrf/alpha/v1\x00"
+
+// ComposeAlpha encodes the input of a round of a protocol, e.g. an epoch of a beacon or of a
+// leader election, without the ambiguity of concatenated strings, where "ab" || "1" || "23" is
+// "ab1" || "23": the domain, the previous beta, and each extra field are prefixed by their
+// length in 4 big-endian octets, and the round is in 8 big-endian octets. The domain should name
+// the protocol and its purpose, e.g. "example.org/chain/leader", so inputs of distinct protocols
+// never collide.
+func ComposeAlpha(domain string, round uint64, prevBeta []byte, extra ...[]byte) []byte {
+	n := len(alphaTag) + 4 + len(domain) + 8 + 4 + len(prevBeta)
+	for _, e := range extra {
+		n += 4 + len(e)
+	}
+	alpha := make([]byte, 0, n)
+	alpha = append(alpha, alphaTag...)
+	alpha = appendField(alpha, []byte(domain))
+	var r [8]byte
+	binary.BigEndian.PutUint64(r[:], round)
+	alpha = append(alpha, r[:]...)
+	alpha = appendField(alpha, prevBeta)
+	for _, e := range extra {
+		alpha = appendField(alpha, e)
+	}
+	return alpha
+}
+
+// appendField appends the length of field in 4 big-endian octets, then field.
+func appendField(dst, field []byte) []byte {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(field)))
+	return append(append(dst, n[:]...), field...)
+}
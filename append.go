@@ -0,0 +1,61 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"time"
+)
+
+// AppendProve is like VRF.Prove, but appends beta to betaDst and pi to piDst and returns the
+// extended buffers, like the Append functions of the standard library, so hot paths can reuse
+// their buffers. On errors, betaDst and piDst are returned unchanged. VRF objects of other
+// packages are called by Prove, whose outputs are then copied.
+func AppendProve(betaDst, piDst []byte, v VRF, sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		b, p, err := v.Prove(sk, alpha)
+		if err != nil {
+			return betaDst, piDst, err
+		}
+		return append(betaDst, b...), append(piDst, p...), nil
+	}
+	if impl.cfg.Metrics != nil {
+		start := time.Now()
+		defer func() { impl.observeProve(start, err) }()
+	}
+	if err = impl.checkPrivateKey(sk); err != nil {
+		return betaDst, piDst, err
+	}
+	core := impl.newCore(sk.Curve)
+	defer core.release()
+	pk := &point{sk.X, sk.Y}
+	if err = core.ValidateKey(pk); err != nil {
+		return betaDst, piDst, err
+	}
+	if beta, pi, err = impl.appendProve(core, betaDst, piDst, sk, pk, alpha); err != nil {
+		return betaDst, piDst, err
+	}
+	return beta, pi, nil
+}
+
+// AppendProofToHash is like ProofToHash, but appends beta to dst and returns the extended buffer.
+// On errors, dst is returned unchanged.
+func AppendProofToHash(dst []byte, v VRF, c elliptic.Curve, pi []byte) ([]byte, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return dst, errUnsupportedVRF
+	}
+	if err := impl.checkCurve(c); err != nil {
+		return dst, err
+	}
+	core := impl.newCore(c)
+	defer core.release()
+	gamma, _, _, err := core.DecodeProof(pi)
+	if err != nil {
+		return dst, err
+	}
+	return core.AppendGammaToHash(dst, gamma), nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+)
+
+var errInvalidPoint = errors.New("invalid point")
+
+// Challenge returns the challenge c of the proof of alpha by pk, with Gamma = x*H and the nonce
+// commitments U = k*B and V = k*H, for protocols computing the response s = k + c*x mod q from
+// shares of x and k, such as threshold proofs. Points are affine coordinates, as in EVMProof.
+// v must be a VRF object of this package.
+func Challenge(v VRF, pk *ecdsa.PublicKey, alpha []byte, gamma, u, w [2]*big.Int) (*big.Int, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if err := impl.checkPublicKey(pk); err != nil {
+		return nil, err
+	}
+	for _, pt := range [][2]*big.Int{gamma, u, w} {
+		if pt[0] == nil || pt[1] == nil || !pk.Curve.IsOnCurve(pt[0], pt[1]) {
+			return nil, errInvalidPoint
+		}
+	}
+	core := impl.newCore(pk.Curve)
+	defer core.release()
+	Y := &point{pk.X, pk.Y}
+	H, err := core.HashToCurve(Y, alpha)
+	if err != nil {
+		return nil, err
+	}
+	return core.Challenge(Y, H, &point{gamma[0], gamma[1]}, &point{u[0], u[1]}, &point{w[0], w[1]}), nil
+}
+
+// AssembleProof encodes the proof of alpha by pk of Gamma, the challenge c and the response s,
+// and verifies it, so a faulty share can't give a wrong output. v must be a VRF object of this package.
+func AssembleProof(v VRF, pk *ecdsa.PublicKey, alpha []byte, gamma [2]*big.Int, c, s *big.Int) (beta, pi []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, nil, errUnsupportedVRF
+	}
+	if err = impl.checkPublicKey(pk); err != nil {
+		return
+	}
+	if gamma[0] == nil || gamma[1] == nil || !pk.Curve.IsOnCurve(gamma[0], gamma[1]) {
+		return nil, nil, errInvalidPoint
+	}
+	core := impl.newCore(pk.Curve)
+	defer core.release()
+	if c.Sign() < 0 || c.BitLen() > core.N()*8 || s.Sign() < 0 || s.Cmp(core.Q()) >= 0 {
+		return nil, nil, ErrInvalidProof
+	}
+	pi = core.EncodeProof(&point{gamma[0], gamma[1]}, c, s)
+	if beta, err = impl.Verify(pk, alpha, pi); err != nil {
+		return nil, nil, err
+	}
+	return beta, pi, nil
+}
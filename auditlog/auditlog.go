@@ -0,0 +1,260 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package auditlog records the proofs a prover emits in an append-only, hash-chained log, so an
+// operator can later show that a key never proved two conflicting inputs for the same slot. Each
+// entry holds the time, the key, an optional slot label, and the SHA-256 digests of alpha and pi,
+// never the inputs themselves, and the hash of the previous entry: an entry can't be altered,
+// dropped or reordered without breaking the hashes of all the entries after it.
+//
+// The log is a stream of JSON lines, which is also its export format. Verify checks a log and
+// reports the conflicting slots; `ecvrf audit` runs it from the command line. Publishing the head
+// hash from time to time, e.g. on chain, keeps the operator from rewriting the whole log.
+package auditlog
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+const hashTag = "go-ecvrf/auditlog/v1\x00"
+
+var errBadEntry = errors.New("auditlog: malformed entry")
+
+// ChainError is the error of Verify for a log whose entry of the sequence number breaks the chain.
+type ChainError struct {
+	Seq    uint64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("auditlog: chain broken at entry %d: %s", e.Seq, e.Reason)
+}
+
+// Entry is an entry of the log. The digests and hashes are hex encoded.
+type Entry struct {
+	// Seq is the sequence number of the entry, from 1.
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	// Key is the ecvrf.PublicKeyID of the key proving.
+	Key string `json:"key"`
+	// Slot labels the position proven, e.g. "epoch 12/slot 7". Two entries of a key with the same
+	// slot and different inputs conflict. Entries without a slot never conflict.
+	Slot        string `json:"slot,omitempty"`
+	AlphaSHA256 string `json:"alpha_sha256"`
+	PiSHA256    string `json:"pi_sha256"`
+	// Prev is the hash of the previous entry, zeros for the first one.
+	Prev string `json:"prev"`
+	Hash string `json:"hash"`
+}
+
+// hash computes the hash of the entry: SHA-256 over a tag, Prev, Seq, the time in Unix nanoseconds,
+// the length-prefixed Key and Slot, and the digests.
+func (e *Entry) hash() ([sha256.Size]byte, error) {
+	var out [sha256.Size]byte
+	prev, err1 := hex.DecodeString(e.Prev)
+	alpha, err2 := hex.DecodeString(e.AlphaSHA256)
+	pi, err3 := hex.DecodeString(e.PiSHA256)
+	if err1 != nil || err2 != nil || err3 != nil || len(prev) != sha256.Size || len(alpha) != sha256.Size || len(pi) != sha256.Size {
+		return out, errBadEntry
+	}
+	h := sha256.New()
+	var b [8]byte
+	h.Write([]byte(hashTag))
+	h.Write(prev)
+	binary.BigEndian.PutUint64(b[:], e.Seq)
+	h.Write(b[:])
+	binary.BigEndian.PutUint64(b[:], uint64(e.Time.UnixNano()))
+	h.Write(b[:])
+	for _, s := range []string{e.Key, e.Slot} {
+		binary.BigEndian.PutUint64(b[:], uint64(len(s)))
+		h.Write(b[:])
+		h.Write([]byte(s))
+	}
+	h.Write(alpha)
+	h.Write(pi)
+	h.Sum(out[:0])
+	return out, nil
+}
+
+// Log appends entries to a writer, e.g. a file opened with os.O_APPEND. It's safe for concurrent use.
+type Log struct {
+	now func() time.Time
+
+	mu   sync.Mutex
+	w    io.Writer
+	seq  uint64
+	prev [sha256.Size]byte
+	err  error
+}
+
+// Option configures a Log.
+type Option func(*Log)
+
+// WithClock sets the clock of the entries, time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(l *Log) {
+		l.now = now
+	}
+}
+
+// New starts a log, written to w.
+func New(w io.Writer, opts ...Option) *Log {
+	l := &Log{w: w, now: time.Now}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Resume verifies the log read from r, and continues it on w, e.g. r and w being the same file
+// reopened after a restart.
+func Resume(w io.Writer, r io.Reader, opts ...Option) (*Log, error) {
+	report, err := Verify(r)
+	if err != nil {
+		return nil, err
+	}
+	l := New(w, opts...)
+	l.seq, l.prev = report.Entries, report.Head
+	return l, nil
+}
+
+// Record appends the entry of a proof pi of alpha by pk, and returns it. A failed write fails the
+// log: the entry and all the next ones are refused, as the chain can't be trusted to continue.
+func (l *Log) Record(pk *ecdsa.PublicKey, slot string, alpha, pi []byte) (*Entry, error) {
+	alphaDigest, piDigest := sha256.Sum256(alpha), sha256.Sum256(pi)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return nil, l.err
+	}
+	e := &Entry{
+		Seq:         l.seq + 1,
+		Time:        l.now().UTC(),
+		Key:         ecvrf.PublicKeyID(pk),
+		Slot:        slot,
+		AlphaSHA256: hex.EncodeToString(alphaDigest[:]),
+		PiSHA256:    hex.EncodeToString(piDigest[:]),
+		Prev:        hex.EncodeToString(l.prev[:]),
+	}
+	h, err := e.hash()
+	if err != nil {
+		return nil, err
+	}
+	e.Hash = hex.EncodeToString(h[:])
+	line, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		l.err = err
+		return nil, err
+	}
+	l.seq, l.prev = e.Seq, h
+	return e, nil
+}
+
+// Head returns the number of entries and the hash of the last one, zeros for an empty log, e.g.
+// to publish it.
+func (l *Log) Head() (entries uint64, hash [sha256.Size]byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq, l.prev
+}
+
+// Wrap returns v with each of its proofs recorded in the log before it's returned, slot giving
+// the slot of an alpha, or nil for entries without slots. A proof which can't be recorded isn't
+// returned.
+func (l *Log) Wrap(v ecvrf.VRF, slot func(alpha []byte) string) ecvrf.VRF {
+	return &recordingVRF{v, l, slot}
+}
+
+type recordingVRF struct {
+	ecvrf.VRF
+	log  *Log
+	slot func(alpha []byte) string
+}
+
+func (v *recordingVRF) Prove(sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
+	if beta, pi, err = v.VRF.Prove(sk, alpha); err != nil {
+		return
+	}
+	var slot string
+	if v.slot != nil {
+		slot = v.slot(alpha)
+	}
+	if _, err := v.log.Record(&sk.PublicKey, slot, alpha, pi); err != nil {
+		return nil, nil, err
+	}
+	return
+}
+
+// Conflict is a pair of entries of a key for the same slot with different inputs.
+type Conflict struct {
+	First, Second *Entry
+}
+
+// Report is the result of Verify.
+type Report struct {
+	Entries uint64
+	// Head is the hash of the last entry, zeros for an empty log.
+	Head [sha256.Size]byte
+	// Conflicts are the double proofs of slots, in the order of the second entries.
+	Conflicts []Conflict
+}
+
+// Verify checks the chain of the log read from r, and reports its conflicting slots. emit, if
+// not nil, is called with each entry checked, e.g. to export it.
+func Verify(r io.Reader, emit ...func(*Entry)) (*Report, error) {
+	var (
+		report  = &Report{}
+		scanner = bufio.NewScanner(r)
+		slots   = make(map[[2]string]*Entry)
+	)
+	scanner.Buffer(make([]byte, 4096), 1<<20)
+	for scanner.Scan() {
+		seq := report.Entries + 1
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, &ChainError{seq, "malformed entry"}
+		}
+		switch h, err := e.hash(); {
+		case err != nil:
+			return nil, &ChainError{seq, "malformed entry"}
+		case e.Seq != seq:
+			return nil, &ChainError{seq, fmt.Sprintf("sequence number %d", e.Seq)}
+		case e.Prev != hex.EncodeToString(report.Head[:]):
+			return nil, &ChainError{seq, "previous hash mismatch"}
+		case e.Hash != hex.EncodeToString(h[:]):
+			return nil, &ChainError{seq, "hash mismatch"}
+		default:
+			report.Entries, report.Head = seq, h
+		}
+		if e.Slot != "" {
+			k := [2]string{e.Key, e.Slot}
+			if first, ok := slots[k]; !ok {
+				slots[k] = &e
+			} else if first.AlphaSHA256 != e.AlphaSHA256 {
+				report.Conflicts = append(report.Conflicts, Conflict{first, &e})
+			}
+		}
+		for _, f := range emit {
+			f(&e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package awskms is an envelope-encrypted keystore of VRF keys under an AWS KMS key: the scalar is
+// sealed by AES-256-GCM under a data key of GenerateDataKey, whose encrypted blob is stored along,
+// so provers on EC2 keep no plaintext key on disk. KMS only protects the key at rest: Open decrypts
+// the data key by Decrypt, and the scalar is then in the memory of the prover, which proves with it.
+//
+// Asymmetric KMS keys aren't used: DeriveSharedSecret of KEY_AGREEMENT keys gives Gamma = x*H,
+// but KMS has no operation answering the challenge with s = k + c*x, so a proof can't be assembled
+// without the scalar. See ecvrf.RemoteKey for devices which can.
+package awskms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// ContextKey is the key of the encryption context set by Seal and Open, whose value is the curve name.
+// Key policies may require it with the kms:EncryptionContext condition.
+const ContextKey = "go-ecvrf:curve"
+
+var (
+	errInvalidKey    = errors.New("awskms: invalid private key")
+	errInvalidSealed = errors.New("awskms: invalid sealed key")
+	errCurveMismatch = errors.New("awskms: sealed key of another curve")
+	errReservedKey   = errors.New("awskms: encryption context uses " + ContextKey)
+)
+
+// API is the part of *kms.Client used by Seal and Open.
+type API interface {
+	GenerateDataKey(ctx context.Context, in *kms.GenerateDataKeyInput, opts ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+	Decrypt(ctx context.Context, in *kms.DecryptInput, opts ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// SealedKey is a VRF private key sealed under a KMS key, to be stored, e.g. as JSON.
+type SealedKey struct {
+	// KeyID is the ARN of the KMS key, as returned by GenerateDataKey.
+	KeyID string `json:"key_id"`
+	// Curve is the name of the curve of the key.
+	Curve string `json:"curve"`
+	// DataKey is the data key encrypted by KMS.
+	DataKey []byte `json:"data_key"`
+	// Nonce and Ciphertext are the AES-256-GCM encryption of the scalar under the data key.
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Seal encrypts the scalar of sk under a new data key of the KMS key keyID, with the encryption
+// context encCtx, which must be given to Open as well.
+func Seal(ctx context.Context, api API, keyID string, sk *ecdsa.PrivateKey, encCtx map[string]string) (*SealedKey, error) {
+	if sk == nil || sk.D == nil || sk.D.Sign() <= 0 || sk.D.Cmp(sk.Curve.Params().N) >= 0 {
+		return nil, errInvalidKey
+	}
+	curve := sk.Curve.Params().Name
+	kctx, err := encryptionContext(encCtx, curve)
+	if err != nil {
+		return nil, err
+	}
+	out, err := api.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:             aws.String(keyID),
+		KeySpec:           types.DataKeySpecAes256,
+		EncryptionContext: kctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(out.Plaintext)
+	aead, err := newAEAD(out.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	sealed := &SealedKey{
+		KeyID:   aws.ToString(out.KeyId),
+		Curve:   curve,
+		DataKey: out.CiphertextBlob,
+		Nonce:   make([]byte, aead.NonceSize()),
+	}
+	if _, err := io.ReadFull(rand.Reader, sealed.Nonce); err != nil {
+		return nil, err
+	}
+	scalar := sk.D.FillBytes(make([]byte, scalarLen(sk.Curve)))
+	defer wipe(scalar)
+	sealed.Ciphertext = aead.Seal(nil, sealed.Nonce, scalar, []byte(curve))
+	return sealed, nil
+}
+
+// Key is a private key opened by Open, in the memory of the process until Close.
+type Key struct {
+	*ecdsa.PrivateKey
+}
+
+// Close zeroes the scalar of the key and drops it, e.g. when the prover stops. KMS isn't called:
+// the sealed key can be opened again.
+func (k *Key) Close() error {
+	if k.PrivateKey != nil {
+		wipeInt(k.D)
+		k.PrivateKey = nil
+	}
+	return nil
+}
+
+// Open decrypts the data key of the sealed key by KMS, and decrypts by it the private key of the curve.
+func Open(ctx context.Context, api API, sealed *SealedKey, curve elliptic.Curve, encCtx map[string]string) (*Key, error) {
+	if sealed.Curve != curve.Params().Name {
+		return nil, errCurveMismatch
+	}
+	kctx, err := encryptionContext(encCtx, sealed.Curve)
+	if err != nil {
+		return nil, err
+	}
+	out, err := api.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob:    sealed.DataKey,
+		KeyId:             aws.String(sealed.KeyID),
+		EncryptionContext: kctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(out.Plaintext)
+	aead, err := newAEAD(out.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return nil, errInvalidSealed
+	}
+	scalar, err := aead.Open(nil, sealed.Nonce, sealed.Ciphertext, []byte(sealed.Curve))
+	if err != nil {
+		return nil, errInvalidSealed
+	}
+	defer wipe(scalar)
+	d := new(big.Int).SetBytes(scalar)
+	if len(scalar) != scalarLen(curve) || d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, errInvalidSealed
+	}
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = curve
+	sk.X, sk.Y = curve.ScalarBaseMult(scalar)
+	return &Key{sk}, nil
+}
+
+func encryptionContext(encCtx map[string]string, curve string) (map[string]string, error) {
+	out := map[string]string{ContextKey: curve}
+	for k, v := range encCtx {
+		if k == ContextKey {
+			return nil, errReservedKey
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func scalarLen(c elliptic.Curve) int {
+	return (c.Params().N.BitLen() + 7) / 8
+}
+
+// wipeInt zeroes the words of the secret k.
+func wipeInt(k *big.Int) {
+	words := k.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	k.SetInt64(0)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
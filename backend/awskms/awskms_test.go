@@ -0,0 +1,93 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package awskms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/vechain/go-ecvrf"
+)
+
+// fakeKMS keeps the data keys it generated, and decrypts them for the same encryption context only.
+type fakeKMS struct {
+	keys map[string][]byte
+}
+
+func (f *fakeKMS) GenerateDataKey(_ context.Context, in *kms.GenerateDataKeyInput, _ ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	blob := fmt.Sprintf("%d|%v", len(f.keys), in.EncryptionContext)
+	f.keys[blob] = append([]byte(nil), key...)
+	return &kms.GenerateDataKeyOutput{
+		KeyId:          aws.String("arn:aws:kms:eu-west-1:111122223333:key/" + aws.ToString(in.KeyId)),
+		Plaintext:      key,
+		CiphertextBlob: []byte(blob),
+	}, nil
+}
+
+func (f *fakeKMS) Decrypt(_ context.Context, in *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	var n int
+	fmt.Sscanf(string(in.CiphertextBlob), "%d|", &n)
+	key, ok := f.keys[fmt.Sprintf("%d|%v", n, in.EncryptionContext)]
+	if !ok {
+		return nil, errors.New("InvalidCiphertextException")
+	}
+	return &kms.DecryptOutput{Plaintext: append([]byte(nil), key...)}, nil
+}
+
+func TestSealOpen(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		api    = &fakeKMS{keys: map[string][]byte{}}
+		sk, _  = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		encCtx = map[string]string{"validator": "v1"}
+	)
+	sealed, err := Seal(ctx, api, "vrf", sk, encCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := Open(ctx, api, sealed, elliptic.P256(), encCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.D.Cmp(sk.D) != 0 || opened.X.Cmp(sk.X) != 0 || opened.Y.Cmp(sk.Y) != 0 {
+		t.Fatal("opened key differs")
+	}
+	_, pi, err := ecvrf.NewP256Sha256Tai().Prove(opened.PrivateKey, []byte("alpha"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ecvrf.NewP256Sha256Tai().Verify(&sk.PublicKey, []byte("alpha"), pi); err != nil {
+		t.Fatal(err)
+	}
+	d := opened.D
+	if err := opened.Close(); err != nil || opened.PrivateKey != nil || d.Sign() != 0 || sk.D.Sign() == 0 {
+		t.Fatal("closed key not wiped")
+	}
+	if err := opened.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(ctx, api, sealed, elliptic.P256(), map[string]string{"validator": "v2"}); err == nil {
+		t.Fatal("opened with another encryption context")
+	}
+	if _, err := Open(ctx, api, sealed, elliptic.P384(), encCtx); err != errCurveMismatch {
+		t.Fatal("opened for another curve")
+	}
+	sealed.Ciphertext[0] ^= 1
+	if _, err := Open(ctx, api, sealed, elliptic.P256(), encCtx); err != errInvalidSealed {
+		t.Fatal("tampered key opened")
+	}
+	if _, err := Seal(ctx, api, "vrf", sk, map[string]string{ContextKey: "x"}); err != errReservedKey {
+		t.Fatal("reserved context key accepted")
+	}
+}
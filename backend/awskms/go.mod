@@ -0,0 +1,17 @@
+module github.com/vechain/go-ecvrf/backend/awskms
+
+go 1.21
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.30.0
+	github.com/aws/aws-sdk-go-v2/service/kms v1.35.0
+	github.com/vechain/go-ecvrf v0.0.0-20200305101714-4252ed3a3b96
+)
+
+require (
+	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.12 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.6.12 // indirect
+	github.com/aws/smithy-go v1.20.2 // indirect
+)
+
+replace github.com/vechain/go-ecvrf => ../../
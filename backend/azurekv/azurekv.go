@@ -0,0 +1,187 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package azurekv is an envelope-encrypted keystore of VRF keys under an Azure Key Vault or Managed
+// HSM key, for parity with awskms and gcpkms: the scalar is sealed by AES-256-GCM under a random data
+// key, which is wrapped by the vault key (RSA-OAEP-256 of RSA keys, or A256KW of the oct-HSM keys of
+// Managed HSM). Open unwraps the data key by UnwrapKey and decrypts the scalar into the memory of the
+// prover, so the vault protects the key at rest only.
+//
+// Proofs can't be computed in the vault instead: its EC keys only sign and verify, with neither ECDH
+// nor a response to a challenge, so they can't back an ecvrf.RemoteKey.
+package azurekv
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+)
+
+var (
+	errInvalidKey    = errors.New("azurekv: invalid private key")
+	errInvalidSealed = errors.New("azurekv: invalid sealed key")
+	errCurveMismatch = errors.New("azurekv: sealed key of another curve")
+)
+
+// API is the part of *azkeys.Client used by Seal and Open.
+type API interface {
+	WrapKey(ctx context.Context, name, version string, parameters azkeys.KeyOperationParameters, options *azkeys.WrapKeyOptions) (azkeys.WrapKeyResponse, error)
+	UnwrapKey(ctx context.Context, name, version string, parameters azkeys.KeyOperationParameters, options *azkeys.UnwrapKeyOptions) (azkeys.UnwrapKeyResponse, error)
+}
+
+// NewManagedIdentityClient creates the client of the vault, authenticated by the managed identity
+// of the VM, AKS pod or App Service: the system-assigned one if clientID is empty, else the
+// user-assigned identity of clientID.
+func NewManagedIdentityClient(vaultURL, clientID string) (*azkeys.Client, error) {
+	opts := &azidentity.ManagedIdentityCredentialOptions{}
+	if clientID != "" {
+		opts.ID = azidentity.ClientID(clientID)
+	}
+	cred, err := azidentity.NewManagedIdentityCredential(opts)
+	if err != nil {
+		return nil, err
+	}
+	return azkeys.NewClient(vaultURL, cred, nil)
+}
+
+// SealedKey is a VRF private key sealed under a vault key, to be stored, e.g. as JSON.
+type SealedKey struct {
+	// KeyID is the kid of the version of the vault key which wrapped the data key.
+	KeyID     string                     `json:"kid"`
+	Algorithm azkeys.EncryptionAlgorithm `json:"alg"`
+	// Curve is the name of the curve of the key, which is authenticated as well.
+	Curve string `json:"curve"`
+	// DataKey is the wrapped data key.
+	DataKey []byte `json:"data_key"`
+	// Nonce and Ciphertext are the AES-256-GCM encryption of the scalar under the data key.
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Seal encrypts the scalar of sk under a new data key, wrapped by the latest version of the vault key
+// keyName with alg, RSA-OAEP-256 if empty.
+func Seal(ctx context.Context, api API, keyName string, alg azkeys.EncryptionAlgorithm, sk *ecdsa.PrivateKey) (*SealedKey, error) {
+	if sk == nil || sk.D == nil || sk.D.Sign() <= 0 || sk.D.Cmp(sk.Curve.Params().N) >= 0 {
+		return nil, errInvalidKey
+	}
+	if alg == "" {
+		alg = azkeys.EncryptionAlgorithmRSAOAEP256
+	}
+	dataKey := make([]byte, 32)
+	defer wipe(dataKey)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	resp, err := api.WrapKey(ctx, keyName, "", azkeys.KeyOperationParameters{Algorithm: &alg, Value: dataKey}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.KID == nil {
+		return nil, errInvalidSealed
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	sealed := &SealedKey{
+		KeyID:     string(*resp.KID),
+		Algorithm: alg,
+		Curve:     sk.Curve.Params().Name,
+		DataKey:   resp.Result,
+		Nonce:     make([]byte, aead.NonceSize()),
+	}
+	if _, err := io.ReadFull(rand.Reader, sealed.Nonce); err != nil {
+		return nil, err
+	}
+	scalar := sk.D.FillBytes(make([]byte, scalarLen(sk.Curve)))
+	defer wipe(scalar)
+	sealed.Ciphertext = aead.Seal(nil, sealed.Nonce, scalar, []byte(sealed.Curve))
+	return sealed, nil
+}
+
+// Key is a private key opened by Open, in the memory of the process until Close.
+type Key struct {
+	*ecdsa.PrivateKey
+}
+
+// Close zeroes the scalar of the key and drops it, when the prover stops. The unwrapped data key
+// was wiped by Open already.
+func (k *Key) Close() error {
+	if k.PrivateKey != nil {
+		wipeInt(k.D)
+		k.PrivateKey = nil
+	}
+	return nil
+}
+
+// Open unwraps the data key of the sealed key by the vault, and decrypts by it the private key of the curve.
+func Open(ctx context.Context, api API, sealed *SealedKey, curve elliptic.Curve) (*Key, error) {
+	if sealed.Curve != curve.Params().Name {
+		return nil, errCurveMismatch
+	}
+	var (
+		kid = azkeys.ID(sealed.KeyID)
+		alg = sealed.Algorithm
+	)
+	resp, err := api.UnwrapKey(ctx, kid.Name(), kid.Version(), azkeys.KeyOperationParameters{Algorithm: &alg, Value: sealed.DataKey}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(resp.Result)
+	aead, err := newAEAD(resp.Result)
+	if err != nil {
+		return nil, errInvalidSealed
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return nil, errInvalidSealed
+	}
+	scalar, err := aead.Open(nil, sealed.Nonce, sealed.Ciphertext, []byte(sealed.Curve))
+	if err != nil {
+		return nil, errInvalidSealed
+	}
+	defer wipe(scalar)
+	d := new(big.Int).SetBytes(scalar)
+	if len(scalar) != scalarLen(curve) || d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, errInvalidSealed
+	}
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = curve
+	sk.X, sk.Y = curve.ScalarBaseMult(scalar)
+	return &Key{sk}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func scalarLen(c elliptic.Curve) int {
+	return (c.Params().N.BitLen() + 7) / 8
+}
+
+// wipeInt zeroes the words of the secret k.
+func wipeInt(k *big.Int) {
+	words := k.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	k.SetInt64(0)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
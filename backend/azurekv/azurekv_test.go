@@ -0,0 +1,75 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package azurekv
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+)
+
+// fakeVault wraps with RSA-OAEP-256 under a single key, like an RSA-HSM key of a vault.
+type fakeVault struct {
+	key *rsa.PrivateKey
+}
+
+const kid = "https://vault.vault.azure.net/keys/vrf/0123456789abcdef"
+
+func (f *fakeVault) WrapKey(_ context.Context, name, version string, p azkeys.KeyOperationParameters, _ *azkeys.WrapKeyOptions) (azkeys.WrapKeyResponse, error) {
+	if name != "vrf" || *p.Algorithm != azkeys.EncryptionAlgorithmRSAOAEP256 {
+		return azkeys.WrapKeyResponse{}, errors.New("BadParameter")
+	}
+	ct, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, &f.key.PublicKey, p.Value, nil)
+	id := azkeys.ID(kid)
+	return azkeys.WrapKeyResponse{KeyOperationResult: azkeys.KeyOperationResult{KID: &id, Result: ct}}, err
+}
+
+func (f *fakeVault) UnwrapKey(_ context.Context, name, version string, p azkeys.KeyOperationParameters, _ *azkeys.UnwrapKeyOptions) (azkeys.UnwrapKeyResponse, error) {
+	if name != "vrf" || version != "0123456789abcdef" {
+		return azkeys.UnwrapKeyResponse{}, errors.New("KeyNotFound")
+	}
+	pt, err := rsa.DecryptOAEP(sha256.New(), nil, f.key, p.Value, nil)
+	return azkeys.UnwrapKeyResponse{KeyOperationResult: azkeys.KeyOperationResult{Result: pt}}, err
+}
+
+func TestSealOpen(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		rk, _ = rsa.GenerateKey(rand.Reader, 2048)
+		api   = &fakeVault{rk}
+		sk, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	)
+	sealed, err := Seal(ctx, api, "vrf", "", sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealed.KeyID != kid || sealed.Algorithm != azkeys.EncryptionAlgorithmRSAOAEP256 {
+		t.Fatal("wrong sealed key", sealed.KeyID, sealed.Algorithm)
+	}
+	opened, err := Open(ctx, api, sealed, elliptic.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.D.Cmp(sk.D) != 0 || opened.X.Cmp(sk.X) != 0 || opened.Y.Cmp(sk.Y) != 0 {
+		t.Fatal("opened key differs")
+	}
+	d := opened.D
+	if err := opened.Close(); err != nil || opened.PrivateKey != nil || d.Sign() != 0 || sk.D.Sign() == 0 {
+		t.Fatal("closed key not wiped")
+	}
+	if _, err := Open(ctx, api, sealed, elliptic.P384()); err != errCurveMismatch {
+		t.Fatal("opened for another curve")
+	}
+	sealed.Ciphertext[0] ^= 1
+	if _, err := Open(ctx, api, sealed, elliptic.P256()); err != errInvalidSealed {
+		t.Fatal("tampered key opened")
+	}
+}
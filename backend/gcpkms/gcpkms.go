@@ -0,0 +1,281 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package gcpkms is an encrypted keystore of VRF keys under a Google Cloud KMS key, so that validators
+// on GKE have no plaintext key on disk or in their secrets: the scalar is encrypted by KMS itself, and
+// decrypted by it into the memory of the prover by Open. The HSM protection level of Cloud HSM, which
+// may be required, applies to the KMS key only; the VRF scalar is used by the prover in memory.
+//
+// Calls are retried with exponential backoff and jitter on transient errors and on CRC32C mismatches,
+// and their latencies are reported to Metrics. Like awskms, asymmetric keys aren't used, as KMS can't
+// answer the challenge of a proof.
+package gcpkms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"hash/crc32"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var (
+	errInvalidKey    = errors.New("gcpkms: invalid private key")
+	errInvalidSealed = errors.New("gcpkms: invalid sealed key")
+	errCurveMismatch = errors.New("gcpkms: sealed key of another curve")
+	errNotHSM        = errors.New("gcpkms: key isn't of the HSM protection level")
+	errCorrupted     = errors.New("gcpkms: CRC32C mismatch")
+
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// API is the part of *kms.KeyManagementClient used by the Client.
+type API interface {
+	Encrypt(ctx context.Context, req *kmspb.EncryptRequest, opts ...gax.CallOption) (*kmspb.EncryptResponse, error)
+	Decrypt(ctx context.Context, req *kmspb.DecryptRequest, opts ...gax.CallOption) (*kmspb.DecryptResponse, error)
+}
+
+// Metrics receives the latency of each attempt of a call, e.g. to feed a histogram.
+// op is "Encrypt" or "Decrypt", attempt counts from 1, and err is nil for successes.
+type Metrics interface {
+	Observe(op string, attempt int, latency time.Duration, err error)
+}
+
+// SealedKey is a VRF private key encrypted by a KMS key, to be stored, e.g. as JSON.
+type SealedKey struct {
+	// KeyName is the resource name of the CryptoKey.
+	KeyName string `json:"key_name"`
+	// Curve is the name of the curve of the key, which is authenticated as well.
+	Curve      string `json:"curve"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Client seals and opens keys with KMS.
+type Client struct {
+	api         API
+	metrics     Metrics
+	maxAttempts int
+	initial     time.Duration
+	max         time.Duration
+	requireHSM  bool
+	sleep       func(context.Context, time.Duration) error
+}
+
+// Option modifies the behavior of a Client.
+type Option func(*Client)
+
+// WithRetry sets the number of attempts of a call, and the bounds of the backoff between them,
+// which doubles from initial up to max. The default is 5 attempts from 100ms up to 5s.
+func WithRetry(maxAttempts int, initial, max time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts, c.initial, c.max = maxAttempts, initial, max
+	}
+}
+
+// WithMetrics sets the receiver of the latencies of the calls.
+func WithMetrics(m Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// WithRequireHSM makes calls fail unless KMS reports the HSM protection level, i.e. Cloud HSM.
+func WithRequireHSM() Option {
+	return func(c *Client) {
+		c.requireHSM = true
+	}
+}
+
+// New creates the Client calling api, usually a *kms.KeyManagementClient.
+func New(api API, opts ...Option) *Client {
+	c := &Client{
+		api:         api,
+		maxAttempts: 5,
+		initial:     100 * time.Millisecond,
+		max:         5 * time.Second,
+		sleep:       sleep,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Seal encrypts the scalar of sk by the CryptoKey keyName.
+func (c *Client) Seal(ctx context.Context, keyName string, sk *ecdsa.PrivateKey) (*SealedKey, error) {
+	if sk == nil || sk.D == nil || sk.D.Sign() <= 0 || sk.D.Cmp(sk.Curve.Params().N) >= 0 {
+		return nil, errInvalidKey
+	}
+	var (
+		curve  = sk.Curve.Params().Name
+		aad    = additionalData(curve)
+		scalar = sk.D.FillBytes(make([]byte, scalarLen(sk.Curve)))
+		resp   *kmspb.EncryptResponse
+	)
+	defer wipe(scalar)
+	err := c.call(ctx, "Encrypt", func(ctx context.Context) (err error) {
+		resp, err = c.api.Encrypt(ctx, &kmspb.EncryptRequest{
+			Name:                              keyName,
+			Plaintext:                         scalar,
+			PlaintextCrc32C:                   checksum(scalar),
+			AdditionalAuthenticatedData:       aad,
+			AdditionalAuthenticatedDataCrc32C: checksum(aad),
+		})
+		if err != nil {
+			return err
+		}
+		if !resp.VerifiedPlaintextCrc32C || !resp.VerifiedAdditionalAuthenticatedDataCrc32C ||
+			resp.CiphertextCrc32C == nil || resp.CiphertextCrc32C.Value != checksum(resp.Ciphertext).Value {
+			return errCorrupted
+		}
+		return c.checkProtection(resp.ProtectionLevel)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SealedKey{KeyName: resp.Name, Curve: curve, Ciphertext: resp.Ciphertext}, nil
+}
+
+// Key is a private key decrypted by Open, in the memory of the process until Close.
+type Key struct {
+	*ecdsa.PrivateKey
+}
+
+// Close zeroes the scalar of the key and drops it, when the prover stops. The Plaintext of the
+// response of Decrypt was wiped by Open already.
+func (k *Key) Close() error {
+	if k.PrivateKey != nil {
+		wipeInt(k.D)
+		k.PrivateKey = nil
+	}
+	return nil
+}
+
+// Open decrypts the sealed key by KMS, and returns the private key of the curve.
+func (c *Client) Open(ctx context.Context, sealed *SealedKey, curve elliptic.Curve) (*Key, error) {
+	if sealed.Curve != curve.Params().Name {
+		return nil, errCurveMismatch
+	}
+	var (
+		aad  = additionalData(sealed.Curve)
+		resp *kmspb.DecryptResponse
+	)
+	err := c.call(ctx, "Decrypt", func(ctx context.Context) (err error) {
+		resp, err = c.api.Decrypt(ctx, &kmspb.DecryptRequest{
+			Name:                              sealed.KeyName,
+			Ciphertext:                        sealed.Ciphertext,
+			CiphertextCrc32C:                  checksum(sealed.Ciphertext),
+			AdditionalAuthenticatedData:       aad,
+			AdditionalAuthenticatedDataCrc32C: checksum(aad),
+		})
+		if err != nil {
+			return err
+		}
+		if resp.PlaintextCrc32C == nil || resp.PlaintextCrc32C.Value != checksum(resp.Plaintext).Value {
+			wipe(resp.Plaintext)
+			return errCorrupted
+		}
+		return c.checkProtection(resp.ProtectionLevel)
+	})
+	if err != nil {
+		return nil, err
+	}
+	scalar := resp.Plaintext
+	defer wipe(scalar)
+	d := new(big.Int).SetBytes(scalar)
+	if len(scalar) != scalarLen(curve) || d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, errInvalidSealed
+	}
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = curve
+	sk.X, sk.Y = curve.ScalarBaseMult(scalar)
+	return &Key{sk}, nil
+}
+
+func (c *Client) checkProtection(level kmspb.ProtectionLevel) error {
+	if c.requireHSM && level != kmspb.ProtectionLevel_HSM {
+		return errNotHSM
+	}
+	return nil
+}
+
+// call runs f until it succeeds, fails with an error which isn't transient, or runs out of attempts.
+func (c *Client) call(ctx context.Context, op string, f func(context.Context) error) error {
+	backoff := c.initial
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := f(ctx)
+		if c.metrics != nil {
+			c.metrics.Observe(op, attempt, time.Since(start), err)
+		}
+		if err == nil || !retryable(err) || attempt >= c.maxAttempts {
+			return err
+		}
+		// full jitter: a random wait up to the backoff, which doubles
+		if err := c.sleep(ctx, time.Duration(rand.Int63n(int64(backoff)+1))); err != nil {
+			return err
+		}
+		if backoff *= 2; backoff > c.max {
+			backoff = c.max
+		}
+	}
+}
+
+// retryable returns whether err is transient, following the retry guidance of Cloud KMS.
+func retryable(err error) bool {
+	if err == errCorrupted {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func additionalData(curve string) []byte {
+	return []byte("go-ecvrf:" + curve)
+}
+
+func checksum(b []byte) *wrapperspb.Int64Value {
+	return wrapperspb.Int64(int64(crc32.Checksum(b, castagnoli)))
+}
+
+func scalarLen(c elliptic.Curve) int {
+	return (c.Params().N.BitLen() + 7) / 8
+}
+
+// wipeInt zeroes the words of the secret k.
+func wipeInt(k *big.Int) {
+	words := k.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	k.SetInt64(0)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package gcpkms
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeKMS "encrypts" by prefixing the additional data, failing the first calls with errs.
+type fakeKMS struct {
+	errs  []error
+	level kmspb.ProtectionLevel
+}
+
+func (f *fakeKMS) fail() error {
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *fakeKMS) Encrypt(_ context.Context, req *kmspb.EncryptRequest, _ ...gax.CallOption) (*kmspb.EncryptResponse, error) {
+	if err := f.fail(); err != nil {
+		return nil, err
+	}
+	ct := append(append([]byte(nil), req.AdditionalAuthenticatedData...), req.Plaintext...)
+	return &kmspb.EncryptResponse{
+		Name:                    req.Name + "/cryptoKeyVersions/1",
+		Ciphertext:              ct,
+		CiphertextCrc32C:        checksum(ct),
+		VerifiedPlaintextCrc32C: req.PlaintextCrc32C.Value == checksum(req.Plaintext).Value,
+		VerifiedAdditionalAuthenticatedDataCrc32C: true,
+		ProtectionLevel: f.level,
+	}, nil
+}
+
+func (f *fakeKMS) Decrypt(_ context.Context, req *kmspb.DecryptRequest, _ ...gax.CallOption) (*kmspb.DecryptResponse, error) {
+	if err := f.fail(); err != nil {
+		return nil, err
+	}
+	aad := req.AdditionalAuthenticatedData
+	if !bytes.HasPrefix(req.Ciphertext, aad) {
+		return nil, status.Error(codes.InvalidArgument, "Decryption failed")
+	}
+	pt := append([]byte(nil), req.Ciphertext[len(aad):]...)
+	return &kmspb.DecryptResponse{Plaintext: pt, PlaintextCrc32C: checksum(pt), ProtectionLevel: f.level}, nil
+}
+
+type attempts []int
+
+func (a *attempts) Observe(op string, attempt int, latency time.Duration, err error) {
+	*a = append(*a, attempt)
+}
+
+func TestClient(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		api     = &fakeKMS{level: kmspb.ProtectionLevel_HSM}
+		metrics attempts
+		waits   []time.Duration
+		sk, _   = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	)
+	c := New(api, WithMetrics(&metrics), WithRequireHSM(), WithRetry(3, time.Second, 2*time.Second))
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	api.errs = []error{status.Error(codes.Unavailable, ""), status.Error(codes.DeadlineExceeded, "")}
+	sealed, err := c.Seal(ctx, "projects/p/locations/l/keyRings/r/cryptoKeys/vrf", sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 3 || len(waits) != 2 || waits[0] > time.Second || waits[1] > 2*time.Second {
+		t.Fatal("wrong retries", metrics, waits)
+	}
+	opened, err := c.Open(ctx, sealed, elliptic.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.D.Cmp(sk.D) != 0 || opened.X.Cmp(sk.X) != 0 {
+		t.Fatal("opened key differs")
+	}
+	d := opened.D
+	if err := opened.Close(); err != nil || opened.PrivateKey != nil || d.Sign() != 0 || sk.D.Sign() == 0 {
+		t.Fatal("closed key not wiped")
+	}
+
+	// permanent errors aren't retried, and retries are bounded
+	metrics = nil
+	api.errs = []error{status.Error(codes.PermissionDenied, "")}
+	if _, err := c.Open(ctx, sealed, elliptic.P256()); status.Code(err) != codes.PermissionDenied || len(metrics) != 1 {
+		t.Fatal("permanent error retried", err, metrics)
+	}
+	metrics = nil
+	api.errs = []error{status.Error(codes.Unavailable, ""), status.Error(codes.Unavailable, ""), status.Error(codes.Unavailable, "")}
+	if _, err := c.Open(ctx, sealed, elliptic.P256()); status.Code(err) != codes.Unavailable || len(metrics) != 3 {
+		t.Fatal("retries not bounded", err, metrics)
+	}
+
+	if _, err := c.Open(ctx, sealed, elliptic.P384()); err != errCurveMismatch {
+		t.Fatal("opened for another curve")
+	}
+	api.level = kmspb.ProtectionLevel_SOFTWARE
+	if _, err := c.Open(ctx, sealed, elliptic.P256()); err != errNotHSM {
+		t.Fatal("software key accepted", err)
+	}
+}
@@ -0,0 +1,22 @@
+module github.com/vechain/go-ecvrf/backend/gcpkms
+
+go 1.21
+
+require (
+	github.com/googleapis/gax-go/v2 v2.12.4
+	google.golang.org/grpc v1.64.0
+	google.golang.org/protobuf v1.34.2
+)
+
+require (
+	cloud.google.com/go/kms v1.18.0
+	cloud.google.com/go/longrunning v0.5.7 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/api v0.184.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20240610135401-a8a62080eff3 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240604185151-ef581f913117 // indirect
+)
+
+replace github.com/vechain/go-ecvrf => ../../
@@ -0,0 +1,10 @@
+module github.com/vechain/go-ecvrf/backend/pkcs11
+
+go 1.21
+
+require (
+	github.com/miekg/pkcs11 v1.1.2
+	github.com/vechain/go-ecvrf v0.0.0-20200305101714-4252ed3a3b96
+)
+
+replace github.com/vechain/go-ecvrf => ../../
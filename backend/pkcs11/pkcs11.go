@@ -0,0 +1,227 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package pkcs11 keeps VRF keys in an HSM through PKCS#11: the key implements ecvrf.RemoteKey,
+// so that ecvrf.ProveRemote assembles proofs without the secret scalar leaving the HSM.
+//
+// Gamma = x*H and the nonce commitment k*H are computed by CKM_ECDH1_DERIVE with CKD_NULL,
+// whose result is the x-coordinate of the product, and nonces are session EC keys generated by
+// CKM_EC_KEY_PAIR_GEN. The response s = (k + c*x) mod q has no standard mechanism, though:
+// it's computed by a Responder, which calls the vendor extension of the HSM providing it.
+package pkcs11
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/vechain/go-ecvrf"
+)
+
+var (
+	errNoResponder   = errors.New("pkcs11: no responder: the HSM must provide k + c*x by a vendor mechanism")
+	errUnknownCurve  = errors.New("pkcs11: unknown curve, set Config.ECParams")
+	errInvalidPublic = errors.New("pkcs11: invalid public key")
+	errInvalidPoint  = errors.New("pkcs11: invalid EC point")
+	errInvalidResult = errors.New("pkcs11: invalid derived value")
+)
+
+// Module is the part of *pkcs11.Ctx used by keys, which sessions are opened and logged in on.
+type Module interface {
+	DeriveKey(sh p11.SessionHandle, m []*p11.Mechanism, basekey p11.ObjectHandle, a []*p11.Attribute) (p11.ObjectHandle, error)
+	GetAttributeValue(sh p11.SessionHandle, o p11.ObjectHandle, a []*p11.Attribute) ([]*p11.Attribute, error)
+	GenerateKeyPair(sh p11.SessionHandle, m []*p11.Mechanism, public, private []*p11.Attribute) (p11.ObjectHandle, p11.ObjectHandle, error)
+	DestroyObject(sh p11.SessionHandle, oh p11.ObjectHandle) error
+}
+
+// Responder returns the response s = (k + c*x) mod q, of 32-octet big-endian scalars, where x is
+// the private key object key and k the private key object nonce, both in the session sh.
+// It's the vendor extension of the HSM, e.g. a function of a firmware module of the vendor SDK.
+type Responder func(m Module, sh p11.SessionHandle, key, nonce p11.ObjectHandle, c []byte) (s []byte, err error)
+
+// Config is the HSM key of a VRF.
+type Config struct {
+	Module  Module
+	Session p11.SessionHandle
+	// Key is the EC private key object, which must allow CKA_DERIVE.
+	Key p11.ObjectHandle
+	// Public is the public key of Key, see PublicKey.
+	Public *ecdsa.PublicKey
+	// ECParams is the DER encoded CKA_EC_PARAMS of nonces, derived from the curve name of
+	// Public if nil, for P-256 and secp256k1.
+	ECParams []byte
+	Respond  Responder
+}
+
+var curveOIDs = map[string]asn1.ObjectIdentifier{
+	"P-256":     {1, 2, 840, 10045, 3, 1, 7},
+	"secp256k1": {1, 3, 132, 0, 10},
+}
+
+// New returns the RemoteKey of the config. The session must not be used concurrently, as PKCS#11
+// sessions are single threaded: keys are created per session for parallel proving.
+func New(cfg Config) (ecvrf.RemoteKey, error) {
+	if cfg.Respond == nil {
+		return nil, errNoResponder
+	}
+	if !validPublic(cfg.Public) {
+		return nil, errInvalidPublic
+	}
+	if cfg.ECParams == nil {
+		oid, ok := curveOIDs[cfg.Public.Curve.Params().Name]
+		if !ok {
+			return nil, errUnknownCurve
+		}
+		cfg.ECParams, _ = asn1.Marshal(oid)
+	}
+	return &key{scalar{&cfg, cfg.Key, cfg.Public}}, nil
+}
+
+// validPublic reports whether pk is a point of its curve, like the public keys accepted by the VRFs.
+func validPublic(pk *ecdsa.PublicKey) bool {
+	return pk != nil && pk.Curve != nil && pk.Curve.Params() != nil && pk.X != nil && pk.Y != nil &&
+		pk.Curve.IsOnCurve(pk.X, pk.Y)
+}
+
+// PublicKey reads the CKA_EC_POINT of the public key object pub of the curve.
+func PublicKey(m Module, sh p11.SessionHandle, pub p11.ObjectHandle, curve elliptic.Curve) (*ecdsa.PublicKey, error) {
+	attrs, err := m.GetAttributeValue(sh, pub, []*p11.Attribute{p11.NewAttribute(p11.CKA_EC_POINT, nil)})
+	if err != nil {
+		return nil, err
+	}
+	return decodePoint(curve, attrs[0].Value)
+}
+
+// decodePoint decodes a CKA_EC_POINT, the DER octet string of the uncompressed point, which some
+// modules give without the DER wrapping.
+func decodePoint(curve elliptic.Curve, v []byte) (*ecdsa.PublicKey, error) {
+	var raw []byte
+	if rest, err := asn1.Unmarshal(v, &raw); err != nil || len(rest) != 0 {
+		raw = v
+	}
+	byteLen := (curve.Params().BitSize + 7) / 8
+	if len(raw) != 1+2*byteLen || raw[0] != 4 {
+		return nil, errInvalidPoint
+	}
+	x := new(big.Int).SetBytes(raw[1 : 1+byteLen])
+	y := new(big.Int).SetBytes(raw[1+byteLen:])
+	if !curve.IsOnCurve(x, y) {
+		return nil, errInvalidPoint
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// scalar is a private key object, the VRF key or a nonce.
+type scalar struct {
+	cfg *Config
+	obj p11.ObjectHandle
+	pub *ecdsa.PublicKey
+}
+
+func (s *scalar) Public() *ecdsa.PublicKey {
+	return s.pub
+}
+
+// ecdhMechanism is the mechanism of ECDH with the uncompressed point pub.
+var ecdhMechanism = func(pub []byte) *p11.Mechanism {
+	return p11.NewMechanism(p11.CKM_ECDH1_DERIVE, p11.NewECDH1DeriveParams(p11.CKD_NULL, nil, pub))
+}
+
+// ECDH derives the x-coordinate of the product by CKM_ECDH1_DERIVE, as an extractable session secret,
+// which is read and destroyed: the products are the public Gamma and k*H of proofs.
+func (s *scalar) ECDH(px, py *big.Int) (*big.Int, error) {
+	var (
+		m       = s.cfg.Module
+		sh      = s.cfg.Session
+		byteLen = (s.pub.Curve.Params().BitSize + 7) / 8
+		pub     = make([]byte, 1+2*byteLen)
+	)
+	pub[0] = 4
+	px.FillBytes(pub[1 : 1+byteLen])
+	py.FillBytes(pub[1+byteLen:])
+	obj, err := m.DeriveKey(sh, []*p11.Mechanism{ecdhMechanism(pub)}, s.obj, []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, p11.CKO_SECRET_KEY),
+		p11.NewAttribute(p11.CKA_KEY_TYPE, p11.CKK_GENERIC_SECRET),
+		p11.NewAttribute(p11.CKA_VALUE_LEN, byteLen),
+		p11.NewAttribute(p11.CKA_TOKEN, false),
+		p11.NewAttribute(p11.CKA_SENSITIVE, false),
+		p11.NewAttribute(p11.CKA_EXTRACTABLE, true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer m.DestroyObject(sh, obj)
+	attrs, err := m.GetAttributeValue(sh, obj, []*p11.Attribute{p11.NewAttribute(p11.CKA_VALUE, nil)})
+	if err != nil {
+		return nil, err
+	}
+	if len(attrs[0].Value) != byteLen {
+		return nil, errInvalidResult
+	}
+	return new(big.Int).SetBytes(attrs[0].Value), nil
+}
+
+type key struct {
+	scalar
+}
+
+// NewNonce generates the nonce as a session key pair, sensitive and not extractable.
+func (k *key) NewNonce() (ecvrf.RemoteNonce, error) {
+	var (
+		m  = k.cfg.Module
+		sh = k.cfg.Session
+	)
+	pubObj, privObj, err := m.GenerateKeyPair(sh, []*p11.Mechanism{p11.NewMechanism(p11.CKM_EC_KEY_PAIR_GEN, nil)},
+		[]*p11.Attribute{
+			p11.NewAttribute(p11.CKA_TOKEN, false),
+			p11.NewAttribute(p11.CKA_EC_PARAMS, k.cfg.ECParams),
+		},
+		[]*p11.Attribute{
+			p11.NewAttribute(p11.CKA_TOKEN, false),
+			p11.NewAttribute(p11.CKA_SENSITIVE, true),
+			p11.NewAttribute(p11.CKA_EXTRACTABLE, false),
+			p11.NewAttribute(p11.CKA_DERIVE, true),
+		})
+	if err != nil {
+		return nil, err
+	}
+	n := &nonce{scalar: scalar{k.cfg, privObj, nil}, key: k, pubObj: pubObj}
+	if n.pub, err = PublicKey(m, sh, pubObj, k.pub.Curve); err != nil {
+		n.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+type nonce struct {
+	scalar
+	key       *key
+	pubObj    p11.ObjectHandle
+	responded bool
+}
+
+// Respond implements ecvrf.RemoteNonce, through the Responder.
+func (n *nonce) Respond(c *big.Int) (*big.Int, error) {
+	if n.responded {
+		return nil, errors.New("pkcs11: nonce already used")
+	}
+	n.responded = true
+	byteLen := (n.pub.Curve.Params().BitSize + 7) / 8
+	s, err := n.cfg.Respond(n.cfg.Module, n.cfg.Session, n.key.obj, n.obj, c.FillBytes(make([]byte, byteLen)))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(s), nil
+}
+
+// Close destroys the session objects of the nonce.
+func (n *nonce) Close() error {
+	err := n.cfg.Module.DestroyObject(n.cfg.Session, n.obj)
+	if err2 := n.cfg.Module.DestroyObject(n.cfg.Session, n.pubObj); err == nil {
+		err = err2
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package pkcs11
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/vechain/go-ecvrf"
+)
+
+// softModule computes the mechanisms used by keys in memory, as an HSM would.
+type softModule struct {
+	curve   elliptic.Curve
+	objects map[p11.ObjectHandle]*softObject
+	next    p11.ObjectHandle
+}
+
+type softObject struct {
+	d     *big.Int // private keys
+	point []byte   // public keys, the DER CKA_EC_POINT
+	value []byte   // derived secrets
+}
+
+func (m *softModule) add(o *softObject) p11.ObjectHandle {
+	m.next++
+	m.objects[m.next] = o
+	return m.next
+}
+
+func (m *softModule) DeriveKey(_ p11.SessionHandle, mech []*p11.Mechanism, base p11.ObjectHandle, _ []*p11.Attribute) (p11.ObjectHandle, error) {
+	pub := mech[0].Parameter
+	x, y := elliptic.Unmarshal(m.curve, pub)
+	if x == nil {
+		return 0, errors.New("CKR_MECHANISM_PARAM_INVALID")
+	}
+	rx, _ := m.curve.ScalarMult(x, y, m.objects[base].d.Bytes())
+	return m.add(&softObject{value: rx.FillBytes(make([]byte, 32))}), nil
+}
+
+func (m *softModule) GetAttributeValue(_ p11.SessionHandle, o p11.ObjectHandle, a []*p11.Attribute) ([]*p11.Attribute, error) {
+	obj, ok := m.objects[o]
+	if !ok {
+		return nil, errors.New("CKR_OBJECT_HANDLE_INVALID")
+	}
+	switch a[0].Type {
+	case p11.CKA_EC_POINT:
+		return []*p11.Attribute{p11.NewAttribute(p11.CKA_EC_POINT, obj.point)}, nil
+	case p11.CKA_VALUE:
+		return []*p11.Attribute{p11.NewAttribute(p11.CKA_VALUE, obj.value)}, nil
+	}
+	return nil, errors.New("CKR_ATTRIBUTE_TYPE_INVALID")
+}
+
+func (m *softModule) GenerateKeyPair(_ p11.SessionHandle, _ []*p11.Mechanism, _, _ []*p11.Attribute) (p11.ObjectHandle, p11.ObjectHandle, error) {
+	sk, err := ecdsa.GenerateKey(m.curve, rand.Reader)
+	if err != nil {
+		return 0, 0, err
+	}
+	return m.addPair(sk)
+}
+
+func (m *softModule) addPair(sk *ecdsa.PrivateKey) (p11.ObjectHandle, p11.ObjectHandle, error) {
+	point, _ := asn1.Marshal(elliptic.Marshal(m.curve, sk.X, sk.Y))
+	return m.add(&softObject{point: point}), m.add(&softObject{d: sk.D}), nil
+}
+
+func (m *softModule) DestroyObject(_ p11.SessionHandle, o p11.ObjectHandle) error {
+	if _, ok := m.objects[o]; !ok {
+		return errors.New("CKR_OBJECT_HANDLE_INVALID")
+	}
+	delete(m.objects, o)
+	return nil
+}
+
+// respond is the vendor mechanism of softModule.
+func respond(m Module, _ p11.SessionHandle, key, nonce p11.ObjectHandle, c []byte) ([]byte, error) {
+	sm := m.(*softModule)
+	s := new(big.Int).Mul(new(big.Int).SetBytes(c), sm.objects[key].d)
+	s.Add(s, sm.objects[nonce].d)
+	s.Mod(s, sm.curve.Params().N)
+	return s.FillBytes(make([]byte, 32)), nil
+}
+
+func TestKey(t *testing.T) {
+	ecdhMechanism = func(pub []byte) *p11.Mechanism {
+		return p11.NewMechanism(p11.CKM_ECDH1_DERIVE, pub)
+	}
+	var (
+		m     = &softModule{curve: elliptic.P256(), objects: map[p11.ObjectHandle]*softObject{}}
+		sk, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		v     = ecvrf.NewP256Sha256Tai()
+	)
+	pubObj, privObj, _ := m.addPair(sk)
+	pk, err := PublicKey(m, 0, pubObj, elliptic.P256())
+	if err != nil || pk.X.Cmp(sk.X) != 0 || pk.Y.Cmp(sk.Y) != 0 {
+		t.Fatal("wrong public key", err)
+	}
+	if _, err := New(Config{}); err == nil {
+		t.Fatal("empty config accepted")
+	}
+	if _, err := New(Config{Module: m, Key: privObj, Public: pk}); err != errNoResponder {
+		t.Fatal("key without responder accepted")
+	}
+	for _, pub := range []*ecdsa.PublicKey{nil, {}, {Curve: elliptic.P256()}, {Curve: elliptic.P256(), X: pk.X, Y: pk.X}} {
+		if _, err := New(Config{Module: m, Key: privObj, Public: pub, Respond: respond}); err != errInvalidPublic {
+			t.Fatal("invalid public key accepted", pub)
+		}
+	}
+	key, err := New(Config{Module: m, Key: privObj, Public: pk, Respond: respond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		alpha := []byte{byte(i)}
+		beta, pi, err := ecvrf.ProveRemote(v, key, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, _, _ := v.Prove(sk, alpha); string(beta) != string(want) {
+			t.Fatal("beta differs from Prove")
+		}
+		if _, err := v.Verify(pk, alpha, pi); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// only the key pair is left: nonces and derived secrets are destroyed
+	if len(m.objects) != 2 {
+		t.Fatal("session objects left:", len(m.objects)-2)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package tpm is a sealed-key store of VRF keys on the TPM 2.0 of a machine: the scalar is sealed under
+// the ECC storage root key of the owner hierarchy, optionally to the current values of PCRs, so the
+// sealed blob can only be unsealed by the same TPM, in the same boot state. The key isn't kept in the
+// TPM: Open unseals the scalar into the memory of the host, which computes the proofs.
+//
+// The TPM can't compute them instead. It could compute Gamma = x*H by TPM2_ECDH_ZGen, but the
+// response s = k + c*x can't be obtained: TPM2_Commit and the ECDAA scheme of TPM2_Sign answer a
+// challenge derived by the TPM from its own nonce and the digest, not the challenge of ECVRF.
+package tpm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	errInvalidKey    = errors.New("tpm: invalid private key")
+	errInvalidSealed = errors.New("tpm: invalid sealed key")
+	errCurveMismatch = errors.New("tpm: sealed key of another curve")
+)
+
+// SealedKey is a VRF private key sealed to a TPM, to be stored, e.g. as JSON.
+type SealedKey struct {
+	// Curve is the name of the curve of the key.
+	Curve string `json:"curve"`
+	// PCRs are the indices of the SHA-256 PCRs the key is bound to.
+	PCRs []int `json:"pcrs,omitempty"`
+	// Blob is the serialized SealedBytes of go-tpm-tools.
+	Blob []byte `json:"blob"`
+}
+
+// Seal seals the scalar of sk to the TPM rw, e.g. of tpm2.OpenTPM(), bound to the current values
+// of the SHA-256 PCRs of indices pcrs, if any.
+func Seal(rw io.ReadWriter, sk *ecdsa.PrivateKey, pcrs []int) (*SealedKey, error) {
+	if sk == nil || sk.D == nil || sk.D.Sign() <= 0 || sk.D.Cmp(sk.Curve.Params().N) >= 0 {
+		return nil, errInvalidKey
+	}
+	srk, err := client.StorageRootKeyECC(rw)
+	if err != nil {
+		return nil, err
+	}
+	defer srk.Close()
+	scalar := sk.D.FillBytes(make([]byte, scalarLen(sk.Curve)))
+	defer wipe(scalar)
+	sealed, err := srk.Seal(scalar, client.SealOpts{Current: selection(pcrs)})
+	if err != nil {
+		return nil, err
+	}
+	blob, err := proto.Marshal(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return &SealedKey{Curve: sk.Curve.Params().Name, PCRs: pcrs, Blob: blob}, nil
+}
+
+// Key is a private key unsealed by Open, in the memory of the host until Close.
+type Key struct {
+	*ecdsa.PrivateKey
+}
+
+// Close zeroes the scalar of the key and drops it. The TPM isn't involved: the sealed key opens
+// again while the PCRs are unchanged.
+func (k *Key) Close() error {
+	if k.PrivateKey != nil {
+		wipeInt(k.D)
+		k.PrivateKey = nil
+	}
+	return nil
+}
+
+// Open unseals the key by the TPM rw, and returns the private key of the curve. It fails if the
+// PCRs changed since Seal.
+func Open(rw io.ReadWriter, sealed *SealedKey, curve elliptic.Curve) (*Key, error) {
+	if sealed.Curve != curve.Params().Name {
+		return nil, errCurveMismatch
+	}
+	var in tpm.SealedBytes
+	if err := proto.Unmarshal(sealed.Blob, &in); err != nil {
+		return nil, errInvalidSealed
+	}
+	srk, err := client.StorageRootKeyECC(rw)
+	if err != nil {
+		return nil, err
+	}
+	defer srk.Close()
+	scalar, err := srk.Unseal(&in, client.UnsealOpts{CertifyCurrent: selection(sealed.PCRs)})
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(scalar)
+	d := new(big.Int).SetBytes(scalar)
+	if len(scalar) != scalarLen(curve) || d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, errInvalidSealed
+	}
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = curve
+	sk.X, sk.Y = curve.ScalarBaseMult(scalar)
+	return &Key{sk}, nil
+}
+
+func selection(pcrs []int) tpm2.PCRSelection {
+	return tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: pcrs}
+}
+
+func scalarLen(c elliptic.Curve) int {
+	return (c.Params().N.BitLen() + 7) / 8
+}
+
+// wipeInt zeroes the words of the secret k.
+func wipeInt(k *big.Int) {
+	words := k.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	k.SetInt64(0)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tpm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/google/go-tpm-tools/simulator"
+	"github.com/google/go-tpm/legacy/tpm2"
+)
+
+func TestSealOpen(t *testing.T) {
+	sim, err := simulator.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	sealed, err := Seal(sim, sk, []int{7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := Open(sim, sealed, elliptic.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.D.Cmp(sk.D) != 0 || opened.X.Cmp(sk.X) != 0 || opened.Y.Cmp(sk.Y) != 0 {
+		t.Fatal("opened key differs")
+	}
+	d := opened.D
+	if err := opened.Close(); err != nil || opened.PrivateKey != nil || d.Sign() != 0 || sk.D.Sign() == 0 {
+		t.Fatal("closed key not wiped")
+	}
+	if _, err := Open(sim, sealed, elliptic.P384()); err != errCurveMismatch {
+		t.Fatal("opened for another curve")
+	}
+
+	// the key is bound to the PCR
+	if err := tpm2.PCRExtend(sim, 7, tpm2.AlgSHA256, make([]byte, 32), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(sim, sealed, elliptic.P256()); err == nil {
+		t.Fatal("opened after the PCR changed")
+	}
+}
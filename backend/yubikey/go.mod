@@ -0,0 +1,3 @@
+module github.com/vechain/go-ecvrf/backend/yubikey
+
+go 1.21
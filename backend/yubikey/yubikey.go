@@ -0,0 +1,241 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package yubikey seals VRF keys to a YubiKey, or another PIV smartcard, for operators without an HSM:
+// the scalar is encrypted to the EC key of a PIV slot by ECIES (ECDH with an ephemeral key, then
+// AES-256-GCM), and the sealed key is stored off the card. Opening it needs the card's ECDH, gated by
+// its PIN and touch policies, but the scalar is then decrypted into the memory of the prover: the card
+// guards the key at rest, not in use.
+//
+// The PIV applet only signs and agrees on keys, with the slot key, so it can't answer the challenge
+// of an ecvrf.RemoteKey.
+package yubikey
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+)
+
+var (
+	errInvalidKey    = errors.New("yubikey: invalid private key")
+	errInvalidSealed = errors.New("yubikey: invalid sealed key")
+	errCurveMismatch = errors.New("yubikey: sealed key of another curve")
+	errWrongCard     = errors.New("yubikey: sealed to another card key")
+)
+
+// Card is the EC key of a PIV slot, i.e. the *piv.ECDSAPrivateKey of github.com/go-piv/piv-go
+// returned by (*piv.YubiKey).PrivateKey, with the PIN in its KeyAuth.
+type Card interface {
+	Public() crypto.PublicKey
+	SharedKey(peer *ecdsa.PublicKey) ([]byte, error)
+}
+
+// TouchPolicy is the touch policy the slot key was generated with, as piv.TouchPolicy.
+type TouchPolicy int
+
+// Touch policies.
+const (
+	TouchNever TouchPolicy = iota + 1
+	TouchAlways
+	TouchCached
+)
+
+// touchCache is how long a YubiKey caches a touch of a TouchCached key.
+const touchCache = 15 * time.Second
+
+// Token opens sealed keys by a card.
+type Token struct {
+	card   Card
+	touch  TouchPolicy
+	prompt func()
+	now    func() time.Time
+
+	mu      sync.Mutex
+	touched time.Time
+}
+
+// Option configures a Token.
+type Option func(*Token)
+
+// WithTouch sets the touch policy of the slot key, and a prompt called when the card waits for a touch,
+// e.g. to tell the operator to touch the YubiKey. The card blocks until it's touched, or times out.
+func WithTouch(policy TouchPolicy, prompt func()) Option {
+	return func(t *Token) {
+		t.touch = policy
+		t.prompt = prompt
+	}
+}
+
+// New creates the token of the card.
+func New(card Card, opts ...Option) *Token {
+	t := &Token{card: card, touch: TouchNever, now: time.Now}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// SealedKey is a VRF private key sealed to a card key, to be stored, e.g. as JSON.
+type SealedKey struct {
+	// Card is the compressed public key of the card key.
+	Card []byte `json:"card"`
+	// Curve is the name of the curve of the VRF key, which is authenticated as well.
+	Curve string `json:"curve"`
+	// Ephemeral is the compressed ephemeral public key, on the curve of the card key.
+	Ephemeral []byte `json:"ephemeral"`
+	// Nonce and Ciphertext are the AES-256-GCM encryption of the scalar.
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Seal seals the scalar of sk to the card key of public key card, P-256 or P-384. It doesn't need the card.
+func Seal(card *ecdsa.PublicKey, sk *ecdsa.PrivateKey) (*SealedKey, error) {
+	if sk == nil || sk.D == nil || sk.D.Sign() <= 0 || sk.D.Cmp(sk.Curve.Params().N) >= 0 {
+		return nil, errInvalidKey
+	}
+	e, err := ecdsa.GenerateKey(card.Curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	z, _ := card.Curve.ScalarMult(card.X, card.Y, e.D.Bytes())
+	sealed := &SealedKey{
+		Card:      elliptic.MarshalCompressed(card.Curve, card.X, card.Y),
+		Curve:     sk.Curve.Params().Name,
+		Ephemeral: elliptic.MarshalCompressed(card.Curve, e.X, e.Y),
+	}
+	shared := z.FillBytes(make([]byte, (card.Curve.Params().BitSize+7)/8))
+	defer wipe(shared)
+	aead, err := newAEAD(shared, sealed)
+	if err != nil {
+		return nil, err
+	}
+	sealed.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, sealed.Nonce); err != nil {
+		return nil, err
+	}
+	scalar := sk.D.FillBytes(make([]byte, scalarLen(sk.Curve)))
+	defer wipe(scalar)
+	sealed.Ciphertext = aead.Seal(nil, sealed.Nonce, scalar, []byte(sealed.Curve))
+	return sealed, nil
+}
+
+// Key is a private key opened by Token.Open, in the memory of the process until Close.
+type Key struct {
+	*ecdsa.PrivateKey
+}
+
+// Close zeroes the scalar of the key and drops it, e.g. when the prover stops. Opening the sealed
+// key again needs the card, with its PIN and touch.
+func (k *Key) Close() error {
+	if k.PrivateKey != nil {
+		wipeInt(k.D)
+		k.PrivateKey = nil
+	}
+	return nil
+}
+
+// Open unseals the key by the ECDH of the card, and returns the private key of the curve.
+func (t *Token) Open(sealed *SealedKey, curve elliptic.Curve) (*Key, error) {
+	if sealed.Curve != curve.Params().Name {
+		return nil, errCurveMismatch
+	}
+	pub, ok := t.card.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errWrongCard
+	}
+	x, y := elliptic.UnmarshalCompressed(pub.Curve, sealed.Card)
+	if x == nil || x.Cmp(pub.X) != 0 || y.Cmp(pub.Y) != 0 {
+		return nil, errWrongCard
+	}
+	ex, ey := elliptic.UnmarshalCompressed(pub.Curve, sealed.Ephemeral)
+	if ex == nil {
+		return nil, errInvalidSealed
+	}
+	shared, err := t.sharedKey(&ecdsa.PublicKey{Curve: pub.Curve, X: ex, Y: ey})
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(shared)
+	aead, err := newAEAD(shared, sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return nil, errInvalidSealed
+	}
+	scalar, err := aead.Open(nil, sealed.Nonce, sealed.Ciphertext, []byte(sealed.Curve))
+	if err != nil {
+		return nil, errInvalidSealed
+	}
+	defer wipe(scalar)
+	d := new(big.Int).SetBytes(scalar)
+	if len(scalar) != scalarLen(curve) || d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, errInvalidSealed
+	}
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = curve
+	sk.X, sk.Y = curve.ScalarBaseMult(scalar)
+	return &Key{sk}, nil
+}
+
+// sharedKey runs the ECDH of the card, prompting for a touch if the policy requires it.
+func (t *Token) sharedKey(peer *ecdsa.PublicKey) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if t.prompt != nil && (t.touch == TouchAlways || t.touch == TouchCached && now.Sub(t.touched) >= touchCache) {
+		t.prompt()
+	}
+	shared, err := t.card.SharedKey(peer)
+	if err != nil {
+		return nil, err
+	}
+	t.touched = now
+	return shared, nil
+}
+
+// newAEAD derives the AES-256-GCM key from the shared secret and the public keys of the sealed key.
+func newAEAD(shared []byte, sealed *SealedKey) (cipher.AEAD, error) {
+	h := sha256.New()
+	h.Write([]byte("go-ecvrf:yubikey"))
+	h.Write(shared)
+	h.Write(sealed.Card)
+	h.Write(sealed.Ephemeral)
+	key := h.Sum(nil)
+	defer wipe(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func scalarLen(c elliptic.Curve) int {
+	return (c.Params().N.BitLen() + 7) / 8
+}
+
+// wipeInt zeroes the words of the secret k.
+func wipeInt(k *big.Int) {
+	words := k.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	k.SetInt64(0)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package yubikey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+// softCard does the ECDH of a PIV slot in software.
+type softCard struct {
+	key *ecdsa.PrivateKey
+}
+
+func (c *softCard) Public() crypto.PublicKey { return &c.key.PublicKey }
+
+func (c *softCard) SharedKey(peer *ecdsa.PublicKey) ([]byte, error) {
+	x, _ := c.key.Curve.ScalarMult(peer.X, peer.Y, c.key.D.Bytes())
+	return x.FillBytes(make([]byte, 32)), nil
+}
+
+func TestSealOpen(t *testing.T) {
+	var (
+		ck, _   = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		sk, _   = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		prompts int
+		now     = time.Unix(0, 0)
+	)
+	token := New(&softCard{ck}, WithTouch(TouchCached, func() { prompts++ }))
+	token.now = func() time.Time { return now }
+
+	sealed, err := Seal(&ck.PublicKey, sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := token.Open(sealed, elliptic.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.D.Cmp(sk.D) != 0 || opened.X.Cmp(sk.X) != 0 || opened.Y.Cmp(sk.Y) != 0 {
+		t.Fatal("opened key differs")
+	}
+	d := opened.D
+	if err := opened.Close(); err != nil || opened.PrivateKey != nil || d.Sign() != 0 || sk.D.Sign() == 0 {
+		t.Fatal("closed key not wiped")
+	}
+
+	// the touch is cached for 15s
+	now = now.Add(10 * time.Second)
+	if _, err := token.Open(sealed, elliptic.P256()); err != nil || prompts != 1 {
+		t.Fatal("touch not cached", err, prompts)
+	}
+	now = now.Add(20 * time.Second)
+	if _, err := token.Open(sealed, elliptic.P256()); err != nil || prompts != 2 {
+		t.Fatal("touch cached too long", err, prompts)
+	}
+
+	if _, err := token.Open(sealed, elliptic.P384()); err != errCurveMismatch {
+		t.Fatal("opened for another curve")
+	}
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := New(&softCard{other}).Open(sealed, elliptic.P256()); err != errWrongCard {
+		t.Fatal("opened by another card", err)
+	}
+	sealed.Ciphertext[0] ^= 1
+	if _, err := token.Open(sealed, elliptic.P256()); err != errInvalidSealed {
+		t.Fatal("tampered key opened")
+	}
+}
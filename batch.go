@@ -0,0 +1,207 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBatchCanceled is the error of batch items skipped after a failure, see WithFailFast.
+var ErrBatchCanceled = errors.New("batch canceled")
+
+// BatchItem is an input of VerifyBatch.
+type BatchItem struct {
+	PublicKey *ecdsa.PublicKey
+	Alpha     []byte
+	Pi        []byte
+}
+
+// BatchResult is the verification result of a BatchItem.
+type BatchResult struct {
+	Beta []byte
+	Err  error
+}
+
+type batchConfig struct {
+	parallelism int
+	failFast    bool
+}
+
+// BatchOption modifies the behavior of batch operations.
+type BatchOption func(*batchConfig)
+
+// WithParallelism sets the number of goroutines of batch operations.
+// If n <= 0, runtime.GOMAXPROCS(0) is used. The default is 1.
+func WithParallelism(n int) BatchOption {
+	return func(c *batchConfig) {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		c.parallelism = n
+	}
+}
+
+// WithFailFast makes batch operations stop at the first failure.
+// Items not yet processed then get ErrBatchCanceled as their error.
+func WithFailFast() BatchOption {
+	return func(c *batchConfig) {
+		c.failFast = true
+	}
+}
+
+func newBatchConfig(opts []BatchOption) batchConfig {
+	cfg := batchConfig{parallelism: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// ProveResult is the result of proving an input of ProveBatch.
+type ProveResult struct {
+	Beta []byte
+	Pi   []byte
+	Err  error
+}
+
+// ProveBatch constructs proofs of the inputs under the same private key. Results are in the same order as
+// the inputs, and the returned error is the one of the failed input of the lowest index.
+// Key-dependent values, such as the encoded public key, are computed once per goroutine.
+func ProveBatch(v VRF, sk *ecdsa.PrivateKey, alphas [][]byte, opts ...BatchOption) ([]ProveResult, error) {
+	return ProveBatchContext(context.Background(), v, sk, alphas, opts...)
+}
+
+// ProveBatchContext is like ProveBatch, but stops when ctx is done: the inputs not yet proven then get
+// the error of ctx, which is returned if no input failed before.
+func ProveBatchContext(ctx context.Context, v VRF, sk *ecdsa.PrivateKey, alphas [][]byte, opts ...BatchOption) ([]ProveResult, error) {
+	var (
+		cfg     = newBatchConfig(opts)
+		results = make([]ProveResult, len(alphas))
+		prove   = func(_, i int) error {
+			results[i].Beta, results[i].Pi, results[i].Err = v.Prove(sk, alphas[i])
+			return results[i].Err
+		}
+	)
+	if impl, ok := v.(*vrf); ok && impl.checkPrivateKey(sk) == nil {
+		var (
+			cores  = make([]*core, cfg.parallelism)
+			pk     = &point{sk.X, sk.Y}
+			check  = impl.newCore(sk.Curve)
+			keyErr = check.ValidateKey(pk) // checked once, like Prove does for each call
+		)
+		check.release()
+		defer func() {
+			for _, c := range cores {
+				if c != nil {
+					c.release()
+				}
+			}
+		}()
+		prove = func(worker, i int) (err error) {
+			// observed like the calls of Prove
+			if impl.cfg.Metrics != nil {
+				start := time.Now()
+				defer func() { impl.observeProve(start, err) }()
+			}
+			if keyErr != nil {
+				results[i].Err = keyErr
+				return keyErr
+			}
+			if cores[worker] == nil {
+				cores[worker] = impl.newCore(sk.Curve)
+			}
+			results[i].Beta, results[i].Pi, results[i].Err = impl.prove(cores[worker], sk, pk, alphas[i])
+			return results[i].Err
+		}
+	}
+	runBatch(ctx, len(alphas), cfg, prove, func(i int, err error) {
+		results[i].Err = err
+	})
+	for _, r := range results {
+		if r.Err != nil && r.Err != ErrBatchCanceled {
+			return results, r.Err
+		}
+	}
+	return results, nil
+}
+
+// VerifyBatch verifies the items using v. Results are in the same order as the items,
+// regardless of the parallelism. The returned error is the one of the failed item of the lowest index,
+// or nil if all items are valid.
+func VerifyBatch(v VRF, items []BatchItem, opts ...BatchOption) ([]BatchResult, error) {
+	return VerifyBatchContext(context.Background(), v, items, opts...)
+}
+
+// VerifyBatchContext is like VerifyBatch, but stops when ctx is done: the items not yet verified then get
+// the error of ctx, which is returned if no item failed before.
+func VerifyBatchContext(ctx context.Context, v VRF, items []BatchItem, opts ...BatchOption) ([]BatchResult, error) {
+	results := make([]BatchResult, len(items))
+	runBatch(ctx, len(items), newBatchConfig(opts), func(_, i int) error {
+		item := &items[i]
+		results[i].Beta, results[i].Err = v.Verify(item.PublicKey, item.Alpha, item.Pi)
+		return results[i].Err
+	}, func(i int, err error) {
+		results[i].Err = err
+	})
+	for _, r := range results {
+		if r.Err != nil && r.Err != ErrBatchCanceled {
+			return results, r.Err
+		}
+	}
+	return results, nil
+}
+
+// runBatch calls do for indices in [0, n) over the configured number of goroutines,
+// along with the index of the goroutine in [0, parallelism).
+// cancel is called instead for indices not started once ctx is done, with the error of ctx,
+// or after a failure if failFast is set, with ErrBatchCanceled.
+func runBatch(ctx context.Context, n int, cfg batchConfig, do func(worker, i int) error, cancel func(i int, err error)) {
+	var (
+		done           = ctx.Done()
+		next     int64 = -1
+		canceled int32
+		wg       sync.WaitGroup
+	)
+	worker := func(id int) {
+		defer wg.Done()
+		for {
+			i := int(atomic.AddInt64(&next, 1))
+			if i >= n {
+				return
+			}
+			if atomic.LoadInt32(&canceled) != 0 {
+				cancel(i, ErrBatchCanceled)
+				continue
+			}
+			select {
+			case <-done:
+				cancel(i, ctx.Err())
+				continue
+			default:
+			}
+			if err := do(id, i); err != nil && cfg.failFast {
+				atomic.StoreInt32(&canceled, 1)
+			}
+		}
+	}
+
+	workers := cfg.parallelism
+	if workers > n {
+		workers = n
+	}
+	wg.Add(workers)
+	for i := 1; i < workers; i++ {
+		go worker(i)
+	}
+	if workers > 0 {
+		worker(0)
+	}
+	wg.Wait()
+}
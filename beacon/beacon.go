@@ -0,0 +1,284 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package beacon is a randomness beacon chaining VRF outputs, in the spirit of drand: the output
+// of round r is the beta of the proof of alpha = r || beta(r-1), by the key of the beacon, so
+// each round is unpredictable before its proof, and the whole history is verifiable from the public
+// key and the genesis seed.
+//
+// Rounds are produced at fixed intervals from the genesis time. Producing nodes hold the key,
+// or prove with a threshold group or a remote key; followers only verify and store the rounds
+// they receive. Gossip is left to the caller, through the callback of WithGossip and Receive.
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// ErrMissingRounds is the error of Receive for a round after a gap: the missing rounds must be
+// fetched from a peer, e.g. by its History, before.
+var ErrMissingRounds = errors.New("beacon: missing rounds")
+
+var (
+	errConfig      = errors.New("beacon: invalid config")
+	errRoundNumber = errors.New("beacon: unexpected round number")
+	errWrongBeta   = errors.New("beacon: beta doesn't match the proof")
+	errConflict    = errors.New("beacon: conflicting round")
+	errNoProver    = errors.New("beacon: no prover")
+)
+
+// Config is the public definition of a beacon, shared by all its nodes and clients.
+type Config struct {
+	VRF       ecvrf.VRF
+	PublicKey *ecdsa.PublicKey
+	// Genesis is the seed in place of the beta of round 0.
+	Genesis []byte
+	// GenesisTime is the time of round 1, and Period the interval between two rounds.
+	GenesisTime time.Time
+	Period      time.Duration
+}
+
+// Round is a round of the beacon.
+type Round struct {
+	Number uint64 `json:"round"`
+	Beta   []byte `json:"beta"`
+	Pi     []byte `json:"pi"`
+}
+
+// Alpha returns the input of the round: the round number in 8 big-endian octets, then the beta of
+// the previous round.
+func Alpha(round uint64, prev []byte) []byte {
+	alpha := make([]byte, 8, 8+len(prev))
+	binary.BigEndian.PutUint64(alpha, round)
+	return append(alpha, prev...)
+}
+
+// TimeOf returns the time of the round.
+func (c *Config) TimeOf(round uint64) time.Time {
+	if round == 0 {
+		return c.GenesisTime.Add(-c.Period)
+	}
+	return c.GenesisTime.Add(time.Duration(round-1) * c.Period)
+}
+
+// RoundAt returns the latest round due at t, 0 before the genesis time.
+func (c *Config) RoundAt(t time.Time) uint64 {
+	if t.Before(c.GenesisTime) {
+		return 0
+	}
+	return uint64(t.Sub(c.GenesisTime)/c.Period) + 1
+}
+
+// VerifyRound verifies the round r following the round of output prev, the genesis seed for round 1.
+func (c *Config) VerifyRound(prev []byte, r *Round) error {
+	if r.Number == 0 {
+		return errRoundNumber
+	}
+	beta, err := c.VRF.Verify(c.PublicKey, Alpha(r.Number, prev), r.Pi)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(beta, r.Beta) {
+		return errWrongBeta
+	}
+	return nil
+}
+
+// VerifyHistory verifies the consecutive rounds following the round of output prev, e.g. the
+// genesis seed for a history from round 1, or the beta of a trusted checkpoint.
+func (c *Config) VerifyHistory(prev []byte, rounds []*Round) error {
+	for i, r := range rounds {
+		if i > 0 && r.Number != rounds[i-1].Number+1 {
+			return errRoundNumber
+		}
+		if err := c.VerifyRound(prev, r); err != nil {
+			return err
+		}
+		prev = r.Beta
+	}
+	return nil
+}
+
+// ProveFunc proves alpha with the key of the beacon.
+type ProveFunc func(alpha []byte) (beta, pi []byte, err error)
+
+// Beacon is a node of the beacon, which stores the rounds from round 1, and produces them if it
+// has a ProveFunc. It's safe for concurrent use.
+type Beacon struct {
+	cfg    Config
+	prove  ProveFunc
+	gossip func(*Round)
+	now    func() time.Time
+
+	mu     sync.Mutex
+	rounds []*Round
+	// gossipMu is taken before mu is released, so rounds are gossiped in order without blocking mu
+	gossipMu sync.Mutex
+}
+
+// Option configures a Beacon.
+type Option func(*Beacon)
+
+// WithProver makes the node produce the rounds with prove, e.g. the Prove of the VRF with the key.
+func WithProver(prove ProveFunc) Option {
+	return func(b *Beacon) {
+		b.prove = prove
+	}
+}
+
+// WithGossip sets the callback called with each new round, produced or received, to send it to
+// the peers. It's called in the order of the rounds, without blocking the reads of the node.
+func WithGossip(gossip func(*Round)) Option {
+	return func(b *Beacon) {
+		b.gossip = gossip
+	}
+}
+
+// WithClock sets the clock giving the due rounds, time.Now by default, e.g. a clock synchronized
+// with the other nodes.
+func WithClock(now func() time.Time) Option {
+	return func(b *Beacon) {
+		b.now = now
+	}
+}
+
+// New creates a node of the beacon of cfg.
+func New(cfg Config, opts ...Option) (*Beacon, error) {
+	if cfg.VRF == nil || cfg.PublicKey == nil || cfg.Period <= 0 {
+		return nil, errConfig
+	}
+	if err := cfg.VRF.ValidatePublicKey(cfg.PublicKey); err != nil {
+		return nil, err
+	}
+	b := &Beacon{cfg: cfg, now: time.Now}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b, nil
+}
+
+// Latest returns the latest round, or nil if none.
+func (b *Beacon) Latest() *Round {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.rounds) == 0 {
+		return nil
+	}
+	return b.rounds[len(b.rounds)-1]
+}
+
+// Get returns the round, or nil if it's not stored.
+func (b *Beacon) Get(round uint64) *Round {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if round == 0 || round > uint64(len(b.rounds)) {
+		return nil
+	}
+	return b.rounds[round-1]
+}
+
+// History returns the stored rounds from round from, at most n, e.g. to serve a peer catching up.
+func (b *Beacon) History(from uint64, n int) []*Round {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if from == 0 || from > uint64(len(b.rounds)) {
+		return nil
+	}
+	rounds := b.rounds[from-1:]
+	if len(rounds) > n {
+		rounds = rounds[:n]
+	}
+	return append([]*Round(nil), rounds...)
+}
+
+// Receive verifies and stores a round from a peer, and gossips it if it's new. A round already
+// stored is ignored; a round after a gap fails with ErrMissingRounds. Rounds not due yet by the
+// clock are rejected, so a compromised key can't publish future rounds to this node.
+func (b *Beacon) Receive(r *Round) error {
+	if r.Number > b.cfg.RoundAt(b.now()) {
+		return errRoundNumber
+	}
+	return b.append(r)
+}
+
+// Run produces the rounds as they're due, catching up missed ones, until ctx is done.
+// It fails if the node has no prover.
+func (b *Beacon) Run(ctx context.Context) error {
+	if b.prove == nil {
+		return errNoProver
+	}
+	for {
+		next := uint64(1)
+		var prev []byte
+		if last := b.Latest(); last != nil {
+			next, prev = last.Number+1, last.Beta
+		} else {
+			prev = b.cfg.Genesis
+		}
+		if wait := b.cfg.TimeOf(next).Sub(b.now()); wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			case <-t.C:
+			}
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		beta, pi, err := b.prove(Alpha(next, prev))
+		if err != nil {
+			return err
+		}
+		if err := b.append(&Round{Number: next, Beta: beta, Pi: pi}); err != nil {
+			return err
+		}
+	}
+}
+
+// append verifies the round following the latest one, stores it, and gossips it.
+func (b *Beacon) append(r *Round) error {
+	b.mu.Lock()
+	n := uint64(len(b.rounds))
+	switch {
+	case r.Number == 0:
+		b.mu.Unlock()
+		return errRoundNumber
+	case r.Number <= n:
+		stored := b.rounds[r.Number-1]
+		b.mu.Unlock()
+		if !bytes.Equal(stored.Beta, r.Beta) {
+			return errConflict
+		}
+		return nil
+	case r.Number > n+1:
+		b.mu.Unlock()
+		return ErrMissingRounds
+	}
+	prev := b.cfg.Genesis
+	if n > 0 {
+		prev = b.rounds[n-1].Beta
+	}
+	if err := b.cfg.VerifyRound(prev, r); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	b.rounds = append(b.rounds, r)
+	b.gossipMu.Lock()
+	defer b.gossipMu.Unlock()
+	b.mu.Unlock()
+	if b.gossip != nil {
+		b.gossip(r)
+	}
+	return nil
+}
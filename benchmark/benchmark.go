@@ -0,0 +1,122 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package benchmark provides a harness for comparative benchmarking of VRF implementations.
+package benchmark
+
+import (
+	"crypto/ecdsa"
+	"strconv"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// DefaultAlphaSizes are the input sizes, in octets, benchmarked if Harness.AlphaSizes is empty.
+var DefaultAlphaSizes = []int{0, 32, 1024}
+
+// Harness runs the same set of benchmarks over a VRF implementation. Functions left nil are skipped,
+// so implementations of other libraries can be benchmarked by wrapping them.
+type Harness struct {
+	// Prove computes beta and the proof pi of alpha.
+	Prove func(alpha []byte) (beta, pi []byte, err error)
+	// Verify checks the proof pi of alpha.
+	Verify func(alpha, pi []byte) (beta []byte, err error)
+	// HashToCurve maps alpha to a curve point, returned in any encoding.
+	HashToCurve func(alpha []byte) ([]byte, error)
+	// AlphaSizes are the input sizes, in octets. DefaultAlphaSizes is used if empty.
+	AlphaSizes []int
+}
+
+// New creates the harness of v with the key pair sk.
+func New(v ecvrf.VRF, sk *ecdsa.PrivateKey) *Harness {
+	return &Harness{
+		Prove: func(alpha []byte) (beta, pi []byte, err error) {
+			return v.Prove(sk, alpha)
+		},
+		Verify: func(alpha, pi []byte) (beta []byte, err error) {
+			return v.Verify(&sk.PublicKey, alpha, pi)
+		},
+		HashToCurve: func(alpha []byte) ([]byte, error) {
+			x, y, err := v.EncodeToCurve(&sk.PublicKey, alpha)
+			if err != nil {
+				return nil, err
+			}
+			return append(x.Bytes(), y.Bytes()...), nil
+		},
+	}
+}
+
+// Run runs all available benchmarks of the harness.
+func (h *Harness) Run(b *testing.B) {
+	h.RunProve(b)
+	h.RunVerify(b)
+	h.RunHashToCurve(b)
+}
+
+// RunProve runs sub-benchmarks of Prove named prove/alpha=size, reporting allocations.
+func (h *Harness) RunProve(b *testing.B) {
+	if h.Prove == nil {
+		return
+	}
+	h.forSizes(b, "prove", func(b *testing.B, alpha []byte) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := h.Prove(alpha); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// RunVerify runs sub-benchmarks of Verify named verify/alpha=size, reporting allocations.
+// Proofs are created by Prove, out of the measured time.
+func (h *Harness) RunVerify(b *testing.B) {
+	if h.Prove == nil || h.Verify == nil {
+		return
+	}
+	h.forSizes(b, "verify", func(b *testing.B, alpha []byte) {
+		b.StopTimer()
+		_, pi, err := h.Prove(alpha)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := h.Verify(alpha, pi); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// RunHashToCurve runs sub-benchmarks of HashToCurve named hash-to-curve/alpha=size, reporting allocations.
+func (h *Harness) RunHashToCurve(b *testing.B) {
+	if h.HashToCurve == nil {
+		return
+	}
+	h.forSizes(b, "hash-to-curve", func(b *testing.B, alpha []byte) {
+		for i := 0; i < b.N; i++ {
+			if _, err := h.HashToCurve(alpha); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// forSizes runs the benchmark f for each input size.
+func (h *Harness) forSizes(b *testing.B, op string, f func(b *testing.B, alpha []byte)) {
+	sizes := h.AlphaSizes
+	if len(sizes) == 0 {
+		sizes = DefaultAlphaSizes
+	}
+	for _, size := range sizes {
+		alpha := make([]byte, size)
+		for i := range alpha {
+			alpha[i] = byte(i)
+		}
+		b.Run(op+"/alpha="+strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			f(b, alpha)
+		})
+	}
+}
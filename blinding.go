@@ -0,0 +1,168 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// maxMaskAttempts bounds the rejection sampling of blinding masks, so that a broken
+// random source fails instead of looping forever.
+const maxMaskAttempts = 64
+
+var errNoBlindingMask = errors.New("failed to sample blinding mask")
+
+// WithScalarBlinding makes Prove blind the private scalar and the nonce with random masks
+// read from rand, which is usually crypto/rand.Reader. Each multiplication by a secret
+// scalar k computes (k * m^-1) * (m * P), or (k - m) * B + m * B for the base point, and
+// s = (c*x + k) mod q is computed as ((c*(x*m) + k*m) * m^-1) mod q, with a fresh mask m.
+//
+// Blinding doesn't change the proofs. It costs about one additional scalar multiplication
+// per multiplication, and is meant for platforms where the arithmetic can't be trusted to
+// run in constant time.
+func WithScalarBlinding(rand io.Reader) Option {
+	return func(cfg *Config) {
+		cfg.Blinding = rand
+	}
+}
+
+// scalarMask samples a mask uniformly from [1, q) using the blinding source.
+func (c *core) scalarMask() ([]byte, error) {
+	return c.sampleScalar(c.Blinding)
+}
+
+// sampleScalar samples a scalar uniformly from [1, q) from rand.
+func (c *core) sampleScalar(rand io.Reader) ([]byte, error) {
+	var (
+		q     = c.Q()
+		qlen  = (q.BitLen() + 7) / 8
+		qbits = q.FillBytes(make([]byte, qlen))
+		m     = make([]byte, qlen)
+	)
+	for i := 0; i < maxMaskAttempts; i++ {
+		if _, err := io.ReadFull(rand, m); err != nil {
+			wipe(m)
+			return nil, err
+		}
+		m[0] &= 0xff >> uint(8*qlen-q.BitLen())
+		if ctIsZero(m)|(1^ctLess(m, qbits)) == 0 {
+			return m, nil
+		}
+	}
+	wipe(m)
+	return nil, errNoBlindingMask
+}
+
+// BlindScalarMult returns k * pt, where k is a secret big-endian scalar of the length of q.
+// The scalar is blinded if the config has a blinding source.
+func (c *core) BlindScalarMult(pt *point, k []byte) (*point, error) {
+	if c.Blinding == nil {
+		return c.ScalarMult(pt, k), nil
+	}
+	m, err := c.scalarMask()
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(m)
+	mInv := c.invModQ(m)
+	defer wipe(mInv)
+	kb := c.mulModQ(k, mInv)
+	defer wipe(kb)
+
+	// k * pt = (k * m^-1) * (m * pt)
+	return c.ScalarMult(c.ScalarMult(pt, m), kb), nil
+}
+
+// BlindScalarBaseMult returns k * B, where k is a secret big-endian scalar of the length of q.
+// The scalar is blinded if the config has a blinding source.
+func (c *core) BlindScalarBaseMult(k []byte) (*point, error) {
+	if c.Blinding == nil {
+		return c.ScalarBaseMult(k), nil
+	}
+	m, err := c.scalarMask()
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(m)
+	km := c.subModQ(k, m)
+	defer wipe(km)
+
+	// the additive split keeps the precomputed table of B usable: k * B = (k - m) * B + m * B
+	return c.Add(c.ScalarBaseMult(km), c.ScalarBaseMult(m)), nil
+}
+
+// BlindScalarMulAdd is like ScalarMulAdd, and blinds the secret scalars y and z if the config
+// has a blinding source.
+func (c *core) BlindScalarMulAdd(x *big.Int, y, z []byte) (*big.Int, error) {
+	if c.Blinding == nil {
+		return c.ScalarMulAdd(x, y, z), nil
+	}
+	m, err := c.scalarMask()
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(m)
+	ym, zm := c.mulModQ(y, m), c.mulModQ(z, m)
+	defer wipe(ym)
+	defer wipe(zm)
+
+	// (x*y + z) = (x*(y*m) + z*m) * m^-1
+	mInv := c.invModQ(m)
+	defer wipe(mInv)
+	r := c.ScalarMulAdd(x, ym, zm)
+	return new(big.Int).SetBytes(c.mulModQ(fixedOctets(r, len(m)), mInv)), nil
+}
+
+// mulModQ returns (x * y) mod q for big-endian scalars of the length of q.
+func (c *core) mulModQ(x, y []byte) []byte {
+	fq := scalarField(c.curve.Params())
+	if fq == nil {
+		r := new(big.Int).Mul(new(big.Int).SetBytes(x), new(big.Int).SetBytes(y))
+		return fixedOctets(r.Mod(r, c.Q()), len(x))
+	}
+	fx, fy := limbsFromShortBytes(x), limbsFromShortBytes(y)
+	fq.fromLimbs(&fx, &fx)
+	fq.fromLimbs(&fy, &fy)
+	fq.mul(&fx, &fx, &fy)
+	return scalarOctets(fq, &fx, len(x))
+}
+
+// subModQ returns (x - y) mod q for big-endian scalars of the length of q.
+func (c *core) subModQ(x, y []byte) []byte {
+	fq := scalarField(c.curve.Params())
+	if fq == nil {
+		r := new(big.Int).Sub(new(big.Int).SetBytes(x), new(big.Int).SetBytes(y))
+		return fixedOctets(r.Mod(r, c.Q()), len(x))
+	}
+	fx, fy := limbsFromShortBytes(x), limbsFromShortBytes(y)
+	fq.fromLimbs(&fx, &fx)
+	fq.fromLimbs(&fy, &fy)
+	fq.sub(&fx, &fx, &fy)
+	return scalarOctets(fq, &fx, len(x))
+}
+
+// invModQ returns x^-1 mod q for a nonzero big-endian scalar of the length of q.
+func (c *core) invModQ(x []byte) []byte {
+	fq := scalarField(c.curve.Params())
+	if fq == nil {
+		r := new(big.Int).ModInverse(new(big.Int).SetBytes(x), c.Q())
+		return fixedOctets(r, len(x))
+	}
+	fx := limbsFromShortBytes(x)
+	fq.fromLimbs(&fx, &fx)
+	fq.inv(&fx, &fx)
+	return scalarOctets(fq, &fx, len(x))
+}
+
+// scalarOctets encodes the scalar x in Montgomery form as a big-endian integer of qlen octets.
+func scalarOctets(fq *field, x *fieldElement, qlen int) []byte {
+	var buf [32]byte
+	fq.bytes(&buf, x)
+	out := make([]byte, qlen)
+	copy(out, buf[32-qlen:])
+	wipe(buf[:])
+	return out
+}
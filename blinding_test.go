@@ -0,0 +1,85 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestCoreBlinding(t *testing.T) {
+	tests := []struct {
+		curve  elliptic.Curve
+		newVRF func(...Option) VRF
+	}{
+		{elliptic.P256(), NewP256Sha256Tai},
+		{elliptic.P384(), NewP256Sha256Tai},
+		{secp256k1, NewSecp256k1Sha256Tai},
+	}
+	for _, tt := range tests {
+		curve := tt.curve
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			var (
+				c     = tt.newVRF(WithScalarBlinding(rand.Reader)).(*vrf).newCore(curve)
+				plain = tt.newVRF().(*vrf).newCore(curve)
+				q     = curve.Params().N
+				g     = &point{curve.Params().Gx, curve.Params().Gy}
+			)
+			defer c.release()
+			defer plain.release()
+			for i := 0; i < 10; i++ {
+				xi, _ := rand.Int(rand.Reader, q)
+				yi, _ := rand.Int(rand.Reader, q)
+				zi, _ := rand.Int(rand.Reader, q)
+				y, z := c.SecretOctets(yi), c.SecretOctets(zi)
+
+				got, err := c.BlindScalarMulAdd(xi, y, z)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := plain.ScalarMulAdd(xi, y, z); got.Cmp(want) != 0 {
+					t.Fatalf("BlindScalarMulAdd() = %v, want %v", got, want)
+				}
+
+				p, err := c.BlindScalarMult(g, y)
+				if err != nil {
+					t.Fatal(err)
+				}
+				b, err := c.BlindScalarBaseMult(y)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := plain.ScalarBaseMult(y)
+				if !bytes.Equal(c.Marshal(p), c.Marshal(want)) || !bytes.Equal(c.Marshal(b), c.Marshal(want)) {
+					t.Fatal("blinded multiplications differ from ScalarBaseMult")
+				}
+			}
+		})
+	}
+}
+
+func TestCoreScalarMask(t *testing.T) {
+	c := &core{Config: &Config{Blinding: bytes.NewReader(make([]byte, 1<<12))}, curve: secp256k1}
+	if _, err := c.scalarMask(); err != errNoBlindingMask {
+		t.Fatalf("scalarMask() of zeros error = %v, want %v", err, errNoBlindingMask)
+	}
+	c.Blinding = bytes.NewReader(nil)
+	if _, err := c.scalarMask(); err == nil {
+		t.Fatal("scalarMask() of an empty source succeeded")
+	}
+
+	// an in-range value is accepted as the mask
+	c.Blinding = bytes.NewReader(bytes.Repeat([]byte{0xfe}, 32))
+	c.curve = elliptic.P256()
+	m, err := c.scalarMask()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := new(big.Int).SetBytes(m); v.Sign() == 0 || v.Cmp(c.Q()) >= 0 {
+		t.Fatalf("scalarMask() = %x, out of range", m)
+	}
+}
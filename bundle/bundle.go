@@ -0,0 +1,147 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package bundle distributes many VRF outputs of a key as one bundle of (alpha, pi, beta) entries,
+// committed by the Merkle root of the entries: once the root is published, e.g. on chain or signed,
+// a light client checks any single entry with its inclusion proof and its VRF proof, without
+// fetching the bundle.
+//
+// The Merkle tree is the one of RFC 6962 with SHA-256, as for the participants of the lottery
+// package. A leaf is the entry with the lengths of alpha and pi in 4 big-endian octets before them:
+// len(alpha) || alpha || len(pi) || pi || beta.
+package bundle
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/internal/merkle"
+)
+
+var (
+	errNoEntries = errors.New("bundle: no entries")
+	errIndex     = errors.New("bundle: index out of range")
+	errRoot      = errors.New("bundle: root doesn't match the entries")
+	errInclusion = errors.New("bundle: entry not included in the root")
+	errBeta      = errors.New("bundle: beta doesn't match the proof")
+)
+
+// Entry is an evaluation of a bundle.
+type Entry struct {
+	Alpha []byte `json:"alpha"`
+	Pi    []byte `json:"pi"`
+	Beta  []byte `json:"beta"`
+}
+
+// Bundle is a batch of entries of a key, with their Merkle root.
+type Bundle struct {
+	Root    []byte  `json:"root"`
+	Entries []Entry `json:"entries"`
+}
+
+// EntryProof is an entry of a bundle with its inclusion proof, for a light client.
+type EntryProof struct {
+	Entry Entry `json:"entry"`
+	// Index is the index of the entry in the bundle of Size entries.
+	Index int `json:"index"`
+	Size  int `json:"size"`
+	// Path is the audit path of the entry, from the leaf to the root.
+	Path [][]byte `json:"path"`
+}
+
+// New proves the inputs with sk, see ecvrf.ProveBatch for the options, and commits the entries.
+func New(v ecvrf.VRF, sk *ecdsa.PrivateKey, alphas [][]byte, opts ...ecvrf.BatchOption) (*Bundle, error) {
+	if len(alphas) == 0 {
+		return nil, errNoEntries
+	}
+	results, err := ecvrf.ProveBatch(v, sk, alphas, opts...)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, len(alphas))
+	for i, r := range results {
+		entries[i] = Entry{Alpha: append([]byte(nil), alphas[i]...), Pi: r.Pi, Beta: r.Beta}
+	}
+	return &Bundle{Root: Root(entries), Entries: entries}, nil
+}
+
+// Root returns the Merkle root of the entries.
+func Root(entries []Entry) []byte {
+	return merkle.Root(leaves(entries))
+}
+
+// Proof returns the entry at index i with its inclusion proof.
+func (b *Bundle) Proof(i int) (*EntryProof, error) {
+	if i < 0 || i >= len(b.Entries) {
+		return nil, errIndex
+	}
+	return &EntryProof{
+		Entry: b.Entries[i],
+		Index: i,
+		Size:  len(b.Entries),
+		Path:  merkle.InclusionProof(leaves(b.Entries), i),
+	}, nil
+}
+
+// Verify checks the whole bundle against the public key: the root, and the proof and beta of every
+// entry, see ecvrf.VerifyBatch for the options.
+func Verify(v ecvrf.VRF, pk *ecdsa.PublicKey, b *Bundle, opts ...ecvrf.BatchOption) error {
+	if len(b.Entries) == 0 {
+		return errNoEntries
+	}
+	if !bytes.Equal(Root(b.Entries), b.Root) {
+		return errRoot
+	}
+	items := make([]ecvrf.BatchItem, len(b.Entries))
+	for i, e := range b.Entries {
+		items[i] = ecvrf.BatchItem{PublicKey: pk, Alpha: e.Alpha, Pi: e.Pi}
+	}
+	results, err := ecvrf.VerifyBatch(v, items, opts...)
+	if err != nil {
+		return err
+	}
+	for i, r := range results {
+		if !bytes.Equal(r.Beta, b.Entries[i].Beta) {
+			return errBeta
+		}
+	}
+	return nil
+}
+
+// VerifyEntry checks that the entry of p is in the bundle of the root and that its proof is valid
+// for the public key, and returns its beta.
+func VerifyEntry(v ecvrf.VRF, pk *ecdsa.PublicKey, root []byte, p *EntryProof) ([]byte, error) {
+	if !merkle.VerifyInclusion(root, leaf(&p.Entry), p.Index, p.Size, p.Path) {
+		return nil, errInclusion
+	}
+	beta, err := v.Verify(pk, p.Entry.Alpha, p.Entry.Pi)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(beta, p.Entry.Beta) {
+		return nil, errBeta
+	}
+	return beta, nil
+}
+
+func leaves(entries []Entry) [][]byte {
+	l := make([][]byte, len(entries))
+	for i := range entries {
+		l[i] = leaf(&entries[i])
+	}
+	return l
+}
+
+// leaf encodes the entry: len(alpha) || alpha || len(pi) || pi || beta.
+func leaf(e *Entry) []byte {
+	var n [4]byte
+	b := make([]byte, 0, 8+len(e.Alpha)+len(e.Pi)+len(e.Beta))
+	binary.BigEndian.PutUint32(n[:], uint32(len(e.Alpha)))
+	b = append(append(b, n[:]...), e.Alpha...)
+	binary.BigEndian.PutUint32(n[:], uint32(len(e.Pi)))
+	b = append(append(b, n[:]...), e.Pi...)
+	return append(b, e.Beta...)
+}
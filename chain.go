@@ -0,0 +1,127 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var (
+	errChainIndex = errors.New("unexpected chain index")
+	errChainBeta  = errors.New("beta of the chain doesn't match the proof")
+	errChainLink  = errors.New("invalid chain link")
+)
+
+// Chain is a sequence of outputs of a key from a seed, e.g. a stream of randomness per slot: beta_0
+// is the seed, and beta_(i+1) is the output of ChainAlpha(i, beta_i). Each output is unpredictable
+// without the key before its proof, and a verifier holding only the public key and the seed checks
+// the whole sequence. A Chain isn't safe for concurrent use.
+type Chain struct {
+	v     VRF
+	sk    *ecdsa.PrivateKey
+	index uint64
+	beta  []byte
+}
+
+// ChainLink is an output of a chain: Beta is beta_Index, and Pi its proof.
+type ChainLink struct {
+	Index uint64 `json:"index"`
+	Beta  []byte `json:"beta"`
+	Pi    []byte `json:"pi"`
+}
+
+// Checkpoint is a position of a chain, from which chains resume and verifiers start: beta_Index.
+// The seed is the checkpoint of index 0.
+type Checkpoint struct {
+	Index uint64 `json:"index"`
+	Beta  []byte `json:"beta"`
+}
+
+// ChainAlpha returns the input of the output following beta_i: beta_i || i, i in 8 big-endian octets.
+func ChainAlpha(i uint64, beta []byte) []byte {
+	alpha := make([]byte, len(beta), len(beta)+8)
+	copy(alpha, beta)
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], i)
+	return append(alpha, n[:]...)
+}
+
+// NewChain returns the chain of sk from the seed.
+func NewChain(v VRF, sk *ecdsa.PrivateKey, seed []byte) *Chain {
+	return ResumeChain(v, sk, Checkpoint{0, seed})
+}
+
+// ResumeChain returns the chain of sk at the checkpoint, e.g. one saved by Checkpoint before a restart.
+func ResumeChain(v VRF, sk *ecdsa.PrivateKey, cp Checkpoint) *Chain {
+	return &Chain{v, sk, cp.Index, append([]byte(nil), cp.Beta...)}
+}
+
+// Next proves the next output of the chain, and moves to it.
+func (c *Chain) Next() (*ChainLink, error) {
+	beta, pi, err := c.v.Prove(c.sk, ChainAlpha(c.index, c.beta))
+	if err != nil {
+		return nil, err
+	}
+	c.index++
+	c.beta = beta
+	return &ChainLink{Index: c.index, Beta: beta, Pi: pi}, nil
+}
+
+// Checkpoint returns the current position of the chain.
+func (c *Chain) Checkpoint() Checkpoint {
+	return Checkpoint{c.index, append([]byte(nil), c.beta...)}
+}
+
+// VerifyChain checks the consecutive links following the checkpoint, e.g. the seed, against the public
+// key, and returns the checkpoint of the last link, from which the next links are verified. The
+// proofs are verified independently, with the betas claimed by the links, so the options of
+// VerifyBatch are taken to verify them in parallel.
+func VerifyChain(v VRF, pk *ecdsa.PublicKey, from Checkpoint, links []*ChainLink, opts ...BatchOption) (Checkpoint, error) {
+	// the indices of the links must not wrap around
+	if uint64(len(links)) > math.MaxUint64-from.Index {
+		return from, errChainIndex
+	}
+	prev := from.Beta
+	for i, l := range links {
+		if l == nil {
+			return from, errChainLink
+		}
+		if l.Index != from.Index+uint64(i)+1 {
+			return from, errChainIndex
+		}
+		prev = l.Beta
+	}
+	var (
+		vr   = v.NewVerifier(pk)
+		errs = make([]error, len(links))
+	)
+	runBatch(context.Background(), len(links), newBatchConfig(opts), func(_, i int) error {
+		prev := from.Beta
+		if i > 0 {
+			prev = links[i-1].Beta
+		}
+		beta, err := vr.Verify(ChainAlpha(links[i].Index-1, prev), links[i].Pi)
+		if err == nil && !bytes.Equal(beta, links[i].Beta) {
+			err = errChainBeta
+		}
+		errs[i] = err
+		return err
+	}, func(i int, err error) {
+		errs[i] = err
+	})
+	for _, err := range errs {
+		if err != nil && err != ErrBatchCanceled {
+			return from, err
+		}
+	}
+	if len(links) == 0 {
+		return from, nil
+	}
+	return Checkpoint{links[len(links)-1].Index, append([]byte(nil), prev...)}, nil
+}
@@ -0,0 +1,399 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Command ecvrf generates keys, proves and verifies VRF outputs, and generates test vectors
+// with the suites of go-ecvrf, so proofs can be tested and debugged without writing Go.
+//
+//	ecvrf keygen  [-suite s]
+//	ecvrf prove   [-suite s] [-spec v] -sk KEY -alpha ALPHA
+//	ecvrf verify  [-suite s] [-spec v] -pk KEY -alpha ALPHA -pi PROOF
+//	ecvrf beta    [-suite s] [-spec v] -pi PROOF
+//	ecvrf vectors [-suite s] [-spec v] [-n N] [-seed SEED]
+//	ecvrf audit   -log FILE [-csv FILE]
+//
+// Keys, proofs and outputs are hex encoded, or base64 with -format base64. The input alpha is
+// the text of the flag, or decoded with -alpha-format hex or base64. verify exits with status 1
+// if the proof is invalid, audit if the log of package auditlog is broken or has conflicts.
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/auditlog"
+)
+
+const usage = `usage: ecvrf <command> [flags]
+
+commands:
+  keygen   generate a key pair
+  prove    compute the output and proof of an input
+  verify   verify a proof and print its output
+  beta     print the output of a proof without verifying it
+  vectors  generate test vectors from a seed
+  audit    verify an audit log of proofs, and export it
+
+run 'ecvrf <command> -h' for the flags of a command
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// options are the flags shared by the commands.
+type options struct {
+	suite       string
+	spec        string
+	format      string
+	alphaFormat string
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.suite, "suite", "secp256k1", "suite: "+strings.Join(suiteNames(), ", "))
+	fs.StringVar(&o.spec, "spec", "draft06", "spec version of p256 and secp256k1: draft06, rfc9381")
+	fs.StringVar(&o.format, "format", "hex", "encoding of keys, proofs and outputs: hex, base64")
+	fs.StringVar(&o.alphaFormat, "alpha-format", "text", "encoding of alpha: text, hex, base64")
+}
+
+func (o *options) newSuite() (suite, error) {
+	var spec ecvrf.SpecVersion
+	switch o.spec {
+	case "draft06":
+		spec = ecvrf.Draft06
+	case "rfc9381":
+		spec = ecvrf.RFC9381
+	default:
+		return nil, fmt.Errorf("unknown spec version %q", o.spec)
+	}
+	s, ok := newSuite(o.suite, spec)
+	if !ok {
+		return nil, fmt.Errorf("unknown suite %q", o.suite)
+	}
+	return s, nil
+}
+
+func (o *options) decode(name, s string) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+	switch o.format {
+	case "hex":
+		b, err = hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	case "base64":
+		b, err = base64.StdEncoding.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("unknown format %q", o.format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s: %v", name, err)
+	}
+	return b, nil
+}
+
+func (o *options) encode(b []byte) string {
+	if o.format == "base64" {
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (o *options) decodeAlpha(s string) ([]byte, error) {
+	switch o.alphaFormat {
+	case "text":
+		return []byte(s), nil
+	case "hex":
+		return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	case "base64":
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return nil, fmt.Errorf("unknown alpha format %q", o.alphaFormat)
+}
+
+var (
+	errInvalidProof = errors.New("invalid proof")
+	errAuditFailed  = errors.New("audit failed")
+)
+
+// run executes the command of args, and returns the exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "ecvrf: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+	var (
+		fs   = flag.NewFlagSet("ecvrf "+args[0], flag.ContinueOnError)
+		opts options
+	)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	exec := cmd(fs, &opts)
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if err := exec(stdout); err != nil {
+		fmt.Fprintf(stderr, "ecvrf %s: %v\n", args[0], err)
+		if err == errInvalidProof || err == errAuditFailed {
+			return 1
+		}
+		return 2
+	}
+	return 0
+}
+
+// commands register their own flags, and return the function executing them after parsing.
+var commands = map[string]func(fs *flag.FlagSet, o *options) func(w io.Writer) error{
+	"keygen":  keygenCmd,
+	"prove":   proveCmd,
+	"verify":  verifyCmd,
+	"beta":    betaCmd,
+	"vectors": vectorsCmd,
+	"audit":   auditCmd,
+}
+
+func keygenCmd(fs *flag.FlagSet, o *options) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		s, err := o.newSuite()
+		if err != nil {
+			return err
+		}
+		sk, err := s.keygen(rand.Reader)
+		if err != nil {
+			return err
+		}
+		pk, err := s.public(sk)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "sk: %s\npk: %s\n", o.encode(sk), o.encode(pk))
+		return nil
+	}
+}
+
+func proveCmd(fs *flag.FlagSet, o *options) func(w io.Writer) error {
+	var (
+		skFlag    = fs.String("sk", "", "secret key: the scalar, or the seed of Ed25519")
+		alphaFlag = fs.String("alpha", "", "input alpha")
+	)
+	return func(w io.Writer) error {
+		s, err := o.newSuite()
+		if err != nil {
+			return err
+		}
+		sk, err := o.decode("sk", *skFlag)
+		if err != nil {
+			return err
+		}
+		alpha, err := o.decodeAlpha(*alphaFlag)
+		if err != nil {
+			return err
+		}
+		beta, pi, err := s.prove(sk, alpha)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "beta: %s\npi: %s\n", o.encode(beta), o.encode(pi))
+		return nil
+	}
+}
+
+func verifyCmd(fs *flag.FlagSet, o *options) func(w io.Writer) error {
+	var (
+		pkFlag    = fs.String("pk", "", "public key: the compressed point, or the Ed25519 public key")
+		alphaFlag = fs.String("alpha", "", "input alpha")
+		piFlag    = fs.String("pi", "", "proof")
+	)
+	return func(w io.Writer) error {
+		s, err := o.newSuite()
+		if err != nil {
+			return err
+		}
+		pk, err := o.decode("pk", *pkFlag)
+		if err != nil {
+			return err
+		}
+		pi, err := o.decode("pi", *piFlag)
+		if err != nil {
+			return err
+		}
+		alpha, err := o.decodeAlpha(*alphaFlag)
+		if err != nil {
+			return err
+		}
+		beta, err := s.verify(pk, alpha, pi)
+		if err != nil {
+			if err == errInvalidKey {
+				return err
+			}
+			fmt.Fprintf(w, "invalid: %v\n", err)
+			return errInvalidProof
+		}
+		fmt.Fprintf(w, "beta: %s\n", o.encode(beta))
+		return nil
+	}
+}
+
+func betaCmd(fs *flag.FlagSet, o *options) func(w io.Writer) error {
+	piFlag := fs.String("pi", "", "proof")
+	return func(w io.Writer) error {
+		s, err := o.newSuite()
+		if err != nil {
+			return err
+		}
+		pi, err := o.decode("pi", *piFlag)
+		if err != nil {
+			return err
+		}
+		beta, err := s.proofToHash(pi)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "beta: %s\n", o.encode(beta))
+		return nil
+	}
+}
+
+// vector is a test vector, in the format of the files of the vectors module.
+type vector struct {
+	Sk    string `json:"sk"`
+	Pk    string `json:"pk"`
+	Alpha string `json:"alpha"`
+	Pi    string `json:"pi"`
+	Beta  string `json:"beta"`
+}
+
+// seedReader is the stream SHA256(seed || counter) of 64-bit big-endian counters.
+type seedReader struct {
+	seed []byte
+	ctr  uint64
+	buf  []byte
+}
+
+func (r *seedReader) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		if len(r.buf) == 0 {
+			var c [8]byte
+			binary.BigEndian.PutUint64(c[:], r.ctr)
+			r.ctr++
+			h := sha256.Sum256(append(append([]byte{}, r.seed...), c[:]...))
+			r.buf = h[:]
+		}
+		k := copy(p[n:], r.buf)
+		r.buf = r.buf[k:]
+		n += k
+	}
+	return len(p), nil
+}
+
+func vectorsCmd(fs *flag.FlagSet, o *options) func(w io.Writer) error {
+	var (
+		n    = fs.Int("n", 10, "number of vectors")
+		seed = fs.String("seed", "go-ecvrf", "seed of the keys and inputs")
+	)
+	return func(w io.Writer) error {
+		s, err := o.newSuite()
+		if err != nil {
+			return err
+		}
+		var (
+			r     = &seedReader{seed: []byte(*seed)}
+			sizes = []int{0, 1, 32, 100}
+			out   = make([]vector, 0, *n)
+		)
+		for i := 0; i < *n; i++ {
+			sk, err := s.keygen(r)
+			if err != nil {
+				return err
+			}
+			pk, err := s.public(sk)
+			if err != nil {
+				return err
+			}
+			alpha := make([]byte, sizes[i%len(sizes)])
+			r.Read(alpha)
+			beta, pi, err := s.prove(sk, alpha)
+			if err != nil {
+				return err
+			}
+			out = append(out, vector{
+				Sk:    hex.EncodeToString(sk),
+				Pk:    hex.EncodeToString(pk),
+				Alpha: hex.EncodeToString(alpha),
+				Pi:    hex.EncodeToString(pi),
+				Beta:  hex.EncodeToString(beta),
+			})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(out)
+	}
+}
+
+func auditCmd(fs *flag.FlagSet, o *options) func(w io.Writer) error {
+	var (
+		logFlag = fs.String("log", "", "audit log, - for the standard input")
+		csvFlag = fs.String("csv", "", "file to export the entries to as CSV")
+	)
+	return func(w io.Writer) error {
+		var r io.Reader = os.Stdin
+		if *logFlag == "" {
+			return errors.New("missing -log")
+		} else if *logFlag != "-" {
+			f, err := os.Open(*logFlag)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		var emit []func(*auditlog.Entry)
+		if *csvFlag != "" {
+			f, err := os.Create(*csvFlag)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			cw := csv.NewWriter(f)
+			defer cw.Flush()
+			cw.Write([]string{"seq", "time", "key", "slot", "alpha_sha256", "pi_sha256", "hash"})
+			emit = append(emit, func(e *auditlog.Entry) {
+				cw.Write([]string{strconv.FormatUint(e.Seq, 10), e.Time.Format(time.RFC3339Nano), e.Key, e.Slot, e.AlphaSHA256, e.PiSHA256, e.Hash})
+			})
+		}
+		report, err := auditlog.Verify(r, emit...)
+		if err != nil {
+			if _, ok := err.(*auditlog.ChainError); !ok {
+				return err
+			}
+			fmt.Fprintf(w, "invalid: %v\n", err)
+			return errAuditFailed
+		}
+		fmt.Fprintf(w, "entries: %d\nhead: %x\nconflicts: %d\n", report.Entries, report.Head, len(report.Conflicts))
+		for _, c := range report.Conflicts {
+			fmt.Fprintf(w, "conflict: key %s slot %q entries %d and %d\n", c.First.Key, c.First.Slot, c.First.Seq, c.Second.Seq)
+		}
+		if len(report.Conflicts) > 0 {
+			return errAuditFailed
+		}
+		return nil
+	}
+}
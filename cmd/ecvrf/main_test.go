@@ -0,0 +1,118 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/auditlog"
+)
+
+// fields runs the command and parses its "name: value" lines.
+func fields(t *testing.T, wantStatus int, args ...string) map[string]string {
+	var stdout, stderr bytes.Buffer
+	if status := run(args, &stdout, &stderr); status != wantStatus {
+		t.Fatalf("run(%q) = %d, want %d: %s", args, status, wantStatus, stderr.String())
+	}
+	out := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		if kv := strings.SplitN(line, ": ", 2); len(kv) == 2 {
+			out[kv[0]] = kv[1]
+		}
+	}
+	return out
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, suite := range suiteNames() {
+		for _, format := range []string{"hex", "base64"} {
+			common := []string{"-suite", suite, "-format", format, "-spec", "rfc9381"}
+			keys := fields(t, 0, append([]string{"keygen"}, common...)...)
+			proof := fields(t, 0, append([]string{"prove", "-sk", keys["sk"], "-alpha", "sample"}, common...)...)
+			verified := fields(t, 0, append([]string{"verify", "-pk", keys["pk"], "-alpha", "sample", "-pi", proof["pi"]}, common...)...)
+			if verified["beta"] != proof["beta"] {
+				t.Fatalf("%s: verify beta = %v, want %v", suite, verified["beta"], proof["beta"])
+			}
+			if got := fields(t, 0, append([]string{"beta", "-pi", proof["pi"]}, common...)...); got["beta"] != proof["beta"] {
+				t.Fatalf("%s: beta = %v, want %v", suite, got["beta"], proof["beta"])
+			}
+			fields(t, 1, append([]string{"verify", "-pk", keys["pk"], "-alpha", "other", "-pi", proof["pi"]}, common...)...)
+		}
+	}
+}
+
+func TestVectors(t *testing.T) {
+	// Example 10 of RFC9381 B.1
+	got := fields(t, 0, "prove", "-sk", "c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721",
+		"-alpha", "73616d706c65", "-alpha-format", "hex", "-suite", "p256", "-spec", "rfc9381")
+	if want := "a3ad7b0ef73d8fc6655053ea22f9bede8c743f08bbed3d38821f0e16474b505e"; got["beta"] != want {
+		t.Fatalf("beta = %v, want %v", got["beta"], want)
+	}
+
+	var a, b bytes.Buffer
+	run([]string{"vectors", "-n", "3", "-seed", "x"}, &a, &a)
+	run([]string{"vectors", "-n", "3", "-seed", "x"}, &b, &b)
+	var vs []vector
+	if err := json.Unmarshal(a.Bytes(), &vs); err != nil || len(vs) != 3 || a.String() != b.String() {
+		t.Fatalf("vectors = %s, %v", a.String(), err)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		file   = filepath.Join(dir, "audit.log")
+		export = filepath.Join(dir, "audit.csv")
+		buf    bytes.Buffer
+		log    = auditlog.New(&buf)
+		vrf    = ecvrf.NewP256Sha256Tai()
+		sk, _  = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	)
+	for _, alpha := range []string{"slot 1", "slot 2"} {
+		_, pi, _ := vrf.Prove(sk, []byte(alpha))
+		log.Record(&sk.PublicKey, alpha, []byte(alpha), pi)
+	}
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got := fields(t, 0, "audit", "-log", file, "-csv", export)
+	if got["entries"] != "2" || got["conflicts"] != "0" {
+		t.Fatalf("audit = %v", got)
+	}
+	if data, err := ioutil.ReadFile(export); err != nil || strings.Count(string(data), "\n") != 3 {
+		t.Fatalf("export = %q, %v", data, err)
+	}
+
+	// a second input for slot 1 conflicts
+	log.Record(&sk.PublicKey, "slot 1", []byte("other"), nil)
+	ioutil.WriteFile(file, buf.Bytes(), 0600)
+	if got := fields(t, 1, "audit", "-log", file); got["conflicts"] != "1" {
+		t.Fatalf("audit = %v", got)
+	}
+
+	// a dropped entry breaks the chain
+	lines := strings.SplitAfter(buf.String(), "\n")
+	ioutil.WriteFile(file, []byte(lines[0]+lines[2]), 0600)
+	if got := fields(t, 1, "audit", "-log", file); got["invalid"] == "" {
+		t.Fatalf("audit = %v", got)
+	}
+	fields(t, 2, "audit")
+	fields(t, 2, "audit", "-log", filepath.Join(dir, "missing"))
+}
+
+func TestUsage(t *testing.T) {
+	fields(t, 2)
+	fields(t, 2, "unknown")
+	fields(t, 2, "prove", "-suite", "p384")
+	fields(t, 2, "prove", "-sk", "zz")
+}
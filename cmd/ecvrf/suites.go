@@ -0,0 +1,173 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+var errInvalidKey = errors.New("invalid key")
+
+// suite wraps the VRF objects of the library behind keys in their octet encodings: secret scalars
+// and compressed points for the Weierstrass suites, seeds and public keys for Ed25519.
+type suite interface {
+	keygen(rand io.Reader) (sk []byte, err error)
+	public(sk []byte) (pk []byte, err error)
+	prove(sk, alpha []byte) (beta, pi []byte, err error)
+	verify(pk, alpha, pi []byte) (beta []byte, err error)
+	proofToHash(pi []byte) (beta []byte, err error)
+}
+
+type ecdsaSuite struct {
+	curve elliptic.Curve
+	vrf   ecvrf.VRF
+}
+
+// keygen draws the scalar from rand by rejection sampling, so that vectors can be derived from a seed.
+func (s *ecdsaSuite) keygen(rand io.Reader) ([]byte, error) {
+	n := s.curve.Params().N
+	b := make([]byte, (n.BitLen()+7)/8)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		if d := new(big.Int).SetBytes(b); d.Sign() > 0 && d.Cmp(n) < 0 {
+			return b, nil
+		}
+	}
+}
+
+func (s *ecdsaSuite) privateKey(b []byte) (*ecdsa.PrivateKey, error) {
+	d := new(big.Int).SetBytes(b)
+	if len(b) != (s.curve.Params().N.BitLen()+7)/8 || d.Sign() == 0 || d.Cmp(s.curve.Params().N) >= 0 {
+		return nil, errInvalidKey
+	}
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = s.curve
+	sk.X, sk.Y = s.curve.ScalarBaseMult(b)
+	return sk, nil
+}
+
+func (s *ecdsaSuite) public(b []byte) ([]byte, error) {
+	sk, err := s.privateKey(b)
+	if err != nil {
+		return nil, err
+	}
+	return elliptic.MarshalCompressed(s.curve, sk.X, sk.Y), nil
+}
+
+func (s *ecdsaSuite) publicKey(b []byte) (*ecdsa.PublicKey, error) {
+	var x, y *big.Int
+	if s.curve == secp256k1.S256() {
+		pk, err := secp256k1.ParsePubKey(b)
+		if err != nil {
+			return nil, err
+		}
+		return pk.ToECDSA(), nil
+	}
+	if len(b) > 0 && b[0] == 4 {
+		x, y = elliptic.Unmarshal(s.curve, b)
+	} else {
+		x, y = elliptic.UnmarshalCompressed(s.curve, b)
+	}
+	if x == nil {
+		return nil, errInvalidKey
+	}
+	return &ecdsa.PublicKey{Curve: s.curve, X: x, Y: y}, nil
+}
+
+func (s *ecdsaSuite) prove(b, alpha []byte) ([]byte, []byte, error) {
+	sk, err := s.privateKey(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.vrf.Prove(sk, alpha)
+}
+
+func (s *ecdsaSuite) verify(b, alpha, pi []byte) ([]byte, error) {
+	pk, err := s.publicKey(b)
+	if err != nil {
+		return nil, err
+	}
+	return s.vrf.Verify(pk, alpha, pi)
+}
+
+func (s *ecdsaSuite) proofToHash(pi []byte) ([]byte, error) {
+	return ecvrf.ProofToHash(s.vrf, s.curve, pi)
+}
+
+type ed25519Suite struct {
+	vrf ecvrf.Ed25519VRF
+}
+
+func (s *ed25519Suite) keygen(rand io.Reader) ([]byte, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+func (s *ed25519Suite) privateKey(seed []byte) (ed25519.PrivateKey, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, errInvalidKey
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func (s *ed25519Suite) public(seed []byte) ([]byte, error) {
+	sk, err := s.privateKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	return sk.Public().(ed25519.PublicKey), nil
+}
+
+func (s *ed25519Suite) prove(seed, alpha []byte) ([]byte, []byte, error) {
+	sk, err := s.privateKey(seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.vrf.Prove(sk, alpha)
+}
+
+func (s *ed25519Suite) verify(pk, alpha, pi []byte) ([]byte, error) {
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, errInvalidKey
+	}
+	return s.vrf.Verify(ed25519.PublicKey(pk), alpha, pi)
+}
+
+func (s *ed25519Suite) proofToHash(pi []byte) ([]byte, error) {
+	return s.vrf.ProofToHash(pi)
+}
+
+// newSuite returns the suite of the name. The spec version only applies to the Weierstrass suites,
+// the Ed25519 ones being named by their revision.
+func newSuite(name string, spec ecvrf.SpecVersion) (suite, bool) {
+	switch name {
+	case "p256":
+		return &ecdsaSuite{elliptic.P256(), ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(spec))}, true
+	case "secp256k1":
+		return &ecdsaSuite{secp256k1.S256(), ecvrf.NewSecp256k1Sha256Tai(ecvrf.WithSpecVersion(spec))}, true
+	case "ed25519-draft03":
+		return &ed25519Suite{ecvrf.NewEd25519Sha512Elligator2()}, true
+	case "ed25519":
+		return &ed25519Suite{ecvrf.NewEd25519Sha512Ell2()}, true
+	}
+	return nil, false
+}
+
+// suiteNames lists the names accepted by newSuite.
+func suiteNames() []string {
+	return []string{"p256", "secp256k1", "ed25519", "ed25519-draft03"}
+}
@@ -0,0 +1,13 @@
+module github.com/vechain/go-ecvrf/cmd/vrfvectors
+
+go 1.16
+
+require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.1
+	github.com/vechain/go-ecvrf v0.0.0-20200305101714-4252ed3a3b96
+	github.com/vechain/go-ecvrf/vectors v0.0.0-00010101000000-000000000000
+)
+
+replace github.com/vechain/go-ecvrf => ../../
+
+replace github.com/vechain/go-ecvrf/vectors => ../../vectors
@@ -0,0 +1,277 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vechain/go-ecvrf"
+	ecvrfvectors "github.com/vechain/go-ecvrf/vectors"
+)
+
+// invalidVector is a proof that must be rejected, with the reason it's invalid and the code of
+// the error returned by go-ecvrf.
+type invalidVector struct {
+	Reason string `json:"reason"`
+	Pk     string `json:"pk"`
+	Alpha  string `json:"alpha"`
+	Pi     string `json:"pi"`
+	Code   string `json:"code"`
+}
+
+// errorCodes are the codes of the errors of go-ecvrf in the files.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ecvrf.ErrUnrecognizedPointEncoding, ecvrfvectors.CodeUnrecognizedPointEncoding},
+	{ecvrf.ErrInvalidPointLength, ecvrfvectors.CodeInvalidPointLength},
+	{ecvrf.ErrNotSquare, ecvrfvectors.CodeNotSquare},
+	{ecvrf.ErrNonCanonicalPoint, ecvrfvectors.CodeNonCanonicalPoint},
+	{ecvrf.ErrNotOnCurve, ecvrfvectors.CodeNotOnCurve},
+	{ecvrf.ErrInvalidProofLength, ecvrfvectors.CodeInvalidProofLength},
+	{ecvrf.ErrInvalidProof, ecvrfvectors.CodeInvalidProof},
+	{ecvrf.ErrInvalidProofScalar, ecvrfvectors.CodeInvalidProofScalar},
+	{ecvrf.ErrNonCanonicalProof, ecvrfvectors.CodeNonCanonicalProof},
+}
+
+// errorCode returns the code of err.
+func errorCode(err error) (string, error) {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code, nil
+		}
+	}
+	return "", fmt.Errorf("no code for the error %q", err)
+}
+
+// other is a suite whose proofs of the same key and input must be rejected.
+type other struct {
+	reason string
+	prove  func(sk, alpha []byte) (pi []byte, err error)
+}
+
+// decoded is a vector decoded from hex.
+type decoded struct {
+	sk, pk, alpha, pi, h []byte
+}
+
+func decode(v *vector) (*decoded, error) {
+	var d decoded
+	for _, f := range []struct {
+		dst *[]byte
+		src string
+	}{{&d.sk, v.Sk}, {&d.pk, v.Pk}, {&d.alpha, v.Alpha}, {&d.pi, v.Pi}, {&d.h, v.H}} {
+		b, err := hex.DecodeString(f.src)
+		if err != nil {
+			return nil, err
+		}
+		*f.dst = b
+	}
+	return &d, nil
+}
+
+// mutations derives the invalid proofs of d shared by all suites, given the offsets of the
+// challenge, negative if the proof has none, and of the response, and the order q of the scalars
+// encoded by scalar and decoded by parse.
+func mutations(d *decoded, cOff, sOff int, q *big.Int, scalar func(*big.Int) []byte, parse func([]byte) *big.Int) map[string][]byte {
+	with := func(off int, b []byte) []byte {
+		pi := append([]byte{}, d.pi...)
+		copy(pi[off:], b)
+		return pi
+	}
+	m := map[string][]byte{
+		"wrong gamma":   with(0, d.h),
+		"truncated":     d.pi[:len(d.pi)-1],
+		"trailing data": append(append([]byte{}, d.pi...), 0),
+	}
+	if cOff >= 0 {
+		c := append([]byte{}, d.pi[cOff:sOff]...)
+		c[0] ^= 1
+		m["wrong challenge"] = with(cOff, c)
+	}
+
+	// s + q if it fits in the encoding, q otherwise
+	s := new(big.Int).Add(parse(d.pi[sOff:]), q)
+	if s.BitLen() > 8*(len(d.pi)-sOff) {
+		s.Set(q)
+	}
+	m["s >= q"] = with(sOff, scalar(s))
+	return m
+}
+
+// rejected verifies the proofs, which must all fail, in the order of reasons.
+func rejected(d *decoded, reasons []string, proofs map[string][]byte, verify func(pk, alpha, pi []byte) error) ([]*invalidVector, error) {
+	var out []*invalidVector
+	for _, reason := range reasons {
+		pi, ok := proofs[reason]
+		if !ok {
+			continue
+		}
+		err := verify(d.pk, d.alpha, pi)
+		if err == nil {
+			return nil, fmt.Errorf("%s: proof accepted", reason)
+		}
+		code, err := errorCode(err)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", reason, err)
+		}
+		out = append(out, &invalidVector{
+			Reason: reason,
+			Pk:     hex.EncodeToString(d.pk),
+			Alpha:  hex.EncodeToString(d.alpha),
+			Pi:     hex.EncodeToString(pi),
+			Code:   code,
+		})
+	}
+	return out, nil
+}
+
+// invalidReasons lists the reasons of the invalid proofs, in the order of the files.
+var invalidReasons = []string{
+	"off-curve gamma", "non-canonical gamma", "gamma tag", "wrong gamma", "s >= q", "wrong challenge",
+	"wrong announcements", "truncated", "trailing data", "suite string confusion", "spec confusion",
+	"format confusion",
+}
+
+func (s *ecdsaSuite) invalid(v *vector) ([]*invalidVector, error) {
+	d, err := decode(v)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		params = s.curve.Params()
+		qlen   = (params.N.BitLen() + 7) / 8
+		ptlen  = 1 + (params.BitSize+7)/8
+		sOff   = len(d.pi) - qlen
+		proofs = mutations(d, ptlen, sOff, params.N,
+			func(x *big.Int) []byte { return x.FillBytes(make([]byte, qlen)) },
+			func(b []byte) *big.Int { return new(big.Int).SetBytes(b) })
+		with = func(gamma []byte) []byte { return append(append([]byte{}, gamma...), d.pi[ptlen:]...) }
+	)
+
+	// the next x after the one of Gamma with no point on the curve
+	x := new(big.Int).SetBytes(d.pi[1:ptlen])
+	for {
+		x.Add(x, big.NewInt(1))
+		gamma := append([]byte{2}, x.FillBytes(make([]byte, ptlen-1))...)
+		if _, err := s.point(gamma); err != nil {
+			proofs["off-curve gamma"] = with(gamma)
+			break
+		}
+	}
+	proofs["non-canonical gamma"] = with(append([]byte{d.pi[0]}, params.P.Bytes()...))
+	proofs["gamma tag"] = with(append([]byte{4}, d.pi[1:ptlen]...))
+	for _, o := range s.others {
+		if proofs[o.reason], err = o.prove(d.sk, d.alpha); err != nil {
+			return nil, err
+		}
+	}
+
+	return rejected(d, invalidReasons, proofs, func(pk, alpha, pi []byte) error {
+		Y, err := s.point(pk)
+		if err != nil {
+			return err
+		}
+		_, err = s.vrf.Verify(&ecdsa.PublicKey{Curve: s.curve, X: Y[0], Y: Y[1]}, alpha, pi)
+		return err
+	})
+}
+
+// prover returns the proofs of the Weierstrass suite v, from keys in their octet encodings.
+func (s *ecdsaSuite) prover(v ecvrf.VRF) func(sk, alpha []byte) ([]byte, error) {
+	return func(b, alpha []byte) ([]byte, error) {
+		sk := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(b)}
+		sk.Curve = s.curve
+		sk.X, sk.Y = s.curve.ScalarBaseMult(b)
+		_, pi, err := v.Prove(sk, alpha)
+		return pi, err
+	}
+}
+
+// ed25519P is the prime 2^255 - 19 of the field of edwards25519, and ed25519D the constant d of
+// its equation -x^2 + y^2 = 1 + d*x^2*y^2.
+var (
+	ed25519P    = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	ed25519D, _ = new(big.Int).SetString("37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+)
+
+// onEd25519 reports whether there is a point of edwards25519 with y, that is whether
+// x^2 = (y^2 - 1) / (d*y^2 + 1) is a square.
+func onEd25519(y *big.Int) bool {
+	y2 := new(big.Int).Mul(y, y)
+	num := new(big.Int).Sub(y2, big.NewInt(1))
+	den := new(big.Int).Mul(ed25519D, y2)
+	den.Add(den, big.NewInt(1)).Mod(den, ed25519P)
+	if den.Sign() == 0 {
+		return false
+	}
+	x2 := num.Mul(num, den.ModInverse(den, ed25519P)).Mod(num, ed25519P)
+	return x2.Sign() == 0 || big.Jacobi(x2, ed25519P) == 1
+}
+
+func (s *ed25519Suite) invalid(v *vector) ([]*invalidVector, error) {
+	d, err := decode(v)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		sOff   = len(d.pi) - 32
+		cOff   = 32
+		scalar = func(x *big.Int) []byte { return toLittleEndian(x, 32) }
+	)
+	if s.batchCompat {
+		// there is no challenge in the proof, which is derived from the announcements
+		cOff = -1
+	}
+	proofs := mutations(d, cOff, sOff, ed25519Order, scalar, littleEndian)
+	if s.draft03 {
+		// like libsodium, draft-03 reduces s modulo q
+		delete(proofs, "s >= q")
+	}
+	with := func(off int, b []byte) []byte {
+		pi := append([]byte{}, d.pi...)
+		copy(pi[off:], b)
+		return pi
+	}
+
+	// the next y after the one of Gamma with no point on the curve
+	y := littleEndian(d.pi[:32])
+	y.SetBit(y, 255, 0)
+	for {
+		y.Add(y, big.NewInt(1))
+		if !onEd25519(y) {
+			proofs["off-curve gamma"] = with(0, toLittleEndian(y, 32))
+			break
+		}
+	}
+	// y = p is the non-canonical encoding of y = 0, whose points (±sqrt(-1), 0) are on the curve
+	proofs["non-canonical gamma"] = with(0, toLittleEndian(ed25519P, 32))
+	if s.batchCompat {
+		proofs["wrong announcements"] = with(32, append(append([]byte{}, d.pi[64:96]...), d.pi[32:64]...))
+	}
+	for _, o := range s.others {
+		if proofs[o.reason], err = o.prove(d.sk, d.alpha); err != nil {
+			return nil, err
+		}
+	}
+
+	return rejected(d, invalidReasons, proofs, func(pk, alpha, pi []byte) error {
+		_, err := s.vrf.Verify(ed25519.PublicKey(pk), alpha, pi)
+		return err
+	})
+}
+
+// ed25519Prover returns the proofs of the Ed25519 suite v, from seeds.
+func ed25519Prover(v ecvrf.Ed25519VRF) func(seed, alpha []byte) ([]byte, error) {
+	return func(seed, alpha []byte) ([]byte, error) {
+		_, pi, err := v.Prove(ed25519.NewKeyFromSeed(seed), alpha)
+		return pi, err
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Command vrfvectors generates JSON files of test vectors with the intermediate values of the
+// proofs, so implementations in other languages can be checked step by step against go-ecvrf:
+//
+//	vrfvectors [-suite s] [-spec v] [-n N] [-seed SEED] [-o FILE]
+//	vrfvectors [-suite s] [-spec v] -in FILE [-o FILE]
+//	vrfvectors [-suite s] [-spec v] -invalid [-n N] [-seed SEED] [-in FILE] [-o FILE]
+//
+// Vectors hold sk, pk, alpha, pi and beta as the files of the vectors module, and the intermediate
+// values H, k, Gamma, U = k*B, V = k*H, c and s, all hex encoded, for all the suites. Scalars are
+// encoded as in the proofs, little-endian for Ed25519, and c of the batch-compatible proofs of
+// ed25519-batchcompat, which don't hold it, is the challenge of U and V. Keys and inputs are
+// derived from the seed as by 'ecvrf vectors', so both commands give the same proofs for a seed.
+// With -in, the vectors of the file are recomputed from their keys and inputs, checked, and
+// written with their intermediate values. With -invalid, the command writes instead proofs of the
+// keys and inputs of the vectors that must be rejected, e.g. with an off-curve Gamma, s >= q, a
+// wrong challenge or a truncated encoding, or by the same suite with another suite string or spec
+// version, each with its reason and the message of the error returned by go-ecvrf.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+var errChanged = errors.New("recomputed vector differs from the file")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("vrfvectors", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		suiteName = fs.String("suite", "secp256k1", "suite: "+strings.Join(suiteNames(), ", "))
+		specName  = fs.String("spec", "draft06", "spec version of p256 and secp256k1: draft06, rfc9381")
+		n         = fs.Int("n", 10, "number of vectors")
+		seed      = fs.String("seed", "go-ecvrf", "seed of the keys and inputs")
+		in        = fs.String("in", "", "vector file to recompute with intermediate values")
+		out       = fs.String("o", "", "output file, stdout by default")
+		invalid   = fs.Bool("invalid", false, "write invalid proofs derived from the vectors")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := generate(*suiteName, *specName, *n, *seed, *in, *out, *invalid, stdout); err != nil {
+		fmt.Fprintln(stderr, "vrfvectors:", err)
+		return 1
+	}
+	return 0
+}
+
+func generate(suiteName, specName string, n int, seed, in, out string, invalid bool, stdout io.Writer) error {
+	var spec ecvrf.SpecVersion
+	switch specName {
+	case "draft06":
+		spec = ecvrf.Draft06
+	case "rfc9381":
+		spec = ecvrf.RFC9381
+	default:
+		return fmt.Errorf("unknown spec version %q", specName)
+	}
+	s, ok := newSuite(suiteName, spec)
+	if !ok {
+		return fmt.Errorf("unknown suite %q", suiteName)
+	}
+
+	var vectors []*vector
+	if in != "" {
+		data, err := ioutil.ReadFile(in)
+		if err != nil {
+			return err
+		}
+		var cases []*vector
+		if err := json.Unmarshal(data, &cases); err != nil {
+			return err
+		}
+		for i, c := range cases {
+			v, err := recompute(s, c)
+			if err != nil {
+				return fmt.Errorf("vector %d: %v", i, err)
+			}
+			vectors = append(vectors, v)
+		}
+	} else {
+		var (
+			r     = &seedReader{seed: []byte(seed)}
+			sizes = []int{0, 1, 32, 100}
+		)
+		for i := 0; i < n; i++ {
+			sk, err := s.keygen(r)
+			if err != nil {
+				return err
+			}
+			alpha := make([]byte, sizes[i%len(sizes)])
+			r.Read(alpha)
+			v, err := s.vector(sk, alpha)
+			if err != nil {
+				return err
+			}
+			vectors = append(vectors, v)
+		}
+	}
+
+	var result interface{} = vectors
+	if invalid {
+		var invalids []*invalidVector
+		for i, v := range vectors {
+			vs, err := s.invalid(v)
+			if err != nil {
+				return fmt.Errorf("vector %d: %v", i, err)
+			}
+			invalids = append(invalids, vs...)
+		}
+		result = invalids
+	}
+
+	w := stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(result)
+}
+
+// recompute computes the vector of the key and input of c, which must give the same proof.
+func recompute(s suite, c *vector) (*vector, error) {
+	sk, err := hex.DecodeString(c.Sk)
+	if err != nil {
+		return nil, err
+	}
+	alpha, err := hex.DecodeString(c.Alpha)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.vector(sk, alpha)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(v.Pk, c.Pk) || !strings.EqualFold(v.Pi, c.Pi) || !strings.EqualFold(v.Beta, c.Beta) {
+		return nil, errChanged
+	}
+	// the key as encoded in the file
+	v.Sk = c.Sk
+	return v, nil
+}
+
+// seedReader is the stream SHA256(seed || counter) of 64-bit big-endian counters.
+type seedReader struct {
+	seed []byte
+	ctr  uint64
+	buf  []byte
+}
+
+func (r *seedReader) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		if len(r.buf) == 0 {
+			var c [8]byte
+			binary.BigEndian.PutUint64(c[:], r.ctr)
+			r.ctr++
+			h := sha256.Sum256(append(append([]byte{}, r.seed...), c[:]...))
+			r.buf = h[:]
+		}
+		k := copy(p[n:], r.buf)
+		r.buf = r.buf[k:]
+		n += k
+	}
+	return len(p), nil
+}
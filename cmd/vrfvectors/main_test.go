@@ -0,0 +1,128 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// vectors runs the command and parses its vectors.
+func vectors(t *testing.T, wantStatus int, args ...string) []*vector {
+	var stdout, stderr bytes.Buffer
+	if status := run(args, &stdout, &stderr); status != wantStatus {
+		t.Fatalf("run(%q) = %d, want %d: %s", args, status, wantStatus, stderr.String())
+	}
+	if wantStatus != 0 {
+		return nil
+	}
+	var vs []*vector
+	if err := json.Unmarshal(stdout.Bytes(), &vs); err != nil {
+		t.Fatal(err)
+	}
+	return vs
+}
+
+func TestGenerate(t *testing.T) {
+	for _, suite := range suiteNames() {
+		for _, spec := range []string{"draft06", "rfc9381"} {
+			a := vectors(t, 0, "-suite", suite, "-spec", spec, "-n", "4", "-seed", "x")
+			b := vectors(t, 0, "-suite", suite, "-spec", spec, "-n", "4", "-seed", "x")
+			if len(a) != 4 {
+				t.Fatalf("%s: %d vectors, want 4", suite, len(a))
+			}
+			for i := range a {
+				if *a[i] != *b[i] {
+					t.Fatalf("%s: vector %d not deterministic", suite, i)
+				}
+				if suite == "ed25519-batchcompat" {
+					if a[i].Gamma+a[i].U+a[i].V+a[i].S != a[i].Pi {
+						t.Fatalf("%s: pi = %v, want Gamma || U || V || s", suite, a[i].Pi)
+					}
+				} else if a[i].Gamma+a[i].C+a[i].S != a[i].Pi {
+					t.Fatalf("%s: pi = %v, want Gamma || c || s", suite, a[i].Pi)
+				}
+				if a[i].H == "" || a[i].K == "" || a[i].U == "" || a[i].V == "" {
+					t.Fatalf("%s: missing intermediate values", suite)
+				}
+			}
+		}
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	vs := vectors(t, 0, "-suite", "p256", "-spec", "rfc9381", "-in", "../../vectors/p256_sha256_tai_rfc9381.json")
+	// Example 10 of RFC9381 B.1
+	if want := "0272a877532e9ac193aff4401234266f59900a4a9e3fc3cfc6a4b7e467a15d06d4"; vs[0].H != want {
+		t.Fatalf("H = %v, want %v", vs[0].H, want)
+	}
+	if want := "0d90591273453d2dc67312d39914e3a93e194ab47a58cd598886897076986f77"; vs[0].K != want {
+		t.Fatalf("k = %v, want %v", vs[0].K, want)
+	}
+
+	// Example 16 of RFC9381 B.3
+	vs25519 := vectors(t, 0, "-suite", "ed25519", "-in", "../../vectors/ed25519_sha512_ell2.json")
+	if want := "b8066ebbb706c72b64390324e4a3276f129569eab100c26b9f05011200c1bad9"; vs25519[0].H != want {
+		t.Fatalf("H = %v, want %v", vs25519[0].H, want)
+	}
+
+	// vectors of another spec version, or tampered with, are rejected
+	vectors(t, 1, "-suite", "p256", "-spec", "draft06", "-in", "../../vectors/p256_sha256_tai_rfc9381.json")
+	vs[0].Beta = strings.Repeat("00", 32)
+	data, _ := json.Marshal(vs)
+	file := filepath.Join(t.TempDir(), "vectors.json")
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	vectors(t, 1, "-suite", "p256", "-spec", "rfc9381", "-in", file)
+}
+
+func TestInvalid(t *testing.T) {
+	// the files of the vectors module are generated by the command
+	for _, f := range []struct{ suite, spec, file string }{
+		{"secp256k1", "draft06", "secp256_k1_sha256_tai_invalid.json"},
+		{"secp256k1", "rfc9381", "secp256_k1_sha256_tai_rfc9381_invalid.json"},
+		{"p256", "draft06", "p256_sha256_tai_invalid.json"},
+		{"p256", "rfc9381", "p256_sha256_tai_rfc9381_invalid.json"},
+		{"ed25519-draft03", "draft06", "ed25519_sha512_elligator2_invalid.json"},
+		{"ed25519", "draft06", "ed25519_sha512_ell2_invalid.json"},
+		{"ed25519-batchcompat", "draft06", "ed25519_sha512_ell2_batchcompat_invalid.json"},
+	} {
+		var stdout, stderr bytes.Buffer
+		args := []string{"-suite", f.suite, "-spec", f.spec, "-invalid", "-n", "3", "-seed", "go-ecvrf/invalid"}
+		if status := run(args, &stdout, &stderr); status != 0 {
+			t.Fatalf("run(%q) = %d: %s", args, status, stderr.String())
+		}
+		want, err := ioutil.ReadFile(filepath.Join("../../vectors", f.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(stdout.Bytes(), want) {
+			t.Errorf("%s: output differs from the file", f.file)
+		}
+	}
+
+	// invalid proofs of vectors read from a file
+	var invalids []*invalidVector
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"-suite", "p256", "-spec", "rfc9381", "-invalid", "-in", "../../vectors/p256_sha256_tai_rfc9381.json"}, &stdout, &stderr); status != 0 {
+		t.Fatalf("run() = %d: %s", status, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &invalids); err != nil {
+		t.Fatal(err)
+	}
+	if len(invalids) == 0 || invalids[0].Reason != "off-curve gamma" || invalids[0].Code != "not_square" {
+		t.Fatalf("invalid proofs = %v", invalids)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	vectors(t, 2, "-unknown")
+	vectors(t, 1, "-suite", "p384")
+	vectors(t, 1, "-spec", "draft99")
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+var (
+	errInvalidKey   = errors.New("invalid key")
+	errInvalidProof = errors.New("invalid proof")
+	errMismatch     = errors.New("intermediate values don't match the proof")
+)
+
+// vector is a test vector, in the format of the files of the vectors module, with the intermediate
+// values of the proof. Scalars are encoded as in the proofs of the suite: big-endian for the
+// Weierstrass suites, little-endian for Ed25519.
+type vector struct {
+	Sk    string `json:"sk"`
+	Pk    string `json:"pk"`
+	Alpha string `json:"alpha"`
+	H     string `json:"h,omitempty"`
+	K     string `json:"k,omitempty"`
+	Gamma string `json:"gamma"`
+	U     string `json:"u,omitempty"`
+	V     string `json:"v,omitempty"`
+	C     string `json:"c"`
+	S     string `json:"s"`
+	Pi    string `json:"pi"`
+	Beta  string `json:"beta"`
+}
+
+// suite computes the vectors of a suite, from keys in their octet encodings: secret scalars for
+// the Weierstrass suites, possibly shorter than the order, and seeds for Ed25519.
+type suite interface {
+	keygen(rand io.Reader) (sk []byte, err error)
+	vector(sk, alpha []byte) (*vector, error)
+	// invalid derives proofs of the key and input of the vector that must be rejected.
+	invalid(v *vector) ([]*invalidVector, error)
+}
+
+type ecdsaSuite struct {
+	curve  elliptic.Curve
+	vrf    ecvrf.VRF
+	others []other
+}
+
+// newECDSASuite returns the suite of newVRF at the spec version, whose proofs by the same suite
+// with the suite string foreign, or at the other spec version, are invalid.
+func newECDSASuite(curve elliptic.Curve, newVRF func(...ecvrf.Option) ecvrf.VRF, spec ecvrf.SpecVersion, foreign byte) *ecdsaSuite {
+	otherSpec := ecvrf.RFC9381
+	if spec == ecvrf.RFC9381 {
+		otherSpec = ecvrf.Draft06
+	}
+	s := &ecdsaSuite{curve: curve, vrf: newVRF(ecvrf.WithSpecVersion(spec))}
+	s.others = []other{
+		{"suite string confusion", s.prover(newVRF(ecvrf.WithSpecVersion(spec), func(cfg *ecvrf.Config) { cfg.SuiteString = foreign }))},
+		{"spec confusion", s.prover(newVRF(ecvrf.WithSpecVersion(otherSpec)))},
+	}
+	return s
+}
+
+// keygen draws the scalar from rand by rejection sampling, as the vectors command of cmd/ecvrf,
+// so the same seed gives the same keys.
+func (s *ecdsaSuite) keygen(rand io.Reader) ([]byte, error) {
+	n := s.curve.Params().N
+	b := make([]byte, (n.BitLen()+7)/8)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		if d := new(big.Int).SetBytes(b); d.Sign() > 0 && d.Cmp(n) < 0 {
+			return b, nil
+		}
+	}
+}
+
+func (s *ecdsaSuite) vector(b, alpha []byte) (*vector, error) {
+	var (
+		params = s.curve.Params()
+		qlen   = (params.N.BitLen() + 7) / 8
+		ptlen  = 1 + (params.BitSize+7)/8
+		d      = new(big.Int).SetBytes(b)
+	)
+	if len(b) > qlen || d.Sign() == 0 || d.Cmp(params.N) >= 0 {
+		return nil, errInvalidKey
+	}
+	b = d.FillBytes(make([]byte, qlen))
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = s.curve
+	sk.X, sk.Y = s.curve.ScalarBaseMult(b)
+	beta, pi, err := s.vrf.Prove(sk, alpha)
+	if err != nil {
+		return nil, err
+	}
+
+	hx, hy, err := s.vrf.EncodeToCurve(&sk.PublicKey, alpha)
+	if err != nil {
+		return nil, err
+	}
+	h := elliptic.MarshalCompressed(s.curve, hx, hy)
+	k := s.vrf.GenerateNonce(sk, h)
+	ux, uy := s.curve.ScalarBaseMult(k.Bytes())
+	vx, vy := s.curve.ScalarMult(hx, hy, k.Bytes())
+
+	// Gamma || c || s, checked against the recomputed challenge and response
+	if len(pi) <= ptlen+qlen {
+		return nil, errInvalidProof
+	}
+	gamma, err := s.point(pi[:ptlen])
+	if err != nil {
+		return nil, err
+	}
+	c := new(big.Int).SetBytes(pi[ptlen : len(pi)-qlen])
+	resp := new(big.Int).SetBytes(pi[len(pi)-qlen:])
+	wantC, err := ecvrf.Challenge(s.vrf, &sk.PublicKey, alpha, gamma, [2]*big.Int{ux, uy}, [2]*big.Int{vx, vy})
+	if err != nil {
+		return nil, err
+	}
+	wantS := new(big.Int).Mul(c, d)
+	wantS.Add(wantS, k)
+	wantS.Mod(wantS, params.N)
+	if wantC.Cmp(c) != 0 || wantS.Cmp(resp) != 0 {
+		return nil, errMismatch
+	}
+
+	return &vector{
+		Sk:    hex.EncodeToString(b),
+		Pk:    hex.EncodeToString(elliptic.MarshalCompressed(s.curve, sk.X, sk.Y)),
+		Alpha: hex.EncodeToString(alpha),
+		H:     hex.EncodeToString(h),
+		K:     hex.EncodeToString(k.FillBytes(make([]byte, qlen))),
+		Gamma: hex.EncodeToString(pi[:ptlen]),
+		U:     hex.EncodeToString(elliptic.MarshalCompressed(s.curve, ux, uy)),
+		V:     hex.EncodeToString(elliptic.MarshalCompressed(s.curve, vx, vy)),
+		C:     hex.EncodeToString(pi[ptlen : len(pi)-qlen]),
+		S:     hex.EncodeToString(pi[len(pi)-qlen:]),
+		Pi:    hex.EncodeToString(pi),
+		Beta:  hex.EncodeToString(beta),
+	}, nil
+}
+
+// point decodes a compressed point.
+func (s *ecdsaSuite) point(b []byte) ([2]*big.Int, error) {
+	if s.curve == secp256k1.S256() {
+		pk, err := secp256k1.ParsePubKey(b)
+		if err != nil {
+			return [2]*big.Int{}, errInvalidProof
+		}
+		p := pk.ToECDSA()
+		return [2]*big.Int{p.X, p.Y}, nil
+	}
+	x, y := elliptic.UnmarshalCompressed(s.curve, b)
+	if x == nil {
+		return [2]*big.Int{}, errInvalidProof
+	}
+	return [2]*big.Int{x, y}, nil
+}
+
+type ed25519Suite struct {
+	vrf         ecvrf.Ed25519VRF
+	batchCompat bool
+	draft03     bool
+	others      []other
+}
+
+func (s *ed25519Suite) keygen(rand io.Reader) ([]byte, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+func (s *ed25519Suite) vector(seed, alpha []byte) (*vector, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, errInvalidKey
+	}
+	sk := ed25519.NewKeyFromSeed(seed)
+	pk := sk.Public().(ed25519.PublicKey)
+	beta, pi, err := s.vrf.Prove(sk, alpha)
+	if err != nil {
+		return nil, err
+	}
+	t, err := ecvrf.Ed25519Terms(s.vrf, pk, alpha, pi)
+	if err != nil {
+		return nil, err
+	}
+
+	// x is the clamped first half of SHA512(seed), and k = SHA512(second half || H) mod q,
+	// checked against the response s = k + c*x mod q
+	az := sha512.Sum512(seed)
+	az[0] &= 248
+	az[31] &= 127
+	az[31] |= 64
+	kh := sha512.Sum512(append(az[32:], t.H...))
+	var (
+		x = littleEndian(az[:32])
+		k = new(big.Int).Mod(littleEndian(kh[:]), ed25519Order)
+		c = littleEndian(t.C)
+	)
+	wantS := new(big.Int).Mul(c, x)
+	wantS.Add(wantS, k)
+	wantS.Mod(wantS, ed25519Order)
+	if wantS.Cmp(littleEndian(t.S)) != 0 {
+		return nil, errMismatch
+	}
+
+	return &vector{
+		Sk:    hex.EncodeToString(seed),
+		Pk:    hex.EncodeToString(pk),
+		Alpha: hex.EncodeToString(alpha),
+		H:     hex.EncodeToString(t.H),
+		K:     hex.EncodeToString(toLittleEndian(k, 32)),
+		Gamma: hex.EncodeToString(t.Gamma),
+		U:     hex.EncodeToString(t.U),
+		V:     hex.EncodeToString(t.V),
+		C:     hex.EncodeToString(t.C),
+		S:     hex.EncodeToString(t.S),
+		Pi:    hex.EncodeToString(pi),
+		Beta:  hex.EncodeToString(beta),
+	}, nil
+}
+
+// ed25519Order is the order q of the prime subgroup of edwards25519, 2^252 + 27742317777372353535851937790883648493.
+var ed25519Order, _ = new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+
+func littleEndian(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+func toLittleEndian(x *big.Int, n int) []byte {
+	b := x.FillBytes(make([]byte, n))
+	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
+
+// newSuite returns the suite of the name. The spec version only applies to the Weierstrass suites,
+// the Ed25519 ones being named by their revision.
+func newSuite(name string, spec ecvrf.SpecVersion) (suite, bool) {
+	switch name {
+	case "p256":
+		return newECDSASuite(elliptic.P256(), ecvrf.NewP256Sha256Tai, spec, 0xfe), true
+	case "secp256k1":
+		return newECDSASuite(secp256k1.S256(), ecvrf.NewSecp256k1Sha256Tai, spec, 0x01), true
+	case "ed25519-draft03":
+		return &ed25519Suite{vrf: ecvrf.NewEd25519Sha512Elligator2(), draft03: true, others: []other{
+			{"spec confusion", ed25519Prover(ecvrf.NewEd25519Sha512Ell2())},
+		}}, true
+	case "ed25519":
+		return &ed25519Suite{vrf: ecvrf.NewEd25519Sha512Ell2(), others: []other{
+			{"spec confusion", ed25519Prover(ecvrf.NewEd25519Sha512Elligator2())},
+		}}, true
+	case "ed25519-batchcompat":
+		return &ed25519Suite{vrf: ecvrf.NewEd25519Sha512Ell2BatchCompat(), batchCompat: true, others: []other{
+			{"format confusion", ed25519Prover(ecvrf.NewEd25519Sha512Ell2())},
+		}}, true
+	}
+	return nil, false
+}
+
+// suiteNames lists the names accepted by newSuite.
+func suiteNames() []string {
+	return []string{"p256", "secp256k1", "ed25519", "ed25519-batchcompat", "ed25519-draft03"}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package commitreveal binds a future VRF evaluation to a prior commitment, e.g. published on
+// chain, against grinding: a party commits to its key and its input before the inputs of the others
+// are known, then reveals the input with its VRF proof, which anyone checks against the commitment.
+// The input can't be changed after the commitment, and the salt hides it until the reveal.
+//
+// The commitment is SHA-256("go-ecvrf/commitreveal\x00" || pk || len(alpha) || alpha || salt), with
+// pk encoded compressed and the length of alpha in 4 big-endian octets.
+package commitreveal
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// SaltSize is the size of the salts of NewSalt, and the minimum size of salts.
+const SaltSize = 32
+
+var (
+	errShortSalt  = errors.New("commitreveal: salt too short")
+	errCommitment = errors.New("commitreveal: reveal doesn't match the commitment")
+	errBeta       = errors.New("commitreveal: beta doesn't match the proof")
+)
+
+// Opening is the reveal of a commitment: the input and the salt, with the VRF output of the input.
+type Opening struct {
+	Alpha []byte `json:"alpha"`
+	Salt  []byte `json:"salt"`
+	Beta  []byte `json:"beta"`
+	Pi    []byte `json:"pi"`
+}
+
+// NewSalt reads a salt of SaltSize octets from rand, e.g. crypto/rand.Reader.
+func NewSalt(rand io.Reader) ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// Commit returns the commitment of the key to alpha, hidden by the salt of at least SaltSize octets.
+func Commit(pk *ecdsa.PublicKey, alpha, salt []byte) ([]byte, error) {
+	if len(salt) < SaltSize {
+		return nil, errShortSalt
+	}
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(alpha)))
+	h := sha256.New()
+	h.Write([]byte("go-ecvrf/commitreveal\x00"))
+	h.Write(elliptic.MarshalCompressed(pk.Curve, pk.X, pk.Y))
+	h.Write(n[:])
+	h.Write(alpha)
+	h.Write(salt)
+	return h.Sum(nil), nil
+}
+
+// Reveal proves the committed alpha with sk, and returns the opening of the commitment.
+func Reveal(v ecvrf.VRF, sk *ecdsa.PrivateKey, alpha, salt []byte) (*Opening, error) {
+	if len(salt) < SaltSize {
+		return nil, errShortSalt
+	}
+	beta, pi, err := v.Prove(sk, alpha)
+	if err != nil {
+		return nil, err
+	}
+	return &Opening{
+		Alpha: append([]byte(nil), alpha...),
+		Salt:  append([]byte(nil), salt...),
+		Beta:  beta,
+		Pi:    pi,
+	}, nil
+}
+
+// Verify checks that the opening matches the commitment of the key, and its VRF proof, and returns beta.
+func Verify(v ecvrf.VRF, pk *ecdsa.PublicKey, commitment []byte, o *Opening) ([]byte, error) {
+	c, err := Commit(pk, o.Alpha, o.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(c, commitment) != 1 {
+		return nil, errCommitment
+	}
+	beta, err := v.Verify(pk, o.Alpha, o.Pi)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(beta, o.Beta) {
+		return nil, errBeta
+	}
+	return beta, nil
+}
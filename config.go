@@ -6,6 +6,7 @@ package ecvrf
 import (
 	"crypto/elliptic"
 	"hash"
+	"io"
 	"math/big"
 )
 
@@ -21,8 +22,36 @@ type Config struct {
 	Y2 func(c elliptic.Curve, x *big.Int) *big.Int
 	// function to calculate square root.
 	Sqrt func(c elliptic.Curve, s *big.Int) *big.Int
+	// optional, create extendable-output function used by proof_to_hash instead of NewHasher.
+	NewXOF func() XOF
+	// number of octets of beta read from the XOF. defaults to the output size of NewHasher.
+	BetaSize int
+	// optional, source of random masks blinding the secret scalars in Prove. no blinding if nil.
+	Blinding io.Reader
+	// optional, source of randomness mixed into the nonces of Prove. nonces are deterministic if nil.
+	NonceRand io.Reader
+	// optional, verify proofs in Prove before returning them.
+	SelfCheck bool
+	// optional, number of candidates hash_to_curve always tries, even after the point is found.
+	// it stops at the point if 0.
+	HashToCurveCandidates int
+	// revision of the specification to follow. defaults to Draft06.
+	Spec SpecVersion
+	// optional, receives the measurements of Prove, Verify and hash_to_curve.
+	Metrics Metrics
+	// optional, receives the events of verification failures and key loads.
+	Logger Logger
 }
 
+// XOF is the interface of extendable-output functions, such as SHAKE256.
+type XOF interface {
+	io.Writer
+	io.Reader
+}
+
+// Option modifies the Config of a VRF object.
+type Option func(*Config)
+
 // DefaultSqrt is the default sqrt method. nil is returned if s is not a square.
 func DefaultSqrt(c elliptic.Curve, s *big.Int) *big.Int {
 	var r big.Int
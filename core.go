@@ -6,12 +6,30 @@ package ecvrf
 import (
 	"bytes"
 	"crypto/elliptic"
-	"crypto/hmac"
 	"errors"
 	"hash"
 	"math/big"
 )
 
+// Errors of malformed points and proofs, which callers can tell apart with errors.Is. Proofs of
+// edwards25519 are all rejected with ErrInvalidProof.
+var (
+	ErrUnrecognizedPointEncoding = errors.New("unrecognized point encoding")
+	ErrInvalidPointLength        = errors.New("invalid point data length")
+	ErrNotSquare                 = errors.New("invalid point: y^2 is not a square")
+	ErrNonCanonicalPoint         = errors.New("invalid point: x is not less than p")
+	ErrNotOnCurve                = errors.New("invalid point: not on the curve")
+	ErrInvalidProofLength        = errors.New("invalid proof length")
+	ErrInvalidProof              = errors.New("invalid proof")
+	ErrInvalidProofScalar        = errors.New("invalid proof: s is not less than q")
+	ErrNonCanonicalProof         = errors.New("invalid proof: non-canonical encoding")
+)
+
+var (
+	errNoValidPoint     = errors.New("no valid point found")
+	errInvalidPublicKey = errors.New("invalid public key")
+)
+
 type point struct {
 	X, Y *big.Int
 }
@@ -19,7 +37,12 @@ type point struct {
 type core struct {
 	*Config
 	curve        elliptic.Curve
+	hashers      *hasherPool
 	cachedHasher hash.Hash
+	arith        *weierstrass // the arithmetic of the curve, nil if not supported
+	cachedGroup  group
+	cachedPK     *point
+	cachedPKData []byte
 }
 
 // Q returns prime order of large prime order subgroup.
@@ -36,86 +59,114 @@ func (c *core) getCachedHasher() hash.Hash {
 	if c.cachedHasher != nil {
 		return c.cachedHasher
 	}
-	c.cachedHasher = c.NewHasher()
+	c.cachedHasher = c.getHashers().get()
 	return c.cachedHasher
 }
 
+// getHashers returns the pool of hash states shared by the VRF object,
+// or a new pool if the core is not created by one.
+func (c *core) getHashers() *hasherPool {
+	if c.hashers == nil {
+		c.hashers = newHasherPool(c.NewHasher)
+	}
+	return c.hashers
+}
+
+// release returns the cached hash state to the pool. The core can still be used afterwards.
+func (c *core) release() {
+	if c.cachedHasher != nil {
+		c.getHashers().put(c.cachedHasher)
+		c.cachedHasher = nil
+	}
+}
+
+// group returns the arithmetic of the curve, the constant-time one if supported.
+func (c *core) group() group {
+	if c.cachedGroup == nil {
+		c.cachedGroup = newGroup(c.curve, c.Config, c.arith)
+	}
+	return c.cachedGroup
+}
+
 // Marshal marshals a point into compressed form specified in section 4.3.6 of ANSI X9.62.
 // It's the alias of `point_to_string` specified in [draft-irtf-cfrg-vrf-06 section 5.5](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.5).
 func (c *core) Marshal(pt *point) []byte {
-	byteLen := (c.curve.Params().BitSize + 7) / 8
-	out := make([]byte, byteLen+1)
-
-	// compress format, 3 for odd y
-	out[0] = 2 + byte(pt.Y.Bit(0))
-
-	bytes := pt.X.Bytes()
+	return c.group().Compress(pt)
+}
 
-	if n := len(bytes); byteLen > n {
-		copy(out[1+byteLen-n:], bytes)
-	} else {
-		copy(out[1:], bytes)
+// marshalKey is like Marshal, but caches the result for the same public key point.
+func (c *core) marshalKey(pk *point) []byte {
+	if c.cachedPK != pk {
+		c.cachedPK, c.cachedPKData = pk, c.Marshal(pk)
 	}
-	return out
+	return c.cachedPKData
 }
 
 // Unmarshal unmarshals a compressed point in the form specified in section 4.3.6 of ANSI X9.62.
 // It's the alias of `string_to_point` specified in [draft-irtf-cfrg-vrf-06 section 5.5](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.5).
 // This is borrowed from the project https://github.com/google/keytransparency.
 func (c *core) Unmarshal(in []byte) (*point, error) {
-	byteLen := (c.curve.Params().BitSize + 7) / 8
-	if (in[0] &^ 1) != 2 {
-		return nil, errors.New("unrecognized point encoding")
-	}
-	if len(in) != 1+byteLen {
-		return nil, errors.New("invalid point data length")
-	}
-	// Based on Routine 2.2.4 in NIST Mathematical routines paper
-	p := c.curve.Params().P
-	x := new(big.Int).SetBytes(in[1 : 1+byteLen])
-	y2 := c.Y2(c.curve, x)
-
-	y := c.Sqrt(c.curve, y2)
-	if y == nil {
-		return nil, errors.New("invalid point: y^2 is not a squire")
-	}
+	return c.group().Decompress(in)
+}
 
-	var y2c big.Int
-	y2c.Mul(y, y).Mod(&y2c, p)
-	if y2c.Cmp(y2) != 0 {
-		return nil, errors.New("invalid point: sqrt(y2)^2 != y2")
-	}
+func (c *core) ScalarMult(pt *point, k []byte) *point {
+	return c.group().ScalarMult(pt, k)
+}
 
-	if y.Bit(0) != uint(in[0]&1) {
-		y.Sub(p, y)
-	}
+// ScalarMultVartime returns k * pt in variable time. It must only be used with public inputs.
+func (c *core) ScalarMultVartime(pt *point, k *big.Int) *point {
+	return c.group().ScalarMultVartime(pt, k)
+}
 
-	// valid point: return it
-	return &point{x, y}, nil
+// MulSubVartime returns k1*pt1 - k2*pt2 in variable time, using a joint multi-scalar multiplication
+// if available, where the doublings are shared by both terms. It must only be used with public inputs.
+func (c *core) MulSubVartime(pt1 *point, k1 *big.Int, pt2 *point, k2 *big.Int) *point {
+	return c.group().MulSubVartime(pt1, k1, pt2, k2)
 }
 
-func (c *core) ScalarMult(pt *point, k []byte) *point {
-	x, y := c.curve.ScalarMult(pt.X, pt.Y, k)
-	return &point{x, y}
+// ScalarMulAdd returns (x * y + z) mod q, where y and z are secret big-endian scalars of the length of q.
+// It runs in constant time with respect to y and z if the group order fits in 256 bits.
+func (c *core) ScalarMulAdd(x *big.Int, y, z []byte) *big.Int {
+	fq := scalarField(c.curve.Params())
+	if fq == nil {
+		r := new(big.Int).Mul(x, new(big.Int).SetBytes(y))
+		r.Add(r, new(big.Int).SetBytes(z))
+		return r.Mod(r, c.Q())
+	}
+	var (
+		fx     fieldElement
+		fy, fz = limbsFromShortBytes(y), limbsFromShortBytes(z)
+	)
+	fq.fromBig(&fx, x)
+	fq.fromLimbs(&fy, &fy)
+	fq.fromLimbs(&fz, &fz)
+	fq.mul(&fx, &fx, &fy)
+	fq.add(&fx, &fx, &fz)
+	return fq.toBig(&fx)
+}
+
+// SecretOctets returns the secret scalar as a big-endian integer of the length of q.
+// Unlike big.Int.Bytes, the length doesn't depend on the value.
+func (c *core) SecretOctets(sk *big.Int) []byte {
+	return fixedOctets(sk, (c.Q().BitLen()+7)/8)
 }
 
 func (c *core) ScalarBaseMult(k []byte) *point {
-	x, y := c.curve.ScalarBaseMult(k)
-	return &point{x, y}
+	return c.group().ScalarBaseMult(k)
 }
 
 func (c *core) Add(pt1, pt2 *point) *point {
-	x, y := c.curve.Add(pt1.X, pt1.Y, pt2.X, pt2.Y)
-	return &point{x, y}
+	return c.group().Add(pt1, pt2)
 }
 
 func (c *core) Sub(pt1, pt2 *point) *point {
-	// pt1 - pt2 = pt1 + invert(pt2),
-	// where invert(pt2) = (x2, P - y2)
-	x, y := c.curve.Add(
-		pt1.X, pt1.Y,
-		pt2.X, new(big.Int).Sub(c.curve.Params().P, pt2.Y))
-	return &point{x, y}
+	return c.group().Add(pt1, c.group().Neg(pt2))
+}
+
+// HashToCurve converts the VRF input `alpha` to a point H on the curve.
+// Currently, only the try_and_increment algorithm is supported.
+func (c *core) HashToCurve(pk *point, alpha []byte) (*point, error) {
+	return c.HashToCurveTryAndIncrement(pk, alpha)
 }
 
 // HashToCurveTryAndIncrement takes in the VRF input `alpha` and converts it to H, using the try_and_increment algorithm.
@@ -129,32 +180,58 @@ func (c *core) HashToCurveTryAndIncrement(pk *point, alpha []byte) (H *point, er
 	ctr := 0
 
 	// step 2: PK_string = point_to_string(Y)
-	pkBytes := c.Marshal(pk)
+	pkBytes := c.marshalKey(pk)
 
 	// step 3 ~ 6
 	prefix := []byte{c.SuiteString, 0x01}
 	suffix := []byte{0}
-	for ; ctr < 256; ctr++ {
-		// hash_string = Hash(suite_string || one_string || PK_string || alpha_string || ctr_string)
+	for ; ctr < 256 && (H == nil || ctr < c.HashToCurveCandidates); ctr++ {
+		// hash_string = Hash(suite_string || one_string || PK_string || alpha_string || ctr_string),
+		// followed by 0x00 in RFC9381
 		suffix[0] = byte(ctr)
 		hasher.Reset()
 		hasher.Write(prefix)
 		hasher.Write(pkBytes)
 		hasher.Write(alpha)
 		hasher.Write(suffix)
+		hasher.Write(c.domainBack())
 		// apppend right after compress format
 		hasher.Sum(hash[1:1])
 
 		// H = arbitrary_string_to_point(hash_string)
-		if H, err = c.Unmarshal(hash); err == nil {
-			if c.Cofactor > 1 {
-				// If H is not "INVALID" and cofactor > 1, set H = cofactor * H
-				H = c.ScalarMult(H, []byte{c.Cofactor})
+		// once H is found, the remaining candidates of HashToCurveCandidates are decoded and dropped
+		if pt, err := c.Unmarshal(hash); err == nil && H == nil {
+			H = pt
+			if c.Metrics != nil {
+				c.Metrics.ObserveHashToCurve(ctr + 1)
 			}
-			return H, nil
 		}
 	}
-	return nil, errors.New("no valid point found")
+	if H == nil {
+		return nil, errNoValidPoint
+	}
+	if c.Cofactor > 1 {
+		// If H is not "INVALID" and cofactor > 1, set H = cofactor * H
+		H = c.ScalarMult(H, []byte{c.Cofactor})
+	}
+	return H, nil
+}
+
+// GenerateNonce generates the nonce k from the secret scalar and the given data, following RFC6979.
+func (c *core) GenerateNonce(sk *big.Int, data []byte) *big.Int {
+	var (
+		x = c.SecretOctets(sk)
+		k = c.GenerateNonceOctets(x, data)
+	)
+	defer wipe(x)
+	defer wipe(k)
+	return new(big.Int).SetBytes(k)
+}
+
+// GenerateNonceOctets is like GenerateNonce, but takes and returns scalars as octets of the length of q,
+// so that secret values never go through big.Int.
+func (c *core) GenerateNonceOctets(sk []byte, data []byte) []byte {
+	return rfc6979nonce(sk, data, nil, c.Q(), c.getHashers())
 }
 
 // See: [draft-irtf-cfrg-vrf-06 section 5.4.3](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.4.3)
@@ -166,25 +243,67 @@ func (c *core) HashPoints(points ...*point) *big.Int {
 	for _, pt := range points {
 		hasher.Write(c.Marshal(pt))
 	}
+	hasher.Write(c.domainBack())
 	return bits2int(hasher.Sum(nil), c.N()*8)
 }
 
+// Challenge computes c of the proof. Draft06 hashes the points (H, Gamma, U, V), and RFC9381
+// also the public key first, following [RFC9381 section 5.4.3](https://www.rfc-editor.org/rfc/rfc9381.html#section-5.4.3).
+func (c *core) Challenge(pk, H, gamma, U, V *point) *big.Int {
+	if c.Spec == RFC9381 {
+		return c.HashPoints(pk, H, gamma, U, V)
+	}
+	return c.HashPoints(H, gamma, U, V)
+}
+
+// See: [draft-irtf-cfrg-vrf-06 section 5.2](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.2)
 func (c *core) GammaToHash(gamma *point) []byte {
-	gammaCof := c.ScalarMult(gamma, []byte{c.Cofactor})
+	return c.AppendGammaToHash(nil, gamma)
+}
+
+// AppendGammaToHash is like GammaToHash, but appends beta to dst.
+func (c *core) AppendGammaToHash(dst []byte, gamma *point) []byte {
+	gammaCof := gamma
+	if c.Cofactor > 1 {
+		gammaCof = c.ScalarMult(gamma, []byte{c.Cofactor})
+	}
+	if c.NewXOF != nil {
+		return c.appendGammaToHashXOF(dst, gammaCof)
+	}
 	hasher := c.getCachedHasher()
 	hasher.Reset()
 	hasher.Write([]byte{c.SuiteString, 0x03})
 	hasher.Write(c.Marshal(gammaCof))
-	return hasher.Sum(nil)
+	hasher.Write(c.domainBack())
+	return hasher.Sum(dst)
 }
 
-func (c *core) EncodeProof(gamma *point, C, S *big.Int) []byte {
-	gammaBytes := c.Marshal(gamma)
+// appendGammaToHashXOF is the variant of proof_to_hash that reads beta of BetaSize octets from the XOF.
+func (c *core) appendGammaToHashXOF(dst []byte, gammaCof *point) []byte {
+	size := c.BetaSize
+	if size <= 0 {
+		size = c.getCachedHasher().Size()
+	}
+	xof := c.NewXOF()
+	xof.Write([]byte{c.SuiteString, 0x03})
+	xof.Write(c.Marshal(gammaCof))
+	xof.Write(c.domainBack())
+
+	n := len(dst)
+	dst = append(dst, make([]byte, size)...)
+	xof.Read(dst[n:])
+	return dst
+}
 
-	cbytes := int2octets(C, c.N())
-	sbytes := int2octets(S, (c.Q().BitLen()+7)/8)
+func (c *core) EncodeProof(gamma *point, C, S *big.Int) []byte {
+	return c.AppendProof(nil, gamma, C, S)
+}
 
-	return append(append(gammaBytes, cbytes...), sbytes...)
+// AppendProof is like EncodeProof, but appends pi to dst.
+func (c *core) AppendProof(dst []byte, gamma *point, C, S *big.Int) []byte {
+	dst = append(dst, c.Marshal(gamma)...)
+	dst = append(dst, int2octets(C, c.N())...)
+	return append(dst, int2octets(S, (c.Q().BitLen()+7)/8)...)
 }
 
 // See: [draft-irtf-cfrg-vrf-06 section 5.4.4](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.4.4)
@@ -195,7 +314,7 @@ func (c *core) DecodeProof(pi []byte) (gamma *point, C, S *big.Int, err error) {
 		slen  = (c.Q().BitLen() + 7) / 8
 	)
 	if len(pi) != ptlen+clen+slen {
-		err = errors.New("invalid proof length")
+		err = ErrInvalidProofLength
 		return
 	}
 
@@ -205,9 +324,35 @@ func (c *core) DecodeProof(pi []byte) (gamma *point, C, S *big.Int, err error) {
 
 	C = new(big.Int).SetBytes(pi[ptlen : ptlen+clen])
 	S = new(big.Int).SetBytes(pi[ptlen+clen:])
+	if S.Cmp(c.Q()) >= 0 {
+		err = ErrInvalidProofScalar
+		return
+	}
+
+	// the checks above leave a single encoding of each proof, which is asserted here
+	// since callers may use pi as a unique key
+	if !bytes.Equal(c.EncodeProof(gamma, C, S), pi) {
+		err = ErrNonCanonicalProof
+	}
 	return
 }
 
+// ValidateKey implements `ECVRF_validate_key` specified in [draft-irtf-cfrg-vrf-06 section 5.6.1](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.6.1).
+// The key must be a point on the curve with canonical coordinates, and not of low order.
+func (c *core) ValidateKey(pk *point) error {
+	if !c.group().Validate(pk) {
+		return errInvalidPublicKey
+	}
+	// the point at infinity has no affine coordinates, so only points of small order dividing
+	// the cofactor remain to be rejected
+	if cofactor := c.group().Cofactor(); cofactor > 1 {
+		if cY := c.ScalarMult(pk, []byte{cofactor}); cY.X.Sign() == 0 && cY.Y.Sign() == 0 {
+			return errInvalidPublicKey
+		}
+	}
+	return nil
+}
+
 // https://tools.ietf.org/html/rfc6979#section-2.3.2
 func bits2int(in []byte, qlen int) *big.Int {
 	out := new(big.Int).SetBytes(in)
@@ -246,16 +391,22 @@ func bits2octets(in []byte, q *big.Int, rolen int) []byte {
 }
 
 // rfc6979nonce generates nonce according to [RFC6979](https://tools.ietf.org/html/rfc6979).
+// The secret key bx is int2octets(x), and the nonce is returned in the same form.
+// extra is the additional data k' of section 3.6, which may be nil.
+// Operations on secret values run in constant time, except for the rejection of out-of-range
+// candidates of Step H3, which only reveals that a candidate is discarded.
 func rfc6979nonce(
-	sk *big.Int,
+	bx []byte,
 	m []byte,
+	extra []byte,
 	q *big.Int,
-	newHasher func() hash.Hash,
-) *big.Int {
+	hashers *hasherPool,
+) []byte {
 	var (
 		qlen   = q.BitLen()
 		rolen  = (qlen + 7) / 8
-		hasher = newHasher()
+		qbytes = fixedOctets(q, rolen)
+		hasher = hashers.get()
 	)
 
 	// Step A
@@ -265,8 +416,8 @@ func rfc6979nonce(
 	hasher.Write(m)
 	h1 := hasher.Sum(nil)
 	hlen := len(h1)
+	hashers.put(hasher)
 
-	bx := int2octets(sk, rolen)
 	bh := bits2octets(h1, q, rolen)
 
 	// Step B
@@ -279,32 +430,40 @@ func rfc6979nonce(
 	// K = 0x00 0x00 0x00 ... 0x00
 	k := make([]byte, hlen)
 
+	mac := hashers.newHMAC(k)
+	defer mac.release()
+	defer wipe(k)
+	defer wipe(v)
+
 	// Step D ~ G
 	for i := 0; i < 2; i++ {
 		// Set:
-		// K = HMAC_K(V || 0x00 || int2octets(x) || bits2octets(h1))
-		mac := hmac.New(newHasher, k)
+		// K = HMAC_K(V || 0x00 || int2octets(x) || bits2octets(h1) || k')
+		// where k' is the additional data of section 3.6, empty for deterministic nonces
 		mac.Write(v)
 		mac.Write([]byte{byte(i)}) // internal octet
 		mac.Write(bx)
 		mac.Write(bh)
+		mac.Write(extra)
 		mac.Sum(k[:0])
 
 		// Set:
 		// V = HMAC_K(V)
-		mac = hmac.New(newHasher, k)
+		mac.setKey(k)
 		mac.Write(v)
 		mac.Sum(v[:0])
+		mac.Reset()
 	}
 
 	// Step H
+	t := make([]byte, 0, rolen+hlen)
+	defer func() { wipe(t[:cap(t)]) }()
 	for {
 		// Step H1
-		var t []byte
+		t = t[:0]
 
 		// Step H2
-		mac := hmac.New(newHasher, k)
-		for len(t)*8 < qlen {
+		for len(t) < rolen {
 			mac.Write(v)
 			mac.Sum(v[:0])
 			mac.Reset()
@@ -313,16 +472,58 @@ func rfc6979nonce(
 		}
 
 		// Step H3
-		secret := bits2int(t, qlen)
-		if secret.Sign() > 0 && secret.Cmp(q) < 0 {
+		// secret = bits2int(T), which is the leading qlen bits of T
+		secret := make([]byte, rolen)
+		shiftRightOctets(secret, t[:rolen], uint(8*rolen-qlen))
+		if ctIsZero(secret)|(1^ctLess(secret, qbytes)) == 0 {
 			return secret
 		}
+		wipe(secret)
 		mac.Write(v)
 		mac.Write([]byte{0x00})
 		mac.Sum(k[:0])
 
-		mac = hmac.New(newHasher, k)
+		mac.setKey(k)
 		mac.Write(v)
 		mac.Sum(v[:0])
+		mac.Reset()
+	}
+}
+
+// fixedOctets is int2octets for secret values, always returning rolen octets without first trimming
+// leading zeros like big.Int.Bytes. The value must be non-negative.
+func fixedOctets(v *big.Int, rolen int) []byte {
+	if v.BitLen() > 8*rolen {
+		return int2octets(v, rolen)
+	}
+	out := make([]byte, rolen)
+	return v.FillBytes(out)
+}
+
+// shiftRightOctets sets dst to the big-endian integer src shifted right by n (< 8) bits.
+func shiftRightOctets(dst, src []byte, n uint) {
+	var carry byte
+	for i := range src {
+		dst[i] = src[i]>>n | carry
+		carry = byte(uint(src[i]) << (8 - n))
+	}
+}
+
+// ctLess returns 1 if the big-endian integer a is less than b of the same length, otherwise 0,
+// in constant time.
+func ctLess(a, b []byte) int {
+	var borrow int
+	for i := len(a) - 1; i >= 0; i-- {
+		borrow = ((int(a[i]) - int(b[i]) - borrow) >> 8) & 1
+	}
+	return borrow
+}
+
+// ctIsZero returns 1 if all octets are zero, otherwise 0, in constant time.
+func ctIsZero(a []byte) int {
+	var acc byte
+	for _, b := range a {
+		acc |= b
 	}
+	return int((uint(acc) - 1) >> 8 & 1)
 }
@@ -2,3 +2,229 @@
 // Licensed under the MIT license.
 
 package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"hash"
+	"math/big"
+	"testing"
+)
+
+func TestCoreScalarMulAdd(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), secp256k1} {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			var (
+				c = &core{Config: &Config{}, curve: curve}
+				q = curve.Params().N
+			)
+			for i := 0; i < 100; i++ {
+				x, _ := rand.Int(rand.Reader, q)
+				y, _ := rand.Int(rand.Reader, q)
+				z, _ := rand.Int(rand.Reader, q)
+
+				want := new(big.Int).Mul(x, y)
+				want.Add(want, z).Mod(want, q)
+				if got := c.ScalarMulAdd(x, c.SecretOctets(y), c.SecretOctets(z)); got.Cmp(want) != 0 {
+					t.Fatalf("ScalarMulAdd(%v, %v, %v) = %v, want %v", x, y, z, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCoreMulSubVartime(t *testing.T) {
+	tests := []struct {
+		vrf   VRF
+		curve elliptic.Curve
+	}{
+		{NewP256Sha256Tai(), elliptic.P256()},
+		{NewSecp256k1Sha256Tai(), secp256k1},
+	}
+	for _, tt := range tests {
+		curve := tt.curve
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			var (
+				c = tt.vrf.(*vrf).newCore(curve)
+				q = curve.Params().N
+				g = &point{curve.Params().Gx, curve.Params().Gy}
+			)
+			for i := 0; i < 20; i++ {
+				a, _ := rand.Int(rand.Reader, q)
+				b, _ := rand.Int(rand.Reader, q)
+				k1, _ := rand.Int(rand.Reader, q)
+				k2, _ := rand.Int(rand.Reader, q)
+				p1, p2 := c.ScalarBaseMult(a.Bytes()), c.ScalarBaseMult(b.Bytes())
+
+				want := c.Sub(c.ScalarMult(p1, k1.Bytes()), c.ScalarMult(p2, k2.Bytes()))
+				if got := c.MulSubVartime(p1, k1, p2, k2); got.X.Cmp(want.X) != 0 || got.Y.Cmp(want.Y) != 0 {
+					t.Fatalf("MulSubVartime() = (%v, %v), want (%v, %v)", got.X, got.Y, want.X, want.Y)
+				}
+			}
+			// k*G - k*G is the point at infinity
+			k, _ := rand.Int(rand.Reader, q)
+			if got := c.MulSubVartime(g, k, g, k); got.X.Sign() != 0 || got.Y.Sign() != 0 {
+				t.Fatalf("MulSubVartime() = (%v, %v), want (0, 0)", got.X, got.Y)
+			}
+		})
+	}
+}
+
+func TestConstantTimeOctets(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		a, b := make([]byte, 5), make([]byte, 5)
+		rand.Read(a)
+		rand.Read(b)
+		if i%10 == 0 {
+			copy(b, a)
+		}
+		if i%7 == 0 {
+			a = make([]byte, 5)
+		}
+		x, y := new(big.Int).SetBytes(a), new(big.Int).SetBytes(b)
+
+		if got, want := ctLess(a, b), x.Cmp(y) < 0; (got == 1) != want {
+			t.Fatalf("ctLess(%x, %x) = %v", a, b, got)
+		}
+		if got, want := ctIsZero(a), x.Sign() == 0; (got == 1) != want {
+			t.Fatalf("ctIsZero(%x) = %v", a, got)
+		}
+		n := uint(i % 8)
+		got := make([]byte, len(a))
+		shiftRightOctets(got, a, n)
+		if want := new(big.Int).Rsh(x, n); new(big.Int).SetBytes(got).Cmp(want) != 0 {
+			t.Fatalf("shiftRightOctets(%x, %v) = %x, want %x", a, n, got, want)
+		}
+	}
+}
+
+func TestCoreStrictValidation(t *testing.T) {
+	tests := []struct {
+		vrf   VRF
+		curve elliptic.Curve
+	}{
+		{NewP256Sha256Tai(), elliptic.P256()},
+		{NewSecp256k1Sha256Tai(), secp256k1},
+	}
+	for _, tt := range tests {
+		curve := tt.curve
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			var (
+				c      = tt.vrf.(*vrf).newCore(curve)
+				params = curve.Params()
+				g      = &point{params.Gx, params.Gy}
+			)
+			defer c.release()
+
+			// public keys
+			keys := []struct {
+				name  string
+				pk    *point
+				valid bool
+			}{
+				{"base point", g, true},
+				{"negated", &point{params.Gx, new(big.Int).Sub(params.P, params.Gy)}, true},
+				{"off curve", &point{params.Gx, new(big.Int).Add(params.Gy, big.NewInt(1))}, false},
+				{"infinity", &point{new(big.Int), new(big.Int)}, false},
+				{"non-canonical", &point{params.Gx, new(big.Int).Add(params.Gy, params.P)}, false},
+				{"nil", &point{}, false},
+			}
+			for _, k := range keys {
+				if err := c.ValidateKey(k.pk); (err == nil) != k.valid {
+					t.Errorf("ValidateKey(%s) error = %v, want valid %v", k.name, err, k.valid)
+				}
+			}
+
+			// proofs
+			sk := &ecdsa.PrivateKey{D: big.NewInt(1)}
+			sk.Curve, sk.X, sk.Y = curve, params.Gx, params.Gy
+			_, pi, err := tt.vrf.Prove(sk, []byte("alpha"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ptlen := 1 + (params.BitSize+7)/8
+
+			bigS := append([]byte{}, pi...)
+			params.N.FillBytes(bigS[len(bigS)-(params.N.BitLen()+7)/8:])
+			if _, _, _, err := c.DecodeProof(bigS); err != ErrInvalidProofScalar {
+				t.Errorf("DecodeProof() of s = q error = %v, want %v", err, ErrInvalidProofScalar)
+			}
+			if _, err := tt.vrf.Verify(&sk.PublicKey, []byte("alpha"), bigS); err != ErrInvalidProofScalar {
+				t.Errorf("Verify() of s = q error = %v, want %v", err, ErrInvalidProofScalar)
+			}
+
+			bigX := append([]byte{}, pi...)
+			params.P.FillBytes(bigX[1:ptlen])
+			if _, err := c.Unmarshal(bigX[:ptlen]); err == nil {
+				t.Error("Unmarshal() accepted x = p")
+			}
+			if _, err := tt.vrf.Verify(&sk.PublicKey, []byte("alpha"), bigX); err == nil {
+				t.Error("Verify() accepted Gamma of x = p")
+			}
+
+			bad := &ecdsa.PublicKey{Curve: curve, X: params.Gx, Y: new(big.Int).Add(params.Gy, big.NewInt(1))}
+			if _, err := tt.vrf.Verify(bad, []byte("alpha"), pi); err != errInvalidPublicKey {
+				t.Errorf("Verify() of an invalid key error = %v, want %v", err, errInvalidPublicKey)
+			}
+			if _, err := tt.vrf.NewVerifier(bad).Verify([]byte("alpha"), pi); err != errInvalidPublicKey {
+				t.Errorf("verifier.Verify() of an invalid key error = %v, want %v", err, errInvalidPublicKey)
+			}
+		})
+	}
+}
+
+// countingHasher counts the hashes computed by hash_to_curve.
+type countingHasher struct {
+	hash.Hash
+	sums *int
+}
+
+func (h countingHasher) Sum(b []byte) []byte {
+	*h.sums++
+	return h.Hash.Sum(b)
+}
+
+func TestHashToCurveCandidates(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), secp256k1} {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			var (
+				sums int
+				cfg  = NewP256Sha256Tai().(*vrf).cfg
+			)
+			if curve == secp256k1 {
+				cfg = NewSecp256k1Sha256Tai().(*vrf).cfg
+			}
+			cfg.NewHasher = func() hash.Hash { return countingHasher{sha256.New(), &sums} }
+			var (
+				plain = New(&cfg).(*vrf).newCore(curve)
+				fixed = New(&cfg, WithFixedHashToCurve(64)).(*vrf).newCore(curve)
+				pk    = &point{curve.Params().Gx, curve.Params().Gy}
+			)
+			defer plain.release()
+			defer fixed.release()
+			for i := 0; i < 20; i++ {
+				alpha := []byte{byte(i)}
+				sums = 0
+				want, err := plain.HashToCurve(pk, alpha)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tried := sums
+
+				sums = 0
+				got, err := fixed.HashToCurve(pk, alpha)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.X.Cmp(want.X) != 0 || got.Y.Cmp(want.Y) != 0 {
+					t.Fatalf("H differs with WithFixedHashToCurve for alpha %x", alpha)
+				}
+				if sums != 64 {
+					t.Fatalf("HashToCurve() tried %v candidates, want 64 (%v without the option)", sums, tried)
+				}
+			}
+		})
+	}
+}
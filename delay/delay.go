@@ -0,0 +1,92 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package delay chains a VRF output into a verifiable delay function, for randomness that the last
+// party to reveal can't grind: the randomness is derived from the VDF of beta, which takes at least
+// the delay of the VDF to compute, even with beta. When the reveal of the proof is due before that
+// delay, e.g. within a block, the prover has to reveal or withhold without knowing the outcome.
+//
+// The VDF is pluggable; Wesolowski is the VDF of Wesolowski over an RSA group.
+package delay
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+var errBeta = errors.New("delay: beta doesn't match the proof")
+
+// VDF is a verifiable delay function: Eval takes a fixed sequential time, and Verify is fast.
+type VDF interface {
+	// Eval computes the output of x, and its proof.
+	Eval(x []byte) (y, proof []byte, err error)
+	// Verify checks that y is the output of x.
+	Verify(x, y, proof []byte) error
+}
+
+// Output is a VRF output of alpha, delayed by a VDF.
+type Output struct {
+	Alpha []byte `json:"alpha"`
+	Beta  []byte `json:"beta"`
+	Pi    []byte `json:"pi"`
+	// Y is the output of the VDF of Beta, and VDFProof its proof.
+	Y        []byte `json:"y"`
+	VDFProof []byte `json:"vdfProof"`
+}
+
+// Randomness returns the randomness of the output: SHA-256("go-ecvrf/delay\x00" || y).
+func (o *Output) Randomness() []byte {
+	h := sha256.New()
+	h.Write([]byte("go-ecvrf/delay\x00"))
+	h.Write(o.Y)
+	return h.Sum(nil)
+}
+
+// Prove proves alpha with sk, and evaluates the VDF of beta. It takes the delay of the VDF, so the
+// proof should be revealed first, with Reveal, and Complete run by anyone afterwards.
+func Prove(v ecvrf.VRF, sk *ecdsa.PrivateKey, d VDF, alpha []byte) (*Output, error) {
+	out, err := Reveal(v, sk, alpha)
+	if err != nil {
+		return nil, err
+	}
+	if err := Complete(d, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Reveal proves alpha with sk, without the VDF, for the prover to reveal before the delay.
+func Reveal(v ecvrf.VRF, sk *ecdsa.PrivateKey, alpha []byte) (*Output, error) {
+	beta, pi, err := v.Prove(sk, alpha)
+	if err != nil {
+		return nil, err
+	}
+	return &Output{Alpha: append([]byte(nil), alpha...), Beta: beta, Pi: pi}, nil
+}
+
+// Complete evaluates the VDF of the beta of a revealed output. Anyone can run it, as the VDF takes
+// no secret.
+func Complete(d VDF, out *Output) (err error) {
+	out.Y, out.VDFProof, err = d.Eval(out.Beta)
+	return
+}
+
+// Verify checks the chain of the output against the public key: the VRF proof of alpha, then the
+// VDF of beta, and returns the randomness.
+func Verify(v ecvrf.VRF, pk *ecdsa.PublicKey, d VDF, out *Output) ([]byte, error) {
+	beta, err := v.Verify(pk, out.Alpha, out.Pi)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(beta, out.Beta) {
+		return nil, errBeta
+	}
+	if err := d.Verify(beta, out.Y, out.VDFProof); err != nil {
+		return nil, err
+	}
+	return out.Randomness(), nil
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package delay
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+var (
+	errModulus  = errors.New("delay: invalid RSA modulus")
+	errSteps    = errors.New("delay: no squarings")
+	errVDFProof = errors.New("delay: invalid VDF proof")
+)
+
+// Wesolowski is the VDF of Wesolowski, "Efficient verifiable delay functions", over the RSA group of
+// N: y = x^(2^T) mod N, with x hashed from the input, and a proof of a single exponentiation by a
+// prime of 256 bits. Eval takes 2T sequential squarings, and Verify two short exponentiations.
+//
+// The factors of N must be unknown to everyone, e.g. N is the RSA-2048 challenge number, or the
+// result of a multi-party ceremony: anyone knowing them evaluates the VDF at once. Values are taken
+// up to their sign, as -1 is of known order, so y and the proof are the lower of v and N - v.
+type Wesolowski struct {
+	n *big.Int
+	t uint64
+}
+
+// NewWesolowski returns the VDF of T squarings modulo n, an RSA modulus of at least 1024 bits.
+func NewWesolowski(n *big.Int, t uint64) (*Wesolowski, error) {
+	if n == nil || n.BitLen() < 1024 || n.Bit(0) == 0 {
+		return nil, errModulus
+	}
+	if t == 0 {
+		return nil, errSteps
+	}
+	return &Wesolowski{new(big.Int).Set(n), t}, nil
+}
+
+// Eval implements VDF.
+func (w *Wesolowski) Eval(input []byte) (y, proof []byte, err error) {
+	x := w.hashToGroup(input)
+	yv := new(big.Int).Set(x)
+	for i := uint64(0); i < w.t; i++ {
+		yv.Mul(yv, yv).Mod(yv, w.n)
+	}
+	yv = w.canonical(yv)
+	l := w.hashToPrime(x, yv)
+
+	// pi = x^floor(2^T / l), by the long division of 2^T by l, one bit of the quotient per squaring
+	var (
+		pi  = big.NewInt(1)
+		r   = big.NewInt(1)
+		two = big.NewInt(2)
+	)
+	for i := uint64(0); i < w.t; i++ {
+		r.Mul(r, two)
+		pi.Mul(pi, pi).Mod(pi, w.n)
+		if r.Cmp(l) >= 0 {
+			r.Sub(r, l)
+			pi.Mul(pi, x).Mod(pi, w.n)
+		}
+	}
+	return w.encode(yv), w.encode(w.canonical(pi)), nil
+}
+
+// Verify implements VDF, by pi^l * x^(2^T mod l) = y.
+func (w *Wesolowski) Verify(input, y, proof []byte) error {
+	size := (w.n.BitLen() + 7) / 8
+	if len(y) != size || len(proof) != size {
+		return errVDFProof
+	}
+	yv, pi := new(big.Int).SetBytes(y), new(big.Int).SetBytes(proof)
+	if !w.isCanonical(yv) || !w.isCanonical(pi) {
+		return errVDFProof
+	}
+	x := w.hashToGroup(input)
+	l := w.hashToPrime(x, yv)
+	r := new(big.Int).Exp(big.NewInt(2), new(big.Int).SetUint64(w.t), l)
+	got := new(big.Int).Exp(pi, l, w.n)
+	got.Mul(got, new(big.Int).Exp(x, r, w.n)).Mod(got, w.n)
+	if w.canonical(got).Cmp(yv) != 0 {
+		return errVDFProof
+	}
+	return nil
+}
+
+// hashToGroup maps the input to an element of the group, by SHA-256 in counter mode over 128 bits
+// more than N, reduced modulo N.
+func (w *Wesolowski) hashToGroup(input []byte) *big.Int {
+	var (
+		buf []byte
+		ctr [4]byte
+	)
+	for i := uint32(0); len(buf) < (w.n.BitLen()+7)/8+16; i++ {
+		binary.BigEndian.PutUint32(ctr[:], i)
+		h := sha256.New()
+		h.Write([]byte("go-ecvrf/delay/wesolowski/x\x00"))
+		h.Write(w.n.Bytes())
+		h.Write(ctr[:])
+		h.Write(input)
+		buf = h.Sum(buf)
+	}
+	x := new(big.Int).SetBytes(buf)
+	return w.canonical(x.Mod(x, w.n))
+}
+
+// hashToPrime derives the prime l of 256 bits of the proof from the statement.
+func (w *Wesolowski) hashToPrime(x, y *big.Int) *big.Int {
+	var t, ctr [8]byte
+	binary.BigEndian.PutUint64(t[:], w.t)
+	for i := uint64(0); ; i++ {
+		binary.BigEndian.PutUint64(ctr[:], i)
+		h := sha256.New()
+		h.Write([]byte("go-ecvrf/delay/wesolowski/l\x00"))
+		h.Write(w.n.Bytes())
+		h.Write(t[:])
+		h.Write(w.encode(x))
+		h.Write(w.encode(y))
+		h.Write(ctr[:])
+		l := new(big.Int).SetBytes(h.Sum(nil))
+		l.SetBit(l, 255, 1)
+		if l.ProbablyPrime(20) {
+			return l
+		}
+	}
+}
+
+// canonical returns the representative of v up to its sign, the lower of v and N - v.
+func (w *Wesolowski) canonical(v *big.Int) *big.Int {
+	if neg := new(big.Int).Sub(w.n, v); neg.Cmp(v) < 0 {
+		return neg
+	}
+	return v
+}
+
+func (w *Wesolowski) isCanonical(v *big.Int) bool {
+	return v.Sign() > 0 && new(big.Int).Sub(w.n, v).Cmp(v) >= 0
+}
+
+func (w *Wesolowski) encode(v *big.Int) []byte {
+	b := make([]byte, (w.n.BitLen()+7)/8)
+	return v.FillBytes(b)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"io"
+	"math/big"
+)
+
+// designatedFront is the domain separation octet of the designated-verifier challenge and nonces,
+// after the suite string, apart from those of the VRF, ring, DLEQ and OPRF hashes.
+const designatedFront = 0x14
+
+// ProveDesignated proves that beta is the output of alpha under sk, like Prove, but only to the
+// designated verifier of the public key W: the proof shows that Gamma = x*H, or that its prover
+// knows the private key of W (Jakobsson, Sako and Impagliazzo). The verifier, who didn't make the
+// proof, is convinced, but can make such proofs of any beta with SimulateDesignated, so the proof
+// convinces nobody else, e.g. in private lotteries where winners mustn't be publicly linkable.
+//
+// beta is the same as the one of Prove. The proof is Gamma || c1 || c2 || s1 || s2, of 129 octets
+// for P256 and secp256k1, and its nonces are derived from the key, H and W, as for Prove.
+// v must be a VRF object of this package.
+func ProveDesignated(v VRF, sk *ecdsa.PrivateKey, verifier *ecdsa.PublicKey, alpha []byte) (beta, pi []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, nil, errUnsupportedVRF
+	}
+	if err = impl.checkPrivateKey(sk); err != nil {
+		return
+	}
+	core, Y, W, err := impl.designatedKeys(&sk.PublicKey, verifier)
+	if err != nil {
+		return
+	}
+	defer core.release()
+	H, err := core.HashToCurve(Y, alpha)
+	if err != nil {
+		return
+	}
+	x := core.SecretOctets(sk.D)
+	defer wipe(x)
+	gamma, err := core.BlindScalarMult(H, x)
+	if err != nil {
+		return
+	}
+
+	// the nonce k of the real statement, and the response s2 and challenge c2 of the simulated one
+	seed := append(append([]byte{core.SuiteString, designatedFront}, core.Marshal(H)...), core.Marshal(W)...)
+	seed = append(seed, 0)
+	var nonces [3][]byte
+	for i := range nonces {
+		seed[len(seed)-1] = byte(i)
+		if nonces[i], err = core.HedgedNonceOctets(x, seed); err != nil {
+			return
+		}
+		defer wipe(nonces[i])
+	}
+	var (
+		k      = nonces[0]
+		s2     = new(big.Int).SetBytes(nonces[1])
+		c2     = new(big.Int).SetBytes(nonces[2][:core.N()])
+		params = sk.Curve.Params()
+		U2     = core.MulSubVartime(&point{params.Gx, params.Gy}, s2, W, c2)
+	)
+	U1, err := core.BlindScalarBaseMult(k)
+	if err != nil {
+		return
+	}
+	V1, err := core.BlindScalarMult(H, k)
+	if err != nil {
+		return
+	}
+	c1 := new(big.Int).Xor(designatedChallenge(core, Y, W, H, gamma, U1, V1, U2), c2)
+	s1, err := core.BlindScalarMulAdd(c1, x, k)
+	if err != nil {
+		return
+	}
+	return core.GammaToHash(gamma), encodeDesignated(core, gamma, c1, c2, s1, s2), nil
+}
+
+// VerifyDesignated checks the proof pi of ProveDesignated that beta is the output of alpha under
+// pk, for the designated verifier of the public key verifier, and returns beta. Only the holder
+// of the private key of verifier may trust it. v must be a VRF object of this package.
+func VerifyDesignated(v VRF, pk, verifier *ecdsa.PublicKey, alpha, pi []byte) (beta []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	core, Y, W, err := impl.designatedKeys(pk, verifier)
+	if err != nil {
+		return
+	}
+	defer core.release()
+	var (
+		ptlen = (pk.Curve.Params().BitSize+7)/8 + 1
+		clen  = core.N()
+		qlen  = (core.Q().BitLen() + 7) / 8
+	)
+	if len(pi) != ptlen+2*clen+2*qlen {
+		return nil, ErrInvalidProofLength
+	}
+	gamma, err := core.Unmarshal(pi[:ptlen])
+	if err != nil {
+		return
+	}
+	var (
+		off = ptlen + 2*clen
+		c1  = new(big.Int).SetBytes(pi[ptlen : ptlen+clen])
+		c2  = new(big.Int).SetBytes(pi[ptlen+clen : off])
+		s1  = new(big.Int).SetBytes(pi[off : off+qlen])
+		s2  = new(big.Int).SetBytes(pi[off+qlen:])
+	)
+	if s1.Cmp(core.Q()) >= 0 || s2.Cmp(core.Q()) >= 0 {
+		return nil, ErrInvalidProofScalar
+	}
+	H, err := core.HashToCurve(Y, alpha)
+	if err != nil {
+		return
+	}
+	var (
+		params = pk.Curve.Params()
+		B      = &point{params.Gx, params.Gy}
+		U1     = core.MulSubVartime(B, s1, Y, c1)
+		V1     = core.MulSubVartime(H, s1, gamma, c1)
+		U2     = core.MulSubVartime(B, s2, W, c2)
+	)
+	if designatedChallenge(core, Y, W, H, gamma, U1, V1, U2).Cmp(new(big.Int).Xor(c1, c2)) != 0 {
+		return nil, ErrInvalidProof
+	}
+	return core.GammaToHash(gamma), nil
+}
+
+// SimulateDesignated makes a proof of alpha under pk for the designated verifier of the private key
+// verifier, which VerifyDesignated accepts, with a random beta read from rand: since the verifier
+// can make them, the proofs of ProveDesignated convince nobody else. v must be a VRF object of this package.
+func SimulateDesignated(v VRF, pk *ecdsa.PublicKey, verifier *ecdsa.PrivateKey, alpha []byte, rand io.Reader) (beta, pi []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, nil, errUnsupportedVRF
+	}
+	if err = impl.checkPrivateKey(verifier); err != nil {
+		return
+	}
+	core, Y, W, err := impl.designatedKeys(pk, &verifier.PublicKey)
+	if err != nil {
+		return
+	}
+	defer core.release()
+	H, err := core.HashToCurve(Y, alpha)
+	if err != nil {
+		return
+	}
+	// Gamma is random, and the DLEQ statement is the simulated one, with the challenge c1 and response s1
+	var scalars [4][]byte
+	for i := range scalars {
+		if scalars[i], err = core.sampleScalar(rand); err != nil {
+			return
+		}
+		defer wipe(scalars[i])
+	}
+	var (
+		gamma  = core.ScalarMult(H, scalars[0])
+		c1     = new(big.Int).SetBytes(scalars[1][:core.N()])
+		s1     = new(big.Int).SetBytes(scalars[2])
+		k      = scalars[3]
+		params = pk.Curve.Params()
+		U1     = core.MulSubVartime(&point{params.Gx, params.Gy}, s1, Y, c1)
+		V1     = core.MulSubVartime(H, s1, gamma, c1)
+	)
+	U2, err := core.BlindScalarBaseMult(k)
+	if err != nil {
+		return
+	}
+	c2 := new(big.Int).Xor(designatedChallenge(core, Y, W, H, gamma, U1, V1, U2), c1)
+	w := core.SecretOctets(verifier.D)
+	defer wipe(w)
+	s2, err := core.BlindScalarMulAdd(c2, w, k)
+	if err != nil {
+		return
+	}
+	return core.GammaToHash(gamma), encodeDesignated(core, gamma, c1, c2, s1, s2), nil
+}
+
+// designatedKeys validates the key of the prover and the one of the verifier, over the same curve,
+// and returns their points with the core of the curve, which should be released after use.
+func (v *vrf) designatedKeys(pk, verifier *ecdsa.PublicKey) (core *core, Y, W *point, err error) {
+	if err = v.checkPublicKey(pk); err != nil {
+		return
+	}
+	if err = v.checkPublicKey(verifier); err != nil {
+		return
+	}
+	if verifier.Curve != pk.Curve {
+		return nil, nil, nil, errInvalidPublicKey
+	}
+	core = v.newCore(pk.Curve)
+	Y, W = &point{pk.X, pk.Y}, &point{verifier.X, verifier.Y}
+	if err = core.ValidateKey(Y); err == nil {
+		err = core.ValidateKey(W)
+	}
+	if err != nil {
+		core.release()
+		return nil, nil, nil, err
+	}
+	return
+}
+
+// designatedChallenge hashes the statements and the commitments of the designated-verifier proof
+// into the challenge c1 XOR c2.
+func designatedChallenge(core *core, Y, W, H, gamma, U1, V1, U2 *point) *big.Int {
+	hasher := core.getCachedHasher()
+	hasher.Reset()
+	hasher.Write([]byte{core.SuiteString, designatedFront})
+	for _, pt := range []*point{Y, W, H, gamma, U1, V1, U2} {
+		hasher.Write(core.Marshal(pt))
+	}
+	hasher.Write(core.domainBack())
+	return bits2int(hasher.Sum(nil), core.N()*8)
+}
+
+// encodeDesignated encodes Gamma || c1 || c2 || s1 || s2.
+func encodeDesignated(core *core, gamma *point, c1, c2, s1, s2 *big.Int) []byte {
+	qlen := (core.Q().BitLen() + 7) / 8
+	pi := core.Marshal(gamma)
+	pi = append(pi, int2octets(c1, core.N())...)
+	pi = append(pi, int2octets(c2, core.N())...)
+	pi = append(pi, int2octets(s1, qlen)...)
+	return append(pi, int2octets(s2, qlen)...)
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+)
+
+// dleqChallengeFront is the domain separation octet of the DLEQ challenge, after the suite string,
+// apart from those of the VRF and of the ring proofs.
+const dleqChallengeFront = 0x12
+
+// ProveDLEQ proves that log_B(Y) = log_H(Gamma), for Y the public key of sk and B the generator,
+// by the Chaum-Pedersen proof within the VRF proofs, and returns Gamma = x*H and the proof c || s.
+// H is any point of the curve, e.g. the blinded element of an OPRF, or a base of a key rotation
+// protocol. The challenge is domain-separated from the one of Prove, so neither proof passes for
+// the other, and the nonce is derived from the key and H, blinded and hedged as for Prove.
+// v must be a VRF object of this package.
+func ProveDLEQ(v VRF, sk *ecdsa.PrivateKey, h [2]*big.Int) (gamma [2]*big.Int, proof []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return gamma, nil, errUnsupportedVRF
+	}
+	if err = impl.checkPrivateKey(sk); err != nil {
+		return
+	}
+	if h[0] == nil || h[1] == nil || !sk.Curve.IsOnCurve(h[0], h[1]) {
+		return gamma, nil, errInvalidPoint
+	}
+	core := impl.newCore(sk.Curve)
+	defer core.release()
+	Y := &point{sk.X, sk.Y}
+	if err = core.ValidateKey(Y); err != nil {
+		return
+	}
+	x := core.SecretOctets(sk.D)
+	defer wipe(x)
+	G, proof, err := dleqProve(core, Y, &point{h[0], h[1]}, x)
+	if err != nil {
+		return
+	}
+	return [2]*big.Int{G.X, G.Y}, proof, nil
+}
+
+// dleqProve computes Gamma = x*H and the proof c || s of its DLEQ with Y = x*B.
+func dleqProve(core *core, Y, H *point, x []byte) (G *point, proof []byte, err error) {
+	if G, err = core.BlindScalarMult(H, x); err != nil {
+		return
+	}
+
+	// the nonce data differs from the one of Prove, so that the same H never meets the same nonce
+	// under two challenges
+	k, err := core.HedgedNonceOctets(x, append([]byte{core.SuiteString, dleqChallengeFront}, core.Marshal(H)...))
+	if err != nil {
+		return
+	}
+	defer wipe(k)
+	kB, err := core.BlindScalarBaseMult(k)
+	if err != nil {
+		return
+	}
+	kH, err := core.BlindScalarMult(H, k)
+	if err != nil {
+		return
+	}
+	c := dleqChallenge(core, Y, H, G, kB, kH)
+	s, err := core.BlindScalarMulAdd(c, x, k)
+	if err != nil {
+		return
+	}
+	return G, append(int2octets(c, core.N()), int2octets(s, (core.Q().BitLen()+7)/8)...), nil
+}
+
+// VerifyDLEQ checks the proof of ProveDLEQ that log_B(Y) = log_H(Gamma), for Y the public key pk.
+// v must be a VRF object of this package.
+func VerifyDLEQ(v VRF, pk *ecdsa.PublicKey, h, gamma [2]*big.Int, proof []byte) error {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return errUnsupportedVRF
+	}
+	if err := impl.checkPublicKey(pk); err != nil {
+		return err
+	}
+	for _, pt := range [][2]*big.Int{h, gamma} {
+		if pt[0] == nil || pt[1] == nil || !pk.Curve.IsOnCurve(pt[0], pt[1]) {
+			return errInvalidPoint
+		}
+	}
+	core := impl.newCore(pk.Curve)
+	defer core.release()
+	Y := &point{pk.X, pk.Y}
+	if err := core.ValidateKey(Y); err != nil {
+		return err
+	}
+	return dleqVerify(core, Y, &point{h[0], h[1]}, &point{gamma[0], gamma[1]}, proof)
+}
+
+// dleqVerify checks the proof c || s of the DLEQ of Gamma = x*H with Y = x*B.
+func dleqVerify(core *core, Y, H, G *point, proof []byte) error {
+	var (
+		clen = core.N()
+		qlen = (core.Q().BitLen() + 7) / 8
+	)
+	if len(proof) != clen+qlen {
+		return ErrInvalidProofLength
+	}
+	c := new(big.Int).SetBytes(proof[:clen])
+	s := new(big.Int).SetBytes(proof[clen:])
+	if s.Cmp(core.Q()) >= 0 {
+		return ErrInvalidProofScalar
+	}
+	var (
+		params = core.curve.Params()
+		U      = core.MulSubVartime(&point{params.Gx, params.Gy}, s, Y, c)
+		V      = core.MulSubVartime(H, s, G, c)
+	)
+	if dleqChallenge(core, Y, H, G, U, V).Cmp(c) != 0 {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// dleqChallenge hashes the points of the DLEQ proof into its challenge, like ECVRF_challenge_generation
+// of RFC9381 with its own domain octet.
+func dleqChallenge(core *core, Y, H, gamma, U, V *point) *big.Int {
+	hasher := core.getCachedHasher()
+	hasher.Reset()
+	hasher.Write([]byte{core.SuiteString, dleqChallengeFront})
+	for _, pt := range []*point{Y, H, gamma, U, V} {
+		hasher.Write(core.Marshal(pt))
+	}
+	hasher.Write(core.domainBack())
+	return bits2int(hasher.Sum(nil), core.N()*8)
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build go1.20
+// +build go1.20
+
+package ecvrf
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+var errNoECDHCurve = errors.New("curve of the ecdh key has no VRF suite")
+
+// ProveECDH is like VRF.Prove, with a private key of crypto/ecdh, which is converted internally.
+// The key must be over a NIST curve of the suite of v, e.g. P-256 for NewP256Sha256Tai, as X25519
+// has no suite. v must be a VRF object of this package.
+func ProveECDH(v VRF, sk *ecdh.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, nil, errUnsupportedVRF
+	}
+	if sk == nil {
+		return nil, nil, errInvalidPrivateKey
+	}
+	c, err := ecdhCurve(sk.Curve())
+	if err != nil {
+		return
+	}
+	pk, err := impl.unmarshalPublicKey(c, sk.PublicKey().Bytes())
+	if err != nil {
+		return
+	}
+	scalar := sk.Bytes()
+	defer wipe(scalar)
+	key := &ecdsa.PrivateKey{PublicKey: *pk, D: new(big.Int).SetBytes(scalar)}
+	return v.Prove(key, alpha)
+}
+
+// VerifyECDH is like VRF.Verify, with a public key of crypto/ecdh. v must be a VRF object of this package.
+func VerifyECDH(v VRF, pk *ecdh.PublicKey, alpha, pi []byte) (beta []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if pk == nil {
+		return nil, errInvalidPublicKey
+	}
+	c, err := ecdhCurve(pk.Curve())
+	if err != nil {
+		return
+	}
+	key, err := impl.unmarshalPublicKey(c, pk.Bytes())
+	if err != nil {
+		return
+	}
+	return v.Verify(key, alpha, pi)
+}
+
+// ecdhCurve returns the elliptic curve of an ecdh curve. X25519 has none.
+func ecdhCurve(c ecdh.Curve) (elliptic.Curve, error) {
+	switch c {
+	case ecdh.P256():
+		return elliptic.P256(), nil
+	case ecdh.P384():
+		return elliptic.P384(), nil
+	case ecdh.P521():
+		return elliptic.P521(), nil
+	}
+	return nil, errNoECDHCurve
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package ecvrftest provides a fake ecvrf.VRF for the unit tests of applications, which is
+// deterministic, fast, and can be told to reject proofs, so tests need neither fixtures nor the
+// cost of real proofs. It gives no security at all: anyone knowing the public key can forge a
+// proof, so it must never be used outside of tests.
+//
+// For a public key pk, encoded compressed, the output of alpha is beta = SHA256(alpha || pk), and
+// its proof is pi = "ecvrftest" || beta.
+package ecvrftest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// ProofSize is the size of the proofs of the fake.
+const ProofSize = len(proofTag) + sha256.Size
+
+const proofTag = "ecvrftest"
+
+var (
+	// ErrForced is returned by the verifications failed by FailVerify or FailAll with a nil error.
+	ErrForced = errors.New("ecvrftest: forced verification failure")
+
+	errInvalidKey   = errors.New("ecvrftest: invalid key")
+	errInvalidProof = errors.New("ecvrftest: invalid proof")
+)
+
+// VRF is the fake. Its zero value is ready to use, and it's safe for concurrent use.
+type VRF struct {
+	mu      sync.Mutex
+	failAll error
+	fail    map[string]error
+}
+
+var _ ecvrf.VRF = (*VRF)(nil)
+
+// New returns a fake VRF.
+func New() *VRF {
+	return &VRF{}
+}
+
+// FailVerify makes the verifications of the proofs of alpha fail with err, or ErrForced if err is nil.
+func (v *VRF) FailVerify(alpha []byte, err error) {
+	if err == nil {
+		err = ErrForced
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.fail == nil {
+		v.fail = make(map[string]error)
+	}
+	v.fail[string(alpha)] = err
+}
+
+// FailAll makes all the verifications fail with err, or ErrForced if err is nil.
+func (v *VRF) FailAll(err error) {
+	if err == nil {
+		err = ErrForced
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.failAll = err
+}
+
+// Reset cancels the failures set by FailVerify and FailAll.
+func (v *VRF) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.failAll = nil
+	v.fail = nil
+}
+
+// Prove implements ecvrf.VRF. It never fails for a key with a public key.
+func (v *VRF) Prove(sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
+	if sk == nil {
+		return nil, nil, errInvalidKey
+	}
+	if err := v.ValidatePublicKey(&sk.PublicKey); err != nil {
+		return nil, nil, err
+	}
+	beta = hash(&sk.PublicKey, alpha)
+	pi = append([]byte(proofTag), beta...)
+	return
+}
+
+// Verify implements ecvrf.VRF.
+func (v *VRF) Verify(pk *ecdsa.PublicKey, alpha, pi []byte) (beta []byte, err error) {
+	return v.AppendVerify(nil, pk, alpha, pi)
+}
+
+// AppendVerify implements ecvrf.VRF.
+func (v *VRF) AppendVerify(dst []byte, pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error) {
+	if err := v.ValidatePublicKey(pk); err != nil {
+		return nil, err
+	}
+	v.mu.Lock()
+	err := v.failAll
+	if err == nil {
+		err = v.fail[string(alpha)]
+	}
+	v.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	beta := hash(pk, alpha)
+	if string(pi) != proofTag+string(beta) {
+		return nil, errInvalidProof
+	}
+	return append(dst, beta...), nil
+}
+
+// ValidatePublicKey implements ecvrf.VRF. It only checks that pk has a curve and coordinates.
+func (v *VRF) ValidatePublicKey(pk *ecdsa.PublicKey) error {
+	if pk == nil || pk.Curve == nil || pk.X == nil || pk.Y == nil {
+		return errInvalidKey
+	}
+	return nil
+}
+
+// NewVerifier implements ecvrf.VRF.
+func (v *VRF) NewVerifier(pk *ecdsa.PublicKey) ecvrf.Verifier {
+	return &verifier{v, pk}
+}
+
+// EncodeToCurve implements ecvrf.VRF, by the multiple of the base point by beta.
+func (v *VRF) EncodeToCurve(pk *ecdsa.PublicKey, alpha []byte) (x, y *big.Int, err error) {
+	if err := v.ValidatePublicKey(pk); err != nil {
+		return nil, nil, err
+	}
+	x, y = pk.Curve.ScalarBaseMult(hash(pk, alpha))
+	return
+}
+
+// GenerateNonce implements ecvrf.VRF, by SHA256(sk || data) reduced modulo the group order.
+func (v *VRF) GenerateNonce(sk *ecdsa.PrivateKey, data []byte) *big.Int {
+	if sk == nil || sk.D == nil || sk.Curve == nil {
+		return nil
+	}
+	h := sha256.New()
+	h.Write(sk.D.Bytes())
+	h.Write(data)
+	k := new(big.Int).SetBytes(h.Sum(nil))
+	return k.Mod(k, sk.Curve.Params().N)
+}
+
+// ProofToHash returns the beta of a well-formed proof of the fake, without verifying it.
+func (v *VRF) ProofToHash(pi []byte) ([]byte, error) {
+	if len(pi) != ProofSize || string(pi[:len(proofTag)]) != proofTag {
+		return nil, errInvalidProof
+	}
+	return append([]byte(nil), pi[len(proofTag):]...), nil
+}
+
+// Params implements ecvrf.VRF. The fake works over any curve and has no security, so the curve,
+// the key size and the security level are left empty.
+func (v *VRF) Params() ecvrf.SuiteParams {
+	return ecvrf.SuiteParams{
+		Name:      "ecvrftest",
+		Hash:      "SHA-256",
+		ProofSize: ProofSize,
+		BetaSize:  sha256.Size,
+	}
+}
+
+// NewKey returns the P-256 key of the name, the same for every call, e.g. NewKey("alice").
+func NewKey(name string) *ecdsa.PrivateKey {
+	c := elliptic.P256()
+	h := sha256.Sum256([]byte("ecvrftest key\x00" + name))
+	d := new(big.Int).SetBytes(h[:])
+	d.Mod(d, new(big.Int).Sub(c.Params().N, big.NewInt(1)))
+	d.Add(d, big.NewInt(1))
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = c
+	sk.X, sk.Y = c.ScalarBaseMult(d.Bytes())
+	return sk
+}
+
+type verifier struct {
+	v  *VRF
+	pk *ecdsa.PublicKey
+}
+
+func (vr *verifier) Verify(alpha, pi []byte) ([]byte, error) {
+	return vr.v.Verify(vr.pk, alpha, pi)
+}
+
+func (vr *verifier) AppendVerify(dst []byte, alpha, pi []byte) ([]byte, error) {
+	return vr.v.AppendVerify(dst, vr.pk, alpha, pi)
+}
+
+// hash returns SHA256(alpha || pk), pk being encoded compressed.
+func hash(pk *ecdsa.PublicKey, alpha []byte) []byte {
+	h := sha256.New()
+	h.Write(alpha)
+	h.Write(elliptic.MarshalCompressed(pk.Curve, pk.X, pk.Y))
+	return h.Sum(nil)
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrftest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// Suite adapts a VRF to CheckProperties, whatever the types of its keys, so the authors of suites
+// can check theirs, e.g. in a test:
+//
+//	if err := ecvrftest.CheckProperties(ecvrftest.ECDSASuite(vrf, elliptic.P256())); err != nil {
+//		t.Fatal(err)
+//	}
+type Suite struct {
+	Name        string
+	GenerateKey func(rand io.Reader) (sk, pk interface{}, err error)
+	Prove       func(sk interface{}, alpha []byte) (beta, pi []byte, err error)
+	Verify      func(pk interface{}, alpha, pi []byte) (beta []byte, err error)
+	// ProofToHash is optional.
+	ProofToHash func(pi []byte) (beta []byte, err error)
+}
+
+// ECDSASuite returns the Suite of a VRF over the curve. ProofToHash is the method of v if it has
+// one, or ecvrf.ProofToHash.
+func ECDSASuite(v ecvrf.VRF, c elliptic.Curve) Suite {
+	s := Suite{
+		Name: c.Params().Name,
+		GenerateKey: func(rand io.Reader) (interface{}, interface{}, error) {
+			sk, err := generateKey(c, rand)
+			if err != nil {
+				return nil, nil, err
+			}
+			return sk, &sk.PublicKey, nil
+		},
+		Prove: func(sk interface{}, alpha []byte) ([]byte, []byte, error) {
+			return v.Prove(sk.(*ecdsa.PrivateKey), alpha)
+		},
+		Verify: func(pk interface{}, alpha, pi []byte) ([]byte, error) {
+			return v.Verify(pk.(*ecdsa.PublicKey), alpha, pi)
+		},
+		ProofToHash: func(pi []byte) ([]byte, error) {
+			return ecvrf.ProofToHash(v, c, pi)
+		},
+	}
+	if p, ok := v.(interface {
+		ProofToHash(pi []byte) ([]byte, error)
+	}); ok {
+		s.ProofToHash = p.ProofToHash
+	}
+	return s
+}
+
+// generateKey reads the scalar from rand, as ecdsa.GenerateKey ignores rand since Go 1.26, which
+// would make the checks of a seeded stream unrepeatable.
+func generateKey(c elliptic.Curve, rand io.Reader) (*ecdsa.PrivateKey, error) {
+	n := c.Params().N
+	b := make([]byte, (n.BitLen()+7)/8)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		if d := new(big.Int).SetBytes(b); d.Sign() > 0 && d.Cmp(n) < 0 {
+			sk := &ecdsa.PrivateKey{D: d}
+			sk.Curve = c
+			sk.X, sk.Y = c.ScalarBaseMult(b)
+			return sk, nil
+		}
+	}
+}
+
+// Ed25519Suite returns the Suite of a VRF over edwards25519.
+func Ed25519Suite(v ecvrf.Ed25519VRF) Suite {
+	return Suite{
+		Name: "edwards25519",
+		GenerateKey: func(rand io.Reader) (interface{}, interface{}, error) {
+			pk, sk, err := ed25519.GenerateKey(rand)
+			return sk, pk, err
+		},
+		Prove: func(sk interface{}, alpha []byte) ([]byte, []byte, error) {
+			return v.Prove(sk.(ed25519.PrivateKey), alpha)
+		},
+		Verify: func(pk interface{}, alpha, pi []byte) ([]byte, error) {
+			return v.Verify(pk.(ed25519.PublicKey), alpha, pi)
+		},
+		ProofToHash: v.ProofToHash,
+	}
+}
+
+// CheckOption configures CheckProperties.
+type CheckOption func(*checkConfig)
+
+type checkConfig struct {
+	rand       io.Reader
+	iterations int
+	maxAlpha   int
+}
+
+// WithRand sets the source of the keys and inputs, crypto/rand.Reader by default. A seeded
+// stream makes failures reproducible.
+func WithRand(r io.Reader) CheckOption {
+	return func(c *checkConfig) {
+		c.rand = r
+	}
+}
+
+// WithIterations sets the number of random keys and inputs, 16 by default.
+func WithIterations(n int) CheckOption {
+	return func(c *checkConfig) {
+		c.iterations = n
+	}
+}
+
+// WithMaxAlpha sets the maximum size of the random inputs, 64 octets by default.
+func WithMaxAlpha(n int) CheckOption {
+	return func(c *checkConfig) {
+		c.maxAlpha = n
+	}
+}
+
+// CheckProperties checks the invariants of a VRF on random keys and inputs:
+//   - the proof of Prove verifies, with the same beta;
+//   - proving again gives the same beta;
+//   - ProofToHash, if given, gives beta;
+//   - the proof fails for another key and for another input;
+//   - the proof fails with any bit flipped at any offset, and if truncated or extended by one octet.
+//
+// It returns an error with the property and the input of the first failure.
+func CheckProperties(s Suite, opts ...CheckOption) error {
+	cfg := checkConfig{rand: rand.Reader, iterations: 16, maxAlpha: 64}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	fail := func(alpha []byte, format string, args ...interface{}) error {
+		return fmt.Errorf("ecvrftest: %s: %s, alpha %x", s.Name, fmt.Sprintf(format, args...), alpha)
+	}
+	var size [2]byte
+	for i := 0; i < cfg.iterations; i++ {
+		sk, pk, err := s.GenerateKey(cfg.rand)
+		if err != nil {
+			return err
+		}
+		_, otherPK, err := s.GenerateKey(cfg.rand)
+		if err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(cfg.rand, size[:]); err != nil {
+			return err
+		}
+		alpha := make([]byte, (int(size[0])<<8|int(size[1]))%(cfg.maxAlpha+1))
+		if _, err := io.ReadFull(cfg.rand, alpha); err != nil {
+			return err
+		}
+
+		beta, pi, err := s.Prove(sk, alpha)
+		if err != nil {
+			return fail(alpha, "prove: %v", err)
+		}
+		if b, err := s.Verify(pk, alpha, pi); err != nil {
+			return fail(alpha, "proof rejected: %v", err)
+		} else if !bytes.Equal(b, beta) {
+			return fail(alpha, "verify gave beta %x, prove %x", b, beta)
+		}
+		if b, _, err := s.Prove(sk, alpha); err != nil || !bytes.Equal(b, beta) {
+			return fail(alpha, "beta not unique: %x, %x", b, beta)
+		}
+		if s.ProofToHash != nil {
+			if b, err := s.ProofToHash(pi); err != nil || !bytes.Equal(b, beta) {
+				return fail(alpha, "proof to hash gave beta %x, %v", b, err)
+			}
+		}
+
+		if _, err := s.Verify(otherPK, alpha, pi); err == nil {
+			return fail(alpha, "proof accepted for another key")
+		}
+		otherAlpha := append(append([]byte(nil), alpha...), 0)
+		if _, err := s.Verify(pk, otherAlpha, pi); err == nil {
+			return fail(alpha, "proof accepted for another input")
+		}
+		if len(alpha) > 0 {
+			otherAlpha = append(otherAlpha[:0], alpha...)
+			otherAlpha[0] ^= 1
+			if _, err := s.Verify(pk, otherAlpha, pi); err == nil {
+				return fail(alpha, "proof accepted for another input")
+			}
+		}
+
+		tampered := append([]byte(nil), pi...)
+		for off := range tampered {
+			bit := byte(1) << uint(i%8)
+			tampered[off] ^= bit
+			if _, err := s.Verify(pk, alpha, tampered); err == nil {
+				return fail(alpha, "proof accepted with bit %d flipped at offset %d", i%8, off)
+			}
+			tampered[off] ^= bit
+		}
+		if _, err := s.Verify(pk, alpha, pi[:len(pi)-1]); err == nil {
+			return fail(alpha, "truncated proof accepted")
+		}
+		if _, err := s.Verify(pk, alpha, append(append([]byte(nil), pi...), 0)); err == nil {
+			return fail(alpha, "extended proof accepted")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,476 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+)
+
+var errNotFIPSApprovedSuite = errors.New("suite is not approved in FIPS mode")
+
+// Ed25519VRF is the interface of VRFs over edwards25519, with keys of crypto/ed25519.
+type Ed25519VRF interface {
+	// Prove constructs a VRF proof `pi` for the given input `alpha`,
+	// using the private key `sk`. The hash output is returned as `beta`.
+	// `sk` is either a key of crypto/ed25519, i.e. the seed then the public key, or the bare seed
+	// of ed25519.SeedSize octets, which is expanded by the rules of RFC 8032 like
+	// ed25519.NewKeyFromSeed does. The clamped scalar of the expanded key isn't a key.
+	Prove(sk ed25519.PrivateKey, alpha []byte) (beta, pi []byte, err error)
+
+	// Verify checks the proof `pi` of the message `alpha` against the given
+	// public key `pk`. The hash output is returned as `beta`.
+	Verify(pk ed25519.PublicKey, alpha, pi []byte) (beta []byte, err error)
+
+	// ProofToHash returns the hash output `beta` of the proof `pi`, without verifying it.
+	ProofToHash(pi []byte) (beta []byte, err error)
+
+	// Params returns the parameters of the suite, such as the sizes of proofs and outputs.
+	Params() SuiteParams
+}
+
+// ed25519VRF implements the ECVRF suites over edwards25519 with SHA512 and Elligator 2.
+type ed25519VRF struct {
+	suite       byte
+	rfc9381     bool // ECVRF-EDWARDS25519-SHA512-ELL2 of RFC9381, otherwise draft-03
+	batchCompat bool // proofs carry k*B and k*H instead of c
+}
+
+// NewEd25519Sha512Elligator2 creates the VRF object of ECVRF-ED25519-SHA512-Elligator2, as specified
+// by [draft-irtf-cfrg-vrf-03](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-03.html) and implemented
+// by crypto_vrf_ietfdraft03 of libsodium, which is the VRF of Algorand and of Cardano's PraosVRF.
+// Proofs and outputs are byte-for-byte those of libsodium, and Verify accepts the same proofs,
+// including those with a non-reduced s.
+func NewEd25519Sha512Elligator2() Ed25519VRF {
+	return &ed25519VRF{suite: 0x04}
+}
+
+// NewEd25519Sha512Ell2 creates the VRF object of ECVRF-EDWARDS25519-SHA512-ELL2 of
+// [RFC9381](https://www.rfc-editor.org/rfc/rfc9381.html), which is also the one of draft-irtf-cfrg-vrf-13.
+// H is computed by encode_to_curve of RFC9380, the challenge commits to the public key, and the
+// strings hashed by the challenge and proof_to_hash end with the domain separator 0x00.
+func NewEd25519Sha512Ell2() Ed25519VRF {
+	return &ed25519VRF{suite: 0x04, rfc9381: true}
+}
+
+// NewEd25519Sha512Ell2BatchCompat is like NewEd25519Sha512Ell2, with the batch-compatible
+// proofs of Cardano's PraosBatchCompatVRF: pi is Gamma || k*B || k*H || s of 128 octets, so that
+// proofs can be verified in batches. Outputs are the same as those of NewEd25519Sha512Ell2.
+func NewEd25519Sha512Ell2BatchCompat() Ed25519VRF {
+	return &ed25519VRF{suite: 0x04, rfc9381: true, batchCompat: true}
+}
+
+const (
+	ed25519ProofLen      = 80  // Gamma (32) || c (16) || s (32)
+	ed25519BatchProofLen = 128 // Gamma (32) || U (32) || V (32) || s (32)
+	ed25519CLen          = 16
+)
+
+// h2cSuiteEll2 is the suite ID of encode_to_curve used by ECVRF-EDWARDS25519-SHA512-ELL2.
+const h2cSuiteEll2 = "edwards25519_XMD:SHA-512_ELL2_NU_"
+
+// Prove implements Ed25519VRF.
+func (v *ed25519VRF) Prove(sk ed25519.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
+	if FIPSMode() {
+		return nil, nil, errNotFIPSApprovedSuite
+	}
+	if len(sk) == ed25519.SeedSize {
+		sk = ed25519.NewKeyFromSeed(sk)
+		defer wipe(sk)
+	}
+	if len(sk) != ed25519.PrivateKeySize {
+		return nil, nil, errInvalidPrivateKey
+	}
+	var (
+		e  = edwards()
+		fq = e.fq
+		pk = sk[ed25519.SeedSize:]
+		Y  edwardsPoint
+	)
+	// the public key is taken from sk, like crypto_vrf_ietfdraft03_prove does
+	if !v.decodePoint(&Y, pk) {
+		return nil, nil, errInvalidPrivateKey
+	}
+
+	// x is the clamped first half of SHA512(seed), the second half keys the nonce
+	az := sha512.Sum512(sk[:ed25519.SeedSize])
+	defer wipe(az[:])
+	az[0] &= 248
+	az[31] &= 127
+	az[31] |= 64
+
+	var X, K fieldElement
+	e.scalarFromBytes(&X, az[:32])
+	defer wipeElement(&X)
+
+	H, hString := v.hashToCurve(&Y, pk, alpha)
+
+	// 2. Gamma = x*H
+	var xb, kb [32]byte
+	defer wipe(xb[:])
+	defer wipe(kb[:])
+	e.scalarBytes(&xb, &X)
+	var gamma, kB, kH edwardsPoint
+	e.scalarMult(&gamma, H, xb[:])
+
+	// 3. k = ECVRF_nonce_generation(SK, h_string), SHA512(truncated_hashed_sk_string || h_string) mod q
+	h := sha512.New()
+	h.Write(az[32:])
+	h.Write(hString[:])
+	kh := h.Sum(nil)
+	defer wipe(kh)
+	defer wipeHasher(h)
+	e.scalarFromWide(&K, kh)
+	defer wipeElement(&K)
+	e.scalarBytes(&kb, &K)
+
+	// 4. c = ECVRF_challenge_generation(Y, H, Gamma, k*B, k*H)
+	e.scalarMult(&kB, &e.b, kb[:])
+	e.scalarMult(&kH, H, kb[:])
+	c := v.challenge(pk, H, &gamma, &kB, &kH)
+
+	// 5. s = (k + c*x) mod q
+	var (
+		C, S fieldElement
+		cb   [32]byte
+	)
+	copy(cb[:], c[:ed25519CLen])
+	e.scalarFromBytes(&C, cb[:])
+	fq.mul(&S, &C, &X)
+	fq.add(&S, &S, &K)
+
+	// 6. pi_string = point_to_string(Gamma) || int_to_string(c, n) || int_to_string(s, qLen),
+	// or point_to_string(Gamma) || point_to_string(k*B) || point_to_string(k*H) || int_to_string(s, qLen)
+	if v.batchCompat {
+		pi = make([]byte, ed25519BatchProofLen)
+		e.encode(pi[32:], &kB)
+		e.encode(pi[64:], &kH)
+	} else {
+		pi = make([]byte, ed25519ProofLen)
+		copy(pi[32:], c[:ed25519CLen])
+	}
+	e.encode(pi, &gamma)
+	var sb [32]byte
+	e.scalarBytes(&sb, &S)
+	for i := range sb {
+		pi[len(pi)-1-i] = sb[i]
+	}
+	return v.proofToHash(&gamma), pi, nil
+}
+
+// Verify implements Ed25519VRF.
+func (v *ed25519VRF) Verify(pk ed25519.PublicKey, alpha, pi []byte) (beta []byte, err error) {
+	if FIPSMode() {
+		return nil, errNotFIPSApprovedSuite
+	}
+	var t ed25519Terms
+	if err = v.verify(&t, pk, alpha, pi); err != nil {
+		return
+	}
+	return v.proofToHash(&t.gamma), nil
+}
+
+// verify checks the proof, and leaves its values in t, with U and V, and c of compact proofs.
+func (v *ed25519VRF) verify(t *ed25519Terms, pk ed25519.PublicKey, alpha, pi []byte) error {
+	if err := v.decodeTerms(t, pk, alpha, pi); err != nil {
+		return err
+	}
+	var dU, dV edwardsPoint
+	t.announcements(&dU, &dV)
+
+	if v.batchCompat {
+		e := edwards()
+		if !e.equal(&dU, &t.U) || !e.equal(&dV, &t.V) {
+			return ErrInvalidProof
+		}
+		return nil
+	}
+
+	// 7. c' = ECVRF_challenge_generation(Y, H, Gamma, U, V), 8. accept if c and c' are equal
+	derived := v.challenge(pk, t.H, &t.gamma, &dU, &dV)
+	if subtle.ConstantTimeCompare(derived[:ed25519CLen], t.c[:ed25519CLen]) != 1 {
+		return ErrInvalidProof
+	}
+	t.U, t.V = dU, dV
+	return nil
+}
+
+// ed25519Terms are the values of a proof and of its statement, which Verify checks.
+type ed25519Terms struct {
+	Y, gamma, U, V edwardsPoint // U and V are only decoded from batch-compatible proofs
+	H              *edwardsPoint
+	c              [32]byte // the octets of c, derived from U and V for batch-compatible proofs
+	cBE, sb        [32]byte // c and s as big-endian scalars
+}
+
+// decodeTerms validates the key, decodes the proof, and computes H, plus c for batch-compatible proofs.
+func (v *ed25519VRF) decodeTerms(t *ed25519Terms, pk ed25519.PublicKey, alpha, pi []byte) error {
+	e := edwards()
+
+	// ECVRF_validate_key: canonical, on the curve, and not of small order
+	var y8 edwardsPoint
+	if len(pk) != ed25519.PublicKeySize || !e.isCanonical(pk) || !v.decodePoint(&t.Y, pk) {
+		return errInvalidPublicKey
+	}
+	if e.mulByCofactor(&y8, &t.Y); e.isIdentity(&y8) {
+		return errInvalidPublicKey
+	}
+
+	// 1. D = ECVRF_decode_proof(pi_string)
+	var S fieldElement
+	if !v.decodeProof(&t.gamma, &t.U, &t.V, &t.c, &S, pi) {
+		return ErrInvalidProof
+	}
+	e.scalarBytes(&t.sb, &S)
+
+	t.H, _ = v.hashToCurve(&t.Y, pk, alpha)
+	if v.batchCompat {
+		// c is derived from U and V, which must then be the points given by s and c
+		copy(t.c[:], v.challenge(pk, t.H, &t.gamma, &t.U, &t.V)[:ed25519CLen])
+	}
+	// the scalars are big-endian, c uses the first 16 octets
+	for i := 0; i < ed25519CLen; i++ {
+		t.cBE[31-i] = t.c[i]
+	}
+	return nil
+}
+
+// announcements sets U = s*B - c*Y and V = s*H - c*Gamma, steps 5 and 6 of ECVRF_verify.
+func (t *ed25519Terms) announcements(U, V *edwardsPoint) {
+	var (
+		e          = edwards()
+		cY, cGamma edwardsPoint
+	)
+	e.scalarMult(U, &e.b, t.sb[:])
+	e.scalarMult(&cY, &t.Y, t.cBE[:])
+	e.neg(&cY, &cY)
+	e.add(U, U, &cY)
+	e.scalarMult(V, t.H, t.sb[:])
+	e.scalarMult(&cGamma, &t.gamma, t.cBE[:])
+	e.neg(&cGamma, &cGamma)
+	e.add(V, V, &cGamma)
+}
+
+// ProofToHash implements Ed25519VRF.
+func (v *ed25519VRF) ProofToHash(pi []byte) (beta []byte, err error) {
+	if FIPSMode() {
+		return nil, errNotFIPSApprovedSuite
+	}
+	var (
+		gamma, U, V edwardsPoint
+		S           fieldElement
+		c           [32]byte
+	)
+	if !v.decodeProof(&gamma, &U, &V, &c, &S, pi) {
+		return nil, ErrInvalidProof
+	}
+	return v.proofToHash(&gamma), nil
+}
+
+// Ed25519ProofTerms are the values of an Ed25519 proof, encoded as in the proofs: the point H of
+// the input, Gamma, the announcements U = k*B and V = k*H, the challenge c of 16 octets and the
+// response s, little-endian.
+type Ed25519ProofTerms struct {
+	H, Gamma, U, V, C, S []byte
+}
+
+// Ed25519Terms verifies the proof pi of alpha by pk with v, an Ed25519VRF of this package, and
+// returns its values, e.g. for test vectors with the intermediate values of the proofs. U and V
+// are recomputed from s and c, so they're the ones of the prover only for valid proofs.
+func Ed25519Terms(v Ed25519VRF, pk ed25519.PublicKey, alpha, pi []byte) (*Ed25519ProofTerms, error) {
+	impl, ok := v.(*ed25519VRF)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if FIPSMode() {
+		return nil, errNotFIPSApprovedSuite
+	}
+	var t ed25519Terms
+	if err := impl.verify(&t, pk, alpha, pi); err != nil {
+		return nil, err
+	}
+	e := edwards()
+	terms := &Ed25519ProofTerms{
+		H:     make([]byte, 32),
+		Gamma: append([]byte(nil), pi[:32]...),
+		U:     make([]byte, 32),
+		V:     make([]byte, 32),
+		C:     append([]byte(nil), t.c[:ed25519CLen]...),
+		S:     append([]byte(nil), pi[len(pi)-32:]...),
+	}
+	e.encode(terms.H, t.H)
+	e.encode(terms.U, &t.U)
+	e.encode(terms.V, &t.V)
+	return terms, nil
+}
+
+// decodePoint implements string_to_point. Like libsodium, draft-03 decodes points by ge25519_frombytes,
+// and RFC9381 requires the encoding of RFC8032: y less than p, and no negative zero x.
+func (v *ed25519VRF) decodePoint(p *edwardsPoint, s []byte) bool {
+	e := edwards()
+	if !e.decode(p, s) {
+		return false
+	}
+	if v.rfc9381 {
+		return e.isCanonical(s) && (s[31]>>7 == 0 || e.fp.isZero(&p.x) == 0)
+	}
+	return true
+}
+
+// decodeProof splits pi into Gamma, the octets of c or the points U and V, and s reduced modulo q.
+// Like libsodium, draft-03 doesn't require s to be less than q.
+func (v *ed25519VRF) decodeProof(gamma, U, V *edwardsPoint, c *[32]byte, s *fieldElement, pi []byte) bool {
+	e := edwards()
+	n := ed25519ProofLen
+	if v.batchCompat {
+		n = ed25519BatchProofLen
+	}
+	if len(pi) != n || !v.decodePoint(gamma, pi[:32]) {
+		return false
+	}
+	if v.batchCompat {
+		if !v.decodePoint(U, pi[32:64]) || !v.decodePoint(V, pi[64:96]) {
+			return false
+		}
+	} else {
+		copy(c[:], pi[32:32+ed25519CLen])
+	}
+	sb := pi[n-32:]
+	if v.rfc9381 {
+		var buf [32]byte
+		for i := range buf {
+			buf[i] = sb[31-i]
+		}
+		if l := limbsFromBytes(&buf); !lessThan(&l, &e.fq.p) {
+			return false
+		}
+	}
+	e.scalarFromBytes(s, sb)
+	return true
+}
+
+// hashToCurve implements ECVRF_encode_to_curve, returning H and its encoding: Elligator 2 of
+// draft-03 over SHA512(suite_string || 0x01 || PK_string || alpha_string), or encode_to_curve of
+// RFC9380 with the salt PK_string.
+func (v *ed25519VRF) hashToCurve(Y *edwardsPoint, pk, alpha []byte) (*edwardsPoint, [32]byte) {
+	var (
+		e    = edwards()
+		hStr [32]byte
+		H    edwardsPoint
+	)
+	if v.rfc9381 {
+		dst := append([]byte("ECVRF_"+h2cSuiteEll2), v.suite)
+		uniform := expandMessageXMD(append(append([]byte{}, pk...), alpha...), dst, 48)
+
+		var u fieldElement
+		e.fieldFromWide(&u, uniform)
+		e.mapToCurveEll2(&H, &u)
+		e.mulByCofactor(&H, &H)
+		e.encode(hStr[:], &H)
+		return &H, hStr
+	}
+
+	var y [32]byte
+	e.encode(y[:], Y)
+	// r = first 32 octets of SHA512(suite_string || 0x01 || PK_string || alpha_string), with bit 255 cleared
+	h := sha512.New()
+	h.Write([]byte{v.suite, 0x01})
+	h.Write(y[:])
+	h.Write(alpha)
+	r := h.Sum(nil)
+	r[31] &= 0x7f
+	e.fromUniform(&H, r[:32])
+
+	// H is used through its encoding, as libsodium does
+	e.encode(hStr[:], &H)
+	e.decode(&H, hStr[:])
+	return &H, hStr
+}
+
+// challenge implements ECVRF_challenge_generation, returning the whole SHA512 digest.
+// RFC9381 also hashes the public key, and ends the string with 0x00.
+func (v *ed25519VRF) challenge(pk []byte, points ...*edwardsPoint) []byte {
+	var (
+		e   = edwards()
+		buf [32]byte
+	)
+	h := sha512.New()
+	h.Write([]byte{v.suite, 0x02})
+	if v.rfc9381 {
+		h.Write(pk)
+	}
+	for _, p := range points {
+		e.encode(buf[:], p)
+		h.Write(buf[:])
+	}
+	h.Write(v.domainBack())
+	return h.Sum(nil)
+}
+
+// proofToHash implements ECVRF_proof_to_hash, SHA512(suite_string || 0x03 || point_to_string(cofactor * Gamma)),
+// followed by 0x00 for RFC9381.
+func (v *ed25519VRF) proofToHash(gamma *edwardsPoint) []byte {
+	var (
+		e   = edwards()
+		g   edwardsPoint
+		buf [32]byte
+	)
+	e.mulByCofactor(&g, gamma)
+	e.encode(buf[:], &g)
+	h := sha512.New()
+	h.Write([]byte{v.suite, 0x03})
+	h.Write(buf[:])
+	h.Write(v.domainBack())
+	return h.Sum(nil)
+}
+
+// domainBack returns the domain separator appended to hashed strings, empty for draft-03.
+func (v *ed25519VRF) domainBack() []byte {
+	if v.rfc9381 {
+		return rfc9381Back
+	}
+	return nil
+}
+
+// expandMessageXMD implements expand_message_xmd of RFC9380 with SHA512, for outputs of at most 255*64 octets.
+func expandMessageXMD(msg, dst []byte, n int) []byte {
+	var (
+		h        = sha512.New()
+		dstPrime = append(append([]byte{}, dst...), byte(len(dst)))
+		zPad     [128]byte // the block size of SHA512
+		out      = make([]byte, 0, n+sha512.Size)
+	)
+	// b_0 = H(Z_pad || msg || l_i_b_str || I2OSP(0, 1) || DST_prime)
+	h.Write(zPad[:])
+	h.Write(msg)
+	h.Write([]byte{byte(n >> 8), byte(n), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	// b_1 = H(b_0 || I2OSP(1, 1) || DST_prime), b_i = H(strxor(b_0, b_(i-1)) || I2OSP(i, 1) || DST_prime)
+	bi := make([]byte, sha512.Size)
+	for i := 1; len(out) < n; i++ {
+		h.Reset()
+		if i == 1 {
+			h.Write(b0)
+		} else {
+			for j := range bi {
+				bi[j] ^= b0[j]
+			}
+			h.Write(bi)
+		}
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(bi[:0])
+		out = append(out, bi...)
+	}
+	return out[:n]
+}
+
+// wipeElement zeroes the secret field element x.
+func wipeElement(x *fieldElement) {
+	*x = fieldElement{}
+}
@@ -0,0 +1,516 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"math/big"
+	"sync"
+)
+
+// edwardsPoint is a point of edwards25519 in extended coordinates (X : Y : Z : T),
+// representing the affine point (X/Z, Y/Z) with T = XY/Z. The identity is (0 : 1 : 1 : 0).
+type edwardsPoint struct {
+	x, y, z, t fieldElement
+}
+
+// edwards25519 implements the group law of the twisted Edwards curve -x² + y² = 1 + dx²y²
+// over GF(2^255 - 19), using the complete addition formulas from
+// [Hisil-Wong-Carter-Dawson 2008](https://eprint.iacr.org/2008/522).
+// Unlike the Weierstrass curves, the group has the cofactor 8.
+type edwards25519 struct {
+	fp      *field
+	fq      *field       // the scalar field modulo the prime order ℓ
+	d, d2   fieldElement // d and 2*d in Montgomery form
+	sqrtM1  fieldElement // a square root of -1
+	a       fieldElement // the coefficient A = 486662 of the birationally equivalent Montgomery curve
+	r256    fieldElement // 2^256 mod ℓ, in the scalar field
+	r256p   fieldElement // 2^256 mod p
+	c1      fieldElement // sqrt(-486664) with sgn0 = 0, for the map of RFC9380 to edwards25519
+	b       edwardsPoint // the base point
+	sqrtExp *big.Int     // (p-5)/8
+	chiExp  *big.Int     // (p-1)/2
+	order   *big.Int     // ℓ
+}
+
+var (
+	ed25519Once  sync.Once
+	ed25519Arith *edwards25519
+)
+
+// ed25519Base is the encoding of the base point, whose y is 4/5.
+var ed25519Base = [32]byte{
+	0x58, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+	0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+}
+
+// edwards returns the arithmetic of edwards25519, building it at the first call.
+func edwards() *edwards25519 {
+	ed25519Once.Do(func() {
+		p := new(big.Int).Lsh(big.NewInt(1), 255)
+		p.Sub(p, big.NewInt(19))
+		l, _ := new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+
+		e := &edwards25519{
+			fp:      newField(p),
+			fq:      newField(l),
+			sqrtExp: new(big.Int).Rsh(new(big.Int).Sub(p, big.NewInt(5)), 3),
+			chiExp:  new(big.Int).Rsh(new(big.Int).Sub(p, big.NewInt(1)), 1),
+			order:   l,
+		}
+		// d = -121665/121666
+		d := new(big.Int).ModInverse(big.NewInt(121666), p)
+		d.Mul(d, big.NewInt(-121665)).Mod(d, p)
+		e.fp.fromBig(&e.d, d)
+		e.fp.add(&e.d2, &e.d, &e.d)
+
+		// sqrt(-1) = 2^((p-1)/4)
+		e.fp.fromBig(&e.sqrtM1, new(big.Int).Exp(big.NewInt(2), new(big.Int).Rsh(e.chiExp, 1), p))
+		e.fp.fromBig(&e.a, big.NewInt(486662))
+
+		r := new(big.Int).Lsh(big.NewInt(1), 256)
+		e.fp.fromBig(&e.r256p, new(big.Int).Mod(r, p))
+		e.fq.fromBig(&e.r256, r.Mod(r, l))
+
+		var c1 fieldElement
+		e.fp.fromBig(&c1, big.NewInt(-486664))
+		e.sqrt(&e.c1, &c1)
+		if e.isNegative(&e.c1) == 1 {
+			e.fp.neg(&e.c1, &e.c1)
+		}
+
+		if !e.decode(&e.b, ed25519Base[:]) {
+			panic("ecvrf: invalid edwards25519 base point")
+		}
+		ed25519Arith = e
+	})
+	return ed25519Arith
+}
+
+// identity sets p to the neutral element.
+func (e *edwards25519) identity(p *edwardsPoint) {
+	p.x = fieldElement{}
+	p.y = e.fp.one
+	p.z = e.fp.one
+	p.t = fieldElement{}
+}
+
+// add sets r = p + q. The formulas are complete, and also used for doubling.
+func (e *edwards25519) add(r, p, q *edwardsPoint) {
+	var (
+		fp                     = e.fp
+		a, b, c, d, t0, t1, ee fieldElement
+		f, g, h                fieldElement
+	)
+	fp.sub(&t0, &p.y, &p.x)
+	fp.sub(&t1, &q.y, &q.x)
+	fp.mul(&a, &t0, &t1) // A = (Y1-X1)(Y2-X2)
+	fp.add(&t0, &p.y, &p.x)
+	fp.add(&t1, &q.y, &q.x)
+	fp.mul(&b, &t0, &t1) // B = (Y1+X1)(Y2+X2)
+	fp.mul(&c, &p.t, &q.t)
+	fp.mul(&c, &c, &e.d2) // C = 2d T1 T2
+	fp.mul(&d, &p.z, &q.z)
+	fp.add(&d, &d, &d) // D = 2 Z1 Z2
+	fp.sub(&ee, &b, &a)
+	fp.sub(&f, &d, &c)
+	fp.add(&g, &d, &c)
+	fp.add(&h, &b, &a)
+	fp.mul(&r.x, &ee, &f)
+	fp.mul(&r.y, &g, &h)
+	fp.mul(&r.t, &ee, &h)
+	fp.mul(&r.z, &f, &g)
+}
+
+// neg sets r = -p.
+func (e *edwards25519) neg(r, p *edwardsPoint) {
+	e.fp.neg(&r.x, &p.x)
+	r.y, r.z = p.y, p.z
+	e.fp.neg(&r.t, &p.t)
+}
+
+// mulByCofactor sets r = 8 * p.
+func (e *edwards25519) mulByCofactor(r, p *edwardsPoint) {
+	e.add(r, p, p)
+	e.add(r, r, r)
+	e.add(r, r, r)
+}
+
+// equal returns whether p and q are the same point.
+func (e *edwards25519) equal(p, q *edwardsPoint) bool {
+	var a, b fieldElement
+	e.fp.mul(&a, &p.x, &q.z)
+	e.fp.mul(&b, &q.x, &p.z)
+	eqX := e.fp.equal(&a, &b)
+	e.fp.mul(&a, &p.y, &q.z)
+	e.fp.mul(&b, &q.y, &p.z)
+	return eqX&e.fp.equal(&a, &b) == 1
+}
+
+// isIdentity returns whether p is the neutral element.
+func (e *edwards25519) isIdentity(p *edwardsPoint) bool {
+	return e.fp.isZero(&p.x) == 1 && e.fp.equal(&p.y, &p.z) == 1
+}
+
+func selectEdwardsPoint(r, p, q *edwardsPoint, cond uint64) {
+	selectElement(&r.x, &p.x, &q.x, cond)
+	selectElement(&r.y, &p.y, &q.y, cond)
+	selectElement(&r.z, &p.z, &q.z, cond)
+	selectElement(&r.t, &p.t, &q.t, cond)
+}
+
+// scalarMult sets r = k * p for a big-endian scalar k, using 4-bit fixed windows.
+// Table lookups are done in constant time, and the running time depends only on the length of k.
+// k is not reduced, so that small order components of p are kept.
+func (e *edwards25519) scalarMult(r, p *edwardsPoint, k []byte) {
+	// table[i] = i * p
+	var table [16]edwardsPoint
+	e.identity(&table[0])
+	table[1] = *p
+	for i := 2; i < 16; i++ {
+		e.add(&table[i], &table[i-1], p)
+	}
+
+	var acc, entry edwardsPoint
+	e.identity(&acc)
+	for _, b := range k {
+		for _, d := range [2]uint64{uint64(b >> 4), uint64(b & 0xf)} {
+			e.add(&acc, &acc, &acc)
+			e.add(&acc, &acc, &acc)
+			e.add(&acc, &acc, &acc)
+			e.add(&acc, &acc, &acc)
+
+			entry = table[0]
+			for j := uint64(1); j < 16; j++ {
+				eq := 1 ^ (((d ^ j) | -(d ^ j)) >> 63)
+				selectEdwardsPoint(&entry, &table[j], &entry, eq)
+			}
+			e.add(&acc, &acc, &entry)
+		}
+	}
+	*r = acc
+}
+
+// multiScalarMultVartime sets r = sum(k[i] * p[i]) for big-endian scalars of 32 octets, by Straus'
+// interleaved 4-bit windows sharing the doublings. It's variable time, for public values only.
+func (e *edwards25519) multiScalarMultVartime(r *edwardsPoint, k [][32]byte, p []edwardsPoint) {
+	tables := make([][16]edwardsPoint, len(p))
+	for i := range p {
+		e.identity(&tables[i][0])
+		tables[i][1] = p[i]
+		for j := 2; j < 16; j++ {
+			e.add(&tables[i][j], &tables[i][j-1], &p[i])
+		}
+	}
+	var acc edwardsPoint
+	e.identity(&acc)
+	for n := 0; n < 64; n++ {
+		e.add(&acc, &acc, &acc)
+		e.add(&acc, &acc, &acc)
+		e.add(&acc, &acc, &acc)
+		e.add(&acc, &acc, &acc)
+		for i := range k {
+			d := k[i][n/2] >> 4
+			if n%2 == 1 {
+				d = k[i][n/2] & 0xf
+			}
+			if d != 0 {
+				e.add(&acc, &acc, &tables[i][d])
+			}
+		}
+	}
+	*r = acc
+}
+
+// decode sets p to the point of the 32-octet little-endian encoding, following ge25519_frombytes
+// of libsodium: y is reduced modulo p, and the sign bit of x = 0 is ignored.
+// false is returned if there's no point with the given y.
+func (e *edwards25519) decode(p *edwardsPoint, s []byte) bool {
+	var (
+		fp                    = e.fp
+		buf                   [32]byte
+		y, u, v, v3, x, vxx   fieldElement
+		t, one, check, check2 fieldElement
+	)
+	if len(s) != 32 {
+		return false
+	}
+	for i := range buf {
+		buf[i] = s[31-i]
+	}
+	sign := uint64(buf[0] >> 7)
+	buf[0] &= 0x7f
+	y = limbsFromBytes(&buf)
+	fp.fromLimbs(&y, &y)
+
+	// x = u v³ (u v⁷)^((p-5)/8), where u = y² - 1 and v = d y² + 1
+	one = fp.one
+	fp.sqr(&u, &y)
+	fp.mul(&v, &u, &e.d)
+	fp.sub(&u, &u, &one)
+	fp.add(&v, &v, &one)
+	fp.sqr(&v3, &v)
+	fp.mul(&v3, &v3, &v)
+	fp.sqr(&x, &v3)
+	fp.mul(&x, &x, &v)
+	fp.mul(&x, &x, &u)
+	fp.exp(&x, &x, e.sqrtExp)
+	fp.mul(&x, &x, &v3)
+	fp.mul(&x, &x, &u)
+
+	// v x² is either u or -u if u/v is a square
+	fp.sqr(&vxx, &x)
+	fp.mul(&vxx, &vxx, &v)
+	fp.sub(&check, &vxx, &u)
+	fp.add(&check2, &vxx, &u)
+	hasMRoot, hasPRoot := fp.isZero(&check), fp.isZero(&check2)
+	fp.mul(&t, &x, &e.sqrtM1)
+	selectElement(&x, &x, &t, hasMRoot)
+
+	fp.neg(&t, &x)
+	selectElement(&x, &t, &x, e.isNegative(&x)^sign)
+	p.x, p.y, p.z = x, y, one
+	fp.mul(&p.t, &x, &y)
+	return hasMRoot|hasPRoot == 1
+}
+
+// isCanonical returns whether the y coordinate of the encoding s is less than p.
+func (e *edwards25519) isCanonical(s []byte) bool {
+	var buf [32]byte
+	for i := range buf {
+		buf[i] = s[31-i]
+	}
+	buf[0] &= 0x7f
+	y := limbsFromBytes(&buf)
+	return lessThan(&y, &e.fp.p)
+}
+
+// isNegative returns the least significant bit of the canonical value of x.
+func (e *edwards25519) isNegative(x *fieldElement) uint64 {
+	var buf [32]byte
+	e.fp.bytes(&buf, x)
+	return uint64(buf[31] & 1)
+}
+
+// encode writes the 32-octet little-endian encoding of p to out.
+func (e *edwards25519) encode(out []byte, p *edwardsPoint) {
+	var (
+		fp         = e.fp
+		buf        [32]byte
+		zinv, x, y fieldElement
+	)
+	fp.inv(&zinv, &p.z)
+	fp.mul(&x, &p.x, &zinv)
+	fp.mul(&y, &p.y, &zinv)
+	fp.bytes(&buf, &y)
+	for i := range buf {
+		out[i] = buf[31-i]
+	}
+	out[31] |= byte(e.isNegative(&x) << 7)
+}
+
+// fromUniform maps the 32-octet string r to a point of the prime order subgroup, following
+// ge25519_from_uniform of libsodium: Elligator 2 to the Montgomery curve, the birational map to
+// edwards25519, and the multiplication by the cofactor. The top bit of r gives the sign of x.
+func (e *edwards25519) fromUniform(p *edwardsPoint, r []byte) {
+	var (
+		fp                         = e.fp
+		buf, s                     [32]byte
+		rr2, x, x2, x3, ee, t, one fieldElement
+	)
+	for i := range buf {
+		buf[i] = r[31-i]
+	}
+	sign := buf[0] & 0x80
+	buf[0] &= 0x7f
+	rr2 = limbsFromBytes(&buf)
+	fp.fromLimbs(&rr2, &rr2)
+	one = fp.one
+
+	// x = -A / (1 + 2r²)
+	fp.sqr(&rr2, &rr2)
+	fp.add(&rr2, &rr2, &rr2)
+	fp.add(&rr2, &rr2, &one)
+	fp.inv(&rr2, &rr2)
+	fp.mul(&x, &e.a, &rr2)
+	fp.neg(&x, &x)
+
+	// e = χ(x³ + Ax² + x)
+	fp.sqr(&x2, &x)
+	fp.mul(&x3, &x, &x2)
+	fp.add(&ee, &x3, &x)
+	fp.mul(&x2, &x2, &e.a)
+	fp.add(&ee, &x2, &ee)
+	fp.exp(&ee, &ee, e.chiExp)
+
+	// x = -x - A if e = -1, i.e. if x³ + Ax² + x is not a square
+	fp.bytes(&buf, &ee)
+	eIsMinus1 := uint64(buf[30] & 1)
+	fp.neg(&t, &x)
+	selectElement(&x, &t, &x, eIsMinus1)
+	t = fieldElement{}
+	selectElement(&t, &e.a, &t, eIsMinus1)
+	fp.sub(&x, &x, &t)
+
+	// y = (x - 1) / (x + 1)
+	fp.add(&t, &x, &one)
+	fp.inv(&t, &t)
+	fp.sub(&x, &x, &one)
+	fp.mul(&x, &x, &t)
+	fp.bytes(&buf, &x)
+	for i := range buf {
+		s[i] = buf[31-i]
+	}
+	s[31] |= sign
+
+	// the encoding of a point on the Montgomery curve always decodes
+	e.decode(p, s[:])
+	e.mulByCofactor(p, p)
+}
+
+// sqrt sets z to a square root of x, and returns 1 if x is a square, otherwise 0.
+func (e *edwards25519) sqrt(z, x *fieldElement) uint64 {
+	var (
+		fp         = e.fp
+		r, rr, rm1 fieldElement
+	)
+	// r = x^((p+3)/8) = x * x^((p-5)/8), which is the root up to a factor of sqrt(-1)
+	fp.exp(&r, x, e.sqrtExp)
+	fp.mul(&r, &r, x)
+	fp.sqr(&rr, &r)
+	isRoot := fp.equal(&rr, x)
+	fp.mul(&rm1, &r, &e.sqrtM1)
+	selectElement(z, &r, &rm1, isRoot)
+	fp.sqr(&rr, z)
+	return fp.equal(&rr, x)
+}
+
+// fieldFromWide sets z to the big-endian integer u of 48 octets reduced modulo p, as hash_to_field
+// of [RFC9380](https://www.rfc-editor.org/rfc/rfc9380.html) does.
+func (e *edwards25519) fieldFromWide(z *fieldElement, u []byte) {
+	var (
+		fp     = e.fp
+		buf    [32]byte
+		lo, hi fieldElement
+	)
+	copy(buf[:], u[16:])
+	lo = limbsFromBytes(&buf)
+	buf = [32]byte{}
+	copy(buf[16:], u[:16])
+	hi = limbsFromBytes(&buf)
+
+	// u = hi * 2^256 + lo
+	fp.fromLimbs(&lo, &lo)
+	fp.fromLimbs(&hi, &hi)
+	fp.mul(&hi, &hi, &e.r256p)
+	fp.add(z, &hi, &lo)
+}
+
+// mapToCurveEll2 sets p to map_to_curve_elligator2_edwards25519(u) of RFC9380: Elligator 2 to
+// curve25519 with Z = 2, followed by the rational map to edwards25519. p is not multiplied by the cofactor.
+func (e *edwards25519) mapToCurveEll2(p *edwardsPoint, u *fieldElement) {
+	var (
+		fp                               = e.fp
+		one, t, x1, x2, gx1, gx2, y1, y2 fieldElement
+		s, y, v, w, den                  fieldElement
+	)
+	one = fp.one
+
+	// x1 = -J / (1 + Z u²), or -J if the denominator is zero
+	fp.sqr(&t, u)
+	fp.add(&t, &t, &t)
+	fp.add(&t, &t, &one)
+	fp.inv(&t, &t)
+	fp.mul(&x1, &e.a, &t)
+	fp.neg(&x1, &x1)
+	fp.neg(&t, &e.a)
+	selectElement(&x1, &t, &x1, fp.isZero(&x1))
+
+	// x2 = -x1 - J, gx = x³ + J x² + x
+	fp.neg(&x2, &x1)
+	fp.sub(&x2, &x2, &e.a)
+	e.montgomeryRHS(&gx1, &x1)
+	e.montgomeryRHS(&gx2, &x2)
+
+	// (s, t) = (x1, sqrt(gx1)) with sgn0(t) = 1 if gx1 is a square, otherwise (x2, sqrt(gx2)) with sgn0(t) = 0
+	isSquare := e.sqrt(&y1, &gx1)
+	e.sqrt(&y2, &gx2)
+	fp.neg(&t, &y1)
+	selectElement(&y1, &t, &y1, 1^e.isNegative(&y1))
+	fp.neg(&t, &y2)
+	selectElement(&y2, &t, &y2, e.isNegative(&y2))
+	selectElement(&s, &x1, &x2, isSquare)
+	selectElement(&y, &y1, &y2, isSquare)
+
+	// (v, w) = (c1 * s / t, (s - 1) / (s + 1)), or the identity if t or s + 1 is zero
+	fp.add(&t, &s, &one)
+	fp.mul(&den, &y, &t)
+	fp.inv(&den, &den)
+	fp.mul(&v, &e.c1, &s)
+	fp.mul(&v, &v, &t)
+	fp.mul(&v, &v, &den)
+	fp.sub(&w, &s, &one)
+	fp.mul(&w, &w, &y)
+	fp.mul(&w, &w, &den)
+	isExceptional := fp.isZero(&den)
+	selectElement(&w, &one, &w, isExceptional)
+
+	p.x, p.y, p.z = v, w, one
+	fp.mul(&p.t, &v, &w)
+}
+
+// montgomeryRHS sets z = x³ + Ax² + x, the right-hand side of the equation of curve25519.
+func (e *edwards25519) montgomeryRHS(z, x *fieldElement) {
+	var (
+		fp    = e.fp
+		x2, t fieldElement
+	)
+	fp.sqr(&x2, x)
+	fp.mul(&t, &x2, x)
+	fp.mul(&x2, &x2, &e.a)
+	fp.add(&t, &t, &x2)
+	fp.add(z, &t, x)
+}
+
+// scalarFromWide sets z to the 64-octet little-endian integer h reduced modulo ℓ,
+// in Montgomery form of the scalar field.
+func (e *edwards25519) scalarFromWide(z *fieldElement, h []byte) {
+	var (
+		fq     = e.fq
+		buf    [32]byte
+		lo, hi fieldElement
+	)
+	for i := range buf {
+		buf[i] = h[31-i]
+	}
+	lo = limbsFromBytes(&buf)
+	for i := range buf {
+		buf[i] = h[63-i]
+	}
+	hi = limbsFromBytes(&buf)
+	wipe(buf[:])
+
+	// h = hi * 2^256 + lo
+	fq.fromLimbs(&lo, &lo)
+	fq.fromLimbs(&hi, &hi)
+	fq.mul(&hi, &hi, &e.r256)
+	fq.add(z, &hi, &lo)
+}
+
+// scalarFromBytes sets z to the 32-octet little-endian integer s reduced modulo ℓ,
+// in Montgomery form of the scalar field.
+func (e *edwards25519) scalarFromBytes(z *fieldElement, s []byte) {
+	var buf [32]byte
+	for i := range buf {
+		buf[i] = s[31-i]
+	}
+	*z = limbsFromBytes(&buf)
+	wipe(buf[:])
+	e.fq.fromLimbs(z, z)
+}
+
+// scalarBytes writes the canonical value of the scalar x as a big-endian integer of 32 octets,
+// the order expected by scalarMult.
+func (e *edwards25519) scalarBytes(out *[32]byte, x *fieldElement) {
+	e.fq.bytes(out, x)
+}
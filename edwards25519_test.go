@@ -0,0 +1,107 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+)
+
+func TestEdwardsScalarBaseMult(t *testing.T) {
+	e := edwards()
+	for i := 0; i < 20; i++ {
+		seed := make([]byte, ed25519.SeedSize)
+		rand.Read(seed)
+		want := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+
+		az := sha512.Sum512(seed)
+		az[0] &= 248
+		az[31] &= 127
+		az[31] |= 64
+		var k [32]byte
+		for j := range k {
+			k[j] = az[31-j]
+		}
+		var p edwardsPoint
+		e.scalarMult(&p, &e.b, k[:])
+		got := make([]byte, 32)
+		e.encode(got, &p)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("scalarMult() = %x, want %x", got, want)
+		}
+
+		var q edwardsPoint
+		if !e.decode(&q, got) {
+			t.Fatalf("decode(%x) failed", got)
+		}
+		e.encode(got, &q)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("encode(decode()) = %x, want %x", got, want)
+		}
+	}
+}
+
+func TestEdwardsMultiScalarMult(t *testing.T) {
+	e := edwards()
+	for n := 1; n <= 5; n++ {
+		var (
+			k    = make([][32]byte, n)
+			p    = make([]edwardsPoint, n)
+			want edwardsPoint
+		)
+		e.identity(&want)
+		for i := range p {
+			var s [32]byte
+			rand.Read(s[:])
+			rand.Read(k[i][:])
+			e.scalarMult(&p[i], &e.b, s[:])
+
+			var kp edwardsPoint
+			e.scalarMult(&kp, &p[i], k[i][:])
+			e.add(&want, &want, &kp)
+		}
+		var got edwardsPoint
+		e.multiScalarMultVartime(&got, k, p)
+		if !e.equal(&got, &want) {
+			t.Fatalf("multiScalarMultVartime() of %v points differs from the sum of scalarMult", n)
+		}
+	}
+}
+
+func TestEdwardsFromUniform(t *testing.T) {
+	e := edwards()
+	l := e.order.FillBytes(make([]byte, 32))
+	for i := 0; i < 20; i++ {
+		r := make([]byte, 32)
+		rand.Read(r)
+		var p, q edwardsPoint
+		e.fromUniform(&p, r)
+		// the point lies in the prime order subgroup
+		e.scalarMult(&q, &p, l)
+		if !e.isIdentity(&q) || e.isIdentity(&p) {
+			t.Fatalf("fromUniform(%x) is not of order ℓ", r)
+		}
+	}
+}
+
+func TestExpandMessageXMD(t *testing.T) {
+	// test vectors of RFC9380 appendix K.3
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA512-256")
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"", "6b9a7312411d92f921c6f68ca0b6380730a1a4d982c507211a90964c394179ba"},
+		{"abc", "0da749f12fbe5483eb066a5f595055679b976e93abe9be6f0f6318bce7aca8dc"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(expandMessageXMD([]byte(tt.msg), dst, 0x20)); got != tt.want {
+			t.Errorf("expandMessageXMD(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
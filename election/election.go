@@ -0,0 +1,233 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package election selects the proposer and the committee of an epoch from a weighted validator
+// set, by the VRF proofs of the validators for the epoch seed, so no one can predict or bias the
+// selection before the proofs are revealed, and anyone can check it from the proofs.
+//
+// Each valid proof gives a validator one score per role, -log2(u)/weight, where u is a uniform
+// value in (0, 1] derived from its beta for the role: the lowest scores win. This is the
+// exponential race of Efraimidis and Spirakis, so the proposer is elected with a probability
+// proportional to its weight, and the committee is a weighted sample without replacement.
+// Scores are computed with integers only, so the selection is the same on all platforms.
+//
+// Validators without a valid proof are left out of the epoch: withholding a proof only removes
+// the validator itself from the selection, and doesn't change the scores of the others.
+package election
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+var (
+	errDuplicateID = errors.New("election: duplicate validator id")
+	errNoEligible  = errors.New("election: no eligible validator")
+	errMismatch    = errors.New("election: result doesn't match the proofs")
+)
+
+// Reasons for leaving a validator out of the epoch, in Entry.
+const (
+	NoWeight     = "no weight"
+	NoProof      = "no proof"
+	InvalidProof = "invalid proof"
+)
+
+// Labels of the outputs derived from beta for each role, by ecvrf.ExpandBeta.
+const (
+	proposerLabel  = "go-ecvrf/election/proposer"
+	committeeLabel = "go-ecvrf/election/committee"
+)
+
+// uniformLen is the length in octets of the uniform values, logFrac the number of fractional bits
+// of the logarithms, and scoreFrac the one of the scores, twice as many so the division by 64-bit
+// weights keeps the precision of the logarithms.
+const (
+	uniformLen = 16
+	logFrac    = 64
+	scoreFrac  = 2 * logFrac
+)
+
+// Validator is a member of the validator set.
+type Validator struct {
+	ID        string
+	PublicKey *ecdsa.PublicKey
+	Weight    uint64
+}
+
+// Entry is the audit data of a validator: its output and scores if it's eligible, or the reason
+// it's left out.
+type Entry struct {
+	ID     string `json:"id"`
+	Weight uint64 `json:"weight"`
+	Beta   []byte `json:"beta,omitempty"`
+	// ProposerScore and CommitteeScore are the scores of -log2(u)/weight, with 128 fractional bits.
+	ProposerScore  *big.Int `json:"proposerScore,omitempty"`
+	CommitteeScore *big.Int `json:"committeeScore,omitempty"`
+	Excluded       string   `json:"excluded,omitempty"`
+}
+
+// Result is the selection of an epoch.
+type Result struct {
+	Proposer string `json:"proposer"`
+	// Committee lists the members from the lowest committee score.
+	Committee []string `json:"committee"`
+	// Entries lists the validators in the order of the set.
+	Entries []*Entry `json:"entries"`
+}
+
+// Alpha returns the input proven by each validator for the epoch seed.
+func Alpha(seed []byte) []byte {
+	return append([]byte("go-ecvrf/election\x00"), seed...)
+}
+
+// Elect verifies the proofs of the validators for the epoch seed, by validator id, and selects
+// the proposer and a committee of at most size members among the validators of valid proofs.
+// It fails if no validator is eligible.
+func Elect(v ecvrf.VRF, validators []Validator, seed []byte, proofs map[string][]byte, size int) (*Result, error) {
+	var (
+		alpha    = Alpha(seed)
+		seen     = make(map[string]bool, len(validators))
+		entries  = make([]*Entry, 0, len(validators))
+		eligible []*Entry
+	)
+	for _, val := range validators {
+		if seen[val.ID] {
+			return nil, errDuplicateID
+		}
+		seen[val.ID] = true
+
+		e := &Entry{ID: val.ID, Weight: val.Weight}
+		entries = append(entries, e)
+		pi, ok := proofs[val.ID]
+		switch {
+		case val.Weight == 0:
+			e.Excluded = NoWeight
+			continue
+		case !ok:
+			e.Excluded = NoProof
+			continue
+		}
+		beta, err := v.Verify(val.PublicKey, alpha, pi)
+		if err != nil {
+			e.Excluded = InvalidProof
+			continue
+		}
+		us, err := ecvrf.ExpandBeta(beta, []string{proposerLabel, committeeLabel}, uniformLen)
+		if err != nil {
+			return nil, err
+		}
+		e.Beta = beta
+		e.ProposerScore = score(us[0], val.Weight)
+		e.CommitteeScore = score(us[1], val.Weight)
+		eligible = append(eligible, e)
+	}
+	if len(eligible) == 0 {
+		return nil, errNoEligible
+	}
+
+	r := &Result{Entries: entries}
+	rank(eligible, func(e *Entry) *big.Int { return e.ProposerScore })
+	r.Proposer = eligible[0].ID
+
+	rank(eligible, func(e *Entry) *big.Int { return e.CommitteeScore })
+	if size > len(eligible) {
+		size = len(eligible)
+	}
+	for _, e := range eligible[:size] {
+		r.Committee = append(r.Committee, e.ID)
+	}
+	return r, nil
+}
+
+// Audit checks that r is the selection of the epoch from the proofs, as computed by Elect.
+func Audit(v ecvrf.VRF, validators []Validator, seed []byte, proofs map[string][]byte, size int, r *Result) error {
+	want, err := Elect(v, validators, seed, proofs, size)
+	if err != nil {
+		return err
+	}
+	if r.Proposer != want.Proposer || !equalIDs(r.Committee, want.Committee) || len(r.Entries) != len(want.Entries) {
+		return errMismatch
+	}
+	for i, e := range r.Entries {
+		w := want.Entries[i]
+		if e.ID != w.ID || e.Weight != w.Weight || e.Excluded != w.Excluded || !bytes.Equal(e.Beta, w.Beta) ||
+			!equalScores(e.ProposerScore, w.ProposerScore) || !equalScores(e.CommitteeScore, w.CommitteeScore) {
+			return errMismatch
+		}
+	}
+	return nil
+}
+
+// rank sorts the entries by increasing score, then by beta and id on ties.
+func rank(entries []*Entry, scoreOf func(*Entry) *big.Int) {
+	sort.Slice(entries, func(i, j int) bool {
+		if c := scoreOf(entries[i]).Cmp(scoreOf(entries[j])); c != 0 {
+			return c < 0
+		}
+		if c := bytes.Compare(entries[i].Beta, entries[j].Beta); c != 0 {
+			return c < 0
+		}
+		return entries[i].ID < entries[j].ID
+	})
+}
+
+// score returns floor(-log2(u) / weight) with scoreFrac fractional bits, where u = (b + 1) / 2^(8*len(b))
+// for the big-endian integer b of the octets.
+func score(octets []byte, weight uint64) *big.Int {
+	m := new(big.Int).SetBytes(octets)
+	m.Add(m, big.NewInt(1))
+	// -log2(u) = 8*len(b) - log2(b + 1)
+	l := big.NewInt(int64(8 * len(octets)))
+	l.Lsh(l, logFrac)
+	l.Sub(l, log2(m, logFrac))
+	l.Lsh(l, scoreFrac-logFrac)
+	return l.Quo(l, new(big.Int).SetUint64(weight))
+}
+
+// log2 returns floor(log2(m) * 2^frac) for m >= 1, by the digit-by-digit squaring algorithm on
+// a fixed-point mantissa, truncated at each step.
+func log2(m *big.Int, frac uint) *big.Int {
+	const prec = 128
+	exp := m.BitLen() - 1
+	// y = m / 2^exp in [1, 2), with prec fractional bits
+	y := new(big.Int).Lsh(m, prec)
+	y.Rsh(y, uint(exp))
+	two := new(big.Int).Lsh(big.NewInt(1), prec+1)
+
+	l := big.NewInt(int64(exp))
+	for i := uint(0); i < frac; i++ {
+		y.Mul(y, y)
+		y.Rsh(y, prec)
+		l.Lsh(l, 1)
+		if y.Cmp(two) >= 0 {
+			y.Rsh(y, 1)
+			l.SetBit(l, 0, 1)
+		}
+	}
+	return l
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalScores(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
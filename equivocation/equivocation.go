@@ -0,0 +1,159 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package equivocation detects the keys which prove two different inputs for the same round, e.g.
+// validators of a PoS chain evaluating the VRF over two forks, and produces evidence of it for
+// slashing. The output of a VRF is unique for an input, so two proofs of the same input never
+// conflict; two valid proofs of different inputs of the same round do, and can only be made by
+// the holder of the private key.
+//
+// Evidence holds both proofs with the key encoded by ecvrf.PublicKey.Bytes, so anyone can check it
+// without the detector, e.g. a slashing contract or module.
+package equivocation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+var (
+	errStaleRound    = errors.New("equivocation: round out of the retention window")
+	errSameInput     = errors.New("equivocation: proofs of the same input")
+	errInputOfRound  = errors.New("equivocation: input not of the round")
+	errNoRoundChecks = errors.New("equivocation: missing check of the inputs of the round")
+)
+
+// Proof is the proof of an input.
+type Proof struct {
+	Alpha []byte `json:"alpha"`
+	Pi    []byte `json:"pi"`
+}
+
+// Evidence is a pair of valid proofs of a key for different inputs of the same round.
+type Evidence struct {
+	// PublicKey is the key, encoded by ecvrf.PublicKey.Bytes with the identifier of its suite.
+	PublicKey []byte `json:"publicKey"`
+	Round     uint64 `json:"round"`
+	// First is the proof seen first by the detector, and Second the conflicting one.
+	First  Proof `json:"first"`
+	Second Proof `json:"second"`
+}
+
+// Verify checks the evidence with v, the suite of the key over the curve c: the key proved both
+// inputs, the inputs differ, and inRound reports that both are inputs of the round, e.g. that
+// they start with the encoding of the round.
+func (e *Evidence) Verify(v ecvrf.VRF, c elliptic.Curve, inRound func(round uint64, alpha []byte) bool) error {
+	if inRound == nil {
+		return errNoRoundChecks
+	}
+	pk, err := ecvrf.ParsePublicKey(v, c, e.PublicKey)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(e.First.Alpha, e.Second.Alpha) {
+		return errSameInput
+	}
+	for _, p := range []Proof{e.First, e.Second} {
+		if !inRound(e.Round, p.Alpha) {
+			return errInputOfRound
+		}
+		if _, err := pk.Verify(p.Alpha, p.Pi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Detector remembers the first valid proof of each key and round, and reports the proofs which
+// conflict with it. It's safe for concurrent use.
+type Detector struct {
+	v         ecvrf.VRF
+	retention uint64
+
+	mu     sync.Mutex
+	latest uint64
+	seen   map[string]Proof
+	rounds map[uint64][]string
+}
+
+// Option configures a Detector.
+type Option func(*Detector)
+
+// WithRetention keeps the proofs of the last rounds only, counted from the latest round observed,
+// so the memory of the detector stays bounded. Proofs of older rounds are refused. By default,
+// all the rounds are kept.
+func WithRetention(rounds uint64) Option {
+	return func(d *Detector) {
+		d.retention = rounds
+	}
+}
+
+// New returns a detector of the proofs of v, which must be a suite of ecvrf.New.
+func New(v ecvrf.VRF, opts ...Option) *Detector {
+	d := &Detector{
+		v:      v,
+		seen:   make(map[string]Proof),
+		rounds: make(map[uint64][]string),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Observe verifies the proof pi of alpha by pk for the round, and returns the evidence of an
+// equivocation if the key already proved another input for the round, nil otherwise. Invalid
+// proofs are returned their error, and aren't remembered.
+func (d *Detector) Observe(pk *ecdsa.PublicKey, round uint64, alpha, pi []byte) (*Evidence, error) {
+	key, err := ecvrf.NewPublicKey(d.v, pk)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := key.Verify(alpha, pi); err != nil {
+		return nil, err
+	}
+	pkBytes := key.Bytes()
+	var r [8]byte
+	binary.BigEndian.PutUint64(r[:], round)
+	id := string(r[:]) + string(pkBytes)
+	proof := Proof{append([]byte(nil), alpha...), append([]byte(nil), pi...)}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.retention > 0 && round+d.retention <= d.latest {
+		return nil, errStaleRound
+	}
+	first, ok := d.seen[id]
+	if !ok {
+		d.seen[id] = proof
+		d.rounds[round] = append(d.rounds[round], id)
+		d.advance(round)
+		return nil, nil
+	}
+	if bytes.Equal(first.Alpha, alpha) {
+		return nil, nil
+	}
+	return &Evidence{PublicKey: pkBytes, Round: round, First: first, Second: proof}, nil
+}
+
+// advance moves the latest round to round, forgetting the rounds out of the retention window.
+func (d *Detector) advance(round uint64) {
+	if d.retention == 0 || round <= d.latest {
+		return
+	}
+	d.latest = round
+	for r, ids := range d.rounds {
+		if r+d.retention <= round {
+			for _, id := range ids {
+				delete(d.seen, id)
+			}
+			delete(d.rounds, r)
+		}
+	}
+}
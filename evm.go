@@ -0,0 +1,137 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+)
+
+var (
+	errUnsupportedVRF = errors.New("unsupported VRF implementation")
+	errNoEVMWitness   = errors.New("proof has no EVM witness: s*H and c*Gamma share x")
+)
+
+// EVMProof is a VRF proof with the witnesses taken by on-chain verifiers, in the layout of the
+// Proof struct of Chainlink's VRF.sol and of the fastVerify parameters of witnet's vrf-solidity.
+// The EVM has no cheap scalar multiplications nor modular inversions, so the contract only checks
+// the witnesses: U through ecrecover, and V as the sum of the two multiplications, with ZInv.
+// All values are uint256 words. The contract must still compute H from the input by itself.
+type EVMProof struct {
+	PublicKey [2]*big.Int
+	Gamma     [2]*big.Int
+	C, S      *big.Int
+
+	// U = s*B - c*Y, and its Ethereum address keccak256(U.x || U.y)[12:], which ecrecover derives
+	U        [2]*big.Int
+	UWitness [20]byte
+
+	// V = SHashWitness - CGammaWitness
+	CGammaWitness [2]*big.Int
+	SHashWitness  [2]*big.Int
+
+	// ZInv is the inverse of the projective z of V, as computed by projectiveECAdd of VRF.sol
+	// from SHashWitness and the negated CGammaWitness, so that V.x = x*ZInv and V.y = y*ZInv.
+	ZInv *big.Int
+}
+
+// ExportEVMProof verifies the proof `pi` of `alpha` against `pk`, and returns its components with
+// the witnesses of on-chain verifiers. v must be a VRF object of this package; the witnesses are
+// checked by the secp256k1 precompiles only for NewSecp256k1Sha256Tai.
+func ExportEVMProof(v VRF, pk *ecdsa.PublicKey, alpha, pi []byte) (*EVMProof, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if _, err := impl.Verify(pk, alpha, pi); err != nil {
+		return nil, err
+	}
+	core := impl.newCore(pk.Curve)
+	defer core.release()
+
+	gamma, c, s, err := core.DecodeProof(pi)
+	if err != nil {
+		return nil, err
+	}
+	Y := &point{pk.X, pk.Y}
+	H, err := core.HashToCurve(Y, alpha)
+	if err != nil {
+		return nil, err
+	}
+	U := core.Sub(core.ScalarBaseMult(s.Bytes()), core.ScalarMultVartime(Y, c))
+	cGamma := core.ScalarMultVartime(gamma, c)
+	sH := core.ScalarMultVartime(H, s)
+
+	p := pk.Curve.Params().P
+	zInv, err := evmZInv(p, sH, &point{cGamma.X, new(big.Int).Sub(p, cGamma.Y)})
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &EVMProof{
+		PublicKey:     [2]*big.Int{new(big.Int).Set(pk.X), new(big.Int).Set(pk.Y)},
+		Gamma:         [2]*big.Int{gamma.X, gamma.Y},
+		C:             c,
+		S:             s,
+		U:             [2]*big.Int{U.X, U.Y},
+		CGammaWitness: [2]*big.Int{cGamma.X, cGamma.Y},
+		SHashWitness:  [2]*big.Int{sH.X, sH.Y},
+		ZInv:          zInv,
+	}
+	proof.UWitness = evmAddress(U)
+	return proof, nil
+}
+
+// evmZInv returns the inverse of the z of the projective sum of p1 and p2 of projectiveECAdd in VRF.sol,
+// where each step keeps its own denominator: x = lx²/lz² - x1 - x2, y = (x1 - x)*lx/lz - y1.
+func evmZInv(p *big.Int, p1, p2 *point) (*big.Int, error) {
+	lz := new(big.Int).Sub(p2.X, p1.X)
+	lz.Mod(lz, p)
+	if lz.Sign() == 0 {
+		return nil, errNoEVMWitness
+	}
+	// the denominator of x is lz², the one of y is lz³, and z = dx * dy unless they are equal
+	dx := new(big.Int).Mul(lz, lz)
+	dx.Mod(dx, p)
+	dy := new(big.Int).Mul(dx, lz)
+	dy.Mod(dy, p)
+	z := dx
+	if dx.Cmp(dy) != 0 {
+		z = new(big.Int).Mul(dx, dy)
+		z.Mod(z, p)
+	}
+	return new(big.Int).ModInverse(z, p), nil
+}
+
+// evmAddress returns the Ethereum address of the point, the last 20 octets of keccak256(x || y).
+func evmAddress(pt *point) (addr [20]byte) {
+	var buf [64]byte
+	pt.X.FillBytes(buf[:32])
+	pt.Y.FillBytes(buf[32:])
+	h := keccak256(buf[:])
+	copy(addr[:], h[12:])
+	return
+}
+
+// keccak256 is the original Keccak-256 used by Ethereum, whose padding differs from SHA3-256.
+func keccak256(data []byte) (out [32]byte) {
+	const rate = 136
+	var state [200]byte
+	for len(data) >= rate {
+		for i := 0; i < rate; i++ {
+			state[i] ^= data[i]
+		}
+		keccakF1600(&state)
+		data = data[rate:]
+	}
+	for i := range data {
+		state[i] ^= data[i]
+	}
+	state[len(data)] ^= 0x01
+	state[rate-1] ^= 0x80
+	keccakF1600(&state)
+	copy(out[:], state[:32])
+	return
+}
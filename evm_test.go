@@ -0,0 +1,86 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestKeccak256(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want string
+	}{
+		{nil, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{[]byte("abc"), "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+	}
+	for _, tt := range tests {
+		if got := keccak256(tt.in); hex.EncodeToString(got[:]) != tt.want {
+			t.Errorf("keccak256(%q) = %x, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExportEVMProof(t *testing.T) {
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	v := NewP256Sha256Tai()
+	alpha := []byte("Hello VeChain")
+	_, pi, err := v.Prove(sk, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := ExportEVMProof(v, &sk.PublicKey, alpha, pi)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := elliptic.P256()
+	p := c.Params().P
+
+	// U = s*B - c*Y
+	sbx, sby := c.ScalarBaseMult(proof.S.Bytes())
+	cyx, cyy := c.ScalarMult(sk.X, sk.Y, proof.C.Bytes())
+	ux, uy := c.Add(sbx, sby, cyx, new(big.Int).Sub(p, cyy))
+	if ux.Cmp(proof.U[0]) != 0 || uy.Cmp(proof.U[1]) != 0 {
+		t.Fatal("U doesn't match")
+	}
+	if evmAddress(&point{ux, uy}) != proof.UWitness {
+		t.Fatal("UWitness doesn't match")
+	}
+
+	// V by the projective formula of VRF.sol, scaled with ZInv
+	x1, y1 := proof.SHashWitness[0], proof.SHashWitness[1]
+	x2, y2 := proof.CGammaWitness[0], new(big.Int).Sub(p, proof.CGammaWitness[1])
+	mod := func(x *big.Int) *big.Int { return x.Mod(x, p) }
+	lx := mod(new(big.Int).Sub(y2, y1))
+	lz := mod(new(big.Int).Sub(x2, x1))
+	dx := mod(new(big.Int).Mul(lz, lz))
+	sx := mod(new(big.Int).Mul(lx, lx))
+	sx = mod(sx.Sub(sx, new(big.Int).Mul(x1, dx)))
+	sx = mod(sx.Sub(sx, new(big.Int).Mul(x2, dx)))
+	sy := mod(new(big.Int).Sub(new(big.Int).Mul(x1, dx), sx))
+	sy = mod(sy.Mul(sy, lx))
+	dy := mod(new(big.Int).Mul(dx, lz))
+	sy = mod(sy.Sub(sy, new(big.Int).Mul(y1, dy)))
+	if dx.Cmp(dy) != 0 {
+		sx = mod(sx.Mul(sx, dy))
+		sy = mod(sy.Mul(sy, dx))
+	}
+	vx, vy := mod(sx.Mul(sx, proof.ZInv)), mod(sy.Mul(sy, proof.ZInv))
+	wx, wy := c.Add(x1, y1, x2, y2)
+	if vx.Cmp(wx) != 0 || vy.Cmp(wy) != 0 {
+		t.Fatal("V by ZInv doesn't match")
+	}
+
+	bad := append([]byte{}, pi...)
+	bad[len(bad)-1] ^= 1
+	if _, err := ExportEVMProof(v, &sk.PublicKey, alpha, bad); err == nil {
+		t.Fatal("ExportEVMProof() accepted an invalid proof")
+	}
+}
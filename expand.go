@@ -0,0 +1,47 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+)
+
+// ExpandBeta derives one independent output of `length` octets for each label in `info`,
+// using beta as the pseudorandom key of HKDF-Expand with HMAC-SHA256 ([RFC5869 section 2.3](https://tools.ietf.org/html/rfc5869#section-2.3)).
+// Outputs for distinct labels are computationally independent of each other.
+func ExpandBeta(beta []byte, info []string, length int) ([][]byte, error) {
+	if len(beta) == 0 {
+		return nil, errors.New("empty beta")
+	}
+	if length <= 0 || length > 255*sha256.Size {
+		return nil, errors.New("invalid output length")
+	}
+
+	outs := make([][]byte, 0, len(info))
+	for _, label := range info {
+		outs = append(outs, hkdfExpand(beta, []byte(label), length))
+	}
+	return outs, nil
+}
+
+// hkdfExpand implements HKDF-Expand(PRK, info, L) with HMAC-SHA256.
+func hkdfExpand(prk, info []byte, length int) []byte {
+	var (
+		mac = hmac.New(sha256.New, prk)
+		out = make([]byte, 0, length+sha256.Size)
+		t   []byte
+	)
+	for i := byte(1); len(out) < length; i++ {
+		// T(i) = HMAC-Hash(PRK, T(i-1) | info | i)
+		mac.Reset()
+		mac.Write(t)
+		mac.Write(info)
+		mac.Write([]byte{i})
+		t = mac.Sum(t[:0])
+		out = append(out, t...)
+	}
+	return out[:length]
+}
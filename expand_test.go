@@ -0,0 +1,35 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestExpandBeta(t *testing.T) {
+	// test case 1 of RFC5869, starting from PRK
+	prk, _ := hex.DecodeString("077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	want := "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"
+
+	outs, err := ExpandBeta(prk, []string{string(info), "shuffle"}, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(outs[0]); got != want {
+		t.Errorf("ExpandBeta() = %v, want %v", got, want)
+	}
+	if bytes.Equal(outs[0], outs[1]) {
+		t.Errorf("ExpandBeta() outputs for distinct labels are equal")
+	}
+
+	if _, err := ExpandBeta(prk, []string{"x"}, 255*32+1); err == nil {
+		t.Errorf("ExpandBeta() accepted oversized length")
+	}
+	if _, err := ExpandBeta(nil, []string{"x"}, 32); err == nil {
+		t.Errorf("ExpandBeta() accepted empty beta")
+	}
+}
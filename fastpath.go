@@ -0,0 +1,256 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"hash"
+	"time"
+)
+
+// verifyKey is the public key prepared for appendVerifyArith.
+type verifyKey struct {
+	pk    *ecdsa.PublicKey
+	y     projectivePoint
+	str   [1 + 32]byte      // point_to_string(Y)
+	table [][32]affinePoint // the fixed table of -Y, nil if not precomputed
+	valid bool              // whether Y passes ECVRF_validate_key
+}
+
+// prepareKey initializes key for pk, and returns false if pk is out of the scope of appendVerifyArith.
+// The key is validated like ECVRF_validate_key, and the result is kept in key.valid.
+func prepareKey(w *weierstrass, key *verifyKey, pk *ecdsa.PublicKey, cofactor byte) bool {
+	byteLen := (pk.Curve.Params().BitSize + 7) / 8
+	if !w.canDecompress() || byteLen != w.fp.byteLen ||
+		pk.X.Sign() < 0 || pk.X.BitLen() > 8*byteLen {
+		return false
+	}
+	key.pk = pk
+	key.str[0] = 2 + byte(pk.Y.Bit(0))
+	pk.X.FillBytes(key.str[1 : 1+byteLen])
+	w.fromBigAffine(&key.y, pk.X, pk.Y)
+	key.valid = w.isOnCurve(pk.X, pk.Y)
+	if key.valid && cofactor > 1 {
+		// points of small order dividing the cofactor are rejected
+		var r projectivePoint
+		w.scalarMultVartime(&r, &key.y, &fieldElement{uint64(cofactor)})
+		key.valid = w.fp.isZero(&r.z) == 0
+	}
+	return true
+}
+
+// verifyScratch holds the buffers of the allocation-free verification. It's reused through the pool of the VRF object.
+type verifyScratch struct {
+	hasher hash.Hash
+	tag    [2]byte      // suite_string || domain separator
+	ctr    [1]byte      // ctr_string of try_and_increment
+	pk     [1 + 32]byte // point_to_string(Y)
+	h      [1 + 32]byte // point_to_string(H)
+	u, v   [1 + 32]byte // point_to_string(U), point_to_string(V)
+	gamma  [1 + 32]byte // point_to_string(cofactor * Gamma)
+	sum    [32]byte     // c' before truncation
+}
+
+// appendVerifyArith implements AppendVerify with the internal arithmetic, working on fixed-size buffers.
+// ok is false if the suite is out of its scope, and then the caller must use the generic implementation.
+func (v *vrf) appendVerifyArith(w *weierstrass, key *verifyKey, dst []byte, alpha, pi []byte) (out []byte, ok bool, err error) {
+	s := v.scratch.Get().(*verifyScratch)
+	defer v.scratch.Put(s)
+
+	var (
+		params  = key.pk.Curve.Params()
+		byteLen = (params.BitSize + 7) / 8
+		ptlen   = 1 + byteLen
+		clen    = ((params.P.BitLen()+1)/2 + 7) / 8
+		slen    = (params.N.BitLen() + 7) / 8
+		hasher  = s.hasher
+	)
+	// hash_to_curve takes hash outputs as x coordinates, so only the suites of matching lengths work
+	if hasher.Size() != byteLen {
+		return
+	}
+	ok = true
+	if !key.valid {
+		return dst, ok, errInvalidPublicKey
+	}
+
+	// step 1 ~ 3: (Gamma, c, s) = ECVRF_decode_proof(pi_string)
+	if len(pi) != ptlen+clen+slen {
+		return dst, ok, ErrInvalidProofLength
+	}
+	var (
+		gammaBytes = pi[:ptlen]
+		cBytes     = pi[ptlen : ptlen+clen]
+		sBytes     = pi[ptlen+clen:]
+		gamma, h   projectivePoint
+		cy, u, vp  projectivePoint
+	)
+	if gammaBytes[0]&^1 != 2 {
+		return dst, ok, ErrUnrecognizedPointEncoding
+	}
+	if !w.decompress(&gamma, gammaBytes[1:], uint(gammaBytes[0]&1)) {
+		return dst, ok, ErrNotSquare
+	}
+	// with x < p checked by decompress and s < q checked here, pi is the only encoding of the proof
+	c, sc := limbsFromShortBytes(cBytes), limbsFromShortBytes(sBytes)
+	if !lessThan(&sc, &w.fq.p) {
+		return dst, ok, ErrInvalidProofScalar
+	}
+
+	// step 4: H = ECVRF_hash_to_curve(suite_string, Y, alpha_string)
+	// copy the key into the scratch, so that passing it to the hasher doesn't move the key to the heap
+	s.pk = key.str
+	s.h[0] = 2 // compress format
+	s.tag = [2]byte{v.cfg.SuiteString, 0x01}
+	var (
+		found bool
+		dummy projectivePoint // decodes the candidates after H, see WithFixedHashToCurve
+	)
+	for ctr := 0; ctr < 256 && (!found || ctr < v.cfg.HashToCurveCandidates); ctr++ {
+		s.ctr[0] = byte(ctr)
+		hasher.Reset()
+		hasher.Write(s.tag[:])
+		hasher.Write(s.pk[:ptlen])
+		hasher.Write(alpha)
+		hasher.Write(s.ctr[:])
+		hasher.Write(v.cfg.domainBack())
+		if found {
+			hasher.Sum(s.sum[:0])
+			w.decompress(&dummy, s.sum[:ptlen-1], 0)
+			continue
+		}
+		hasher.Sum(s.h[1:1])
+		if found = w.decompress(&h, s.h[1:ptlen], 0); found && v.cfg.Metrics != nil {
+			v.cfg.Metrics.ObserveHashToCurve(ctr + 1)
+		}
+	}
+	if !found {
+		return dst, ok, errNoValidPoint
+	}
+	if v.cfg.Cofactor > 1 {
+		w.scalarMultVartime(&h, &h, &fieldElement{uint64(v.cfg.Cofactor)})
+		w.encode(s.h[:ptlen], &h)
+	}
+
+	// step 5: U = s*B - c*Y
+	w.scalarBaseMult(&u, sBytes)
+	if key.table != nil {
+		w.fixedMult(&cy, key.table, cBytes)
+	} else {
+		w.scalarMultVartime(&cy, &key.y, &c)
+		w.neg(&cy, &cy)
+	}
+	w.add(&u, &u, &cy)
+
+	// step 6: V = s*H - c*Gamma, by a joint multiplication sharing the doublings
+	w.multiScalarMultVartime(&vp, []vartimeTerm{{p: h, k: sc}, {p: gamma, k: c, neg: true}})
+
+	// step 7: c' = ECVRF_hash_points(H, Gamma, U, V), or ECVRF_challenge_generation(Y, H, Gamma, U, V) in RFC9381
+	w.encode(s.u[:ptlen], &u)
+	w.encode(s.v[:ptlen], &vp)
+	s.tag[1] = 0x02
+	hasher.Reset()
+	hasher.Write(s.tag[:])
+	if v.cfg.Spec == RFC9381 {
+		hasher.Write(s.pk[:ptlen])
+	}
+	hasher.Write(s.h[:ptlen])
+	hasher.Write(gammaBytes)
+	hasher.Write(s.u[:ptlen])
+	hasher.Write(s.v[:ptlen])
+	hasher.Write(v.cfg.domainBack())
+	hasher.Sum(s.sum[:0])
+
+	// step 8: c' is the leading n octets of the hash, which must equal c
+	if !bytes.Equal(s.sum[:clen], cBytes) {
+		return dst, ok, ErrInvalidProof
+	}
+
+	// ECVRF_proof_to_hash(pi_string)
+	if v.cfg.Cofactor > 1 {
+		w.scalarMultVartime(&gamma, &gamma, &fieldElement{uint64(v.cfg.Cofactor)})
+		w.encode(s.gamma[:ptlen], &gamma)
+	} else {
+		copy(s.gamma[:], gammaBytes)
+	}
+	s.tag[1] = 0x03
+	if v.cfg.NewXOF != nil {
+		size := v.cfg.BetaSize
+		if size <= 0 {
+			size = hasher.Size()
+		}
+		xof := v.cfg.NewXOF()
+		xof.Write(s.tag[:])
+		xof.Write(s.gamma[:ptlen])
+		xof.Write(v.cfg.domainBack())
+		n := len(dst)
+		dst = append(dst, make([]byte, size)...)
+		xof.Read(dst[n:])
+		return dst, ok, nil
+	}
+	hasher.Reset()
+	hasher.Write(s.tag[:])
+	hasher.Write(s.gamma[:ptlen])
+	hasher.Write(v.cfg.domainBack())
+	return hasher.Sum(dst), ok, nil
+}
+
+// limbsFromShortBytes converts a big-endian integer of at most 32 octets to limbs.
+func limbsFromShortBytes(b []byte) fieldElement {
+	var buf [32]byte
+	copy(buf[len(buf)-len(b):], b)
+	return limbsFromBytes(&buf)
+}
+
+// verifier is the Verifier of a public key prepared for appendVerifyArith.
+type verifier struct {
+	vrf *vrf
+	w   *weierstrass // nil if the key is not prepared
+	key verifyKey
+}
+
+// newVerifier creates the Verifier of pk, precomputing the fixed table of -Y if the curve is supported.
+func (v *vrf) newVerifier(pk *ecdsa.PublicKey) *verifier {
+	vr := &verifier{vrf: v}
+	vr.key.pk = pk
+	if v.checkPublicKey(pk) != nil {
+		// the generic implementation reports the error on each call
+		return vr
+	}
+	if w := v.arith(pk.Curve); w != nil && prepareKey(w, &vr.key, pk, v.cfg.Cofactor) && vr.key.valid {
+		var (
+			negY projectivePoint
+			clen = ((pk.Curve.Params().P.BitLen()+1)/2 + 7) / 8 // c is of n octets
+		)
+		w.neg(&negY, &vr.key.y)
+		vr.key.table = w.fixedTable(&affinePoint{negY.x, negY.y}, (8*clen+6)/6)
+		vr.w = w
+	}
+	return vr
+}
+
+func (vr *verifier) Verify(alpha, pi []byte) (beta []byte, err error) {
+	return vr.AppendVerify(nil, alpha, pi)
+}
+
+func (vr *verifier) AppendVerify(dst []byte, alpha, pi []byte) ([]byte, error) {
+	if vr.vrf.cfg.Metrics == nil && vr.vrf.cfg.Logger == nil {
+		return vr.appendVerify(dst, alpha, pi)
+	}
+	start := time.Now()
+	out, err := vr.appendVerify(dst, alpha, pi)
+	vr.vrf.observeVerify(start, err)
+	vr.vrf.logVerifyFailed(vr.key.pk, alpha, err)
+	return out, err
+}
+
+func (vr *verifier) appendVerify(dst []byte, alpha, pi []byte) ([]byte, error) {
+	if vr.w != nil {
+		if out, ok, err := vr.vrf.appendVerifyArith(vr.w, &vr.key, dst, alpha, pi); ok {
+			return out, err
+		}
+	}
+	return vr.vrf.appendVerifyGeneric(dst, vr.key.pk, alpha, pi)
+}
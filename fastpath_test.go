@@ -0,0 +1,61 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestAppendVerifyArith(t *testing.T) {
+	v := NewSecp256k1Sha256Tai().(*vrf)
+	w := curveArith(secp256k1, v.cfg.Y2)
+	if w == nil {
+		t.Fatal("curve arithmetic not available")
+	}
+	for i := 0; i < 20; i++ {
+		d, _ := rand.Int(rand.Reader, new(big.Int).Sub(secp256k1.N, big.NewInt(1)))
+		d.Add(d, big.NewInt(1))
+		sk := &ecdsa.PrivateKey{D: d}
+		sk.Curve = secp256k1
+		sk.X, sk.Y = refScalarMult(secp256k1.P, big.NewInt(0), secp256k1.Gx, secp256k1.Gy, d.Bytes())
+
+		alpha := make([]byte, i)
+		rand.Read(alpha)
+		_, pi, err := v.Prove(sk, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vr := v.newVerifier(&sk.PublicKey)
+		// the fast path must agree with the generic implementation, on valid and tampered proofs
+		for j := -1; j < len(pi); j += 7 {
+			bad := append([]byte{}, pi...)
+			if j >= 0 {
+				bad[j] ^= 0x10
+			}
+			want, wantErr := v.verify(&sk.PublicKey, alpha, bad)
+			var key verifyKey
+			if !prepareKey(w, &key, &sk.PublicKey, v.cfg.Cofactor) {
+				t.Fatal("prepareKey() failed")
+			}
+			got, ok, err := v.appendVerifyArith(w, &key, nil, alpha, bad)
+			if !ok {
+				t.Fatal("appendVerifyArith() not applicable")
+			}
+			if !bytes.Equal(got, want) || (err == nil) != (wantErr == nil) {
+				t.Fatalf("appendVerifyArith() = %x, %v, want %x, %v", got, err, want, wantErr)
+			}
+
+			// and so must the verifier with the precomputed table
+			got, err = vr.AppendVerify(nil, alpha, bad)
+			if !bytes.Equal(got, want) || (err == nil) != (wantErr == nil) {
+				t.Fatalf("verifier.AppendVerify() = %x, %v, want %x, %v", got, err, want, wantErr)
+			}
+		}
+	}
+}
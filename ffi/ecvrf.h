@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2020 vechain.org.
+ * Licensed under the MIT license.
+ *
+ * C API of go-ecvrf, built by
+ *
+ *     go build -buildmode=c-shared -o libecvrf.so
+ *
+ * Use this header rather than the one generated by cgo, whose Go types may change between
+ * releases. Buffers are owned by the caller: the length of an output is its capacity on input,
+ * and the length written on output, or the length needed if the result is ECVRF_ERR_BUFFER.
+ * The functions are safe for concurrent use.
+ */
+#ifndef ECVRF_H
+#define ECVRF_H
+
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/* Suites. */
+#define ECVRF_P256_SHA256_TAI 1
+#define ECVRF_SECP256K1_SHA256_TAI 2
+#define ECVRF_EDWARDS25519_SHA512_ELL2 3
+#define ECVRF_EDWARDS25519_SHA512_ELLIGATOR2_DRAFT03 4
+
+/* Results. */
+#define ECVRF_OK 0
+#define ECVRF_ERR_SUITE (-1)  /* unknown suite */
+#define ECVRF_ERR_KEY (-2)    /* malformed key */
+#define ECVRF_ERR_PROOF (-3)  /* invalid or malformed proof */
+#define ECVRF_ERR_BUFFER (-4) /* output buffer too small */
+
+/* Buffer sizes large enough for the proofs and outputs of all suites. */
+#define ECVRF_PROOF_MAX 128
+#define ECVRF_HASH_MAX 64
+
+#ifndef ECVRF_NO_PROTOTYPES
+
+/*
+ * Proves alpha with the secret key sk: the scalar of the Weierstrass suites, in big-endian octets
+ * of the order's length, or the 32-octet seed of the Ed25519 suites. Writes the proof and its output.
+ */
+int ecvrf_prove(int suite, const uint8_t *sk, size_t sk_len, const uint8_t *alpha, size_t alpha_len,
+                uint8_t *pi, size_t *pi_len, uint8_t *beta, size_t *beta_len);
+
+/*
+ * Verifies the proof pi of alpha by the public key pk: a compressed or uncompressed point of the
+ * Weierstrass suites, or the 32-octet public key of the Ed25519 suites. Writes the output if valid.
+ */
+int ecvrf_verify(int suite, const uint8_t *pk, size_t pk_len, const uint8_t *alpha, size_t alpha_len,
+                 const uint8_t *pi, size_t pi_len, uint8_t *beta, size_t *beta_len);
+
+/* Writes the output of the proof pi without verifying it. */
+int ecvrf_proof_to_hash(int suite, const uint8_t *pi, size_t pi_len, uint8_t *beta, size_t *beta_len);
+
+#endif
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
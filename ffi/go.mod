@@ -0,0 +1,10 @@
+module github.com/vechain/go-ecvrf/ffi
+
+go 1.21
+
+require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.1
+	github.com/vechain/go-ecvrf v0.0.0-20200305101714-4252ed3a3b96
+)
+
+replace github.com/vechain/go-ecvrf => ../
@@ -0,0 +1,105 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Command ffi is the C API of go-ecvrf, declared by ecvrf.h, for services in other languages to link
+// against this implementation:
+//
+//	go build -buildmode=c-shared -o libecvrf.so
+//	cc -o app app.c -L. -lecvrf
+//
+// The generated libecvrf.h declares the same functions with the types of cgo; use ecvrf.h.
+package main
+
+/*
+#define ECVRF_NO_PROTOTYPES
+#include "ecvrf.h"
+*/
+import "C"
+
+import "unsafe"
+
+func main() {}
+
+//export ecvrf_prove
+func ecvrf_prove(suite C.int, sk *C.uint8_t, skLen C.size_t, alpha *C.uint8_t, alphaLen C.size_t,
+	pi *C.uint8_t, piLen *C.size_t, beta *C.uint8_t, betaLen *C.size_t) C.int {
+	s := newSuite(int(suite))
+	if s == nil {
+		return C.ECVRF_ERR_SUITE
+	}
+	key := goBytes(sk, skLen)
+	defer wipe(key)
+	p, b, err := s.prove(key, goBytes(alpha, alphaLen))
+	if err != nil {
+		return C.ECVRF_ERR_KEY
+	}
+	// both lengths are set if too small
+	if piFits, betaFits := fits(piLen, p), fits(betaLen, b); !piFits || !betaFits {
+		return C.ECVRF_ERR_BUFFER
+	}
+	write(pi, piLen, p)
+	write(beta, betaLen, b)
+	return C.ECVRF_OK
+}
+
+//export ecvrf_verify
+func ecvrf_verify(suite C.int, pk *C.uint8_t, pkLen C.size_t, alpha *C.uint8_t, alphaLen C.size_t,
+	pi *C.uint8_t, piLen C.size_t, beta *C.uint8_t, betaLen *C.size_t) C.int {
+	s := newSuite(int(suite))
+	if s == nil {
+		return C.ECVRF_ERR_SUITE
+	}
+	b, err := s.verify(goBytes(pk, pkLen), goBytes(alpha, alphaLen), goBytes(pi, piLen))
+	return output(b, err, beta, betaLen)
+}
+
+//export ecvrf_proof_to_hash
+func ecvrf_proof_to_hash(suite C.int, pi *C.uint8_t, piLen C.size_t, beta *C.uint8_t, betaLen *C.size_t) C.int {
+	s := newSuite(int(suite))
+	if s == nil {
+		return C.ECVRF_ERR_SUITE
+	}
+	b, err := s.proofToHash(goBytes(pi, piLen))
+	return output(b, err, beta, betaLen)
+}
+
+// output writes the output beta, or returns the result of err.
+func output(b []byte, err error, beta *C.uint8_t, betaLen *C.size_t) C.int {
+	switch {
+	case err == errInvalidKey:
+		return C.ECVRF_ERR_KEY
+	case err != nil:
+		return C.ECVRF_ERR_PROOF
+	case !fits(betaLen, b):
+		return C.ECVRF_ERR_BUFFER
+	}
+	write(beta, betaLen, b)
+	return C.ECVRF_OK
+}
+
+// goBytes copies the n octets at p, so no Go memory refers to the caller's buffers.
+func goBytes(p *C.uint8_t, n C.size_t) []byte {
+	if p == nil || n == 0 {
+		return nil
+	}
+	return append([]byte(nil), unsafe.Slice((*byte)(unsafe.Pointer(p)), int(n))...)
+}
+
+// fits reports whether the buffer of capacity *n takes b, and sets *n to the length needed if not.
+func fits(n *C.size_t, b []byte) bool {
+	if n == nil {
+		return false
+	}
+	if int(*n) < len(b) {
+		*n = C.size_t(len(b))
+		return false
+	}
+	return true
+}
+
+func write(p *C.uint8_t, n *C.size_t, b []byte) {
+	if len(b) > 0 {
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(p)), len(b)), b)
+	}
+	*n = C.size_t(len(b))
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestABI builds the shared library, and links testdata/abi.c against it with ecvrf.h.
+func TestABI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the shared library")
+	}
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("no C compiler")
+	}
+	dir := t.TempDir()
+	run(t, "go", "build", "-buildmode=c-shared", "-o", filepath.Join(dir, "libecvrf.so"), ".")
+	run(t, cc, "-Wall", "-Werror", "-I.", "-o", filepath.Join(dir, "abi"), "testdata/abi.c", "-L"+dir, "-lecvrf")
+
+	cmd := exec.Command(filepath.Join(dir, "abi"))
+	cmd.Env = append(os.Environ(), "LD_LIBRARY_PATH="+dir, "DYLD_LIBRARY_PATH="+dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+func run(t *testing.T, name string, args ...string) {
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		t.Fatalf("%s: %v\n%s", name, err, out)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+var (
+	errInvalidKey   = errors.New("invalid key")
+	errInvalidProof = errors.New("invalid proof")
+)
+
+// suite wraps the VRF objects of the library behind keys in their octet encodings, as the ecvrf
+// command does.
+type suite interface {
+	prove(sk, alpha []byte) (pi, beta []byte, err error)
+	verify(pk, alpha, pi []byte) (beta []byte, err error)
+	proofToHash(pi []byte) (beta []byte, err error)
+}
+
+// newSuite returns the suite of the ECVRF_* identifier of ecvrf.h, or nil.
+func newSuite(id int) suite {
+	switch id {
+	case 1:
+		return &ecdsaSuite{elliptic.P256(), ecvrf.NewP256Sha256Tai()}
+	case 2:
+		return &ecdsaSuite{secp256k1.S256(), ecvrf.NewSecp256k1Sha256Tai()}
+	case 3:
+		return &ed25519Suite{ecvrf.NewEd25519Sha512Ell2()}
+	case 4:
+		return &ed25519Suite{ecvrf.NewEd25519Sha512Elligator2()}
+	}
+	return nil
+}
+
+type ecdsaSuite struct {
+	curve elliptic.Curve
+	vrf   ecvrf.VRF
+}
+
+func (s *ecdsaSuite) prove(b, alpha []byte) ([]byte, []byte, error) {
+	sk, err := ecvrf.DecodePrivateKey(s.vrf, s.curve, b)
+	if err != nil {
+		return nil, nil, errInvalidKey
+	}
+	beta, pi, err := s.vrf.Prove(sk, alpha)
+	return pi, beta, err
+}
+
+func (s *ecdsaSuite) verify(b, alpha, pi []byte) ([]byte, error) {
+	pk, err := ecvrf.DecodePublicKey(s.vrf, s.curve, b)
+	if err != nil {
+		return nil, errInvalidKey
+	}
+	beta, err := s.vrf.Verify(pk, alpha, pi)
+	if err != nil {
+		return nil, errInvalidProof
+	}
+	return beta, nil
+}
+
+func (s *ecdsaSuite) proofToHash(pi []byte) ([]byte, error) {
+	beta, err := ecvrf.ProofToHash(s.vrf, s.curve, pi)
+	if err != nil {
+		return nil, errInvalidProof
+	}
+	return beta, nil
+}
+
+type ed25519Suite struct {
+	vrf ecvrf.Ed25519VRF
+}
+
+func (s *ed25519Suite) prove(seed, alpha []byte) ([]byte, []byte, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, nil, errInvalidKey
+	}
+	sk := ed25519.NewKeyFromSeed(seed)
+	defer wipe(sk)
+	beta, pi, err := s.vrf.Prove(sk, alpha)
+	return pi, beta, err
+}
+
+func (s *ed25519Suite) verify(pk, alpha, pi []byte) ([]byte, error) {
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, errInvalidKey
+	}
+	beta, err := s.vrf.Verify(ed25519.PublicKey(pk), alpha, pi)
+	if err != nil {
+		return nil, errInvalidProof
+	}
+	return beta, nil
+}
+
+func (s *ed25519Suite) proofToHash(pi []byte) ([]byte, error) {
+	beta, err := s.vrf.ProofToHash(pi)
+	if err != nil {
+		return nil, errInvalidProof
+	}
+	return beta, nil
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestSuites(t *testing.T) {
+	sk := bytes.Repeat([]byte{7}, 32)
+	x, y := secp256k1.S256().ScalarBaseMult(sk)
+	px, py := elliptic.P256().ScalarBaseMult(sk)
+	pks := map[int][]byte{
+		1: elliptic.MarshalCompressed(elliptic.P256(), px, py),
+		2: elliptic.Marshal(secp256k1.S256(), x, y),
+		3: ed25519.NewKeyFromSeed(sk).Public().(ed25519.PublicKey),
+		4: ed25519.NewKeyFromSeed(sk).Public().(ed25519.PublicKey),
+	}
+	alpha := []byte("sample")
+	for id, pk := range pks {
+		s := newSuite(id)
+		pi, beta, err := s.prove(sk, alpha)
+		if err != nil {
+			t.Fatal(id, err)
+		}
+		if b, err := s.verify(pk, alpha, pi); err != nil || !bytes.Equal(b, beta) {
+			t.Fatal(id, "valid proof rejected", err)
+		}
+		if b, err := s.proofToHash(pi); err != nil || !bytes.Equal(b, beta) {
+			t.Fatal(id, "wrong output", err)
+		}
+		if _, err := s.verify(pk, alpha[1:], pi); err != errInvalidProof {
+			t.Fatal(id, "invalid proof accepted", err)
+		}
+		if _, err := s.verify(pk[1:], alpha, pi); err != errInvalidKey {
+			t.Fatal(id, "malformed key accepted", err)
+		}
+	}
+	if newSuite(0) != nil {
+		t.Fatal("unknown suite")
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2020 vechain.org.
+ * Licensed under the MIT license.
+ *
+ * Proves and verifies through libecvrf with ecvrf.h, as a C service would. Exits non-zero on failure.
+ */
+#include <stdio.h>
+#include <string.h>
+
+#include "ecvrf.h"
+
+#define CHECK(cond)                                             \
+    if (!(cond)) {                                              \
+        fprintf(stderr, "%s:%d: %s\n", __FILE__, __LINE__, #cond); \
+        return 1;                                               \
+    }
+
+int main(void) {
+    /* a P-256 key pair of sk = 1 */
+    uint8_t sk[32] = {0};
+    sk[31] = 1;
+    const uint8_t pk[33] = {0x03, 0x6b, 0x17, 0xd1, 0xf2, 0xe1, 0x2c, 0x42, 0x47, 0xf8, 0xbc, 0xe6,
+                            0xe5, 0x63, 0xa4, 0x40, 0xf2, 0x77, 0x03, 0x7d, 0x81, 0x2d, 0xeb, 0x33,
+                            0xa0, 0xf4, 0xa1, 0x39, 0x45, 0xd8, 0x98, 0xc2, 0x96};
+    const uint8_t alpha[] = "sample";
+    uint8_t pi[ECVRF_PROOF_MAX], beta[ECVRF_HASH_MAX], beta2[ECVRF_HASH_MAX];
+    size_t pi_len = sizeof(pi), beta_len = sizeof(beta), beta2_len;
+
+    CHECK(ecvrf_prove(ECVRF_P256_SHA256_TAI, sk, sizeof(sk), alpha, 6, pi, &pi_len, beta, &beta_len) == ECVRF_OK);
+    CHECK(pi_len == 81 && beta_len == 32);
+
+    beta2_len = sizeof(beta2);
+    CHECK(ecvrf_verify(ECVRF_P256_SHA256_TAI, pk, sizeof(pk), alpha, 6, pi, pi_len, beta2, &beta2_len) == ECVRF_OK);
+    CHECK(beta2_len == beta_len && memcmp(beta, beta2, beta_len) == 0);
+
+    beta2_len = sizeof(beta2);
+    CHECK(ecvrf_proof_to_hash(ECVRF_P256_SHA256_TAI, pi, pi_len, beta2, &beta2_len) == ECVRF_OK);
+    CHECK(beta2_len == beta_len && memcmp(beta, beta2, beta_len) == 0);
+
+    /* results */
+    beta2_len = 16;
+    CHECK(ecvrf_proof_to_hash(ECVRF_P256_SHA256_TAI, pi, pi_len, beta2, &beta2_len) == ECVRF_ERR_BUFFER);
+    CHECK(beta2_len == 32);
+    beta2_len = sizeof(beta2);
+    CHECK(ecvrf_verify(ECVRF_P256_SHA256_TAI, pk, sizeof(pk), alpha, 5, pi, pi_len, beta2, &beta2_len) == ECVRF_ERR_PROOF);
+    CHECK(ecvrf_verify(ECVRF_P256_SHA256_TAI, pk, 32, alpha, 6, pi, pi_len, beta2, &beta2_len) == ECVRF_ERR_KEY);
+    CHECK(ecvrf_verify(99, pk, sizeof(pk), alpha, 6, pi, pi_len, beta2, &beta2_len) == ECVRF_ERR_SUITE);
+    CHECK(ecvrf_prove(ECVRF_EDWARDS25519_SHA512_ELL2, sk, 31, alpha, 6, pi, &pi_len, beta, &beta_len) == ECVRF_ERR_KEY);
+    return 0;
+}
@@ -0,0 +1,660 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// fieldElement is an element of a prime field in Montgomery form, stored as
+// little-endian 64-bit limbs.
+type fieldElement [4]uint64
+
+// field implements arithmetic modulo an odd prime p < 2^256, over elements in Montgomery form.
+// For pseudo-Mersenne primes p = 2^256 - c, R is 1 and elements are kept as is, since
+// the special reduction is faster than Montgomery's.
+// The arithmetic is branch-free, so the running time doesn't depend on the values of operands.
+type field struct {
+	p   fieldElement
+	c   uint64       // 2^256 - p if it fits in 63 bits, otherwise 0
+	n0  uint64       // -p^-1 mod 2^64
+	r2  fieldElement // R^2 mod p, where R = 2^256
+	one fieldElement // R mod p
+
+	modulus *big.Int // p as big.Int
+	pMinus2 *big.Int // exponent for inversion
+	byteLen int      // length in octets of p
+}
+
+// newField creates the field of the given odd prime modulus. nil is returned if p is out of range.
+func newField(p *big.Int) *field {
+	if p.Sign() <= 0 || p.BitLen() > 256 || p.Bit(0) == 0 {
+		return nil
+	}
+	f := &field{
+		modulus: new(big.Int).Set(p),
+		pMinus2: new(big.Int).Sub(p, big.NewInt(2)),
+		byteLen: (p.BitLen() + 7) / 8,
+	}
+	f.p = limbsFromBig(p)
+
+	// n0 = -p^-1 mod 2^64, by Newton's iteration
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - f.p[0]*inv
+	}
+	f.n0 = -inv
+
+	r := new(big.Int).Lsh(big.NewInt(1), 256)
+	if c := new(big.Int).Sub(r, p); c.BitLen() < 64 {
+		f.c = c.Uint64()
+		f.one = fieldElement{1}
+		f.r2 = fieldElement{1}
+		return f
+	}
+	f.one = limbsFromBig(new(big.Int).Mod(r, p))
+	f.r2 = limbsFromBig(r.Mul(r, r).Mod(r, p))
+	return f
+}
+
+var scalarFieldCache sync.Map // *elliptic.CurveParams -> *field
+
+// scalarField returns the field of integers modulo the group order of the curve,
+// or nil if the order is out of range.
+func scalarField(params *elliptic.CurveParams) *field {
+	if f, ok := scalarFieldCache.Load(params); ok {
+		return f.(*field)
+	}
+	f := newField(params.N)
+	scalarFieldCache.Store(params, f)
+	return f
+}
+
+// limbsFromBig converts a non-negative integer less than 2^256 to limbs.
+func limbsFromBig(v *big.Int) (out fieldElement) {
+	var buf [32]byte
+	v.FillBytes(buf[:])
+	return limbsFromBytes(&buf)
+}
+
+// limbsFromBytes converts a 32-octet big-endian integer to limbs.
+func limbsFromBytes(buf *[32]byte) (out fieldElement) {
+	for i := 0; i < 4; i++ {
+		j := 32 - 8*(i+1)
+		out[i] = uint64(buf[j])<<56 | uint64(buf[j+1])<<48 | uint64(buf[j+2])<<40 | uint64(buf[j+3])<<32 |
+			uint64(buf[j+4])<<24 | uint64(buf[j+5])<<16 | uint64(buf[j+6])<<8 | uint64(buf[j+7])
+	}
+	return
+}
+
+// limbsToBytes converts limbs to a 32-octet big-endian integer.
+func limbsToBytes(buf *[32]byte, v *fieldElement) {
+	for i := 0; i < 4; i++ {
+		j := 32 - 8*(i+1)
+		l := v[i]
+		buf[j], buf[j+1], buf[j+2], buf[j+3] = byte(l>>56), byte(l>>48), byte(l>>40), byte(l>>32)
+		buf[j+4], buf[j+5], buf[j+6], buf[j+7] = byte(l>>24), byte(l>>16), byte(l>>8), byte(l)
+	}
+}
+
+// fromBig sets z = v mod p, in Montgomery form.
+func (f *field) fromBig(z *fieldElement, v *big.Int) {
+	if v.Sign() < 0 || v.BitLen() > 256 {
+		v = new(big.Int).Mod(v, f.modulus)
+	}
+	l := limbsFromBig(v)
+	f.fromLimbs(z, &l)
+}
+
+// fromLimbs sets z = x mod p in Montgomery form, where x is a plain integer less than 2^256.
+func (f *field) fromLimbs(z, x *fieldElement) {
+	f.mul(z, x, &f.r2)
+}
+
+// toLimbs sets z to the canonical value of x as a plain integer.
+func (f *field) toLimbs(z, x *fieldElement) {
+	one := fieldElement{1}
+	f.mul(z, x, &one)
+}
+
+// toBig returns the canonical integer value of x.
+func (f *field) toBig(x *fieldElement) *big.Int {
+	var buf [32]byte
+	f.bytes(&buf, x)
+	return new(big.Int).SetBytes(buf[:])
+}
+
+// bytes writes the canonical value of x as a 32-octet big-endian integer.
+func (f *field) bytes(buf *[32]byte, x *fieldElement) {
+	var t fieldElement
+	f.toLimbs(&t, x)
+	limbsToBytes(buf, &t)
+}
+
+// mul sets z = x * y * R^-1 mod p.
+func (f *field) mul(z, x, y *fieldElement) {
+	if f.c != 0 {
+		f.pmMul(z, x, y)
+		return
+	}
+	f.montMul(z, x, y)
+}
+
+// pmMul sets z = x * y mod p, for pseudo-Mersenne prime p = 2^256 - c.
+func (f *field) pmMul(z, x, y *fieldElement) {
+	var (
+		x0, x1, x2, x3                 = x[0], x[1], x[2], x[3]
+		y0, y1, y2, y3                 = y[0], y[1], y[2], y[3]
+		t0, t1, t2, t3, t4, t5, t6, t7 uint64
+		c, hi, lo, carry               uint64
+	)
+
+	// t = x * y, by schoolbook multiplication
+	c = 0
+	hi, lo = bits.Mul64(x0, y0)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t0, carry = bits.Add64(t0, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, y0)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, y0)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, y0)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	t4 = c
+	c = 0
+	hi, lo = bits.Mul64(x0, y1)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, y1)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, y1)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, y1)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t4, carry = bits.Add64(t4, lo, 0)
+	c = hi + carry
+	t5 = c
+	c = 0
+	hi, lo = bits.Mul64(x0, y2)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, y2)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, y2)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t4, carry = bits.Add64(t4, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, y2)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t5, carry = bits.Add64(t5, lo, 0)
+	c = hi + carry
+	t6 = c
+	c = 0
+	hi, lo = bits.Mul64(x0, y3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, y3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t4, carry = bits.Add64(t4, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, y3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t5, carry = bits.Add64(t5, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, y3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t6, carry = bits.Add64(t6, lo, 0)
+	c = hi + carry
+	t7 = c
+
+	f.pmReduce(z, t0, t1, t2, t3, t4, t5, t6, t7)
+}
+
+// pmSqr sets z = x * x mod p, for pseudo-Mersenne prime p = 2^256 - c.
+func (f *field) pmSqr(z, x *fieldElement) {
+	var (
+		x0, x1, x2, x3                 = x[0], x[1], x[2], x[3]
+		t0, t1, t2, t3, t4, t5, t6, t7 uint64
+		c, hi, lo, carry               uint64
+	)
+
+	// cross products x[i] * x[j] for i < j
+	c = 0
+	hi, lo = bits.Mul64(x0, x1)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x0, x2)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x0, x3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	t4 = c
+	c = 0
+	hi, lo = bits.Mul64(x1, x2)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, x3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t4, carry = bits.Add64(t4, lo, 0)
+	c = hi + carry
+	t5 = c
+	c = 0
+	hi, lo = bits.Mul64(x2, x3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t5, carry = bits.Add64(t5, lo, 0)
+	c = hi + carry
+	t6 = c
+
+	// double the cross products
+	t7 = t6 >> 63
+	t6 = t6<<1 | t5>>63
+	t5 = t5<<1 | t4>>63
+	t4 = t4<<1 | t3>>63
+	t3 = t3<<1 | t2>>63
+	t2 = t2<<1 | t1>>63
+	t1 <<= 1
+
+	// add the squares x[i] * x[i]
+	hi, lo = bits.Mul64(x0, x0)
+	t0 = lo
+	t1, carry = bits.Add64(t1, hi, 0)
+	hi, lo = bits.Mul64(x1, x1)
+	t2, carry = bits.Add64(t2, lo, carry)
+	t3, carry = bits.Add64(t3, hi, carry)
+	hi, lo = bits.Mul64(x2, x2)
+	t4, carry = bits.Add64(t4, lo, carry)
+	t5, carry = bits.Add64(t5, hi, carry)
+	hi, lo = bits.Mul64(x3, x3)
+	t6, carry = bits.Add64(t6, lo, carry)
+	t7, carry = bits.Add64(t7, hi, carry)
+
+	f.pmReduce(z, t0, t1, t2, t3, t4, t5, t6, t7)
+}
+
+// pmReduce sets z = t mod p, for 512-bit t and pseudo-Mersenne prime p = 2^256 - c.
+func (f *field) pmReduce(z *fieldElement, t0, t1, t2, t3, t4, t5, t6, t7 uint64) {
+	var c, hi, lo, carry uint64
+
+	// 2^256 = c mod p, so t = t[0:4] + t[4:8] * c
+	pc := f.c
+	c = 0
+	hi, lo = bits.Mul64(t4, pc)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t0, carry = bits.Add64(t0, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(t5, pc)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(t6, pc)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(t7, pc)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+
+	// fold the top word again
+	hi, lo = bits.Mul64(c, pc)
+	t0, carry = bits.Add64(t0, lo, 0)
+	t1, carry = bits.Add64(t1, hi, carry)
+	t2, carry = bits.Add64(t2, 0, carry)
+	t3, carry = bits.Add64(t3, 0, carry)
+
+	// at most one more carry, which can't overflow again
+	t0, carry = bits.Add64(t0, pc&-carry, 0)
+	t1, carry = bits.Add64(t1, 0, carry)
+	t2, carry = bits.Add64(t2, 0, carry)
+	t3, _ = bits.Add64(t3, 0, carry)
+
+	f.reduce(z, t0, t1, t2, t3, 0)
+}
+
+// montMul sets z = x * y * R^-1 mod p, using the CIOS method.
+func (f *field) montMul(z, x, y *fieldElement) {
+	var (
+		x0, x1, x2, x3         = x[0], x[1], x[2], x[3]
+		p0, p1, p2, p3         = f.p[0], f.p[1], f.p[2], f.p[3]
+		n0                     = f.n0
+		t0, t1, t2, t3, t4, t5 uint64
+		m, c, hi, lo, carry    uint64
+	)
+
+	// round 0: t = (t + x * y[0] + m * p) / 2^64
+	yi := y[0]
+	hi, lo = bits.Mul64(x0, yi)
+	t0, carry = bits.Add64(t0, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	t4, t5 = bits.Add64(t4, c, 0)
+	m = t0 * n0
+	hi, lo = bits.Mul64(m, p0)
+	_, carry = bits.Add64(lo, t0, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p1)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t0, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p2)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	t3, carry = bits.Add64(t4, c, 0)
+	t4 = t5 + carry
+
+	// round 1: t = (t + x * y[1] + m * p) / 2^64
+	yi = y[1]
+	hi, lo = bits.Mul64(x0, yi)
+	t0, carry = bits.Add64(t0, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	t4, t5 = bits.Add64(t4, c, 0)
+	m = t0 * n0
+	hi, lo = bits.Mul64(m, p0)
+	_, carry = bits.Add64(lo, t0, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p1)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t0, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p2)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	t3, carry = bits.Add64(t4, c, 0)
+	t4 = t5 + carry
+
+	// round 2: t = (t + x * y[2] + m * p) / 2^64
+	yi = y[2]
+	hi, lo = bits.Mul64(x0, yi)
+	t0, carry = bits.Add64(t0, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	t4, t5 = bits.Add64(t4, c, 0)
+	m = t0 * n0
+	hi, lo = bits.Mul64(m, p0)
+	_, carry = bits.Add64(lo, t0, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p1)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t0, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p2)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	t3, carry = bits.Add64(t4, c, 0)
+	t4 = t5 + carry
+
+	// round 3: t = (t + x * y[3] + m * p) / 2^64
+	yi = y[3]
+	hi, lo = bits.Mul64(x0, yi)
+	t0, carry = bits.Add64(t0, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, yi)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t3, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	t4, t5 = bits.Add64(t4, c, 0)
+	m = t0 * n0
+	hi, lo = bits.Mul64(m, p0)
+	_, carry = bits.Add64(lo, t0, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p1)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t0, carry = bits.Add64(t1, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p2)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t1, carry = bits.Add64(t2, lo, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, p3)
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	t2, carry = bits.Add64(t3, lo, 0)
+	c = hi + carry
+	t3, carry = bits.Add64(t4, c, 0)
+	t4 = t5 + carry
+
+	f.reduce(z, t0, t1, t2, t3, t4)
+}
+
+// reduce sets z = t mod p, given t < 2p.
+func (f *field) reduce(z *fieldElement, t0, t1, t2, t3, t4 uint64) {
+	var b uint64
+	r0, b := bits.Sub64(t0, f.p[0], 0)
+	r1, b := bits.Sub64(t1, f.p[1], b)
+	r2, b := bits.Sub64(t2, f.p[2], b)
+	r3, b := bits.Sub64(t3, f.p[3], b)
+	_, b = bits.Sub64(t4, 0, b)
+
+	// b == 1 means t < p, keep t
+	mask := -b
+	z[0] = (t0 & mask) | (r0 &^ mask)
+	z[1] = (t1 & mask) | (r1 &^ mask)
+	z[2] = (t2 & mask) | (r2 &^ mask)
+	z[3] = (t3 & mask) | (r3 &^ mask)
+}
+
+// sqr sets z = x * x * R^-1 mod p.
+func (f *field) sqr(z, x *fieldElement) {
+	if f.c != 0 {
+		f.pmSqr(z, x)
+		return
+	}
+	f.montMul(z, x, x)
+}
+
+// add sets z = x + y mod p.
+func (f *field) add(z, x, y *fieldElement) {
+	var c uint64
+	t0, c := bits.Add64(x[0], y[0], 0)
+	t1, c := bits.Add64(x[1], y[1], c)
+	t2, c := bits.Add64(x[2], y[2], c)
+	t3, c := bits.Add64(x[3], y[3], c)
+	f.reduce(z, t0, t1, t2, t3, c)
+}
+
+// sub sets z = x - y mod p.
+func (f *field) sub(z, x, y *fieldElement) {
+	var b uint64
+	t0, b := bits.Sub64(x[0], y[0], 0)
+	t1, b := bits.Sub64(x[1], y[1], b)
+	t2, b := bits.Sub64(x[2], y[2], b)
+	t3, b := bits.Sub64(x[3], y[3], b)
+
+	// add p back if borrowed
+	mask := -b
+	var c uint64
+	z[0], c = bits.Add64(t0, f.p[0]&mask, 0)
+	z[1], c = bits.Add64(t1, f.p[1]&mask, c)
+	z[2], c = bits.Add64(t2, f.p[2]&mask, c)
+	z[3], _ = bits.Add64(t3, f.p[3]&mask, c)
+}
+
+// neg sets z = -x mod p.
+func (f *field) neg(z, x *fieldElement) {
+	var zero fieldElement
+	f.sub(z, &zero, x)
+}
+
+// exp sets z = x^e mod p, using 4-bit fixed windows.
+// The sequence of operations depends only on the exponent.
+func (f *field) exp(z, x *fieldElement, e *big.Int) {
+	// powers[i] = x^i
+	var powers [16]fieldElement
+	powers[0] = f.one
+	powers[1] = *x
+	for i := 2; i < 16; i++ {
+		f.mul(&powers[i], &powers[i-1], x)
+	}
+
+	r := f.one
+	for i := (e.BitLen() + 3) / 4 * 4; i > 0; i -= 4 {
+		f.sqr(&r, &r)
+		f.sqr(&r, &r)
+		f.sqr(&r, &r)
+		f.sqr(&r, &r)
+		w := e.Bit(i-1)<<3 | e.Bit(i-2)<<2 | e.Bit(i-3)<<1 | e.Bit(i-4)
+		if w != 0 {
+			f.mul(&r, &r, &powers[w])
+		}
+	}
+	*z = r
+}
+
+// inv sets z = x^-1 mod p, or zero if x is zero.
+func (f *field) inv(z, x *fieldElement) {
+	f.exp(z, x, f.pMinus2)
+}
+
+// isZero returns 1 if x is zero, otherwise 0.
+func (f *field) isZero(x *fieldElement) uint64 {
+	v := x[0] | x[1] | x[2] | x[3]
+	return 1 ^ ((v | -v) >> 63)
+}
+
+// equal returns 1 if x == y, otherwise 0.
+func (f *field) equal(x, y *fieldElement) uint64 {
+	var d fieldElement
+	for i := range d {
+		d[i] = x[i] ^ y[i]
+	}
+	return f.isZero(&d)
+}
+
+// selectElement sets z = x if cond is 1, or z = y if cond is 0.
+func selectElement(z, x, y *fieldElement, cond uint64) {
+	mask := -cond
+	for i := range z {
+		z[i] = (x[i] & mask) | (y[i] &^ mask)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+var secp256k1P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+
+func TestField(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *big.Int
+	}{
+		{"montgomery", elliptic.P256().Params().P},
+		{"pseudo-mersenne", secp256k1P},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newField(tt.p)
+			for i := 0; i < 200; i++ {
+				x, _ := rand.Int(rand.Reader, tt.p)
+				y, _ := rand.Int(rand.Reader, tt.p)
+				// edge values
+				switch i {
+				case 0:
+					x.SetInt64(0)
+				case 1:
+					x.Sub(tt.p, big.NewInt(1))
+					y.Sub(tt.p, big.NewInt(1))
+				}
+
+				var fx, fy, fz fieldElement
+				f.fromBig(&fx, x)
+				f.fromBig(&fy, y)
+
+				check := func(op string, want *big.Int) {
+					t.Helper()
+					if got := f.toBig(&fz); got.Cmp(want.Mod(want, tt.p)) != 0 {
+						t.Fatalf("%v(%v, %v) = %v, want %v", op, x, y, got, want)
+					}
+				}
+				f.mul(&fz, &fx, &fy)
+				check("mul", new(big.Int).Mul(x, y))
+				f.sqr(&fz, &fx)
+				check("sqr", new(big.Int).Mul(x, x))
+				f.add(&fz, &fx, &fy)
+				check("add", new(big.Int).Add(x, y))
+				f.sub(&fz, &fx, &fy)
+				check("sub", new(big.Int).Sub(x, y))
+				f.neg(&fz, &fx)
+				check("neg", new(big.Int).Neg(x))
+				if x.Sign() != 0 {
+					f.inv(&fz, &fx)
+					check("inv", new(big.Int).ModInverse(x, tt.p))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build go1.24
+// +build go1.24
+
+package ecvrf
+
+import "crypto/fips140"
+
+// FIPSMode reports whether the Go Cryptographic Module runs in FIPS 140-3 mode, which is enabled
+// by GODEBUG=fips140=on or by building with GOFIPS140 (requires Go 1.24+).
+//
+// In FIPS mode, VRF objects only accept the NIST curves, whose operations are implemented by the
+// module through crypto/elliptic, and RFC6979 nonces are computed by crypto/hmac. Hashing goes
+// through Config.NewHasher, which is crypto/sha256 for the P256_SHA256_TAI suite. Other curves,
+// including secp256k1, are rejected since they're computed by the arithmetic of this package.
+func FIPSMode() bool {
+	return fips140.Enabled()
+}
@@ -0,0 +1,13 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build !go1.24
+// +build !go1.24
+
+package ecvrf
+
+// FIPSMode reports whether the Go Cryptographic Module runs in FIPS 140-3 mode,
+// which is never the case before Go 1.24.
+func FIPSMode() bool {
+	return false
+}
@@ -0,0 +1,10 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package generic types VRFs by their keys, as VRF[K, P], so suites whose keys don't fit
+// crypto/ecdsa, such as those over edwards25519 or ristretto255, share one interface with the
+// Weierstrass ones, and generic code, e.g. a registry of keys, works with all of them.
+//
+// Both ecvrf.VRF and ecvrf.Ed25519VRF already are VRFs of this package, of ECDSA and Ed25519 keys.
+// Scalars adapts an ecvrf.VRF to keys held as raw scalars. The package requires Go 1.18.
+package generic
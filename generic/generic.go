@@ -0,0 +1,118 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build go1.18
+// +build go1.18
+
+package generic
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+var (
+	errInvalidScalar = errors.New("generic: invalid scalar")
+	errPublicKeyType = errors.New("generic: public key of another type")
+)
+
+// PrivateKey is the constraint of the private keys of VRFs. Like those of the standard library,
+// a private key gives its public key, e.g. *ecdsa.PrivateKey or ed25519.PrivateKey.
+type PrivateKey interface {
+	Public() crypto.PublicKey
+}
+
+// PublicKey is the constraint of the public keys of VRFs. Like those of the standard library,
+// public keys can be compared, e.g. *ecdsa.PublicKey or ed25519.PublicKey.
+type PublicKey interface {
+	Equal(x crypto.PublicKey) bool
+}
+
+// VRF is a VRF of private keys K and public keys P.
+type VRF[K PrivateKey, P PublicKey] interface {
+	// Prove constructs a VRF proof `pi` for the given input `alpha`,
+	// using the private key `sk`. The hash output is returned as `beta`.
+	Prove(sk K, alpha []byte) (beta, pi []byte, err error)
+
+	// Verify checks the proof `pi` of the message `alpha` against the given
+	// public key `pk`. The hash output is returned as `beta`.
+	Verify(pk P, alpha, pi []byte) (beta []byte, err error)
+}
+
+var (
+	_ VRF[*ecdsa.PrivateKey, *ecdsa.PublicKey]   = ecvrf.VRF(nil)
+	_ VRF[ed25519.PrivateKey, ed25519.PublicKey] = ecvrf.Ed25519VRF(nil)
+)
+
+// ECDSA returns v as a VRF of ECDSA keys.
+func ECDSA(v ecvrf.VRF) VRF[*ecdsa.PrivateKey, *ecdsa.PublicKey] {
+	return v
+}
+
+// Ed25519 returns v as a VRF of Ed25519 keys.
+func Ed25519(v ecvrf.Ed25519VRF) VRF[ed25519.PrivateKey, ed25519.PublicKey] {
+	return v
+}
+
+// Public returns the public key of sk, as the public key type of the VRF.
+func Public[K PrivateKey, P PublicKey](sk K) (P, error) {
+	pk, ok := sk.Public().(P)
+	if !ok {
+		var zero P
+		return zero, errPublicKeyType
+	}
+	return pk, nil
+}
+
+// Scalar is a private key held as a raw big-endian scalar of a Weierstrass curve, e.g. as
+// exported by a KMS or stored by an application, as long as the curve is known.
+type Scalar struct {
+	Curve elliptic.Curve
+	D     []byte
+}
+
+// Public returns the *ecdsa.PublicKey of the scalar, or nil if it's invalid.
+func (s Scalar) Public() crypto.PublicKey {
+	sk, err := s.privateKey()
+	if err != nil {
+		return nil
+	}
+	return &sk.PublicKey
+}
+
+func (s Scalar) privateKey() (*ecdsa.PrivateKey, error) {
+	if s.Curve == nil {
+		return nil, errInvalidScalar
+	}
+	d := new(big.Int).SetBytes(s.D)
+	if d.Sign() == 0 || d.Cmp(s.Curve.Params().N) >= 0 {
+		return nil, errInvalidScalar
+	}
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = s.Curve
+	sk.X, sk.Y = s.Curve.ScalarBaseMult(s.D)
+	return sk, nil
+}
+
+// Scalars returns v as a VRF of raw scalars, whose public keys are ECDSA keys.
+func Scalars(v ecvrf.VRF) VRF[Scalar, *ecdsa.PublicKey] {
+	return scalarVRF{v}
+}
+
+type scalarVRF struct {
+	ecvrf.VRF
+}
+
+func (v scalarVRF) Prove(s Scalar, alpha []byte) (beta, pi []byte, err error) {
+	sk, err := s.privateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return v.VRF.Prove(sk, alpha)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// glv holds the parameters of the efficient endomorphism φ(x, y) = (βx, y) = λ(x, y) of
+// curves with a = 0 and p = 1 mod 3, used to split scalars into halves
+// ([Gallant-Lambert-Vanstone 2001](https://www.iacr.org/archive/crypto2001/21390189.pdf)).
+// Scalars are split without big.Int, following libsecp256k1.
+type glv struct {
+	fq     *field
+	beta   fieldElement // cube root of unity in the base field, in Montgomery form
+	lambda fieldElement // cube root of unity modulo n, in Montgomery form
+
+	// -b1 and -b2 of the short basis {(a1, b1), (a2, b2)} of the lattice {(x, y) | x + yλ = 0 mod n},
+	// in Montgomery form
+	minusB1, minusB2 fieldElement
+	// g1 = round(2^384 * b2 / n), g2 = round(2^384 * -b1 / n), as plain integers
+	g1, g2 fieldElement
+	halfN  fieldElement // (n - 1) / 2, as a plain integer
+}
+
+func mustHex(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex string")
+	}
+	return v
+}
+
+// secp256k1GLV returns the endomorphism parameters if the curve is secp256k1, otherwise nil.
+// Constants are taken from libsecp256k1.
+func secp256k1GLV(fp, fq *field, p, n *big.Int) *glv {
+	if p.Cmp(mustHex("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f")) != 0 ||
+		n.Cmp(mustHex("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")) != 0 {
+		return nil
+	}
+	var (
+		b1 = new(big.Int).Neg(mustHex("e4437ed6010e88286f547fa90abfe4c3"))
+		b2 = mustHex("3086d221a7d46bcde86c90e49284eb15")
+		r  = new(big.Int).Lsh(big.NewInt(1), 384)
+		g  = &glv{fq: fq}
+	)
+	fp.fromBig(&g.beta, mustHex("7ae96a2b657c07106e64479eac3434e99cf0497512f58995c1396c28719501ee"))
+	fq.fromBig(&g.lambda, mustHex("5363ad4cc05c30e0a5261c028812645a122e22ea20816678df02967c1b23bd72"))
+	fq.fromBig(&g.minusB1, new(big.Int).Neg(b1))
+	fq.fromBig(&g.minusB2, new(big.Int).Neg(b2))
+	g.g1 = limbsFromBig(roundDiv(new(big.Int).Mul(r, b2), n))
+	g.g2 = limbsFromBig(roundDiv(new(big.Int).Mul(r, new(big.Int).Neg(b1)), n))
+	g.halfN = limbsFromBig(new(big.Int).Rsh(n, 1))
+	return g
+}
+
+// split returns k1, k2 of about half the bit length of n, such that k = ±k1 ± k2*λ mod n,
+// where the signs are negative if neg1 and neg2 are set respectively.
+// k is a plain integer less than 2^256, and so are k1 and k2.
+func (g *glv) split(k *fieldElement) (k1, k2 fieldElement, neg1, neg2 bool) {
+	var (
+		fq             = g.fq
+		kr, km, c1, c2 fieldElement
+	)
+	fq.fromLimbs(&km, k)
+	fq.toLimbs(&kr, &km)
+
+	// c1 = round(k * g1 / 2^384) = round(b2 * k / n), c2 = round(k * g2 / 2^384) = round(-b1 * k / n)
+	c1 = mulShift384(&kr, &g.g1)
+	c2 = mulShift384(&kr, &g.g2)
+	fq.fromLimbs(&c1, &c1)
+	fq.fromLimbs(&c2, &c2)
+
+	// k2 = c1*(-b1) + c2*(-b2), k1 = k - k2*λ
+	var m1, m2 fieldElement
+	fq.mul(&m1, &c1, &g.minusB1)
+	fq.mul(&m2, &c2, &g.minusB2)
+	fq.add(&m2, &m1, &m2)
+	fq.mul(&m1, &m2, &g.lambda)
+	fq.sub(&m1, &km, &m1)
+
+	// choose the shorter of ±k1 and ±k2
+	fq.toLimbs(&k1, &m1)
+	if lessThan(&g.halfN, &k1) {
+		fq.neg(&m1, &m1)
+		fq.toLimbs(&k1, &m1)
+		neg1 = true
+	}
+	fq.toLimbs(&k2, &m2)
+	if lessThan(&g.halfN, &k2) {
+		fq.neg(&m2, &m2)
+		fq.toLimbs(&k2, &m2)
+		neg2 = true
+	}
+	return
+}
+
+// mulShift384 returns round(x * y / 2^384) for plain integers x and y.
+func mulShift384(x, y *fieldElement) (r fieldElement) {
+	var t [8]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(x[i], y[j])
+			var c uint64
+			lo, c = bits.Add64(lo, t[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[i+j], carry = lo, hi
+		}
+		t[i+4] = carry
+	}
+	var c uint64
+	r[0], c = bits.Add64(t[6], t[5]>>63, 0)
+	r[1] = t[7] + c
+	return
+}
+
+// endomorphism sets r = φ(p) = λ * p.
+func (g *glv) endomorphism(fp *field, r, p *projectivePoint) {
+	fp.mul(&r.x, &p.x, &g.beta)
+	r.y = p.y
+	r.z = p.z
+}
+
+// roundDiv returns round(x / y) for positive y.
+func roundDiv(x, y *big.Int) *big.Int {
+	// floor((2x + y) / 2y)
+	num := new(big.Int).Lsh(x, 1)
+	num.Add(num, y)
+	return num.Div(num, new(big.Int).Lsh(y, 1))
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestGLVSplit(t *testing.T) {
+	w := newTestWeierstrass(t, secp256k1, big.NewInt(0))
+	if w.glv == nil {
+		t.Fatal("endomorphism not detected")
+	}
+	n := secp256k1.N
+	for i := 0; i < 100; i++ {
+		k, _ := rand.Int(rand.Reader, n)
+		if i == 0 {
+			k.Sub(n, big.NewInt(1))
+		}
+		kl := limbsFromBig(k)
+		l1, l2, neg1, neg2 := w.glv.split(&kl)
+		k1, k2 := new(big.Int).SetBytes(limbsBytes(&l1)), new(big.Int).SetBytes(limbsBytes(&l2))
+		if k1.BitLen() > 129 || k2.BitLen() > 129 {
+			t.Fatalf("split(%v) = (%v, %v), too long", k, k1, k2)
+		}
+		if neg1 {
+			k1.Neg(k1)
+		}
+		if neg2 {
+			k2.Neg(k2)
+		}
+		// k1 + k2*λ = k mod n
+		sum := new(big.Int).Mul(k2, w.glv.fq.toBig(&w.glv.lambda))
+		sum.Add(sum, k1).Mod(sum, n)
+		if sum.Cmp(k) != 0 {
+			t.Fatalf("split(%v) = (%v, %v), k1 + k2*λ = %v", k, k1, k2, sum)
+		}
+	}
+}
+
+func TestWeierstrassScalarMultVartime(t *testing.T) {
+	for _, tt := range weierstrassTests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWeierstrass(t, tt.params, tt.a)
+			for i := 0; i < 10; i++ {
+				r := make([]byte, 32)
+				rand.Read(r)
+				px, py := refScalarMult(tt.params.P, tt.a, tt.params.Gx, tt.params.Gy, r)
+
+				k, _ := rand.Int(rand.Reader, tt.params.N)
+				switch i {
+				case 0:
+					k.SetInt64(0)
+				case 1:
+					k.Sub(tt.params.N, big.NewInt(1))
+				}
+				wantX, wantY := refScalarMult(tt.params.P, tt.a, px, py, k.Bytes())
+
+				var p projectivePoint
+				kl := limbsFromBig(k)
+				w.fromBigAffine(&p, px, py)
+				w.scalarMultVartime(&p, &p, &kl)
+				if x, y := w.toAffine(&p); x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+					t.Errorf("scalarMultVartime(%v) = (%v, %v), want (%v, %v)", k, x, y, wantX, wantY)
+				}
+			}
+		})
+	}
+}
+
+func TestWeierstrassMultiScalarMultVartime(t *testing.T) {
+	for _, tt := range weierstrassTests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWeierstrass(t, tt.params, tt.a)
+			// sum of (-1)^i * k_i * G for more terms than a single Strauss batch
+			var (
+				terms        = make([]vartimeTerm, 7)
+				sum          = new(big.Int)
+				wantX, wantY *big.Int
+			)
+			for i := range terms {
+				k, _ := rand.Int(rand.Reader, tt.params.N)
+				terms[i] = vartimeTerm{k: limbsFromBig(k), neg: i%2 == 1}
+				w.fromBigAffine(&terms[i].p, tt.params.Gx, tt.params.Gy)
+				if terms[i].neg {
+					k.Neg(k)
+				}
+				sum.Add(sum, k)
+			}
+			sum.Mod(sum, tt.params.N)
+			wantX, wantY = refScalarMult(tt.params.P, tt.a, tt.params.Gx, tt.params.Gy, sum.Bytes())
+
+			var r projectivePoint
+			w.multiScalarMultVartime(&r, terms)
+			if x, y := w.toAffine(&r); x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+				t.Errorf("multiScalarMultVartime() = (%v, %v), want (%v, %v)", x, y, wantX, wantY)
+			}
+		})
+	}
+}
+
+// limbsBytes returns the big-endian encoding of plain limbs.
+func limbsBytes(v *fieldElement) []byte {
+	var buf [32]byte
+	limbsToBytes(&buf, v)
+	return buf[:]
+}
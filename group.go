@@ -0,0 +1,275 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+// group is the point arithmetic the proofs and verifications of the core are built on, so a curve
+// backend only has to implement it to be used by the suites. Points are affine, the point at
+// infinity being (0, 0) as in crypto/elliptic.
+type group interface {
+	// Order returns the prime order q of the large subgroup.
+	Order() *big.Int
+	// Cofactor returns the cofactor of the curve.
+	Cofactor() byte
+
+	Add(p1, p2 *point) *point
+	Neg(p *point) *point
+	// ScalarMult returns k * p, in constant time with respect to k of the length of q.
+	ScalarMult(p *point, k []byte) *point
+	// ScalarBaseMult returns k * B, in constant time with respect to k of the length of q.
+	ScalarBaseMult(k []byte) *point
+	// ScalarMultVartime returns k * p in variable time. It must only be used with public inputs.
+	ScalarMultVartime(p *point, k *big.Int) *point
+	// MulSubVartime returns k1*p1 - k2*p2 in variable time. It must only be used with public inputs.
+	MulSubVartime(p1 *point, k1 *big.Int, p2 *point, k2 *big.Int) *point
+
+	// Compress encodes a point, and Decompress decodes it, checking it's on the curve.
+	Compress(p *point) []byte
+	Decompress(in []byte) (*point, error)
+	// Validate reports whether p is on the curve with canonical coordinates.
+	Validate(p *point) bool
+}
+
+// newGroup returns the group of the curve, using the constant-time arithmetic w if not nil,
+// and the arithmetic of elliptic.Curve otherwise.
+func newGroup(curve elliptic.Curve, cfg *Config, w *weierstrass) group {
+	g := &curveGroup{curve, cfg}
+	if w != nil {
+		return &arithGroup{g, w}
+	}
+	return g
+}
+
+// curveGroup is the group of a short Weierstrass curve, with the arithmetic of elliptic.Curve,
+// and the equation and square roots of the config.
+type curveGroup struct {
+	curve elliptic.Curve
+	cfg   *Config
+}
+
+func (g *curveGroup) Order() *big.Int {
+	return g.curve.Params().N
+}
+
+func (g *curveGroup) Cofactor() byte {
+	return g.cfg.Cofactor
+}
+
+func (g *curveGroup) Add(p1, p2 *point) *point {
+	x, y := g.curve.Add(p1.X, p1.Y, p2.X, p2.Y)
+	return &point{x, y}
+}
+
+// Neg returns -p = (x, P - y).
+func (g *curveGroup) Neg(p *point) *point {
+	return &point{p.X, new(big.Int).Sub(g.curve.Params().P, p.Y)}
+}
+
+func (g *curveGroup) ScalarMult(p *point, k []byte) *point {
+	x, y := g.curve.ScalarMult(p.X, p.Y, k)
+	return &point{x, y}
+}
+
+func (g *curveGroup) ScalarBaseMult(k []byte) *point {
+	x, y := g.curve.ScalarBaseMult(k)
+	return &point{x, y}
+}
+
+func (g *curveGroup) ScalarMultVartime(p *point, k *big.Int) *point {
+	return g.ScalarMult(p, k.Bytes())
+}
+
+func (g *curveGroup) MulSubVartime(p1 *point, k1 *big.Int, p2 *point, k2 *big.Int) *point {
+	return g.Add(g.ScalarMult(p1, k1.Bytes()), g.Neg(g.ScalarMult(p2, k2.Bytes())))
+}
+
+// Compress encodes the point in the compressed form specified in section 4.3.6 of ANSI X9.62.
+func (g *curveGroup) Compress(p *point) []byte {
+	byteLen := (g.curve.Params().BitSize + 7) / 8
+	out := make([]byte, byteLen+1)
+
+	// compress format, 3 for odd y
+	out[0] = 2 + byte(p.Y.Bit(0))
+
+	bytes := p.X.Bytes()
+
+	if n := len(bytes); byteLen > n {
+		copy(out[1+byteLen-n:], bytes)
+	} else {
+		copy(out[1:], bytes)
+	}
+	return out
+}
+
+// checkEncoding checks the length and the tag of a compressed point.
+func (g *curveGroup) checkEncoding(in []byte) error {
+	byteLen := (g.curve.Params().BitSize + 7) / 8
+	if len(in) != 1+byteLen {
+		return ErrInvalidPointLength
+	}
+	if (in[0] &^ 1) != 2 {
+		return ErrUnrecognizedPointEncoding
+	}
+	return nil
+}
+
+// Decompress decodes a point in the compressed form specified in section 4.3.6 of ANSI X9.62.
+// This is borrowed from the project https://github.com/google/keytransparency.
+func (g *curveGroup) Decompress(in []byte) (*point, error) {
+	if err := g.checkEncoding(in); err != nil {
+		return nil, err
+	}
+
+	// Based on Routine 2.2.4 in NIST Mathematical routines paper
+	p := g.curve.Params().P
+	x := new(big.Int).SetBytes(in[1:])
+	if x.Cmp(p) >= 0 {
+		return nil, ErrNonCanonicalPoint
+	}
+	y2 := g.cfg.Y2(g.curve, x)
+
+	y := g.cfg.Sqrt(g.curve, y2)
+	if y == nil {
+		return nil, ErrNotSquare
+	}
+
+	var y2c big.Int
+	y2c.Mul(y, y).Mod(&y2c, p)
+	if y2c.Cmp(y2) != 0 {
+		return nil, errors.New("invalid point: sqrt(y2)^2 != y2")
+	}
+
+	if y.Bit(0) != uint(in[0]&1) {
+		y.Sub(p, y)
+	}
+
+	// Y2 comes from the config, and the curve arithmetic panics on points off its own equation
+	if !g.curve.IsOnCurve(x, y) {
+		return nil, ErrNotOnCurve
+	}
+
+	// valid point: return it
+	return &point{x, y}, nil
+}
+
+// canonical reports whether the coordinates of p are in [0, P).
+func (g *curveGroup) canonical(p *point) bool {
+	P := g.curve.Params().P
+	return p.X != nil && p.Y != nil &&
+		p.X.Sign() >= 0 && p.X.Cmp(P) < 0 && p.Y.Sign() >= 0 && p.Y.Cmp(P) < 0
+}
+
+func (g *curveGroup) Validate(p *point) bool {
+	if !g.canonical(p) {
+		return false
+	}
+	P := g.curve.Params().P
+	y2 := new(big.Int).Mul(p.Y, p.Y)
+	return y2.Mod(y2, P).Cmp(new(big.Int).Mod(g.cfg.Y2(g.curve, p.X), P)) == 0 && g.curve.IsOnCurve(p.X, p.Y)
+}
+
+// arithGroup is the group of a curve supported by the constant-time arithmetic, falling back to
+// curveGroup for the operations it doesn't cover.
+type arithGroup struct {
+	*curveGroup
+	w *weierstrass
+}
+
+func (g *arithGroup) Add(p1, p2 *point) *point {
+	var r1, r2 projectivePoint
+	g.w.fromBigAffine(&r1, p1.X, p1.Y)
+	g.w.fromBigAffine(&r2, p2.X, p2.Y)
+	g.w.add(&r1, &r1, &r2)
+	x, y := g.w.toAffine(&r1)
+	return &point{x, y}
+}
+
+func (g *arithGroup) ScalarMult(p *point, k []byte) *point {
+	var r projectivePoint
+	g.w.fromBigAffine(&r, p.X, p.Y)
+	g.w.scalarMult(&r, &r, g.padScalar(k))
+	x, y := g.w.toAffine(&r)
+	return &point{x, y}
+}
+
+// padScalar left pads the scalar with zeros to the length of the group order,
+// so that the running time of scalar multiplications doesn't depend on its bit length.
+func (g *arithGroup) padScalar(k []byte) []byte {
+	qlen := (g.Order().BitLen() + 7) / 8
+	if len(k) >= qlen {
+		return k
+	}
+	out := make([]byte, qlen)
+	copy(out[qlen-len(k):], k)
+	return out
+}
+
+func (g *arithGroup) ScalarBaseMult(k []byte) *point {
+	if len(k) > 32 {
+		return g.curveGroup.ScalarBaseMult(k)
+	}
+	// use the precomputed table of the base point
+	var r projectivePoint
+	g.w.scalarBaseMult(&r, k)
+	x, y := g.w.toAffine(&r)
+	return &point{x, y}
+}
+
+func (g *arithGroup) ScalarMultVartime(p *point, k *big.Int) *point {
+	var (
+		r  projectivePoint
+		kl = g.vartimeScalar(k)
+	)
+	g.w.fromBigAffine(&r, p.X, p.Y)
+	g.w.scalarMultVartime(&r, &r, &kl)
+	x, y := g.w.toAffine(&r)
+	return &point{x, y}
+}
+
+// MulSubVartime uses a joint multi-scalar multiplication, where the doublings are shared by both terms.
+func (g *arithGroup) MulSubVartime(p1 *point, k1 *big.Int, p2 *point, k2 *big.Int) *point {
+	var (
+		r     projectivePoint
+		terms [2]vartimeTerm
+	)
+	g.w.fromBigAffine(&terms[0].p, p1.X, p1.Y)
+	g.w.fromBigAffine(&terms[1].p, p2.X, p2.Y)
+	terms[0].k = g.vartimeScalar(k1)
+	terms[1].k, terms[1].neg = g.vartimeScalar(k2), true
+	g.w.multiScalarMultVartime(&r, terms[:])
+	x, y := g.w.toAffine(&r)
+	return &point{x, y}
+}
+
+// vartimeScalar converts k to the limbs taken by variable-time multiplications, reducing it if out of range.
+func (g *arithGroup) vartimeScalar(k *big.Int) fieldElement {
+	if k.Sign() < 0 || k.BitLen() > 256 {
+		k = new(big.Int).Mod(k, g.Order())
+	}
+	return limbsFromBig(k)
+}
+
+func (g *arithGroup) Decompress(in []byte) (*point, error) {
+	if !g.w.canDecompress() {
+		return g.curveGroup.Decompress(in)
+	}
+	if err := g.checkEncoding(in); err != nil {
+		return nil, err
+	}
+	var p projectivePoint
+	if !g.w.decompress(&p, in[1:], uint(in[0]&1)) {
+		return nil, ErrNotSquare
+	}
+	x, y := g.w.toAffine(&p)
+	return &point{x, y}, nil
+}
+
+func (g *arithGroup) Validate(p *point) bool {
+	return g.canonical(p) && g.w.isOnCurve(p.X, p.Y)
+}
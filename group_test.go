@@ -0,0 +1,56 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// TestGroupBackends checks that the constant-time arithmetic of P-256 agrees with the
+// arithmetic of the standard library behind the group interface.
+func TestGroupBackends(t *testing.T) {
+	var (
+		v       = NewP256Sha256Tai().(*vrf)
+		generic = &curveGroup{elliptic.P256(), &v.cfg}
+		w       = newWeierstrass(elliptic.P256().Params(), v.cfg.Y2)
+	)
+	if w == nil {
+		t.Fatal("curve not supported")
+	}
+	backends := []group{generic, &arithGroup{generic, w}}
+
+	equal := func(name string, f func(g group) *point) {
+		want := f(backends[0])
+		if got := f(backends[1]); got.X.Cmp(want.X) != 0 || got.Y.Cmp(want.Y) != 0 {
+			t.Fatalf("%s = (%v, %v), want (%v, %v)", name, got.X, got.Y, want.X, want.Y)
+		}
+	}
+	q := generic.Order()
+	for i := 0; i < 20; i++ {
+		a, _ := rand.Int(rand.Reader, q)
+		b, _ := rand.Int(rand.Reader, q)
+		p1, p2 := generic.ScalarBaseMult(a.Bytes()), generic.ScalarBaseMult(b.Bytes())
+
+		equal("ScalarBaseMult", func(g group) *point { return g.ScalarBaseMult(a.Bytes()) })
+		equal("ScalarMult", func(g group) *point { return g.ScalarMult(p1, b.Bytes()) })
+		equal("ScalarMultVartime", func(g group) *point { return g.ScalarMultVartime(p1, b) })
+		equal("MulSubVartime", func(g group) *point { return g.MulSubVartime(p1, a, p2, b) })
+		equal("Add", func(g group) *point { return g.Add(p1, g.Neg(p2)) })
+		equal("Decompress", func(g group) *point {
+			p, err := g.Decompress(g.Compress(p1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return p
+		})
+		for _, g := range backends {
+			if !g.Validate(p1) || g.Validate(&point{p1.X, new(big.Int).Add(p1.Y, big.NewInt(1))}) {
+				t.Fatalf("%T: Validate() accepts an off-curve point or rejects a valid one", g)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/hmac"
+	"hash"
+	"sync"
+)
+
+// hasherPool keeps hash states created by Config.NewHasher for reuse, since
+// try_and_increment and RFC6979 go through many of them per proof.
+type hasherPool struct {
+	newHasher func() hash.Hash
+	pool      sync.Pool
+}
+
+func newHasherPool(newHasher func() hash.Hash) *hasherPool {
+	return &hasherPool{newHasher: newHasher}
+}
+
+// get returns a reset hash state.
+func (p *hasherPool) get() hash.Hash {
+	if h, ok := p.pool.Get().(hash.Hash); ok {
+		h.Reset()
+		return h
+	}
+	return p.newHasher()
+}
+
+// put releases the hash state for reuse.
+func (p *hasherPool) put(h hash.Hash) {
+	p.pool.Put(h)
+}
+
+// hmacState computes HMAC ([RFC2104](https://tools.ietf.org/html/rfc2104)) over hash states of the pool.
+// Unlike crypto/hmac, the key can be changed without allocations, which RFC6979 does at each step.
+// In FIPS mode, it delegates to crypto/hmac instead, which implements HMAC in the Go Cryptographic Module.
+type hmacState struct {
+	pool         *hasherPool
+	inner, outer hash.Hash
+	ipad, opad   []byte
+	innerSum     []byte
+	std          hash.Hash // crypto/hmac in FIPS mode, otherwise nil
+}
+
+// newHMAC creates the HMAC state of the given key. release must be called after use.
+func (p *hasherPool) newHMAC(key []byte) *hmacState {
+	if FIPSMode() {
+		return &hmacState{pool: p, std: hmac.New(p.newHasher, key)}
+	}
+	m := &hmacState{pool: p, inner: p.get(), outer: p.get()}
+	m.ipad = make([]byte, m.inner.BlockSize())
+	m.opad = make([]byte, m.inner.BlockSize())
+	m.innerSum = make([]byte, 0, m.inner.Size())
+	m.setKey(key)
+	return m
+}
+
+// setKey restarts the HMAC with the new key.
+func (m *hmacState) setKey(key []byte) {
+	if m.std != nil {
+		m.std = hmac.New(m.pool.newHasher, key)
+		return
+	}
+	if len(key) > len(m.ipad) {
+		// hash keys longer than a block
+		m.outer.Reset()
+		m.outer.Write(key)
+		key = m.outer.Sum(m.innerSum[:0])
+	}
+	copy(m.ipad, key)
+	for i := len(key); i < len(m.ipad); i++ {
+		m.ipad[i] = 0
+	}
+	copy(m.opad, m.ipad)
+	for i := range m.ipad {
+		m.ipad[i] ^= 0x36
+		m.opad[i] ^= 0x5c
+	}
+	m.Reset()
+}
+
+func (m *hmacState) Write(p []byte) (int, error) {
+	if m.std != nil {
+		return m.std.Write(p)
+	}
+	return m.inner.Write(p)
+}
+
+// Sum appends the MAC of the data written so far to b. Like crypto/hmac, it doesn't change the state.
+func (m *hmacState) Sum(b []byte) []byte {
+	if m.std != nil {
+		return m.std.Sum(b)
+	}
+	m.innerSum = m.inner.Sum(m.innerSum[:0])
+	m.outer.Reset()
+	m.outer.Write(m.opad)
+	m.outer.Write(m.innerSum)
+	return m.outer.Sum(b)
+}
+
+// Reset restarts the HMAC with the same key.
+func (m *hmacState) Reset() {
+	if m.std != nil {
+		m.std.Reset()
+		return
+	}
+	m.inner.Reset()
+	m.inner.Write(m.ipad)
+}
+
+// release wipes the key material and returns the hash states to the pool. m must not be used afterwards.
+func (m *hmacState) release() {
+	if m.std != nil {
+		// crypto/hmac keeps its own copies of the key, which can't be wiped from here
+		m.std = nil
+		return
+	}
+	wipe(m.ipad)
+	wipe(m.opad)
+	wipe(m.innerSum[:cap(m.innerSum)])
+	wipeHasher(m.inner)
+	wipeHasher(m.outer)
+	m.pool.put(m.inner)
+	m.pool.put(m.outer)
+	m.inner, m.outer = nil, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"testing"
+)
+
+func TestHMACState(t *testing.T) {
+	for _, newHasher := range []func() hash.Hash{sha256.New, sha512.New} {
+		pool := newHasherPool(newHasher)
+		mac := pool.newHMAC(nil)
+		for _, keyLen := range []int{0, 32, 64, 128, 200} {
+			key := make([]byte, keyLen)
+			msg := make([]byte, keyLen+7)
+			rand.Read(key)
+			rand.Read(msg)
+
+			want := hmac.New(newHasher, key)
+			want.Write(msg)
+
+			// the state is reused across keys, and Sum must not change it
+			mac.setKey(key)
+			mac.Write(msg[:3])
+			mac.Sum(nil)
+			mac.Write(msg[3:])
+			if got := mac.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+				t.Fatalf("hmacState(key of %v octets) = %x, want %x", keyLen, got, want.Sum(nil))
+			}
+
+			mac.Reset()
+			mac.Write(msg)
+			if got := mac.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+				t.Fatalf("hmacState(key of %v octets) after Reset = %x, want %x", keyLen, got, want.Sum(nil))
+			}
+		}
+		mac.release()
+	}
+}
+
+func TestHMACStateRelease(t *testing.T) {
+	pool := newHasherPool(sha256.New)
+	mac := pool.newHMAC([]byte("secret key"))
+	mac.Write([]byte("secret data"))
+	mac.Sum(nil)
+
+	ipad, opad, inner := mac.ipad, mac.opad, mac.inner
+	mac.release()
+	for _, b := range append(append([]byte{}, ipad...), opad...) {
+		if b != 0 {
+			t.Fatal("release() left key material")
+		}
+	}
+	// the wiped hash state must work like a new one
+	want := sha256.Sum256([]byte("data"))
+	inner.Write([]byte("data"))
+	if got := inner.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Fatalf("wiped hasher Sum() = %x, want %x", got, want)
+	}
+}
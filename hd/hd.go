@@ -0,0 +1,215 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package hd derives VRF keys from a master seed by [SLIP-10](https://github.com/satoshilabs/slips/blob/master/slip-0010.md),
+// the generalization of BIP32 to P-256 and Ed25519, so the keys of all epochs or services are
+// backed up as one seed. For secp256k1, keys are those of BIP32.
+//
+// Only hardened derivation is supported: a VRF key is a secret only known to its prover, and
+// non-hardened derivation would let anyone holding a parent public key and a child secret key
+// recover the parent secret key, and thus all its siblings.
+package hd
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Hardened is the offset of the indices of hardened children.
+const Hardened uint32 = 0x80000000
+
+var (
+	errUnsupportedCurve = errors.New("hd: unsupported curve")
+	errSeedLength       = errors.New("hd: seed must be 16 to 64 octets")
+	errNotHardened      = errors.New("hd: only hardened derivation is supported")
+	errPath             = errors.New("hd: invalid path")
+)
+
+// Key is a node of the derivation tree.
+type Key struct {
+	curve     elliptic.Curve // nil for Ed25519
+	key       []byte
+	chainCode []byte
+}
+
+// NewMaster returns the master key of the seed for P-256 or secp256k1, by the curve name.
+func NewMaster(seed []byte, curve elliptic.Curve) (*Key, error) {
+	var hmacKey string
+	switch curve.Params().Name {
+	case "P-256":
+		hmacKey = "Nist256p1 seed"
+	case "secp256k1":
+		hmacKey = "Bitcoin seed"
+	default:
+		return nil, errUnsupportedCurve
+	}
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errSeedLength
+	}
+	n := curve.Params().N
+	I := hmacSHA512([]byte(hmacKey), seed)
+	for {
+		// retried on invalid keys, with probability below 2^-127
+		if k := new(big.Int).SetBytes(I[:32]); k.Sign() != 0 && k.Cmp(n) < 0 {
+			return &Key{curve: curve, key: I[:32], chainCode: I[32:]}, nil
+		}
+		I = hmacSHA512([]byte(hmacKey), I)
+	}
+}
+
+// NewMasterEd25519 returns the master key of the seed for Ed25519.
+func NewMasterEd25519(seed []byte) (*Key, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errSeedLength
+	}
+	I := hmacSHA512([]byte("ed25519 seed"), seed)
+	return &Key{key: I[:32], chainCode: I[32:]}, nil
+}
+
+// Child derives the child of the index, which must be hardened, i.e. at least Hardened.
+func (k *Key) Child(index uint32) (*Key, error) {
+	if index < Hardened {
+		return nil, errNotHardened
+	}
+	data := make([]byte, 37)
+	copy(data[1:], k.key)
+	binary.BigEndian.PutUint32(data[33:], index)
+	I := hmacSHA512(k.chainCode, data)
+	defer wipe(data)
+	if k.curve == nil {
+		return &Key{key: I[:32], chainCode: I[32:]}, nil
+	}
+
+	n := k.curve.Params().N
+	parent := new(big.Int).SetBytes(k.key)
+	for {
+		il := new(big.Int).SetBytes(I[:32])
+		if il.Cmp(n) < 0 {
+			il.Add(il, parent)
+			il.Mod(il, n)
+			if il.Sign() != 0 {
+				return &Key{curve: k.curve, key: il.FillBytes(I[:32]), chainCode: I[32:]}, nil
+			}
+		}
+		// I = HMAC-SHA512(c_par, 0x01 || IR || ser32(i)) on invalid keys
+		data[0] = 0x01
+		copy(data[1:], I[32:])
+		I = hmacSHA512(k.chainCode, data)
+	}
+}
+
+// Derive derives the descendant of the path from this key, e.g. "m/44'/818'/0'" from the master
+// key, where all the indices must be hardened, marked by ' or h.
+func (k *Key) Derive(path string) (*Key, error) {
+	indices, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, i := range indices {
+		child, err := key.Child(i)
+		if key != k {
+			key.Wipe()
+		}
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// ParsePath parses a path of hardened indices, e.g. "m/44'/818'/0'".
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, errPath
+	}
+	indices := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		if !strings.HasSuffix(part, "'") && !strings.HasSuffix(part, "h") {
+			return nil, errNotHardened
+		}
+		i, err := strconv.ParseUint(part[:len(part)-1], 10, 31)
+		if err != nil {
+			return nil, errPath
+		}
+		indices = append(indices, uint32(i)+Hardened)
+	}
+	return indices, nil
+}
+
+// Path formats the path of the hardened indices, e.g. Path(44, 818, epoch) for the key of an epoch.
+func Path(indices ...uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, i := range indices {
+		fmt.Fprintf(&b, "/%d'", i&^Hardened)
+	}
+	return b.String()
+}
+
+// ChainCode returns the chain code of the key.
+func (k *Key) ChainCode() []byte {
+	return append([]byte(nil), k.chainCode...)
+}
+
+// ECDSA returns the VRF private key of a P-256 or secp256k1 key, e.g. for NewP256Sha256Tai.
+func (k *Key) ECDSA() (*ecdsa.PrivateKey, error) {
+	if k.curve == nil {
+		return nil, errUnsupportedCurve
+	}
+	sk := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(k.key)}
+	sk.Curve = k.curve
+	sk.X, sk.Y = k.curve.ScalarBaseMult(k.key)
+	return sk, nil
+}
+
+// Ed25519 returns the VRF private key of an Ed25519 key, whose seed is the key, e.g. for
+// NewEd25519Sha512Ell2.
+func (k *Key) Ed25519() (ed25519.PrivateKey, error) {
+	if k.curve != nil {
+		return nil, errUnsupportedCurve
+	}
+	return ed25519.NewKeyFromSeed(k.key), nil
+}
+
+// PublicKey returns the public key matching the key, an *ecdsa.PublicKey or an ed25519.PublicKey,
+// e.g. to register the keys of future epochs.
+func (k *Key) PublicKey() crypto.PublicKey {
+	if k.curve == nil {
+		sk := ed25519.NewKeyFromSeed(k.key)
+		defer wipe(sk)
+		return sk.Public()
+	}
+	x, y := k.curve.ScalarBaseMult(k.key)
+	return &ecdsa.PublicKey{Curve: k.curve, X: x, Y: y}
+}
+
+// Wipe zeroes the key.
+func (k *Key) Wipe() {
+	wipe(k.key)
+	wipe(k.chainCode)
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
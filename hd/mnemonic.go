@@ -0,0 +1,133 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package hd
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+var (
+	errEntropyLength  = errors.New("hd: entropy must be 16 to 32 octets, by 4")
+	errMnemonicLength = errors.New("hd: mnemonic must have 12 to 24 words, by 3")
+	errMnemonicWord   = errors.New("hd: unknown mnemonic word")
+	errChecksum       = errors.New("hd: invalid mnemonic checksum")
+)
+
+// GenerateMnemonic returns a new BIP39 mnemonic of 12, 15, 18, 21 or 24 words, of entropy read
+// from rand, which is usually crypto/rand.Reader. 24 words hold the 256 bits of entropy of a
+// P-256 or Ed25519 key.
+func GenerateMnemonic(rand io.Reader, words int) (string, error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return "", errMnemonicLength
+	}
+	entropy := make([]byte, words/3*4)
+	defer wipe(entropy)
+	if _, err := io.ReadFull(rand, entropy); err != nil {
+		return "", err
+	}
+	return NewMnemonic(entropy)
+}
+
+// NewMnemonic returns the BIP39 mnemonic of the entropy, of English words.
+func NewMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", errEntropyLength
+	}
+	sum := sha256.Sum256(entropy)
+	// the entropy then the first len/4 bits of its hash, by 11 bits per word
+	data := append(append([]byte(nil), entropy...), sum[0])
+	defer wipe(data)
+	n := len(entropy) * 8 / 32 * 3
+	words := make([]string, n)
+	for i := range words {
+		idx := 0
+		for b := i * 11; b < (i+1)*11; b++ {
+			idx = idx<<1 | int(data[b/8]>>uint(7-b%8)&1)
+		}
+		words[i] = english[idx]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy returns the entropy of the mnemonic, checking its words and checksum.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, errMnemonicLength
+	}
+	data := make([]byte, (len(words)*11+7)/8)
+	defer wipe(data)
+	for i, w := range words {
+		idx := sort.SearchStrings(english, w)
+		if idx == len(english) || english[idx] != w {
+			return nil, errMnemonicWord
+		}
+		for b := 0; b < 11; b++ {
+			if idx>>uint(10-b)&1 == 1 {
+				pos := i*11 + b
+				data[pos/8] |= 0x80 >> uint(pos%8)
+			}
+		}
+	}
+	entropy := append([]byte(nil), data[:len(words)/3*4]...)
+	sum := sha256.Sum256(entropy)
+	csBits := uint(len(words) / 3)
+	if mask := byte(0xff) << (8 - csBits); data[len(entropy)]&mask != sum[0]&mask {
+		wipe(entropy)
+		return nil, errChecksum
+	}
+	return entropy, nil
+}
+
+// NewSeed returns the 64-octet BIP39 seed of the mnemonic and the passphrase, which may be empty,
+// after checking the mnemonic. The passphrase must be in the NFKD form of Unicode, as ASCII is,
+// e.g. by golang.org/x/text/unicode/norm.
+func NewSeed(mnemonic, passphrase string) ([]byte, error) {
+	entropy, err := MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	wipe(entropy)
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2SHA512([]byte(normalized), []byte("mnemonic"+passphrase), 2048), nil
+}
+
+// NewMasterFromMnemonic returns the master key of the BIP39 seed of the mnemonic and passphrase,
+// for P-256 or secp256k1, or Ed25519 if curve is nil.
+func NewMasterFromMnemonic(mnemonic, passphrase string, curve elliptic.Curve) (*Key, error) {
+	seed, err := NewSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(seed)
+	if curve == nil {
+		return NewMasterEd25519(seed)
+	}
+	return NewMaster(seed, curve)
+}
+
+// pbkdf2SHA512 is the first block of PBKDF2-HMAC-SHA512 of RFC 8018, of 64 octets.
+func pbkdf2SHA512(password, salt []byte, iter int) []byte {
+	mac := hmac.New(sha512.New, password)
+	mac.Write(salt)
+	mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+	out := append([]byte(nil), u...)
+	for i := 1; i < iter; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range out {
+			out[j] ^= u[j]
+		}
+	}
+	return out
+}
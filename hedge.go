@@ -0,0 +1,34 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import "io"
+
+// WithHedgedNonce makes Prove mix randomness read from rand into the RFC6979 nonce, as the
+// additional data k' of [RFC6979 section 3.6](https://tools.ietf.org/html/rfc6979#section-3.6).
+// The nonce stays derived from the private key and the input, so a broken or repeating source,
+// such as the RNG of a cloned virtual machine, is no worse than the deterministic nonce, while fault
+// attacks relying on repeated nonces are thwarted.
+//
+// beta doesn't change, but each call outputs a different `pi`; all of them verify. Don't use
+// hedged nonces if proofs must be reproducible, e.g. when they serve as unique keys.
+func WithHedgedNonce(rand io.Reader) Option {
+	return func(cfg *Config) {
+		cfg.NonceRand = rand
+	}
+}
+
+// HedgedNonceOctets is like GenerateNonceOctets, and mixes in randomness of the length of q
+// if the config has a nonce source. An error is returned if the source fails.
+func (c *core) HedgedNonceOctets(sk []byte, data []byte) ([]byte, error) {
+	if c.NonceRand == nil {
+		return c.GenerateNonceOctets(sk, data), nil
+	}
+	extra := make([]byte, (c.Q().BitLen()+7)/8)
+	defer wipe(extra)
+	if _, err := io.ReadFull(c.NonceRand, extra); err != nil {
+		return nil, err
+	}
+	return rfc6979nonce(sk, data, extra, c.Q(), c.getHashers()), nil
+}
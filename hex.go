@@ -0,0 +1,96 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"math/big"
+)
+
+// ProveHex is like VRF.Prove, with hex strings for the tools and the JSON-RPC layers that pass them
+// around: skHex is the scalar of the private key over the curve c, of the length of the group order,
+// and alphaHex the input. v must be a VRF object of this package.
+func ProveHex(v VRF, c elliptic.Curve, skHex, alphaHex string) (betaHex, piHex string, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return "", "", errUnsupportedVRF
+	}
+	if err = impl.checkCurve(c); err != nil {
+		return
+	}
+	scalar, err := hex.DecodeString(skHex)
+	if err != nil {
+		return "", "", errInvalidPrivateKey
+	}
+	defer wipe(scalar)
+	alpha, err := hex.DecodeString(alphaHex)
+	if err != nil {
+		return
+	}
+	sk, err := DecodePrivateKey(v, c, scalar)
+	if err != nil {
+		return
+	}
+
+	beta, pi, err := v.Prove(sk, alpha)
+	if err != nil {
+		return
+	}
+	return hex.EncodeToString(beta), hex.EncodeToString(pi), nil
+}
+
+// VerifyHex is like VRF.Verify, with hex strings: pkHex is the public key over the curve c, encoded
+// compressed or uncompressed. v must be a VRF object of this package.
+func VerifyHex(v VRF, c elliptic.Curve, pkHex, alphaHex, piHex string) (betaHex string, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return "", errUnsupportedVRF
+	}
+	if err = impl.checkCurve(c); err != nil {
+		return
+	}
+	b, err := hex.DecodeString(pkHex)
+	if err != nil {
+		return "", errInvalidPublicKey
+	}
+	alpha, err := hex.DecodeString(alphaHex)
+	if err != nil {
+		return
+	}
+	pi, err := hex.DecodeString(piHex)
+	if err != nil {
+		return
+	}
+	pk, err := impl.unmarshalPublicKey(c, b)
+	if err != nil {
+		return
+	}
+	beta, err := v.Verify(pk, alpha, pi)
+	if err != nil {
+		return
+	}
+	return hex.EncodeToString(beta), nil
+}
+
+// unmarshalPublicKey decodes a public key of SEC 1, compressed or uncompressed. The key is
+// validated by Verify.
+func (v *vrf) unmarshalPublicKey(c elliptic.Curve, b []byte) (*ecdsa.PublicKey, error) {
+	byteLen := (c.Params().BitSize + 7) / 8
+	if len(b) == 1+2*byteLen && b[0] == 4 {
+		return &ecdsa.PublicKey{
+			Curve: c,
+			X:     new(big.Int).SetBytes(b[1 : 1+byteLen]),
+			Y:     new(big.Int).SetBytes(b[1+byteLen:]),
+		}, nil
+	}
+	core := v.newCore(c)
+	defer core.release()
+	pt, err := core.Unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: c, X: pt.X, Y: pt.Y}, nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package merkle is the Merkle tree of RFC 6962 section 2.1 with SHA-256, shared by the packages
+// committing to lists: leaves are hashed with the prefix 0x00 and nodes with 0x01, and the left
+// subtree of n leaves holds the largest power of two below n.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+// Root returns the Merkle root of the leaves, nil if there are none.
+func Root(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+	if len(leaves) == 1 {
+		return LeafHash(leaves[0])
+	}
+	k := split(len(leaves))
+	return nodeHash(Root(leaves[:k]), Root(leaves[k:]))
+}
+
+// InclusionProof returns the audit path of the leaf at index i, from the leaf to the root.
+// i must be in [0, len(leaves)).
+func InclusionProof(leaves [][]byte, i int) [][]byte {
+	var path [][]byte
+	for len(leaves) > 1 {
+		k := split(len(leaves))
+		if i < k {
+			path = append(path, Root(leaves[k:]))
+			leaves = leaves[:k]
+		} else {
+			path = append(path, Root(leaves[:k]))
+			leaves, i = leaves[k:], i-k
+		}
+	}
+	// reverse to the order from the leaf
+	for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+		path[l], path[r] = path[r], path[l]
+	}
+	return path
+}
+
+// VerifyInclusion checks that leaf is at index i of the list of n leaves of the root.
+func VerifyInclusion(root, leaf []byte, i, n int, path [][]byte) bool {
+	if i < 0 || i >= n {
+		return false
+	}
+	// the sizes of the subtrees from the root down to the leaf
+	var (
+		sizes []int
+		idx   []int
+	)
+	for size, at := n, i; size > 1; {
+		k := split(size)
+		sizes = append(sizes, size)
+		idx = append(idx, at)
+		if at < k {
+			size = k
+		} else {
+			size, at = size-k, at-k
+		}
+	}
+	if len(path) != len(sizes) {
+		return false
+	}
+	h := LeafHash(leaf)
+	for d, sibling := range path {
+		level := len(sizes) - 1 - d
+		if idx[level] < split(sizes[level]) {
+			h = nodeHash(h, sibling)
+		} else {
+			h = nodeHash(sibling, h)
+		}
+	}
+	return bytes.Equal(h, root)
+}
+
+// split returns the largest power of two below n, for n > 1.
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// LeafHash returns the hash of a leaf.
+func LeafHash(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+func nodeHash(l, r []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(l)
+	h.Write(r)
+	return h.Sum(nil)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package stream is the stream of uniform values derived from a VRF output, shared by the packages
+// sampling from it: the blocks HMAC-SHA256(beta, "go-ecvrf/" || use || ctr), ctr in 8 big-endian
+// octets from 0, read in order. The counter has a fixed length, so the streams of distinct uses
+// are independent. Integers are drawn by rejection, without modulo bias, so draws are identical
+// on all architectures.
+package stream
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"math"
+)
+
+// The uses of beta, one per kind of draw.
+const (
+	UseShuffle      = "shuffle" // the shuffles and samples, and the lottery winners
+	UseWeightedPick = "weighted-pick"
+)
+
+// Stream reads the stream of a use of beta.
+type Stream struct {
+	mac   hash.Hash
+	label []byte
+	ctr   uint64
+	block []byte
+}
+
+// New returns the stream of beta for the use.
+func New(beta []byte, use string) *Stream {
+	return &Stream{mac: hmac.New(sha256.New, beta), label: []byte("go-ecvrf/" + use)}
+}
+
+// read returns the next n octets, n dividing the block size.
+func (s *Stream) read(n int) []byte {
+	if len(s.block) < n {
+		var ctr [8]byte
+		binary.BigEndian.PutUint64(ctr[:], s.ctr)
+		s.ctr++
+		s.mac.Reset()
+		s.mac.Write(s.label)
+		s.mac.Write(ctr[:])
+		s.block = s.mac.Sum(s.block[:0])
+	}
+	b := s.block[:n]
+	s.block = s.block[n:]
+	return b
+}
+
+// Uint64 returns the next 8 octets as a big-endian integer.
+func (s *Stream) Uint64() uint64 {
+	return binary.BigEndian.Uint64(s.read(8))
+}
+
+// Uint128 returns the next 16 octets as a big-endian integer hi * 2^64 + lo.
+func (s *Stream) Uint128() (hi, lo uint64) {
+	b := s.read(16)
+	return binary.BigEndian.Uint64(b), binary.BigEndian.Uint64(b[8:])
+}
+
+// Uniform returns an integer in [0, n), n > 0, rejecting the values of the last incomplete range.
+func (s *Stream) Uniform(n uint64) uint64 {
+	limit := math.MaxUint64 - math.MaxUint64%n
+	for {
+		if v := s.Uint64(); v < limit {
+			return v % n
+		}
+	}
+}
+
+// Shuffle shuffles n elements with swap by a Fisher-Yates shuffle: for i from 0, element i is
+// swapped with a uniform j in [i, n).
+func (s *Stream) Shuffle(n int, swap func(i, j int)) {
+	for i := 0; i < n-1; i++ {
+		swap(i, i+int(s.Uniform(uint64(n-i))))
+	}
+}
+
+// Sample returns the first k elements of the permutation of [0, n) of Shuffle, in order, in O(k)
+// time and memory by a partial Fisher-Yates shuffle remembering only the swapped positions.
+// k must be in [0, n].
+func (s *Stream) Sample(n, k int) []int {
+	var (
+		swapped = make(map[int]int, k)
+		sample  = make([]int, k)
+	)
+	at := func(i int) int {
+		if e, ok := swapped[i]; ok {
+			return e
+		}
+		return i
+	}
+	for i := 0; i < k; i++ {
+		// the last element of Shuffle has no draw, the one of n-1 being always itself
+		j := i
+		if i < n-1 {
+			j += int(s.Uniform(uint64(n - i)))
+		}
+		sample[i] = at(j)
+		swapped[j] = at(i)
+	}
+	return sample
+}
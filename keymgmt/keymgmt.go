@@ -0,0 +1,245 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package keymgmt manages the rotation of VRF keys: each key has a validity window, the active key
+// proves, and proofs are verified against the keys valid at their round or time, so old proofs
+// stay verifiable after a rotation.
+//
+// Windows are positions in a unit chosen by the caller, rounds or Unix seconds, the same for all
+// the keys of a Manager. Overlapping windows let a new key take over before the old one expires.
+package keymgmt
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// The events of the Logger of WithLogger.
+const (
+	EventKeyAdded   = "keymgmt.key_added"
+	EventKeyRemoved = "keymgmt.key_removed"
+	// EventKeyActivated is the first proof of a key, which took over from the previous active key.
+	EventKeyActivated = "keymgmt.key_activated"
+	// EventKeyExpiring is the warning of WithExpiryWarning, logged even without a warning callback.
+	EventKeyExpiring = "keymgmt.key_expiring"
+)
+
+var (
+	errKeyID     = errors.New("keymgmt: duplicate or empty key id")
+	errWindow    = errors.New("keymgmt: invalid validity window")
+	errKeyPair   = errors.New("keymgmt: private key doesn't match the public key")
+	errNoActive  = errors.New("keymgmt: no active key")
+	errNoValid   = errors.New("keymgmt: no key valid at the position")
+	errUnknownID = errors.New("keymgmt: unknown key id")
+)
+
+// Key is a VRF key valid from From, inclusive, until Until, exclusive, or with no expiry if Until is 0.
+type Key struct {
+	ID        string
+	PublicKey *ecdsa.PublicKey
+	// PrivateKey is nil for keys only used to verify, e.g. the keys of a peer.
+	PrivateKey *ecdsa.PrivateKey
+	From       uint64
+	Until      uint64
+}
+
+// ValidAt reports whether at is in the validity window of the key.
+func (k *Key) ValidAt(at uint64) bool {
+	return at >= k.From && (k.Until == 0 || at < k.Until)
+}
+
+// Manager tracks the keys. It's safe for concurrent use.
+type Manager struct {
+	vrf   ecvrf.VRF
+	ahead uint64
+	warn  func(k *Key, at uint64)
+	log   ecvrf.Logger
+
+	mu     sync.Mutex
+	keys   []*Key
+	warned map[string]bool
+	// lastActive is the id of the key of the latest proof
+	lastActive string
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithExpiryWarning makes Prove call warn, once per key, when the active key expires within ahead
+// of the position proven, unless another key with a private key takes over by then.
+func WithExpiryWarning(ahead uint64, warn func(k *Key, at uint64)) Option {
+	return func(m *Manager) {
+		m.ahead = ahead
+		m.warn = warn
+	}
+}
+
+// WithLogger makes the Manager log the changes of its keys to l, by their id and public key.
+func WithLogger(l ecvrf.Logger) Option {
+	return func(m *Manager) {
+		m.log = l
+	}
+}
+
+// New creates a Manager of keys of v.
+func New(v ecvrf.VRF, opts ...Option) *Manager {
+	m := &Manager{vrf: v, warned: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Add adds a key, whose id must be unique.
+func (m *Manager) Add(k Key) error {
+	if k.ID == "" {
+		return errKeyID
+	}
+	if k.Until != 0 && k.Until <= k.From {
+		return errWindow
+	}
+	if err := m.vrf.ValidatePublicKey(k.PublicKey); err != nil {
+		return err
+	}
+	if sk := k.PrivateKey; sk != nil && (sk.X.Cmp(k.PublicKey.X) != 0 || sk.Y.Cmp(k.PublicKey.Y) != 0) {
+		return errKeyPair
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.keys {
+		if e.ID == k.ID {
+			return errKeyID
+		}
+	}
+	m.keys = append(m.keys, &k)
+	// ordered by start, the latest key winning on overlap
+	sort.SliceStable(m.keys, func(i, j int) bool { return m.keys[i].From < m.keys[j].From })
+	m.logKey(EventKeyAdded, &k,
+		ecvrf.LogAttr{Key: "from", Value: k.From},
+		ecvrf.LogAttr{Key: "until", Value: k.Until},
+		ecvrf.LogAttr{Key: "can_prove", Value: k.PrivateKey != nil})
+	return nil
+}
+
+// Remove removes the key of the id, e.g. a compromised key: its proofs no longer verify.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, k := range m.keys {
+		if k.ID == id {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			m.logKey(EventKeyRemoved, k)
+			return nil
+		}
+	}
+	return errUnknownID
+}
+
+// Keys returns a copy of the keys, ordered by start.
+func (m *Manager) Keys() []Key {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]Key, 0, len(m.keys))
+	for _, k := range m.keys {
+		keys = append(keys, *k)
+	}
+	return keys
+}
+
+// Active returns the key proving at the position: among the keys with a private key valid at
+// the position, the one of the latest start.
+func (m *Manager) Active(at uint64) (*Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := m.active(at)
+	if k == nil {
+		return nil, errNoActive
+	}
+	copied := *k
+	return &copied, nil
+}
+
+// Prove proves alpha with the key active at the position, and returns the id of the key with the
+// output and proof.
+func (m *Manager) Prove(at uint64, alpha []byte) (id string, beta, pi []byte, err error) {
+	m.mu.Lock()
+	k := m.active(at)
+	if k == nil {
+		m.mu.Unlock()
+		return "", nil, nil, errNoActive
+	}
+	var expiring *Key
+	if (m.warn != nil || m.log != nil) && !m.warned[k.ID] && k.Until != 0 && at+m.ahead >= k.Until {
+		if next := m.active(k.Until); next == nil || next == k {
+			m.warned[k.ID] = true
+			copied := *k
+			expiring = &copied
+		}
+	}
+	if k.ID != m.lastActive {
+		m.lastActive = k.ID
+		m.logKey(EventKeyActivated, k, ecvrf.LogAttr{Key: "at", Value: at})
+	}
+	sk := k.PrivateKey
+	id = k.ID
+	m.mu.Unlock()
+
+	if expiring != nil {
+		m.logKey(EventKeyExpiring, expiring, ecvrf.LogAttr{Key: "at", Value: at}, ecvrf.LogAttr{Key: "until", Value: expiring.Until})
+		if m.warn != nil {
+			m.warn(expiring, at)
+		}
+	}
+	beta, pi, err = m.vrf.Prove(sk, alpha)
+	return
+}
+
+// Verify checks the proof pi of alpha proven at the position, against the keys valid then from the
+// latest start, and returns the output and the id of the key.
+func (m *Manager) Verify(at uint64, alpha, pi []byte) (id string, beta []byte, err error) {
+	m.mu.Lock()
+	var valid []*Key
+	for i := len(m.keys) - 1; i >= 0; i-- {
+		if m.keys[i].ValidAt(at) {
+			valid = append(valid, m.keys[i])
+		}
+	}
+	m.mu.Unlock()
+
+	if len(valid) == 0 {
+		return "", nil, errNoValid
+	}
+	for _, k := range valid {
+		if beta, err = m.vrf.Verify(k.PublicKey, alpha, pi); err == nil {
+			return k.ID, beta, nil
+		}
+	}
+	return "", nil, err
+}
+
+// logKey logs the event of k, if the Manager has a logger.
+func (m *Manager) logKey(event string, k *Key, attrs ...ecvrf.LogAttr) {
+	if m.log == nil {
+		return
+	}
+	attrs = append([]ecvrf.LogAttr{
+		{Key: "key_id", Value: k.ID},
+		{Key: "public_key", Value: ecvrf.PublicKeyID(k.PublicKey)},
+	}, attrs...)
+	m.log.Log(event, nil, attrs...)
+}
+
+// active returns the key proving at the position, or nil.
+func (m *Manager) active(at uint64) *Key {
+	for i := len(m.keys) - 1; i >= 0; i-- {
+		if k := m.keys[i]; k.PrivateKey != nil && k.ValidAt(at) {
+			return k
+		}
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	errKeySuite    = errors.New("key of another suite")
+	errNoKeyScalar = errors.New("failed to sample private key")
+	errWipedKey    = errors.New("private key wiped")
+)
+
+// PrivateKey is a VRF private key bound to its suite, which proves with it, so it can't be used with
+// the VRF of another suite by mistake, unlike a bare *ecdsa.PrivateKey.
+type PrivateKey struct {
+	vrf *vrf
+	sk  *ecdsa.PrivateKey // nil once wiped
+	pk  *ecdsa.PublicKey
+}
+
+// PublicKey is a VRF public key bound to its suite, which verifies its proofs.
+type PublicKey struct {
+	vrf *vrf
+	pk  *ecdsa.PublicKey
+}
+
+// GenerateKey generates a private key of the suite of v, over the curve c. The scalar is read from
+// rand by rejection sampling, so a deterministic rand gives the same keys, e.g. in simulations,
+// unlike ecdsa.GenerateKey which ignores rand since Go 1.26.
+func GenerateKey(v VRF, c elliptic.Curve, rand io.Reader) (*PrivateKey, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if err := impl.checkCurve(c); err != nil {
+		return nil, err
+	}
+	n := c.Params().N
+	b := make([]byte, (n.BitLen()+7)/8)
+	defer wipe(b)
+	for i := 0; ; i++ {
+		// bounded like the sampling of blinding masks
+		if i == maxMaskAttempts {
+			return nil, errNoKeyScalar
+		}
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		if d := new(big.Int).SetBytes(b); d.Sign() > 0 && d.Cmp(n) < 0 {
+			sk := &ecdsa.PrivateKey{D: d}
+			sk.Curve = c
+			core := impl.newCore(c)
+			pt := core.ScalarBaseMult(b)
+			core.release()
+			sk.X, sk.Y = pt.X, pt.Y
+			return NewPrivateKey(v, sk)
+		}
+	}
+}
+
+// NewPrivateKey binds sk to the suite of v, after checking it. v must be a VRF object of this package.
+func NewPrivateKey(v VRF, sk *ecdsa.PrivateKey) (*PrivateKey, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if err := impl.checkPrivateKey(sk); err != nil {
+		return nil, err
+	}
+	if err := impl.ValidatePublicKey(&sk.PublicKey); err != nil {
+		return nil, err
+	}
+	return &PrivateKey{impl, sk, &sk.PublicKey}, nil
+}
+
+// NewPublicKey binds pk to the suite of v, after checking it. v must be a VRF object of this package.
+func NewPublicKey(v VRF, pk *ecdsa.PublicKey) (*PublicKey, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if err := impl.ValidatePublicKey(pk); err != nil {
+		return nil, err
+	}
+	return &PublicKey{impl, pk}, nil
+}
+
+// Prove is like VRF.Prove, with the key and the VRF of its suite.
+func (sk *PrivateKey) Prove(alpha []byte) (beta, pi []byte, err error) {
+	if sk.sk == nil {
+		return nil, nil, errWipedKey
+	}
+	return sk.vrf.Prove(sk.sk, alpha)
+}
+
+// Wipe zeroes the scalar of sk in place, including in the *ecdsa.PrivateKey it was made from, and
+// drops it, so Prove fails and ECDSA returns nil afterwards. The public key is kept.
+func (sk *PrivateKey) Wipe() {
+	if sk.sk == nil {
+		return
+	}
+	if sk.sk.D != nil {
+		words := sk.sk.D.Bits()
+		for i := range words {
+			words[i] = 0
+		}
+		sk.sk.D.SetInt64(0)
+	}
+	sk.sk = nil
+}
+
+// PublicKey returns the public key of sk.
+func (sk *PrivateKey) PublicKey() *PublicKey {
+	return &PublicKey{sk.vrf, sk.pk}
+}
+
+// Public returns the *PublicKey of sk, like the Public method of the private keys of crypto packages.
+func (sk *PrivateKey) Public() crypto.PublicKey {
+	return sk.PublicKey()
+}
+
+// VRF returns the VRF object of the suite of the key.
+func (sk *PrivateKey) VRF() VRF {
+	return sk.vrf
+}
+
+// ECDSA returns the key as an *ecdsa.PrivateKey, e.g. for the functions of this package, or nil
+// once wiped.
+func (sk *PrivateKey) ECDSA() *ecdsa.PrivateKey {
+	return sk.sk
+}
+
+// Bytes encodes the key, with the identifier of its suite, or returns nil once wiped. The encoding
+// is secret, as the key is.
+func (sk *PrivateKey) Bytes() []byte {
+	if sk.sk == nil {
+		return nil
+	}
+	core := sk.vrf.newCore(sk.sk.Curve)
+	defer core.release()
+	return append(sk.vrf.keyHeader(), core.SecretOctets(sk.sk.D)...)
+}
+
+// Verify is like VRF.Verify, with the key and the VRF of its suite.
+func (pk *PublicKey) Verify(alpha, pi []byte) (beta []byte, err error) {
+	return pk.vrf.Verify(pk.pk, alpha, pi)
+}
+
+// VRF returns the VRF object of the suite of the key.
+func (pk *PublicKey) VRF() VRF {
+	return pk.vrf
+}
+
+// ECDSA returns the key as an *ecdsa.PublicKey.
+func (pk *PublicKey) ECDSA() *ecdsa.PublicKey {
+	return pk.pk
+}
+
+// Equal reports whether x is a *PublicKey of the same point and suite.
+func (pk *PublicKey) Equal(x crypto.PublicKey) bool {
+	other, ok := x.(*PublicKey)
+	if !ok {
+		return false
+	}
+	return pk.vrf.cfg.SuiteString == other.vrf.cfg.SuiteString && pk.vrf.cfg.Spec == other.vrf.cfg.Spec &&
+		pk.pk.Curve == other.pk.Curve && pk.pk.X.Cmp(other.pk.X) == 0 && pk.pk.Y.Cmp(other.pk.Y) == 0
+}
+
+// Bytes encodes the key compressed, with the identifier of its suite.
+func (pk *PublicKey) Bytes() []byte {
+	core := pk.vrf.newCore(pk.pk.Curve)
+	defer core.release()
+	return append(pk.vrf.keyHeader(), core.Marshal(&point{pk.pk.X, pk.pk.Y})...)
+}
+
+// ParsePrivateKey decodes the private key of PrivateKey.Bytes over the curve c, which must be of
+// the suite of v.
+func ParsePrivateKey(v VRF, c elliptic.Curve, b []byte) (*PrivateKey, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if err := impl.checkCurve(c); err != nil {
+		return nil, err
+	}
+	scalar, err := impl.checkKeyHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(scalar) != (c.Params().N.BitLen()+7)/8 {
+		return nil, errInvalidPrivateKey
+	}
+	sk := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(scalar)}
+	sk.Curve = c
+	if sk.D.Sign() == 0 || sk.D.Cmp(c.Params().N) >= 0 {
+		return nil, errInvalidPrivateKey
+	}
+	core := impl.newCore(c)
+	pt := core.ScalarBaseMult(scalar)
+	core.release()
+	sk.X, sk.Y = pt.X, pt.Y
+	key, err := NewPrivateKey(v, sk)
+	if err == nil && impl.cfg.Logger != nil {
+		impl.cfg.Logger.Log(EventKeyLoaded, nil, LogAttr{"suite", impl.Params().Name}, LogAttr{"public_key", PublicKeyID(&sk.PublicKey)})
+	}
+	return key, err
+}
+
+// ParsePublicKey decodes the public key of PublicKey.Bytes over the curve c, which must be of the
+// suite of v.
+func ParsePublicKey(v VRF, c elliptic.Curve, b []byte) (*PublicKey, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if err := impl.checkCurve(c); err != nil {
+		return nil, err
+	}
+	data, err := impl.checkKeyHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	core := impl.newCore(c)
+	pt, err := core.Unmarshal(data)
+	core.release()
+	if err != nil {
+		return nil, err
+	}
+	return NewPublicKey(v, &ecdsa.PublicKey{Curve: c, X: pt.X, Y: pt.Y})
+}
+
+// DecodePrivateKey decodes the bare scalar b of a private key over the curve c, big-endian of the
+// length of the group order, as passed by the bindings and ProveHex. c must be of the suite of v.
+// Unlike ParsePrivateKey, b has no suite header.
+func DecodePrivateKey(v VRF, c elliptic.Curve, b []byte) (*ecdsa.PrivateKey, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if err := impl.checkCurve(c); err != nil {
+		return nil, err
+	}
+	n := c.Params().N
+	if len(b) != (n.BitLen()+7)/8 {
+		return nil, errInvalidPrivateKey
+	}
+	sk := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(b)}
+	sk.Curve = c
+	if sk.D.Sign() == 0 || sk.D.Cmp(n) >= 0 {
+		return nil, errInvalidPrivateKey
+	}
+	core := impl.newCore(c)
+	pt := core.ScalarBaseMult(b)
+	core.release()
+	sk.X, sk.Y = pt.X, pt.Y
+	return sk, nil
+}
+
+// DecodePublicKey decodes the bare public key b over the curve c, a point of SEC 1 compressed or
+// uncompressed, and validates it. c must be of the suite of v. Unlike ParsePublicKey, b has no
+// suite header.
+func DecodePublicKey(v VRF, c elliptic.Curve, b []byte) (*ecdsa.PublicKey, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if err := impl.checkCurve(c); err != nil {
+		return nil, err
+	}
+	pk, err := impl.unmarshalPublicKey(c, b)
+	if err != nil {
+		return nil, err
+	}
+	if err := impl.ValidatePublicKey(pk); err != nil {
+		return nil, err
+	}
+	return pk, nil
+}
+
+// keyHeader identifies the suite of encoded keys: the suite string then the spec version.
+func (v *vrf) keyHeader() []byte {
+	return []byte{v.cfg.SuiteString, byte(v.cfg.Spec)}
+}
+
+// checkKeyHeader returns the key after the header, if it's the one of v.
+func (v *vrf) checkKeyHeader(b []byte) ([]byte, error) {
+	if len(b) < 2 || b[0] != v.cfg.SuiteString || b[1] != byte(v.cfg.Spec) {
+		return nil, errKeySuite
+	}
+	return b[2:], nil
+}
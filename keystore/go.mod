@@ -0,0 +1,12 @@
+module github.com/vechain/go-ecvrf/keystore
+
+go 1.21
+
+require (
+	github.com/vechain/go-ecvrf v0.0.0-20200305101714-4252ed3a3b96
+	golang.org/x/crypto v0.17.0
+)
+
+require golang.org/x/sys v0.15.0 // indirect
+
+replace github.com/vechain/go-ecvrf => ../
@@ -0,0 +1,406 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package keystore stores VRF private keys encrypted on disk, one JSON file per key, in a versioned
+// format similar to the keystores of Ethereum: the scalar is encrypted by AES-256-GCM under a key
+// derived from a passphrase by Argon2id, or scrypt, with the parameters and the salt in the file.
+//
+// The name, the curve and the public key are stored in clear, so keys can be listed without the
+// passphrase, and authenticated as the additional data of the AEAD, so they can't be swapped.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vechain/go-ecvrf"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Version is the version of the key file format.
+const Version = 1
+
+const (
+	kdfArgon2id = "argon2id"
+	kdfScrypt   = "scrypt"
+	cipherName  = "aes-256-gcm"
+	keyLen      = 32
+	saltLen     = 32
+	ext         = ".json"
+)
+
+var (
+	errInvalidKey    = errors.New("keystore: invalid private key")
+	errInvalidName   = errors.New("keystore: invalid key name")
+	errExists        = errors.New("keystore: key already exists")
+	errVersion       = errors.New("keystore: unsupported key file version")
+	errKeyFile       = errors.New("keystore: invalid key file")
+	errCurve         = errors.New("keystore: key of another curve")
+	errDecrypt       = errors.New("keystore: wrong passphrase or corrupted key file")
+	errUnsupported   = errors.New("keystore: unsupported kdf or cipher")
+	errKDFParameters = errors.New("keystore: invalid kdf parameters")
+)
+
+// The events of the Logger of WithLogger, with the name of the key and the ecvrf.PublicKeyID of
+// its public key.
+const (
+	EventKeySaved  = "keystore.key_saved"
+	EventKeyLoaded = "keystore.key_loaded"
+	// EventLoadFailed is a failed Load, e.g. for a wrong passphrase, with the error.
+	EventLoadFailed = "keystore.load_failed"
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// Entry describes a stored key.
+type Entry struct {
+	Name      string
+	Curve     string
+	PublicKey *ecdsa.PublicKey
+}
+
+// Store is a directory of key files.
+type Store struct {
+	dir    string
+	params kdfParams
+	rand   io.Reader
+	log    ecvrf.Logger
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithArgon2id derives the keys of new files by Argon2id of time passes over memory KiB, with
+// threads lanes. The default is the second recommendation of RFC 9106: 3 passes, 64 MiB, 4 lanes.
+func WithArgon2id(time, memory uint32, threads uint8) Option {
+	return func(s *Store) {
+		s.params = kdfParams{KDF: kdfArgon2id, Time: time, Memory: memory, Threads: threads}
+	}
+}
+
+// WithScrypt derives the keys of new files by scrypt of cost n, block size r and parallelism p,
+// e.g. 262144, 8, 1 like geth.
+func WithScrypt(n, r, p int) Option {
+	return func(s *Store) {
+		s.params = kdfParams{KDF: kdfScrypt, N: n, R: r, P: p}
+	}
+}
+
+// WithRand sets the source of the salts and nonces of new files, crypto/rand.Reader by default.
+// A deterministic source makes the files of tests repeatable; it must never be used otherwise.
+func WithRand(r io.Reader) Option {
+	return func(s *Store) {
+		s.rand = r
+	}
+}
+
+// WithLogger makes the Store log the keys saved and loaded, and the failed loads, to l.
+func WithLogger(l ecvrf.Logger) Option {
+	return func(s *Store) {
+		s.log = l
+	}
+}
+
+// NewStore creates the Store of the directory, which is created with Save if it doesn't exist.
+func NewStore(dir string, opts ...Option) *Store {
+	s := &Store{dir: dir, params: kdfParams{KDF: kdfArgon2id, Time: 3, Memory: 64 * 1024, Threads: 4}, rand: rand.Reader}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type keyFile struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Curve     string     `json:"curve"`
+	PublicKey []byte     `json:"publicKey"`
+	Crypto    cryptoJSON `json:"crypto"`
+}
+
+type cryptoJSON struct {
+	KDFParams  kdfParams `json:"kdfparams"`
+	Cipher     string    `json:"cipher"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+type kdfParams struct {
+	KDF  string `json:"kdf"`
+	Salt []byte `json:"salt"`
+	// Argon2id
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+	// scrypt
+	N int `json:"n,omitempty"`
+	R int `json:"r,omitempty"`
+	P int `json:"p,omitempty"`
+}
+
+// Save encrypts sk with the passphrase into the file of the name, which must not exist. Names are
+// made of letters, digits, '.', '_' and '-', and don't start with '.'.
+func (s *Store) Save(name string, sk *ecdsa.PrivateKey, passphrase []byte) error {
+	if !validName.MatchString(name) {
+		return errInvalidName
+	}
+	if sk == nil || sk.D == nil || sk.D.Sign() <= 0 || sk.D.Cmp(sk.Curve.Params().N) >= 0 {
+		return errInvalidKey
+	}
+	f := keyFile{
+		Version:   Version,
+		Name:      name,
+		Curve:     sk.Curve.Params().Name,
+		PublicKey: elliptic.Marshal(sk.Curve, sk.X, sk.Y),
+	}
+	f.Crypto.Cipher = cipherName
+	f.Crypto.KDFParams = s.params
+	f.Crypto.KDFParams.Salt = make([]byte, saltLen)
+	if _, err := io.ReadFull(s.rand, f.Crypto.KDFParams.Salt); err != nil {
+		return err
+	}
+	aead, err := newAEAD(passphrase, &f.Crypto.KDFParams)
+	if err != nil {
+		return err
+	}
+	f.Crypto.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(s.rand, f.Crypto.Nonce); err != nil {
+		return err
+	}
+	scalar := sk.D.FillBytes(make([]byte, scalarLen(sk.Curve)))
+	defer wipe(scalar)
+	f.Crypto.Ciphertext = aead.Seal(nil, f.Crypto.Nonce, scalar, f.additionalData())
+
+	data, err := json.MarshalIndent(&f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	// written aside then linked, so a key file is never partial nor overwritten
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Link(tmp.Name(), s.path(name)); err != nil {
+		if os.IsExist(err) {
+			return errExists
+		}
+		return err
+	}
+	if s.log != nil {
+		s.log.Log(EventKeySaved, nil, ecvrf.LogAttr{Key: "name", Value: name}, ecvrf.LogAttr{Key: "public_key", Value: ecvrf.PublicKeyID(&sk.PublicKey)})
+	}
+	return nil
+}
+
+// Key is a private key decrypted by Load. The file stays encrypted on disk; the decrypted scalar is
+// in the memory of the process until Close.
+type Key struct {
+	*ecdsa.PrivateKey
+}
+
+// Close zeroes the decrypted scalar and drops it. Loading the key again needs the passphrase.
+func (k *Key) Close() error {
+	if k.PrivateKey != nil {
+		wipeInt(k.D)
+		k.PrivateKey = nil
+	}
+	return nil
+}
+
+// Load decrypts the key of the name with the passphrase, and returns the private key of the curve.
+func (s *Store) Load(name string, passphrase []byte, curve elliptic.Curve) (*Key, error) {
+	sk, err := s.load(name, passphrase, curve)
+	if s.log != nil {
+		var pk *ecdsa.PublicKey
+		event := EventLoadFailed
+		if err == nil {
+			pk, event = &sk.PublicKey, EventKeyLoaded
+		}
+		s.log.Log(event, err, ecvrf.LogAttr{Key: "name", Value: name}, ecvrf.LogAttr{Key: "public_key", Value: ecvrf.PublicKeyID(pk)})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Key{sk}, nil
+}
+
+func (s *Store) load(name string, passphrase []byte, curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	if !validName.MatchString(name) {
+		return nil, errInvalidName
+	}
+	f, err := s.read(name)
+	if err != nil {
+		return nil, err
+	}
+	if f.Curve != curve.Params().Name {
+		return nil, errCurve
+	}
+	if f.Crypto.Cipher != cipherName {
+		return nil, errUnsupported
+	}
+	aead, err := newAEAD(passphrase, &f.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Crypto.Nonce) != aead.NonceSize() {
+		return nil, errKeyFile
+	}
+	scalar, err := aead.Open(nil, f.Crypto.Nonce, f.Crypto.Ciphertext, f.additionalData())
+	if err != nil {
+		return nil, errDecrypt
+	}
+	defer wipe(scalar)
+	d := new(big.Int).SetBytes(scalar)
+	if len(scalar) != scalarLen(curve) || d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, errKeyFile
+	}
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = curve
+	sk.X, sk.Y = curve.ScalarBaseMult(scalar)
+	if x, y := elliptic.Unmarshal(curve, f.PublicKey); x == nil || x.Cmp(sk.X) != 0 || y.Cmp(sk.Y) != 0 {
+		return nil, errKeyFile
+	}
+	return sk, nil
+}
+
+// List returns the stored keys, by name, without decrypting them. The public key of an entry is nil
+// for curves other than the ones given.
+func (s *Store) List(curves ...elliptic.Curve) ([]Entry, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []Entry
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ext)
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ext) || !validName.MatchString(name) {
+			continue
+		}
+		f, err := s.read(name)
+		if err != nil {
+			return nil, err
+		}
+		e := Entry{Name: f.Name, Curve: f.Curve}
+		for _, c := range curves {
+			if c.Params().Name != f.Curve {
+				continue
+			}
+			if x, y := elliptic.Unmarshal(c, f.PublicKey); x != nil {
+				e.PublicKey = &ecdsa.PublicKey{Curve: c, X: x, Y: y}
+			}
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+ext)
+}
+
+func (s *Store) read(name string) (*keyFile, error) {
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		return nil, err
+	}
+	var f keyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, errKeyFile
+	}
+	if f.Version != Version {
+		return nil, errVersion
+	}
+	if f.Name != name {
+		return nil, errKeyFile
+	}
+	return &f, nil
+}
+
+// additionalData authenticates the clear fields of the file.
+func (f *keyFile) additionalData() []byte {
+	ad := []byte("go-ecvrf keystore v1\x00")
+	ad = append(ad, f.Name...)
+	ad = append(ad, 0)
+	ad = append(ad, f.Curve...)
+	ad = append(ad, 0)
+	return append(ad, f.PublicKey...)
+}
+
+// newAEAD derives the encryption key from the passphrase.
+func newAEAD(passphrase []byte, p *kdfParams) (cipher.AEAD, error) {
+	if len(p.Salt) == 0 {
+		return nil, errKDFParameters
+	}
+	var key []byte
+	switch p.KDF {
+	case kdfArgon2id:
+		if p.Time == 0 || p.Memory == 0 || p.Threads == 0 {
+			return nil, errKDFParameters
+		}
+		key = argon2.IDKey(passphrase, p.Salt, p.Time, p.Memory, p.Threads, keyLen)
+	case kdfScrypt:
+		var err error
+		if key, err = scrypt.Key(passphrase, p.Salt, p.N, p.R, p.P, keyLen); err != nil {
+			return nil, errKDFParameters
+		}
+	default:
+		return nil, errUnsupported
+	}
+	defer wipe(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func scalarLen(c elliptic.Curve) int {
+	return (c.Params().N.BitLen() + 7) / 8
+}
+
+// wipeInt zeroes the words of the secret k.
+func wipeInt(k *big.Int) {
+	words := k.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	k.SetInt64(0)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
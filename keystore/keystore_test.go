@@ -0,0 +1,162 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestStore(t *testing.T) {
+	for _, opt := range []Option{
+		func(*Store) {},
+		WithArgon2id(1, 8*1024, 1),
+		WithScrypt(1<<12, 8, 1),
+	} {
+		dir := filepath.Join(t.TempDir(), "keys")
+		s := NewStore(dir, opt)
+		if entries, err := s.List(); err != nil || len(entries) != 0 {
+			t.Fatal("keys in a missing directory", entries, err)
+		}
+		sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err := s.Save("validator-1", sk, []byte("secret")); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Save("validator-1", sk, []byte("secret")); err != errExists {
+			t.Fatal("key overwritten", err)
+		}
+		loaded, err := s.Load("validator-1", []byte("secret"), elliptic.P256())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.D.Cmp(sk.D) != 0 || loaded.X.Cmp(sk.X) != 0 {
+			t.Error("loaded another key")
+		}
+		if d := loaded.D; loaded.Close() != nil || loaded.PrivateKey != nil || d.Sign() != 0 || sk.D.Sign() == 0 {
+			t.Error("closed key not wiped")
+		}
+		if _, err := s.Load("validator-1", []byte("wrong"), elliptic.P256()); err != errDecrypt {
+			t.Error("loaded with a wrong passphrase", err)
+		}
+		if _, err := s.Load("validator-1", []byte("secret"), elliptic.P384()); err != errCurve {
+			t.Error("loaded for another curve", err)
+		}
+
+		entries, err := s.List(elliptic.P256())
+		if err != nil || len(entries) != 1 || entries[0].Name != "validator-1" ||
+			entries[0].PublicKey == nil || entries[0].PublicKey.X.Cmp(sk.X) != 0 {
+			t.Fatal("wrong entries", entries, err)
+		}
+		if info, _ := os.Stat(filepath.Join(dir, "validator-1.json")); info.Mode().Perm() != 0600 {
+			t.Error("key file readable by others", info.Mode())
+		}
+	}
+}
+
+func TestTamperedFile(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, WithArgon2id(1, 8*1024, 1))
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err := s.Save("a", sk, []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "a.json")
+	data, _ := os.ReadFile(path)
+
+	for name, tamper := range map[string]func(*keyFile){
+		"public key": func(f *keyFile) { f.PublicKey = elliptic.Marshal(other.Curve, other.X, other.Y) },
+		"version":    func(f *keyFile) { f.Version = 2 },
+		"name":       func(f *keyFile) { f.Name = "b" },
+		"salt":       func(f *keyFile) { f.Crypto.KDFParams.Salt[0] ^= 1 },
+		"kdf":        func(f *keyFile) { f.Crypto.KDFParams.KDF = "pbkdf2" },
+	} {
+		var f keyFile
+		json.Unmarshal(data, &f)
+		tamper(&f)
+		tampered, _ := json.Marshal(&f)
+		os.WriteFile(path, tampered, 0600)
+		if _, err := s.Load("a", []byte("secret"), elliptic.P256()); err == nil {
+			t.Error("loaded with a tampered", name)
+		}
+	}
+
+	for _, name := range []string{"", ".hidden", "../a", "a/b"} {
+		if err := s.Save(name, sk, []byte("secret")); err != errInvalidName {
+			t.Error("saved with the name", name, err)
+		}
+	}
+}
+
+// zeroReader is a deterministic source of salts and nonces.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestWithRand(t *testing.T) {
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var files [2][]byte
+	for i := range files {
+		dir := t.TempDir()
+		s := NewStore(dir, WithScrypt(1<<12, 8, 1), WithRand(zeroReader{}))
+		if err := s.Save("k", sk, []byte("secret")); err != nil {
+			t.Fatal(err)
+		}
+		files[i], _ = os.ReadFile(filepath.Join(dir, "k.json"))
+	}
+	if len(files[0]) == 0 || string(files[0]) != string(files[1]) {
+		t.Fatal("key files differ")
+	}
+}
+
+type eventLog struct {
+	events []string
+	attrs  []ecvrf.LogAttr
+}
+
+func (l *eventLog) Log(event string, err error, attrs ...ecvrf.LogAttr) {
+	l.events = append(l.events, event)
+	l.attrs = append(l.attrs, attrs...)
+}
+
+func TestWithLogger(t *testing.T) {
+	log := &eventLog{}
+	s := NewStore(t.TempDir(), WithScrypt(1<<10, 8, 1), WithLogger(log))
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err := s.Save("validator-1", sk, []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load("validator-1", []byte("secret"), elliptic.P256()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load("validator-1", []byte("wrong"), elliptic.P256()); err == nil {
+		t.Fatal("loaded with a wrong passphrase")
+	}
+	want := []string{EventKeySaved, EventKeyLoaded, EventLoadFailed}
+	if len(log.events) != len(want) {
+		t.Fatalf("events = %v, want %v", log.events, want)
+	}
+	for i := range want {
+		if log.events[i] != want[i] {
+			t.Fatalf("events = %v, want %v", log.events, want)
+		}
+	}
+	for _, a := range log.attrs {
+		if a.Key == "public_key" && a.Value != ecvrf.PublicKeyID(&sk.PublicKey) && a.Value != "" {
+			t.Errorf("public_key = %v", a.Value)
+		}
+	}
+}
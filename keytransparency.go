@@ -0,0 +1,229 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+	"math/big"
+)
+
+// KeyTransparencyVRF is the pre-standard P-256 VRF of [google/keytransparency](https://github.com/google/keytransparency)
+// (core/crypto/vrf/p256), whose proofs are served by existing transparency logs. Proofs are
+// s || t || VRF of 129 octets, where VRF = [k]H1(m) is uncompressed, and the output is SHA256(VRF).
+// It isn't an ECVRF: new deployments should use NewP256Sha256Tai with WithSpecVersion(RFC9381).
+type KeyTransparencyVRF interface {
+	// Prove computes the output `beta` of `alpha` and its proof `pi`, with a random nonce like the original.
+	Prove(sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error)
+
+	// Verify checks the proof `pi` of `alpha` against `pk`, and returns the output `beta`.
+	Verify(pk *ecdsa.PublicKey, alpha, pi []byte) (beta []byte, err error)
+}
+
+type keyTransparencyVRF struct {
+	rand io.Reader
+	p256 *vrf
+}
+
+// NewKeyTransparencyP256 creates the KeyTransparencyVRF object, whose nonces are drawn from rand,
+// which is usually crypto/rand.Reader. rand may be nil if only Verify is used.
+func NewKeyTransparencyP256(rand io.Reader) KeyTransparencyVRF {
+	return &keyTransparencyVRF{rand: rand, p256: NewP256Sha256Tai().(*vrf)}
+}
+
+const (
+	ktScalarLen = 32
+	ktPointLen  = 65
+	ktProofLen  = 2*ktScalarLen + ktPointLen
+)
+
+// Prove implements KeyTransparencyVRF.
+func (v *keyTransparencyVRF) Prove(sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
+	if v.rand == nil {
+		return nil, nil, errNoWitnessRand
+	}
+	if err = v.p256.checkPrivateKey(sk); err != nil {
+		return
+	}
+	if sk.Curve != elliptic.P256() {
+		return nil, nil, errCurveMismatch
+	}
+	core := v.p256.newCore(sk.Curve)
+	defer core.release()
+
+	// r <-- [1, N-1]
+	r, err := v.nonce(core.Q())
+	if err != nil {
+		return
+	}
+	rBytes := r.FillBytes(make([]byte, ktScalarLen))
+	defer wipe(rBytes)
+	skBytes := core.SecretOctets(sk.D)
+	defer wipe(skBytes)
+
+	// H = H1(m), VRF = [k]H
+	H, err := v.h1(core, alpha)
+	if err != nil {
+		return
+	}
+	vrfPoint := core.ScalarMult(H, skBytes)
+	rG := core.ScalarBaseMult(rBytes)
+	rH := core.ScalarMult(H, rBytes)
+
+	// s = H2(G, H, [k]G, VRF, [r]G, [r]H), t = r - s*k mod N
+	s := v.h2(core, H, &point{sk.X, sk.Y}, vrfPoint, rG, rH)
+	t := core.ScalarMulAdd(new(big.Int).Sub(core.Q(), s), skBytes, rBytes)
+
+	pi = make([]byte, 0, ktProofLen)
+	pi = append(pi, fixedOctets(s, ktScalarLen)...)
+	pi = append(pi, fixedOctets(t, ktScalarLen)...)
+	pi = appendUncompressed(pi, vrfPoint)
+	digest := sha256.Sum256(pi[2*ktScalarLen:])
+	return digest[:], pi, nil
+}
+
+// Verify implements KeyTransparencyVRF. Like the original ProofToHash, t and the points are used
+// as they are; unlike it, s is compared with its leading zeros, which the original prover writes.
+func (v *keyTransparencyVRF) Verify(pk *ecdsa.PublicKey, alpha, pi []byte) (beta []byte, err error) {
+	if err = v.p256.checkPublicKey(pk); err != nil {
+		return
+	}
+	if pk.Curve != elliptic.P256() {
+		return nil, errCurveMismatch
+	}
+	if len(pi) != ktProofLen {
+		return nil, ErrInvalidProof
+	}
+	core := v.p256.newCore(pk.Curve)
+	defer core.release()
+	Y := &point{pk.X, pk.Y}
+	if err = core.ValidateKey(Y); err != nil {
+		return
+	}
+
+	// s is an output of H2, so it's in [1, N-1]
+	s := new(big.Int).SetBytes(pi[:ktScalarLen])
+	t := new(big.Int).SetBytes(pi[ktScalarLen : 2*ktScalarLen])
+	if s.Sign() == 0 || s.Cmp(core.Q()) >= 0 {
+		return nil, ErrInvalidProof
+	}
+	vrfPoint, err := unmarshalUncompressed(pk.Curve, pi[2*ktScalarLen:])
+	if err != nil {
+		return
+	}
+
+	// [t]G + [s]([k]G) = [r]G, [t]H + [s]VRF = [r]H
+	H, err := v.h1(core, alpha)
+	if err != nil {
+		return
+	}
+	rG := core.Add(core.ScalarBaseMult(t.Bytes()), core.ScalarMultVartime(Y, s))
+	rH := core.Add(core.ScalarMultVartime(H, t), core.ScalarMultVartime(vrfPoint, s))
+	if rG.X == nil || rH.X == nil || rG.X.Sign() == 0 && rG.Y.Sign() == 0 || rH.X.Sign() == 0 && rH.Y.Sign() == 0 {
+		return nil, ErrInvalidProof
+	}
+	h2 := v.h2(core, H, Y, vrfPoint, rG, rH)
+	if subtle.ConstantTimeCompare(pi[:ktScalarLen], fixedOctets(h2, ktScalarLen)) != 1 {
+		return nil, ErrInvalidProof
+	}
+	digest := sha256.Sum256(pi[2*ktScalarLen:])
+	return digest[:], nil
+}
+
+// nonce draws a scalar of [1, N-1] by the simple discard method.
+func (v *keyTransparencyVRF) nonce(n *big.Int) (*big.Int, error) {
+	buf := make([]byte, ktScalarLen)
+	defer wipe(buf)
+	for {
+		if _, err := io.ReadFull(v.rand, buf); err != nil {
+			return nil, err
+		}
+		r := new(big.Int).SetBytes(buf)
+		if r.Sign() > 0 && r.Cmp(n) < 0 {
+			return r, nil
+		}
+	}
+}
+
+// h1 is H1 of the original, which maps m to the point of x = SHA512(i || m)[:32] and even y,
+// for the first 32-bit big-endian counter i which gives a point, trying at most 100 of them.
+func (v *keyTransparencyVRF) h1(core *core, m []byte) (*point, error) {
+	var (
+		h   = sha512.New()
+		ctr [4]byte
+		enc = make([]byte, 1, 1+sha512.Size)
+	)
+	enc[0] = 2
+	for i := uint32(0); i < 100; i++ {
+		binary.BigEndian.PutUint32(ctr[:], i)
+		h.Reset()
+		h.Write(ctr[:])
+		h.Write(m)
+		b := h.Sum(enc[:1])
+		if H, err := core.Unmarshal(b[:1+ktScalarLen]); err == nil {
+			return H, nil
+		}
+	}
+	return nil, errNoValidPoint
+}
+
+// h2 is H2 of the original applied to the uncompressed points G, H, [k]G, VRF, [r]G, [r]H:
+// the first 32 octets of SHA512(i || data) for the first counter i giving an integer k < N-1, plus 1.
+func (v *keyTransparencyVRF) h2(core *core, points ...*point) *big.Int {
+	var (
+		data   bytes.Buffer
+		params = core.curve.Params()
+		nMinus = new(big.Int).Sub(params.N, big.NewInt(1))
+		h      = sha512.New()
+		ctr    [4]byte
+	)
+	data.Write(appendUncompressed(nil, &point{params.Gx, params.Gy}))
+	for _, pt := range points {
+		data.Write(appendUncompressed(nil, pt))
+	}
+	for i := uint32(0); ; i++ {
+		binary.BigEndian.PutUint32(ctr[:], i)
+		h.Reset()
+		h.Write(ctr[:])
+		h.Write(data.Bytes())
+		k := new(big.Int).SetBytes(h.Sum(nil)[:ktScalarLen])
+		if k.Cmp(nMinus) < 0 {
+			return k.Add(k, big.NewInt(1))
+		}
+	}
+}
+
+// appendUncompressed appends the uncompressed encoding 0x04 || x || y of the P-256 point to dst.
+func appendUncompressed(dst []byte, pt *point) []byte {
+	dst = append(dst, 4)
+	dst = append(dst, fixedOctets(pt.X, ktScalarLen)...)
+	return append(dst, fixedOctets(pt.Y, ktScalarLen)...)
+}
+
+// unmarshalUncompressed decodes the uncompressed encoding of a point of the curve, rejecting
+// non-canonical coordinates and points off the curve.
+func unmarshalUncompressed(c elliptic.Curve, in []byte) (*point, error) {
+	if len(in) != ktPointLen {
+		return nil, ErrInvalidPointLength
+	}
+	if in[0] != 4 {
+		return nil, ErrUnrecognizedPointEncoding
+	}
+	p := c.Params().P
+	x := new(big.Int).SetBytes(in[1 : 1+ktScalarLen])
+	y := new(big.Int).SetBytes(in[1+ktScalarLen:])
+	if x.Cmp(p) >= 0 || y.Cmp(p) >= 0 {
+		return nil, ErrNonCanonicalPoint
+	}
+	if !c.IsOnCurve(x, y) {
+		return nil, ErrNotOnCurve
+	}
+	return &point{x, y}, nil
+}
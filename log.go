@@ -0,0 +1,71 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// The events of the Logger of WithLogger.
+const (
+	// EventVerifyFailed is a rejected proof, with the error.
+	EventVerifyFailed = "ecvrf.verify_failed"
+	// EventKeyLoaded is a private key decoded by ParsePrivateKey.
+	EventKeyLoaded = "ecvrf.key_loaded"
+)
+
+// Logger receives structured events, e.g. for the audit trails of operators; NewSlogLogger adapts
+// a *slog.Logger. Events are named by a constant of the emitting package, such as EventVerifyFailed,
+// and err is nil for events which aren't failures. Attributes never hold secrets: keys are logged
+// by PublicKeyID, inputs by their size and a digest, and private keys never.
+//
+// Log is called synchronously, so it must be fast and safe for concurrent use.
+type Logger interface {
+	Log(event string, err error, attrs ...LogAttr)
+}
+
+// LogAttr is an attribute of an event, of a value of type string, int, uint64 or bool.
+type LogAttr struct {
+	Key   string
+	Value interface{}
+}
+
+// WithLogger makes the VRF object log its events to l.
+func WithLogger(l Logger) Option {
+	return func(cfg *Config) {
+		cfg.Logger = l
+	}
+}
+
+// PublicKeyID identifies pk in logs: the first 8 octets of the SHA-256 of its compressed encoding,
+// in hex. It's empty for a key without coordinates.
+func PublicKeyID(pk *ecdsa.PublicKey) string {
+	if pk == nil || pk.Curve == nil || pk.X == nil || pk.Y == nil {
+		return ""
+	}
+	h := sha256.Sum256(elliptic.MarshalCompressed(pk.Curve, pk.X, pk.Y))
+	return hex.EncodeToString(h[:8])
+}
+
+// logDigest is the digest of an input in logs, as PublicKeyID: inputs may be private, e.g. in key
+// transparency.
+func logDigest(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:8])
+}
+
+// logVerifyFailed logs a rejected proof, if the VRF has a logger.
+func (v *vrf) logVerifyFailed(pk *ecdsa.PublicKey, alpha []byte, err error) {
+	if v.cfg.Logger == nil || err == nil {
+		return
+	}
+	v.cfg.Logger.Log(EventVerifyFailed, err,
+		LogAttr{"suite", v.Params().Name},
+		LogAttr{"public_key", PublicKeyID(pk)},
+		LogAttr{"alpha_size", len(alpha)},
+		LogAttr{"alpha_digest", logDigest(alpha)})
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package lottery draws provably fair winners from a participant list with a VRF key: the list is
+// committed by its Merkle root, the winners are sampled without replacement from the VRF output of
+// the draw name and the root, and the draw is published as a JSON attestation anyone can verify
+// with the public key.
+//
+// The root should be published before the draw, e.g. with the draw name including an external
+// seed such as a future block hash, so the operator can't grind the list for an outcome. A
+// participant knowing only the root checks its ticket with an inclusion proof.
+package lottery
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/internal/stream"
+)
+
+var (
+	errNoParticipants = errors.New("lottery: no participants")
+	errWinners        = errors.New("lottery: invalid number of winners")
+	errIndex          = errors.New("lottery: index out of range")
+	errRoot           = errors.New("lottery: root doesn't match the participants")
+	errBeta           = errors.New("lottery: beta doesn't match the proof")
+	errDrawnWinners   = errors.New("lottery: winners don't match the draw")
+)
+
+// Attestation is the self-contained record of a draw.
+type Attestation struct {
+	Draw         string   `json:"draw"`
+	Participants []string `json:"participants"`
+	Root         []byte   `json:"root"`
+	// Winners are the indices of the winners in Participants, in the order drawn.
+	Winners []int  `json:"winners"`
+	Beta    []byte `json:"beta"`
+	Pi      []byte `json:"pi"`
+}
+
+// WinnerNames returns the participants drawn, in the order drawn.
+func (a *Attestation) WinnerNames() []string {
+	names := make([]string, 0, len(a.Winners))
+	for _, i := range a.Winners {
+		names = append(names, a.Participants[i])
+	}
+	return names
+}
+
+// Alpha returns the input proven for the draw over the list of the root.
+func Alpha(draw string, root []byte) []byte {
+	alpha := append([]byte("go-ecvrf/lottery\x00"), root...)
+	return append(alpha, draw...)
+}
+
+// Draw proves the draw over the participants with sk, and draws k distinct winners.
+func Draw(v ecvrf.VRF, sk *ecdsa.PrivateKey, draw string, participants []string, k int) (*Attestation, error) {
+	if len(participants) == 0 {
+		return nil, errNoParticipants
+	}
+	if k <= 0 || k > len(participants) {
+		return nil, errWinners
+	}
+	root := Root(participants)
+	beta, pi, err := v.Prove(sk, Alpha(draw, root))
+	if err != nil {
+		return nil, err
+	}
+	return &Attestation{
+		Draw:         draw,
+		Participants: append([]string(nil), participants...),
+		Root:         root,
+		Winners:      Winners(beta, len(participants), k),
+		Beta:         beta,
+		Pi:           pi,
+	}, nil
+}
+
+// Verify checks the attestation against the public key of the operator.
+func Verify(v ecvrf.VRF, pk *ecdsa.PublicKey, a *Attestation) error {
+	if len(a.Participants) == 0 {
+		return errNoParticipants
+	}
+	if len(a.Winners) == 0 || len(a.Winners) > len(a.Participants) {
+		return errWinners
+	}
+	if !bytes.Equal(Root(a.Participants), a.Root) {
+		return errRoot
+	}
+	beta, err := v.Verify(pk, Alpha(a.Draw, a.Root), a.Pi)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(beta, a.Beta) {
+		return errBeta
+	}
+	for i, w := range Winners(beta, len(a.Participants), len(a.Winners)) {
+		if a.Winners[i] != w {
+			return errDrawnWinners
+		}
+	}
+	return nil
+}
+
+// Winners samples k distinct indices in [0, n) from beta, in the order drawn: they are the first k
+// elements of ecvrf.Shuffle(beta, n), so their set is ecvrf.SampleK(beta, n, k). k must be in [0, n].
+func Winners(beta []byte, n, k int) []int {
+	return stream.New(beta, stream.UseShuffle).Sample(n, k)
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package lottery
+
+import "github.com/vechain/go-ecvrf/internal/merkle"
+
+// The Merkle tree of the participants is the one of RFC 6962 section 2.1 with SHA-256: leaves are
+// hashed with the prefix 0x00 and nodes with 0x01, and the left subtree of n leaves holds the
+// largest power of two below n.
+
+// Root returns the Merkle root of the participants.
+func Root(participants []string) []byte {
+	return merkle.Root(leaves(participants))
+}
+
+// InclusionProof returns the audit path of the participant at index i, from the leaf to the root.
+func InclusionProof(participants []string, i int) ([][]byte, error) {
+	if i < 0 || i >= len(participants) {
+		return nil, errIndex
+	}
+	return merkle.InclusionProof(leaves(participants), i), nil
+}
+
+// VerifyInclusion checks that participant is at index i of the list of n participants of the root.
+func VerifyInclusion(root []byte, participant string, i, n int, path [][]byte) bool {
+	return merkle.VerifyInclusion(root, []byte(participant), i, n, path)
+}
+
+func leaves(participants []string) [][]byte {
+	l := make([][]byte, len(participants))
+	for i, p := range participants {
+		l[i] = []byte(p)
+	}
+	return l
+}
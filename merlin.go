@@ -0,0 +1,186 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// strobeR is the rate of STROBE-128 over Keccak-f[1600], in octets.
+const strobeR = 166
+
+// flags of STROBE operations.
+const (
+	strobeFlagI = 1 << iota
+	strobeFlagA
+	strobeFlagC
+	strobeFlagT
+	strobeFlagM
+	strobeFlagK
+)
+
+// strobe128 implements the subset of [STROBE](https://strobe.sourceforge.io) v1.0.2 used by Merlin,
+// following the reference strobe.rs of the merlin crate.
+type strobe128 struct {
+	state    [200]byte
+	pos      int
+	posBegin byte
+}
+
+func newStrobe128(protocol []byte) *strobe128 {
+	s := &strobe128{}
+	copy(s.state[:], []byte{1, strobeR + 2, 1, 0, 1, 96})
+	copy(s.state[6:], "STROBEv1.0.2")
+	keccakF1600(&s.state)
+	s.metaAD(protocol, false)
+	return s
+}
+
+func (s *strobe128) metaAD(data []byte, more bool) {
+	s.beginOp(strobeFlagM|strobeFlagA, more)
+	s.absorb(data)
+}
+
+func (s *strobe128) ad(data []byte, more bool) {
+	s.beginOp(strobeFlagA, more)
+	s.absorb(data)
+}
+
+func (s *strobe128) prf(data []byte, more bool) {
+	s.beginOp(strobeFlagI|strobeFlagA|strobeFlagC, more)
+	s.squeeze(data)
+}
+
+func (s *strobe128) key(data []byte, more bool) {
+	s.beginOp(strobeFlagA|strobeFlagC, more)
+	s.overwrite(data)
+}
+
+func (s *strobe128) runF() {
+	s.state[s.pos] ^= s.posBegin
+	s.state[s.pos+1] ^= 0x04
+	s.state[strobeR+1] ^= 0x80
+	keccakF1600(&s.state)
+	s.pos = 0
+	s.posBegin = 0
+}
+
+func (s *strobe128) absorb(data []byte) {
+	for _, b := range data {
+		s.state[s.pos] ^= b
+		s.pos++
+		if s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe128) overwrite(data []byte) {
+	for _, b := range data {
+		s.state[s.pos] = b
+		s.pos++
+		if s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe128) squeeze(data []byte) {
+	for i := range data {
+		data[i] = s.state[s.pos]
+		s.state[s.pos] = 0
+		s.pos++
+		if s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe128) beginOp(flags byte, more bool) {
+	// continuing an operation, whose flags are the same
+	if more {
+		return
+	}
+	oldBegin := s.posBegin
+	s.posBegin = byte(s.pos + 1)
+	s.absorb([]byte{oldBegin, flags})
+
+	// cipher and key operations always start a new block
+	if flags&(strobeFlagC|strobeFlagK) != 0 && s.pos != 0 {
+		s.runF()
+	}
+}
+
+// MerlinTranscript is a [Merlin](https://merlin.cool) transcript, the Fiat-Shamir transform over STROBE
+// used by schnorrkel to bind VRF inputs and proofs, e.g. the BABE slot claims of Substrate.
+type MerlinTranscript struct {
+	strobe strobe128
+}
+
+// NewMerlinTranscript creates a transcript separated by the application label.
+func NewMerlinTranscript(label []byte) *MerlinTranscript {
+	t := &MerlinTranscript{strobe: *newStrobe128([]byte("Merlin v1.0"))}
+	t.AppendMessage([]byte("dom-sep"), label)
+	return t
+}
+
+// Clone returns a copy of the transcript in its current state.
+func (t *MerlinTranscript) Clone() *MerlinTranscript {
+	c := *t
+	return &c
+}
+
+// AppendMessage appends the message to the transcript with the given label.
+func (t *MerlinTranscript) AppendMessage(label, message []byte) {
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(message)))
+	t.strobe.metaAD(label, false)
+	t.strobe.metaAD(n[:], true)
+	t.strobe.ad(message, false)
+}
+
+// AppendUint64 appends the little-endian encoding of x with the given label.
+func (t *MerlinTranscript) AppendUint64(label []byte, x uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], x)
+	t.AppendMessage(label, b[:])
+}
+
+// ChallengeBytes fills dest with challenge octets bound to the whole transcript and the label.
+func (t *MerlinTranscript) ChallengeBytes(label, dest []byte) {
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(dest)))
+	t.strobe.metaAD(label, false)
+	t.strobe.metaAD(n[:], true)
+	t.strobe.prf(dest, false)
+}
+
+// witnessBytes fills dest like the TranscriptRng of merlin: the transcript is forked, rekeyed with
+// the secret witnesses and 32 octets read from rand, and squeezed. The transcript itself is unchanged.
+func (t *MerlinTranscript) witnessBytes(label []byte, dest []byte, witnesses [][]byte, rand io.Reader) error {
+	var (
+		s = t.strobe
+		n [4]byte
+		r [32]byte
+	)
+	defer wipe(s.state[:])
+	for _, w := range witnesses {
+		binary.LittleEndian.PutUint32(n[:], uint32(len(w)))
+		s.metaAD(label, false)
+		s.metaAD(n[:], true)
+		s.key(w, false)
+	}
+	if _, err := io.ReadFull(rand, r[:]); err != nil {
+		return err
+	}
+	s.metaAD([]byte("rng"), false)
+	s.key(r[:], false)
+	wipe(r[:])
+
+	binary.LittleEndian.PutUint32(n[:], uint32(len(dest)))
+	s.metaAD(n[:], false)
+	s.prf(dest, false)
+	return nil
+}
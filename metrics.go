@@ -0,0 +1,43 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import "time"
+
+// Metrics receives the measurements of a VRF object, e.g. for the monitoring of validator nodes;
+// prommetrics is an implementation exporting them to Prometheus. The methods are called
+// synchronously, from the goroutines of the calls measured, so they must be fast and safe for
+// concurrent use.
+type Metrics interface {
+	// ObserveProve is called after each Prove and AppendProve, with its duration and its error.
+	ObserveProve(d time.Duration, err error)
+	// ObserveVerify is called after each verification by Verify, AppendVerify or a Verifier, with
+	// its duration and its error, nil for a valid proof. The self-checks of WithSelfCheck aren't
+	// observed.
+	ObserveVerify(d time.Duration, err error)
+	// ObserveHashToCurve is called with the number of candidates of try_and_increment until the
+	// point, from 1, each time an input is hashed to the curve.
+	ObserveHashToCurve(candidates int)
+}
+
+// WithMetrics makes the VRF object report its measurements to m.
+func WithMetrics(m Metrics) Option {
+	return func(cfg *Config) {
+		cfg.Metrics = m
+	}
+}
+
+// observeProve reports the Prove started at start, if the VRF has metrics.
+func (v *vrf) observeProve(start time.Time, err error) {
+	if v.cfg.Metrics != nil {
+		v.cfg.Metrics.ObserveProve(time.Since(start), err)
+	}
+}
+
+// observeVerify reports the verification started at start, if the VRF has metrics.
+func (v *vrf) observeVerify(start time.Time, err error) {
+	if v.cfg.Metrics != nil {
+		v.cfg.Metrics.ObserveVerify(time.Since(start), err)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package mobile is the API of go-ecvrf for gomobile, so wallets can prove and verify offline on
+// iOS and Android:
+//
+//	gomobile bind -target=android github.com/vechain/go-ecvrf/mobile
+//	gomobile bind -target=ios github.com/vechain/go-ecvrf/mobile
+//
+// Signatures only have strings and byte slices. Suites are named as by the ecvrf command; secret keys
+// are scalars in big-endian octets of the order's length for the Weierstrass suites, and 32-octet seeds
+// for Ed25519; public keys are compressed points, or Ed25519 public keys.
+package mobile
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+// Suites.
+const (
+	P256           = "p256"
+	Secp256k1      = "secp256k1"
+	Ed25519        = "ed25519"
+	Ed25519Draft03 = "ed25519-draft03"
+)
+
+var (
+	errUnknownSuite = errors.New("ecvrf: unknown suite")
+	errInvalidKey   = errors.New("ecvrf: invalid key")
+)
+
+// Proof is the proof of an input and its output.
+type Proof struct {
+	Pi   []byte
+	Beta []byte
+}
+
+// GenerateKey returns a new secret key of the suite.
+func GenerateKey(suite string) ([]byte, error) {
+	if c, _ := weierstrass(suite); c != nil {
+		n := c.Params().N
+		b := make([]byte, (n.BitLen()+7)/8)
+		for {
+			if _, err := io.ReadFull(rand.Reader, b); err != nil {
+				return nil, err
+			}
+			if d := new(big.Int).SetBytes(b); d.Sign() > 0 && d.Cmp(n) < 0 {
+				return b, nil
+			}
+		}
+	}
+	if edwards(suite) != nil {
+		seed := make([]byte, ed25519.SeedSize)
+		if _, err := io.ReadFull(rand.Reader, seed); err != nil {
+			return nil, err
+		}
+		return seed, nil
+	}
+	return nil, errUnknownSuite
+}
+
+// PublicKey returns the public key of the secret key sk of the suite.
+func PublicKey(suite string, sk []byte) ([]byte, error) {
+	if c, v := weierstrass(suite); c != nil {
+		key, err := privateKey(v, c, sk)
+		if err != nil {
+			return nil, err
+		}
+		return elliptic.MarshalCompressed(c, key.X, key.Y), nil
+	}
+	if edwards(suite) != nil {
+		if len(sk) != ed25519.SeedSize {
+			return nil, errInvalidKey
+		}
+		return ed25519.NewKeyFromSeed(sk).Public().(ed25519.PublicKey), nil
+	}
+	return nil, errUnknownSuite
+}
+
+// Prove returns the proof of alpha by the secret key sk of the suite.
+func Prove(suite string, sk, alpha []byte) (*Proof, error) {
+	var (
+		beta, pi []byte
+		err      error
+	)
+	if c, v := weierstrass(suite); c != nil {
+		var key *ecdsa.PrivateKey
+		if key, err = privateKey(v, c, sk); err != nil {
+			return nil, err
+		}
+		beta, pi, err = v.Prove(key, alpha)
+	} else if v := edwards(suite); v != nil {
+		if len(sk) != ed25519.SeedSize {
+			return nil, errInvalidKey
+		}
+		beta, pi, err = v.Prove(ed25519.NewKeyFromSeed(sk), alpha)
+	} else {
+		return nil, errUnknownSuite
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{Pi: pi, Beta: beta}, nil
+}
+
+// Verify verifies the proof pi of alpha by the public key pk of the suite, and returns its output.
+func Verify(suite string, pk, alpha, pi []byte) ([]byte, error) {
+	if c, v := weierstrass(suite); c != nil {
+		key, err := publicKey(v, c, pk)
+		if err != nil {
+			return nil, err
+		}
+		return v.Verify(key, alpha, pi)
+	}
+	if v := edwards(suite); v != nil {
+		if len(pk) != ed25519.PublicKeySize {
+			return nil, errInvalidKey
+		}
+		return v.Verify(ed25519.PublicKey(pk), alpha, pi)
+	}
+	return nil, errUnknownSuite
+}
+
+// ProofToHash returns the output of the proof pi of the suite, without verifying it.
+func ProofToHash(suite string, pi []byte) ([]byte, error) {
+	if c, v := weierstrass(suite); c != nil {
+		return ecvrf.ProofToHash(v, c, pi)
+	}
+	if v := edwards(suite); v != nil {
+		return v.ProofToHash(pi)
+	}
+	return nil, errUnknownSuite
+}
+
+func weierstrass(suite string) (elliptic.Curve, ecvrf.VRF) {
+	switch suite {
+	case P256:
+		return elliptic.P256(), ecvrf.NewP256Sha256Tai()
+	case Secp256k1:
+		return secp256k1.S256(), ecvrf.NewSecp256k1Sha256Tai()
+	}
+	return nil, nil
+}
+
+func edwards(suite string) ecvrf.Ed25519VRF {
+	switch suite {
+	case Ed25519:
+		return ecvrf.NewEd25519Sha512Ell2()
+	case Ed25519Draft03:
+		return ecvrf.NewEd25519Sha512Elligator2()
+	}
+	return nil
+}
+
+// privateKey and publicKey decode the keys of the ECDSA suites, failing with errInvalidKey.
+func privateKey(v ecvrf.VRF, c elliptic.Curve, b []byte) (*ecdsa.PrivateKey, error) {
+	sk, err := ecvrf.DecodePrivateKey(v, c, b)
+	if err != nil {
+		return nil, errInvalidKey
+	}
+	return sk, nil
+}
+
+func publicKey(v ecvrf.VRF, c elliptic.Curve, b []byte) (*ecdsa.PublicKey, error) {
+	pk, err := ecvrf.DecodePublicKey(v, c, b)
+	if err != nil {
+		return nil, errInvalidKey
+	}
+	return pk, nil
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package mobile
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProveVerify(t *testing.T) {
+	alpha := []byte("sample")
+	for _, suite := range []string{P256, Secp256k1, Ed25519, Ed25519Draft03} {
+		sk, err := GenerateKey(suite)
+		if err != nil {
+			t.Fatal(suite, err)
+		}
+		pk, err := PublicKey(suite, sk)
+		if err != nil {
+			t.Fatal(suite, err)
+		}
+		proof, err := Prove(suite, sk, alpha)
+		if err != nil {
+			t.Fatal(suite, err)
+		}
+		if beta, err := Verify(suite, pk, alpha, proof.Pi); err != nil || !bytes.Equal(beta, proof.Beta) {
+			t.Fatal(suite, "valid proof rejected", err)
+		}
+		if beta, err := ProofToHash(suite, proof.Pi); err != nil || !bytes.Equal(beta, proof.Beta) {
+			t.Fatal(suite, "wrong output", err)
+		}
+		if _, err := Verify(suite, pk, alpha[1:], proof.Pi); err == nil {
+			t.Fatal(suite, "invalid proof accepted")
+		}
+		if _, err := Prove(suite, sk[1:], alpha); err != errInvalidKey {
+			t.Fatal(suite, "malformed key accepted", err)
+		}
+	}
+	if _, err := GenerateKey("p384"); err != errUnknownSuite {
+		t.Fatal("unknown suite accepted")
+	}
+}
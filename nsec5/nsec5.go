@@ -0,0 +1,136 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package nsec5 computes the hashed owner names of [NSEC5](https://datatracker.ietf.org/doc/draft-vcelak-nsec5/)
+// from VRF outputs, for authoritative DNS servers which deny existence without enabling zone walking.
+// The NSEC5 hash of a name is the VRF output of its canonical wire form, and the proof lets resolvers
+// check it with the public key of the zone. EC-P256-SHA256 of NSEC5 is ecvrf.NewP256Sha256Tai().
+package nsec5
+
+import (
+	"crypto/ecdsa"
+	"encoding/base32"
+	"errors"
+	"strings"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+const (
+	maxLabelLen = 63
+	maxNameLen  = 255
+)
+
+var (
+	errEmptyLabel   = errors.New("invalid name: empty label")
+	errLabelTooLong = errors.New("invalid name: label longer than 63 octets")
+	errNameTooLong  = errors.New("invalid name: longer than 255 octets")
+	errBadEscape    = errors.New("invalid name: bad escape")
+	errHashTooLong  = errors.New("hash too long for a label")
+
+	base32hex = base32.HexEncoding.WithPadding(base32.NoPadding)
+)
+
+// CanonicalName returns the canonical wire form of the fully qualified domain name in presentation
+// format, following RFC4034 section 6.2: labels prefixed by their lengths, ASCII letters lowercased,
+// and the root label last. The trailing dot is optional, and escapes \X and \DDD are decoded.
+func CanonicalName(name string) ([]byte, error) {
+	if name == "." || name == "" {
+		return []byte{0}, nil
+	}
+	var (
+		out   = make([]byte, 1, len(name)+2)
+		start = 0 // index of the length octet of the current label
+	)
+	endLabel := func() error {
+		n := len(out) - start - 1
+		if n == 0 {
+			return errEmptyLabel
+		}
+		if n > maxLabelLen {
+			return errLabelTooLong
+		}
+		out[start] = byte(n)
+		start = len(out)
+		out = append(out, 0)
+		return nil
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '.':
+			if err := endLabel(); err != nil {
+				return nil, err
+			}
+			continue
+		case c == '\\':
+			if i+1 >= len(name) {
+				return nil, errBadEscape
+			}
+			if d := name[i+1]; d >= '0' && d <= '9' {
+				if i+3 >= len(name) {
+					return nil, errBadEscape
+				}
+				v := 0
+				for _, d := range []byte(name[i+1 : i+4]) {
+					if d < '0' || d > '9' {
+						return nil, errBadEscape
+					}
+					v = v*10 + int(d-'0')
+				}
+				if v > 255 {
+					return nil, errBadEscape
+				}
+				c, i = byte(v), i+3
+			} else {
+				c, i = d, i+1
+			}
+		}
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		out = append(out, c)
+	}
+	// the name may omit the trailing dot
+	if len(out)-start > 1 {
+		if err := endLabel(); err != nil {
+			return nil, err
+		}
+	}
+	if len(out) > maxNameLen {
+		return nil, errNameTooLong
+	}
+	return out, nil
+}
+
+// Prove computes the NSEC5 hash of the name and its NSEC5 proof, with the private key of the zone.
+func Prove(v ecvrf.VRF, sk *ecdsa.PrivateKey, name string) (hash, proof []byte, err error) {
+	alpha, err := CanonicalName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v.Prove(sk, alpha)
+}
+
+// Verify checks the NSEC5 proof of the name against the public key of the zone, and returns the NSEC5 hash.
+func Verify(v ecvrf.VRF, pk *ecdsa.PublicKey, name string, proof []byte) (hash []byte, err error) {
+	alpha, err := CanonicalName(name)
+	if err != nil {
+		return nil, err
+	}
+	return v.Verify(pk, alpha, proof)
+}
+
+// OwnerName returns the hashed owner name of the NSEC5 record of the hash in the zone: the lowercase
+// Base32hex encoding of the hash without padding, prepended as a label to the zone name.
+func OwnerName(hash []byte, zone string) (string, error) {
+	label := strings.ToLower(base32hex.EncodeToString(hash))
+	if len(label) > maxLabelLen {
+		return "", errHashTooLong
+	}
+	zone = strings.TrimSuffix(zone, ".")
+	if zone == "" {
+		return label + ".", nil
+	}
+	return label + "." + zone + ".", nil
+}
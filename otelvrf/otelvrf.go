@@ -0,0 +1,163 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package otelvrf traces VRF operations with OpenTelemetry, so slow proofs can be told apart
+// between curve math, the network and HSM latency: Prove, Verify and VerifyBatch get a span each,
+// and ProveRemote gets a span with a child span per call to the remote key (ECDH, NewNonce,
+// Respond, Close), the time left in the parent being the local math. Keys loaded from a KMS are
+// traced by OpenKey, around the Open of the backend, whose SDK spans then nest under it.
+//
+// It's a separate module, so the library itself keeps no dependencies. Spans carry the suite and
+// the sizes of the inputs, never the inputs or the keys.
+package otelvrf
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/vechain/go-ecvrf"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer of the spans, the import path of the package.
+const TracerName = "github.com/vechain/go-ecvrf/otelvrf"
+
+// Tracer creates the spans of VRF operations.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// Option configures a Tracer.
+type Option func(*Tracer)
+
+// WithTracerProvider sets the provider of the tracer, the global one of otel by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(t *Tracer) {
+		t.tracer = tp.Tracer(TracerName)
+	}
+}
+
+// New creates a Tracer.
+func New(opts ...Option) *Tracer {
+	t := &Tracer{tracer: otel.Tracer(TracerName)}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Prove is v.Prove in the span ecvrf.Prove.
+func (t *Tracer) Prove(ctx context.Context, v ecvrf.VRF, sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
+	_, span := t.start(ctx, "ecvrf.Prove", v, attribute.Int("ecvrf.alpha_size", len(alpha)))
+	defer func() { end(span, err) }()
+	return v.Prove(sk, alpha)
+}
+
+// Verify is v.Verify in the span ecvrf.Verify.
+func (t *Tracer) Verify(ctx context.Context, v ecvrf.VRF, pk *ecdsa.PublicKey, alpha, pi []byte) (beta []byte, err error) {
+	_, span := t.start(ctx, "ecvrf.Verify", v, attribute.Int("ecvrf.alpha_size", len(alpha)))
+	defer func() { end(span, err) }()
+	return v.Verify(pk, alpha, pi)
+}
+
+// VerifyBatch is ecvrf.VerifyBatchContext in the span ecvrf.VerifyBatch, with the number of items
+// and of invalid proofs.
+func (t *Tracer) VerifyBatch(ctx context.Context, v ecvrf.VRF, items []ecvrf.BatchItem, opts ...ecvrf.BatchOption) (results []ecvrf.BatchResult, err error) {
+	ctx, span := t.start(ctx, "ecvrf.VerifyBatch", v, attribute.Int("ecvrf.batch_size", len(items)))
+	defer func() {
+		invalid := 0
+		for _, r := range results {
+			if r.Err != nil {
+				invalid++
+			}
+		}
+		span.SetAttributes(attribute.Int("ecvrf.batch_invalid", invalid))
+		end(span, err)
+	}()
+	return ecvrf.VerifyBatchContext(ctx, v, items, opts...)
+}
+
+// ProveRemote is ecvrf.ProveRemote in the span ecvrf.ProveRemote, the calls to key being its child
+// spans.
+func (t *Tracer) ProveRemote(ctx context.Context, v ecvrf.VRF, key ecvrf.RemoteKey, alpha []byte) (beta, pi []byte, err error) {
+	ctx, span := t.start(ctx, "ecvrf.ProveRemote", v, attribute.Int("ecvrf.alpha_size", len(alpha)))
+	defer func() { end(span, err) }()
+	return ecvrf.ProveRemote(v, t.WrapRemoteKey(ctx, key), alpha)
+}
+
+// WrapRemoteKey returns key with its calls traced as child spans of ctx, e.g. for callers of
+// ecvrf.ProveRemote not going through ProveRemote.
+func (t *Tracer) WrapRemoteKey(ctx context.Context, key ecvrf.RemoteKey) ecvrf.RemoteKey {
+	return &remoteKey{remoteScalar{key, t, ctx, "ecvrf.remote.key"}, key}
+}
+
+// OpenKey traces the loading of a key by open, e.g. calling the Open of a KMS backend and keeping
+// the key it returns, in the span ecvrf.OpenKey with the name of the backend.
+func (t *Tracer) OpenKey(ctx context.Context, backend string, open func(ctx context.Context) error) (err error) {
+	ctx, span := t.tracer.Start(ctx, "ecvrf.OpenKey", trace.WithAttributes(attribute.String("ecvrf.backend", backend)))
+	defer func() { end(span, err) }()
+	return open(ctx)
+}
+
+func (t *Tracer) start(ctx context.Context, name string, v ecvrf.VRF, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	p := v.Params()
+	attrs = append(attrs, attribute.String("ecvrf.suite", p.Name), attribute.Int("ecvrf.suite_string", int(p.SuiteString)))
+	return t.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// end ends the span, with the status of err.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type remoteScalar struct {
+	ecvrf.RemoteScalar
+	t      *Tracer
+	ctx    context.Context
+	prefix string
+}
+
+func (r *remoteScalar) ECDH(px, py *big.Int) (x *big.Int, err error) {
+	_, span := r.t.tracer.Start(r.ctx, r.prefix+".ECDH", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { end(span, err) }()
+	return r.RemoteScalar.ECDH(px, py)
+}
+
+type remoteKey struct {
+	remoteScalar
+	key ecvrf.RemoteKey
+}
+
+func (k *remoteKey) NewNonce() (n ecvrf.RemoteNonce, err error) {
+	_, span := k.t.tracer.Start(k.ctx, "ecvrf.remote.NewNonce", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { end(span, err) }()
+	if n, err = k.key.NewNonce(); err != nil {
+		return nil, err
+	}
+	return &remoteNonce{remoteScalar{n, k.t, k.ctx, "ecvrf.remote.nonce"}, n}, nil
+}
+
+type remoteNonce struct {
+	remoteScalar
+	nonce ecvrf.RemoteNonce
+}
+
+func (n *remoteNonce) Respond(c *big.Int) (s *big.Int, err error) {
+	_, span := n.t.tracer.Start(n.ctx, "ecvrf.remote.Respond", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { end(span, err) }()
+	return n.nonce.Respond(c)
+}
+
+func (n *remoteNonce) Close() (err error) {
+	_, span := n.t.tracer.Start(n.ctx, "ecvrf.remote.Close", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { end(span, err) }()
+	return n.nonce.Close()
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package otelvrf
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// softKey is a RemoteKey computed in memory, as a device would.
+type softKey struct {
+	sk *ecdsa.PrivateKey
+}
+
+func (k *softKey) Public() *ecdsa.PublicKey { return &k.sk.PublicKey }
+
+func (k *softKey) ECDH(px, py *big.Int) (*big.Int, error) {
+	x, _ := k.sk.Curve.ScalarMult(px, py, k.sk.D.Bytes())
+	return x, nil
+}
+
+func (k *softKey) NewNonce() (ecvrf.RemoteNonce, error) {
+	nonce, err := ecdsa.GenerateKey(k.sk.Curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &softNonce{softKey{nonce}, k}, nil
+}
+
+type softNonce struct {
+	softKey
+	key *softKey
+}
+
+func (n *softNonce) Respond(c *big.Int) (*big.Int, error) {
+	s := new(big.Int).Mul(c, n.key.sk.D)
+	s.Add(s, n.sk.D)
+	return s.Mod(s, n.sk.Curve.Params().N), nil
+}
+
+func (n *softNonce) Close() error { return nil }
+
+func TestTracer(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tr := New(WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))))
+	vrf := ecvrf.NewP256Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ctx := context.Background()
+
+	_, pi, err := tr.Prove(ctx, vrf, sk, []byte("slot 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Verify(ctx, vrf, &sk.PublicKey, []byte("slot 2"), pi); err == nil {
+		t.Fatal("Verify() accepted the proof of another input")
+	}
+	results, err := tr.VerifyBatch(ctx, vrf, []ecvrf.BatchItem{
+		{PublicKey: &sk.PublicKey, Alpha: []byte("slot 1"), Pi: pi},
+		{PublicKey: &sk.PublicKey, Alpha: []byte("slot 2"), Pi: pi},
+	})
+	if err == nil || results[0].Err != nil {
+		t.Fatal(err, results)
+	}
+	if _, _, err := tr.ProveRemote(ctx, vrf, &softKey{sk}, []byte("slot 3")); err != nil {
+		t.Fatal(err)
+	}
+	errSealed := errors.New("sealed key corrupted")
+	if err := tr.OpenKey(ctx, "awskms", func(context.Context) error { return errSealed }); err != errSealed {
+		t.Fatal(err)
+	}
+
+	spans := rec.Ended()
+	names := map[string]int{}
+	for _, s := range spans {
+		names[s.Name()]++
+	}
+	for name, want := range map[string]int{
+		"ecvrf.Prove":             1,
+		"ecvrf.Verify":            1,
+		"ecvrf.VerifyBatch":       1,
+		"ecvrf.ProveRemote":       1,
+		"ecvrf.remote.key.ECDH":   2,
+		"ecvrf.remote.NewNonce":   1,
+		"ecvrf.remote.nonce.ECDH": 2,
+		"ecvrf.remote.Respond":    1,
+		"ecvrf.remote.Close":      1,
+		"ecvrf.OpenKey":           1,
+	} {
+		if names[name] != want {
+			t.Errorf("%v spans %s, want %v", names[name], name, want)
+		}
+	}
+
+	var proveRemote sdktrace.ReadOnlySpan
+	for _, s := range spans {
+		switch s.Name() {
+		case "ecvrf.ProveRemote":
+			proveRemote = s
+		case "ecvrf.Verify", "ecvrf.VerifyBatch", "ecvrf.OpenKey":
+			if s.Status().Code != codes.Error {
+				t.Errorf("span %s has status %v, want an error", s.Name(), s.Status())
+			}
+			for _, a := range s.Attributes() {
+				if a.Key == "ecvrf.batch_invalid" && a.Value.AsInt64() != 1 {
+					t.Errorf("batch_invalid = %v, want 1", a.Value.AsInt64())
+				}
+			}
+		}
+	}
+	// the calls to the remote key are children of ProveRemote
+	for _, s := range spans {
+		if len(s.Name()) > 13 && s.Name()[:13] == "ecvrf.remote." && s.Parent().SpanID() != proveRemote.SpanContext().SpanID() {
+			t.Errorf("span %s isn't a child of ecvrf.ProveRemote", s.Name())
+		}
+	}
+}
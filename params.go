@@ -0,0 +1,83 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+// SuiteParams describes the suite of a VRF object, so tools can size buffers and display it
+// without knowing the suite.
+type SuiteParams struct {
+	SuiteString byte
+	// Name is the name of the suite in the specification, e.g. ECVRF-P256-SHA256-TAI.
+	Name string
+	// Spec is the revision of the specification followed. Draft06 stands for the drafts before
+	// RFC9381, e.g. draft-03 for ECVRF-ED25519-SHA512-Elligator2.
+	Spec SpecVersion
+	// Curve is the name of the curve, e.g. P-256.
+	Curve string
+	// Hash is the name of the hash function, e.g. SHA-256.
+	Hash string
+	// PublicKeySize is the size of the encoded public keys, compressed for Weierstrass curves.
+	PublicKeySize int
+	ProofSize     int
+	BetaSize      int
+	// SecurityBits is the security level in bits, the lower of the ones of the group and of beta.
+	SecurityBits int
+}
+
+// knownSuite holds the parameters of a suite string which don't depend on the options.
+type knownSuite struct {
+	name, curve, hash string
+	fieldLen, cLen    int
+	qBits             int
+}
+
+var knownSuites = map[byte]knownSuite{
+	0x01: {"ECVRF-P256-SHA256-TAI", "P-256", "SHA-256", 32, 16, 256},
+	0xfe: {"ECVRF-SECP256K1-SHA256-TAI", "secp256k1", "SHA-256", 32, 16, 256},
+}
+
+// Params returns the parameters of the suite. Suites made by New with other suite strings than
+// those of NewP256Sha256Tai and NewSecp256k1Sha256Tai don't tell their curve, for which the name,
+// the sizes of keys and proofs, and the security level are left empty.
+func (v *vrf) Params() SuiteParams {
+	p := SuiteParams{SuiteString: v.cfg.SuiteString, Spec: v.cfg.Spec}
+	if v.cfg.NewXOF != nil && v.cfg.BetaSize > 0 {
+		p.BetaSize = v.cfg.BetaSize
+	} else {
+		p.BetaSize = v.cfg.NewHasher().Size()
+	}
+	s, ok := knownSuites[v.cfg.SuiteString]
+	if !ok {
+		return p
+	}
+	p.Name, p.Curve, p.Hash = s.name, s.curve, s.hash
+	p.PublicKeySize = 1 + s.fieldLen
+	p.ProofSize = p.PublicKeySize + s.cLen + (s.qBits+7)/8
+	p.SecurityBits = s.qBits / 2
+	if b := p.BetaSize * 8 / 2; b < p.SecurityBits {
+		p.SecurityBits = b
+	}
+	return p
+}
+
+// Params implements Ed25519VRF.
+func (v *ed25519VRF) Params() SuiteParams {
+	p := SuiteParams{
+		SuiteString:   v.suite,
+		Name:          "ECVRF-ED25519-SHA512-Elligator2",
+		Spec:          Draft06,
+		Curve:         "edwards25519",
+		Hash:          "SHA-512",
+		PublicKeySize: 32,
+		ProofSize:     ed25519ProofLen,
+		BetaSize:      64,
+		SecurityBits:  128,
+	}
+	if v.rfc9381 {
+		p.Name, p.Spec = "ECVRF-EDWARDS25519-SHA512-ELL2", RFC9381
+	}
+	if v.batchCompat {
+		p.ProofSize = ed25519BatchProofLen
+	}
+	return p
+}
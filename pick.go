@@ -0,0 +1,128 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/vechain/go-ecvrf/internal/stream"
+)
+
+// WeightedPick picks an index of weights from beta, with a probability of weights[i] / sum(weights)
+// exactly, e.g. for stake-weighted leader election. It returns -1 if all the weights are 0.
+//
+// The uniform values are 128-bit fractions u of an HMAC-SHA256 stream keyed by beta, and the pick
+// is the one of floor(u * sum(weights)), in integer arithmetic, with the fractions of the last
+// incomplete range rejected, as in "Fast Random Integer Generation in an Interval" by Lemire: the
+// picks are unbiased, and identical on all architectures.
+func WeightedPick(beta []byte, weights []uint64) int {
+	picks := WeightedPicks(beta, weights, 1)
+	if len(picks) == 0 {
+		return -1
+	}
+	return picks[0]
+}
+
+// WeightedPicks picks k distinct indices of weights from beta without replacement, in the order
+// picked: each pick is a WeightedPick among the indices not picked yet, from the same stream. The
+// first pick is the one of WeightedPick. Fewer than k indices are returned if fewer weights than k
+// are positive.
+func WeightedPicks(beta []byte, weights []uint64, k int) []int {
+	var (
+		s      = pickStream{stream.New(beta, stream.UseWeightedPick)}
+		left   = append([]uint64(nil), weights...)
+		total  uint128
+		picked []int
+	)
+	for _, w := range left {
+		total = total.add(uint128{0, w})
+	}
+	for len(picked) < k && !total.isZero() {
+		r := s.uniform(total)
+		for i, w := range left {
+			if r.less(uint128{0, w}) {
+				picked = append(picked, i)
+				total = total.sub(uint128{0, w})
+				left[i] = 0
+				break
+			}
+			r = r.sub(uint128{0, w})
+		}
+	}
+	return picked
+}
+
+// uint128 is an unsigned integer of 128 bits, hi * 2^64 + lo.
+type uint128 struct {
+	hi, lo uint64
+}
+
+func (x uint128) add(y uint128) uint128 {
+	lo, carry := bits.Add64(x.lo, y.lo, 0)
+	hi, _ := bits.Add64(x.hi, y.hi, carry)
+	return uint128{hi, lo}
+}
+
+func (x uint128) sub(y uint128) uint128 {
+	lo, borrow := bits.Sub64(x.lo, y.lo, 0)
+	hi, _ := bits.Sub64(x.hi, y.hi, borrow)
+	return uint128{hi, lo}
+}
+
+func (x uint128) less(y uint128) bool {
+	return x.hi < y.hi || (x.hi == y.hi && x.lo < y.lo)
+}
+
+func (x uint128) isZero() bool {
+	return x.hi == 0 && x.lo == 0
+}
+
+// mul returns the product x * y of 256 bits, as its high and low halves.
+func (x uint128) mul(y uint128) (hi, lo uint128) {
+	// the four partial products of 128 bits, added by columns of 64 bits
+	h0, l0 := bits.Mul64(x.lo, y.lo)
+	h1, l1 := bits.Mul64(x.lo, y.hi)
+	h2, l2 := bits.Mul64(x.hi, y.lo)
+	h3, l3 := bits.Mul64(x.hi, y.hi)
+
+	c1, carry := bits.Add64(h0, l1, 0)
+	c2, carry2 := bits.Add64(h1, l3, carry)
+	c3, _ := bits.Add64(h3, 0, carry2)
+	c1, carry = bits.Add64(c1, l2, 0)
+	c2, carry2 = bits.Add64(c2, h2, carry)
+	c3, _ = bits.Add64(c3, 0, carry2)
+	return uint128{c3, c2}, uint128{c1, l0}
+}
+
+func (x uint128) big() *big.Int {
+	b := new(big.Int).SetUint64(x.hi)
+	return b.Lsh(b, 64).Or(b, new(big.Int).SetUint64(x.lo))
+}
+
+// pickStream draws the picks from the 128-bit fractions of the stream.
+type pickStream struct {
+	*stream.Stream
+}
+
+func (s pickStream) next() uint128 {
+	hi, lo := s.Uint128()
+	return uint128{hi, lo}
+}
+
+// uniform returns an integer in [0, n), n > 0, by the high half of u * n, rejecting the u whose low
+// half is below 2^128 mod n.
+func (s pickStream) uniform(n uint128) uint128 {
+	hi, lo := s.next().mul(n)
+	if lo.less(n) {
+		// threshold = (2^128 - n) mod n, computed once per pick
+		t := uint128{}.sub(n).big()
+		t.Mod(t, n.big())
+		threshold := uint128{new(big.Int).Rsh(t, 64).Uint64(), t.Uint64()}
+		for lo.less(threshold) {
+			hi, lo = s.next().mul(n)
+		}
+	}
+	return hi
+}
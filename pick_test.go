@@ -0,0 +1,25 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestUint128Mul(t *testing.T) {
+	values := []uint128{{0, 0}, {0, 1}, {0, math.MaxUint64}, {1, 0}, {math.MaxUint64, math.MaxUint64}, {0x0123456789abcdef, 0xfedcba9876543210}}
+	for _, x := range values {
+		for _, y := range values {
+			hi, lo := x.mul(y)
+			want := new(big.Int).Mul(x.big(), y.big())
+			got := new(big.Int).Lsh(hi.big(), 128)
+			got.Or(got, lo.big())
+			if got.Cmp(want) != 0 {
+				t.Fatalf("%v * %v = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+)
+
+// possessionTag prefixes the attestation strings, so that no application input collides with them
+// unless it starts with the same tag.
+const possessionTag = "ECVRF proof of possession v1\x00"
+
+// PossessionAlpha returns the attestation string proven by a key to show its possession: a fixed tag,
+// the length of the encoded public key in one octet, the public key, then the nonce of the verifier,
+// e.g. a registry, which must be fresh to prevent replays. Public keys are encoded compressed, as by
+// elliptic.MarshalCompressed, or as the 32 octets of an Ed25519 key, proven by an Ed25519VRF.
+func PossessionAlpha(pk, nonce []byte) []byte {
+	alpha := make([]byte, 0, len(possessionTag)+1+len(pk)+len(nonce))
+	alpha = append(alpha, possessionTag...)
+	alpha = append(alpha, byte(len(pk)))
+	alpha = append(alpha, pk...)
+	return append(alpha, nonce...)
+}
+
+// ProvePossession proves the possession of sk to the verifier of the nonce.
+func ProvePossession(v VRF, sk *ecdsa.PrivateKey, nonce []byte) (pi []byte, err error) {
+	if sk == nil {
+		return nil, errInvalidPrivateKey
+	}
+	if err = v.ValidatePublicKey(&sk.PublicKey); err != nil {
+		return
+	}
+	_, pi, err = v.Prove(sk, PossessionAlpha(elliptic.MarshalCompressed(sk.Curve, sk.X, sk.Y), nonce))
+	return
+}
+
+// VerifyPossession checks the proof of possession of pk for the nonce, e.g. before registering pk.
+func VerifyPossession(v VRF, pk *ecdsa.PublicKey, nonce, pi []byte) error {
+	if err := v.ValidatePublicKey(pk); err != nil {
+		return err
+	}
+	_, err := v.Verify(pk, PossessionAlpha(elliptic.MarshalCompressed(pk.Curve, pk.X, pk.Y), nonce), pi)
+	return err
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package prommetrics exports the measurements of ecvrf.WithMetrics to Prometheus:
+//
+//	ecvrf_operations_total{op="prove|verify", result="ok|error"}  counter
+//	ecvrf_operation_duration_seconds{op="prove|verify"}           histogram
+//	ecvrf_hash_to_curve_candidates                                 histogram
+//
+// It's a separate module, so the library itself keeps no dependencies.
+package prommetrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vechain/go-ecvrf"
+)
+
+// Metrics implements ecvrf.Metrics, and is the prometheus.Collector of the measurements.
+type Metrics struct {
+	ops        *prometheus.CounterVec
+	durations  *prometheus.HistogramVec
+	candidates prometheus.Histogram
+}
+
+var _ ecvrf.Metrics = (*Metrics)(nil)
+
+// Option configures Metrics.
+type Option func(*options)
+
+type options struct {
+	namespace   string
+	constLabels prometheus.Labels
+	buckets     []float64
+}
+
+// WithNamespace sets the namespace of the metrics, "ecvrf" by default.
+func WithNamespace(ns string) Option {
+	return func(o *options) {
+		o.namespace = ns
+	}
+}
+
+// WithConstLabels adds labels to all the metrics, e.g. the suite when a node runs several VRFs.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(o *options) {
+		o.constLabels = labels
+	}
+}
+
+// WithDurationBuckets sets the buckets of the durations, in seconds, from 10µs to about 80ms by default.
+func WithDurationBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// New creates the metrics, to register, e.g. by prometheus.MustRegister.
+func New(opts ...Option) *Metrics {
+	o := options{namespace: "ecvrf", buckets: prometheus.ExponentialBuckets(10e-6, 2, 14)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Metrics{
+		ops: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   o.namespace,
+			Name:        "operations_total",
+			Help:        "Number of VRF proofs made and verified, by result.",
+			ConstLabels: o.constLabels,
+		}, []string{"op", "result"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   o.namespace,
+			Name:        "operation_duration_seconds",
+			Help:        "Duration of VRF proofs and verifications.",
+			ConstLabels: o.constLabels,
+			Buckets:     o.buckets,
+		}, []string{"op"}),
+		candidates: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   o.namespace,
+			Name:        "hash_to_curve_candidates",
+			Help:        "Number of candidates of try_and_increment until the point.",
+			ConstLabels: o.constLabels,
+			Buckets:     prometheus.LinearBuckets(1, 1, 16),
+		}),
+	}
+}
+
+// ObserveProve implements ecvrf.Metrics.
+func (m *Metrics) ObserveProve(d time.Duration, err error) {
+	m.observe("prove", d, err)
+}
+
+// ObserveVerify implements ecvrf.Metrics.
+func (m *Metrics) ObserveVerify(d time.Duration, err error) {
+	m.observe("verify", d, err)
+}
+
+// ObserveHashToCurve implements ecvrf.Metrics.
+func (m *Metrics) ObserveHashToCurve(candidates int) {
+	m.candidates.Observe(float64(candidates))
+}
+
+func (m *Metrics) observe(op string, d time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	m.ops.WithLabelValues(op, result).Inc()
+	m.durations.WithLabelValues(op).Observe(d.Seconds())
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.ops.Describe(ch)
+	m.durations.Describe(ch)
+	m.candidates.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.ops.Collect(ch)
+	m.durations.Collect(ch)
+	m.candidates.Collect(ch)
+}
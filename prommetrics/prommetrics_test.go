@@ -0,0 +1,61 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package prommetrics
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New(WithConstLabels(prometheus.Labels{"suite": "p256"}))
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(m)
+
+	vrf := ecvrf.NewP256Sha256Tai(ecvrf.WithMetrics(m))
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, pi, err := vrf.Prove(sk, []byte("round 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vrf.NewVerifier(&sk.PublicKey).Verify([]byte("round 1"), pi); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vrf.Verify(&sk.PublicKey, []byte("round 2"), pi); err == nil {
+		t.Fatal("Verify() accepted the proof of another input")
+	}
+
+	for _, c := range []struct {
+		op, result string
+		want       float64
+	}{
+		{"prove", "ok", 1},
+		{"verify", "ok", 1},
+		{"verify", "error", 1},
+	} {
+		if got := testutil.ToFloat64(m.ops.WithLabelValues(c.op, c.result)); got != c.want {
+			t.Errorf("%s %s = %v, want %v", c.op, c.result, got, c.want)
+		}
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// one hash_to_curve per call
+	var hashed uint64
+	for _, f := range families {
+		if f.GetName() == "ecvrf_hash_to_curve_candidates" {
+			hashed = f.GetMetric()[0].GetHistogram().GetSampleCount()
+		}
+	}
+	if hashed != 3 {
+		t.Errorf("%v candidates observed, want 3", hashed)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package randtest runs statistical tests of NIST SP 800-22 over the outputs of a VRF, to show that
+// the betas of a suite behave as uniform bits, e.g. for an audit. Sequences are concatenations of
+// betas of distinct inputs; each test gives a P-value per sequence, and the sequences of a test are
+// assessed together as in section 4.2: by the proportion of sequences passing, and the uniformity
+// of their P-values.
+//
+// The tests are the frequency, block frequency, runs, longest run of ones, cumulative sums and
+// approximate entropy tests. Passing them shows no bias the tests can detect, not that the outputs
+// are unpredictable, which is up to the security of the suite.
+package randtest
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// DefaultSignificance is the significance level of the tests of a sequence, if Config.Significance is 0.
+const DefaultSignificance = 0.01
+
+// UniformityThreshold is the P-value under which the P-values of a test aren't uniform, from section 4.2.2.
+const UniformityThreshold = 0.0001
+
+var errNoSequences = errors.New("randtest: no sequences")
+
+// Test is a statistical test of a sequence of bits, each 0 or 1, returning its P-value.
+type Test struct {
+	Name string
+	Run  func(bits []byte) float64
+}
+
+// Tests returns the tests of the package, with the parameters recommended for sequences of n bits.
+func Tests(n int) []Test {
+	m := 2
+	for m < 10 && 1<<uint(m+1+5) < n {
+		m++
+	}
+	return []Test{
+		{"Frequency", Frequency},
+		{"BlockFrequency", func(bits []byte) float64 { return BlockFrequency(bits, 128) }},
+		{"Runs", Runs},
+		{"LongestRunOfOnes", LongestRunOfOnes},
+		{"CumulativeSums", CumulativeSums},
+		{fmt.Sprintf("ApproximateEntropy(m=%d)", m), func(bits []byte) float64 { return ApproximateEntropy(bits, m) }},
+	}
+}
+
+// Alpha returns the input of the beta j of the sequence i: "randtest" || i || j, in 8 big-endian
+// octets each.
+func Alpha(i, j int) []byte {
+	alpha := make([]byte, 8+16)
+	copy(alpha, "randtest")
+	binary.BigEndian.PutUint64(alpha[8:], uint64(i))
+	binary.BigEndian.PutUint64(alpha[16:], uint64(j))
+	return alpha
+}
+
+// Collect returns n sequences of at least bits bits, each the concatenation of the betas of
+// Alpha(i, 0), Alpha(i, 1)... with sk, unpacked to one bit per octet, most significant first.
+func Collect(v ecvrf.VRF, sk *ecdsa.PrivateKey, n, bits int) ([][]byte, error) {
+	seqs := make([][]byte, n)
+	for i := range seqs {
+		seq := make([]byte, 0, bits+512)
+		for j := 0; len(seq) < bits; j++ {
+			beta, _, err := v.Prove(sk, Alpha(i, j))
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, Unpack(beta)...)
+		}
+		seqs[i] = seq
+	}
+	return seqs, nil
+}
+
+// Unpack returns the bits of b, one per octet, most significant first.
+func Unpack(b []byte) []byte {
+	bits := make([]byte, 0, 8*len(b))
+	for _, o := range b {
+		for i := 7; i >= 0; i-- {
+			bits = append(bits, o>>uint(i)&1)
+		}
+	}
+	return bits
+}
+
+// Config sets the assessment of the sequences.
+type Config struct {
+	// Tests are the tests run, Tests of the length of the first sequence if nil.
+	Tests []Test
+	// Significance is the level under which the P-value of a sequence fails, DefaultSignificance if 0.
+	Significance float64
+}
+
+// Result is the assessment of the sequences by a test.
+type Result struct {
+	Test      string
+	Sequences int
+	// Passed is the number of sequences of a P-value of at least the significance level.
+	Passed int
+	// MinPassed is the lowest number of sequences passing of the confidence interval of section
+	// 4.2.1, of three standard deviations.
+	MinPassed int
+	// Uniformity is the P-value of the chi-square test of the P-values over 10 bins of section 4.2.2.
+	Uniformity float64
+	PValues    []float64
+}
+
+// OK reports whether the sequences pass the test: enough of them pass, and their P-values are uniform.
+func (r *Result) OK() bool {
+	return r.Passed >= r.MinPassed && r.Uniformity >= UniformityThreshold
+}
+
+func (r *Result) String() string {
+	status := "PASS"
+	if !r.OK() {
+		status = "FAIL"
+	}
+	return fmt.Sprintf("%-28s %d/%d passed (min %d), uniformity %.6f %s", r.Test, r.Passed, r.Sequences, r.MinPassed, r.Uniformity, status)
+}
+
+// Assess runs the tests over the sequences. The uniformity of the P-values needs at least 55
+// sequences to be meaningful.
+func Assess(seqs [][]byte, cfg Config) ([]*Result, error) {
+	if len(seqs) == 0 {
+		return nil, errNoSequences
+	}
+	tests := cfg.Tests
+	if tests == nil {
+		tests = Tests(len(seqs[0]))
+	}
+	alpha := cfg.Significance
+	if alpha == 0 {
+		alpha = DefaultSignificance
+	}
+	var (
+		s       = float64(len(seqs))
+		p       = 1 - alpha
+		results = make([]*Result, len(tests))
+	)
+	for i, t := range tests {
+		r := &Result{
+			Test:      t.Name,
+			Sequences: len(seqs),
+			MinPassed: int(math.Ceil(s * (p - 3*math.Sqrt(p*alpha/s)))),
+			PValues:   make([]float64, len(seqs)),
+		}
+		var bins [10]int
+		for j, seq := range seqs {
+			pv := t.Run(seq)
+			r.PValues[j] = pv
+			if pv >= alpha {
+				r.Passed++
+			}
+			bin := int(pv * 10)
+			if bin > 9 {
+				bin = 9
+			}
+			bins[bin]++
+		}
+		var chi2 float64
+		for _, f := range bins {
+			d := float64(f) - s/10
+			chi2 += d * d / (s / 10)
+		}
+		r.Uniformity = igamc(4.5, chi2/2)
+		results[i] = r
+	}
+	return results, nil
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package randtest
+
+import "math"
+
+// The tests follow the definitions of NIST SP 800-22 rev. 1a, section 2, and return the P-value
+// of a sequence of bits, each 0 or 1. A sequence too short for a test gets a P-value of 0.
+
+// Frequency is the frequency (monobit) test of section 2.1.
+func Frequency(bits []byte) float64 {
+	n := len(bits)
+	if n == 0 {
+		return 0
+	}
+	s := 0
+	for _, b := range bits {
+		s += 2*int(b) - 1
+	}
+	return math.Erfc(math.Abs(float64(s)) / math.Sqrt(float64(n)) / math.Sqrt2)
+}
+
+// BlockFrequency is the frequency test within blocks of m bits of section 2.2.
+func BlockFrequency(bits []byte, m int) float64 {
+	if m <= 0 || len(bits) < m {
+		return 0
+	}
+	blocks := len(bits) / m
+	var chi2 float64
+	for i := 0; i < blocks; i++ {
+		ones := 0
+		for _, b := range bits[i*m : (i+1)*m] {
+			ones += int(b)
+		}
+		d := float64(ones)/float64(m) - 0.5
+		chi2 += d * d
+	}
+	chi2 *= 4 * float64(m)
+	return igamc(float64(blocks)/2, chi2/2)
+}
+
+// Runs is the runs test of section 2.3.
+func Runs(bits []byte) float64 {
+	n := len(bits)
+	if n < 2 {
+		return 0
+	}
+	ones := 0
+	for _, b := range bits {
+		ones += int(b)
+	}
+	pi := float64(ones) / float64(n)
+	// the frequency prerequisite of the test
+	if math.Abs(pi-0.5) >= 2/math.Sqrt(float64(n)) {
+		return 0
+	}
+	v := 1
+	for i := 1; i < n; i++ {
+		if bits[i] != bits[i-1] {
+			v++
+		}
+	}
+	q := pi * (1 - pi)
+	return math.Erfc(math.Abs(float64(v)-2*float64(n)*q) / (2 * math.Sqrt(2*float64(n)) * q))
+}
+
+// longestRunParams are the block size, the bounds of the classes of the longest runs and their
+// probabilities of section 2.4, as precise as in the reference implementation, for the sequences
+// of at least minBits bits.
+var longestRunParams = []struct {
+	minBits, m int
+	low, high  int
+	pi         []float64
+}{
+	{750000, 10000, 10, 16, []float64{0.0882, 0.2092, 0.2483, 0.1933, 0.1208, 0.0675, 0.0727}},
+	{6272, 128, 4, 9, []float64{0.1174035788, 0.242955959, 0.249363483, 0.17517706, 0.102701071, 0.112398847}},
+	{128, 8, 1, 4, []float64{0.21484375, 0.3671875, 0.23046875, 0.1875}},
+}
+
+// LongestRunOfOnes is the test for the longest run of ones in a block of section 2.4, with the
+// block size of the length of the sequence, of at least 128 bits.
+func LongestRunOfOnes(bits []byte) float64 {
+	for _, p := range longestRunParams {
+		if len(bits) < p.minBits {
+			continue
+		}
+		var (
+			blocks = len(bits) / p.m
+			counts = make([]int, len(p.pi))
+		)
+		for i := 0; i < blocks; i++ {
+			longest, run := 0, 0
+			for _, b := range bits[i*p.m : (i+1)*p.m] {
+				if b == 1 {
+					if run++; run > longest {
+						longest = run
+					}
+				} else {
+					run = 0
+				}
+			}
+			if longest < p.low {
+				longest = p.low
+			} else if longest > p.high {
+				longest = p.high
+			}
+			counts[longest-p.low]++
+		}
+		var chi2 float64
+		for i, pi := range p.pi {
+			e := float64(blocks) * pi
+			d := float64(counts[i]) - e
+			chi2 += d * d / e
+		}
+		return igamc(float64(len(p.pi)-1)/2, chi2/2)
+	}
+	return 0
+}
+
+// CumulativeSums is the cumulative sums test of section 2.13, in the forward mode.
+func CumulativeSums(bits []byte) float64 {
+	n := len(bits)
+	if n == 0 {
+		return 0
+	}
+	s, z := 0, 0
+	for _, b := range bits {
+		s += 2*int(b) - 1
+		if s > z {
+			z = s
+		} else if -s > z {
+			z = -s
+		}
+	}
+	if z == 0 {
+		return 0
+	}
+	var (
+		fn  = float64(n)
+		fz  = float64(z)
+		sq  = math.Sqrt(fn)
+		sum = 1.0
+	)
+	for k := math.Floor((-fn/fz + 1) / 4); k <= math.Floor((fn/fz-1)/4); k++ {
+		sum -= normal((4*k+1)*fz/sq) - normal((4*k-1)*fz/sq)
+	}
+	for k := math.Floor((-fn/fz - 3) / 4); k <= math.Floor((fn/fz-1)/4); k++ {
+		sum += normal((4*k+3)*fz/sq) - normal((4*k+1)*fz/sq)
+	}
+	return sum
+}
+
+// ApproximateEntropy is the approximate entropy test of section 2.12, with blocks of m bits, of
+// 1 to 20. The section recommends m < log2(len(bits)) - 5.
+func ApproximateEntropy(bits []byte, m int) float64 {
+	n := len(bits)
+	if m <= 0 || m > 20 || n < m {
+		return 0
+	}
+	apEn := phi(bits, m) - phi(bits, m+1)
+	chi2 := 2 * float64(n) * (math.Ln2 - apEn)
+	return igamc(math.Ldexp(1, m-1), chi2/2)
+}
+
+// phi is the sum of C log C over the frequencies C of the patterns of m bits, the sequence wrapping.
+func phi(bits []byte, m int) float64 {
+	var (
+		n      = len(bits)
+		counts = make([]int, 1<<uint(m))
+		mask   = 1<<uint(m) - 1
+		w      = 0
+	)
+	for i := 0; i < m-1; i++ {
+		w = w<<1 | int(bits[i])
+	}
+	for i := 0; i < n; i++ {
+		w = (w<<1 | int(bits[(i+m-1)%n])) & mask
+		counts[w]++
+	}
+	var sum float64
+	for _, c := range counts {
+		if c > 0 {
+			f := float64(c) / float64(n)
+			sum += f * math.Log(f)
+		}
+	}
+	return sum
+}
+
+// normal is the cumulative distribution function of the standard normal distribution.
+func normal(x float64) float64 {
+	return math.Erfc(-x/math.Sqrt2) / 2
+}
+
+// igamc is the regularized upper incomplete gamma function Q(a, x), by its series below a+1 and its
+// continued fraction above, as in Numerical Recipes, section 6.2.
+func igamc(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	lg, _ := math.Lgamma(a)
+	front := math.Exp(-x + a*math.Log(x) - lg)
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1.0; n < 1000; n++ {
+			term *= x / (a + n)
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-15 {
+				break
+			}
+		}
+		return 1 - sum*front
+	}
+	// modified Lentz's method
+	const tiny = 1e-300
+	var (
+		b = x + 1 - a
+		c = 1 / tiny
+		d = 1 / b
+		h = d
+	)
+	for i := 1.0; i < 1000; i++ {
+		an := -i * (i - a)
+		b += 2
+		if d = an*d + b; math.Abs(d) < tiny {
+			d = tiny
+		}
+		if c = b + an/c; math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return front * h
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package ratelimit guards the proofs of a VRF, so that a compromised caller of an RPC prover, such as
+// vrfhttp or the service package, can't grind a key by requesting unlimited proofs: each key proves
+// at most a number of alphas per second, and optionally each alpha at most once per window, e.g.
+// to catch replayed slot requests, which would give out several proofs of a slot with hedged or
+// remote nonces.
+//
+// A Limiter is an ecvrf.VRF, proving with the one it wraps, so it goes wherever a VRF is taken.
+// Keys are told apart by ecvrf.PublicKeyID. Refused proofs return a *RateLimitError or
+// a *ReplayError. Verifications aren't limited.
+package ratelimit
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// RateLimitError is the error of a proof refused for the rate of its key.
+type RateLimitError struct {
+	// Key is the ecvrf.PublicKeyID of the key.
+	Key string
+	// RetryAfter is the wait until the key may prove again.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("ratelimit: key %s over its rate, retry after %v", e.Key, e.RetryAfter)
+}
+
+// ReplayError is the error of a proof of an alpha the key already proved within the window.
+type ReplayError struct {
+	// Key is the ecvrf.PublicKeyID of the key.
+	Key string
+	// ProvenAt is the time of the earlier proof.
+	ProvenAt time.Time
+}
+
+func (e *ReplayError) Error() string {
+	return fmt.Sprintf("ratelimit: alpha already proven by key %s at %v", e.Key, e.ProvenAt.Format(time.RFC3339))
+}
+
+// Limiter is a VRF whose Prove is limited per key. It's safe for concurrent use.
+type Limiter struct {
+	ecvrf.VRF
+	rate   float64
+	burst  float64
+	window time.Duration
+	now    func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]*keyState
+}
+
+var _ ecvrf.VRF = (*Limiter)(nil)
+
+// keyState is the token bucket of a key and its alphas proven within the window, in order.
+type keyState struct {
+	tokens float64
+	last   time.Time
+	proven map[[sha256.Size]byte]time.Time
+	order  []provenAlpha
+}
+
+type provenAlpha struct {
+	digest [sha256.Size]byte
+	at     time.Time
+}
+
+// Option configures a Limiter.
+type Option func(*Limiter)
+
+// WithRate limits each key to qps proofs per second on average, with bursts of up to burst proofs.
+// Proofs are unlimited by rate if qps is 0.
+func WithRate(qps float64, burst int) Option {
+	return func(l *Limiter) {
+		l.rate = qps
+		if l.burst = float64(burst); l.burst < 1 {
+			l.burst = 1
+		}
+	}
+}
+
+// WithUniqueAlpha refuses the proofs of an alpha already proven by the key less than window ago.
+// The alphas are remembered by digest, at most as many as the rate allows within the window.
+func WithUniqueAlpha(window time.Duration) Option {
+	return func(l *Limiter) {
+		l.window = window
+	}
+}
+
+// WithClock sets the clock of the limits, time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+// New creates a Limiter proving with v.
+func New(v ecvrf.VRF, opts ...Option) *Limiter {
+	l := &Limiter{VRF: v, now: time.Now, keys: make(map[string]*keyState)}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Prove implements ecvrf.VRF, proving with the wrapped VRF within the limits of the key.
+func (l *Limiter) Prove(sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
+	if sk == nil {
+		return l.VRF.Prove(sk, alpha)
+	}
+	id := ecvrf.PublicKeyID(&sk.PublicKey)
+	digest := sha256.Sum256(alpha)
+	if err := l.reserve(id, digest); err != nil {
+		return nil, nil, err
+	}
+	if beta, pi, err = l.VRF.Prove(sk, alpha); err != nil {
+		// a failed proof gave nothing away: the alpha may be proven again, the token is spent
+		l.release(id, digest)
+	}
+	return
+}
+
+// reserve takes a token of the key and records the alpha, or returns the error of the limit hit.
+func (l *Limiter) reserve(id string, digest [sha256.Size]byte) error {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	k := l.keys[id]
+	if k == nil {
+		k = &keyState{tokens: l.burst, last: now, proven: make(map[[sha256.Size]byte]time.Time)}
+		l.keys[id] = k
+	}
+
+	if l.window > 0 {
+		// forget the alphas out of the window, oldest first
+		i := 0
+		for ; i < len(k.order) && now.Sub(k.order[i].at) >= l.window; i++ {
+			if k.proven[k.order[i].digest] == k.order[i].at {
+				delete(k.proven, k.order[i].digest)
+			}
+		}
+		k.order = k.order[i:]
+		if at, ok := k.proven[digest]; ok {
+			return &ReplayError{Key: id, ProvenAt: at}
+		}
+	}
+
+	if l.rate > 0 {
+		if elapsed := now.Sub(k.last).Seconds(); elapsed > 0 {
+			if k.tokens += elapsed * l.rate; k.tokens > l.burst {
+				k.tokens = l.burst
+			}
+		}
+		k.last = now
+		if k.tokens < 1 {
+			wait := time.Duration((1 - k.tokens) / l.rate * float64(time.Second))
+			return &RateLimitError{Key: id, RetryAfter: wait}
+		}
+		k.tokens--
+	}
+
+	if l.window > 0 {
+		k.proven[digest] = now
+		k.order = append(k.order, provenAlpha{digest, now})
+	}
+	return nil
+}
+
+// release forgets the alpha of a failed proof.
+func (l *Limiter) release(id string, digest [sha256.Size]byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if k := l.keys[id]; k != nil {
+		delete(k.proven, digest)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var errRemoteKey = errors.New("inconsistent results of the remote key")
+
+// RemoteScalar is a secret scalar x held by a device or service, such as an HSM, which only
+// gives the x-coordinate of x*P for points P, like the ECDH mechanisms of PKCS#11 and KMSes.
+type RemoteScalar interface {
+	// Public returns x*B.
+	Public() *ecdsa.PublicKey
+
+	// ECDH returns the x-coordinate of x*P.
+	ECDH(px, py *big.Int) (*big.Int, error)
+}
+
+// RemoteKey is a VRF secret key held by a device or service, see ProveRemote.
+type RemoteKey interface {
+	RemoteScalar
+
+	// NewNonce draws a nonce k held by the device as well, for a single proof.
+	NewNonce() (RemoteNonce, error)
+}
+
+// RemoteNonce is the nonce k of a proof made with a RemoteKey. It's closed after the proof.
+type RemoteNonce interface {
+	RemoteScalar
+	io.Closer
+
+	// Respond returns s = (k + c*x) mod q, where x is the key of the nonce.
+	// It must be called at most once, as two responses of a nonce give away the key.
+	Respond(c *big.Int) (*big.Int, error)
+}
+
+// ProveRemote constructs the proof of alpha like Prove, with the operations involving the secret key
+// done by key: Gamma = x*H and k*H are recovered from ECDH results, and s is the response of
+// the nonce to the challenge. The nonce isn't the one of RFC6979, so proofs aren't deterministic,
+// but beta is the same. The proof is verified before it's returned, so faults of the device
+// can't give a wrong output. v must be a VRF object of this package.
+func ProveRemote(v VRF, key RemoteKey, alpha []byte) (beta, pi []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, nil, errUnsupportedVRF
+	}
+	pk := key.Public()
+	if err = impl.checkPublicKey(pk); err != nil {
+		return
+	}
+	core := impl.newCore(pk.Curve)
+	defer core.release()
+	Y := &point{pk.X, pk.Y}
+	if err = core.ValidateKey(Y); err != nil {
+		return
+	}
+
+	H, err := core.HashToCurve(Y, alpha)
+	if err != nil {
+		return
+	}
+	params := pk.Curve.Params()
+	HB := core.Add(H, &point{params.Gx, params.Gy})
+	gamma, err := remoteScalarMult(core, key, Y, H, HB)
+	if err != nil {
+		return
+	}
+
+	nonce, err := key.NewNonce()
+	if err != nil {
+		return
+	}
+	defer nonce.Close()
+	kPub := nonce.Public()
+	if kPub == nil || kPub.Curve != pk.Curve || !pk.Curve.IsOnCurve(kPub.X, kPub.Y) {
+		return nil, nil, errRemoteKey
+	}
+	kB := &point{kPub.X, kPub.Y}
+	kH, err := remoteScalarMult(core, nonce, kB, H, HB)
+	if err != nil {
+		return
+	}
+
+	c := core.Challenge(Y, H, gamma, kB, kH)
+	s, err := nonce.Respond(c)
+	if err != nil {
+		return
+	}
+	if s == nil || s.Sign() < 0 || s.Cmp(core.Q()) >= 0 {
+		return nil, nil, errRemoteKey
+	}
+	pi = core.EncodeProof(gamma, c, s)
+	if beta, err = impl.Verify(pk, alpha, pi); err != nil {
+		return nil, nil, errRemoteKey
+	}
+	return beta, pi, nil
+}
+
+// remoteScalarMult returns x*H of the remote scalar x, whose x*B is xB. ECDH loses the sign of y,
+// which is the one of the candidate P such that P + x*B has the x-coordinate of x*(H+B).
+func remoteScalarMult(core *core, r RemoteScalar, xB, H, HB *point) (*point, error) {
+	hx, err := r.ECDH(H.X, H.Y)
+	if err != nil {
+		return nil, err
+	}
+	sumX, err := r.ECDH(HB.X, HB.Y)
+	if err != nil {
+		return nil, err
+	}
+	if hx == nil || sumX == nil || hx.Sign() < 0 || hx.Cmp(core.curve.Params().P) >= 0 {
+		return nil, errRemoteKey
+	}
+	byteLen := (core.curve.Params().BitSize + 7) / 8
+	even, err := core.Unmarshal(append([]byte{2}, fixedOctets(hx, byteLen)...))
+	if err != nil {
+		return nil, errRemoteKey
+	}
+	odd := &point{even.X, new(big.Int).Sub(core.curve.Params().P, even.Y)}
+	matchEven := core.Add(even, xB).X.Cmp(sumX) == 0
+	matchOdd := core.Add(odd, xB).X.Cmp(sumX) == 0
+	switch {
+	case matchEven && !matchOdd:
+		return even, nil
+	case matchOdd && !matchEven:
+		return odd, nil
+	}
+	return nil, errRemoteKey
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+var (
+	errEmptyRing = errors.New("empty ring")
+	errNotInRing = errors.New("key not in the ring")
+)
+
+// Domain separation octets of the ring hashes, after the suite string, apart from those of
+// hash_to_curve (0x01), the challenge (0x02) and proof_to_hash (0x03).
+const (
+	ringDigestFront    = 0x10
+	ringChallengeFront = 0x11
+)
+
+// ProveRing proves that beta is the output of alpha by one of the keys of the ring, the one of sk,
+// without revealing which, e.g. for private slot-leader election: a party is elected if its beta
+// is below a threshold, which only it knows until it reveals the proof.
+//
+// Gamma = x*H, where H is hash_to_curve of alpha with the generator in place of the public key,
+// so the output of a key for alpha is unique and independent of the ring, but differs from the
+// one of Prove. The proof is a ring of DLEQ proofs, like the linkable ring signatures of Liu et al.
+// with Gamma as the key image: Gamma || c_0 || s_0 || ... || s_{n-1}, of 32 octets per key for
+// P256 and secp256k1. Nonces are derived as for Prove. v must be a VRF object of this package.
+func ProveRing(v VRF, sk *ecdsa.PrivateKey, ring []*ecdsa.PublicKey, alpha []byte) (beta, pi []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, nil, errUnsupportedVRF
+	}
+	if err = impl.checkPrivateKey(sk); err != nil {
+		return
+	}
+	core := impl.newCore(sk.Curve)
+	defer core.release()
+	keys, digest, err := ringKeys(impl, core, ring)
+	if err != nil {
+		return
+	}
+	signer := -1
+	for i, Y := range keys {
+		if Y.X.Cmp(sk.X) == 0 && Y.Y.Cmp(sk.Y) == 0 {
+			signer = i
+			break
+		}
+	}
+	if signer < 0 {
+		return nil, nil, errNotInRing
+	}
+	params := sk.Curve.Params()
+	H, err := core.HashToCurve(&point{params.Gx, params.Gy}, alpha)
+	if err != nil {
+		return
+	}
+	x := core.SecretOctets(sk.D)
+	defer wipe(x)
+	gamma, err := core.BlindScalarMult(H, x)
+	if err != nil {
+		return
+	}
+
+	// the nonce of the signer, and the responses of the other keys, are derived from the key
+	seed := append(append(core.Marshal(H), digest...), make([]byte, 4)...)
+	ub, err := core.HedgedNonceOctets(x, seed)
+	if err != nil {
+		return
+	}
+	defer wipe(ub)
+	uB, err := core.BlindScalarBaseMult(ub)
+	if err != nil {
+		return
+	}
+	uH, err := core.BlindScalarMult(H, ub)
+	if err != nil {
+		return
+	}
+
+	n := len(keys)
+	cs := make([]*big.Int, n)
+	ss := make([]*big.Int, n)
+	cs[(signer+1)%n] = ringChallenge(core, digest, H, gamma, uB, uH)
+	for j := 1; j < n; j++ {
+		i := (signer + j) % n
+		binary.BigEndian.PutUint32(seed[len(seed)-4:], uint32(i+1))
+		sb, err := core.HedgedNonceOctets(x, seed)
+		if err != nil {
+			return nil, nil, err
+		}
+		ss[i] = new(big.Int).SetBytes(sb)
+		U := core.MulSubVartime(&point{params.Gx, params.Gy}, ss[i], keys[i], cs[i])
+		V := core.MulSubVartime(H, ss[i], gamma, cs[i])
+		cs[(i+1)%n] = ringChallenge(core, digest, H, gamma, U, V)
+	}
+	// s = u + c*x closes the ring at the signer
+	if ss[signer], err = core.BlindScalarMulAdd(cs[signer], x, ub); err != nil {
+		return
+	}
+
+	pi = core.Marshal(gamma)
+	pi = append(pi, int2octets(cs[0], core.N())...)
+	qlen := (core.Q().BitLen() + 7) / 8
+	for _, s := range ss {
+		pi = append(pi, int2octets(s, qlen)...)
+	}
+	return core.GammaToHash(gamma), pi, nil
+}
+
+// VerifyRing checks the proof pi of ProveRing that alpha was proven by a key of the ring, in the
+// same order, and returns the output. v must be a VRF object of this package.
+func VerifyRing(v VRF, ring []*ecdsa.PublicKey, alpha, pi []byte) (beta []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if len(ring) == 0 {
+		return nil, errEmptyRing
+	}
+	if err = impl.checkPublicKey(ring[0]); err != nil {
+		return
+	}
+	core := impl.newCore(ring[0].Curve)
+	defer core.release()
+	keys, digest, err := ringKeys(impl, core, ring)
+	if err != nil {
+		return
+	}
+	var (
+		params = ring[0].Curve.Params()
+		ptlen  = (params.BitSize+7)/8 + 1
+		clen   = core.N()
+		qlen   = (core.Q().BitLen() + 7) / 8
+	)
+	if len(pi) != ptlen+clen+len(keys)*qlen {
+		return nil, ErrInvalidProofLength
+	}
+	gamma, err := core.Unmarshal(pi[:ptlen])
+	if err != nil {
+		return
+	}
+	H, err := core.HashToCurve(&point{params.Gx, params.Gy}, alpha)
+	if err != nil {
+		return
+	}
+	c0 := new(big.Int).SetBytes(pi[ptlen : ptlen+clen])
+	c := c0
+	for i, Y := range keys {
+		off := ptlen + clen + i*qlen
+		s := new(big.Int).SetBytes(pi[off : off+qlen])
+		if s.Cmp(core.Q()) >= 0 {
+			return nil, ErrInvalidProofScalar
+		}
+		U := core.MulSubVartime(&point{params.Gx, params.Gy}, s, Y, c)
+		V := core.MulSubVartime(H, s, gamma, c)
+		c = ringChallenge(core, digest, H, gamma, U, V)
+	}
+	if c.Cmp(c0) != 0 {
+		return nil, ErrInvalidProof
+	}
+	return core.GammaToHash(gamma), nil
+}
+
+// ringKeys validates the keys of the ring, and returns their points and digest.
+func ringKeys(impl *vrf, core *core, ring []*ecdsa.PublicKey) ([]*point, []byte, error) {
+	if len(ring) == 0 {
+		return nil, nil, errEmptyRing
+	}
+	hasher := core.getCachedHasher()
+	hasher.Reset()
+	hasher.Write([]byte{core.SuiteString, ringDigestFront})
+	keys := make([]*point, len(ring))
+	for i, pk := range ring {
+		if pk == nil || pk.Curve != core.curve {
+			return nil, nil, errInvalidPublicKey
+		}
+		if err := impl.checkPublicKey(pk); err != nil {
+			return nil, nil, err
+		}
+		keys[i] = &point{pk.X, pk.Y}
+		if err := core.ValidateKey(keys[i]); err != nil {
+			return nil, nil, err
+		}
+		hasher.Write(core.Marshal(keys[i]))
+	}
+	return keys, hasher.Sum(nil), nil
+}
+
+// ringChallenge hashes the ring digest and the points of a link of the ring into the next challenge.
+func ringChallenge(core *core, digest []byte, H, gamma, U, V *point) *big.Int {
+	hasher := core.getCachedHasher()
+	hasher.Reset()
+	hasher.Write([]byte{core.SuiteString, ringChallengeFront})
+	hasher.Write(digest)
+	for _, pt := range []*point{H, gamma, U, V} {
+		hasher.Write(core.Marshal(pt))
+	}
+	return bits2int(hasher.Sum(nil), core.N()*8)
+}
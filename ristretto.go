@@ -0,0 +1,278 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"math/big"
+	"sync"
+)
+
+// ristretto255 implements the prime order group of [RFC9496](https://www.rfc-editor.org/rfc/rfc9496.html)
+// on top of edwards25519. Elements are edwards25519 points, and points of the same coset of the
+// 4-torsion subgroup are the same element.
+type ristretto255 struct {
+	e *edwards25519
+
+	oneMinusDSq    fieldElement // 1 - d²
+	dMinusOneSq    fieldElement // (d - 1)²
+	sqrtADMinusOne fieldElement // sqrt(a*d - 1)
+	invSqrtAMinusD fieldElement // 1 / sqrt(a - d)
+}
+
+var (
+	ristrettoOnce  sync.Once
+	ristrettoGroup *ristretto255
+)
+
+// ristretto returns the arithmetic of ristretto255, building it at the first call.
+func ristretto() *ristretto255 {
+	ristrettoOnce.Do(func() {
+		e := edwards()
+		fp := e.fp
+		r := &ristretto255{e: e}
+
+		var one, t fieldElement
+		one = fp.one
+		fp.sqr(&t, &e.d)
+		fp.sub(&r.oneMinusDSq, &one, &t)
+		fp.sub(&t, &e.d, &one)
+		fp.sqr(&r.dMinusOneSq, &t)
+
+		sqrtADMinusOne, _ := new(big.Int).SetString("25063068953384623474111414158702152701244531502492656460079210482610430750235", 10)
+		invSqrtAMinusD, _ := new(big.Int).SetString("54469307008909316920995813868745141605393597292927456921205312896311721017578", 10)
+		fp.fromBig(&r.sqrtADMinusOne, sqrtADMinusOne)
+		fp.fromBig(&r.invSqrtAMinusD, invSqrtAMinusD)
+		ristrettoGroup = r
+	})
+	return ristrettoGroup
+}
+
+// abs sets z = |x|, the non-negative one of x and -x.
+func (r *ristretto255) abs(z, x *fieldElement) {
+	var n fieldElement
+	r.e.fp.neg(&n, x)
+	selectElement(z, &n, x, r.e.isNegative(x))
+}
+
+// sqrtRatioM1 implements SQRT_RATIO_M1 of RFC9496: z is the non-negative sqrt(u/v) if it exists,
+// otherwise sqrt(i*u/v). 1 is returned if u/v is a square.
+func (r *ristretto255) sqrtRatioM1(z, u, v *fieldElement) uint64 {
+	var (
+		fp                 = r.e.fp
+		v3, v7, t, check   fieldElement
+		negU, negUi, rootI fieldElement
+	)
+	// r = (u * v^3) * (u * v^7)^((p-5)/8)
+	fp.sqr(&v3, v)
+	fp.mul(&v3, &v3, v)
+	fp.sqr(&v7, &v3)
+	fp.mul(&v7, &v7, v)
+	fp.mul(&t, u, &v7)
+	fp.exp(&t, &t, r.e.sqrtExp)
+	fp.mul(&t, &t, u)
+	fp.mul(&t, &t, &v3)
+
+	fp.sqr(&check, &t)
+	fp.mul(&check, &check, v)
+	fp.neg(&negU, u)
+	fp.mul(&negUi, &negU, &r.e.sqrtM1)
+	correct := fp.equal(&check, u)
+	flipped := fp.equal(&check, &negU)
+	flippedI := fp.equal(&check, &negUi)
+
+	fp.mul(&rootI, &t, &r.e.sqrtM1)
+	selectElement(&t, &rootI, &t, flipped|flippedI)
+	r.abs(z, &t)
+	return correct | flipped
+}
+
+// decode sets p to the element of the 32-octet encoding s. false is returned if s is not
+// the canonical encoding of an element.
+func (r *ristretto255) decode(p *edwardsPoint, s []byte) bool {
+	var (
+		e                                  = r.e
+		fp                                 = e.fp
+		buf                                [32]byte
+		fs, ss, u1, u2, u2sq, v, t         fieldElement
+		invsqrt, denX, denY, x, y, one, tt fieldElement
+	)
+	if len(s) != 32 {
+		return false
+	}
+	for i := range buf {
+		buf[i] = s[31-i]
+	}
+	fs = limbsFromBytes(&buf)
+	// s must be canonical and non-negative
+	if !lessThan(&fs, &fp.p) || buf[31]&1 == 1 {
+		return false
+	}
+	fp.fromLimbs(&fs, &fs)
+	one = fp.one
+
+	fp.sqr(&ss, &fs)
+	fp.sub(&u1, &one, &ss)
+	fp.add(&u2, &one, &ss)
+	fp.sqr(&u2sq, &u2)
+
+	// v = -(d * u1²) - u2²
+	fp.sqr(&t, &u1)
+	fp.mul(&t, &t, &e.d)
+	fp.neg(&v, &t)
+	fp.sub(&v, &v, &u2sq)
+
+	fp.mul(&t, &v, &u2sq)
+	wasSquare := r.sqrtRatioM1(&invsqrt, &one, &t)
+	fp.mul(&denX, &invsqrt, &u2)
+	fp.mul(&denY, &invsqrt, &denX)
+	fp.mul(&denY, &denY, &v)
+
+	// x = |2 * s * den_x|, y = u1 * den_y, t = x * y
+	fp.add(&x, &fs, &fs)
+	fp.mul(&x, &x, &denX)
+	r.abs(&x, &x)
+	fp.mul(&y, &u1, &denY)
+	fp.mul(&tt, &x, &y)
+	if wasSquare == 0 || e.isNegative(&tt) == 1 || fp.isZero(&y) == 1 {
+		return false
+	}
+	p.x, p.y, p.z, p.t = x, y, one, tt
+	return true
+}
+
+// encode writes the 32-octet canonical encoding of the element p to out.
+func (r *ristretto255) encode(out []byte, p *edwardsPoint) {
+	var (
+		e                             = r.e
+		fp                            = e.fp
+		u1, u2, t, invsqrt, den1      fieldElement
+		den2, zInv, ix, iy, enchanted fieldElement
+		x, y, denInv, s               fieldElement
+		buf                           [32]byte
+	)
+	fp.add(&u1, &p.z, &p.y)
+	fp.sub(&t, &p.z, &p.y)
+	fp.mul(&u1, &u1, &t)
+	fp.mul(&u2, &p.x, &p.y)
+
+	fp.sqr(&t, &u2)
+	fp.mul(&t, &t, &u1)
+	one := fp.one
+	r.sqrtRatioM1(&invsqrt, &one, &t)
+	fp.mul(&den1, &invsqrt, &u1)
+	fp.mul(&den2, &invsqrt, &u2)
+	fp.mul(&zInv, &den1, &den2)
+	fp.mul(&zInv, &zInv, &p.t)
+
+	fp.mul(&ix, &p.x, &e.sqrtM1)
+	fp.mul(&iy, &p.y, &e.sqrtM1)
+	fp.mul(&enchanted, &den1, &r.invSqrtAMinusD)
+
+	fp.mul(&t, &p.t, &zInv)
+	rotate := e.isNegative(&t)
+	selectElement(&x, &iy, &p.x, rotate)
+	selectElement(&y, &ix, &p.y, rotate)
+	selectElement(&denInv, &enchanted, &den2, rotate)
+
+	fp.mul(&t, &x, &zInv)
+	fp.neg(&s, &y)
+	selectElement(&y, &s, &y, e.isNegative(&t))
+
+	fp.sub(&s, &p.z, &y)
+	fp.mul(&s, &s, &denInv)
+	r.abs(&s, &s)
+	fp.bytes(&buf, &s)
+	for i := range buf {
+		out[i] = buf[31-i]
+	}
+}
+
+// mapToPoint implements the MAP function of RFC9496, the Elligator map of a field element to the group.
+func (r *ristretto255) mapToPoint(p *edwardsPoint, t *fieldElement) {
+	var (
+		e                          = r.e
+		fp                         = e.fp
+		one, rr, u, v, s, st, c, n fieldElement
+		w0, w1, w2, w3, tmp, ssq   fieldElement
+	)
+	one = fp.one
+
+	// r = i * t², u = (r + 1) * (1 - d²), v = (-1 - r*d) * (r + d)
+	fp.sqr(&rr, t)
+	fp.mul(&rr, &rr, &e.sqrtM1)
+	fp.add(&u, &rr, &one)
+	fp.mul(&u, &u, &r.oneMinusDSq)
+	fp.mul(&tmp, &rr, &e.d)
+	fp.neg(&v, &one)
+	fp.sub(&v, &v, &tmp)
+	fp.add(&tmp, &rr, &e.d)
+	fp.mul(&v, &v, &tmp)
+
+	wasSquare := r.sqrtRatioM1(&s, &u, &v)
+	fp.mul(&st, &s, t)
+	r.abs(&st, &st)
+	fp.neg(&st, &st)
+	selectElement(&s, &s, &st, wasSquare)
+	fp.neg(&tmp, &one)
+	selectElement(&c, &tmp, &rr, wasSquare)
+
+	// N = c * (r - 1) * (d - 1)² - v
+	fp.sub(&n, &rr, &one)
+	fp.mul(&n, &n, &c)
+	fp.mul(&n, &n, &r.dMinusOneSq)
+	fp.sub(&n, &n, &v)
+
+	fp.add(&w0, &s, &s)
+	fp.mul(&w0, &w0, &v)
+	fp.mul(&w1, &n, &r.sqrtADMinusOne)
+	fp.sqr(&ssq, &s)
+	fp.sub(&w2, &one, &ssq)
+	fp.add(&w3, &one, &ssq)
+
+	fp.mul(&p.x, &w0, &w3)
+	fp.mul(&p.y, &w2, &w1)
+	fp.mul(&p.z, &w1, &w3)
+	fp.mul(&p.t, &w0, &w2)
+}
+
+// fromUniformBytes implements the one-way map of RFC9496 from 64 uniform octets to the group,
+// which is RistrettoPoint::from_uniform_bytes of curve25519-dalek.
+func (r *ristretto255) fromUniformBytes(p *edwardsPoint, b []byte) {
+	var (
+		fp     = r.e.fp
+		buf    [32]byte
+		t1, t2 fieldElement
+		p2     edwardsPoint
+	)
+	for i := range buf {
+		buf[i] = b[31-i]
+	}
+	buf[0] &= 0x7f
+	t1 = limbsFromBytes(&buf)
+	fp.fromLimbs(&t1, &t1)
+	for i := range buf {
+		buf[i] = b[63-i]
+	}
+	buf[0] &= 0x7f
+	t2 = limbsFromBytes(&buf)
+	fp.fromLimbs(&t2, &t2)
+
+	r.mapToPoint(p, &t1)
+	r.mapToPoint(&p2, &t2)
+	r.e.add(p, p, &p2)
+}
+
+// equal returns whether p and q are the same element, i.e. X1*Y2 == Y1*X2 or Y1*Y2 == X1*X2.
+func (r *ristretto255) equal(p, q *edwardsPoint) bool {
+	var (
+		fp   = r.e.fp
+		a, b fieldElement
+	)
+	fp.mul(&a, &p.x, &q.y)
+	fp.mul(&b, &p.y, &q.x)
+	eq1 := fp.equal(&a, &b)
+	fp.mul(&a, &p.y, &q.y)
+	fp.mul(&b, &p.x, &q.x)
+	return eq1|fp.equal(&a, &b) == 1
+}
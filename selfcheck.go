@@ -0,0 +1,29 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+)
+
+var errSelfCheckFailed = errors.New("proof failed the self-check")
+
+// WithSelfCheck makes Prove verify each proof before returning it, so that proofs corrupted by faults,
+// e.g. glitched computations on hostile hardware, are never output. It costs one verification per proof.
+func WithSelfCheck() Option {
+	return func(cfg *Config) {
+		cfg.SelfCheck = true
+	}
+}
+
+// selfCheck verifies the proof just made by prove, which must give the same beta.
+func (v *vrf) selfCheck(pk *ecdsa.PublicKey, alpha, beta, pi []byte) error {
+	got, err := v.appendVerify(nil, pk, alpha, pi)
+	if err != nil || !bytes.Equal(got, beta) {
+		return errSelfCheckFailed
+	}
+	return nil
+}
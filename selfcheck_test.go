@@ -0,0 +1,36 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+)
+
+func TestSelfCheck(t *testing.T) {
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	alpha := []byte("alpha")
+	v := NewP256Sha256Tai(WithSelfCheck()).(*vrf)
+	beta, pi, err := v.Prove(sk, alpha)
+	if err != nil {
+		t.Fatalf("Prove() error = %v", err)
+	}
+	if err := v.selfCheck(&sk.PublicKey, alpha, beta, pi); err != nil {
+		t.Fatalf("selfCheck() error = %v", err)
+	}
+
+	// a glitched s, and a glitched beta, are caught
+	bad := append([]byte{}, pi...)
+	bad[len(bad)-1] ^= 1
+	if err := v.selfCheck(&sk.PublicKey, alpha, beta, bad); err != errSelfCheckFailed {
+		t.Fatalf("selfCheck() of a faulty proof error = %v, want %v", err, errSelfCheckFailed)
+	}
+	badBeta := append([]byte{}, beta...)
+	badBeta[0] ^= 1
+	if err := v.selfCheck(&sk.PublicKey, alpha, badBeta, pi); err != errSelfCheckFailed {
+		t.Fatalf("selfCheck() of a faulty beta error = %v, want %v", err, errSelfCheckFailed)
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package service is a VRF prover shared by several internal clients: the keys stay in the service,
+// and each client identity is granted operations per key. It doesn't depend on a transport;
+// the vrfgrpc module serves it over gRPC, with the client identities authenticated by mTLS.
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"io"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// DefaultMaxBatch is the number of items of a BatchVerify call above which it's refused, if WithMaxBatch isn't used.
+const DefaultMaxBatch = 1024
+
+// Errors of the service, which transports map to their status codes.
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrUnknownKey       = errors.New("unknown key")
+	ErrNoPrivateKey     = errors.New("key can't prove: no private key")
+	ErrBatchTooLarge    = errors.New("batch too large")
+
+	errDuplicateKey = errors.New("duplicate key id")
+	errInvalidKey   = errors.New("key has no id, VRF or public key")
+)
+
+// Operation is an operation on a key, subject to the ACL.
+type Operation string
+
+// Operations of the service.
+const (
+	OpProve  Operation = "prove"
+	OpVerify Operation = "verify"
+)
+
+// AnyIdentity grants an operation to every authenticated client in an ACL.
+const AnyIdentity = "*"
+
+// ACL grants operations on keys to client identities: ACL[keyID][identity] holds the operations
+// the identity may call on the key. Nothing is granted by default.
+type ACL map[string]map[string][]Operation
+
+func (a ACL) allows(identity, keyID string, op Operation) bool {
+	if identity == "" {
+		return false
+	}
+	grants := a[keyID]
+	for _, id := range []string{identity, AnyIdentity} {
+		for _, o := range grants[id] {
+			if o == op {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Key is a key served by the service. Private is nil for keys which only verify;
+// Public is derived from Private if nil.
+type Key struct {
+	ID      string
+	VRF     ecvrf.VRF
+	Private *ecdsa.PrivateKey
+	Public  *ecdsa.PublicKey
+}
+
+// VerifyItem is an input of BatchVerify and VerifyStream.
+type VerifyItem struct {
+	KeyID string
+	Alpha []byte
+	Pi    []byte
+}
+
+// VerifyResult is the result of a VerifyItem. Err is ErrPermissionDenied or ErrUnknownKey
+// for items the client may not verify, and the error of the VRF for invalid proofs.
+type VerifyResult struct {
+	Beta []byte
+	Err  error
+}
+
+// ProveResult is the result of an input of ProveStream.
+type ProveResult struct {
+	Beta []byte
+	Pi   []byte
+	Err  error
+}
+
+// Server is the VRF service. It's safe for concurrent use.
+type Server struct {
+	keys        map[string]*Key
+	acl         ACL
+	maxBatch    int
+	parallelism int
+}
+
+// Option modifies the behavior of a Server.
+type Option func(*Server)
+
+// WithMaxBatch sets the number of items of a BatchVerify call above which it's refused with ErrBatchTooLarge.
+func WithMaxBatch(n int) Option {
+	return func(s *Server) {
+		s.maxBatch = n
+	}
+}
+
+// WithParallelism sets the number of goroutines verifying the items of a BatchVerify call, see ecvrf.WithParallelism.
+func WithParallelism(n int) Option {
+	return func(s *Server) {
+		s.parallelism = n
+	}
+}
+
+// New creates the Server of the keys, whose operations are granted by the ACL.
+// The keys and the ACL must not be modified afterwards.
+func New(keys []Key, acl ACL, opts ...Option) (*Server, error) {
+	s := &Server{
+		keys:        make(map[string]*Key, len(keys)),
+		acl:         acl,
+		maxBatch:    DefaultMaxBatch,
+		parallelism: 1,
+	}
+	for i := range keys {
+		k := keys[i]
+		if k.Public == nil && k.Private != nil {
+			k.Public = &k.Private.PublicKey
+		}
+		if k.ID == "" || k.VRF == nil || k.Public == nil {
+			return nil, errInvalidKey
+		}
+		if _, ok := s.keys[k.ID]; ok {
+			return nil, errDuplicateKey
+		}
+		s.keys[k.ID] = &k
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// PublicKey returns the public key of the key, for clients allowed to verify with it.
+func (s *Server) PublicKey(identity, keyID string) (*ecdsa.PublicKey, error) {
+	k, err := s.key(identity, keyID, OpVerify)
+	if err != nil {
+		return nil, err
+	}
+	return k.Public, nil
+}
+
+// Prove computes the output of alpha under the key, and its proof.
+func (s *Server) Prove(identity, keyID string, alpha []byte) (beta, pi []byte, err error) {
+	k, err := s.key(identity, keyID, OpProve)
+	if err != nil {
+		return nil, nil, err
+	}
+	if k.Private == nil {
+		return nil, nil, ErrNoPrivateKey
+	}
+	return k.VRF.Prove(k.Private, alpha)
+}
+
+// Verify checks the proof pi of alpha against the key, and returns the output.
+func (s *Server) Verify(identity, keyID string, alpha, pi []byte) (beta []byte, err error) {
+	k, err := s.key(identity, keyID, OpVerify)
+	if err != nil {
+		return nil, err
+	}
+	return k.VRF.Verify(k.Public, alpha, pi)
+}
+
+// BatchVerify verifies the items, which may be of different keys. Results are in the same order as the items.
+// Unlike ecvrf.VerifyBatch, the error is only about the whole batch, i.e. ErrBatchTooLarge.
+func (s *Server) BatchVerify(identity string, items []VerifyItem) ([]VerifyResult, error) {
+	return s.BatchVerifyContext(context.Background(), identity, items)
+}
+
+// BatchVerifyContext is like BatchVerify, but stops when ctx is done, e.g. when the request of the
+// client times out, and then returns the error of ctx.
+func (s *Server) BatchVerifyContext(ctx context.Context, identity string, items []VerifyItem) ([]VerifyResult, error) {
+	if len(items) > s.maxBatch {
+		return nil, ErrBatchTooLarge
+	}
+	var (
+		results = make([]VerifyResult, len(items))
+		groups  = make(map[string][]int)
+		order   []string
+	)
+	for i, item := range items {
+		if _, err := s.key(identity, item.KeyID, OpVerify); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if _, ok := groups[item.KeyID]; !ok {
+			order = append(order, item.KeyID)
+		}
+		groups[item.KeyID] = append(groups[item.KeyID], i)
+	}
+	// the items of a key are verified together, as ecvrf.VerifyBatch takes a single VRF object
+	for _, id := range order {
+		var (
+			k       = s.keys[id]
+			indices = groups[id]
+			batch   = make([]ecvrf.BatchItem, len(indices))
+		)
+		for j, i := range indices {
+			batch[j] = ecvrf.BatchItem{PublicKey: k.Public, Alpha: items[i].Alpha, Pi: items[i].Pi}
+		}
+		res, _ := ecvrf.VerifyBatchContext(ctx, k.VRF, batch, ecvrf.WithParallelism(s.parallelism))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for j, i := range indices {
+			results[i] = VerifyResult{Beta: res[j].Beta, Err: res[j].Err}
+		}
+	}
+	return results, nil
+}
+
+// VerifyStream verifies the items returned by recv until it returns io.EOF, and passes their results to send
+// in the same order. Other errors of recv and send end the stream, and are returned.
+func (s *Server) VerifyStream(identity string, recv func() (VerifyItem, error), send func(VerifyResult) error) error {
+	for {
+		item, err := recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var r VerifyResult
+		r.Beta, r.Err = s.Verify(identity, item.KeyID, item.Alpha, item.Pi)
+		if err := send(r); err != nil {
+			return err
+		}
+	}
+}
+
+// ProveStream proves the inputs returned by recv under the key until it returns io.EOF, and passes their
+// results to send in the same order. The access to the key is checked once, before the first input.
+func (s *Server) ProveStream(identity, keyID string, recv func() ([]byte, error), send func(ProveResult) error) error {
+	k, err := s.key(identity, keyID, OpProve)
+	if err != nil {
+		return err
+	}
+	if k.Private == nil {
+		return ErrNoPrivateKey
+	}
+	for {
+		alpha, err := recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var r ProveResult
+		r.Beta, r.Pi, r.Err = k.VRF.Prove(k.Private, alpha)
+		if err := send(r); err != nil {
+			return err
+		}
+	}
+}
+
+// key returns the key if the identity may call op on it. Unknown keys are reported to clients
+// which may use them only, so that key ids aren't disclosed.
+func (s *Server) key(identity, keyID string, op Operation) (*Key, error) {
+	if !s.acl.allows(identity, keyID, op) {
+		return nil, ErrPermissionDenied
+	}
+	k, ok := s.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return k, nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+var errNoClientCertificate = errors.New("no verified client certificate")
+
+// TLSConfig returns the server side of mTLS: TLS 1.2 at least, and clients must present
+// a certificate issued by clientCAs, whose identity is then given by Identity.
+func TLSConfig(cert tls.Certificate, clientCAs *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// ClientTLSConfig returns the client side of mTLS, presenting cert to servers issued by rootCAs.
+func ClientTLSConfig(cert tls.Certificate, rootCAs *x509.CertPool, serverName string) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      rootCAs,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// Identity returns the identity of the client of a connection of TLSConfig, which the ACL refers to:
+// the first URI SAN of its verified certificate, such as a SPIFFE ID, else the first DNS SAN,
+// else the subject common name.
+func Identity(state *tls.ConnectionState) (string, error) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return "", errNoClientCertificate
+	}
+	leaf := state.VerifiedChains[0][0]
+	switch {
+	case len(leaf.URIs) > 0:
+		return leaf.URIs[0].String(), nil
+	case len(leaf.DNSNames) > 0:
+		return leaf.DNSNames[0], nil
+	case leaf.Subject.CommonName != "":
+		return leaf.Subject.CommonName, nil
+	}
+	return "", errNoClientCertificate
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package vrfgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Client calls the VRF service on a connection, such as one of grpc.NewClient with the
+// credentials of service.ClientTLSConfig. Its calls set the JSON content-subtype themselves.
+type Client struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewClient creates the client of the service on cc.
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc}
+}
+
+// Prove calls Prove. Errors of the service are gRPC status errors, whose codes are listed in the package documentation.
+func (c *Client) Prove(ctx context.Context, keyID string, alpha []byte, opts ...grpc.CallOption) (*ProveResponse, error) {
+	resp := new(ProveResponse)
+	if err := c.invoke(ctx, "Prove", &ProveRequest{KeyID: keyID, Alpha: alpha}, resp, opts); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Verify calls Verify. Invalid proofs are reported by the response, not by the error.
+func (c *Client) Verify(ctx context.Context, keyID string, alpha, pi []byte, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	resp := new(VerifyResponse)
+	if err := c.invoke(ctx, "Verify", &VerifyRequest{KeyID: keyID, Alpha: alpha, Pi: pi}, resp, opts); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// BatchVerify calls BatchVerify.
+func (c *Client) BatchVerify(ctx context.Context, items []VerifyRequest, opts ...grpc.CallOption) (*BatchVerifyResponse, error) {
+	resp := new(BatchVerifyResponse)
+	if err := c.invoke(ctx, "BatchVerify", &BatchVerifyRequest{Items: items}, resp, opts); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PublicKey calls PublicKey.
+func (c *Client) PublicKey(ctx context.Context, keyID string, opts ...grpc.CallOption) (*PublicKeyResponse, error) {
+	resp := new(PublicKeyResponse)
+	if err := c.invoke(ctx, "PublicKey", &PublicKeyRequest{KeyID: keyID}, resp, opts); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ProveStream opens a ProveStream, on which requests are sent with SendMsg(*ProveRequest)
+// and responses received with RecvMsg(*ProveResponse), in the same order.
+func (c *Client) ProveStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.stream(ctx, 0, opts)
+}
+
+// VerifyStream opens a VerifyStream, on which requests are sent with SendMsg(*VerifyRequest)
+// and responses received with RecvMsg(*VerifyResponse), in the same order.
+func (c *Client) VerifyStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.stream(ctx, 1, opts)
+}
+
+func (c *Client) invoke(ctx context.Context, method string, req, resp interface{}, opts []grpc.CallOption) error {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(CodecName)}, opts...)
+	return c.cc.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp, opts...)
+}
+
+func (c *Client) stream(ctx context.Context, i int, opts []grpc.CallOption) (grpc.ClientStream, error) {
+	desc := &serviceDesc.Streams[i]
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(CodecName)}, opts...)
+	return c.cc.NewStream(ctx, desc, "/"+ServiceName+"/"+desc.StreamName, opts...)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package vrfgrpc
+
+import (
+	"encoding/json"
+)
+
+// ProveRequest is the request of Prove and the messages of ProveStream. In a stream, KeyID is
+// taken from the first message, and later messages must repeat it or leave it empty.
+type ProveRequest struct {
+	KeyID string `json:"key_id,omitempty"`
+	Alpha []byte `json:"alpha"`
+}
+
+// ProveResponse is the response of Prove and the messages of ProveStream.
+// Error is set instead of Beta and Pi if the VRF failed on the input.
+type ProveResponse struct {
+	Beta  []byte `json:"beta,omitempty"`
+	Pi    []byte `json:"pi,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// VerifyRequest is the request of Verify, an item of BatchVerify and the messages of VerifyStream.
+type VerifyRequest struct {
+	KeyID string `json:"key_id"`
+	Alpha []byte `json:"alpha"`
+	Pi    []byte `json:"pi"`
+}
+
+// VerifyResponse is the response of Verify, a result of BatchVerify and the messages of VerifyStream.
+// Error is set if the proof is invalid, or, in batches and streams, if the key can't be used.
+type VerifyResponse struct {
+	Valid bool   `json:"valid"`
+	Beta  []byte `json:"beta,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// BatchVerifyRequest is the request of BatchVerify.
+type BatchVerifyRequest struct {
+	Items []VerifyRequest `json:"items"`
+}
+
+// BatchVerifyResponse is the response of BatchVerify, with results in the order of the items.
+type BatchVerifyResponse struct {
+	Results []VerifyResponse `json:"results"`
+}
+
+// PublicKeyRequest is the request of PublicKey.
+type PublicKeyRequest struct {
+	KeyID string `json:"key_id"`
+}
+
+// PublicKeyResponse is the response of PublicKey, with the compressed point and the suite string.
+type PublicKeyResponse struct {
+	PublicKey []byte `json:"public_key"`
+	Curve     string `json:"curve"`
+}
+
+// codec encodes the messages in JSON, the content-subtype of which clients of this package set,
+// so that the service needs no generated protobuf code. Byte fields are base64 strings.
+type codec struct{}
+
+// CodecName is the gRPC content-subtype of the messages, i.e. "application/grpc+json".
+const CodecName = "json"
+
+func (codec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (codec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (codec) Name() string                               { return CodecName }
@@ -0,0 +1,239 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package vrfgrpc serves a service.Server as JSON over HTTP/2, with the framing of gRPC, and the
+// client identities of the ACL authenticated by mTLS. It isn't a protobuf service: there's no .proto,
+// the service descriptor is written by hand, and the messages of this package are JSON encoded under
+// the "json" content-subtype, i.e. "application/grpc+json", see CodecName. The Go client of this
+// package sets it; other clients need a gRPC library with a JSON codec registered, and tools relying
+// on protobuf descriptors, such as server reflection or grpc-gateway, don't apply.
+//
+// ServiceName has the unary methods Prove, Verify, BatchVerify and PublicKey, and the bidirectional
+// streams ProveStream and VerifyStream, whose results are sent in the order of the requests.
+// Errors of the service are the status codes PermissionDenied, NotFound for unknown keys,
+// FailedPrecondition for keys without private key, ResourceExhausted for batches too large,
+// and Unauthenticated for clients without a verified certificate.
+package vrfgrpc
+
+import (
+	"context"
+	"crypto/elliptic"
+	"crypto/tls"
+	"errors"
+	"io"
+
+	"github.com/vechain/go-ecvrf/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName is the full name of the service, the path prefix of its methods.
+const ServiceName = "ecvrf.v1.VRF"
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// NewServer returns a gRPC server of svc with mTLS, see service.TLSConfig, on which Serve is to be called.
+func NewServer(svc *service.Server, tlsConfig *tls.Config, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, opts...)
+	s := grpc.NewServer(opts...)
+	Register(s, svc)
+	return s
+}
+
+// Register registers svc on the gRPC server s, whose credentials must verify client certificates.
+func Register(s grpc.ServiceRegistrar, svc *service.Server) {
+	s.RegisterService(&serviceDesc, &handler{svc})
+}
+
+type handler struct {
+	svc *service.Server
+}
+
+// serviceDesc is written by hand, as there's no generated code of a .proto.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Prove", Handler: unary("Prove", func(ctx context.Context, h *handler, id string, req *ProveRequest) (interface{}, error) {
+			beta, pi, err := h.svc.Prove(id, req.KeyID, req.Alpha)
+			if err != nil {
+				return nil, statusOf(err)
+			}
+			return &ProveResponse{Beta: beta, Pi: pi}, nil
+		})},
+		{MethodName: "Verify", Handler: unary("Verify", func(ctx context.Context, h *handler, id string, req *VerifyRequest) (interface{}, error) {
+			beta, err := h.svc.Verify(id, req.KeyID, req.Alpha, req.Pi)
+			if isAccessError(err) {
+				return nil, statusOf(err)
+			}
+			return verifyResponse(beta, err), nil
+		})},
+		{MethodName: "BatchVerify", Handler: unary("BatchVerify", func(ctx context.Context, h *handler, id string, req *BatchVerifyRequest) (interface{}, error) {
+			items := make([]service.VerifyItem, len(req.Items))
+			for i, it := range req.Items {
+				items[i] = service.VerifyItem{KeyID: it.KeyID, Alpha: it.Alpha, Pi: it.Pi}
+			}
+			results, err := h.svc.BatchVerifyContext(ctx, id, items)
+			if err != nil {
+				return nil, statusOf(err)
+			}
+			resp := &BatchVerifyResponse{Results: make([]VerifyResponse, len(results))}
+			for i, r := range results {
+				resp.Results[i] = *verifyResponse(r.Beta, r.Err)
+			}
+			return resp, nil
+		})},
+		{MethodName: "PublicKey", Handler: unary("PublicKey", func(ctx context.Context, h *handler, id string, req *PublicKeyRequest) (interface{}, error) {
+			pk, err := h.svc.PublicKey(id, req.KeyID)
+			if err != nil {
+				return nil, statusOf(err)
+			}
+			return &PublicKeyResponse{
+				PublicKey: elliptic.MarshalCompressed(pk.Curve, pk.X, pk.Y),
+				Curve:     pk.Curve.Params().Name,
+			}, nil
+		})},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "ProveStream", Handler: proveStream, ServerStreams: true, ClientStreams: true},
+		{StreamName: "VerifyStream", Handler: verifyStream, ServerStreams: true, ClientStreams: true},
+	},
+}
+
+// unary adapts a typed method to a grpc.MethodDesc handler, authenticating the client first.
+func unary[Req any](method string, f func(ctx context.Context, h *handler, id string, req *Req) (interface{}, error)) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		req := new(Req)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			id, err := identity(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return f(ctx, srv.(*handler), id, req.(*Req))
+		}
+		if interceptor == nil {
+			return call(ctx, req)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + method}
+		return interceptor(ctx, req, info, call)
+	}
+}
+
+func proveStream(srv interface{}, stream grpc.ServerStream) error {
+	id, err := identity(stream.Context())
+	if err != nil {
+		return err
+	}
+	var first ProveRequest
+	if err := stream.RecvMsg(&first); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	pending := &first
+	recv := func() ([]byte, error) {
+		if pending != nil {
+			alpha := pending.Alpha
+			pending = nil
+			return alpha, nil
+		}
+		var req ProveRequest
+		if err := stream.RecvMsg(&req); err != nil {
+			return nil, err
+		}
+		if req.KeyID != "" && req.KeyID != first.KeyID {
+			return nil, status.Error(codes.InvalidArgument, "key_id changed in the stream")
+		}
+		return req.Alpha, nil
+	}
+	send := func(r service.ProveResult) error {
+		resp := &ProveResponse{Beta: r.Beta, Pi: r.Pi}
+		if r.Err != nil {
+			resp = &ProveResponse{Error: r.Err.Error()}
+		}
+		return stream.SendMsg(resp)
+	}
+	return statusOf(srv.(*handler).svc.ProveStream(id, first.KeyID, recv, send))
+}
+
+func verifyStream(srv interface{}, stream grpc.ServerStream) error {
+	id, err := identity(stream.Context())
+	if err != nil {
+		return err
+	}
+	recv := func() (service.VerifyItem, error) {
+		var req VerifyRequest
+		if err := stream.RecvMsg(&req); err != nil {
+			return service.VerifyItem{}, err
+		}
+		return service.VerifyItem{KeyID: req.KeyID, Alpha: req.Alpha, Pi: req.Pi}, nil
+	}
+	send := func(r service.VerifyResult) error {
+		return stream.SendMsg(verifyResponse(r.Beta, r.Err))
+	}
+	return statusOf(srv.(*handler).svc.VerifyStream(id, recv, send))
+}
+
+// identity returns the identity of the client from its verified certificate.
+func identity(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "no peer")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "no TLS client certificate")
+	}
+	id, err := service.Identity(&info.State)
+	if err != nil {
+		return "", status.Error(codes.Unauthenticated, err.Error())
+	}
+	return id, nil
+}
+
+func isAccessError(err error) bool {
+	return err == service.ErrPermissionDenied || err == service.ErrUnknownKey
+}
+
+func verifyResponse(beta []byte, err error) *VerifyResponse {
+	if err != nil {
+		return &VerifyResponse{Error: err.Error()}
+	}
+	return &VerifyResponse{Valid: true, Beta: beta}
+}
+
+// statusOf maps the errors of the service to gRPC status codes; errors of the streams are kept.
+func statusOf(err error) error {
+	var code codes.Code
+	switch {
+	case err == nil:
+		return nil
+	case err == service.ErrPermissionDenied:
+		code = codes.PermissionDenied
+	case err == service.ErrUnknownKey:
+		code = codes.NotFound
+	case err == service.ErrNoPrivateKey:
+		code = codes.FailedPrecondition
+	case err == service.ErrBatchTooLarge:
+		code = codes.ResourceExhausted
+	default:
+		if _, ok := status.FromError(err); ok || errors.Is(err, context.Canceled) {
+			return err
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err).Err()
+		}
+		code = codes.Internal
+	}
+	return status.Error(code, err.Error())
+}
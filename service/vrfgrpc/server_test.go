@@ -0,0 +1,256 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package vrfgrpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type pki struct {
+	ca    *x509.Certificate
+	caKey *ecdsa.PrivateKey
+	pool  *x509.CertPool
+}
+
+func newPKI(t *testing.T) *pki {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	return &pki{ca, key, pool}
+}
+
+func (p *pki) issue(t *testing.T, name string, usage x509.ExtKeyUsage) tls.Certificate {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, p.ca, &key.PublicKey, p.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func setup(t *testing.T) (func(client string) *Client, *ecdsa.PrivateKey) {
+	var (
+		p      = newPKI(t)
+		sk, _  = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		lis    = bufconn.Listen(1 << 20)
+		keys   = []service.Key{{ID: "k1", VRF: ecvrf.NewP256Sha256Tai(), Private: sk}}
+		acl    = service.ACL{"k1": {"prover": {service.OpProve, service.OpVerify}, service.AnyIdentity: {service.OpVerify}}}
+		svc, _ = service.New(keys, acl, service.WithMaxBatch(4))
+		srv    = NewServer(svc, service.TLSConfig(p.issue(t, "vrf.internal", x509.ExtKeyUsageServerAuth), p.pool))
+	)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	dial := func(client string) *Client {
+		cfg := service.ClientTLSConfig(p.issue(t, client, x509.ExtKeyUsageClientAuth), p.pool, "vrf.internal")
+		cc, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { cc.Close() })
+		return NewClient(cc)
+	}
+	return dial, sk
+}
+
+func TestService(t *testing.T) {
+	var (
+		dial, sk = setup(t)
+		ctx      = context.Background()
+		prover   = dial("prover")
+		other    = dial("other")
+	)
+
+	proved, err := prover.Prove(ctx, "k1", []byte("alpha"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, err := ecvrf.NewP256Sha256Tai().Verify(&sk.PublicKey, []byte("alpha"), proved.Pi)
+	if err != nil || string(beta) != string(proved.Beta) {
+		t.Fatal("proof of the service doesn't verify", err)
+	}
+
+	// verification is granted to all, proving only to "prover"
+	if _, err := other.Prove(ctx, "k1", []byte("alpha")); status.Code(err) != codes.PermissionDenied {
+		t.Fatal("want PermissionDenied, got", err)
+	}
+	if _, err := other.Prove(ctx, "k2", []byte("alpha")); status.Code(err) != codes.PermissionDenied {
+		t.Fatal("want PermissionDenied for ungranted keys, got", err)
+	}
+	verified, err := other.Verify(ctx, "k1", []byte("alpha"), proved.Pi)
+	if err != nil || !verified.Valid || string(verified.Beta) != string(proved.Beta) {
+		t.Fatal("valid proof rejected", verified, err)
+	}
+	verified, err = other.Verify(ctx, "k1", []byte("beta"), proved.Pi)
+	if err != nil || verified.Valid || verified.Error == "" {
+		t.Fatal("invalid proof accepted", verified, err)
+	}
+	if pk, err := other.PublicKey(ctx, "k1"); err != nil || string(pk.PublicKey) != string(elliptic.MarshalCompressed(sk.Curve, sk.X, sk.Y)) {
+		t.Fatal("wrong public key", err)
+	}
+
+	batch, err := other.BatchVerify(ctx, []VerifyRequest{
+		{KeyID: "k1", Alpha: []byte("alpha"), Pi: proved.Pi},
+		{KeyID: "k1", Alpha: []byte("beta"), Pi: proved.Pi},
+		{KeyID: "k2", Alpha: []byte("alpha"), Pi: proved.Pi},
+	})
+	if err != nil || len(batch.Results) != 3 || !batch.Results[0].Valid || batch.Results[1].Valid || batch.Results[2].Valid {
+		t.Fatal("wrong batch results", batch, err)
+	}
+	if _, err := other.BatchVerify(ctx, make([]VerifyRequest, 5)); status.Code(err) != codes.ResourceExhausted {
+		t.Fatal("want ResourceExhausted, got", err)
+	}
+}
+
+func TestStreams(t *testing.T) {
+	var (
+		dial, sk = setup(t)
+		ctx      = context.Background()
+		prover   = dial("prover")
+		alphas   = []string{"a", "b", "c"}
+		proofs   [][]byte
+	)
+
+	ps, err := prover.ProveStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, a := range alphas {
+		req := &ProveRequest{Alpha: []byte(a)}
+		if i == 0 {
+			req.KeyID = "k1"
+		}
+		if err := ps.SendMsg(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ps.CloseSend()
+	for range alphas {
+		var resp ProveResponse
+		if err := ps.RecvMsg(&resp); err != nil {
+			t.Fatal(err)
+		}
+		proofs = append(proofs, resp.Pi)
+	}
+	if err := ps.RecvMsg(new(ProveResponse)); err != io.EOF {
+		t.Fatal("want end of stream, got", err)
+	}
+
+	vs, err := dial("other").VerifyStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, a := range alphas {
+		if err := vs.SendMsg(&VerifyRequest{KeyID: "k1", Alpha: []byte(a), Pi: proofs[i%2]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vs.CloseSend()
+	for i, a := range alphas {
+		var resp VerifyResponse
+		if err := vs.RecvMsg(&resp); err != nil {
+			t.Fatal(err)
+		}
+		want, _ := ecvrf.NewP256Sha256Tai().Verify(&sk.PublicKey, []byte(a), proofs[i%2])
+		if resp.Valid != (want != nil) {
+			t.Fatal("wrong stream result", i, resp)
+		}
+	}
+
+	// the stream is refused before the first input without the grant
+	ps, err = dial("other").ProveStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps.SendMsg(&ProveRequest{KeyID: "k1", Alpha: []byte("a")})
+	ps.CloseSend()
+	if err := ps.RecvMsg(new(ProveResponse)); status.Code(err) != codes.PermissionDenied {
+		t.Fatal("want PermissionDenied, got", err)
+	}
+}
+
+func TestNoClientCertificate(t *testing.T) {
+	var (
+		p   = newPKI(t)
+		lis = bufconn.Listen(1 << 10)
+	)
+	svc, _ := service.New(nil, nil)
+	srv := NewServer(svc, service.TLSConfig(p.issue(t, "vrf.internal", x509.ExtKeyUsageServerAuth), p.pool))
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	cc, _ := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: p.pool, ServerName: "vrf.internal"})))
+	defer cc.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := NewClient(cc).Verify(ctx, "k1", nil, nil); err == nil {
+		t.Fatal("call without client certificate accepted")
+	}
+}
+
+func TestBatchVerifyCanceled(t *testing.T) {
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	svc, _ := service.New([]service.Key{{ID: "k1", VRF: ecvrf.NewP256Sha256Tai(), Private: sk}},
+		service.ACL{"k1": {service.AnyIdentity: {service.OpVerify}}})
+	_, pi, _ := ecvrf.NewP256Sha256Tai().Prove(sk, []byte("alpha"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	results, err := svc.BatchVerifyContext(ctx, "client", []service.VerifyItem{{KeyID: "k1", Alpha: []byte("alpha"), Pi: pi}})
+	if err != context.DeadlineExceeded || results != nil {
+		t.Fatal("want context.DeadlineExceeded, got", err)
+	}
+	if status.Code(statusOf(err)) != codes.DeadlineExceeded {
+		t.Fatal("want DeadlineExceeded, got", statusOf(err))
+	}
+	if results, err := svc.BatchVerifyContext(context.Background(), "client", []service.VerifyItem{{KeyID: "k1", Alpha: []byte("alpha"), Pi: pi}}); err != nil || results[0].Err != nil {
+		t.Fatal("valid proof rejected", err)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package shamir escrows the private key of a Weierstrass VRF among n custodians, by Shamir's
+// secret sharing: any t of the shares rebuild the key, fewer tell nothing about it. Unlike the
+// threshold package, the key exists in one place when split and when combined, e.g. for a backup.
+//
+// Shares carry the Feldman commitments of the polynomial, whose first one is the public key, so
+// each custodian checks its share on receipt, and Combine detects corrupted shares before
+// rebuilding the key.
+package shamir
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+var (
+	errParams        = errors.New("shamir: invalid threshold or number of shares")
+	errInvalidKey    = errors.New("shamir: invalid private key")
+	errFewShares     = errors.New("shamir: not enough shares")
+	errMixedShares   = errors.New("shamir: shares of different keys")
+	errInvalidResult = errors.New("shamir: shares gave another key")
+	errEncoding      = errors.New("shamir: invalid share encoding")
+)
+
+// ShareError reports the custodian whose share is invalid, so it can be excluded.
+type ShareError struct {
+	Index int
+}
+
+func (e *ShareError) Error() string {
+	return fmt.Sprintf("shamir: invalid share %d", e.Index)
+}
+
+// Share is the share f(Index) of the key of a custodian, where the polynomial f of degree T-1 has
+// the secret key as f(0).
+type Share struct {
+	Curve elliptic.Curve
+	T     int
+	Index int
+	Value *big.Int
+	// Commitments are a_k*B of the coefficients a_k of f, a_0*B being the public key.
+	Commitments [][2]*big.Int
+}
+
+// Split splits sk into n shares, of indices 1..n, any t of which rebuild it. n can't exceed 255.
+func Split(sk *ecdsa.PrivateKey, t, n int, rand io.Reader) ([]*Share, error) {
+	if t < 1 || t > n || n > 255 {
+		return nil, errParams
+	}
+	if sk == nil || sk.D == nil || sk.D.Sign() <= 0 || sk.D.Cmp(sk.Curve.Params().N) >= 0 {
+		return nil, errInvalidKey
+	}
+	c := sk.Curve
+	q := c.Params().N
+	coeffs := make([]*big.Int, t)
+	defer func() {
+		for _, a := range coeffs[1:] {
+			wipeInt(a)
+		}
+	}()
+	commitments := make([][2]*big.Int, t)
+	coeffs[0] = sk.D
+	commitments[0] = [2]*big.Int{new(big.Int).Set(sk.X), new(big.Int).Set(sk.Y)}
+	for k := 1; k < t; k++ {
+		a, err := randScalar(c, rand)
+		if err != nil {
+			return nil, err
+		}
+		coeffs[k] = a
+		commitments[k] = baseMult(c, a)
+	}
+
+	shares := make([]*Share, n)
+	for j := 1; j <= n; j++ {
+		// Horner's rule
+		f := new(big.Int)
+		x := big.NewInt(int64(j))
+		for k := t - 1; k >= 0; k-- {
+			f.Mul(f, x)
+			f.Add(f, coeffs[k])
+			f.Mod(f, q)
+		}
+		shares[j-1] = &Share{Curve: c, T: t, Index: j, Value: f, Commitments: commitments}
+	}
+	return shares, nil
+}
+
+// PublicKey returns the public key of the shared key.
+func (s *Share) PublicKey() *ecdsa.PublicKey {
+	return &ecdsa.PublicKey{Curve: s.Curve, X: s.Commitments[0][0], Y: s.Commitments[0][1]}
+}
+
+// Verify checks the share against its commitments: Value*B = sum(Index^k * C_k). A custodian
+// verifies its share on receipt, and checks the public key against the registered one.
+func (s *Share) Verify() error {
+	if s.T < 1 || len(s.Commitments) != s.T || s.Index < 1 || s.Index > 255 {
+		return &ShareError{s.Index}
+	}
+	for _, ck := range s.Commitments {
+		if ck[0] == nil || ck[1] == nil || !s.Curve.IsOnCurve(ck[0], ck[1]) {
+			return &ShareError{s.Index}
+		}
+	}
+	if s.Value == nil || s.Value.Sign() < 0 || s.Value.Cmp(s.Curve.Params().N) >= 0 ||
+		!equal(baseMult(s.Curve, s.Value), evalCommitments(s.Curve, s.Commitments, s.Index)) {
+		return &ShareError{s.Index}
+	}
+	return nil
+}
+
+// Combine rebuilds the private key from at least T shares of distinct indices. It fails with a
+// *ShareError at the first invalid share.
+func Combine(shares []*Share) (*ecdsa.PrivateKey, error) {
+	if len(shares) == 0 {
+		return nil, errFewShares
+	}
+	first := shares[0]
+	seen := make(map[int]bool, len(shares))
+	var xs []*Share
+	for _, s := range shares {
+		if s.Curve != first.Curve || s.T != first.T || !sameCommitments(s.Commitments, first.Commitments) {
+			return nil, errMixedShares
+		}
+		if err := s.Verify(); err != nil {
+			return nil, err
+		}
+		if !seen[s.Index] {
+			seen[s.Index] = true
+			xs = append(xs, s)
+		}
+	}
+	if len(xs) < first.T {
+		return nil, errFewShares
+	}
+	xs = xs[:first.T]
+
+	// f(0) = sum(f(j) * prod(m / (m - j))) over the other indices m
+	c := first.Curve
+	q := c.Params().N
+	d := new(big.Int)
+	for _, s := range xs {
+		num, den := big.NewInt(1), big.NewInt(1)
+		for _, o := range xs {
+			if o.Index == s.Index {
+				continue
+			}
+			num.Mul(num, big.NewInt(int64(o.Index)))
+			den.Mul(den, big.NewInt(int64(o.Index-s.Index)))
+		}
+		den.Mod(den, q)
+		l := num.Mul(num, den.ModInverse(den, q))
+		l.Mul(l, s.Value)
+		d.Add(d, l)
+		d.Mod(d, q)
+		wipeInt(l)
+	}
+
+	pk := first.PublicKey()
+	sk := &ecdsa.PrivateKey{D: d}
+	sk.Curve = c
+	sk.X, sk.Y = c.ScalarBaseMult(d.Bytes())
+	if d.Sign() == 0 || sk.X.Cmp(pk.X) != 0 || sk.Y.Cmp(pk.Y) != 0 {
+		wipeInt(d)
+		return nil, errInvalidResult
+	}
+	return sk, nil
+}
+
+// Bytes encodes the share to be stored by its custodian: T and Index in one octet each, then the
+// value, then the T commitments uncompressed.
+func (s *Share) Bytes() []byte {
+	qlen := (s.Curve.Params().N.BitLen() + 7) / 8
+	b := []byte{byte(s.T), byte(s.Index)}
+	b = append(b, s.Value.FillBytes(make([]byte, qlen))...)
+	for _, ck := range s.Commitments {
+		b = append(b, elliptic.Marshal(s.Curve, ck[0], ck[1])...)
+	}
+	return b
+}
+
+// ParseShare decodes the share of Bytes for the curve, and verifies it.
+func ParseShare(c elliptic.Curve, b []byte) (*Share, error) {
+	var (
+		qlen  = (c.Params().N.BitLen() + 7) / 8
+		ptlen = 1 + 2*((c.Params().BitSize+7)/8)
+	)
+	if len(b) < 2 || len(b) != 2+qlen+int(b[0])*ptlen {
+		return nil, errEncoding
+	}
+	s := &Share{Curve: c, T: int(b[0]), Index: int(b[1]), Value: new(big.Int).SetBytes(b[2 : 2+qlen])}
+	for off := 2 + qlen; off < len(b); off += ptlen {
+		x, y := elliptic.Unmarshal(c, b[off:off+ptlen])
+		if x == nil {
+			return nil, errEncoding
+		}
+		s.Commitments = append(s.Commitments, [2]*big.Int{x, y})
+	}
+	if err := s.Verify(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// evalCommitments returns sum(j^k * C_k) = f(j)*B.
+func evalCommitments(c elliptic.Curve, commitments [][2]*big.Int, j int) [2]*big.Int {
+	q := c.Params().N
+	x := big.NewInt(int64(j))
+	pow := big.NewInt(1)
+	var r [2]*big.Int
+	for k, ck := range commitments {
+		term := ck
+		if k > 0 {
+			term = scalarMult(c, ck, pow)
+		}
+		if r[0] == nil {
+			r = term
+		} else {
+			rx, ry := c.Add(r[0], r[1], term[0], term[1])
+			r = [2]*big.Int{rx, ry}
+		}
+		pow.Mul(pow, x)
+		pow.Mod(pow, q)
+	}
+	return r
+}
+
+func sameCommitments(a, b [][2]*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i][0] == nil || b[i][0] == nil || !equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func randScalar(c elliptic.Curve, rand io.Reader) (*big.Int, error) {
+	q := c.Params().N
+	b := make([]byte, (q.BitLen()+7)/8+8)
+	defer wipe(b)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		// 64 extra bits make the bias of the reduction negligible
+		if k := new(big.Int).Mod(new(big.Int).SetBytes(b), q); k.Sign() > 0 {
+			return k, nil
+		}
+	}
+}
+
+func equal(p1, p2 [2]*big.Int) bool {
+	return p1[0].Cmp(p2[0]) == 0 && p1[1].Cmp(p2[1]) == 0
+}
+
+func scalarMult(c elliptic.Curve, p [2]*big.Int, k *big.Int) [2]*big.Int {
+	x, y := c.ScalarMult(p[0], p[1], k.Bytes())
+	return [2]*big.Int{x, y}
+}
+
+func baseMult(c elliptic.Curve, k *big.Int) [2]*big.Int {
+	x, y := c.ScalarBaseMult(k.Bytes())
+	return [2]*big.Int{x, y}
+}
+
+// wipeInt zeroes the words of the secret k.
+func wipeInt(k *big.Int) {
+	if k == nil {
+		return
+	}
+	words := k.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	k.SetInt64(0)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
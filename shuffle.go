@@ -0,0 +1,70 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"sort"
+
+	"github.com/vechain/go-ecvrf/internal/stream"
+)
+
+// Shuffle returns the permutation of [0, n) derived from beta, e.g. a deck order or a validator
+// ordering, by ShuffleSlice.
+func Shuffle(beta []byte, n int) []int {
+	if n <= 0 {
+		return nil
+	}
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	ShuffleSlice(beta, n, func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+	return perm
+}
+
+// ShuffleSlice shuffles n elements with swap, like math/rand.Shuffle, by a Fisher-Yates shuffle
+// over a stream of uniform integers derived from beta: for i from 0, element i is swapped with a
+// uniform j in [i, n), so the first k elements are a uniform sample of k elements. The stream is
+// HMAC-SHA256 with beta as the key in counter mode, and integers are drawn by rejection, without
+// modulo bias.
+func ShuffleSlice(beta []byte, n int, swap func(i, j int)) {
+	stream.New(beta, stream.UseShuffle).Shuffle(n, swap)
+}
+
+// SampleK returns k distinct elements of [0, n) derived from beta, in increasing order, e.g. a
+// committee or the entries of an audit. The sample is the set of the first k elements of Shuffle,
+// computed in O(k) time and memory by a partial Fisher-Yates shuffle remembering only the swapped
+// positions, so it's canonical: anyone re-derives the same set, whatever n. k is capped at n.
+func SampleK(beta []byte, n, k int) []int {
+	if k > n {
+		k = n
+	}
+	if k <= 0 {
+		return nil
+	}
+	sample := stream.New(beta, stream.UseShuffle).Sample(n, k)
+	sort.Ints(sample)
+	return sample
+}
+
+// VerifySampleK checks the proof pi of alpha against pk, and returns the sample of k elements of
+// [0, n) derived from its output.
+func VerifySampleK(v VRF, pk *ecdsa.PublicKey, alpha, pi []byte, n, k int) ([]int, error) {
+	beta, err := v.Verify(pk, alpha, pi)
+	if err != nil {
+		return nil, err
+	}
+	return SampleK(beta, n, k), nil
+}
+
+// VerifyShuffle checks the proof pi of alpha against pk, and returns the permutation of [0, n)
+// derived from its output.
+func VerifyShuffle(v VRF, pk *ecdsa.PublicKey, alpha, pi []byte, n int) ([]int, error) {
+	beta, err := v.Verify(pk, alpha, pi)
+	if err != nil {
+		return nil, err
+	}
+	return Shuffle(beta, n), nil
+}
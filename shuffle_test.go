@@ -0,0 +1,104 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/binary"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestShuffle(t *testing.T) {
+	// each element lands at each position uniformly over fixed betas
+	const n, rounds = 5, 20000
+	var counts [n][n]int
+	beta := make([]byte, 32)
+	for r := 0; r < rounds; r++ {
+		binary.BigEndian.PutUint64(beta, uint64(r))
+		perm := Shuffle(beta, n)
+		seen := make([]bool, n)
+		for pos, e := range perm {
+			if seen[e] {
+				t.Fatalf("Shuffle() = %v, not a permutation", perm)
+			}
+			seen[e] = true
+			counts[e][pos]++
+		}
+	}
+	for e := range counts {
+		for pos, c := range counts[e] {
+			if c < rounds/n*9/10 || c > rounds/n*11/10 {
+				t.Errorf("element %d at position %d %d times out of %d", e, pos, c, rounds)
+			}
+		}
+	}
+
+	// the slice shuffler follows the same permutation
+	deck := []string{"a", "b", "c", "d", "e"}
+	ShuffleSlice(beta, len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+	perm := Shuffle(beta, len(deck))
+	for pos, e := range perm {
+		if deck[pos] != string(rune('a'+e)) {
+			t.Fatalf("ShuffleSlice() = %v, want the order %v", deck, perm)
+		}
+	}
+	if Shuffle(beta, 0) != nil {
+		t.Errorf("Shuffle() of no elements isn't nil")
+	}
+
+	// the permutation of a fixed beta, which changes only with the stream
+	if got, want := Shuffle([]byte("go-ecvrf/pinned"), 10), []int{8, 0, 6, 9, 1, 3, 5, 7, 2, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Shuffle() = %v, want %v", got, want)
+	}
+}
+
+func TestVerifyShuffle(t *testing.T) {
+	vrf := NewP256Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	beta, pi, err := vrf.Prove(sk, []byte("hand 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	perm, err := VerifyShuffle(vrf, &sk.PublicKey, []byte("hand 1"), pi, 52)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(perm, Shuffle(beta, 52)) {
+		t.Errorf("VerifyShuffle() = %v, want the permutation of beta", perm)
+	}
+	if _, err := VerifyShuffle(vrf, &sk.PublicKey, []byte("hand 2"), pi, 52); err == nil {
+		t.Errorf("VerifyShuffle() accepted the proof of another input")
+	}
+}
+
+func TestSampleK(t *testing.T) {
+	beta := make([]byte, 32)
+	for r := 0; r < 200; r++ {
+		binary.BigEndian.PutUint64(beta, uint64(r))
+		for _, k := range []int{1, 3, 9, 10} {
+			// the sample is the set of the first elements of the shuffle
+			want := append([]int(nil), Shuffle(beta, 10)[:k]...)
+			sort.Ints(want)
+			if got := SampleK(beta, 10, k); !reflect.DeepEqual(got, want) {
+				t.Fatalf("SampleK(%d) = %v, want %v", k, got, want)
+			}
+		}
+	}
+	if got := SampleK(beta, 3, 5); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+		t.Errorf("SampleK() of more than n = %v", got)
+	}
+	if SampleK(beta, 10, 0) != nil || SampleK(beta, 0, 3) != nil {
+		t.Errorf("SampleK() of no elements isn't nil")
+	}
+	if got := SampleK(beta, 1<<40, 4); len(got) != 4 {
+		t.Errorf("SampleK() of a large range = %v", got)
+	}
+	if got, want := SampleK([]byte("go-ecvrf/pinned"), 1000, 5), []int{67, 168, 566, 578, 828}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SampleK() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build go1.21
+// +build go1.21
+
+package ecvrf
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlogLogger returns a Logger writing the events to l, as records of the message of the event,
+// with the error as the "error" attribute: at the warning level for failures, at the info level
+// otherwise.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Log(event string, err error, attrs ...LogAttr) {
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	ctx := context.Background()
+	if !s.l.Enabled(ctx, level) {
+		return
+	}
+	args := make([]slog.Attr, 0, len(attrs)+1)
+	if err != nil {
+		args = append(args, slog.String("error", err.Error()))
+	}
+	for _, a := range attrs {
+		args = append(args, slog.Any(a.Key, a.Value))
+	}
+	s.l.LogAttrs(ctx, level, event, args...)
+}
+
+// LogValue implements slog.LogValuer, so a private key logged by mistake shows as redacted, with
+// the id of its public key.
+func (sk *PrivateKey) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("secret", "REDACTED"), slog.String("public_key", PublicKeyID(sk.pk)))
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package solidity
+
+// contractTemplate is the verifier of SECP256K1_SHA256_TAI. Scalar multiplications are checked
+// through ecrecover, and the square root of hash_to_curve is computed by the modexp precompile.
+const contractTemplate = `// SPDX-License-Identifier: MIT
+// Code generated by github.com/vechain/go-ecvrf/solidity. DO NOT EDIT.
+pragma solidity {{.Pragma}};
+
+/// @title {{.Name}}
+/// @notice Verifies ECVRF proofs of SECP256K1_SHA256_TAI ({{.SpecName}}), with the witnesses
+/// exported by ecvrf.ExportEVMProof of go-ecvrf.
+contract {{.Name}} {
+    uint256 private constant P = 0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f;
+    uint256 private constant N = 0xfffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141;
+    uint256 private constant SQRT_EXP = 0x3fffffffffffffffffffffffffffffffffffffffffffffffffffffffbfffff0c;
+    bytes1 private constant SUITE = 0x{{printf "%02x" .SuiteString}};
+
+    /// @notice Verifies the proof (gamma, c, s) of alpha under the public key pk, and returns beta.
+    /// u = s*B - c*pk, cGammaWitness = c*gamma and sHashWitness = s*H are checked, not computed.
+    function verify(
+        uint256[2] calldata pk,
+        uint256[2] calldata gamma,
+        uint256 c,
+        uint256 s,
+        uint256[2] calldata u,
+        uint256[2] calldata cGammaWitness,
+        uint256[2] calldata sHashWitness,
+        uint256 zInv,
+        bytes calldata alpha
+    ) external view returns (bytes32 beta) {
+        require(isOnCurve(pk) && isOnCurve(gamma), "invalid point");
+        require(isOnCurve(u) && isOnCurve(cGammaWitness) && isOnCurve(sHashWitness), "invalid witness");
+        require(c != 0 && c < (1 << 128) && s != 0 && s < N, "invalid scalar");
+
+        uint256[2] memory h = hashToCurve(pk, alpha);
+        require(isLinearCombinationWithGenerator(N - c, pk, s, pointAddress(u)), "invalid u");
+        require(isScalarMult(gamma, c, cGammaWitness), "invalid c*gamma");
+        require(isScalarMult(h, s, sHashWitness), "invalid s*H");
+        uint256[2] memory v = subWithZInv(sHashWitness, cGammaWitness, zInv);
+        require(challenge({{if .RFC9381}}pk, {{end}}h, gamma, u, v) == c, "invalid proof");
+        return sha256(abi.encodePacked(SUITE, bytes1(0x03), compress(gamma){{.Back}}));
+    }
+
+    /// @dev ECVRF_challenge_generation, the first 16 octets of the hash of the points.
+    function challenge(
+{{- if .RFC9381}}
+        uint256[2] memory pk,
+{{- end}}
+        uint256[2] memory h,
+        uint256[2] memory gamma,
+        uint256[2] memory u,
+        uint256[2] memory v
+    ) internal pure returns (uint256) {
+        bytes memory points = abi.encodePacked({{if .RFC9381}}compress(pk), {{end}}compress(h), compress(gamma), compress(u), compress(v));
+        return uint256(sha256(abi.encodePacked(SUITE, bytes1(0x02), points{{.Back}}))) >> 128;
+    }
+
+    /// @dev ECVRF_hash_to_curve_try_and_increment, the point of even y is taken.
+    function hashToCurve(uint256[2] memory pk, bytes calldata alpha) internal view returns (uint256[2] memory) {
+        bytes memory pkString = compress(pk);
+        for (uint256 ctr = 0; ctr < 256; ctr++) {
+            uint256 x = uint256(sha256(abi.encodePacked(SUITE, bytes1(0x01), pkString, alpha, bytes1(uint8(ctr)){{.Back}})));
+            if (x >= P) {
+                continue;
+            }
+            uint256 y2 = addmod(mulmod(mulmod(x, x, P), x, P), 7, P);
+            uint256 y = modexp(y2, SQRT_EXP);
+            if (mulmod(y, y, P) != y2) {
+                continue;
+            }
+            if (y & 1 == 1) {
+                y = P - y;
+            }
+            return [x, y];
+        }
+        revert("no valid point");
+    }
+
+    /// @dev whether product = scalar * m, by ecrecover(0, v, m.x, scalar * m.x) = scalar * m.
+    function isScalarMult(uint256[2] memory m, uint256 scalar, uint256[2] memory product) internal pure returns (bool) {
+        uint8 v = m[1] & 1 == 0 ? 27 : 28;
+        bytes32 scalarTimesX = bytes32(mulmod(scalar, m[0], N));
+        return ecrecover(bytes32(0), v, bytes32(m[0]), scalarTimesX) == pointAddress(product);
+    }
+
+    /// @dev whether the point of the address witness is k * p + s * B,
+    /// by ecrecover(-(p.x * s), v, p.x, k * p.x) = k * p + s * B.
+    function isLinearCombinationWithGenerator(uint256 k, uint256[2] memory p, uint256 s, address witness)
+        internal
+        pure
+        returns (bool)
+    {
+        uint8 v = p[1] & 1 == 0 ? 27 : 28;
+        bytes32 pseudoHash = bytes32(N - mulmod(p[0], s, N));
+        bytes32 pseudoSignature = bytes32(mulmod(k, p[0], N));
+        return ecrecover(pseudoHash, v, bytes32(p[0]), pseudoSignature) == witness;
+    }
+
+    /// @dev p1 - p2 in projective coordinates, scaled to affine by zInv, which is checked.
+    function subWithZInv(uint256[2] memory p1, uint256[2] memory p2, uint256 zInv)
+        internal
+        pure
+        returns (uint256[2] memory)
+    {
+        (uint256 x1, uint256 y1, uint256 x2, uint256 y2) = (p1[0], p1[1], p2[0], P - p2[1]);
+        require(x1 != x2, "invalid witness");
+        uint256 lx = addmod(y2, P - y1, P);
+        uint256 lz = addmod(x2, P - x1, P);
+        uint256 dx = mulmod(lz, lz, P);
+        uint256 sx = mulmod(lx, lx, P);
+        sx = addmod(sx, P - mulmod(x1, dx, P), P);
+        sx = addmod(sx, P - mulmod(x2, dx, P), P);
+        uint256 dy = mulmod(dx, lz, P);
+        uint256 sy = mulmod(addmod(mulmod(x1, dx, P), P - sx, P), lx, P);
+        sy = addmod(sy, P - mulmod(y1, dy, P), P);
+        uint256 sz = dx;
+        if (dx != dy) {
+            sx = mulmod(sx, dy, P);
+            sy = mulmod(sy, dx, P);
+            sz = mulmod(dx, dy, P);
+        }
+        require(mulmod(sz, zInv, P) == 1, "invalid zInv");
+        return [mulmod(sx, zInv, P), mulmod(sy, zInv, P)];
+    }
+
+    function isOnCurve(uint256[2] memory p) internal pure returns (bool) {
+        if (p[0] >= P || p[1] >= P) {
+            return false;
+        }
+        return mulmod(p[1], p[1], P) == addmod(mulmod(mulmod(p[0], p[0], P), p[0], P), 7, P);
+    }
+
+    function compress(uint256[2] memory p) internal pure returns (bytes memory) {
+        return abi.encodePacked(bytes1(uint8(2 + (p[1] & 1))), p[0]);
+    }
+
+    function pointAddress(uint256[2] memory p) internal pure returns (address) {
+        return address(uint160(uint256(keccak256(abi.encodePacked(p[0], p[1])))));
+    }
+
+    function modexp(uint256 base, uint256 e) internal view returns (uint256 r) {
+        assembly {
+            let m := mload(0x40)
+            mstore(m, 0x20)
+            mstore(add(m, 0x20), 0x20)
+            mstore(add(m, 0x40), 0x20)
+            mstore(add(m, 0x60), base)
+            mstore(add(m, 0x80), e)
+            mstore(add(m, 0xa0), P)
+            if iszero(staticcall(gas(), 0x05, m, 0xc0, m, 0x20)) {
+                revert(0, 0)
+            }
+            r := mload(m)
+        }
+    }
+}
+`
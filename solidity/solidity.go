@@ -0,0 +1,125 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package solidity generates the Solidity contract verifying VRF proofs on the EVM, and formats
+// the calldata of its verify function from proofs of this library, so that both halves are built
+// from the same suite and specification version.
+package solidity
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"regexp"
+	"text/template"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// SuiteSecp256k1Sha256Tai is the suite string of SECP256K1_SHA256_TAI, the only suite of the generator:
+// the verifier relies on ecrecover, which is bound to secp256k1.
+const SuiteSecp256k1Sha256Tai = 0xfe
+
+// DefaultPragma is the Solidity version pragma used if Options.Pragma is empty.
+const DefaultPragma = "^0.8.0"
+
+// VerifySelector is the function selector of the verify function of generated contracts,
+// the first 4 octets of keccak256(VerifySignature).
+var VerifySelector = [4]byte{0x32, 0x06, 0x43, 0xb2}
+
+// VerifySignature is the signature of the verify function of generated contracts.
+const VerifySignature = "verify(uint256[2],uint256[2],uint256,uint256,uint256[2],uint256[2],uint256[2],uint256,bytes)"
+
+var (
+	errUnsupportedSuite = errors.New("unsupported suite: only SECP256K1_SHA256_TAI can be verified on the EVM")
+	errInvalidName      = errors.New("invalid contract name")
+
+	identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	contract   = template.Must(template.New("contract").Parse(contractTemplate))
+)
+
+// Options selects the verifier to generate.
+type Options struct {
+	// Name is the name of the contract, "ECVRFVerifier" if empty.
+	Name string
+	// SuiteString is the suite of the proofs, which must be SuiteSecp256k1Sha256Tai.
+	SuiteString byte
+	// Spec is the version of the specification of the proofs, see ecvrf.WithSpecVersion.
+	Spec ecvrf.SpecVersion
+	// Pragma is the Solidity version pragma, DefaultPragma if empty.
+	Pragma string
+}
+
+// Generate writes the source of the verifier contract selected by opts to w.
+func Generate(w io.Writer, opts Options) error {
+	if opts.SuiteString != SuiteSecp256k1Sha256Tai {
+		return errUnsupportedSuite
+	}
+	if opts.Name == "" {
+		opts.Name = "ECVRFVerifier"
+	}
+	if !identifier.MatchString(opts.Name) {
+		return errInvalidName
+	}
+	if opts.Pragma == "" {
+		opts.Pragma = DefaultPragma
+	}
+	data := struct {
+		Options
+		SpecName string
+		RFC9381  bool
+		Back     string
+	}{Options: opts, SpecName: "draft-irtf-cfrg-vrf-06"}
+	if opts.Spec == ecvrf.RFC9381 {
+		data.SpecName, data.RFC9381, data.Back = "RFC 9381", true, ", bytes1(0x00)"
+	}
+	return contract.Execute(w, data)
+}
+
+// Calldata returns the ABI encoded call of the verify function of generated contracts, for the proof
+// exported by ecvrf.ExportEVMProof and its input alpha.
+func Calldata(p *ecvrf.EVMProof, alpha []byte) []byte {
+	words := []*big.Int{
+		p.PublicKey[0], p.PublicKey[1],
+		p.Gamma[0], p.Gamma[1],
+		p.C, p.S,
+		p.U[0], p.U[1],
+		p.CGammaWitness[0], p.CGammaWitness[1],
+		p.SHashWitness[0], p.SHashWitness[1],
+		p.ZInv,
+	}
+	// the static words, the offset of alpha, its length and its octets padded to words
+	out := make([]byte, 4, 4+32*(len(words)+2)+(len(alpha)+31)/32*32)
+	copy(out, VerifySelector[:])
+	for _, w := range words {
+		out = appendWord(out, w)
+	}
+	out = appendUint(out, uint64(32*(len(words)+1)))
+	out = appendUint(out, uint64(len(alpha)))
+	out = append(out, alpha...)
+	return append(out, make([]byte, cap(out)-len(out))...)
+}
+
+// CalldataOf verifies the proof pi of alpha against pk with the VRF object of this library,
+// and returns the ABI encoded call of the verify function of generated contracts.
+func CalldataOf(v ecvrf.VRF, pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error) {
+	p, err := ecvrf.ExportEVMProof(v, pk, alpha, pi)
+	if err != nil {
+		return nil, err
+	}
+	return Calldata(p, alpha), nil
+}
+
+func appendWord(out []byte, x *big.Int) []byte {
+	var w [32]byte
+	x.FillBytes(w[:])
+	return append(out, w[:]...)
+}
+
+func appendUint(out []byte, x uint64) []byte {
+	var w [32]byte
+	binary.BigEndian.PutUint64(w[24:], x)
+	return append(out, w[:]...)
+}
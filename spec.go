@@ -0,0 +1,36 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+// SpecVersion selects the revision of the ECVRF specification followed by a VRF object.
+type SpecVersion int
+
+const (
+	// Draft06 follows [draft-irtf-cfrg-vrf-06](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html),
+	// the default, which keeps the outputs of earlier versions of this package.
+	Draft06 SpecVersion = iota
+	// RFC9381 follows the final [RFC9381](https://www.rfc-editor.org/rfc/rfc9381.html). Compared to
+	// Draft06, strings hashed by encode_to_curve, challenge_generation and proof_to_hash end with the
+	// domain separator 0x00, and the challenge also commits to the public key. Proofs and outputs of
+	// the two versions are incompatible.
+	RFC9381
+)
+
+// WithSpecVersion makes the VRF object follow the given revision of the specification.
+func WithSpecVersion(v SpecVersion) Option {
+	return func(cfg *Config) {
+		cfg.Spec = v
+	}
+}
+
+// rfc9381Back is the domain separator that RFC9381 appends to hashed strings.
+var rfc9381Back = []byte{0x00}
+
+// domainBack returns the domain separator appended to hashed strings, empty before RFC9381.
+func (cfg *Config) domainBack() []byte {
+	if cfg.Spec == RFC9381 {
+		return rfc9381Back
+	}
+	return nil
+}
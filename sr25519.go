@@ -0,0 +1,282 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+var errNoWitnessRand = errors.New("no random source for proofs")
+
+// Sr25519SecretKey is an expanded sr25519 secret key of schnorrkel: the scalar and the seed of nonces.
+type Sr25519SecretKey struct {
+	key   fieldElement // the scalar, in Montgomery form of the scalar field
+	nonce [32]byte
+}
+
+// NewSr25519KeyFromSeed expands the 32-octet mini secret key of schnorrkel like
+// MiniSecretKey::expand(ExpandMode::Ed25519), which Substrate uses for sr25519 seeds.
+func NewSr25519KeyFromSeed(seed []byte) (*Sr25519SecretKey, error) {
+	if len(seed) != 32 {
+		return nil, errInvalidPrivateKey
+	}
+	h := sha512.Sum512(seed)
+	defer wipe(h[:])
+	h[0] &= 248
+	h[31] &= 63
+	h[31] |= 64
+
+	// divided by the cofactor, as schnorrkel keeps the scalar cleared of the torsion
+	var low byte
+	for i := 31; i >= 0; i-- {
+		r := h[i] & 7
+		h[i] = h[i]>>3 + low
+		low = r << 5
+	}
+	sk := &Sr25519SecretKey{}
+	edwards().scalarFromBytes(&sk.key, h[:32])
+	copy(sk.nonce[:], h[32:])
+	return sk, nil
+}
+
+// NewSr25519KeyFromBytes decodes the 64-octet secret key of SecretKey::to_bytes of schnorrkel:
+// the canonical little-endian scalar followed by the nonce seed.
+func NewSr25519KeyFromBytes(b []byte) (*Sr25519SecretKey, error) {
+	if len(b) != 64 || !isCanonicalScalar(b[:32]) {
+		return nil, errInvalidPrivateKey
+	}
+	sk := &Sr25519SecretKey{}
+	edwards().scalarFromBytes(&sk.key, b[:32])
+	if edwards().fq.isZero(&sk.key) == 1 {
+		return nil, errInvalidPrivateKey
+	}
+	copy(sk.nonce[:], b[32:])
+	return sk, nil
+}
+
+// Public returns the 32-octet ristretto255 encoding of the public key.
+func (sk *Sr25519SecretKey) Public() []byte {
+	var (
+		e  = edwards()
+		kb [32]byte
+		p  edwardsPoint
+	)
+	e.scalarBytes(&kb, &sk.key)
+	defer wipe(kb[:])
+	e.scalarMult(&p, &e.b, kb[:])
+	pk := make([]byte, 32)
+	ristretto().encode(pk, &p)
+	return pk
+}
+
+// Sr25519InOut is the input point of a VRF call of schnorrkel and the output point, both encoded.
+type Sr25519InOut struct {
+	Input, Output [32]byte
+}
+
+// MakeBytes derives n octets of VRF output, separated by the context, like VRFInOut::make_bytes of schnorrkel.
+// BABE of Substrate takes 16 octets with the context "substrate-babe-vrf".
+func (io *Sr25519InOut) MakeBytes(context []byte, n int) []byte {
+	t := NewMerlinTranscript([]byte("VRFResult"))
+	t.AppendMessage(nil, context)
+	t.AppendMessage([]byte("vrf-in"), io.Input[:])
+	t.AppendMessage([]byte("vrf-out"), io.Output[:])
+	out := make([]byte, n)
+	t.ChallengeBytes(nil, out)
+	return out
+}
+
+// NewSr25519SigningTranscript creates the transcript of signing_context(context).bytes(message) of schnorrkel.
+func NewSr25519SigningTranscript(context, message []byte) *MerlinTranscript {
+	t := NewMerlinTranscript([]byte("SigningContext"))
+	t.AppendMessage(nil, context)
+	t.AppendMessage([]byte("sign-bytes"), message)
+	return t
+}
+
+// Sr25519VRF is the VRF of [schnorrkel](https://github.com/w3f/schnorrkel), over ristretto255 and
+// Merlin transcripts, used by Polkadot for the BABE slot claims. The input is a transcript rather than
+// a string, and proofs are Schnorr DLEQ proofs c || s of 64 octets.
+type Sr25519VRF interface {
+	// Prove computes the VRF output of the transcript `t` with the secret key `sk`, and its proof.
+	// The transcript is left unchanged.
+	Prove(sk *Sr25519SecretKey, t *MerlinTranscript) (inout *Sr25519InOut, proof []byte, err error)
+
+	// Verify checks the VRF output `output` of the transcript `t` and its proof `proof` against
+	// the public key `pk`, all ristretto255 encodings. The transcript is left unchanged.
+	Verify(pk []byte, t *MerlinTranscript, output, proof []byte) (*Sr25519InOut, error)
+}
+
+type sr25519VRF struct {
+	rand io.Reader
+}
+
+// NewSr25519 creates the VRF object of schnorrkel, whose proofs are made with randomized
+// nonces drawn from rand, which is usually crypto/rand.Reader. rand may be nil if only Verify is used.
+//
+// Like vrf_sign and vrf_verify of schnorrkel, which set KUSAMA_VRF, the DLEQ proof doesn't commit
+// to the public key, and no extra transcript is bound to it.
+func NewSr25519(rand io.Reader) Sr25519VRF {
+	return &sr25519VRF{rand: rand}
+}
+
+const sr25519ProofLen = 64
+
+// Prove implements Sr25519VRF.
+func (v *sr25519VRF) Prove(sk *Sr25519SecretKey, t *MerlinTranscript) (inout *Sr25519InOut, proof []byte, err error) {
+	if FIPSMode() {
+		return nil, nil, errNotFIPSApprovedSuite
+	}
+	if v.rand == nil {
+		return nil, nil, errNoWitnessRand
+	}
+	if sk == nil || t == nil {
+		return nil, nil, errInvalidPrivateKey
+	}
+	var (
+		e          = edwards()
+		r          = ristretto()
+		fq         = e.fq
+		kb, rb     [32]byte
+		in, out, p edwardsPoint
+		pk         = sk.Public()
+	)
+	defer wipe(kb[:])
+	defer wipe(rb[:])
+	e.scalarBytes(&kb, &sk.key)
+
+	// the input point is hashed from the transcript and the public key, the output is sk times it
+	v.hashInput(&in, t.Clone(), pk)
+	e.scalarMult(&out, &in, kb[:])
+	inout = &Sr25519InOut{}
+	r.encode(inout.Input[:], &in)
+	r.encode(inout.Output[:], &out)
+
+	tr := NewMerlinTranscript([]byte("VRF"))
+	tr.AppendMessage([]byte("proto-name"), []byte("DLEQProof"))
+	tr.AppendMessage([]byte("vrf:h"), inout.Input[:])
+
+	// the witness r is derived from the transcript, the nonce seed of the key, and random octets
+	var (
+		wide [64]byte
+		R, S fieldElement
+		buf  [32]byte
+	)
+	defer wipe(wide[:])
+	defer wipeElement(&R)
+	if err := tr.witnessBytes([]byte("proving\x00"), wide[:], [][]byte{sk.nonce[:]}, v.rand); err != nil {
+		return nil, nil, err
+	}
+	e.scalarFromWide(&R, wide[:])
+	e.scalarBytes(&rb, &R)
+
+	e.scalarMult(&p, &e.b, rb[:])
+	r.encode(buf[:], &p)
+	tr.AppendMessage([]byte("vrf:R=g^r"), buf[:])
+	e.scalarMult(&p, &in, rb[:])
+	r.encode(buf[:], &p)
+	tr.AppendMessage([]byte("vrf:h^r"), buf[:])
+	tr.AppendMessage([]byte("vrf:h^sk"), inout.Output[:])
+
+	// c = challenge_scalar("prove"), s = r - c * sk
+	var C fieldElement
+	tr.ChallengeBytes([]byte("prove"), wide[:])
+	e.scalarFromWide(&C, wide[:])
+	fq.mul(&S, &C, &sk.key)
+	fq.sub(&S, &R, &S)
+
+	proof = make([]byte, sr25519ProofLen)
+	e.scalarBytes(&buf, &C)
+	for i := range buf {
+		proof[i] = buf[31-i]
+	}
+	e.scalarBytes(&buf, &S)
+	for i := range buf {
+		proof[32+i] = buf[31-i]
+	}
+	return inout, proof, nil
+}
+
+// Verify implements Sr25519VRF.
+func (v *sr25519VRF) Verify(pk []byte, t *MerlinTranscript, output, proof []byte) (*Sr25519InOut, error) {
+	if FIPSMode() {
+		return nil, errNotFIPSApprovedSuite
+	}
+	var (
+		e                = edwards()
+		r                = ristretto()
+		Y, in, out, p, q edwardsPoint
+		cb, sb, buf      [32]byte
+		C, S             fieldElement
+		inout            = &Sr25519InOut{}
+	)
+	if t == nil || !r.decode(&Y, pk) || e.isIdentity(&Y) {
+		return nil, errInvalidPublicKey
+	}
+	if len(output) != 32 || !r.decode(&out, output) {
+		return nil, ErrInvalidProof
+	}
+	if len(proof) != sr25519ProofLen || !isCanonicalScalar(proof[:32]) || !isCanonicalScalar(proof[32:]) {
+		return nil, ErrInvalidProof
+	}
+	e.scalarFromBytes(&C, proof[:32])
+	e.scalarFromBytes(&S, proof[32:])
+	e.scalarBytes(&cb, &C)
+	e.scalarBytes(&sb, &S)
+
+	v.hashInput(&in, t.Clone(), pk)
+	r.encode(inout.Input[:], &in)
+	copy(inout.Output[:], output)
+
+	tr := NewMerlinTranscript([]byte("VRF"))
+	tr.AppendMessage([]byte("proto-name"), []byte("DLEQProof"))
+	tr.AppendMessage([]byte("vrf:h"), inout.Input[:])
+
+	// R = c * pk + s * B, h^r = c * output + s * input
+	e.scalarMult(&p, &Y, cb[:])
+	e.scalarMult(&q, &e.b, sb[:])
+	e.add(&p, &p, &q)
+	r.encode(buf[:], &p)
+	tr.AppendMessage([]byte("vrf:R=g^r"), buf[:])
+	e.scalarMult(&p, &out, cb[:])
+	e.scalarMult(&q, &in, sb[:])
+	e.add(&p, &p, &q)
+	r.encode(buf[:], &p)
+	tr.AppendMessage([]byte("vrf:h^r"), buf[:])
+	tr.AppendMessage([]byte("vrf:h^sk"), inout.Output[:])
+
+	var (
+		wide    [64]byte
+		derived fieldElement
+	)
+	tr.ChallengeBytes([]byte("prove"), wide[:])
+	e.scalarFromWide(&derived, wide[:])
+	e.scalarBytes(&buf, &derived)
+	if subtle.ConstantTimeCompare(buf[:], cb[:]) != 1 {
+		return nil, ErrInvalidProof
+	}
+	return inout, nil
+}
+
+// hashInput sets p to the input point, PublicKey::vrf_hash of schnorrkel: the transcript commits
+// to the public key, and 64 challenge octets are mapped to the group.
+func (v *sr25519VRF) hashInput(p *edwardsPoint, t *MerlinTranscript, pk []byte) {
+	var b [64]byte
+	t.AppendMessage([]byte("vrf-nm-pk"), pk)
+	t.ChallengeBytes([]byte("VRFHash"), b[:])
+	ristretto().fromUniformBytes(p, b[:])
+}
+
+// isCanonicalScalar returns whether the 32-octet little-endian scalar s is less than ℓ.
+func isCanonicalScalar(s []byte) bool {
+	var buf [32]byte
+	for i := range buf {
+		buf[i] = s[31-i]
+	}
+	l := limbsFromBytes(&buf)
+	return lessThan(&l, &edwards().fq.p)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+)
+
+func TestKeccakF1600(t *testing.T) {
+	// SHA3-256 of the empty string, padded by hand into the rate of 136 octets
+	var state [200]byte
+	state[0] ^= 0x06
+	state[135] ^= 0x80
+	keccakF1600(&state)
+	if got, want := hex.EncodeToString(state[:32]), "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a"; got != want {
+		t.Errorf("keccakF1600() = %v, want %v", got, want)
+	}
+}
+
+func TestMerlinTranscript(t *testing.T) {
+	// the test vector of the merlin crate
+	tr := NewMerlinTranscript([]byte("test protocol"))
+	tr.AppendMessage([]byte("some label"), []byte("some data"))
+	got := make([]byte, 32)
+	tr.ChallengeBytes([]byte("challenge"), got)
+	if want := "d5a21972d0d5fe320c0d263fac7fffb8145aa640af6e9bca177c03c7efcf0615"; hex.EncodeToString(got) != want {
+		t.Errorf("ChallengeBytes() = %x, want %v", got, want)
+	}
+}
+
+func TestRistretto(t *testing.T) {
+	var (
+		e = edwards()
+		r = ristretto()
+	)
+	// encodings of 0, B, 2B, 3B from RFC9496 A.1
+	multiples := []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+		"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+		"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	}
+	var p, q edwardsPoint
+	e.identity(&p)
+	for i, want := range multiples {
+		got := make([]byte, 32)
+		r.encode(got, &p)
+		if hex.EncodeToString(got) != want {
+			t.Errorf("encode(%dB) = %x, want %v", i, got, want)
+		}
+		if !r.decode(&q, got) || !r.equal(&p, &q) {
+			t.Errorf("decode(%x) failed", got)
+		}
+		e.add(&p, &p, &e.b)
+	}
+
+	// non-canonical and negative field elements are rejected
+	bad, _ := hex.DecodeString("0100000000000000000000000000000000000000000000000000000000000000")
+	if r.decode(&q, bad) {
+		t.Errorf("decode(%x) succeeded", bad)
+	}
+	bad, _ = hex.DecodeString("edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if r.decode(&q, bad) {
+		t.Errorf("decode(%x) succeeded", bad)
+	}
+
+	// hash to group from RFC9496 A.3
+	h := sha512.Sum512([]byte("Ristretto is traditionally a short shot of espresso coffee"))
+	r.fromUniformBytes(&p, h[:])
+	got := make([]byte, 32)
+	r.encode(got, &p)
+	if want := "3066f82a1a747d45120d1740f14358531a8f04bbffe6a819f86dfe50f44a0a46"; hex.EncodeToString(got) != want {
+		t.Errorf("fromUniformBytes() = %x, want %v", got, want)
+	}
+}
+
+func TestSr25519KeyFromSeed(t *testing.T) {
+	// the //Alice development account of Substrate
+	seed, _ := hex.DecodeString("e5be9a5092b81bca64be81d212e7f2f9eba183bb7a90954f7b76361f6edb5c0a")
+	sk, err := NewSr25519KeyFromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(sk.Public()), "d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"; got != want {
+		t.Errorf("Public() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+// WithFixedHashToCurve makes hash_to_curve try at least n candidates of try_and_increment, always
+// doing the work of the remaining ones after the point is found, so that the timing of Prove, Verify
+// and EncodeToCurve doesn't tell how many candidates alpha took, e.g. for key transparency where
+// alpha is a private identifier. H and the proofs are the same as without the option.
+// A point is found after n candidates with a probability of about 2^-n, which then shows in the
+// timing; n = 64 is enough in practice. Each candidate costs a hash and a square root.
+func WithFixedHashToCurve(n int) Option {
+	return func(cfg *Config) {
+		if n > 256 {
+			n = 256
+		}
+		cfg.HashToCurveCandidates = n
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256()},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			other, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			alphas := [][]byte{[]byte("request 1"), []byte("request 2"), []byte("request 3"), {}}
+
+			betas, pi, err := ecvrf.ProveAggregate(tt.vrf, sk, alphas)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := 33*len(alphas) + 48; len(pi) != want {
+				t.Fatalf("len(pi) = %v, want %v", len(pi), want)
+			}
+			for i, alpha := range alphas {
+				beta, _, err := tt.vrf.Prove(sk, alpha)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(betas[i], beta) {
+					t.Errorf("betas[%v] differs from the beta of Prove", i)
+				}
+			}
+			got, err := ecvrf.VerifyAggregate(tt.vrf, &sk.PublicKey, alphas, pi)
+			if err != nil {
+				t.Fatalf("VerifyAggregate() error = %v", err)
+			}
+			for i := range betas {
+				if !bytes.Equal(got[i], betas[i]) {
+					t.Errorf("VerifyAggregate() betas[%v] differs from the one of ProveAggregate", i)
+				}
+			}
+
+			if _, err := ecvrf.VerifyAggregate(tt.vrf, &other.PublicKey, alphas, pi); err == nil {
+				t.Errorf("VerifyAggregate() accepted the proof for another key")
+			}
+			swapped := [][]byte{alphas[1], alphas[0], alphas[2], alphas[3]}
+			if _, err := ecvrf.VerifyAggregate(tt.vrf, &sk.PublicKey, swapped, pi); err == nil {
+				t.Errorf("VerifyAggregate() accepted swapped inputs")
+			}
+			if _, err := ecvrf.VerifyAggregate(tt.vrf, &sk.PublicKey, alphas[:3], pi); err == nil {
+				t.Errorf("VerifyAggregate() accepted fewer inputs")
+			}
+			for _, i := range []int{1, 33 + 5, len(pi) - 40, len(pi) - 1} {
+				bad := append([]byte{}, pi...)
+				bad[i] ^= 1
+				if _, err := ecvrf.VerifyAggregate(tt.vrf, &sk.PublicKey, alphas, bad); err == nil {
+					t.Errorf("VerifyAggregate() accepted the proof with octet %v flipped", i)
+				}
+			}
+			// the Gammas of two proofs don't mix
+			_, pi2, err := ecvrf.ProveAggregate(tt.vrf, sk, [][]byte{[]byte("request 9"), alphas[1], alphas[2], alphas[3]})
+			if err != nil {
+				t.Fatal(err)
+			}
+			mixed := append(append([]byte{}, pi2[:33]...), pi[33:]...)
+			if _, err := ecvrf.VerifyAggregate(tt.vrf, &sk.PublicKey, [][]byte{[]byte("request 9"), alphas[1], alphas[2], alphas[3]}, mixed); err == nil {
+				t.Errorf("VerifyAggregate() accepted a Gamma of another proof")
+			}
+
+			if _, _, err := ecvrf.ProveAggregate(tt.vrf, sk, nil); err == nil {
+				t.Errorf("ProveAggregate() accepted no input")
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestComposeAlpha(t *testing.T) {
+	got := ecvrf.ComposeAlpha("d", 1, []byte{0xaa}, []byte("x"))
+	want, _ := hex.DecodeString("676f2d65637672662f616c7068612f763100" + "0000000164" + "0000000000000001" + "00000001aa" + "0000000178")
+	if !bytes.Equal(got, want) {
+		t.Fatalf("ComposeAlpha() = %x, want %x", got, want)
+	}
+
+	// inputs which concatenate to the same string compose distinct alphas
+	distinct := [][]byte{
+		ecvrf.ComposeAlpha("ab", 1, nil),
+		ecvrf.ComposeAlpha("a", 1, []byte("b")),
+		ecvrf.ComposeAlpha("ab", 1, nil, nil),
+		ecvrf.ComposeAlpha("ab", 1, nil, []byte("x"), []byte("y")),
+		ecvrf.ComposeAlpha("ab", 1, nil, []byte("xy")),
+		ecvrf.ComposeAlpha("ab", 1, nil, []byte("xy"), nil),
+		ecvrf.ComposeAlpha("ab", 0x0100, nil),
+		ecvrf.ComposeAlpha("ab1", 1, nil),
+	}
+	for i := range distinct {
+		for j := i + 1; j < len(distinct); j++ {
+			if bytes.Equal(distinct[i], distinct[j]) {
+				t.Errorf("ComposeAlpha() collides for the inputs %v and %v", i, j)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/auditlog"
+)
+
+func TestAuditLog(t *testing.T) {
+	for _, s := range suites {
+		t.Run(s.name, func(t *testing.T) {
+			now := time.Unix(1000, 0)
+			clock := auditlog.WithClock(func() time.Time { return now })
+			var buf bytes.Buffer
+			log := auditlog.New(&buf, clock)
+			vrf := log.Wrap(s.vrf, func(alpha []byte) string { return strings.SplitN(string(alpha), ":", 2)[0] })
+			sk, _ := ecdsa.GenerateKey(s.curve, rand.Reader)
+
+			for _, alpha := range []string{"slot 1:a", "slot 2:b", "slot 1:a"} {
+				now = now.Add(time.Second)
+				if _, _, err := vrf.Prove(sk, []byte(alpha)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			report, err := auditlog.Verify(bytes.NewReader(buf.Bytes()))
+			if err != nil || report.Entries != 3 || len(report.Conflicts) != 0 {
+				t.Fatalf("Verify() = %+v, %v", report, err)
+			}
+			if n, head := log.Head(); n != 3 || head != report.Head {
+				t.Fatalf("Head() = %d, %x, want %x", n, head, report.Head)
+			}
+
+			// resuming after a restart continues the chain, and the double proof of slot 1 conflicts
+			log, err = auditlog.Resume(&buf, bytes.NewReader(buf.Bytes()), clock)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := log.Wrap(s.vrf, func(alpha []byte) string { return "slot 1" }).Prove(sk, []byte("c")); err != nil {
+				t.Fatal(err)
+			}
+			var exported []*auditlog.Entry
+			report, err = auditlog.Verify(bytes.NewReader(buf.Bytes()), func(e *auditlog.Entry) { exported = append(exported, e) })
+			if err != nil || report.Entries != 4 || len(exported) != 4 || len(report.Conflicts) != 1 {
+				t.Fatalf("Verify() = %+v, %v", report, err)
+			}
+			if c := report.Conflicts[0]; c.First.Seq != 1 || c.Second.Seq != 4 || c.Second.Key != ecvrf.PublicKeyID(&sk.PublicKey) {
+				t.Fatalf("conflict = %+v, %+v", c.First, c.Second)
+			}
+		})
+	}
+}
+
+func TestAuditLogTampering(t *testing.T) {
+	var buf bytes.Buffer
+	log := auditlog.New(&buf)
+	sk, _ := ecdsa.GenerateKey(suites[0].curve, rand.Reader)
+	for _, slot := range []string{"1", "2", "3"} {
+		if _, err := log.Record(&sk.PublicKey, slot, []byte(slot), []byte("pi")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.SplitAfter(buf.String(), "\n")[:3]
+	for name, tampered := range map[string]string{
+		"dropped":   lines[0] + lines[2],
+		"reordered": lines[1] + lines[0] + lines[2],
+		"edited":    lines[0] + strings.Replace(lines[1], `"slot":"2"`, `"slot":"9"`, 1) + lines[2],
+		"truncated": lines[0] + lines[1][:20],
+	} {
+		var chainErr *auditlog.ChainError
+		if _, err := auditlog.Verify(strings.NewReader(tampered)); !errors.As(err, &chainErr) {
+			t.Errorf("%s: Verify() err = %v", name, err)
+		}
+		if _, err := auditlog.Resume(&bytes.Buffer{}, strings.NewReader(tampered)); err == nil {
+			t.Errorf("%s: Resume() succeeded", name)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestAuditLogWriteFailure(t *testing.T) {
+	vrf := auditlog.New(failingWriter{}).Wrap(suites[0].vrf, nil)
+	sk, _ := ecdsa.GenerateKey(suites[0].curve, rand.Reader)
+	if beta, pi, err := vrf.Prove(sk, []byte("alpha")); err == nil || beta != nil || pi != nil {
+		t.Fatalf("Prove() = %x, %x, %v, want an error", beta, pi, err)
+	}
+}
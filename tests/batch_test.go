@@ -0,0 +1,173 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+// readBatch loads the secp256k1 cases as batch items, with the expected betas.
+func readBatch(t *testing.T) ([]ecvrf.BatchItem, [][]byte) {
+	cases, err := readCases("../vectors/secp256_k1_sha256_tai.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		items []ecvrf.BatchItem
+		betas [][]byte
+	)
+	for _, c := range cases {
+		skBytes, _ := hex.DecodeString(c.Sk)
+		alpha, _ := hex.DecodeString(c.Alpha)
+		pi, _ := hex.DecodeString(c.Pi)
+		beta, _ := hex.DecodeString(c.Beta)
+
+		items = append(items, ecvrf.BatchItem{
+			PublicKey: secp256k1.PrivKeyFromBytes(skBytes).PubKey().ToECDSA(),
+			Alpha:     alpha,
+			Pi:        pi,
+		})
+		betas = append(betas, beta)
+	}
+	return items, betas
+}
+
+func Test_VerifyBatch(t *testing.T) {
+	items, betas := readBatch(t)
+	// repeat the cases to have more items than workers
+	for len(items) < 50 {
+		items = append(items, items...)
+		betas = append(betas, betas...)
+	}
+	vrf := ecvrf.NewSecp256k1Sha256Tai()
+
+	for _, n := range []int{0, 1, 4, 100} {
+		results, err := ecvrf.VerifyBatch(vrf, items, ecvrf.WithParallelism(n))
+		if err != nil {
+			t.Fatalf("VerifyBatch(parallelism=%v) error = %v", n, err)
+		}
+		for i, r := range results {
+			if r.Err != nil || !reflect.DeepEqual(r.Beta, betas[i]) {
+				t.Fatalf("VerifyBatch(parallelism=%v)[%v] = %x, %v, want %x", n, i, r.Beta, r.Err, betas[i])
+			}
+		}
+	}
+}
+
+func Test_VerifyBatch_failure(t *testing.T) {
+	items, _ := readBatch(t)
+	bad := items[1]
+	bad.Pi = append([]byte{}, bad.Pi...)
+	bad.Pi[len(bad.Pi)-1] ^= 1
+	items[1] = bad
+	vrf := ecvrf.NewSecp256k1Sha256Tai()
+
+	results, err := ecvrf.VerifyBatch(vrf, items, ecvrf.WithParallelism(4))
+	if err == nil || results[1].Err != err {
+		t.Fatalf("VerifyBatch() error = %v, want the error of item 1", err)
+	}
+	for i, r := range results {
+		if i != 1 && r.Err != nil {
+			t.Fatalf("VerifyBatch()[%v] error = %v", i, r.Err)
+		}
+	}
+
+	// with fail fast, items after the failure are canceled when running sequentially
+	results, err = ecvrf.VerifyBatch(vrf, items, ecvrf.WithFailFast())
+	if err == nil || results[1].Err != err {
+		t.Fatalf("VerifyBatch() error = %v, want the error of item 1", err)
+	}
+	if results[0].Err != nil {
+		t.Fatalf("VerifyBatch()[0] error = %v", results[0].Err)
+	}
+	for i := 2; i < len(results); i++ {
+		if results[i].Err != ecvrf.ErrBatchCanceled {
+			t.Fatalf("VerifyBatch()[%v] error = %v, want ErrBatchCanceled", i, results[i].Err)
+		}
+	}
+}
+
+func Test_ProveBatch(t *testing.T) {
+	cases, err := readCases("../vectors/secp256_k1_sha256_tai.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	skBytes, _ := hex.DecodeString(cases[0].Sk)
+	sk := secp256k1.PrivKeyFromBytes(skBytes).ToECDSA()
+
+	var alphas [][]byte
+	for i := 0; i < 20; i++ {
+		alphas = append(alphas, []byte{byte(i)})
+	}
+	vrf := ecvrf.NewSecp256k1Sha256Tai()
+
+	results, err := ecvrf.ProveBatch(vrf, sk, alphas, ecvrf.WithParallelism(4))
+	if err != nil {
+		t.Fatalf("ProveBatch() error = %v", err)
+	}
+	for i, r := range results {
+		beta, pi, _ := vrf.Prove(sk, alphas[i])
+		if r.Err != nil || !reflect.DeepEqual(r.Beta, beta) || !reflect.DeepEqual(r.Pi, pi) {
+			t.Fatalf("ProveBatch()[%v] = %x, %x, %v, want %x, %x", i, r.Beta, r.Pi, r.Err, beta, pi)
+		}
+	}
+}
+
+// cancelingVRF cancels the context of a batch at its n-th verification.
+type cancelingVRF struct {
+	ecvrf.VRF
+	n      int
+	cancel context.CancelFunc
+}
+
+func (v *cancelingVRF) Verify(pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error) {
+	if v.n--; v.n == 0 {
+		v.cancel()
+	}
+	return v.VRF.Verify(pk, alpha, pi)
+}
+
+func Test_VerifyBatchContext(t *testing.T) {
+	items, _ := readBatch(t)
+	vrf := ecvrf.NewSecp256k1Sha256Tai()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := ecvrf.VerifyBatchContext(ctx, vrf, items, ecvrf.WithParallelism(4))
+	if err != context.Canceled {
+		t.Fatalf("VerifyBatchContext() error = %v, want context.Canceled", err)
+	}
+	for i, r := range results {
+		if r.Err != context.Canceled {
+			t.Fatalf("VerifyBatchContext()[%v] error = %v, want context.Canceled", i, r.Err)
+		}
+	}
+
+	// items after the cancellation are skipped when running sequentially
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	results, err = ecvrf.VerifyBatchContext(ctx, &cancelingVRF{vrf, 2, cancel}, items)
+	if err != context.Canceled || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("VerifyBatchContext() error = %v, want context.Canceled after item 1", err)
+	}
+	for i := 2; i < len(results); i++ {
+		if results[i].Err != context.Canceled {
+			t.Fatalf("VerifyBatchContext()[%v] error = %v, want context.Canceled", i, results[i].Err)
+		}
+	}
+
+	cases, _ := readCases("../vectors/secp256_k1_sha256_tai.json")
+	skBytes, _ := hex.DecodeString(cases[0].Sk)
+	sk := secp256k1.PrivKeyFromBytes(skBytes).ToECDSA()
+	if _, err := ecvrf.ProveBatchContext(ctx, vrf, sk, [][]byte{{1}, {2}}); err != context.Canceled {
+		t.Fatalf("ProveBatchContext() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/beacon"
+)
+
+func TestBeacon(t *testing.T) {
+	vrf := ecvrf.NewP256Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	cfg := beacon.Config{
+		VRF:         vrf,
+		PublicKey:   &sk.PublicKey,
+		Genesis:     []byte("genesis"),
+		GenesisTime: time.Now(),
+		Period:      10 * time.Millisecond,
+	}
+	follower, err := beacon.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	gossiped := make(chan error, 100)
+	producer, err := beacon.New(cfg,
+		beacon.WithProver(func(alpha []byte) ([]byte, []byte, error) { return vrf.Prove(sk, alpha) }),
+		beacon.WithGossip(func(r *beacon.Round) {
+			gossiped <- follower.Receive(r)
+			if r.Number == 5 {
+				cancel()
+			}
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := producer.Run(ctx); err != context.Canceled {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := <-gossiped; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if follower.Latest().Number != 5 {
+		t.Fatal("rounds not received")
+	}
+
+	history := producer.History(1, 5)
+	if err := cfg.VerifyHistory(cfg.Genesis, history); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.VerifyHistory(history[1].Beta, history[2:]); err != nil {
+		t.Fatal("history from a checkpoint rejected", err)
+	}
+	if cfg.VerifyHistory(cfg.Genesis, history[1:]) == nil {
+		t.Fatal("history of another genesis accepted")
+	}
+	tampered := *history[3]
+	tampered.Beta = history[2].Beta
+	if cfg.VerifyHistory(history[2].Beta, []*beacon.Round{&tampered}) == nil {
+		t.Fatal("tampered round accepted")
+	}
+
+	// a late node syncs from the history
+	late, _ := beacon.New(cfg)
+	if err := late.Receive(history[4]); err != beacon.ErrMissingRounds {
+		t.Fatal("gap not reported", err)
+	}
+	for _, r := range history {
+		if err := late.Receive(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := late.Receive(history[4]); err != nil {
+		t.Fatal("duplicate round rejected", err)
+	}
+
+	// rounds from the future are rejected
+	alpha := beacon.Alpha(1, cfg.Genesis)
+	beta, pi, _ := vrf.Prove(sk, alpha)
+	future := cfg
+	future.GenesisTime = time.Now().Add(time.Hour)
+	early, _ := beacon.New(future)
+	if early.Receive(&beacon.Round{Number: 1, Beta: beta, Pi: pi}) == nil {
+		t.Fatal("round before its time accepted")
+	}
+	if cfg.RoundAt(cfg.TimeOf(7)) != 7 || cfg.RoundAt(cfg.GenesisTime.Add(-time.Nanosecond)) != 0 {
+		t.Fatal("wrong round timing")
+	}
+}
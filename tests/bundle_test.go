@@ -0,0 +1,88 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/bundle"
+)
+
+func TestBundle(t *testing.T) {
+	vrf := ecvrf.NewP256Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var alphas [][]byte
+	for i := 0; i < 7; i++ {
+		alphas = append(alphas, []byte(fmt.Sprint("slot ", i)))
+	}
+
+	b, err := bundle.New(vrf, sk, alphas, ecvrf.WithParallelism(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(b)
+	var decoded bundle.Bundle
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.Verify(vrf, &sk.PublicKey, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Verify(vrf, &other.PublicKey, &decoded) == nil {
+		t.Error("bundle of another key accepted")
+	}
+
+	for i := range alphas {
+		p, err := b.Proof(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		beta, err := bundle.VerifyEntry(vrf, &sk.PublicKey, b.Root, p)
+		if err != nil {
+			t.Fatalf("VerifyEntry(%v) error = %v", i, err)
+		}
+		if !bytes.Equal(beta, b.Entries[i].Beta) {
+			t.Fatalf("VerifyEntry(%v) returned another beta", i)
+		}
+	}
+	if _, err := b.Proof(len(alphas)); err == nil {
+		t.Error("Proof() accepted an index out of range")
+	}
+
+	p, _ := b.Proof(3)
+	tampered := *p
+	tampered.Index = 4
+	if _, err := bundle.VerifyEntry(vrf, &sk.PublicKey, b.Root, &tampered); err == nil {
+		t.Error("entry at another index accepted")
+	}
+	tampered = *p
+	tampered.Entry.Beta = b.Entries[4].Beta
+	if _, err := bundle.VerifyEntry(vrf, &sk.PublicKey, b.Root, &tampered); err == nil {
+		t.Error("entry with another beta accepted")
+	}
+	// a valid proof of the key, but not in the bundle
+	beta, pi, _ := vrf.Prove(sk, []byte("slot 9"))
+	tampered = *p
+	tampered.Entry = bundle.Entry{Alpha: []byte("slot 9"), Pi: pi, Beta: beta}
+	if _, err := bundle.VerifyEntry(vrf, &sk.PublicKey, b.Root, &tampered); err == nil {
+		t.Error("entry outside the bundle accepted")
+	}
+
+	decoded.Entries[2] = tampered.Entry
+	if bundle.Verify(vrf, &sk.PublicKey, &decoded) == nil {
+		t.Error("bundle with a replaced entry accepted")
+	}
+	decoded.Root = bundle.Root(decoded.Entries)
+	if err := bundle.Verify(vrf, &sk.PublicKey, &decoded); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"math"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestChain(t *testing.T) {
+	vrf := ecvrf.NewSecp256k1Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	seed := []byte("genesis")
+
+	chain := ecvrf.NewChain(vrf, sk, seed)
+	var links []*ecvrf.ChainLink
+	for i := 0; i < 10; i++ {
+		l, err := chain.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		links = append(links, l)
+	}
+	// beta_1 is the output of seed || 0
+	if beta, _, _ := vrf.Prove(sk, ecvrf.ChainAlpha(0, seed)); !bytes.Equal(beta, links[0].Beta) {
+		t.Fatal("the first link isn't the output of the seed")
+	}
+
+	genesis := ecvrf.Checkpoint{Index: 0, Beta: seed}
+	cp, err := ecvrf.VerifyChain(vrf, &sk.PublicKey, genesis, links, ecvrf.WithParallelism(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := chain.Checkpoint(); cp.Index != want.Index || !bytes.Equal(cp.Beta, want.Beta) {
+		t.Fatalf("VerifyChain() = %v, want %v", cp, want)
+	}
+
+	// a verifier resumes from a checkpoint, as the prover does
+	mid, err := ecvrf.VerifyChain(vrf, &sk.PublicKey, genesis, links[:4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := ecvrf.ResumeChain(vrf, sk, mid)
+	l, err := resumed.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Index != 5 || !bytes.Equal(l.Beta, links[4].Beta) {
+		t.Fatal("the resumed chain differs")
+	}
+	if _, err := ecvrf.VerifyChain(vrf, &sk.PublicKey, mid, links[4:]); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ecvrf.VerifyChain(vrf, &sk.PublicKey, ecvrf.Checkpoint{Index: 0, Beta: []byte("other")}, links); err == nil {
+		t.Error("chain of another seed accepted")
+	}
+	if _, err := ecvrf.VerifyChain(vrf, &sk.PublicKey, genesis, append([]*ecvrf.ChainLink{links[1]}, links[2:]...)); err == nil {
+		t.Error("chain with a missing link accepted")
+	}
+	tampered := append([]*ecvrf.ChainLink{}, links...)
+	bad := *links[6]
+	bad.Beta = append([]byte{}, bad.Beta...)
+	bad.Beta[0] ^= 1
+	tampered[6] = &bad
+	if _, err := ecvrf.VerifyChain(vrf, &sk.PublicKey, genesis, tampered); err == nil {
+		t.Error("chain with an altered beta accepted")
+	}
+
+	// hostile inputs are rejected, without panicking
+	withNil := append([]*ecvrf.ChainLink{}, links...)
+	withNil[5] = nil
+	if _, err := ecvrf.VerifyChain(vrf, &sk.PublicKey, genesis, withNil); err == nil {
+		t.Error("chain with a nil link accepted")
+	}
+	last := links[len(links)-1]
+	wrapped := &ecvrf.ChainLink{Index: 0, Beta: last.Beta, Pi: last.Pi}
+	if _, err := ecvrf.VerifyChain(vrf, &sk.PublicKey, ecvrf.Checkpoint{Index: math.MaxUint64, Beta: seed}, []*ecvrf.ChainLink{wrapped}); err == nil {
+		t.Error("chain with a wrapped index accepted")
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/commitreveal"
+)
+
+func TestCommitReveal(t *testing.T) {
+	vrf := ecvrf.NewSecp256k1Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	alpha := []byte("epoch 12")
+	salt, err := commitreveal.NewSalt(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, err := commitreveal.Commit(&sk.PublicKey, alpha, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := commitreveal.Reveal(vrf, sk, alpha, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := commitreveal.Verify(vrf, &sk.PublicKey, commitment, o); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := commitreveal.Verify(vrf, &other.PublicKey, commitment, o); err == nil {
+		t.Error("opening for another key accepted")
+	}
+
+	// another input, with a valid proof of the key
+	changed, _ := commitreveal.Reveal(vrf, sk, []byte("epoch 13"), salt)
+	if _, err := commitreveal.Verify(vrf, &sk.PublicKey, commitment, changed); err == nil {
+		t.Error("opening of another input accepted")
+	}
+	tampered := *o
+	tampered.Salt = append([]byte{}, o.Salt...)
+	tampered.Salt[0] ^= 1
+	if _, err := commitreveal.Verify(vrf, &sk.PublicKey, commitment, &tampered); err == nil {
+		t.Error("opening with another salt accepted")
+	}
+	tampered = *o
+	tampered.Beta = changed.Beta
+	if _, err := commitreveal.Verify(vrf, &sk.PublicKey, commitment, &tampered); err == nil {
+		t.Error("opening with another beta accepted")
+	}
+	// the length prefix keeps alpha apart from the salt
+	shifted, _ := commitreveal.Commit(&sk.PublicKey, append(alpha, salt[0]), append(salt[1:], 0))
+	if string(shifted) == string(commitment) {
+		t.Error("commitments of shifted inputs collide")
+	}
+	if _, err := commitreveal.Commit(&sk.PublicKey, alpha, salt[:commitreveal.SaltSize-1]); err == nil {
+		t.Error("short salt accepted")
+	}
+}
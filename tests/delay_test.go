@@ -0,0 +1,82 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/delay"
+)
+
+func TestDelay(t *testing.T) {
+	vrf := ecvrf.NewP256Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	// the factors are dropped, as a setup ceremony would
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdf, err := delay.NewWesolowski(rsaKey.N, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := delay.Reveal(vrf, sk, []byte("round 7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := delay.Complete(vdf, out); err != nil {
+		t.Fatal(err)
+	}
+	randomness, err := delay.Verify(vrf, &sk.PublicKey, vdf, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(randomness, out.Randomness()) {
+		t.Error("Verify() returned another randomness")
+	}
+	again, err := delay.Prove(vrf, sk, vdf, []byte("round 7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Y, out.Y) || !bytes.Equal(again.VDFProof, out.VDFProof) {
+		t.Error("the VDF isn't deterministic")
+	}
+
+	y := new(big.Int).SetBytes(out.Y)
+	y.Exp(y, big.NewInt(2), rsaKey.N)
+	for _, bad := range []func(o *delay.Output){
+		func(o *delay.Output) { o.Y = y.FillBytes(make([]byte, len(o.Y))) },
+		func(o *delay.Output) {
+			o.Y = new(big.Int).Sub(rsaKey.N, new(big.Int).SetBytes(o.Y)).FillBytes(make([]byte, len(o.Y)))
+		},
+		func(o *delay.Output) {
+			o.VDFProof = append([]byte{}, o.VDFProof...)
+			o.VDFProof[len(o.VDFProof)-1] ^= 1
+		},
+		func(o *delay.Output) { o.Beta = append([]byte{}, o.Beta...); o.Beta[0] ^= 1 },
+		func(o *delay.Output) { o.Alpha = []byte("round 8") },
+	} {
+		tampered := *out
+		bad(&tampered)
+		if _, err := delay.Verify(vrf, &sk.PublicKey, vdf, &tampered); err == nil {
+			t.Error("tampered output accepted")
+		}
+	}
+	if _, err := delay.Verify(vrf, &other.PublicKey, vdf, out); err == nil {
+		t.Error("output of another key accepted")
+	}
+	slower, _ := delay.NewWesolowski(rsaKey.N, 2001)
+	if _, err := delay.Verify(vrf, &sk.PublicKey, slower, out); err == nil {
+		t.Error("output of another delay accepted")
+	}
+}
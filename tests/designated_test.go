@@ -0,0 +1,79 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestDesignatedVerifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256()},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				sk, _       = ecdsa.GenerateKey(tt.curve, rand.Reader)
+				verifier, _ = ecdsa.GenerateKey(tt.curve, rand.Reader)
+				other, _    = ecdsa.GenerateKey(tt.curve, rand.Reader)
+				alpha       = []byte("Hello VeChain")
+			)
+			beta, pi, err := ecvrf.ProveDesignated(tt.vrf, sk, &verifier.PublicKey, alpha)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pi) != 129 {
+				t.Fatalf("len(pi) = %v, want 129", len(pi))
+			}
+			if want, _, _ := tt.vrf.Prove(sk, alpha); !bytes.Equal(beta, want) {
+				t.Errorf("beta differs from the one of Prove")
+			}
+			got, err := ecvrf.VerifyDesignated(tt.vrf, &sk.PublicKey, &verifier.PublicKey, alpha, pi)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, beta) {
+				t.Errorf("VerifyDesignated() = %x, want %x", got, beta)
+			}
+
+			if _, err := ecvrf.VerifyDesignated(tt.vrf, &sk.PublicKey, &other.PublicKey, alpha, pi); err == nil {
+				t.Errorf("VerifyDesignated() accepted the proof for another verifier")
+			}
+			if _, err := ecvrf.VerifyDesignated(tt.vrf, &sk.PublicKey, &verifier.PublicKey, []byte("other"), pi); err == nil {
+				t.Errorf("VerifyDesignated() accepted the proof of another input")
+			}
+			for i := range pi {
+				bad := append([]byte{}, pi...)
+				bad[i] ^= 1
+				if _, err := ecvrf.VerifyDesignated(tt.vrf, &sk.PublicKey, &verifier.PublicKey, alpha, bad); err == nil {
+					t.Fatalf("VerifyDesignated() accepted the proof with octet %v flipped", i)
+				}
+			}
+
+			// the verifier makes proofs of other outputs, which third parties can't tell apart
+			fakeBeta, fakePi, err := ecvrf.SimulateDesignated(tt.vrf, &sk.PublicKey, verifier, alpha, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(fakeBeta, beta) {
+				t.Errorf("simulated beta is the real one")
+			}
+			if got, err := ecvrf.VerifyDesignated(tt.vrf, &sk.PublicKey, &verifier.PublicKey, alpha, fakePi); err != nil || !bytes.Equal(got, fakeBeta) {
+				t.Errorf("VerifyDesignated() = %x, %v for the simulated proof", got, err)
+			}
+		})
+	}
+}
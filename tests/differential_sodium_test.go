@@ -0,0 +1,52 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build differential && libsodium
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/tests/sodium"
+)
+
+func Test_Differential_Libsodium(t *testing.T) {
+	n, err := strconv.Atoi(diffEnv("ECVRF_DIFF_CASES", "1000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		vrf  = ecvrf.NewEd25519Sha512Elligator2()
+		seed = diffEnv("ECVRF_DIFF_SEED", "go-ecvrf")
+	)
+	for i := 0; i < n; i++ {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(i))
+		h := sha256.Sum256(append([]byte(seed+"/ed25519"), b[:]...))
+		sk := ed25519.NewKeyFromSeed(h[:])
+		alpha := bytes.Repeat(h[:1], i%300)
+
+		beta, pi, err := vrf.Prove(sk, alpha)
+		if err != nil {
+			t.Fatalf("Prove() error = %v", err)
+		}
+		wantBeta, wantPi, err := sodium.Prove(sk, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pi, wantPi) || !bytes.Equal(beta, wantBeta) {
+			t.Fatalf("case %d diverges from libsodium: seed=%x alpha=%x\n  go:        pi=%x beta=%x\n  libsodium: pi=%x beta=%x",
+				i, h, alpha, pi, beta, wantPi, wantBeta)
+		}
+		if got, err := sodium.Verify(sk.Public().(ed25519.PublicKey), alpha, pi); err != nil || !bytes.Equal(got, beta) {
+			t.Fatalf("case %d: libsodium rejects the proof: %v", i, err)
+		}
+	}
+}
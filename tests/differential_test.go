@@ -0,0 +1,257 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build differential
+
+package tests
+
+// The differential harness compares this library with other implementations over a generated corpus:
+//
+//	go test -tags differential -run Differential
+//
+// ECVRF_DIFF_CASES sets the number of cases per suite and spec version (1000 by default), ECVRF_DIFF_SEED
+// the seed of the corpus, and ECVRF_PYTHON the interpreter of reference/ecvrf.py (python3 by default).
+// ECVRF_DIFF_VECTORS adds vector files of vrf-rs, as a comma-separated list of suite:path, where the
+// suite is p256 or secp256k1. Building with the libsodium tag as well compares Ed25519 proofs with
+// crypto_vrf_ietfdraft03 of libsodium. The first divergence is reported with all intermediate values.
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+// diffCase is an input of the corpus, in the format read by reference/ecvrf.py.
+type diffCase struct {
+	Suite string `json:"suite"`
+	Spec  string `json:"spec"`
+	Sk    string `json:"sk"`
+	Alpha string `json:"alpha"`
+}
+
+// intermediates are the values computed by Prove, hex encoded, in the order of the specification.
+type intermediates struct {
+	H     string `json:"H"`
+	K     string `json:"k"`
+	Gamma string `json:"gamma"`
+	U     string `json:"U"`
+	V     string `json:"V"`
+	C     string `json:"c"`
+	S     string `json:"s"`
+	Pi    string `json:"pi"`
+	Beta  string `json:"beta"`
+}
+
+func (iv *intermediates) fields() [][2]string {
+	return [][2]string{
+		{"H", iv.H}, {"k", iv.K}, {"Gamma", iv.Gamma}, {"U", iv.U}, {"V", iv.V},
+		{"c", iv.C}, {"s", iv.S}, {"pi", iv.Pi}, {"beta", iv.Beta},
+	}
+}
+
+// diffSuites are the suites compared with the reference implementation.
+var diffSuites = map[string]struct {
+	curve elliptic.Curve
+	vrf   func(...ecvrf.Option) ecvrf.VRF
+}{
+	"p256":      {elliptic.P256(), ecvrf.NewP256Sha256Tai},
+	"secp256k1": {secp256k1.S256(), ecvrf.NewSecp256k1Sha256Tai},
+}
+
+var diffSpecs = map[string]ecvrf.SpecVersion{"draft06": ecvrf.Draft06, "rfc9381": ecvrf.RFC9381}
+
+func diffEnv(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// diffCorpus generates n cases of every suite and spec version, deterministically from the seed.
+func diffCorpus(t *testing.T, seed string, n int) []diffCase {
+	var (
+		sizes  = []int{0, 1, 31, 32, 33, 64, 255, 1024}
+		ctr    uint64
+		stream = func(size int) []byte {
+			var out []byte
+			for len(out) < size {
+				var b [8]byte
+				binary.BigEndian.PutUint64(b[:], ctr)
+				ctr++
+				h := sha256.Sum256(append([]byte(seed), b[:]...))
+				out = append(out, h[:]...)
+			}
+			return out[:size]
+		}
+		cases []diffCase
+	)
+	for _, suite := range []string{"p256", "secp256k1"} {
+		q := diffSuites[suite].curve.Params().N
+		for _, spec := range []string{"draft06", "rfc9381"} {
+			for i := 0; i < n; i++ {
+				sk := new(big.Int).SetBytes(stream(32))
+				if sk.Mod(sk, q).Sign() == 0 {
+					sk.SetInt64(1)
+				}
+				cases = append(cases, diffCase{
+					Suite: suite,
+					Spec:  spec,
+					Sk:    fmt.Sprintf("%064x", sk),
+					Alpha: hex.EncodeToString(stream(sizes[i%len(sizes)])),
+				})
+			}
+		}
+	}
+	return cases
+}
+
+// goIntermediates computes the intermediate values of the case with this library.
+func goIntermediates(t *testing.T, c diffCase) intermediates {
+	var (
+		suite    = diffSuites[c.Suite]
+		vrf      = suite.vrf(ecvrf.WithSpecVersion(diffSpecs[c.Spec]))
+		skInt, _ = new(big.Int).SetString(c.Sk, 16)
+		alpha, _ = hex.DecodeString(c.Alpha)
+		sk       = &ecdsa.PrivateKey{D: skInt}
+	)
+	sk.Curve = suite.curve
+	sk.X, sk.Y = suite.curve.ScalarBaseMult(skInt.Bytes())
+
+	hx, hy, err := vrf.EncodeToCurve(&sk.PublicKey, alpha)
+	if err != nil {
+		t.Fatalf("EncodeToCurve(%+v) error = %v", c, err)
+	}
+	H := elliptic.MarshalCompressed(suite.curve, hx, hy)
+	k := vrf.GenerateNonce(sk, H)
+	beta, pi, err := vrf.Prove(sk, alpha)
+	if err != nil {
+		t.Fatalf("Prove(%+v) error = %v", c, err)
+	}
+	ux, uy := suite.curve.ScalarBaseMult(k.Bytes())
+	vx, vy := suite.curve.ScalarMult(hx, hy, k.Bytes())
+	return intermediates{
+		H:     hex.EncodeToString(H),
+		K:     fmt.Sprintf("%064x", k),
+		Gamma: hex.EncodeToString(pi[:33]),
+		U:     hex.EncodeToString(elliptic.MarshalCompressed(suite.curve, ux, uy)),
+		V:     hex.EncodeToString(elliptic.MarshalCompressed(suite.curve, vx, vy)),
+		C:     hex.EncodeToString(pi[33:49]),
+		S:     hex.EncodeToString(pi[49:]),
+		Pi:    hex.EncodeToString(pi),
+		Beta:  hex.EncodeToString(beta),
+	}
+}
+
+// reportDivergence fails with the table of the intermediate values of both sides, if they differ.
+func reportDivergence(t *testing.T, other string, index int, c diffCase, got, want intermediates) {
+	if got == want {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "case %d diverges from %s: suite=%s spec=%s sk=%s alpha=%s\n", index, other, c.Suite, c.Spec, c.Sk, c.Alpha)
+	wantFields := want.fields()
+	for i, f := range got.fields() {
+		mark := " "
+		if f[1] != wantFields[i][1] {
+			mark = "!"
+		}
+		fmt.Fprintf(&b, "%s %-5s go:    %s\n", mark, f[0], f[1])
+		fmt.Fprintf(&b, "%s %-5s %-6s %s\n", mark, "", other+":", wantFields[i][1])
+	}
+	t.Fatal(b.String())
+}
+
+// runReference computes the intermediate values of the cases with reference/ecvrf.py.
+func runReference(t *testing.T, cases []diffCase) []intermediates {
+	python, err := exec.LookPath(diffEnv("ECVRF_PYTHON", "python3"))
+	if err != nil {
+		t.Skipf("no python interpreter: %v", err)
+	}
+	var in bytes.Buffer
+	enc := json.NewEncoder(&in)
+	for _, c := range cases {
+		enc.Encode(c)
+	}
+	cmd := exec.Command(python, "reference/ecvrf.py")
+	cmd.Stdin = &in
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("reference/ecvrf.py error = %v", err)
+	}
+	var (
+		results []intermediates
+		scanner = bufio.NewScanner(bytes.NewReader(out))
+	)
+	for scanner.Scan() {
+		var r intermediates
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+	if len(results) != len(cases) {
+		t.Fatalf("reference/ecvrf.py returned %d results of %d cases", len(results), len(cases))
+	}
+	return results
+}
+
+func Test_Differential_Reference(t *testing.T) {
+	n, err := strconv.Atoi(diffEnv("ECVRF_DIFF_CASES", "1000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := diffCorpus(t, diffEnv("ECVRF_DIFF_SEED", "go-ecvrf"), n)
+	want := runReference(t, cases)
+	for i, c := range cases {
+		reportDivergence(t, "python", i, c, goIntermediates(t, c), want[i])
+	}
+}
+
+func Test_Differential_VrfRs(t *testing.T) {
+	files := []string{"secp256k1:../vectors/secp256_k1_sha256_tai.json", "p256:../vectors/p256_sha256_tai.json"}
+	if extra := os.Getenv("ECVRF_DIFF_VECTORS"); extra != "" {
+		files = append(files, strings.Split(extra, ",")...)
+	}
+	var (
+		cases   []diffCase
+		vectors []Case
+	)
+	for _, f := range files {
+		parts := strings.SplitN(f, ":", 2)
+		if _, ok := diffSuites[parts[0]]; !ok || len(parts) != 2 {
+			t.Fatalf("invalid vector file %q", f)
+		}
+		vs, err := readCases(parts[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range vs {
+			cases = append(cases, diffCase{Suite: parts[0], Spec: "draft06", Sk: v.Sk, Alpha: v.Alpha})
+			vectors = append(vectors, v)
+		}
+	}
+	// the vectors only carry pi and beta, the reference fills in the rest
+	want := runReference(t, cases)
+	for i, c := range cases {
+		if want[i].Pi != vectors[i].Pi || want[i].Beta != vectors[i].Beta {
+			t.Fatalf("vector %d: reference/ecvrf.py = %s, %s, want %s, %s", i, want[i].Pi, want[i].Beta, vectors[i].Pi, vectors[i].Beta)
+		}
+		reportDivergence(t, "vrf-rs", i, c, goIntermediates(t, c), want[i])
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestDLEQ(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256()},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256()},
+		{"p256 rfc9381", ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381)), elliptic.P256()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			other, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			alpha := []byte("Hello VeChain")
+			hx, hy, err := tt.vrf.EncodeToCurve(&sk.PublicKey, alpha)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := [2]*big.Int{hx, hy}
+
+			gamma, proof, err := ecvrf.ProveDLEQ(tt.vrf, sk, h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(proof) != 48 {
+				t.Fatalf("len(proof) = %v, want 48", len(proof))
+			}
+			if wx, wy := tt.curve.ScalarMult(hx, hy, sk.D.Bytes()); gamma[0].Cmp(wx) != 0 || gamma[1].Cmp(wy) != 0 {
+				t.Fatalf("Gamma is not x*H")
+			}
+			if err := ecvrf.VerifyDLEQ(tt.vrf, &sk.PublicKey, h, gamma, proof); err != nil {
+				t.Fatalf("VerifyDLEQ() error = %v", err)
+			}
+
+			if err := ecvrf.VerifyDLEQ(tt.vrf, &other.PublicKey, h, gamma, proof); err == nil {
+				t.Errorf("VerifyDLEQ() accepted the proof for another key")
+			}
+			gx, gy := tt.curve.Add(gamma[0], gamma[1], tt.curve.Params().Gx, tt.curve.Params().Gy)
+			if err := ecvrf.VerifyDLEQ(tt.vrf, &sk.PublicKey, h, [2]*big.Int{gx, gy}, proof); err == nil {
+				t.Errorf("VerifyDLEQ() accepted the proof for another Gamma")
+			}
+			for i := range proof {
+				bad := append([]byte{}, proof...)
+				bad[i] ^= 1
+				if err := ecvrf.VerifyDLEQ(tt.vrf, &sk.PublicKey, h, gamma, bad); err == nil {
+					t.Fatalf("VerifyDLEQ() accepted the proof with octet %v flipped", i)
+				}
+			}
+			if _, _, err := ecvrf.ProveDLEQ(tt.vrf, sk, [2]*big.Int{hx, new(big.Int).Add(hy, big.NewInt(1))}); err == nil {
+				t.Errorf("ProveDLEQ() accepted H off the curve")
+			}
+
+			// c || s of a VRF proof, whose statement is the same, isn't a DLEQ proof
+			_, pi, err := tt.vrf.Prove(sk, alpha)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ecvrf.VerifyDLEQ(tt.vrf, &sk.PublicKey, h, gamma, pi[33:]); err == nil {
+				t.Errorf("VerifyDLEQ() accepted the challenge and response of a VRF proof")
+			}
+		})
+	}
+}
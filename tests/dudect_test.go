@@ -0,0 +1,196 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build dudect
+
+package tests
+
+// The timing harness follows dudect (Reparaz, Balasch and Verbauwhede, "Dude, is my code constant
+// time?"): Prove is timed over inputs of two classes, a fixed secret and random secrets, drawn in
+// random order, and Welch's t-test compares the two distributions, also cropped at percentiles to
+// drop the measurements disturbed by the system:
+//
+//	go test -tags dudect -run ConstantTime -v
+//
+// ECVRF_DUDECT_MEASUREMENTS sets the number of measurements per suite (20000 by default), and
+// ECVRF_DUDECT_T the largest |t| accepted (10 by default, the threshold where dudect reports a
+// definite leak; values above 4.5 are already suspicious). Alphas are random in both classes, so
+// the number of try-and-increment rounds, which depends on the public key, has the same
+// distribution in both.
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"math"
+	"math/big"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+func dudectEnv(name string, def float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(name), 64); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+// welch accumulates the measurements of both classes, by Welford's online algorithm.
+type welch struct {
+	n, mean, m2 [2]float64
+}
+
+func (w *welch) push(class int, x float64) {
+	w.n[class]++
+	d := x - w.mean[class]
+	w.mean[class] += d / w.n[class]
+	w.m2[class] += d * (x - w.mean[class])
+}
+
+// t returns the statistic of Welch's t-test, 0 while a class has too few measurements.
+func (w *welch) t() float64 {
+	if w.n[0] < 2 || w.n[1] < 2 {
+		return 0
+	}
+	v0, v1 := w.m2[0]/(w.n[0]-1), w.m2[1]/(w.n[1]-1)
+	return (w.mean[0] - w.mean[1]) / math.Sqrt(v0/w.n[0]+v1/w.n[1])
+}
+
+// maxT returns the largest |t| of the measurements, uncropped and cropped at the percentiles of
+// dudect, 1 - 0.5^(10(i+1)/100), of all measurements.
+func maxT(classes []int, times []float64) float64 {
+	sorted := append([]float64{}, times...)
+	sort.Float64s(sorted)
+	crops := []float64{math.Inf(1)}
+	for i := 0; i < 100; i++ {
+		p := 1 - math.Pow(0.5, 10*float64(i+1)/100)
+		crops = append(crops, sorted[int(p*float64(len(sorted)-1))])
+	}
+
+	var max float64
+	for _, crop := range crops {
+		var w welch
+		for i, x := range times {
+			if x <= crop {
+				w.push(classes[i], x)
+			}
+		}
+		if t := math.Abs(w.t()); t > max {
+			max = t
+		}
+	}
+	return max
+}
+
+// checkConstantTime times prove(i) for each input, where classes[i] is 0 for the fixed secret and
+// 1 for a random one, and fails if the largest |t| of the two classes exceeds thresh.
+func checkConstantTime(t *testing.T, classes []int, thresh float64, prove func(i int)) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	runtime.GC()
+
+	times := make([]float64, len(classes))
+	for i := range classes {
+		start := time.Now()
+		prove(i)
+		times[i] = float64(time.Since(start))
+	}
+	tmax := maxT(classes, times)
+	t.Logf("max |t| = %.2f over %d measurements", tmax, len(times))
+	if tmax > thresh {
+		t.Fatalf("max |t| = %.2f > %.2f: Prove timing depends on the secret", tmax, thresh)
+	}
+}
+
+// dudectClasses draws the class of each of n measurements.
+func dudectClasses(t *testing.T, n int) []int {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	classes := make([]int, n)
+	for i := range b {
+		classes[i] = int(b[i] & 1)
+	}
+	return classes
+}
+
+func dudectAlphas(t *testing.T, n int) [][]byte {
+	alphas := make([][]byte, n)
+	for i := range alphas {
+		alphas[i] = make([]byte, 32)
+		if _, err := rand.Read(alphas[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return alphas
+}
+
+func TestConstantTimeProve(t *testing.T) {
+	var (
+		n      = int(dudectEnv("ECVRF_DUDECT_MEASUREMENTS", 20000))
+		thresh = dudectEnv("ECVRF_DUDECT_T", 10)
+	)
+	for _, s := range suites {
+		t.Run(s.name, func(t *testing.T) {
+			// the fixed secret is 1, the scalar most unlike random ones, which would reveal a
+			// multiplication depending on the bit length or the weight of the scalar
+			var (
+				classes = dudectClasses(t, n)
+				alphas  = dudectAlphas(t, n)
+				keys    = make([]*ecdsa.PrivateKey, n)
+				fixed   = &ecdsa.PrivateKey{D: big.NewInt(1)}
+			)
+			fixed.Curve = s.curve
+			fixed.X, fixed.Y = s.curve.Params().Gx, s.curve.Params().Gy
+			for i := range keys {
+				keys[i] = fixed
+				if classes[i] == 1 {
+					sk, err := ecdsa.GenerateKey(s.curve, rand.Reader)
+					if err != nil {
+						t.Fatal(err)
+					}
+					keys[i] = sk
+				}
+			}
+
+			checkConstantTime(t, classes, thresh, func(i int) {
+				if _, _, err := s.vrf.Prove(keys[i], alphas[i]); err != nil {
+					t.Fatal(err)
+				}
+			})
+		})
+	}
+
+	t.Run("ed25519sha512ell2", func(t *testing.T) {
+		var (
+			v       = ecvrf.NewEd25519Sha512Ell2()
+			classes = dudectClasses(t, n)
+			alphas  = dudectAlphas(t, n)
+			keys    = make([]ed25519.PrivateKey, n)
+			fixed   = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+		)
+		for i := range keys {
+			keys[i] = fixed
+			if classes[i] == 1 {
+				_, sk, err := ed25519.GenerateKey(rand.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				keys[i] = sk
+			}
+		}
+
+		checkConstantTime(t, classes, thresh, func(i int) {
+			if _, _, err := v.Prove(keys[i], alphas[i]); err != nil {
+				t.Fatal(err)
+			}
+		})
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build go1.20
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestECDHKeys(t *testing.T) {
+	var (
+		v     = ecvrf.NewP256Sha256Tai()
+		alpha = []byte("Hello VeChain")
+	)
+	sk, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, pi, err := ecvrf.ProveECDH(v, sk, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ecvrf.VerifyECDH(v, sk.PublicKey(), alpha, pi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(beta, got) {
+		t.Errorf("beta of VerifyECDH differs from the one of ProveECDH")
+	}
+
+	// the proofs are those of the same key as an ecdsa key
+	c := elliptic.P256()
+	esk := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(sk.Bytes())}
+	esk.Curve = c
+	esk.X, esk.Y = c.ScalarBaseMult(sk.Bytes())
+	if _, pi2, _ := v.Prove(esk, alpha); !bytes.Equal(pi, pi2) {
+		t.Errorf("proof differs from the one of the ecdsa key")
+	}
+
+	if _, err := ecvrf.VerifyECDH(v, sk.PublicKey(), []byte("other"), pi); err == nil {
+		t.Errorf("VerifyECDH accepted the proof of another input")
+	}
+	other, _ := ecdh.P384().GenerateKey(rand.Reader)
+	if _, _, err := ecvrf.ProveECDH(v, other, alpha); err == nil {
+		t.Errorf("ProveECDH accepted a P-384 key with the P-256 suite")
+	}
+	x, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	if _, err := ecvrf.VerifyECDH(v, x.PublicKey(), alpha, pi); err == nil {
+		t.Errorf("VerifyECDH accepted an X25519 key")
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/ecvrftest"
+	"github.com/vechain/go-ecvrf/keymgmt"
+)
+
+func TestFakeVRF(t *testing.T) {
+	fake := ecvrftest.New()
+	sk, other := ecvrftest.NewKey("alice"), ecvrftest.NewKey("bob")
+	if !bytes.Equal(sk.D.Bytes(), ecvrftest.NewKey("alice").D.Bytes()) || sk.D.Cmp(other.D) == 0 {
+		t.Fatal("keys not deterministic")
+	}
+	alpha := []byte("sample")
+
+	beta, pi, err := fake.Prove(sk, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sha256.Sum256(append(append([]byte(nil), alpha...), elliptic.MarshalCompressed(sk.Curve, sk.X, sk.Y)...))
+	if !bytes.Equal(beta, want[:]) || len(pi) != ecvrftest.ProofSize {
+		t.Fatalf("beta = %x, want %x", beta, want)
+	}
+	if b, err := fake.ProofToHash(pi); err != nil || !bytes.Equal(b, beta) {
+		t.Fatal("ProofToHash", b, err)
+	}
+	if b, err := fake.NewVerifier(&sk.PublicKey).Verify(alpha, pi); err != nil || !bytes.Equal(b, beta) {
+		t.Fatal("proof rejected", err)
+	}
+	if _, err := fake.Verify(&other.PublicKey, alpha, pi); err == nil {
+		t.Fatal("proof of another key accepted")
+	}
+	if _, err := fake.Verify(&sk.PublicKey, []byte("other"), pi); err == nil {
+		t.Fatal("proof of another input accepted")
+	}
+
+	// forced failures
+	myErr := errors.New("my error")
+	fake.FailVerify(alpha, myErr)
+	if _, err := fake.Verify(&sk.PublicKey, alpha, pi); err != myErr {
+		t.Fatal("forced failure", err)
+	}
+	_, pi2, _ := fake.Prove(sk, []byte("other"))
+	if _, err := fake.Verify(&sk.PublicKey, []byte("other"), pi2); err != nil {
+		t.Fatal(err)
+	}
+	fake.FailAll(nil)
+	if _, err := fake.Verify(&sk.PublicKey, []byte("other"), pi2); err != ecvrftest.ErrForced {
+		t.Fatal("forced failure", err)
+	}
+	fake.Reset()
+	if _, err := fake.Verify(&sk.PublicKey, alpha, pi); err != nil {
+		t.Fatal(err)
+	}
+
+	// in place of a real VRF
+	var vrf ecvrf.VRF = fake
+	m := keymgmt.New(vrf)
+	if err := m.Add(keymgmt.Key{ID: "k1", PublicKey: &sk.PublicKey, PrivateKey: sk}); err != nil {
+		t.Fatal(err)
+	}
+	_, _, pi, err = m.Prove(1, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.FailVerify(alpha, nil)
+	if _, _, err := m.Verify(1, alpha, pi); err == nil {
+		t.Fatal("forced failure ignored")
+	}
+}
+
+func TestProperties(t *testing.T) {
+	suites := []ecvrftest.Suite{
+		ecvrftest.ECDSASuite(ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256()),
+		ecvrftest.ECDSASuite(ecvrf.NewP256Sha256Tai(), elliptic.P256()),
+		ecvrftest.ECDSASuite(ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381)), elliptic.P256()),
+		ecvrftest.Ed25519Suite(ecvrf.NewEd25519Sha512Elligator2()),
+		ecvrftest.Ed25519Suite(ecvrf.NewEd25519Sha512Ell2()),
+		ecvrftest.Ed25519Suite(ecvrf.NewEd25519Sha512Ell2BatchCompat()),
+		ecvrftest.ECDSASuite(ecvrftest.New(), elliptic.P256()),
+	}
+	for _, s := range suites {
+		if err := ecvrftest.CheckProperties(s, ecvrftest.WithIterations(8)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a VRF whose output ignores the key is caught
+	broken := ecvrftest.ECDSASuite(ecvrftest.New(), elliptic.P256())
+	broken.Verify = func(pk interface{}, alpha, pi []byte) ([]byte, error) {
+		return pi[len(pi)-32:], nil
+	}
+	if err := ecvrftest.CheckProperties(broken); err == nil || !strings.Contains(err.Error(), "another key") {
+		t.Fatal("broken VRF passed", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"strconv"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestVerifyEd25519Batch(t *testing.T) {
+	var (
+		v     = ecvrf.NewEd25519Sha512Ell2BatchCompat()
+		items []ecvrf.Ed25519BatchItem
+		betas [][]byte
+	)
+	for i := 0; i < 8; i++ {
+		_, sk, _ := ed25519.GenerateKey(rand.Reader)
+		alpha := []byte(strconv.Itoa(i))
+		beta, pi, err := v.Prove(sk, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, ecvrf.Ed25519BatchItem{PublicKey: sk.Public().(ed25519.PublicKey), Alpha: alpha, Pi: pi})
+		betas = append(betas, beta)
+	}
+	results, err := ecvrf.VerifyEd25519Batch(v, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if r.Err != nil || !bytes.Equal(r.Beta, betas[i]) {
+			t.Fatalf("result %v = %x, %v, want %x", i, r.Beta, r.Err, betas[i])
+		}
+	}
+
+	// a bad item fails the batch, and is the only one reported
+	for _, off := range []int{0, 40, 70, 100} {
+		bad := append([]ecvrf.Ed25519BatchItem{}, items...)
+		bad[5].Pi = append([]byte{}, items[5].Pi...)
+		bad[5].Pi[off] ^= 1
+		results, err := ecvrf.VerifyEd25519Batch(v, bad)
+		if err == nil {
+			t.Fatalf("VerifyEd25519Batch() accepted the proof with octet %v flipped", off)
+		}
+		for i, r := range results {
+			if (r.Err != nil) != (i == 5) {
+				t.Fatalf("result %v = %v with item 5 invalid", i, r.Err)
+			}
+		}
+	}
+	swapped := append([]ecvrf.Ed25519BatchItem{}, items...)
+	swapped[2].Alpha, swapped[3].Alpha = items[3].Alpha, items[2].Alpha
+	if _, err := ecvrf.VerifyEd25519Batch(v, swapped); err == nil {
+		t.Fatalf("VerifyEd25519Batch() accepted swapped inputs")
+	}
+
+	// proofs of other VRF objects are verified one by one
+	compact := ecvrf.NewEd25519Sha512Ell2()
+	for i := range items {
+		items[i].Pi, err = ecvrf.Ed25519ProofFromBatchCompat(items[i].PublicKey, items[i].Alpha, items[i].Pi)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if results, err := ecvrf.VerifyEd25519Batch(compact, items); err != nil || !bytes.Equal(results[0].Beta, betas[0]) {
+		t.Fatalf("VerifyEd25519Batch() = %x, %v with compact proofs", results[0].Beta, err)
+	}
+}
+
+func TestEd25519ProofConversion(t *testing.T) {
+	var (
+		compact = ecvrf.NewEd25519Sha512Ell2()
+		batch   = ecvrf.NewEd25519Sha512Ell2BatchCompat()
+		alpha   = []byte("Hello VeChain")
+	)
+	_, sk, _ := ed25519.GenerateKey(rand.Reader)
+	pk := sk.Public().(ed25519.PublicKey)
+	beta, pi, err := compact.Prove(sk, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, batchPi, err := batch.Prove(sk, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ecvrf.Ed25519ProofToBatchCompat(pk, alpha, pi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, batchPi) {
+		t.Errorf("Ed25519ProofToBatchCompat() = %x, want %x", got, batchPi)
+	}
+	if got, err = ecvrf.Ed25519ProofFromBatchCompat(pk, alpha, batchPi); err != nil || !bytes.Equal(got, pi) {
+		t.Errorf("Ed25519ProofFromBatchCompat() = %x, %v, want %x", got, err, pi)
+	}
+	if b, err := batch.Verify(pk, alpha, batchPi); err != nil || !bytes.Equal(b, beta) {
+		t.Errorf("beta of the batch-compatible proof differs")
+	}
+
+	bad := append([]byte{}, pi...)
+	bad[40] ^= 1
+	if _, err := ecvrf.Ed25519ProofToBatchCompat(pk, alpha, bad); err == nil {
+		t.Errorf("Ed25519ProofToBatchCompat() converted an invalid proof")
+	}
+	if _, err := ecvrf.Ed25519ProofFromBatchCompat(pk, alpha, pi); err == nil {
+		t.Errorf("Ed25519ProofFromBatchCompat() converted a compact proof")
+	}
+}
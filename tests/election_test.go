@@ -0,0 +1,85 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/election"
+)
+
+func TestElection(t *testing.T) {
+	vrf := ecvrf.NewP256Sha256Tai()
+	seed := []byte("epoch 7")
+	var validators []election.Validator
+	proofs := map[string][]byte{}
+	for i := 0; i < 10; i++ {
+		sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		id := fmt.Sprint("v", i)
+		validators = append(validators, election.Validator{ID: id, PublicKey: &sk.PublicKey, Weight: uint64(i)})
+		if i == 9 {
+			// withheld
+			continue
+		}
+		_, pi, err := vrf.Prove(sk, election.Alpha(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		proofs[id] = pi
+	}
+	// a proof of another epoch
+	proofs["v8"] = proofs["v7"]
+
+	r, err := election.Elect(vrf, validators, seed, proofs, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Committee) != 4 || len(r.Entries) != 10 {
+		t.Fatal("wrong selection", r.Committee)
+	}
+	for id, reason := range map[string]string{"v0": election.NoWeight, "v8": election.InvalidProof, "v9": election.NoProof} {
+		for _, e := range r.Entries {
+			if e.ID == id && e.Excluded != reason {
+				t.Fatal("wrong exclusion of", id, e.Excluded)
+			}
+		}
+		if r.Proposer == id {
+			t.Fatal("excluded validator elected", id)
+		}
+		for _, m := range r.Committee {
+			if m == id {
+				t.Fatal("excluded validator in the committee", id)
+			}
+		}
+	}
+	if err := election.Audit(vrf, validators, seed, proofs, 4, r); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := *r
+	tampered.Proposer = "v0"
+	if election.Audit(vrf, validators, seed, proofs, 4, &tampered) == nil {
+		t.Fatal("tampered proposer accepted")
+	}
+	tampered = *r
+	tampered.Committee = append([]string{r.Committee[1], r.Committee[0]}, r.Committee[2:]...)
+	if election.Audit(vrf, validators, seed, proofs, 4, &tampered) == nil {
+		t.Fatal("tampered committee accepted")
+	}
+	if election.Audit(vrf, validators, []byte("epoch 8"), proofs, 4, r) == nil {
+		t.Fatal("result of another epoch accepted")
+	}
+
+	if _, err := election.Elect(vrf, append(validators, validators[1]), seed, proofs, 4); err == nil {
+		t.Fatal("duplicate validator accepted")
+	}
+	if _, err := election.Elect(vrf, validators, seed, nil, 4); err == nil {
+		t.Fatal("elected without proofs")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/vechain/go-ecvrf/equivocation"
+)
+
+// roundAlpha is the input of a round over a fork: the round in 8 big-endian octets, then the fork.
+func roundAlpha(round uint64, fork string) []byte {
+	var r [8]byte
+	binary.BigEndian.PutUint64(r[:], round)
+	return append(r[:], fork...)
+}
+
+func inRound(round uint64, alpha []byte) bool {
+	return len(alpha) >= 8 && binary.BigEndian.Uint64(alpha) == round
+}
+
+func TestEquivocation(t *testing.T) {
+	for _, s := range suites {
+		t.Run(s.name, func(t *testing.T) {
+			d := equivocation.New(s.vrf)
+			sk, _ := ecdsa.GenerateKey(s.curve, rand.Reader)
+			other, _ := ecdsa.GenerateKey(s.curve, rand.Reader)
+			observe := func(sk *ecdsa.PrivateKey, round uint64, fork string) *equivocation.Evidence {
+				alpha := roundAlpha(round, fork)
+				_, pi, err := s.vrf.Prove(sk, alpha)
+				if err != nil {
+					t.Fatal(err)
+				}
+				e, err := d.Observe(&sk.PublicKey, round, alpha, pi)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return e
+			}
+
+			for _, e := range []*equivocation.Evidence{
+				observe(sk, 1, "a"),
+				observe(sk, 1, "a"),
+				observe(sk, 2, "b"),
+				observe(other, 1, "b"),
+			} {
+				if e != nil {
+					t.Fatalf("evidence of honest proofs: %+v", e)
+				}
+			}
+			e := observe(sk, 1, "b")
+			if e == nil || e.Round != 1 || !bytes.Equal(e.First.Alpha, roundAlpha(1, "a")) || !bytes.Equal(e.Second.Alpha, roundAlpha(1, "b")) {
+				t.Fatalf("Observe() = %+v, want the evidence of round 1", e)
+			}
+
+			// the evidence survives encoding, and is checked without the detector
+			data, _ := json.Marshal(e)
+			var decoded equivocation.Evidence
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if err := decoded.Verify(s.vrf, s.curve, inRound); err != nil {
+				t.Fatal(err)
+			}
+			forged := decoded
+			forged.Round = 2
+			if err := forged.Verify(s.vrf, s.curve, inRound); err == nil {
+				t.Fatal("Verify() of another round succeeded")
+			}
+			forged = decoded
+			forged.Second = forged.First
+			if err := forged.Verify(s.vrf, s.curve, inRound); err == nil {
+				t.Fatal("Verify() of the same input succeeded")
+			}
+			forged = decoded
+			forged.Second.Pi = append([]byte(nil), forged.Second.Pi...)
+			forged.Second.Pi[len(forged.Second.Pi)-1] ^= 1
+			if err := forged.Verify(s.vrf, s.curve, inRound); err == nil {
+				t.Fatal("Verify() of an invalid proof succeeded")
+			}
+			if err := decoded.Verify(s.vrf, s.curve, nil); err == nil {
+				t.Fatal("Verify() without a round check succeeded")
+			}
+
+			// invalid proofs are refused
+			if _, err := d.Observe(&sk.PublicKey, 3, roundAlpha(3, "a"), e.First.Pi); err == nil {
+				t.Fatal("Observe() of an invalid proof succeeded")
+			}
+		})
+	}
+}
+
+func TestEquivocationRetention(t *testing.T) {
+	s := suites[0]
+	d := equivocation.New(s.vrf, equivocation.WithRetention(2))
+	sk, _ := ecdsa.GenerateKey(s.curve, rand.Reader)
+	observe := func(round uint64, fork string) (*equivocation.Evidence, error) {
+		alpha := roundAlpha(round, fork)
+		_, pi, _ := s.vrf.Prove(sk, alpha)
+		return d.Observe(&sk.PublicKey, round, alpha, pi)
+	}
+	for round := uint64(1); round <= 3; round++ {
+		if _, err := observe(round, "a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := observe(1, "b"); err == nil {
+		t.Fatal("Observe() of a forgotten round succeeded")
+	}
+	if e, err := observe(2, "b"); err != nil || e == nil {
+		t.Fatalf("Observe() in the window = %+v, %v", e, err)
+	}
+}
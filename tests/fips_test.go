@@ -0,0 +1,74 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+// Test_FIPSMode runs Test_FIPSMode_child in a new process with FIPS 140-3 mode enabled.
+func Test_FIPSMode(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^Test_FIPSMode_child$", "-test.v")
+	cmd.Env = append(os.Environ(), "GODEBUG=fips140=on", "ECVRF_FIPS_CHILD=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("FIPS mode: %v\n%s", err, out)
+	}
+	t.Logf("%s", out)
+}
+
+func Test_FIPSMode_child(t *testing.T) {
+	if os.Getenv("ECVRF_FIPS_CHILD") != "1" {
+		t.Skip("run by Test_FIPSMode")
+	}
+	if !ecvrf.FIPSMode() {
+		t.Skip("FIPS 140-3 mode is not available")
+	}
+
+	// the P-256 suite gives the same results
+	cases, err := readCases("../vectors/p256_sha256_tai.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vrf := ecvrf.NewP256Sha256Tai()
+	for _, c := range cases {
+		skBytes, _ := hex.DecodeString(c.Sk)
+		alpha, _ := hex.DecodeString(c.Alpha)
+		wantPi, _ := hex.DecodeString(c.Pi)
+		wantBeta, _ := hex.DecodeString(c.Beta)
+
+		sk := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(skBytes)}
+		sk.Curve = elliptic.P256()
+		sk.X, sk.Y = sk.Curve.ScalarBaseMult(skBytes)
+		beta, pi, err := vrf.Prove(sk, alpha)
+		if err != nil {
+			t.Fatalf("vrf.Prove() error = %v", err)
+		}
+		if !reflect.DeepEqual(beta, wantBeta) || !reflect.DeepEqual(pi, wantPi) {
+			t.Fatalf("vrf.Prove() = %x, %x, want %x, %x", beta, pi, wantBeta, wantPi)
+		}
+		if _, err := vrf.Verify(&sk.PublicKey, alpha, pi); err != nil {
+			t.Fatalf("vrf.Verify() error = %v", err)
+		}
+	}
+
+	// secp256k1 is not approved
+	sk := secp256k1.PrivKeyFromBytes([]byte{1}).ToECDSA()
+	if _, _, err := ecvrf.NewSecp256k1Sha256Tai().Prove(sk, []byte("alpha")); err == nil {
+		t.Fatal("vrf.Prove() of secp256k1 succeeded in FIPS mode")
+	}
+	if _, err := ecvrf.NewSecp256k1Sha256Tai().Verify(&sk.PublicKey, []byte("alpha"), make([]byte, 81)); err == nil {
+		t.Fatal("vrf.Verify() of secp256k1 succeeded in FIPS mode")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build go1.18
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/generic"
+)
+
+// roundTrip proves alpha with sk and verifies the proof with its public key, for any suite.
+func roundTrip[K generic.PrivateKey, P generic.PublicKey](t *testing.T, v generic.VRF[K, P], sk K) {
+	t.Helper()
+	pk, err := generic.Public[K, P](sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, pi, err := v.Prove(sk, []byte("sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := v.Verify(pk, []byte("sample"), pi); err != nil || !bytes.Equal(b, beta) {
+		t.Fatal("proof rejected", err)
+	}
+	if _, err := v.Verify(pk, []byte("other"), pi); err == nil {
+		t.Fatal("proof of another input accepted")
+	}
+}
+
+func TestGenericVRF(t *testing.T) {
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	roundTrip(t, generic.ECDSA(ecvrf.NewP256Sha256Tai()), sk)
+
+	_, edSK, _ := ed25519.GenerateKey(rand.Reader)
+	roundTrip(t, generic.Ed25519(ecvrf.NewEd25519Sha512Ell2()), edSK)
+
+	scalar := generic.Scalar{Curve: elliptic.P256(), D: sk.D.FillBytes(make([]byte, 32))}
+	roundTrip(t, generic.Scalars(ecvrf.NewP256Sha256Tai()), scalar)
+	// the raw scalar gives the proofs of its ECDSA key
+	_, pi1, _ := generic.Scalars(ecvrf.NewP256Sha256Tai()).Prove(scalar, []byte("sample"))
+	_, pi2, _ := ecvrf.NewP256Sha256Tai().Prove(sk, []byte("sample"))
+	if !bytes.Equal(pi1, pi2) {
+		t.Fatal("proofs of the scalar differ")
+	}
+	if _, _, err := generic.Scalars(ecvrf.NewP256Sha256Tai()).Prove(generic.Scalar{Curve: elliptic.P256(), D: make([]byte, 32)}, nil); err == nil {
+		t.Fatal("zero scalar accepted")
+	}
+
+	// the key types of a VRF are checked
+	if _, err := generic.Public[ed25519.PrivateKey, *ecdsa.PublicKey](edSK); err == nil {
+		t.Fatal("public key of another type")
+	}
+}
@@ -1,11 +1,13 @@
 module github.com/vechain/go-ecvrf/tests
 
-go 1.12
+go 1.16
 
 require (
-	github.com/btcsuite/btcd v0.20.1-beta
-	github.com/stretchr/testify v1.5.1
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.1
 	github.com/vechain/go-ecvrf v0.0.0-20200305101714-4252ed3a3b96
+	github.com/vechain/go-ecvrf/vectors v0.0.0-00010101000000-000000000000
 )
 
 replace github.com/vechain/go-ecvrf => ../
+
+replace github.com/vechain/go-ecvrf/vectors => ../vectors
@@ -0,0 +1,184 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/hd"
+)
+
+func TestHD(t *testing.T) {
+	// test vector 1 of SLIP-10, at m and m/0H
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	for _, tt := range []struct {
+		curve                elliptic.Curve
+		chain, key           string
+		childChain, childKey string
+	}{
+		{secp256k1.S256(),
+			"873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+			"47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{elliptic.P256(),
+			"beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea", "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2",
+			"3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11", "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c"},
+		{nil,
+			"90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			"8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3"},
+	} {
+		var (
+			master *hd.Key
+			err    error
+		)
+		if tt.curve == nil {
+			master, err = hd.NewMasterEd25519(seed)
+		} else {
+			master, err = hd.NewMaster(seed, tt.curve)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		child, err := master.Derive("m/0'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range []struct {
+			key         *hd.Key
+			chain, want string
+		}{{master, tt.chain, tt.key}, {child, tt.childChain, tt.childKey}} {
+			if got := hex.EncodeToString(k.key.ChainCode()); got != k.chain {
+				t.Errorf("chain code = %v, want %v", got, k.chain)
+			}
+			var got string
+			if tt.curve == nil {
+				sk, _ := k.key.Ed25519()
+				got = hex.EncodeToString(sk.Seed())
+				if !bytes.Equal(k.key.PublicKey().(ed25519.PublicKey), sk.Public().(ed25519.PublicKey)) {
+					t.Error("public key doesn't match")
+				}
+			} else {
+				sk, _ := k.key.ECDSA()
+				got = hex.EncodeToString(sk.D.Bytes())
+				if pk := k.key.PublicKey().(*ecdsa.PublicKey); pk.X.Cmp(sk.X) != 0 || pk.Y.Cmp(sk.Y) != 0 {
+					t.Error("public key doesn't match")
+				}
+			}
+			if got != k.want {
+				t.Errorf("key = %v, want %v", got, k.want)
+			}
+		}
+	}
+
+	// per-epoch keys prove and verify, and differ
+	master, _ := hd.NewMaster(seed, elliptic.P256())
+	vrf := ecvrf.NewP256Sha256Tai()
+	epoch1, _ := master.Derive(hd.Path(44, 818, 1))
+	epoch2, _ := master.Derive("m/44h/818h/2h")
+	sk1, _ := epoch1.ECDSA()
+	sk2, _ := epoch2.ECDSA()
+	if sk1.D.Cmp(sk2.D) == 0 {
+		t.Fatal("epochs share keys")
+	}
+	_, pi, err := vrf.Prove(sk1, []byte("epoch 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vrf.Verify(epoch1.PublicKey().(*ecdsa.PublicKey), []byte("epoch 1"), pi); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := master.Derive("m/44'/0"); err == nil {
+		t.Fatal("non-hardened derivation accepted")
+	}
+	if _, err := master.Child(5); err == nil {
+		t.Fatal("non-hardened child derived")
+	}
+	if _, err := hd.NewMaster(seed[:8], elliptic.P256()); err == nil {
+		t.Fatal("short seed accepted")
+	}
+	if _, err := hd.NewMaster(seed, elliptic.P384()); err == nil {
+		t.Fatal("unsupported curve accepted")
+	}
+}
+
+func TestMnemonic(t *testing.T) {
+	// vectors of BIP39 of Trezor, with the passphrase TREZOR
+	for _, tt := range []struct {
+		entropy, mnemonic, seed string
+	}{
+		{"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"},
+		{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607"},
+		{"9e885d952ad362caeb4efe34a8e91bd2",
+			"ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic",
+			"274ddc525802f7c828d8ef7ddbcdc5304e87ac3535913611fbbfa986d0c9e5476c91689f9c8a54fd55bd38606aa6a8595ad213d4c9c9f9aca3fb217069a41028"},
+		{"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+			"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad"},
+	} {
+		entropy, _ := hex.DecodeString(tt.entropy)
+		mnemonic, err := hd.NewMnemonic(entropy)
+		if err != nil || mnemonic != tt.mnemonic {
+			t.Errorf("NewMnemonic() = %v, %v, want %v", mnemonic, err, tt.mnemonic)
+		}
+		if got, err := hd.MnemonicToEntropy(tt.mnemonic); err != nil || !bytes.Equal(got, entropy) {
+			t.Errorf("MnemonicToEntropy() = %x, %v, want %v", got, err, tt.entropy)
+		}
+		seed, err := hd.NewSeed(tt.mnemonic, "TREZOR")
+		if err != nil || hex.EncodeToString(seed) != tt.seed {
+			t.Errorf("NewSeed() = %x, %v, want %v", seed, err, tt.seed)
+		}
+	}
+
+	// a recovered mnemonic gives back the keys
+	mnemonic, err := hd.GenerateMnemonic(rand.Reader, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(strings.Fields(mnemonic)) != 24 {
+		t.Fatal("wrong number of words", mnemonic)
+	}
+	master, err := hd.NewMasterFromMnemonic(mnemonic, "pass", elliptic.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, _ := hd.NewMasterFromMnemonic("  "+strings.Replace(mnemonic, " ", "  ", -1), "pass", elliptic.P256())
+	k1, _ := master.Derive("m/1'")
+	k2, _ := recovered.Derive("m/1'")
+	sk1, _ := k1.ECDSA()
+	sk2, _ := k2.ECDSA()
+	if sk1.D.Cmp(sk2.D) != 0 {
+		t.Fatal("recovered another key")
+	}
+	other, _ := hd.NewMasterFromMnemonic(mnemonic, "", elliptic.P256())
+	if k3, _ := other.Derive("m/1'"); bytes.Equal(k3.ChainCode(), k1.ChainCode()) {
+		t.Fatal("passphrase ignored")
+	}
+	if _, err := hd.NewMasterFromMnemonic(mnemonic, "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the last word differs from "about" only in the checksum bits
+	if _, err := hd.NewSeed(strings.Repeat("abandon ", 12), ""); err == nil {
+		t.Error("mnemonic of a wrong checksum accepted")
+	}
+	if _, err := hd.NewSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandonx", ""); err == nil {
+		t.Error("unknown word accepted")
+	}
+	if _, err := hd.GenerateMnemonic(rand.Reader, 13); err == nil {
+		t.Error("mnemonic of 13 words generated")
+	}
+}
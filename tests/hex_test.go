@@ -0,0 +1,56 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestHex(t *testing.T) {
+	// Example 10 of RFC9381 B.1
+	vrf := ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381))
+	beta, pi, err := ecvrf.ProveHex(vrf, elliptic.P256(), "c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721", "73616d706c65")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a3ad7b0ef73d8fc6655053ea22f9bede8c743f08bbed3d38821f0e16474b505e"; beta != want {
+		t.Fatalf("beta = %v, want %v", beta, want)
+	}
+	pk := "0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6"
+	if b, err := ecvrf.VerifyHex(vrf, elliptic.P256(), pk, "73616d706c65", pi); err != nil || b != beta {
+		t.Fatal("proof rejected", err)
+	}
+	if _, err := ecvrf.VerifyHex(vrf, elliptic.P256(), pk, "74657374", pi); err == nil {
+		t.Fatal("proof of another input accepted")
+	}
+
+	// uncompressed keys of secp256k1
+	k1 := ecvrf.NewSecp256k1Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	skHex := hex.EncodeToString(sk.D.FillBytes(make([]byte, 32)))
+	beta, pi, err = ecvrf.ProveHex(k1, secp256k1.S256(), skHex, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncompressed := "04" + hex.EncodeToString(sk.X.FillBytes(make([]byte, 32))) + hex.EncodeToString(sk.Y.FillBytes(make([]byte, 32)))
+	if b, err := ecvrf.VerifyHex(k1, secp256k1.S256(), uncompressed, "", pi); err != nil || b != beta {
+		t.Fatal("proof rejected", err)
+	}
+
+	for _, c := range [][2]string{{"zz", ""}, {"01", ""}, {skHex, "z"}} {
+		if _, _, err := ecvrf.ProveHex(k1, secp256k1.S256(), c[0], c[1]); err == nil {
+			t.Fatal("invalid input accepted", c)
+		}
+	}
+	if _, err := ecvrf.VerifyHex(k1, secp256k1.S256(), uncompressed[:64], "", pi); err == nil {
+		t.Fatal("truncated key accepted")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/ecvrftest"
+)
+
+// stubProver is a Prover of the kind generated by moq.
+type stubProver struct {
+	ProveFunc func(sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error)
+}
+
+func (s *stubProver) Prove(sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
+	return s.ProveFunc(sk, alpha)
+}
+
+// ticket proves the ticket of a round, with nothing but a Prover.
+func ticket(p ecvrf.Prover, sk *ecdsa.PrivateKey, round string) ([]byte, error) {
+	beta, _, err := p.Prove(sk, []byte("ticket "+round))
+	return beta, err
+}
+
+func TestInterfaces(t *testing.T) {
+	for _, s := range suites {
+		var (
+			suite ecvrf.Suite         = s.vrf
+			_     ecvrf.Prover        = suite
+			_     ecvrf.ProofVerifier = suite
+		)
+		sk, _ := ecdsa.GenerateKey(s.curve, rand.Reader)
+		beta, err := ticket(suite, sk, "1")
+		if err != nil || len(beta) != suite.Params().BetaSize {
+			t.Fatalf("%s: ticket() = %x, %v", s.name, beta, err)
+		}
+	}
+	var _ ecvrf.Suite = ecvrftest.New()
+
+	errDown := errors.New("prover down")
+	stub := &stubProver{func(*ecdsa.PrivateKey, []byte) ([]byte, []byte, error) { return nil, nil, errDown }}
+	if _, err := ticket(stub, ecvrftest.NewKey("alice"), "1"); err != errDown {
+		t.Fatalf("ticket() err = %v, want %v", err, errDown)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/vectors"
+)
+
+// invalidCodes are the errors of the codes of the invalid corpus.
+var invalidCodes = map[string]error{
+	vectors.CodeUnrecognizedPointEncoding: ecvrf.ErrUnrecognizedPointEncoding,
+	vectors.CodeInvalidPointLength:        ecvrf.ErrInvalidPointLength,
+	vectors.CodeNotSquare:                 ecvrf.ErrNotSquare,
+	vectors.CodeNonCanonicalPoint:         ecvrf.ErrNonCanonicalPoint,
+	vectors.CodeNotOnCurve:                ecvrf.ErrNotOnCurve,
+	vectors.CodeInvalidProofLength:        ecvrf.ErrInvalidProofLength,
+	vectors.CodeInvalidProof:              ecvrf.ErrInvalidProof,
+	vectors.CodeInvalidProofScalar:        ecvrf.ErrInvalidProofScalar,
+	vectors.CodeNonCanonicalProof:         ecvrf.ErrNonCanonicalProof,
+}
+
+// TestInvalidProofs checks that each proof of the invalid corpus is rejected, with the error of
+// the code of its file, and that the corpus covers the ways a proof can be malformed.
+func TestInvalidProofs(t *testing.T) {
+	var (
+		weierstrass = []string{"off-curve gamma", "non-canonical gamma", "gamma tag", "wrong gamma", "s >= q",
+			"wrong challenge", "truncated", "trailing data", "suite string confusion", "spec confusion"}
+		ed25519Reasons = []string{"off-curve gamma", "non-canonical gamma", "wrong gamma", "truncated", "trailing data"}
+		p256Key        = func(b []byte) *ecdsa.PublicKey {
+			x, y := elliptic.UnmarshalCompressed(elliptic.P256(), b)
+			return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		}
+		secp256k1Key = func(b []byte) *ecdsa.PublicKey {
+			pk, err := secp256k1.ParsePubKey(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return pk.ToECDSA()
+		}
+		ecdsaVerify = func(v ecvrf.VRF, key func([]byte) *ecdsa.PublicKey) func(c *vectors.InvalidCase) error {
+			return func(c *vectors.InvalidCase) error {
+				_, err := v.Verify(key(c.PK), c.Alpha, c.Pi)
+				return err
+			}
+		}
+		ed25519Verify = func(v ecvrf.Ed25519VRF) func(c *vectors.InvalidCase) error {
+			return func(c *vectors.InvalidCase) error {
+				_, err := v.Verify(ed25519.PublicKey(c.PK), c.Alpha, c.Pi)
+				return err
+			}
+		}
+	)
+
+	for _, s := range []struct {
+		name    string
+		verify  func(c *vectors.InvalidCase) error
+		cases   []vectors.InvalidCase
+		reasons []string
+	}{
+		{"secp256k1", ecdsaVerify(ecvrf.NewSecp256k1Sha256Tai(), secp256k1Key), vectors.Secp256k1Sha256TaiInvalid(), weierstrass},
+		{"secp256k1 rfc9381", ecdsaVerify(ecvrf.NewSecp256k1Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381)), secp256k1Key), vectors.Secp256k1Sha256TaiRFC9381Invalid(), weierstrass},
+		{"p256", ecdsaVerify(ecvrf.NewP256Sha256Tai(), p256Key), vectors.P256Sha256TaiInvalid(), weierstrass},
+		{"p256 rfc9381", ecdsaVerify(ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381)), p256Key), vectors.P256Sha256TaiRFC9381Invalid(), weierstrass},
+		{"elligator2", ed25519Verify(ecvrf.NewEd25519Sha512Elligator2()), vectors.Ed25519Sha512Elligator2Invalid(),
+			append([]string{"wrong challenge", "spec confusion"}, ed25519Reasons...)},
+		{"ell2", ed25519Verify(ecvrf.NewEd25519Sha512Ell2()), vectors.Ed25519Sha512Ell2Invalid(),
+			append([]string{"s >= q", "wrong challenge", "spec confusion"}, ed25519Reasons...)},
+		{"ell2 batchcompat", ed25519Verify(ecvrf.NewEd25519Sha512Ell2BatchCompat()), vectors.Ed25519Sha512Ell2BatchCompatInvalid(),
+			append([]string{"s >= q", "wrong announcements", "format confusion"}, ed25519Reasons...)},
+	} {
+		seen := make(map[string]bool)
+		for i, c := range s.cases {
+			seen[c.Reason] = true
+			want, ok := invalidCodes[c.Code]
+			if !ok {
+				t.Fatalf("%s: case %d (%s): unknown code %q", s.name, i, c.Reason, c.Code)
+			}
+			if err := s.verify(&c); !errors.Is(err, want) {
+				t.Errorf("%s: case %d (%s): Verify() error = %v, want %v", s.name, i, c.Reason, err, want)
+			}
+		}
+		for _, reason := range s.reasons {
+			if !seen[reason] {
+				t.Errorf("%s: no case of %s", s.name, reason)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/keymgmt"
+)
+
+func TestKeyRotation(t *testing.T) {
+	vrf := ecvrf.NewP256Sha256Tai()
+	var warnings []string
+	m := keymgmt.New(vrf, keymgmt.WithExpiryWarning(10, func(k *keymgmt.Key, at uint64) {
+		warnings = append(warnings, k.ID)
+	}))
+	newKey := func(id string, from, until uint64) keymgmt.Key {
+		sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		return keymgmt.Key{ID: id, PublicKey: &sk.PublicKey, PrivateKey: sk, From: from, Until: until}
+	}
+	k1, k2 := newKey("k1", 0, 100), newKey("k2", 95, 0)
+	if err := m.Add(k1); err != nil {
+		t.Fatal(err)
+	}
+
+	// k1 expires with no successor
+	id, _, pi50, err := m.Prove(50, []byte("round 50"))
+	if err != nil || id != "k1" {
+		t.Fatal("wrong active key", id, err)
+	}
+	if len(warnings) != 0 {
+		t.Fatal("early warning", warnings)
+	}
+	if _, _, _, err := m.Prove(91, []byte("round 91")); err != nil {
+		t.Fatal(err)
+	}
+	m.Prove(92, []byte("round 92"))
+	if len(warnings) != 1 || warnings[0] != "k1" {
+		t.Fatal("expiry not warned once", warnings)
+	}
+
+	// k2 takes over on the overlap
+	if err := m.Add(k2); err != nil {
+		t.Fatal(err)
+	}
+	id, beta, pi96, err := m.Prove(96, []byte("round 96"))
+	if err != nil || id != "k2" {
+		t.Fatal("new key not active", id, err)
+	}
+	if id, b, err := m.Verify(96, []byte("round 96"), pi96); err != nil || id != "k2" || string(b) != string(beta) {
+		t.Fatal("proof of the new key rejected", id, err)
+	}
+	// old proofs still verify at their round, but not past the window of their key
+	if id, _, err := m.Verify(50, []byte("round 50"), pi50); err != nil || id != "k1" {
+		t.Fatal("old proof rejected", id, err)
+	}
+	if _, _, err := m.Verify(100, []byte("round 50"), pi50); err == nil {
+		t.Fatal("proof accepted after the expiry of its key")
+	}
+	if _, _, err := m.Verify(50, []byte("round 96"), pi96); err == nil {
+		t.Fatal("proof accepted before the start of its key")
+	}
+
+	// a verify-only manager
+	peer := keymgmt.New(vrf)
+	for _, k := range m.Keys() {
+		k.PrivateKey = nil
+		if err := peer.Add(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := peer.Verify(50, []byte("round 50"), pi50); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := peer.Prove(50, []byte("round 50")); err == nil {
+		t.Fatal("proved without private keys")
+	}
+	if err := peer.Remove("k1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := peer.Verify(50, []byte("round 50"), pi50); err == nil {
+		t.Fatal("proof of a removed key accepted")
+	}
+
+	if m.Add(newKey("k1", 0, 0)) == nil {
+		t.Fatal("duplicate id accepted")
+	}
+	if m.Add(newKey("k3", 10, 5)) == nil {
+		t.Fatal("empty window accepted")
+	}
+	mismatched := newKey("k4", 0, 0)
+	mismatched.PublicKey = k1.PublicKey
+	if m.Add(mismatched) == nil {
+		t.Fatal("mismatched key pair accepted")
+	}
+}
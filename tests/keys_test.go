@@ -0,0 +1,171 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestSuiteKeys(t *testing.T) {
+	var (
+		p256    = ecvrf.NewP256Sha256Tai()
+		rfc9381 = ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381))
+		k1      = ecvrf.NewSecp256k1Sha256Tai()
+	)
+	sk, err := ecvrf.GenerateKey(p256, elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := sk.PublicKey()
+	beta, pi, err := sk.Prove([]byte("sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := pk.Verify([]byte("sample"), pi); err != nil || !bytes.Equal(b, beta) {
+		t.Fatal("proof rejected", err)
+	}
+	// the same as with the bare keys
+	if _, want, _ := p256.Prove(sk.ECDSA(), []byte("sample")); !bytes.Equal(pi, want) {
+		t.Fatal("proofs differ")
+	}
+
+	// serialization round trips within the suite only
+	parsedSK, err := ecvrf.ParsePrivateKey(ecvrf.NewP256Sha256Tai(), elliptic.P256(), sk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsedSK.PublicKey().Equal(pk) || parsedSK.ECDSA().D.Cmp(sk.ECDSA().D) != 0 {
+		t.Fatal("private key changed")
+	}
+	parsedPK, err := ecvrf.ParsePublicKey(p256, elliptic.P256(), pk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsedPK.Equal(pk) || !pk.Equal(sk.Public()) {
+		t.Fatal("public key changed")
+	}
+	if _, err := ecvrf.ParsePublicKey(rfc9381, elliptic.P256(), pk.Bytes()); err == nil {
+		t.Fatal("key of draft06 parsed for RFC9381")
+	}
+	if _, err := ecvrf.ParsePrivateKey(k1, secp256k1.S256(), sk.Bytes()); err == nil {
+		t.Fatal("P-256 key parsed for secp256k1")
+	}
+	if _, err := ecvrf.ParsePublicKey(p256, elliptic.P256(), pk.Bytes()[:10]); err == nil {
+		t.Fatal("truncated key parsed")
+	}
+	other, _ := ecvrf.NewPublicKey(rfc9381, pk.ECDSA())
+	if other.Equal(pk) {
+		t.Fatal("keys of different suites are equal")
+	}
+
+	// secp256k1 keys are encoded compressed too
+	k1SK, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	k1Key, err := ecvrf.NewPrivateKey(k1, k1SK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(k1Key.PublicKey().Bytes()) != 2+33 {
+		t.Fatal("wrong encoding length")
+	}
+	if parsed, err := ecvrf.ParsePublicKey(k1, secp256k1.S256(), k1Key.PublicKey().Bytes()); err != nil || !parsed.Equal(k1Key.PublicKey()) {
+		t.Fatal("secp256k1 key changed", err)
+	}
+	if _, err := ecvrf.NewPrivateKey(p256, nil); err == nil {
+		t.Fatal("nil key accepted")
+	}
+}
+
+func TestDecodeKeys(t *testing.T) {
+	for _, c := range []struct {
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+	}{
+		{ecvrf.NewP256Sha256Tai(), elliptic.P256()},
+		{ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256()},
+	} {
+		ecdsaSK, _ := ecdsa.GenerateKey(c.curve, rand.Reader)
+		scalar := ecdsaSK.D.FillBytes(make([]byte, 32))
+		sk, err := ecvrf.DecodePrivateKey(c.vrf, c.curve, scalar)
+		if err != nil || sk.D.Cmp(ecdsaSK.D) != 0 || sk.X.Cmp(ecdsaSK.X) != 0 || sk.Y.Cmp(ecdsaSK.Y) != 0 {
+			t.Fatal("private key changed", err)
+		}
+		for _, bad := range [][]byte{scalar[1:], make([]byte, 32), c.curve.Params().N.Bytes()} {
+			if _, err := ecvrf.DecodePrivateKey(c.vrf, c.curve, bad); err == nil {
+				t.Fatal("invalid scalar decoded", bad)
+			}
+		}
+
+		// compressed and uncompressed points
+		compressed := append([]byte{byte(2 + ecdsaSK.Y.Bit(0))}, ecdsaSK.X.FillBytes(make([]byte, 32))...)
+		uncompressed := append([]byte{4}, ecdsaSK.X.FillBytes(make([]byte, 32))...)
+		uncompressed = append(uncompressed, ecdsaSK.Y.FillBytes(make([]byte, 32))...)
+		for _, b := range [][]byte{compressed, uncompressed} {
+			pk, err := ecvrf.DecodePublicKey(c.vrf, c.curve, b)
+			if err != nil || pk.X.Cmp(ecdsaSK.X) != 0 || pk.Y.Cmp(ecdsaSK.Y) != 0 {
+				t.Fatal("public key changed", err)
+			}
+		}
+		offCurve := append([]byte(nil), uncompressed...)
+		offCurve[len(offCurve)-1] ^= 1
+		for _, bad := range [][]byte{nil, compressed[1:], offCurve} {
+			if _, err := ecvrf.DecodePublicKey(c.vrf, c.curve, bad); err == nil {
+				t.Fatal("invalid public key decoded", bad)
+			}
+		}
+	}
+}
+
+func TestDeterministicRand(t *testing.T) {
+	// the same seeded stream gives the same keys and proofs, with all the randomness injected
+	run := func() ([]byte, []byte) {
+		r := mrand.New(mrand.NewSource(42))
+		vrf := ecvrf.NewP256Sha256Tai(ecvrf.WithHedgedNonce(r), ecvrf.WithScalarBlinding(r))
+		sk, err := ecvrf.GenerateKey(vrf, elliptic.P256(), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, pi, err := sk.Prove([]byte("sample"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sk.Bytes(), pi
+	}
+	sk1, pi1 := run()
+	sk2, pi2 := run()
+	if !bytes.Equal(sk1, sk2) || !bytes.Equal(pi1, pi2) {
+		t.Fatal("runs differ")
+	}
+	if _, err := ecvrf.GenerateKey(ecvrf.NewP256Sha256Tai(), elliptic.P256(), bytes.NewReader(nil)); err == nil {
+		t.Fatal("key of an empty source")
+	}
+}
+
+func TestWipeKey(t *testing.T) {
+	vrf := ecvrf.NewP256Sha256Tai()
+	ecdsaSK, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	sk, err := ecvrf.NewPrivateKey(vrf, ecdsaSK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := sk.PublicKey()
+	sk.Wipe()
+	if _, _, err := sk.Prove([]byte("sample")); err == nil {
+		t.Fatal("wiped key proved")
+	}
+	if ecdsaSK.D.Sign() != 0 || sk.ECDSA() != nil || sk.Bytes() != nil {
+		t.Fatal("scalar not wiped")
+	}
+	if !sk.PublicKey().Equal(pk) {
+		t.Fatal("public key changed")
+	}
+	sk.Wipe()
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build go1.21
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/keymgmt"
+)
+
+// slogRecords returns a Logger writing JSON records to buf, with the records parsed by records.
+func slogRecords() (ecvrf.Logger, *bytes.Buffer, func(t *testing.T) []map[string]interface{}) {
+	var buf bytes.Buffer
+	l := ecvrf.NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	return l, &buf, func(t *testing.T) []map[string]interface{} {
+		var records []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var r map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &r); err != nil {
+				t.Fatal(err)
+			}
+			records = append(records, r)
+		}
+		return records
+	}
+}
+
+func TestLogger(t *testing.T) {
+	l, buf, records := slogRecords()
+	vrf := ecvrf.NewP256Sha256Tai(ecvrf.WithLogger(l))
+	sk, _ := ecvrf.GenerateKey(vrf, elliptic.P256(), rand.Reader)
+	if _, err := ecvrf.ParsePrivateKey(vrf, elliptic.P256(), sk.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	_, pi, err := sk.Prove([]byte("private id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vrf.Verify(sk.PublicKey().ECDSA(), []byte("private id"), pi); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vrf.NewVerifier(sk.PublicKey().ECDSA()).Verify([]byte("other id"), pi); err == nil {
+		t.Fatal("Verify() accepted the proof of another input")
+	}
+
+	got := records(t)
+	if len(got) != 2 || got[0]["msg"] != ecvrf.EventKeyLoaded || got[1]["msg"] != ecvrf.EventVerifyFailed {
+		t.Fatalf("records = %v", got)
+	}
+	failed := got[1]
+	if failed["level"] != "WARN" || failed["error"] == nil || failed["public_key"] != ecvrf.PublicKeyID(sk.PublicKey().ECDSA()) || failed["alpha_size"] != 8.0 {
+		t.Errorf("record = %v", failed)
+	}
+	// neither the input nor the key are logged
+	if out := buf.String(); strings.Contains(out, "other id") || strings.Contains(out, hex.EncodeToString(sk.ECDSA().D.Bytes())) {
+		t.Errorf("secrets logged: %s", out)
+	}
+
+	// private keys logged by mistake are redacted
+	buf.Reset()
+	slog.New(slog.NewJSONHandler(buf, nil)).Info("oops", "key", sk)
+	if out := buf.String(); !strings.Contains(out, "REDACTED") || strings.Contains(out, sk.ECDSA().D.String()) {
+		t.Errorf("private key logged: %s", out)
+	}
+}
+
+func TestKeyRotationLogger(t *testing.T) {
+	l, _, records := slogRecords()
+	vrf := ecvrf.NewP256Sha256Tai()
+	m := keymgmt.New(vrf, keymgmt.WithLogger(l), keymgmt.WithExpiryWarning(10, nil))
+	for i, id := range []string{"2024-q1", "2024-q2"} {
+		sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		from := uint64(i * 100)
+		if err := m.Add(keymgmt.Key{ID: id, PublicKey: &sk.PublicKey, PrivateKey: sk, From: from, Until: from + 100}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, at := range []uint64{1, 2, 150, 195} {
+		if _, _, _, err := m.Prove(at, []byte("round")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Remove("2024-q1"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		keymgmt.EventKeyAdded, keymgmt.EventKeyAdded,
+		keymgmt.EventKeyActivated, keymgmt.EventKeyActivated,
+		// the key of q2 has no successor
+		keymgmt.EventKeyExpiring,
+		keymgmt.EventKeyRemoved,
+	}
+	got := records(t)
+	if len(got) != len(want) {
+		t.Fatalf("records = %v", got)
+	}
+	for i, r := range got {
+		if r["msg"] != want[i] {
+			t.Fatalf("record %d = %v, want %s", i, r, want[i])
+		}
+	}
+	if got[2]["key_id"] != "2024-q1" || got[3]["key_id"] != "2024-q2" {
+		t.Errorf("activations = %v, %v", got[2], got[3])
+	}
+}
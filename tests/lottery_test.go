@@ -0,0 +1,107 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/lottery"
+)
+
+func TestLottery(t *testing.T) {
+	vrf := ecvrf.NewP256Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var participants []string
+	for i := 0; i < 11; i++ {
+		participants = append(participants, fmt.Sprint("ticket-", i))
+	}
+
+	a, err := lottery.Draw(vrf, sk, "raffle #1, block 0xabcd", participants, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[int]bool{}
+	for _, w := range a.Winners {
+		if w < 0 || w >= len(participants) || seen[w] {
+			t.Fatal("invalid winners", a.Winners)
+		}
+		seen[w] = true
+	}
+	// the winners are the first elements of the shuffle of beta
+	if perm := ecvrf.Shuffle(a.Beta, len(participants)); !reflect.DeepEqual(a.Winners, perm[:3]) {
+		t.Fatalf("winners = %v, want the first of %v", a.Winners, perm)
+	}
+	// the winners of a fixed beta, which change only with the stream
+	if got, want := lottery.Winners([]byte("go-ecvrf/pinned"), 11, 3), []int{8, 1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Winners() = %v, want %v", got, want)
+	}
+	data, _ := json.Marshal(a)
+	var decoded lottery.Attestation
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := lottery.Verify(vrf, &sk.PublicKey, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := decoded
+	tampered.Winners = append([]int{decoded.Winners[1], decoded.Winners[0]}, decoded.Winners[2:]...)
+	if lottery.Verify(vrf, &sk.PublicKey, &tampered) == nil {
+		t.Fatal("reordered winners accepted")
+	}
+	tampered = decoded
+	tampered.Participants = append([]string{"ticket-x"}, decoded.Participants[1:]...)
+	if lottery.Verify(vrf, &sk.PublicKey, &tampered) == nil {
+		t.Fatal("altered list accepted")
+	}
+	tampered = decoded
+	tampered.Draw = "raffle #2"
+	if lottery.Verify(vrf, &sk.PublicKey, &tampered) == nil {
+		t.Fatal("attestation of another draw accepted")
+	}
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if lottery.Verify(vrf, &other.PublicKey, &decoded) == nil {
+		t.Fatal("attestation of another key accepted")
+	}
+
+	// everyone wins when all are drawn
+	all := lottery.Winners(a.Beta, len(participants), len(participants))
+	seen = map[int]bool{}
+	for _, w := range all {
+		seen[w] = true
+	}
+	if len(seen) != len(participants) {
+		t.Fatal("not a permutation", all)
+	}
+
+	for n := 1; n <= len(participants); n++ {
+		root := lottery.Root(participants[:n])
+		for i := 0; i < n; i++ {
+			path, err := lottery.InclusionProof(participants[:n], i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !lottery.VerifyInclusion(root, participants[i], i, n, path) {
+				t.Fatal("inclusion rejected", n, i)
+			}
+			if lottery.VerifyInclusion(root, participants[(i+1)%n], i, n, path) && n > 1 {
+				t.Fatal("inclusion of another participant accepted", n, i)
+			}
+		}
+	}
+
+	if _, err := lottery.Draw(vrf, sk, "empty", nil, 1); err == nil {
+		t.Fatal("drew from an empty list")
+	}
+	if _, err := lottery.Draw(vrf, sk, "too many", participants, 12); err == nil {
+		t.Fatal("drew more winners than participants")
+	}
+}
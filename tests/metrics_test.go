@@ -0,0 +1,87 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+type recordingMetrics struct {
+	mu                     sync.Mutex
+	proves, verifies, fail int
+	candidates             []int
+}
+
+func (m *recordingMetrics) ObserveProve(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.proves++
+}
+
+func (m *recordingMetrics) ObserveVerify(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verifies++
+	if err != nil {
+		m.fail++
+	}
+}
+
+func (m *recordingMetrics) ObserveHashToCurve(candidates int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.candidates = append(m.candidates, candidates)
+}
+
+func TestMetrics(t *testing.T) {
+	m := &recordingMetrics{}
+	vrf := ecvrf.NewSecp256k1Sha256Tai(ecvrf.WithMetrics(m), ecvrf.WithSelfCheck())
+	sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	_, pi, err := vrf.Prove(sk, []byte("sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the self-check isn't a verification of the caller
+	if m.proves != 1 || m.verifies != 0 {
+		t.Fatalf("%v proves and %v verifications observed, want 1 and 0", m.proves, m.verifies)
+	}
+	if _, err := vrf.Verify(&sk.PublicKey, []byte("sample"), pi); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vrf.NewVerifier(&sk.PublicKey).Verify([]byte("other"), pi); err == nil {
+		t.Fatal("Verify() accepted the proof of another input")
+	}
+	if m.verifies != 2 || m.fail != 1 {
+		t.Errorf("%v verifications and %v failures observed, want 2 and 1", m.verifies, m.fail)
+	}
+
+	// the counters of the fast and generic paths agree
+	x, _, err := vrf.EncodeToCurve(&sk.PublicKey, []byte("sample"))
+	if err != nil || x == nil {
+		t.Fatal(err)
+	}
+	// Prove, its self-check, Verify, the other input and EncodeToCurve
+	if len(m.candidates) != 5 {
+		t.Fatalf("candidates = %v", m.candidates)
+	}
+	c := m.candidates
+	if c[0] < 1 || c[1] != c[0] || c[2] != c[0] || c[4] != c[0] {
+		t.Errorf("candidates = %v, not those of the input", c)
+	}
+
+	// each input of a batch is a proof
+	if _, err := ecvrf.ProveBatch(vrf, sk, [][]byte{[]byte("a"), []byte("b"), []byte("c")}); err != nil {
+		t.Fatal(err)
+	}
+	if m.proves != 4 {
+		t.Errorf("%v proves observed, want 4", m.proves)
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build !race
+
+package tests
+
+const raceEnabled = false
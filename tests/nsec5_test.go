@@ -0,0 +1,71 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/nsec5"
+)
+
+func Test_NSEC5CanonicalName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    []byte
+		wantErr bool
+	}{
+		{".", []byte{0}, false},
+		{"Example.COM.", []byte("\x07example\x03com\x00"), false},
+		{"example.com", []byte("\x07example\x03com\x00"), false},
+		{`a\.b.example.`, []byte("\x03a.b\x07example\x00"), false},
+		{`\065\000.`, []byte("\x02a\x00\x00"), false},
+		{"a..example.", nil, true},
+		{`a\`, nil, true},
+		{`\25`, nil, true},
+		{`\256.`, nil, true},
+		{strings.Repeat("a", 64) + ".", nil, true},
+		{strings.Repeat(strings.Repeat("a", 63)+".", 4), nil, true},
+	}
+	for _, tt := range tests {
+		got, err := nsec5.CanonicalName(tt.name)
+		if (err != nil) != tt.wantErr || !bytes.Equal(got, tt.want) {
+			t.Errorf("CanonicalName(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func Test_NSEC5(t *testing.T) {
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	vrf := ecvrf.NewP256Sha256Tai()
+
+	hash, proof, err := nsec5.Prove(vrf, sk, "www.Example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// names which differ only by case have the same hash
+	if got, err := nsec5.Verify(vrf, &sk.PublicKey, "WWW.example.COM", proof); err != nil || !bytes.Equal(got, hash) {
+		t.Fatalf("Verify() = %x, %v, want %x", got, err, hash)
+	}
+	if _, err := nsec5.Verify(vrf, &sk.PublicKey, "mail.example.com.", proof); err == nil {
+		t.Fatal("Verify() accepted the proof of another name")
+	}
+
+	owner, err := nsec5.OwnerName(hash, "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	label := owner[:strings.IndexByte(owner, '.')]
+	if len(label) != 52 || strings.ToLower(label) != label || !strings.HasSuffix(owner, ".example.com.") {
+		t.Fatalf("OwnerName() = %v", owner)
+	}
+	if _, err := nsec5.OwnerName(make([]byte, 64), "example.com."); err == nil {
+		t.Fatal("OwnerName() accepted a hash longer than a label")
+	}
+}
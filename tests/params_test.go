@@ -0,0 +1,80 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+		want  ecvrf.SuiteParams
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256(), ecvrf.SuiteParams{
+			SuiteString: 0xfe, Name: "ECVRF-SECP256K1-SHA256-TAI", Spec: ecvrf.Draft06, Curve: "secp256k1", Hash: "SHA-256",
+			PublicKeySize: 33, ProofSize: 81, BetaSize: 32, SecurityBits: 128,
+		}},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256(), ecvrf.SuiteParams{
+			SuiteString: 0x01, Name: "ECVRF-P256-SHA256-TAI", Spec: ecvrf.Draft06, Curve: "P-256", Hash: "SHA-256",
+			PublicKeySize: 33, ProofSize: 81, BetaSize: 32, SecurityBits: 128,
+		}},
+		{"p256 rfc9381", ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381)), elliptic.P256(), ecvrf.SuiteParams{
+			SuiteString: 0x01, Name: "ECVRF-P256-SHA256-TAI", Spec: ecvrf.RFC9381, Curve: "P-256", Hash: "SHA-256",
+			PublicKeySize: 33, ProofSize: 81, BetaSize: 32, SecurityBits: 128,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.vrf.Params()
+			if p != tt.want {
+				t.Fatalf("Params() = %+v, want %+v", p, tt.want)
+			}
+			sk, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			beta, pi, err := tt.vrf.Prove(sk, []byte("alpha"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pi) != p.ProofSize || len(beta) != p.BetaSize {
+				t.Errorf("proof of %v octets and beta of %v, want %v and %v", len(pi), len(beta), p.ProofSize, p.BetaSize)
+			}
+		})
+	}
+
+	ed := []struct {
+		name  string
+		vrf   ecvrf.Ed25519VRF
+		want  string
+		proof int
+	}{
+		{"draft03", ecvrf.NewEd25519Sha512Elligator2(), "ECVRF-ED25519-SHA512-Elligator2", 80},
+		{"rfc9381", ecvrf.NewEd25519Sha512Ell2(), "ECVRF-EDWARDS25519-SHA512-ELL2", 80},
+		{"batchcompat", ecvrf.NewEd25519Sha512Ell2BatchCompat(), "ECVRF-EDWARDS25519-SHA512-ELL2", 128},
+	}
+	_, sk, _ := ed25519.GenerateKey(rand.Reader)
+	for _, tt := range ed {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.vrf.Params()
+			if p.Name != tt.want || p.Curve != "edwards25519" || p.Hash != "SHA-512" || p.PublicKeySize != ed25519.PublicKeySize {
+				t.Errorf("Params() = %+v", p)
+			}
+			beta, pi, err := tt.vrf.Prove(sk, []byte("alpha"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pi) != tt.proof || len(pi) != p.ProofSize || len(beta) != p.BetaSize {
+				t.Errorf("proof of %v octets and beta of %v, Params() = %+v", len(pi), len(beta), p)
+			}
+		})
+	}
+}
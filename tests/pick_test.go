@@ -0,0 +1,84 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestWeightedPick(t *testing.T) {
+	beta := sha256.Sum256([]byte("beta"))
+	if ecvrf.WeightedPick(beta[:], nil) != -1 || ecvrf.WeightedPick(beta[:], []uint64{0, 0}) != -1 {
+		t.Error("WeightedPick() picked a zero weight")
+	}
+	if got := ecvrf.WeightedPick(beta[:], []uint64{0, 5, 0}); got != 1 {
+		t.Errorf("WeightedPick() = %v, want 1", got)
+	}
+
+	// the picks follow the weights, including sums above 64 bits
+	for _, weights := range [][]uint64{
+		{1, 2, 3, 4},
+		{math.MaxUint64, math.MaxUint64 / 3, 0, math.MaxUint64},
+	} {
+		var (
+			total  float64
+			counts = make([]int, len(weights))
+			n      = 20000
+		)
+		for _, w := range weights {
+			total += float64(w)
+		}
+		for i := 0; i < n; i++ {
+			var b [8]byte
+			binary.BigEndian.PutUint64(b[:], uint64(i))
+			h := sha256.Sum256(b[:])
+			counts[ecvrf.WeightedPick(h[:], weights)]++
+		}
+		for i, w := range weights {
+			want := float64(n) * float64(w) / total
+			if d := float64(counts[i]) - want; d*d > 25*want+1 {
+				t.Errorf("weights %v: index %v picked %v times, want about %v", weights, i, counts[i], want)
+			}
+		}
+	}
+}
+
+func TestWeightedPicks(t *testing.T) {
+	beta := sha256.Sum256([]byte("beta"))
+	weights := []uint64{10, 0, 30, 20, 5, 0, 1}
+	picks := ecvrf.WeightedPicks(beta[:], weights, 4)
+	if len(picks) != 4 {
+		t.Fatalf("len(WeightedPicks()) = %v, want 4", len(picks))
+	}
+	if picks[0] != ecvrf.WeightedPick(beta[:], weights) {
+		t.Error("the first pick isn't the one of WeightedPick()")
+	}
+	seen := map[int]bool{}
+	for _, i := range picks {
+		if seen[i] || weights[i] == 0 {
+			t.Fatalf("WeightedPicks() = %v", picks)
+		}
+		seen[i] = true
+	}
+	again := ecvrf.WeightedPicks(beta[:], weights, 4)
+	for i := range picks {
+		if again[i] != picks[i] {
+			t.Fatal("WeightedPicks() isn't deterministic")
+		}
+	}
+	if got := ecvrf.WeightedPicks(beta[:], weights, 10); len(got) != 5 {
+		t.Errorf("WeightedPicks() picked %v indices of the 5 positive weights", len(got))
+	}
+
+	// the picks of a fixed beta, which change only with the stream
+	if got, want := ecvrf.WeightedPicks([]byte("go-ecvrf/pinned"), weights, 4), []int{2, 0, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WeightedPicks() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestPossession(t *testing.T) {
+	vrf := ecvrf.NewP256Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	nonce := []byte("registry nonce 1")
+
+	pi, err := ecvrf.ProvePossession(vrf, sk, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ecvrf.VerifyPossession(vrf, &sk.PublicKey, nonce, pi); err != nil {
+		t.Fatal(err)
+	}
+	if ecvrf.VerifyPossession(vrf, &sk.PublicKey, []byte("registry nonce 2"), pi) == nil {
+		t.Fatal("replayed proof accepted")
+	}
+	if ecvrf.VerifyPossession(vrf, &other.PublicKey, nonce, pi) == nil {
+		t.Fatal("proof of another key accepted")
+	}
+	if ecvrf.VerifyPossession(vrf, nil, nonce, pi) == nil {
+		t.Fatal("nil key accepted")
+	}
+	// a proof of the attestation string isn't one of an application input
+	if _, err := vrf.Verify(&sk.PublicKey, nonce, pi); err == nil {
+		t.Fatal("proof of possession accepted for the nonce")
+	}
+
+	// Ed25519 keys prove the same attestation string
+	edVRF := ecvrf.NewEd25519Sha512Ell2()
+	pk, edSK, _ := ed25519.GenerateKey(rand.Reader)
+	_, edPi, err := edVRF.Prove(edSK, ecvrf.PossessionAlpha(pk, nonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := edVRF.Verify(pk, ecvrf.PossessionAlpha(pk, nonce), edPi); err != nil {
+		t.Fatal(err)
+	}
+}
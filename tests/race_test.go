@@ -0,0 +1,9 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build race
+
+package tests
+
+// raceEnabled reports whether the race detector is on, which makes sync.Pool drop items at random.
+const raceEnabled = true
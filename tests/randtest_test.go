@@ -0,0 +1,87 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/vechain/go-ecvrf/randtest"
+)
+
+// the examples of NIST SP 800-22 rev. 1a, section 2
+const (
+	sp80022Epsilon100 = "1100100100001111110110101010001000100001011010001100001000110100110001001100011001100010100010111000"
+	sp80022Epsilon128 = "11001100000101010110110001001100111000000000001001001101010100010001001111010110100000001101011111001100111001101101100010110010"
+)
+
+func sp80022Bits(s string) []byte {
+	bits := make([]byte, len(s))
+	for i := range s {
+		bits[i] = s[i] - '0'
+	}
+	return bits
+}
+
+func TestSP80022Examples(t *testing.T) {
+	eps := sp80022Bits(sp80022Epsilon100)
+	for _, c := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"Frequency", randtest.Frequency(eps), 0.109599},
+		{"BlockFrequency", randtest.BlockFrequency(eps, 10), 0.706438},
+		{"Runs", randtest.Runs(eps), 0.500798},
+		{"LongestRunOfOnes", randtest.LongestRunOfOnes(sp80022Bits(sp80022Epsilon128)), 0.180609},
+		{"CumulativeSums", randtest.CumulativeSums(eps), 0.219194},
+		{"ApproximateEntropy", randtest.ApproximateEntropy(eps, 2), 0.235301},
+	} {
+		if math.Abs(c.got-c.want) > 1e-6 {
+			t.Errorf("%s = %.6f, want %.6f", c.name, c.got, c.want)
+		}
+	}
+}
+
+func randtestEnv(name string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+// TestSuiteRandomness assesses the betas of each suite. RANDTEST_SEQUENCES and RANDTEST_BITS scale
+// it up for an audit, e.g. 1000 sequences of 1000000 bits as recommended by SP 800-22.
+func TestSuiteRandomness(t *testing.T) {
+	n, bits := randtestEnv("RANDTEST_SEQUENCES", 100), randtestEnv("RANDTEST_BITS", 8192)
+	if testing.Short() {
+		n, bits = 60, 2048
+	}
+	for _, s := range suites {
+		// a fixed key, so the assessment is reproducible
+		sk := &ecdsa.PrivateKey{D: big.NewInt(0x5eed)}
+		sk.Curve = s.curve
+		sk.X, sk.Y = s.curve.ScalarBaseMult(sk.D.Bytes())
+
+		seqs, err := randtest.Collect(s.vrf, sk, n, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := randtest.Assess(seqs, randtest.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range results {
+			if !r.OK() {
+				t.Errorf("%s: %v", s.name, r)
+			} else if testing.Verbose() {
+				t.Logf("%s: %v", s.name, r)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := ratelimit.New(ecvrf.NewP256Sha256Tai(), ratelimit.WithRate(2, 3), ratelimit.WithClock(func() time.Time { return now }))
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	// the burst, then one proof every half second
+	for i := 0; i < 3; i++ {
+		if _, _, err := l.Prove(sk, []byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, _, err := l.Prove(sk, []byte("3"))
+	var rateErr *ratelimit.RateLimitError
+	if !errors.As(err, &rateErr) || rateErr.Key != ecvrf.PublicKeyID(&sk.PublicKey) || rateErr.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("Prove() over the rate, err = %v", err)
+	}
+	// other keys have their own limit
+	if _, _, err := l.Prove(other, []byte("3")); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if _, _, err := l.Prove(sk, []byte("3")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := l.Prove(sk, []byte("4")); !errors.As(err, &rateErr) {
+		t.Fatalf("Prove() over the rate, err = %v", err)
+	}
+}
+
+func TestUniqueAlpha(t *testing.T) {
+	now := time.Unix(1000, 0)
+	vrf := ecvrf.NewP256Sha256Tai()
+	l := ratelimit.New(vrf, ratelimit.WithUniqueAlpha(time.Minute), ratelimit.WithClock(func() time.Time { return now }))
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	beta, pi, err := l.Prove(sk, []byte("slot 7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Verify(&sk.PublicKey, []byte("slot 7"), pi); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(59 * time.Second)
+	_, _, err = l.Prove(sk, []byte("slot 7"))
+	var replay *ratelimit.ReplayError
+	if !errors.As(err, &replay) || !replay.ProvenAt.Equal(time.Unix(1000, 0)) {
+		t.Fatalf("Prove() of a replayed alpha, err = %v", err)
+	}
+	if _, _, err := l.Prove(sk, []byte("slot 8")); err != nil {
+		t.Fatal(err)
+	}
+	// the window is over
+	now = now.Add(time.Second)
+	if b, _, err := l.Prove(sk, []byte("slot 7")); err != nil || string(b) != string(beta) {
+		t.Fatal(err)
+	}
+
+	// a failed proof doesn't take the alpha
+	l = ratelimit.New(vrf, ratelimit.WithUniqueAlpha(time.Minute))
+	bad := &ecdsa.PrivateKey{PublicKey: sk.PublicKey, D: sk.Curve.Params().N}
+	if _, _, err := l.Prove(bad, []byte("slot 9")); err == nil {
+		t.Fatal("Prove() of an invalid key")
+	}
+	if _, _, err := l.Prove(sk, []byte("slot 9")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,161 @@
+#!/usr/bin/env python3
+# Copyright (c) 2020 vechain.org.
+# Licensed under the MIT license.
+
+"""Reference ECVRF of P256_SHA256_TAI and SECP256K1_SHA256_TAI, for the differential harness.
+
+It's written from draft-irtf-cfrg-vrf-06 and RFC 9381 with plain integers, sharing no code with
+the Go implementation. Cases are read as JSON lines from stdin, {"suite", "spec", "sk", "alpha"},
+and the intermediate values are written as JSON lines to stdout.
+"""
+
+import hashlib
+import hmac
+import json
+import sys
+
+CURVES = {
+    "p256": dict(
+        suite=0x01,
+        p=0xFFFFFFFF00000001000000000000000000000000FFFFFFFFFFFFFFFFFFFFFFFF,
+        a=-3,
+        b=0x5AC635D8AA3A93E7B3EBBD55769886BC651D06B0CC53B0F63BCE3C3E27D2604B,
+        q=0xFFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551,
+        g=(
+            0x6B17D1F2E12C4247F8BCE6E563A440F277037D812DEB33A0F4A13945D898C296,
+            0x4FE342E2FE1A7F9B8EE7EB4A7C0F9E162BCE33576B315ECECBB6406837BF51F5,
+        ),
+    ),
+    "secp256k1": dict(
+        suite=0xFE,
+        p=0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F,
+        a=0,
+        b=7,
+        q=0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141,
+        g=(
+            0x79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798,
+            0x483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8,
+        ),
+    ),
+}
+
+
+class Curve:
+    def __init__(self, suite, p, a, b, q, g):
+        self.suite, self.p, self.a, self.b, self.q, self.g = suite, p, a, b, q, g
+
+    def add(self, P, Q):
+        if P is None:
+            return Q
+        if Q is None:
+            return P
+        p = self.p
+        if P[0] == Q[0]:
+            if (P[1] + Q[1]) % p == 0:
+                return None
+            lam = (3 * P[0] * P[0] + self.a) * pow(2 * P[1], -1, p) % p
+        else:
+            lam = (Q[1] - P[1]) * pow(Q[0] - P[0], -1, p) % p
+        x = (lam * lam - P[0] - Q[0]) % p
+        return x, (lam * (P[0] - x) - P[1]) % p
+
+    def mul(self, k, P):
+        R = None
+        for bit in bin(k)[2:]:
+            R = self.add(R, R)
+            if bit == "1":
+                R = self.add(R, P)
+        return R
+
+    def encode(self, P):
+        return bytes([2 + (P[1] & 1)]) + P[0].to_bytes(32, "big")
+
+    def decode(self, s):
+        if len(s) != 33 or s[0] not in (2, 3):
+            return None
+        x = int.from_bytes(s[1:], "big")
+        p = self.p
+        if x >= p:
+            return None
+        y2 = (x * x * x + self.a * x + self.b) % p
+        y = pow(y2, (p + 1) // 4, p)
+        if y * y % p != y2:
+            return None
+        if y & 1 != s[0] & 1:
+            y = p - y
+        return x, y
+
+
+def rfc6979(curve, x, m):
+    q = curve.q
+    qlen = q.bit_length()
+    rolen = (qlen + 7) // 8
+
+    def bits2int(b):
+        v = int.from_bytes(b, "big")
+        return v >> (len(b) * 8 - qlen) if len(b) * 8 > qlen else v
+
+    h1 = hashlib.sha256(m).digest()
+    bx = x.to_bytes(rolen, "big") + (bits2int(h1) % q).to_bytes(rolen, "big")
+    V, K = b"\x01" * 32, b"\x00" * 32
+    K = hmac.new(K, V + b"\x00" + bx, hashlib.sha256).digest()
+    V = hmac.new(K, V, hashlib.sha256).digest()
+    K = hmac.new(K, V + b"\x01" + bx, hashlib.sha256).digest()
+    V = hmac.new(K, V, hashlib.sha256).digest()
+    while True:
+        T = b""
+        while len(T) < rolen:
+            V = hmac.new(K, V, hashlib.sha256).digest()
+            T += V
+        k = bits2int(T[:rolen])
+        if 1 <= k < q:
+            return k
+        K = hmac.new(K, V + b"\x00", hashlib.sha256).digest()
+        V = hmac.new(K, V, hashlib.sha256).digest()
+
+
+def prove(curve, rfc9381, x, alpha):
+    back = b"\x00" if rfc9381 else b""
+    suite = bytes([curve.suite])
+    Y = curve.mul(x, curve.g)
+
+    H = None
+    for ctr in range(256):
+        h = hashlib.sha256(suite + b"\x01" + curve.encode(Y) + alpha + bytes([ctr]) + back).digest()
+        H = curve.decode(b"\x02" + h)
+        if H is not None:
+            break
+
+    k = rfc6979(curve, x, curve.encode(H))
+    gamma = curve.mul(x, H)
+    U = curve.mul(k, curve.g)
+    V = curve.mul(k, H)
+    points = ([Y] if rfc9381 else []) + [H, gamma, U, V]
+    digest = hashlib.sha256(suite + b"\x02" + b"".join(curve.encode(P) for P in points) + back).digest()
+    c = int.from_bytes(digest[:16], "big")
+    s = (k + c * x) % curve.q
+    pi = curve.encode(gamma) + c.to_bytes(16, "big") + s.to_bytes(32, "big")
+    beta = hashlib.sha256(suite + b"\x03" + curve.encode(gamma) + back).digest()
+    return dict(
+        H=curve.encode(H).hex(),
+        k="%064x" % k,
+        gamma=curve.encode(gamma).hex(),
+        U=curve.encode(U).hex(),
+        V=curve.encode(V).hex(),
+        c="%032x" % c,
+        s="%064x" % s,
+        pi=pi.hex(),
+        beta=beta.hex(),
+    )
+
+
+def main():
+    for line in sys.stdin:
+        case = json.loads(line)
+        curve = Curve(**CURVES[case["suite"]])
+        out = prove(curve, case["spec"] == "rfc9381", int(case["sk"], 16), bytes.fromhex(case["alpha"]))
+        sys.stdout.write(json.dumps(out) + "\n")
+
+
+if __name__ == "__main__":
+    main()
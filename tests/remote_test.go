@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+// softScalar is a RemoteScalar computed in memory, as a device would.
+type softScalar struct {
+	sk *ecdsa.PrivateKey
+}
+
+func (s *softScalar) Public() *ecdsa.PublicKey { return &s.sk.PublicKey }
+
+func (s *softScalar) ECDH(px, py *big.Int) (*big.Int, error) {
+	x, _ := s.sk.Curve.ScalarMult(px, py, s.sk.D.Bytes())
+	return x, nil
+}
+
+type softKey struct {
+	softScalar
+	fault bool
+}
+
+func (k *softKey) NewNonce() (ecvrf.RemoteNonce, error) {
+	nonce, err := ecdsa.GenerateKey(k.sk.Curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &softNonce{softScalar{nonce}, k}, nil
+}
+
+type softNonce struct {
+	softScalar
+	key *softKey
+}
+
+func (n *softNonce) Respond(c *big.Int) (*big.Int, error) {
+	s := new(big.Int).Mul(c, n.key.sk.D)
+	s.Add(s, n.sk.D)
+	if n.key.fault {
+		s.Add(s, big.NewInt(1))
+	}
+	return s.Mod(s, n.sk.Curve.Params().N), nil
+}
+
+func (n *softNonce) Close() error { return nil }
+
+func Test_ProveRemote(t *testing.T) {
+	s256 := func() *ecdsa.PrivateKey {
+		sk, _ := secp256k1.GeneratePrivateKey()
+		return sk.ToECDSA()
+	}
+	p256 := func() *ecdsa.PrivateKey {
+		sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		return sk
+	}
+	for _, tt := range []struct {
+		name  string
+		vrf   ecvrf.VRF
+		genSK func() *ecdsa.PrivateKey
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), s256},
+		{"p256", ecvrf.NewP256Sha256Tai(), p256},
+		{"p256 rfc9381", ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381)), p256},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 8; i++ {
+				sk := tt.genSK()
+				alpha := []byte{byte(i)}
+				key := &softKey{softScalar: softScalar{sk}}
+				beta, pi, err := ecvrf.ProveRemote(tt.vrf, key, alpha)
+				if err != nil {
+					t.Fatal(err)
+				}
+				wantBeta, _, _ := tt.vrf.Prove(sk, alpha)
+				if string(beta) != string(wantBeta) {
+					t.Fatal("beta differs from Prove")
+				}
+				if _, err := tt.vrf.Verify(&sk.PublicKey, alpha, pi); err != nil {
+					t.Fatal(err)
+				}
+
+				key.fault = true
+				if _, _, err := ecvrf.ProveRemote(tt.vrf, key, alpha); err == nil {
+					t.Fatal("faulty response accepted")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestRing(t *testing.T) {
+	alpha := []byte("slot 42")
+	for _, tt := range []struct {
+		curve elliptic.Curve
+		vrf   ecvrf.VRF
+	}{
+		{elliptic.P256(), ecvrf.NewP256Sha256Tai()},
+		{secp256k1.S256(), ecvrf.NewSecp256k1Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381))},
+	} {
+		var (
+			sks  []*ecdsa.PrivateKey
+			ring []*ecdsa.PublicKey
+		)
+		newKey := func() *ecdsa.PrivateKey {
+			if tt.curve == secp256k1.S256() {
+				k, _ := secp256k1.GeneratePrivateKey()
+				return k.ToECDSA()
+			}
+			sk, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			return sk
+		}
+		for i := 0; i < 5; i++ {
+			sk := newKey()
+			sks = append(sks, sk)
+			ring = append(ring, &sk.PublicKey)
+		}
+
+		betas := map[string]bool{}
+		for _, sk := range sks {
+			beta, pi, err := ecvrf.ProveRing(tt.vrf, sk, ring, alpha)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pi) != 33+16+5*32 {
+				t.Fatal("wrong proof length", len(pi))
+			}
+			if b, err := ecvrf.VerifyRing(tt.vrf, ring, alpha, pi); err != nil || !bytes.Equal(b, beta) {
+				t.Fatal("valid proof rejected", err)
+			}
+			betas[string(beta)] = true
+
+			// the output doesn't depend on the ring
+			b, _, err := ecvrf.ProveRing(tt.vrf, sk, []*ecdsa.PublicKey{&sk.PublicKey}, alpha)
+			if err != nil || !bytes.Equal(b, beta) {
+				t.Fatal("output depends on the ring", err)
+			}
+
+			if _, err := ecvrf.VerifyRing(tt.vrf, ring, []byte("slot 43"), pi); err == nil {
+				t.Fatal("proof of another input accepted")
+			}
+			swapped := append([]*ecdsa.PublicKey{ring[1], ring[0]}, ring[2:]...)
+			if _, err := ecvrf.VerifyRing(tt.vrf, swapped, alpha, pi); err == nil {
+				t.Fatal("proof of another ring accepted")
+			}
+			if _, err := ecvrf.VerifyRing(tt.vrf, ring[:4], alpha, pi); err == nil {
+				t.Fatal("proof of a larger ring accepted")
+			}
+			for i := 0; i < len(pi); i += 37 {
+				tampered := append([]byte(nil), pi...)
+				tampered[i] ^= 1
+				if _, err := ecvrf.VerifyRing(tt.vrf, ring, alpha, tampered); err == nil {
+					t.Fatal("tampered proof accepted at", i)
+				}
+			}
+		}
+		if len(betas) != len(sks) {
+			t.Fatal("members share outputs")
+		}
+
+		if _, _, err := ecvrf.ProveRing(tt.vrf, newKey(), ring, alpha); err == nil {
+			t.Fatal("proved by a key outside of the ring")
+		}
+		if _, _, err := ecvrf.ProveRing(tt.vrf, sks[0], nil, alpha); err == nil {
+			t.Fatal("proved with an empty ring")
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/shamir"
+)
+
+func TestShamir(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), secp256k1.S256()} {
+		var sk *ecdsa.PrivateKey
+		if curve == secp256k1.S256() {
+			k, _ := secp256k1.GeneratePrivateKey()
+			sk = k.ToECDSA()
+		} else {
+			sk, _ = ecdsa.GenerateKey(curve, rand.Reader)
+		}
+		shares, err := shamir.Split(sk, 3, 5, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range shares {
+			if err := s.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			if pk := s.PublicKey(); pk.X.Cmp(sk.X) != 0 || pk.Y.Cmp(sk.Y) != 0 {
+				t.Fatal("share of another public key")
+			}
+			parsed, err := shamir.ParseShare(curve, s.Bytes())
+			if err != nil || parsed.Value.Cmp(s.Value) != 0 || parsed.Index != s.Index {
+				t.Fatal("share not parsed back", err)
+			}
+		}
+
+		for _, subset := range [][]*shamir.Share{
+			shares[:3],
+			{shares[4], shares[1], shares[2]},
+			shares,
+		} {
+			key, err := shamir.Combine(subset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.D.Cmp(sk.D) != 0 {
+				t.Fatal("rebuilt another key")
+			}
+		}
+		if _, err := shamir.Combine(shares[:2]); err == nil {
+			t.Fatal("combined below the threshold")
+		}
+		if _, err := shamir.Combine([]*shamir.Share{shares[0], shares[0], shares[1]}); err == nil {
+			t.Fatal("combined duplicate shares")
+		}
+
+		corrupted := *shares[1]
+		corrupted.Value = new(big.Int).Add(corrupted.Value, big.NewInt(1))
+		_, err = shamir.Combine([]*shamir.Share{shares[0], &corrupted, shares[2]})
+		if se, ok := err.(*shamir.ShareError); !ok || se.Index != 2 {
+			t.Fatal("corrupted share not reported", err)
+		}
+		b := shares[3].Bytes()
+		b[5] ^= 1
+		if _, err := shamir.ParseShare(curve, b); err == nil {
+			t.Fatal("corrupted encoding accepted")
+		}
+
+		others, _ := shamir.Split(sk, 3, 5, rand.Reader)
+		if _, err := shamir.Combine([]*shamir.Share{shares[0], shares[1], others[2]}); err == nil {
+			t.Fatal("combined shares of different splits")
+		}
+	}
+
+	// a rebuilt key proves as the original
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	shares, _ := shamir.Split(sk, 1, 1, rand.Reader)
+	key, err := shamir.Combine(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vrf := ecvrf.NewP256Sha256Tai()
+	_, pi, _ := vrf.Prove(key, []byte("alpha"))
+	if _, err := vrf.Verify(&sk.PublicKey, []byte("alpha"), pi); err != nil {
+		t.Fatal(err)
+	}
+}
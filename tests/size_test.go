@@ -0,0 +1,78 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test_verifyOnly_binary checks that programs which only verify proofs don't link the proving code,
+// and logs the binary size to track it.
+func Test_verifyOnly_binary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a binary")
+	}
+	var (
+		dir = t.TempDir()
+		bin = filepath.Join(dir, "verifyonly")
+	)
+	if out, err := exec.Command("go", "build", "-o", bin, "./verifyonly").CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+	info, err := os.Stat(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("binary size: %v bytes", info.Size())
+
+	syms, err := exec.Command("go", "tool", "nm", bin).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go tool nm: %v\n%s", err, syms)
+	}
+	for _, name := range []string{"go-ecvrf.(*vrf).Prove", "go-ecvrf.rfc6979nonce", "go-ecvrf.(*hasherPool).newHMAC"} {
+		if strings.Contains(string(syms), name) {
+			t.Errorf("binary links %v", name)
+		}
+	}
+
+	if out, err := exec.Command(bin).CombinedOutput(); err != nil {
+		t.Fatalf("verifyonly: %v\n%s", err, out)
+	}
+}
+
+// Test_verifyOnly_tinygo checks that the verify-only program builds with TinyGo, when it is installed.
+func Test_verifyOnly_tinygo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a binary")
+	}
+	tinygo, err := exec.LookPath("tinygo")
+	if err != nil {
+		t.Skip("tinygo not installed")
+	}
+	bin := filepath.Join(t.TempDir(), "verifyonly")
+	if out, err := exec.Command(tinygo, "build", "-o", bin, "./verifyonly").CombinedOutput(); err != nil {
+		t.Fatalf("tinygo build: %v\n%s", err, out)
+	}
+	if out, err := exec.Command(bin).CombinedOutput(); err != nil {
+		t.Fatalf("verifyonly: %v\n%s", err, out)
+	}
+}
+
+// Test_stdlibOnly checks that the library, including the secp256k1 suite, depends on the standard
+// library only, so users of a single suite have no third-party code to review.
+func Test_stdlibOnly(t *testing.T) {
+	out, err := exec.Command("go", "list", "-deps", "-f", "{{if not .Standard}}{{.ImportPath}}{{end}}", "github.com/vechain/go-ecvrf").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go list: %v\n%s", err, out)
+	}
+	for _, pkg := range strings.Fields(string(out)) {
+		if pkg != "github.com/vechain/go-ecvrf" && !strings.HasPrefix(pkg, "github.com/vechain/go-ecvrf/internal/") {
+			t.Errorf("the library depends on %v", pkg)
+		}
+	}
+}
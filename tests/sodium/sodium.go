@@ -0,0 +1,58 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build libsodium
+
+// Package sodium binds crypto_vrf_ietfdraft03 of the libsodium fork of IOHK and Algorand, the
+// ECVRF-ED25519-SHA512-Elligator2 used as the other side of the differential harness.
+// Mainline libsodium has no VRF, so the fork must be installed to build with the libsodium tag.
+package sodium
+
+/*
+#cgo LDFLAGS: -lsodium
+#include <sodium.h>
+*/
+import "C"
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"unsafe"
+)
+
+var errFailed = errors.New("libsodium: operation failed")
+
+func init() {
+	if C.sodium_init() < 0 {
+		panic("libsodium: initialization failed")
+	}
+}
+
+func ptr(b []byte) *C.uchar {
+	if len(b) == 0 {
+		return nil
+	}
+	return (*C.uchar)(unsafe.Pointer(&b[0]))
+}
+
+// Prove calls crypto_vrf_ietfdraft03_prove and crypto_vrf_ietfdraft03_proof_to_hash.
+func Prove(sk ed25519.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
+	pi = make([]byte, C.crypto_vrf_ietfdraft03_PROOFBYTES)
+	if C.crypto_vrf_ietfdraft03_prove(ptr(pi), ptr(sk), ptr(alpha), C.ulonglong(len(alpha))) != 0 {
+		return nil, nil, errFailed
+	}
+	beta = make([]byte, C.crypto_vrf_ietfdraft03_OUTPUTBYTES)
+	if C.crypto_vrf_ietfdraft03_proof_to_hash(ptr(beta), ptr(pi)) != 0 {
+		return nil, nil, errFailed
+	}
+	return beta, pi, nil
+}
+
+// Verify calls crypto_vrf_ietfdraft03_verify.
+func Verify(pk ed25519.PublicKey, alpha, pi []byte) (beta []byte, err error) {
+	beta = make([]byte, C.crypto_vrf_ietfdraft03_OUTPUTBYTES)
+	if C.crypto_vrf_ietfdraft03_verify(ptr(beta), ptr(pk), ptr(pi), ptr(alpha), C.ulonglong(len(alpha))) != 0 {
+		return nil, errFailed
+	}
+	return beta, nil
+}
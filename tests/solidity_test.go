@@ -0,0 +1,107 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secpecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/solidity"
+)
+
+func Test_SolidityGenerate(t *testing.T) {
+	var draft, rfc bytes.Buffer
+	if err := solidity.Generate(&draft, solidity.Options{SuiteString: solidity.SuiteSecp256k1Sha256Tai}); err != nil {
+		t.Fatal(err)
+	}
+	if err := solidity.Generate(&rfc, solidity.Options{
+		Name:        "RFC9381Verifier",
+		SuiteString: solidity.SuiteSecp256k1Sha256Tai,
+		Spec:        ecvrf.RFC9381,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(draft.String(), "contract ECVRFVerifier {") || strings.Contains(draft.String(), "bytes1(0x00)") {
+		t.Fatal("unexpected draft-06 verifier")
+	}
+	if !strings.Contains(rfc.String(), "contract RFC9381Verifier {") || !strings.Contains(rfc.String(), "compress(pk), compress(h)") {
+		t.Fatal("unexpected RFC 9381 verifier")
+	}
+	if strings.Contains(rfc.String(), "{{") {
+		t.Fatal("template not fully expanded")
+	}
+
+	if err := solidity.Generate(&draft, solidity.Options{SuiteString: 0x01}); err == nil {
+		t.Fatal("Generate() accepted P256_SHA256_TAI")
+	}
+	if err := solidity.Generate(&draft, solidity.Options{Name: "a b", SuiteString: solidity.SuiteSecp256k1Sha256Tai}); err == nil {
+		t.Fatal("Generate() accepted an invalid name")
+	}
+}
+
+// ecrecover is the precompile of the EVM, returning the public key instead of its address.
+func ecrecover(t *testing.T, hash []byte, v byte, r, s *big.Int) *secp256k1.PublicKey {
+	sig := make([]byte, 65)
+	sig[0] = v
+	r.FillBytes(sig[1:33])
+	s.FillBytes(sig[33:])
+	pk, _, err := secpecdsa.RecoverCompact(sig, hash)
+	if err != nil {
+		t.Fatalf("ecrecover() error = %v", err)
+	}
+	return pk
+}
+
+func Test_SolidityCalldata(t *testing.T) {
+	sk := secp256k1.PrivKeyFromBytes(bytes.Repeat([]byte{7}, 32)).ToECDSA()
+	alpha := []byte("Hello VeChain")
+	for _, spec := range []ecvrf.SpecVersion{ecvrf.Draft06, ecvrf.RFC9381} {
+		vrf := ecvrf.NewSecp256k1Sha256Tai(ecvrf.WithSpecVersion(spec))
+		_, pi, err := vrf.Prove(sk, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := ecvrf.ExportEVMProof(vrf, &sk.PublicKey, alpha, pi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := solidity.CalldataOf(vrf, &sk.PublicKey, alpha, pi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, solidity.Calldata(p, alpha)) || !bytes.Equal(data[:4], solidity.VerifySelector[:]) {
+			t.Fatal("unexpected calldata")
+		}
+		word := func(i int) *big.Int { return new(big.Int).SetBytes(data[4+32*i : 4+32*(i+1)]) }
+		if word(4).Cmp(p.C) != 0 || word(12).Cmp(p.ZInv) != 0 || word(13).Int64() != 14*32 || word(14).Int64() != int64(len(alpha)) {
+			t.Fatal("unexpected calldata layout")
+		}
+		if len(data) != 4+32*16 || !bytes.Equal(data[4+32*15:4+32*15+len(alpha)], alpha) {
+			t.Fatalf("unexpected calldata %x", data)
+		}
+
+		// the ecrecover identities of the contract
+		n := secp256k1.S256().Params().N
+		parity := func(y *big.Int) byte { return 27 + byte(y.Bit(0)) }
+		// k * m = ecrecover(0, v, m.x, k * m.x)
+		cx := new(big.Int).Mul(p.C, p.Gamma[0])
+		got := ecrecover(t, make([]byte, 32), parity(p.Gamma[1]), p.Gamma[0], cx.Mod(cx, n)).ToECDSA()
+		if got.X.Cmp(p.CGammaWitness[0]) != 0 || got.Y.Cmp(p.CGammaWitness[1]) != 0 {
+			t.Fatal("c*Gamma isn't recovered")
+		}
+		// U = (n - c) * Y + s * B = ecrecover(-(Y.x * s), v, Y.x, (n - c) * Y.x)
+		h := new(big.Int).Mul(sk.X, p.S)
+		h.Sub(n, h.Mod(h, n))
+		k := new(big.Int).Mul(new(big.Int).Sub(n, p.C), sk.X)
+		got = ecrecover(t, h.FillBytes(make([]byte, 32)), parity(sk.Y), sk.X, k.Mod(k, n)).ToECDSA()
+		if got.X.Cmp(p.U[0]) != 0 || got.Y.Cmp(p.U[1]) != 0 {
+			t.Fatal("U isn't recovered")
+		}
+	}
+}
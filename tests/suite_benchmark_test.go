@@ -0,0 +1,101 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strconv"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/benchmark"
+)
+
+var suites = []struct {
+	name  string
+	vrf   ecvrf.VRF
+	curve elliptic.Curve
+}{
+	{"secp256k1sha256tai", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256()},
+	{"p256sha256tai", ecvrf.NewP256Sha256Tai(), elliptic.P256()},
+}
+
+// benchSuites runs the benchmark of the harness of each suite.
+func benchSuites(b *testing.B, run func(h *benchmark.Harness, b *testing.B)) {
+	for _, s := range suites {
+		sk, _ := ecdsa.GenerateKey(s.curve, rand.Reader)
+		h := benchmark.New(s.vrf, sk)
+		b.Run(s.name, func(b *testing.B) {
+			run(h, b)
+		})
+	}
+}
+
+func BenchmarkProve(b *testing.B) {
+	benchSuites(b, (*benchmark.Harness).RunProve)
+}
+
+func BenchmarkVerify(b *testing.B) {
+	benchSuites(b, (*benchmark.Harness).RunVerify)
+}
+
+func BenchmarkHashToCurve(b *testing.B) {
+	benchSuites(b, (*benchmark.Harness).RunHashToCurve)
+}
+
+func BenchmarkBatchVerify(b *testing.B) {
+	for _, s := range suites {
+		for _, size := range []int{16, 128} {
+			sk, _ := ecdsa.GenerateKey(s.curve, rand.Reader)
+			items := make([]ecvrf.BatchItem, size)
+			for i := range items {
+				alpha := []byte(strconv.Itoa(i))
+				_, pi, _ := s.vrf.Prove(sk, alpha)
+				items[i] = ecvrf.BatchItem{PublicKey: &sk.PublicKey, Alpha: alpha, Pi: pi}
+			}
+			for _, parallelism := range []int{1, 0} {
+				name := s.name + "/size=" + strconv.Itoa(size) + "/parallelism=" + strconv.Itoa(parallelism)
+				b.Run(name, func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						if _, err := ecvrf.VerifyBatch(s.vrf, items, ecvrf.WithParallelism(parallelism)); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkVerifyEd25519Batch(b *testing.B) {
+	v := ecvrf.NewEd25519Sha512Ell2BatchCompat()
+	_, sk, _ := ed25519.GenerateKey(rand.Reader)
+	items := make([]ecvrf.Ed25519BatchItem, 64)
+	for i := range items {
+		alpha := []byte(strconv.Itoa(i))
+		_, pi, _ := v.Prove(sk, alpha)
+		items[i] = ecvrf.Ed25519BatchItem{PublicKey: sk.Public().(ed25519.PublicKey), Alpha: alpha, Pi: pi}
+	}
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := ecvrf.VerifyEd25519Batch(v, items); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("one-by-one", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, item := range items {
+				if _, err := v.Verify(item.PublicKey, item.Alpha, item.Pi); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
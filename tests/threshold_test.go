@@ -0,0 +1,147 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/threshold"
+)
+
+// dkg runs the DKG of t-of-n among honest parties.
+func dkg(t *testing.T, c elliptic.Curve, th, n int) []*threshold.KeyShare {
+	commitments := map[int][][2]*big.Int{}
+	shares := make([]map[int]*big.Int, n)
+	for i := range shares {
+		shares[i] = map[int]*big.Int{}
+	}
+	for dealer := 1; dealer <= n; dealer++ {
+		deal, err := threshold.NewDeal(c, th, n, dealer, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitments[dealer] = deal.Commitments
+		for j, share := range deal.Shares {
+			if err := threshold.VerifyShare(c, j+1, share, deal.Commitments); err != nil {
+				t.Fatal(err)
+			}
+			shares[j][dealer] = share
+		}
+	}
+	keys := make([]*threshold.KeyShare, n)
+	for i := range keys {
+		ks, err := threshold.NewKeyShare(c, th, n, i+1, shares[i], commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = ks
+	}
+	return keys
+}
+
+// prove runs the two rounds among the parties, tampering with the partial of the party bad, if any.
+func prove(t *testing.T, v ecvrf.VRF, parties []*threshold.KeyShare, alpha []byte, bad int) ([]byte, []byte, error) {
+	var (
+		nonces      []*threshold.Nonce
+		commitments []*threshold.Commitment
+		partials    []*threshold.Partial
+	)
+	for _, ks := range parties {
+		nonce, cm, err := ks.Commit(v, alpha, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonces = append(nonces, nonce)
+		commitments = append(commitments, cm)
+	}
+	for i, ks := range parties {
+		p, err := ks.Respond(v, alpha, nonces[i], commitments)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ks.ID == bad {
+			p.S.Add(p.S, big.NewInt(1))
+		}
+		partials = append(partials, p)
+	}
+	if _, err := parties[0].Respond(v, alpha, nonces[0], commitments); err == nil {
+		t.Fatal("nonce used twice")
+	}
+	return parties[0].Combine(v, alpha, commitments, partials)
+}
+
+func TestThreshold(t *testing.T) {
+	alpha := []byte("round 1")
+	for _, tt := range []struct {
+		curve elliptic.Curve
+		vrf   ecvrf.VRF
+	}{
+		{elliptic.P256(), ecvrf.NewP256Sha256Tai()},
+		{secp256k1.S256(), ecvrf.NewSecp256k1Sha256Tai()},
+	} {
+		keys := dkg(t, tt.curve, 3, 5)
+		pk := keys[0].PublicKey
+
+		// the key is the interpolation of any t shares
+		x := new(big.Int)
+		q := tt.curve.Params().N
+		for _, i := range []int{1, 2, 3} {
+			// lambda_1 = 3, lambda_2 = -3, lambda_3 = 1
+			l := map[int]int64{1: 3, 2: -3, 3: 1}[i]
+			x.Add(x, new(big.Int).Mul(big.NewInt(l), keys[i-1].Secret))
+		}
+		sk := &ecdsa.PrivateKey{PublicKey: *pk, D: x.Mod(x, q)}
+		want, _, err := tt.vrf.Prove(sk, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, set := range [][]int{{1, 3, 5}, {2, 4, 5}, {1, 2, 3, 4}} {
+			var parties []*threshold.KeyShare
+			for _, i := range set {
+				parties = append(parties, keys[i-1])
+			}
+			beta, pi, err := prove(t, tt.vrf, parties, alpha, 0)
+			if err != nil {
+				t.Fatal(set, err)
+			}
+			if !bytes.Equal(beta, want) {
+				t.Fatal(set, "output isn't the one of the group key")
+			}
+			if b, err := tt.vrf.Verify(pk, alpha, pi); err != nil || !bytes.Equal(b, want) {
+				t.Fatal(set, "standard verification failed", err)
+			}
+		}
+
+		_, _, err = prove(t, tt.vrf, []*threshold.KeyShare{keys[0], keys[1], keys[4]}, alpha, 2)
+		if pe, ok := err.(*threshold.PartialError); !ok || pe.ID != 2 {
+			t.Fatal("invalid partial not reported", err)
+		}
+		if _, _, err := prove(t, tt.vrf, keys[:2], alpha, 0); err == nil {
+			t.Fatal("proved with less than t parties")
+		}
+	}
+}
+
+func TestThresholdBadShare(t *testing.T) {
+	c := elliptic.P256()
+	deal, err := threshold.NewDeal(c, 2, 3, 1, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := new(big.Int).Add(deal.Shares[1], big.NewInt(1))
+	if threshold.VerifyShare(c, 2, bad, deal.Commitments) == nil {
+		t.Fatal("bad share accepted")
+	}
+	if threshold.VerifyShare(c, 3, deal.Shares[1], deal.Commitments) == nil {
+		t.Fatal("share of another party accepted")
+	}
+}
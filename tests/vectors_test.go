@@ -0,0 +1,78 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/vectors"
+)
+
+func TestVectorsPackage(t *testing.T) {
+	p256Key := func(b []byte) *ecdsa.PublicKey {
+		x, y := elliptic.UnmarshalCompressed(elliptic.P256(), b)
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	}
+	secp256k1Key := func(b []byte) *ecdsa.PublicKey {
+		pk, err := secp256k1.ParsePubKey(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pk.ToECDSA()
+	}
+	for _, s := range []struct {
+		name  string
+		vrf   ecvrf.VRF
+		key   func([]byte) *ecdsa.PublicKey
+		cases []vectors.Case
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1Key, vectors.Secp256k1Sha256Tai()},
+		{"secp256k1 rfc9381", ecvrf.NewSecp256k1Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381)), secp256k1Key, vectors.Secp256k1Sha256TaiRFC9381()},
+		{"p256", ecvrf.NewP256Sha256Tai(), p256Key, vectors.P256Sha256Tai()},
+		{"p256 rfc9381", ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381)), p256Key, vectors.P256Sha256TaiRFC9381()},
+	} {
+		if len(s.cases) == 0 {
+			t.Fatalf("%s: no cases", s.name)
+		}
+		for i, c := range s.cases {
+			if beta, err := s.vrf.Verify(s.key(c.PK), c.Alpha, c.Pi); err != nil || !bytes.Equal(beta, c.Beta) {
+				t.Errorf("%s: case %d: Verify() = %x, %v, want %x", s.name, i, beta, err, c.Beta)
+			}
+			if c.H == nil || c.K == nil || c.Gamma == nil || c.U == nil || c.V == nil || c.C == nil || c.S == nil || !bytes.HasPrefix(c.Pi, c.Gamma) {
+				t.Errorf("%s: case %d: missing intermediate values", s.name, i)
+			}
+		}
+	}
+	for _, s := range []struct {
+		name  string
+		vrf   ecvrf.Ed25519VRF
+		cases []vectors.Case
+	}{
+		{"elligator2", ecvrf.NewEd25519Sha512Elligator2(), vectors.Ed25519Sha512Elligator2()},
+		{"ell2", ecvrf.NewEd25519Sha512Ell2(), vectors.Ed25519Sha512Ell2()},
+		{"ell2 batchcompat", ecvrf.NewEd25519Sha512Ell2BatchCompat(), vectors.Ed25519Sha512Ell2BatchCompat()},
+	} {
+		for i, c := range s.cases {
+			if beta, err := s.vrf.Verify(ed25519.PublicKey(c.PK), c.Alpha, c.Pi); err != nil || !bytes.Equal(beta, c.Beta) {
+				t.Errorf("%s: case %d: Verify() = %x, %v, want %x", s.name, i, beta, err, c.Beta)
+			}
+			if c.H == nil || c.K == nil || c.Gamma == nil || c.U == nil || c.V == nil || c.C == nil || c.S == nil || !bytes.HasPrefix(c.Pi, c.Gamma) {
+				t.Errorf("%s: case %d: missing intermediate values", s.name, i)
+			}
+		}
+	}
+
+	// each call returns its own cases
+	a, b := vectors.P256Sha256Tai(), vectors.P256Sha256Tai()
+	a[0].Pi[0] ^= 1
+	if bytes.Equal(a[0].Pi, b[0].Pi) {
+		t.Fatal("cases share their slices")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/verifycache"
+)
+
+// countingVRF counts the verifications reaching the VRF.
+type countingVRF struct {
+	ecvrf.VRF
+	mu sync.Mutex
+	n  int
+}
+
+func (v *countingVRF) Verify(pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error) {
+	v.mu.Lock()
+	v.n++
+	v.mu.Unlock()
+	return v.VRF.Verify(pk, alpha, pi)
+}
+
+func TestVerifyCache(t *testing.T) {
+	inner := &countingVRF{VRF: ecvrf.NewP256Sha256Tai()}
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	beta, pi, err := inner.Prove(sk, []byte("gossip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := verifycache.New()
+	vrf := c.Wrap(inner)
+	for i := 0; i < 3; i++ {
+		got, err := vrf.NewVerifier(&sk.PublicKey).Verify([]byte("gossip"), pi)
+		if err != nil || !bytes.Equal(got, beta) {
+			t.Fatalf("Verify() = %x, %v", got, err)
+		}
+		got[0] ^= 1
+	}
+	// failures are cached too
+	for i := 0; i < 2; i++ {
+		if _, err := vrf.Verify(&sk.PublicKey, []byte("other"), pi); err == nil {
+			t.Fatal("Verify() accepted the proof of another input")
+		}
+	}
+	if dst, err := vrf.AppendVerify([]byte{7}, &sk.PublicKey, []byte("gossip"), pi); err != nil || !bytes.Equal(dst, append([]byte{7}, beta...)) {
+		t.Errorf("AppendVerify() = %x, %v", dst, err)
+	}
+	if inner.n != 2 {
+		t.Errorf("%v verifications reached the VRF, want 2", inner.n)
+	}
+	if s := c.Stats(); s.Hits != 4 || s.Misses != 2 || s.Len != 2 || s.HitRate() != 4.0/6 {
+		t.Errorf("Stats() = %+v", s)
+	}
+
+	// the same proof under another suite is another entry
+	other := &countingVRF{VRF: ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381))}
+	if _, err := c.Verify(other, &sk.PublicKey, []byte("gossip"), pi); err == nil || other.n != 1 {
+		t.Errorf("a proof of draft-06 hit the cache of RFC 9381, err = %v", err)
+	}
+	c.Purge()
+	if s := c.Stats(); s.Len != 0 {
+		t.Errorf("Stats() after Purge() = %+v", s)
+	}
+}
+
+func TestVerifyCacheLimits(t *testing.T) {
+	inner := &countingVRF{VRF: ecvrf.NewP256Sha256Tai()}
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var pis [][]byte
+	for i := 0; i < 3; i++ {
+		_, pi, _ := inner.Prove(sk, []byte(strconv.Itoa(i)))
+		pis = append(pis, pi)
+	}
+	now := time.Unix(1000, 0)
+	c := verifycache.New(verifycache.WithSize(2), verifycache.WithTTL(time.Minute), verifycache.WithClock(func() time.Time { return now }))
+	verify := func(i int) {
+		if _, err := c.Verify(inner, &sk.PublicKey, []byte(strconv.Itoa(i)), pis[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the least recently used entry is evicted
+	verify(0)
+	verify(1)
+	verify(0)
+	verify(2)
+	verify(0)
+	if inner.n != 3 {
+		t.Errorf("%v verifications, want 3", inner.n)
+	}
+	verify(1)
+	if s := c.Stats(); inner.n != 4 || s.Evictions != 2 || s.Len != 2 {
+		t.Errorf("%v verifications, Stats() = %+v", inner.n, s)
+	}
+
+	// and expired entries are verified again
+	now = now.Add(time.Minute)
+	verify(1)
+	if s := c.Stats(); inner.n != 5 || s.Expirations != 1 {
+		t.Errorf("%v verifications, Stats() = %+v", inner.n, s)
+	}
+}
+
+func TestVerifyCacheKeys(t *testing.T) {
+	inner := ecvrf.NewP256Sha256Tai()
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, pi, err := inner.Prove(sk, []byte("gossip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := verifycache.New()
+	if _, err := c.Verify(inner, &sk.PublicKey, []byte("gossip"), pi); err != nil {
+		t.Fatal(err)
+	}
+
+	// keys looking like the cached one get the results of the VRF, not the cached beta
+	params := *elliptic.P256().Params()
+	params.Name = "P-256 copy"
+	for _, pk := range []*ecdsa.PublicKey{
+		{Curve: elliptic.P256(), X: new(big.Int).Neg(sk.X), Y: sk.Y},
+		{Curve: elliptic.P256(), X: sk.X, Y: new(big.Int).Add(sk.Y, elliptic.P256().Params().P)},
+		{Curve: elliptic.P384(), X: sk.X, Y: sk.Y},
+		{Curve: &params, X: sk.X, Y: sk.Y},
+	} {
+		_, want := inner.Verify(pk, []byte("gossip"), pi)
+		if _, err := c.Verify(inner, pk, []byte("gossip"), pi); (err == nil) != (want == nil) {
+			t.Errorf("Verify() of %v: cached error %v, uncached %v", pk.Curve.Params().Name, err, want)
+		}
+	}
+}
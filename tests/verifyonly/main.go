@@ -0,0 +1,33 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Command verifyonly is a minimal verifier, used to track the binary size of programs which only check proofs,
+// and to try builds with TinyGo, when it is installed:
+//
+//	go build ./verifyonly
+//	tinygo build ./verifyonly
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"os"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+func main() {
+	var (
+		pkBytes, _ = hex.DecodeString("0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6")
+		alpha      = []byte("sample")
+		pi, _      = hex.DecodeString("029bdca4cc39e57d97e2f42f88bcf0ecb1120fb67eb408a856050dbfbcbf57c524347fc46ccd87843ec0a9fdc090a407c6fbae8ac1480e240c58854897eabbc3a7bb61b201059f89186e7175af796d65e7")
+	)
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), pkBytes)
+	beta, err := ecvrf.NewP256Sha256Tai().Verify(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, alpha, pi)
+	if err != nil {
+		println(err.Error())
+		os.Exit(1)
+	}
+	println(hex.EncodeToString(beta))
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestVOPRF(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256()},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			other, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			input := []byte("rate-limit token")
+
+			b1, err := ecvrf.BlindOPRF(tt.vrf, tt.curve, input, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b2, _ := ecvrf.BlindOPRF(tt.vrf, tt.curve, input, rand.Reader)
+			if b1.Element[0].Cmp(b2.Element[0]) == 0 {
+				t.Fatalf("blinded elements of the same input are equal")
+			}
+
+			evaluated, proof, err := ecvrf.BlindEvaluateOPRF(tt.vrf, sk, b1.Element)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ecvrf.FinalizeOPRF(tt.vrf, &other.PublicKey, b1, evaluated, proof); err == nil {
+				t.Fatalf("FinalizeOPRF() accepted the evaluation for another key")
+			}
+			out1, err := ecvrf.FinalizeOPRF(tt.vrf, &sk.PublicKey, b1, evaluated, proof)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ecvrf.FinalizeOPRF(tt.vrf, &sk.PublicKey, b1, evaluated, proof); err == nil {
+				t.Errorf("FinalizeOPRF() reused a wiped blind")
+			}
+
+			evaluated2, proof2, _ := ecvrf.BlindEvaluateOPRF(tt.vrf, sk, b2.Element)
+			out2, err := ecvrf.FinalizeOPRF(tt.vrf, &sk.PublicKey, b2, evaluated2, proof2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := ecvrf.EvaluateOPRF(tt.vrf, sk, input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out1, want) || !bytes.Equal(out2, want) {
+				t.Fatalf("outputs differ: %x, %x, %x", out1, out2, want)
+			}
+			if got, _ := ecvrf.EvaluateOPRF(tt.vrf, other, input); bytes.Equal(got, want) {
+				t.Errorf("outputs of two keys are equal")
+			}
+
+			// an evaluation by another key, with its valid proof, is rejected
+			b3, _ := ecvrf.BlindOPRF(tt.vrf, tt.curve, input, rand.Reader)
+			forged, forgedProof, _ := ecvrf.BlindEvaluateOPRF(tt.vrf, other, b3.Element)
+			if _, err := ecvrf.FinalizeOPRF(tt.vrf, &sk.PublicKey, b3, forged, forgedProof); err == nil {
+				t.Errorf("FinalizeOPRF() accepted the evaluation by another key")
+			}
+			if _, _, err := ecvrf.BlindEvaluateOPRF(tt.vrf, sk, [2]*big.Int{big.NewInt(1), big.NewInt(1)}); err == nil {
+				t.Errorf("BlindEvaluateOPRF() accepted an element off the curve")
+			}
+		})
+	}
+}
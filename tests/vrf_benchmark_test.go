@@ -9,13 +9,13 @@ import (
 	"crypto/rand"
 	"testing"
 
-	"github.com/btcsuite/btcd/btcec"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/vechain/go-ecvrf"
 )
 
 func BenchmarkVRF(b *testing.B) {
 	b.Run("secp256k1sha256tai-proving", func(b *testing.B) {
-		sk, _ := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+		sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
 		alpha := []byte("Hello VeChain")
 
 		for i := 0; i < b.N; i++ {
@@ -25,8 +25,22 @@ func BenchmarkVRF(b *testing.B) {
 			}
 		}
 	})
+	b.Run("secp256k1sha256tai-append-proving", func(b *testing.B) {
+		sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+		alpha := []byte("Hello VeChain")
+
+		vrf := ecvrf.NewSecp256k1Sha256Tai()
+		beta, pi := make([]byte, 0, 32), make([]byte, 0, 81)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _, err := ecvrf.AppendProve(beta[:0], pi[:0], vrf, sk, alpha)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 	b.Run("secp256k1sha256tai-verifying", func(b *testing.B) {
-		sk, _ := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+		sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
 		alpha := []byte("Hello VeChain")
 
 		_, pi, _ := ecvrf.NewSecp256k1Sha256Tai().Prove(sk, alpha)
@@ -37,6 +51,36 @@ func BenchmarkVRF(b *testing.B) {
 			}
 		}
 	})
+	b.Run("secp256k1sha256tai-append-verifying", func(b *testing.B) {
+		sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+		alpha := []byte("Hello VeChain")
+
+		vrf := ecvrf.NewSecp256k1Sha256Tai()
+		_, pi, _ := vrf.Prove(sk, alpha)
+		beta := make([]byte, 0, 32)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, err := vrf.AppendVerify(beta[:0], &sk.PublicKey, alpha, pi)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("secp256k1sha256tai-verifier-verifying", func(b *testing.B) {
+		sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+		alpha := []byte("Hello VeChain")
+
+		vrf := ecvrf.NewSecp256k1Sha256Tai()
+		_, pi, _ := vrf.Prove(sk, alpha)
+		verifier := vrf.NewVerifier(&sk.PublicKey)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := verifier.Verify(alpha, pi)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 	b.Run("p256sha256tai-proving", func(b *testing.B) {
 		sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 		alpha := []byte("Hello VeChain")
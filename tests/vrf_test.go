@@ -4,8 +4,11 @@
 package tests
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"io/ioutil"
@@ -15,7 +18,7 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/btcsuite/btcd/btcec"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/vechain/go-ecvrf"
 )
 
@@ -26,6 +29,15 @@ type Case struct {
 	Alpha string `json:"alpha"`
 	Pi    string `json:"pi"`
 	Beta  string `json:"beta"`
+
+	// intermediate values of the proof, as generated by cmd/vrfvectors, if given
+	H     string `json:"h,omitempty"`
+	K     string `json:"k,omitempty"`
+	Gamma string `json:"gamma,omitempty"`
+	U     string `json:"u,omitempty"`
+	V     string `json:"v,omitempty"`
+	C     string `json:"c,omitempty"`
+	S     string `json:"s,omitempty"`
 }
 
 func readCases(fileName string) ([]Case, error) {
@@ -51,7 +63,7 @@ func readCases(fileName string) ([]Case, error) {
 
 func Test_Secp256K1Sha256Tai_vrf_Prove(t *testing.T) {
 	// Know Correct cases.
-	var cases, _ = readCases("./secp256_k1_sha256_tai.json")
+	var cases, _ = readCases("../vectors/secp256_k1_sha256_tai.json")
 
 	type Test struct {
 		name     string
@@ -65,7 +77,7 @@ func Test_Secp256K1Sha256Tai_vrf_Prove(t *testing.T) {
 	tests := []Test{}
 	for _, c := range cases {
 		skBytes, _ := hex.DecodeString(c.Sk)
-		sk, _ := btcec.PrivKeyFromBytes(btcec.S256(), skBytes)
+		sk := secp256k1.PrivKeyFromBytes(skBytes)
 
 		alpha, _ := hex.DecodeString(c.Alpha)
 		wantBeta, _ := hex.DecodeString(c.Beta)
@@ -103,7 +115,7 @@ func Test_Secp256K1Sha256Tai_vrf_Prove(t *testing.T) {
 
 func Test_Secp256K1Sha256Tai_vrf_Verify(t *testing.T) {
 	// Know Correct cases.
-	var cases, _ = readCases("./secp256_k1_sha256_tai.json")
+	var cases, _ = readCases("../vectors/secp256_k1_sha256_tai.json")
 
 	type Test struct {
 		name     string
@@ -117,7 +129,7 @@ func Test_Secp256K1Sha256Tai_vrf_Verify(t *testing.T) {
 	tests := []Test{}
 	for _, c := range cases {
 		skBytes, _ := hex.DecodeString(c.Sk)
-		sk, _ := btcec.PrivKeyFromBytes(btcec.S256(), skBytes)
+		sk := secp256k1.PrivKeyFromBytes(skBytes)
 
 		pk := sk.PubKey().ToECDSA()
 
@@ -156,7 +168,7 @@ func Test_Secp256K1Sha256Tai_vrf_Verify(t *testing.T) {
 
 func Test_P256Sha256Tai_vrf_Prove(t *testing.T) {
 	// Know Correct cases.
-	var P256Sha256TaiCases, _ = readCases("./p256_sha256_tai.json")
+	var P256Sha256TaiCases, _ = readCases("../vectors/p256_sha256_tai.json")
 
 	type Test struct {
 		name     string
@@ -216,7 +228,7 @@ func Test_P256Sha256Tai_vrf_Prove(t *testing.T) {
 
 func Test_P256Sha256Tai_vrf_Verify(t *testing.T) {
 	// Know Correct cases.
-	var P256Sha256TaiCases, _ = readCases("./p256_sha256_tai.json")
+	var P256Sha256TaiCases, _ = readCases("../vectors/p256_sha256_tai.json")
 
 	type Test struct {
 		name     string
@@ -269,3 +281,904 @@ func Test_P256Sha256Tai_vrf_Verify(t *testing.T) {
 		})
 	}
 }
+
+func Test_vrf_EncodeToCurve(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+		file  string
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256(), "../vectors/secp256_k1_sha256_tai.json"},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256(), "../vectors/p256_sha256_tai.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, err := readCases(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range cases {
+				skBytes, _ := hex.DecodeString(c.Sk)
+				alpha, _ := hex.DecodeString(c.Alpha)
+
+				pkX, pkY := tt.curve.ScalarBaseMult(skBytes)
+				pk := &ecdsa.PublicKey{Curve: tt.curve, X: pkX, Y: pkY}
+
+				x, y, err := tt.vrf.EncodeToCurve(pk, alpha)
+				if err != nil {
+					t.Fatalf("vrf.EncodeToCurve() error = %v", err)
+				}
+				if !tt.curve.IsOnCurve(x, y) {
+					t.Fatalf("vrf.EncodeToCurve() = (%v, %v), not on curve", x, y)
+				}
+
+				x2, y2, _ := tt.vrf.EncodeToCurve(pk, alpha)
+				if x.Cmp(x2) != 0 || y.Cmp(y2) != 0 {
+					t.Fatalf("vrf.EncodeToCurve() is not deterministic")
+				}
+
+				x3, _, _ := tt.vrf.EncodeToCurve(pk, append(alpha, 0))
+				if x.Cmp(x3) == 0 {
+					t.Fatalf("vrf.EncodeToCurve() maps different inputs to the same point")
+				}
+			}
+		})
+	}
+}
+
+func Test_vrf_Intermediates(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+		file  string
+	}{
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256(), "../vectors/p256_sha256_tai.json"},
+		{"p256 rfc9381", ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381)), elliptic.P256(), "../vectors/p256_sha256_tai_rfc9381.json"},
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256(), "../vectors/secp256_k1_sha256_tai.json"},
+		{"secp256k1 rfc9381", ecvrf.NewSecp256k1Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381)), secp256k1.S256(), "../vectors/secp256_k1_sha256_tai_rfc9381.json"},
+	}
+	for _, tt := range tests {
+		curve := tt.curve
+		t.Run(tt.name, func(t *testing.T) {
+			cases, err := readCases(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range cases {
+				if c.H == "" {
+					t.Fatal("no intermediate values")
+				}
+				skBytes, _ := hex.DecodeString(c.Sk)
+				alpha, _ := hex.DecodeString(c.Alpha)
+				sk := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(skBytes)}
+				sk.Curve = curve
+				sk.X, sk.Y = curve.ScalarBaseMult(skBytes)
+
+				hx, hy, err := tt.vrf.EncodeToCurve(&sk.PublicKey, alpha)
+				if err != nil {
+					t.Fatal(err)
+				}
+				h := elliptic.MarshalCompressed(curve, hx, hy)
+				if got := hex.EncodeToString(h); got != c.H {
+					t.Errorf("vrf.EncodeToCurve() = %v, want %v", got, c.H)
+				}
+				k := tt.vrf.GenerateNonce(sk, h)
+				if got := hex.EncodeToString(k.FillBytes(make([]byte, 32))); got != c.K {
+					t.Errorf("vrf.GenerateNonce() = %v, want %v", got, c.K)
+				}
+				ux, uy := curve.ScalarBaseMult(k.Bytes())
+				vx, vy := curve.ScalarMult(hx, hy, k.Bytes())
+				if got := hex.EncodeToString(elliptic.MarshalCompressed(curve, ux, uy)); got != c.U {
+					t.Errorf("U = %v, want %v", got, c.U)
+				}
+				if got := hex.EncodeToString(elliptic.MarshalCompressed(curve, vx, vy)); got != c.V {
+					t.Errorf("V = %v, want %v", got, c.V)
+				}
+				if c.Gamma+c.C+c.S != c.Pi {
+					t.Errorf("pi = %v, want Gamma || c || s", c.Pi)
+				}
+			}
+		})
+	}
+}
+
+func Test_Ed25519Terms(t *testing.T) {
+	tests := []struct {
+		name string
+		vrf  ecvrf.Ed25519VRF
+		file string
+	}{
+		{"draft03", ecvrf.NewEd25519Sha512Elligator2(), "../vectors/ed25519_sha512_elligator2.json"},
+		{"ell2", ecvrf.NewEd25519Sha512Ell2(), "../vectors/ed25519_sha512_ell2.json"},
+		{"batchcompat", ecvrf.NewEd25519Sha512Ell2BatchCompat(), "../vectors/ed25519_sha512_ell2_batchcompat.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, err := readCases(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range cases {
+				pk, _ := hex.DecodeString(c.Pk)
+				alpha, _ := hex.DecodeString(c.Alpha)
+				pi, _ := hex.DecodeString(c.Pi)
+				terms, err := ecvrf.Ed25519Terms(tt.vrf, pk, alpha, pi)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, v := range []struct {
+					name      string
+					got, want string
+				}{
+					{"H", hex.EncodeToString(terms.H), c.H},
+					{"Gamma", hex.EncodeToString(terms.Gamma), c.Gamma},
+					{"U", hex.EncodeToString(terms.U), c.U},
+					{"V", hex.EncodeToString(terms.V), c.V},
+					{"c", hex.EncodeToString(terms.C), c.C},
+					{"s", hex.EncodeToString(terms.S), c.S},
+				} {
+					if v.got != v.want {
+						t.Errorf("%s = %v, want %v", v.name, v.got, v.want)
+					}
+				}
+				pi[40] ^= 1
+				if _, err := ecvrf.Ed25519Terms(tt.vrf, pk, alpha, pi); err == nil {
+					t.Error("Ed25519Terms() of an invalid proof succeeded")
+				}
+			}
+		})
+	}
+}
+
+func Test_P256Sha256Tai_vrf_GenerateNonce(t *testing.T) {
+	// test vectors from RFC6979 A.2.5, with SHA-256
+	skBytes, _ := hex.DecodeString("c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721")
+	curve := elliptic.P256()
+	pkX, pkY := curve.ScalarBaseMult(skBytes)
+	sk := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: curve,
+			X:     pkX,
+			Y:     pkY,
+		},
+		D: new(big.Int).SetBytes(skBytes),
+	}
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"sample", "a6e3c57dd01abe90086538398355dd4c3b17aa873382b0f24d6129493d8aad60"},
+		{"test", "d16b6ae827f17175e040871a1c7ec3500192c4c92677336ec2537acaee0008e0"},
+	}
+
+	vrf := ecvrf.NewP256Sha256Tai()
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			got := vrf.GenerateNonce(sk, []byte(tt.data))
+			if gotStr := hex.EncodeToString(got.Bytes()); gotStr != tt.want {
+				t.Errorf("vrf.GenerateNonce() = %v, want %v", gotStr, tt.want)
+			}
+		})
+	}
+}
+
+func Test_vrf_AppendVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		vrf        ecvrf.VRF
+		curve      elliptic.Curve
+		file       string
+		wantAllocs float64 // -1 if not checked
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256(), "../vectors/secp256_k1_sha256_tai.json", 0},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256(), "../vectors/p256_sha256_tai.json", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, err := readCases(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range cases {
+				skBytes, _ := hex.DecodeString(c.Sk)
+				alpha, _ := hex.DecodeString(c.Alpha)
+				pi, _ := hex.DecodeString(c.Pi)
+				wantBeta, _ := hex.DecodeString(c.Beta)
+
+				pkX, pkY := tt.curve.ScalarBaseMult(skBytes)
+				pk := &ecdsa.PublicKey{Curve: tt.curve, X: pkX, Y: pkY}
+
+				prefix := []byte("beta:")
+				got, err := tt.vrf.AppendVerify(append([]byte{}, prefix...), pk, alpha, pi)
+				if err != nil {
+					t.Fatalf("vrf.AppendVerify() error = %v", err)
+				}
+				if want := append(prefix, wantBeta...); !reflect.DeepEqual(got, want) {
+					t.Fatalf("vrf.AppendVerify() = %x, want %x", got, want)
+				}
+
+				// tampered proofs must be rejected, leaving dst untouched
+				for _, i := range []int{0, 1, len(pi) - 40, len(pi) - 1} {
+					bad := append([]byte{}, pi...)
+					bad[i] ^= 1
+					if got, err := tt.vrf.AppendVerify(prefix, pk, alpha, bad); err == nil || !reflect.DeepEqual(got, prefix) {
+						t.Fatalf("vrf.AppendVerify() = %x, %v, want error", got, err)
+					}
+				}
+
+				if tt.wantAllocs >= 0 && !raceEnabled {
+					dst := make([]byte, 0, 64)
+					allocs := testing.AllocsPerRun(10, func() {
+						tt.vrf.AppendVerify(dst, pk, alpha, pi)
+					})
+					if allocs != tt.wantAllocs {
+						t.Fatalf("vrf.AppendVerify() allocs = %v, want %v", allocs, tt.wantAllocs)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_vrf_AppendProve(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+		file  string
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256(), "../vectors/secp256_k1_sha256_tai.json"},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256(), "../vectors/p256_sha256_tai.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, err := readCases(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range cases {
+				skBytes, _ := hex.DecodeString(c.Sk)
+				alpha, _ := hex.DecodeString(c.Alpha)
+				wantPi, _ := hex.DecodeString(c.Pi)
+				wantBeta, _ := hex.DecodeString(c.Beta)
+
+				sk := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(skBytes)}
+				sk.Curve = tt.curve
+				sk.X, sk.Y = tt.curve.ScalarBaseMult(skBytes)
+
+				betaPrefix, piPrefix := []byte("beta:"), []byte("pi:")
+				beta, pi, err := ecvrf.AppendProve(append([]byte{}, betaPrefix...), append([]byte{}, piPrefix...), tt.vrf, sk, alpha)
+				if err != nil {
+					t.Fatalf("AppendProve() error = %v", err)
+				}
+				if want := append(betaPrefix, wantBeta...); !reflect.DeepEqual(beta, want) {
+					t.Fatalf("AppendProve() beta = %x, want %x", beta, want)
+				}
+				if want := append(piPrefix, wantPi...); !reflect.DeepEqual(pi, want) {
+					t.Fatalf("AppendProve() pi = %x, want %x", pi, want)
+				}
+
+				got, err := ecvrf.AppendProofToHash(betaPrefix, tt.vrf, tt.curve, wantPi)
+				if err != nil {
+					t.Fatalf("AppendProofToHash() error = %v", err)
+				}
+				if want := append(betaPrefix, wantBeta...); !reflect.DeepEqual(got, want) {
+					t.Fatalf("AppendProofToHash() = %x, want %x", got, want)
+				}
+				if got, err := ecvrf.AppendProofToHash(betaPrefix, tt.vrf, tt.curve, wantPi[1:]); err == nil || !reflect.DeepEqual(got, betaPrefix) {
+					t.Fatalf("AppendProofToHash() = %x, %v, want error", got, err)
+				}
+			}
+
+			// invalid keys leave the buffers untouched
+			beta, pi, err := ecvrf.AppendProve([]byte("beta:"), []byte("pi:"), tt.vrf, &ecdsa.PrivateKey{}, nil)
+			if err == nil || string(beta) != "beta:" || string(pi) != "pi:" {
+				t.Fatalf("AppendProve() = %q, %q, %v, want error", beta, pi, err)
+			}
+		})
+	}
+}
+
+func Test_vrf_FixedHashToCurve(t *testing.T) {
+	tests := []struct {
+		name   string
+		newVRF func(opts ...ecvrf.Option) ecvrf.VRF
+		curve  elliptic.Curve
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai, secp256k1.S256()},
+		{"p256", ecvrf.NewP256Sha256Tai, elliptic.P256()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			plain, fixed := tt.newVRF(), tt.newVRF(ecvrf.WithFixedHashToCurve(64))
+			for i := 0; i < 10; i++ {
+				alpha := []byte{byte(i)}
+				beta, pi, err := plain.Prove(sk, alpha)
+				if err != nil {
+					t.Fatal(err)
+				}
+				beta2, pi2, err := fixed.Prove(sk, alpha)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(beta, beta2) || !bytes.Equal(pi, pi2) {
+					t.Fatalf("proof differs with WithFixedHashToCurve for alpha %x", alpha)
+				}
+				if got, err := fixed.Verify(&sk.PublicKey, alpha, pi); err != nil || !bytes.Equal(got, beta) {
+					t.Fatalf("Verify() = %x, %v with WithFixedHashToCurve", got, err)
+				}
+			}
+		})
+	}
+}
+
+func Test_vrf_NewVerifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+		file  string
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256(), "../vectors/secp256_k1_sha256_tai.json"},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256(), "../vectors/p256_sha256_tai.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, err := readCases(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range cases {
+				skBytes, _ := hex.DecodeString(c.Sk)
+				alpha, _ := hex.DecodeString(c.Alpha)
+				pi, _ := hex.DecodeString(c.Pi)
+				wantBeta, _ := hex.DecodeString(c.Beta)
+
+				pkX, pkY := tt.curve.ScalarBaseMult(skBytes)
+				verifier := tt.vrf.NewVerifier(&ecdsa.PublicKey{Curve: tt.curve, X: pkX, Y: pkY})
+
+				// verify twice to reuse the precomputation
+				for i := 0; i < 2; i++ {
+					beta, err := verifier.Verify(alpha, pi)
+					if err != nil {
+						t.Fatalf("verifier.Verify() error = %v", err)
+					}
+					if !reflect.DeepEqual(beta, wantBeta) {
+						t.Fatalf("verifier.Verify() = %x, want %x", beta, wantBeta)
+					}
+				}
+				bad := append([]byte{}, pi...)
+				bad[len(bad)-1] ^= 1
+				if _, err := verifier.Verify(alpha, bad); err == nil {
+					t.Fatal("verifier.Verify() accepted the tampered proof")
+				}
+			}
+		})
+	}
+}
+
+func Test_vrf_ScalarBlinding(t *testing.T) {
+	tests := []struct {
+		name   string
+		newVRF func(...ecvrf.Option) ecvrf.VRF
+		curve  elliptic.Curve
+		file   string
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai, secp256k1.S256(), "../vectors/secp256_k1_sha256_tai.json"},
+		{"p256", ecvrf.NewP256Sha256Tai, elliptic.P256(), "../vectors/p256_sha256_tai.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, err := readCases(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vrf := tt.newVRF(ecvrf.WithScalarBlinding(rand.Reader))
+			for _, c := range cases {
+				skBytes, _ := hex.DecodeString(c.Sk)
+				alpha, _ := hex.DecodeString(c.Alpha)
+				wantPi, _ := hex.DecodeString(c.Pi)
+				wantBeta, _ := hex.DecodeString(c.Beta)
+
+				sk := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(skBytes)}
+				sk.Curve = tt.curve
+				sk.X, sk.Y = tt.curve.ScalarBaseMult(skBytes)
+
+				// blinding must not change the proofs
+				beta, pi, err := vrf.Prove(sk, alpha)
+				if err != nil {
+					t.Fatalf("vrf.Prove() error = %v", err)
+				}
+				if !reflect.DeepEqual(pi, wantPi) || !reflect.DeepEqual(beta, wantBeta) {
+					t.Fatalf("vrf.Prove() = %x, %x, want %x, %x", beta, pi, wantBeta, wantPi)
+				}
+			}
+		})
+	}
+}
+
+func Test_vrf_ValidatePublicKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+		file  string
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256(), "../vectors/secp256_k1_sha256_tai.json"},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256(), "../vectors/p256_sha256_tai.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, err := readCases(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range cases {
+				skBytes, _ := hex.DecodeString(c.Sk)
+				pkX, pkY := tt.curve.ScalarBaseMult(skBytes)
+				if err := tt.vrf.ValidatePublicKey(&ecdsa.PublicKey{Curve: tt.curve, X: pkX, Y: pkY}); err != nil {
+					t.Fatalf("vrf.ValidatePublicKey() error = %v", err)
+				}
+				offCurve := &ecdsa.PublicKey{Curve: tt.curve, X: pkX, Y: new(big.Int).Add(pkY, big.NewInt(1))}
+				if err := tt.vrf.ValidatePublicKey(offCurve); err == nil {
+					t.Fatal("vrf.ValidatePublicKey() accepted a point off the curve")
+				}
+			}
+			identity := &ecdsa.PublicKey{Curve: tt.curve, X: new(big.Int), Y: new(big.Int)}
+			if err := tt.vrf.ValidatePublicKey(identity); err == nil {
+				t.Fatal("vrf.ValidatePublicKey() accepted the point at infinity")
+			}
+		})
+	}
+}
+
+func Test_vrf_Verify_canonical(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256()},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			alpha := []byte("Hello VeChain")
+			_, pi, err := tt.vrf.Prove(sk, alpha)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifier := tt.vrf.NewVerifier(&sk.PublicKey)
+
+			// no other encoding within one bit of pi is accepted
+			for i := 0; i < 8*len(pi); i++ {
+				bad := append([]byte{}, pi...)
+				bad[i/8] ^= 1 << uint(i%8)
+				if _, err := tt.vrf.Verify(&sk.PublicKey, alpha, bad); err == nil {
+					t.Fatalf("vrf.Verify() accepted pi with bit %d flipped", i)
+				}
+				if _, err := verifier.Verify(alpha, bad); err == nil {
+					t.Fatalf("verifier.Verify() accepted pi with bit %d flipped", i)
+				}
+			}
+			// nor are padded or truncated proofs
+			for _, bad := range [][]byte{append([]byte{0}, pi...), append(append([]byte{}, pi...), 0), pi[:len(pi)-1]} {
+				if _, err := tt.vrf.Verify(&sk.PublicKey, alpha, bad); err == nil {
+					t.Fatalf("vrf.Verify() accepted pi of %d octets", len(bad))
+				}
+			}
+		})
+	}
+}
+
+func Test_vrf_HedgedNonce(t *testing.T) {
+	tests := []struct {
+		name   string
+		newVRF func(...ecvrf.Option) ecvrf.VRF
+		curve  elliptic.Curve
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai, secp256k1.S256()},
+		{"p256", ecvrf.NewP256Sha256Tai, elliptic.P256()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			alpha := []byte("Hello VeChain")
+			wantBeta, wantPi, err := tt.newVRF().Prove(sk, alpha)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hedged := tt.newVRF(ecvrf.WithHedgedNonce(rand.Reader))
+			seen := map[string]bool{string(wantPi): true}
+			for i := 0; i < 3; i++ {
+				beta, pi, err := hedged.Prove(sk, alpha)
+				if err != nil {
+					t.Fatalf("vrf.Prove() error = %v", err)
+				}
+				if !reflect.DeepEqual(beta, wantBeta) {
+					t.Fatalf("vrf.Prove() beta = %x, want %x", beta, wantBeta)
+				}
+				if seen[string(pi)] {
+					t.Fatal("vrf.Prove() repeated a proof with hedged nonces")
+				}
+				seen[string(pi)] = true
+				if _, err := hedged.Verify(&sk.PublicKey, alpha, pi); err != nil {
+					t.Fatalf("vrf.Verify() error = %v", err)
+				}
+			}
+
+			// the same randomness gives the same proof
+			extra := bytes.Repeat([]byte{0x42}, 32)
+			_, pi1, _ := tt.newVRF(ecvrf.WithHedgedNonce(bytes.NewReader(extra))).Prove(sk, alpha)
+			_, pi2, _ := tt.newVRF(ecvrf.WithHedgedNonce(bytes.NewReader(extra))).Prove(sk, alpha)
+			if !reflect.DeepEqual(pi1, pi2) || reflect.DeepEqual(pi1, wantPi) {
+				t.Fatal("vrf.Prove() with fixed randomness is not reproducible, or not hedged")
+			}
+
+			if _, _, err := tt.newVRF(ecvrf.WithHedgedNonce(bytes.NewReader(nil))).Prove(sk, alpha); err == nil {
+				t.Fatal("vrf.Prove() succeeded with a failing random source")
+			}
+		})
+	}
+}
+
+func Test_vrf_RFC9381(t *testing.T) {
+	cases, err := readCases("../vectors/p256_sha256_tai_rfc9381.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vrf := ecvrf.NewP256Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381))
+	draft := ecvrf.NewP256Sha256Tai()
+	for _, c := range cases {
+		t.Run(c.Alpha, func(t *testing.T) {
+			skBytes, _ := hex.DecodeString(c.Sk)
+			alpha, _ := hex.DecodeString(c.Alpha)
+			wantPi, _ := hex.DecodeString(c.Pi)
+			wantBeta, _ := hex.DecodeString(c.Beta)
+
+			sk := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(skBytes)}
+			sk.Curve = elliptic.P256()
+			sk.X, sk.Y = sk.Curve.ScalarBaseMult(skBytes)
+			if got := hex.EncodeToString(elliptic.MarshalCompressed(sk.Curve, sk.X, sk.Y)); got != c.Pk {
+				t.Fatalf("pk = %v, want %v", got, c.Pk)
+			}
+
+			beta, pi, err := vrf.Prove(sk, alpha)
+			if err != nil {
+				t.Fatalf("vrf.Prove() error = %v", err)
+			}
+			if !reflect.DeepEqual(pi, wantPi) || !reflect.DeepEqual(beta, wantBeta) {
+				t.Fatalf("vrf.Prove() = %x, %x, want %x, %x", beta, pi, wantBeta, wantPi)
+			}
+			if beta, err := vrf.Verify(&sk.PublicKey, alpha, pi); err != nil || !reflect.DeepEqual(beta, wantBeta) {
+				t.Fatalf("vrf.Verify() = %x, %v, want %x", beta, err, wantBeta)
+			}
+			if beta, err := vrf.NewVerifier(&sk.PublicKey).Verify(alpha, pi); err != nil || !reflect.DeepEqual(beta, wantBeta) {
+				t.Fatalf("verifier.Verify() = %x, %v, want %x", beta, err, wantBeta)
+			}
+
+			// proofs of one revision are rejected by the other
+			if _, err := draft.Verify(&sk.PublicKey, alpha, pi); err == nil {
+				t.Fatal("draft vrf.Verify() accepted an RFC9381 proof")
+			}
+			_, draftPi, _ := draft.Prove(sk, alpha)
+			if _, err := vrf.Verify(&sk.PublicKey, alpha, draftPi); err == nil {
+				t.Fatal("vrf.Verify() accepted a draft proof")
+			}
+		})
+	}
+
+	t.Run("secp256k1", func(t *testing.T) {
+		vrf := ecvrf.NewSecp256k1Sha256Tai(ecvrf.WithSpecVersion(ecvrf.RFC9381))
+		sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+		alpha := []byte("Hello VeChain")
+		beta, pi, err := vrf.Prove(sk, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := vrf.AppendVerify(nil, &sk.PublicKey, alpha, pi); err != nil || !reflect.DeepEqual(got, beta) {
+			t.Fatalf("vrf.AppendVerify() = %x, %v, want %x", got, err, beta)
+		}
+		if got, err := vrf.NewVerifier(&sk.PublicKey).Verify(alpha, pi); err != nil || !reflect.DeepEqual(got, beta) {
+			t.Fatalf("verifier.Verify() = %x, %v, want %x", got, err, beta)
+		}
+		if _, err := ecvrf.NewSecp256k1Sha256Tai().Verify(&sk.PublicKey, alpha, pi); err == nil {
+			t.Fatal("draft vrf.Verify() accepted an RFC9381 proof")
+		}
+	})
+}
+
+func Test_Ed25519Sha512Elligator2(t *testing.T) {
+	cases, err := readCases("../vectors/ed25519_sha512_elligator2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vrf := ecvrf.NewEd25519Sha512Elligator2()
+	for _, c := range cases {
+		t.Run(c.Pk, func(t *testing.T) {
+			seed, _ := hex.DecodeString(c.Sk)
+			alpha, _ := hex.DecodeString(c.Alpha)
+			wantPi, _ := hex.DecodeString(c.Pi)
+			wantBeta, _ := hex.DecodeString(c.Beta)
+			sk := ed25519.NewKeyFromSeed(seed)
+			pk := sk.Public().(ed25519.PublicKey)
+			if got := hex.EncodeToString(pk); got != c.Pk {
+				t.Fatalf("pk = %v, want %v", got, c.Pk)
+			}
+
+			beta, pi, err := vrf.Prove(sk, alpha)
+			if err != nil {
+				t.Fatalf("vrf.Prove() error = %v", err)
+			}
+			if !reflect.DeepEqual(pi, wantPi) || !reflect.DeepEqual(beta, wantBeta) {
+				t.Fatalf("vrf.Prove() = %x, %x, want %x, %x", beta, pi, wantBeta, wantPi)
+			}
+			if beta, err := vrf.Verify(pk, alpha, pi); err != nil || !reflect.DeepEqual(beta, wantBeta) {
+				t.Fatalf("vrf.Verify() = %x, %v, want %x", beta, err, wantBeta)
+			}
+			if beta, err := vrf.ProofToHash(pi); err != nil || !reflect.DeepEqual(beta, wantBeta) {
+				t.Fatalf("vrf.ProofToHash() = %x, %v, want %x", beta, err, wantBeta)
+			}
+
+			for i := 0; i < 8*len(pi); i++ {
+				bad := append([]byte{}, pi...)
+				bad[i/8] ^= 1 << uint(i%8)
+				if _, err := vrf.Verify(pk, alpha, bad); err == nil {
+					t.Fatalf("vrf.Verify() accepted pi with bit %d flipped", i)
+				}
+			}
+			if _, err := vrf.Verify(pk, append(alpha, 0), pi); err == nil {
+				t.Fatal("vrf.Verify() accepted another alpha")
+			}
+
+			// like libsodium, s isn't required to be reduced: s + ℓ is accepted
+			l, _ := new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+			s := make([]byte, 32)
+			for i := range s {
+				s[i] = pi[79-i]
+			}
+			sl := new(big.Int).Add(new(big.Int).SetBytes(s), l).FillBytes(make([]byte, 32))
+			unreduced := append([]byte{}, pi[:48]...)
+			for i := range sl {
+				unreduced = append(unreduced, sl[31-i])
+			}
+			if beta, err := vrf.Verify(pk, alpha, unreduced); err != nil || !reflect.DeepEqual(beta, wantBeta) {
+				t.Fatalf("vrf.Verify() = %x, %v with a non-reduced s", beta, err)
+			}
+		})
+	}
+
+	t.Run("invalid keys", func(t *testing.T) {
+		sk := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+		_, pi, err := vrf.Prove(sk, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		identity := make([]byte, 32)
+		identity[0] = 1
+		nonCanonical := bytes.Repeat([]byte{0xff}, 32)
+		nonCanonical[31] = 0x7f
+		for _, pk := range [][]byte{nil, make([]byte, 31), identity, nonCanonical} {
+			if _, err := vrf.Verify(pk, nil, pi); err == nil {
+				t.Fatalf("vrf.Verify() accepted pk %x", pk)
+			}
+		}
+		if _, _, err := vrf.Prove(sk[:31], nil); err == nil {
+			t.Fatal("vrf.Prove() accepted a short private key")
+		}
+	})
+
+	t.Run("seed", func(t *testing.T) {
+		seed := bytes.Repeat([]byte{3}, ed25519.SeedSize)
+		beta, pi, err := vrf.Prove(ed25519.NewKeyFromSeed(seed), []byte("alpha"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		beta2, pi2, err := vrf.Prove(seed, []byte("alpha"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(beta, beta2) || !bytes.Equal(pi, pi2) {
+			t.Errorf("proof of the seed differs from the one of its key")
+		}
+		if !bytes.Equal(seed, bytes.Repeat([]byte{3}, ed25519.SeedSize)) {
+			t.Errorf("vrf.Prove() modified the seed")
+		}
+	})
+}
+
+func Test_Ed25519Sha512Ell2(t *testing.T) {
+	tests := []struct {
+		name string
+		vrf  ecvrf.Ed25519VRF
+		file string
+	}{
+		{"standard", ecvrf.NewEd25519Sha512Ell2(), "../vectors/ed25519_sha512_ell2.json"},
+		{"batchcompat", ecvrf.NewEd25519Sha512Ell2BatchCompat(), "../vectors/ed25519_sha512_ell2_batchcompat.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, err := readCases(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range cases {
+				seed, _ := hex.DecodeString(c.Sk)
+				alpha, _ := hex.DecodeString(c.Alpha)
+				wantPi, _ := hex.DecodeString(c.Pi)
+				wantBeta, _ := hex.DecodeString(c.Beta)
+				sk := ed25519.NewKeyFromSeed(seed)
+				pk := sk.Public().(ed25519.PublicKey)
+
+				beta, pi, err := tt.vrf.Prove(sk, alpha)
+				if err != nil {
+					t.Fatalf("vrf.Prove() error = %v", err)
+				}
+				if !reflect.DeepEqual(pi, wantPi) || !reflect.DeepEqual(beta, wantBeta) {
+					t.Fatalf("vrf.Prove() = %x, %x, want %x, %x", beta, pi, wantBeta, wantPi)
+				}
+				if beta, err := tt.vrf.Verify(pk, alpha, pi); err != nil || !reflect.DeepEqual(beta, wantBeta) {
+					t.Fatalf("vrf.Verify() = %x, %v, want %x", beta, err, wantBeta)
+				}
+				if beta, err := tt.vrf.ProofToHash(pi); err != nil || !reflect.DeepEqual(beta, wantBeta) {
+					t.Fatalf("vrf.ProofToHash() = %x, %v, want %x", beta, err, wantBeta)
+				}
+
+				for i := 0; i < 8*len(pi); i++ {
+					bad := append([]byte{}, pi...)
+					bad[i/8] ^= 1 << uint(i%8)
+					if _, err := tt.vrf.Verify(pk, alpha, bad); err == nil {
+						t.Fatalf("vrf.Verify() accepted pi with bit %d flipped", i)
+					}
+				}
+
+				// s must be reduced
+				l, _ := new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+				s := make([]byte, 32)
+				for i := range s {
+					s[i] = pi[len(pi)-1-i]
+				}
+				sl := new(big.Int).Add(new(big.Int).SetBytes(s), l).FillBytes(make([]byte, 32))
+				unreduced := append([]byte{}, pi[:len(pi)-32]...)
+				for i := range sl {
+					unreduced = append(unreduced, sl[31-i])
+				}
+				if _, err := tt.vrf.Verify(pk, alpha, unreduced); err == nil {
+					t.Fatal("vrf.Verify() accepted a non-reduced s")
+				}
+				if _, err := ecvrf.NewEd25519Sha512Elligator2().Verify(pk, alpha, pi); err == nil {
+					t.Fatal("draft-03 vrf.Verify() accepted the proof")
+				}
+			}
+		})
+	}
+
+	// the batch-compatible proof carries the same Gamma and s, and gives the same beta
+	sk := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	beta1, pi1, _ := ecvrf.NewEd25519Sha512Ell2().Prove(sk, []byte("Hello VeChain"))
+	beta2, pi2, _ := ecvrf.NewEd25519Sha512Ell2BatchCompat().Prove(sk, []byte("Hello VeChain"))
+	if !reflect.DeepEqual(beta1, beta2) || !bytes.Equal(pi1[:32], pi2[:32]) || !bytes.Equal(pi1[48:], pi2[96:]) {
+		t.Fatalf("batch-compatible proof %x doesn't match %x", pi2, pi1)
+	}
+}
+
+func Test_Sr25519(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, 32)
+	sk, err := ecvrf.NewSr25519KeyFromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := sk.Public()
+	vrf := ecvrf.NewSr25519(rand.Reader)
+
+	tr := ecvrf.NewSr25519SigningTranscript([]byte("substrate"), []byte("Hello VeChain"))
+	inout, proof, err := vrf.Prove(sk, tr)
+	if err != nil {
+		t.Fatalf("vrf.Prove() error = %v", err)
+	}
+	got, err := vrf.Verify(pk, tr, inout.Output[:], proof)
+	if err != nil || *got != *inout {
+		t.Fatalf("vrf.Verify() = %v, %v, want %v", got, err, inout)
+	}
+
+	// proofs are randomized, outputs are not
+	inout2, proof2, _ := vrf.Prove(sk, tr)
+	if *inout2 != *inout || bytes.Equal(proof, proof2) {
+		t.Fatal("vrf.Prove() isn't a randomized proof of the same output")
+	}
+	if !bytes.Equal(inout.MakeBytes([]byte("substrate-babe-vrf"), 16), inout2.MakeBytes([]byte("substrate-babe-vrf"), 16)) {
+		t.Fatal("MakeBytes() isn't deterministic")
+	}
+	if bytes.Equal(inout.MakeBytes([]byte("a"), 16), inout.MakeBytes([]byte("b"), 16)) {
+		t.Fatal("MakeBytes() isn't separated by the context")
+	}
+
+	for i := 0; i < 8*len(proof); i++ {
+		bad := append([]byte{}, proof...)
+		bad[i/8] ^= 1 << uint(i%8)
+		if _, err := vrf.Verify(pk, tr, inout.Output[:], bad); err == nil {
+			t.Fatalf("vrf.Verify() accepted proof with bit %d flipped", i)
+		}
+	}
+	other := ecvrf.NewSr25519SigningTranscript([]byte("substrate"), []byte("Hello"))
+	if _, err := vrf.Verify(pk, other, inout.Output[:], proof); err == nil {
+		t.Fatal("vrf.Verify() accepted another transcript")
+	}
+	sk2, _ := ecvrf.NewSr25519KeyFromSeed(bytes.Repeat([]byte{8}, 32))
+	if _, err := vrf.Verify(sk2.Public(), tr, inout.Output[:], proof); err == nil {
+		t.Fatal("vrf.Verify() accepted another public key")
+	}
+	if _, _, err := ecvrf.NewSr25519(nil).Prove(sk, tr); err == nil {
+		t.Fatal("vrf.Prove() without a random source succeeded")
+	}
+}
+
+func Test_KeyTransparencyP256(t *testing.T) {
+	vrf := ecvrf.NewKeyTransparencyP256(rand.Reader)
+	for i := 0; i < 10; i++ {
+		sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		alpha := []byte{byte(i)}
+		beta, pi, err := vrf.Prove(sk, alpha)
+		if err != nil {
+			t.Fatalf("vrf.Prove() error = %v", err)
+		}
+		if len(pi) != 129 || len(beta) != 32 {
+			t.Fatalf("vrf.Prove() = %x, %x", beta, pi)
+		}
+		if got, err := vrf.Verify(&sk.PublicKey, alpha, pi); err != nil || !bytes.Equal(got, beta) {
+			t.Fatalf("vrf.Verify() = %x, %v, want %x", got, err, beta)
+		}
+		// the output doesn't depend on the random nonce
+		beta2, pi2, _ := vrf.Prove(sk, alpha)
+		if !bytes.Equal(beta, beta2) || bytes.Equal(pi, pi2) {
+			t.Fatal("vrf.Prove() isn't a randomized proof of the same output")
+		}
+
+		for j := 0; j < 8*len(pi); j += 7 {
+			bad := append([]byte{}, pi...)
+			bad[j/8] ^= 1 << uint(j%8)
+			if _, err := vrf.Verify(&sk.PublicKey, alpha, bad); err == nil {
+				t.Fatalf("vrf.Verify() accepted pi with bit %d flipped", j)
+			}
+		}
+		if _, err := vrf.Verify(&sk.PublicKey, []byte("other"), pi); err == nil {
+			t.Fatal("vrf.Verify() accepted another input")
+		}
+	}
+	if _, _, err := ecvrf.NewKeyTransparencyP256(nil).Prove(nil, nil); err == nil {
+		t.Fatal("vrf.Prove() without a random source succeeded")
+	}
+}
+
+func Test_ProofToHash(t *testing.T) {
+	tests := []struct {
+		name  string
+		vrf   ecvrf.VRF
+		curve elliptic.Curve
+		file  string
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai(), secp256k1.S256(), "../vectors/secp256_k1_sha256_tai.json"},
+		{"p256", ecvrf.NewP256Sha256Tai(), elliptic.P256(), "../vectors/p256_sha256_tai.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, _ := readCases(tt.file)
+			for _, c := range cases {
+				pi, _ := hex.DecodeString(c.Pi)
+				want, _ := hex.DecodeString(c.Beta)
+				if beta, err := ecvrf.ProofToHash(tt.vrf, tt.curve, pi); err != nil || !bytes.Equal(beta, want) {
+					t.Fatalf("ProofToHash() = %x, %v, want %x", beta, err, want)
+				}
+				if _, err := ecvrf.ProofToHash(tt.vrf, tt.curve, pi[1:]); err == nil {
+					t.Fatal("ProofToHash() accepted a truncated proof")
+				}
+			}
+		})
+	}
+}
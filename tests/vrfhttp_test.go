@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/vrfhttp"
+)
+
+func Test_VrfHTTP(t *testing.T) {
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	h := vrfhttp.NewHandler(ecvrf.NewP256Sha256Tai(), map[string]*ecdsa.PrivateKey{"k1": sk}, vrfhttp.WithMaxBodyBytes(1024), vrfhttp.WithMaxAlphaBytes(8))
+
+	call := func(method, path, contentType, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		var resp map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal("response isn't JSON:", w.Body.String())
+		}
+		return w.Code, resp
+	}
+	alpha := hex.EncodeToString([]byte("sample"))
+
+	code, proved := call("POST", "/prove", "application/json", `{"key_id":"k1","alpha":"`+alpha+`"}`)
+	if code != http.StatusOK {
+		t.Fatal("prove failed", code, proved)
+	}
+	pi, _ := hex.DecodeString(proved["pi"].(string))
+	beta, err := ecvrf.NewP256Sha256Tai().Verify(&sk.PublicKey, []byte("sample"), pi)
+	if err != nil || hex.EncodeToString(beta) != proved["beta"] {
+		t.Fatal("proof of the handler doesn't verify", err)
+	}
+
+	code, verified := call("POST", "/verify", "application/json; charset=utf-8", `{"key_id":"k1","alpha":"`+alpha+`","pi":"`+proved["pi"].(string)+`"}`)
+	if code != http.StatusOK || verified["valid"] != true || verified["beta"] != proved["beta"] {
+		t.Fatal("valid proof rejected", code, verified)
+	}
+	code, verified = call("POST", "/verify", "application/json", `{"key_id":"k1","alpha":"00","pi":"`+proved["pi"].(string)+`"}`)
+	if code != http.StatusOK || verified["valid"] != false || verified["error"] == nil {
+		t.Fatal("invalid proof accepted", code, verified)
+	}
+
+	for _, tc := range []struct {
+		method, path, contentType, body string
+		code                            int
+	}{
+		{"GET", "/prove", "application/json", "", http.StatusMethodNotAllowed},
+		{"POST", "/prove", "text/plain", `{"key_id":"k1","alpha":""}`, http.StatusUnsupportedMediaType},
+		{"POST", "/prove", "application/json", `{"key_id":"k2","alpha":""}`, http.StatusNotFound},
+		{"POST", "/prove", "application/json", `{"key_id":"k1","alpha":"zz"}`, http.StatusBadRequest},
+		{"POST", "/prove", "application/json", `{"key_id":"k1","alpha":"","extra":1}`, http.StatusBadRequest},
+		{"POST", "/prove", "application/json", `{"key_id":"k1","alpha":""} {}`, http.StatusBadRequest},
+		{"POST", "/prove", "application/json", `{"key_id":"k1","alpha":"` + strings.Repeat("00", 9) + `"}`, http.StatusBadRequest},
+		{"POST", "/prove", "application/json", `{"key_id":"k1","alpha":"` + strings.Repeat("00", 1024) + `"}`, http.StatusRequestEntityTooLarge},
+	} {
+		code, resp := call(tc.method, tc.path, tc.contentType, tc.body)
+		if code != tc.code || resp["error"] == nil {
+			t.Error(tc.method, tc.path, "want", tc.code, "got", code, resp)
+		}
+	}
+}
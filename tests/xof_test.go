@@ -0,0 +1,67 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build go1.24
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func Test_vrf_Shake256Beta(t *testing.T) {
+	tests := []struct {
+		name   string
+		newVRF func(opts ...ecvrf.Option) ecvrf.VRF
+		curve  elliptic.Curve
+	}{
+		{"secp256k1", ecvrf.NewSecp256k1Sha256Tai, secp256k1.S256()},
+		{"p256", ecvrf.NewP256Sha256Tai, elliptic.P256()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			alpha := []byte("Hello VeChain")
+
+			beta64, pi, err := tt.newVRF(ecvrf.WithShake256Beta(64)).Prove(sk, alpha)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(beta64) != 64 {
+				t.Fatalf("len(beta) = %v, want 64", len(beta64))
+			}
+			if p := tt.newVRF(ecvrf.WithShake256Beta(64)).Params(); p.BetaSize != 64 {
+				t.Errorf("Params().BetaSize = %v, want 64", p.BetaSize)
+			}
+			// beta collisions bound the security level of short outputs
+			if p := tt.newVRF(ecvrf.WithShake256Beta(16)).Params(); p.SecurityBits != 64 {
+				t.Errorf("Params().SecurityBits = %v, want 64", p.SecurityBits)
+			}
+
+			beta128, err := tt.newVRF(ecvrf.WithShake256Beta(128)).Verify(&sk.PublicKey, alpha, pi)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(beta128) != 128 {
+				t.Fatalf("len(beta) = %v, want 128", len(beta128))
+			}
+			// the shorter output is a prefix of the longer one
+			if !bytes.Equal(beta64, beta128[:64]) {
+				t.Errorf("beta of 64 octets is not the prefix of beta of 128 octets")
+			}
+
+			// proof is not affected by the beta mode
+			_, pi2, _ := tt.newVRF().Prove(sk, alpha)
+			if !bytes.Equal(pi, pi2) {
+				t.Errorf("pi differs between beta modes")
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package threshold implements t-of-n proofs of the Weierstrass suites of ecvrf, for randomness
+// beacons where no single node knows the key: the key is shared among the parties 1..n by a
+// distributed key generation, and any t of them compute a standard proof, verified by
+// ecvrf.VRF.Verify with the group public key, whose beta is the one of the group key.
+//
+// The DKG is the Joint-Feldman protocol of Pedersen: each party deals the shares of a random
+// secret by Feldman's VSS, and the key is the sum of the secrets of the dealers which aren't
+// disqualified by complaints. Transport, broadcast and complaints are left to the caller:
+//
+//	deal, _ := threshold.NewDeal(curve, t, n, id, rand.Reader)
+//	// broadcast deal.Commitments, send deal.Shares[j-1] privately to each party j,
+//	// and complain about the dealers whose shares fail VerifyShare
+//	share, _ := threshold.NewKeyShare(curve, t, n, id, shares, commitments)
+//
+// As shown by Gennaro et al., a rushing adversary can bias the distribution of the group key,
+// not learn it. Proofs take two rounds, as FROST signatures:
+//
+//	nonce, commitment, _ := share.Commit(vrf, alpha, rand.Reader)
+//	// exchange the commitments of the parties of the proof
+//	partial, _ := share.Respond(vrf, alpha, nonce, commitments)
+//	// any party, or a coordinator, collects the partials
+//	beta, pi, _ := share.Combine(vrf, alpha, commitments, partials)
+//
+// Each party commits to its share of Gamma and to two nonces, both multiplied by B and H; the
+// nonce of the proof is the sum of the first nonces and of the second ones weighted by binding
+// factors hashed from all the commitments, so commitments can't be chosen after the others.
+package threshold
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	errParams        = errors.New("threshold: invalid threshold or party")
+	errInvalidShare  = errors.New("threshold: share doesn't match the commitments")
+	errInvalidDeal   = errors.New("threshold: invalid commitments")
+	errNoDealers     = errors.New("threshold: no qualified dealers")
+	errInvalidResult = errors.New("threshold: DKG gave an invalid key")
+)
+
+// Deal is the Feldman VSS of the secret of a dealer: the commitments to the coefficients of its
+// polynomial f of degree t-1 are broadcast, and the share f(j) is sent privately to the party j.
+type Deal struct {
+	Dealer int
+	// Commitments are a_k*B of the coefficients a_k of f, a_0 being the secret.
+	Commitments [][2]*big.Int
+	// Shares holds f(j) at index j-1. They must be wiped once sent.
+	Shares []*big.Int
+}
+
+// NewDeal draws the polynomial of the dealer, of degree t-1, and deals it to the parties 1..n.
+func NewDeal(c elliptic.Curve, t, n, dealer int, rand io.Reader) (*Deal, error) {
+	if t < 1 || t > n || dealer < 1 || dealer > n {
+		return nil, errParams
+	}
+	coeffs := make([]*big.Int, t)
+	defer func() {
+		for _, a := range coeffs {
+			wipeInt(a)
+		}
+	}()
+	deal := &Deal{Dealer: dealer, Commitments: make([][2]*big.Int, t), Shares: make([]*big.Int, n)}
+	for k := range coeffs {
+		a, err := randScalar(c, rand)
+		if err != nil {
+			return nil, err
+		}
+		coeffs[k] = a
+		deal.Commitments[k] = baseMult(c, a)
+	}
+	q := c.Params().N
+	for j := 1; j <= n; j++ {
+		// Horner's rule
+		f := new(big.Int)
+		x := big.NewInt(int64(j))
+		for k := t - 1; k >= 0; k-- {
+			f.Mul(f, x)
+			f.Add(f, coeffs[k])
+			f.Mod(f, q)
+		}
+		deal.Shares[j-1] = f
+	}
+	return deal, nil
+}
+
+// VerifyShare checks the share of the party j against the commitments of its dealer:
+// share*B = sum(j^k * C_k). A party complains about the dealers whose shares fail.
+func VerifyShare(c elliptic.Curve, j int, share *big.Int, commitments [][2]*big.Int) error {
+	if err := checkCommitments(c, commitments); err != nil {
+		return err
+	}
+	if share == nil || share.Sign() < 0 || share.Cmp(c.Params().N) >= 0 {
+		return errInvalidShare
+	}
+	if !equal(baseMult(c, share), evalCommitments(c, commitments, j)) {
+		return errInvalidShare
+	}
+	return nil
+}
+
+// PublicKeySet is the public outcome of a DKG, the same for all parties and observers.
+type PublicKeySet struct {
+	Curve elliptic.Curve
+	T     int
+	// PublicKey is the group key, which verifies the proofs.
+	PublicKey *ecdsa.PublicKey
+	// Shares holds the public key x_j*B of the share of the party j at index j-1.
+	Shares [][2]*big.Int
+}
+
+// NewPublicKeySet computes the public keys of the DKG of n parties from the commitments of the
+// qualified dealers, by dealer.
+func NewPublicKeySet(c elliptic.Curve, t, n int, commitments map[int][][2]*big.Int) (*PublicKeySet, error) {
+	if t < 1 || t > n {
+		return nil, errParams
+	}
+	if len(commitments) == 0 {
+		return nil, errNoDealers
+	}
+	// the commitments of the summed polynomial are the sums of the commitments
+	sum := make([][2]*big.Int, t)
+	for dealer, cs := range commitments {
+		if dealer < 1 || dealer > n || len(cs) != t {
+			return nil, errInvalidDeal
+		}
+		if err := checkCommitments(c, cs); err != nil {
+			return nil, err
+		}
+		for k, ck := range cs {
+			if sum[k][0] == nil {
+				sum[k] = ck
+			} else {
+				sum[k] = add(c, sum[k], ck)
+			}
+		}
+	}
+	if !c.IsOnCurve(sum[0][0], sum[0][1]) {
+		return nil, errInvalidResult
+	}
+	pks := &PublicKeySet{
+		Curve:     c,
+		T:         t,
+		PublicKey: &ecdsa.PublicKey{Curve: c, X: sum[0][0], Y: sum[0][1]},
+		Shares:    make([][2]*big.Int, n),
+	}
+	for j := 1; j <= n; j++ {
+		pks.Shares[j-1] = evalCommitments(c, sum, j)
+		if !c.IsOnCurve(pks.Shares[j-1][0], pks.Shares[j-1][1]) {
+			return nil, errInvalidResult
+		}
+	}
+	return pks, nil
+}
+
+// KeyShare is the share of the key of a party.
+type KeyShare struct {
+	PublicKeySet
+	ID int
+	// Secret is x_ID, the sum of the shares of the party.
+	Secret *big.Int
+}
+
+// NewKeyShare verifies the shares received by the party id from the qualified dealers, and
+// combines them with their commitments, both by dealer, into its key share.
+func NewKeyShare(c elliptic.Curve, t, n, id int, shares map[int]*big.Int, commitments map[int][][2]*big.Int) (*KeyShare, error) {
+	if id < 1 || id > n {
+		return nil, errParams
+	}
+	if len(shares) != len(commitments) {
+		return nil, errInvalidShare
+	}
+	pks, err := NewPublicKeySet(c, t, n, commitments)
+	if err != nil {
+		return nil, err
+	}
+	q := c.Params().N
+	x := new(big.Int)
+	for dealer, cs := range commitments {
+		if err := VerifyShare(c, id, shares[dealer], cs); err != nil {
+			return nil, err
+		}
+		x.Add(x, shares[dealer])
+	}
+	x.Mod(x, q)
+	if x.Sign() == 0 || !equal(baseMult(c, x), pks.Shares[id-1]) {
+		return nil, errInvalidResult
+	}
+	return &KeyShare{PublicKeySet: *pks, ID: id, Secret: x}, nil
+}
+
+func checkCommitments(c elliptic.Curve, commitments [][2]*big.Int) error {
+	if len(commitments) == 0 {
+		return errInvalidDeal
+	}
+	for _, ck := range commitments {
+		if !onCurve(c, ck) {
+			return errInvalidDeal
+		}
+	}
+	return nil
+}
+
+// evalCommitments returns sum(j^k * C_k) = f(j)*B.
+func evalCommitments(c elliptic.Curve, commitments [][2]*big.Int, j int) [2]*big.Int {
+	q := c.Params().N
+	x := big.NewInt(int64(j))
+	pow := big.NewInt(1)
+	var r [2]*big.Int
+	for k, ck := range commitments {
+		term := ck
+		if k > 0 {
+			term = scalarMult(c, ck, pow)
+		}
+		if r[0] == nil {
+			r = term
+		} else {
+			r = add(c, r, term)
+		}
+		pow.Mul(pow, x)
+		pow.Mod(pow, q)
+	}
+	return r
+}
+
+func randScalar(c elliptic.Curve, rand io.Reader) (*big.Int, error) {
+	q := c.Params().N
+	b := make([]byte, (q.BitLen()+7)/8+8)
+	defer wipe(b)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		// 64 extra bits make the bias of the reduction negligible
+		if k := new(big.Int).Mod(new(big.Int).SetBytes(b), q); k.Sign() > 0 {
+			return k, nil
+		}
+	}
+}
+
+func onCurve(c elliptic.Curve, p [2]*big.Int) bool {
+	return p[0] != nil && p[1] != nil && c.IsOnCurve(p[0], p[1])
+}
+
+func equal(p1, p2 [2]*big.Int) bool {
+	return p1[0].Cmp(p2[0]) == 0 && p1[1].Cmp(p2[1]) == 0
+}
+
+func add(c elliptic.Curve, p1, p2 [2]*big.Int) [2]*big.Int {
+	x, y := c.Add(p1[0], p1[1], p2[0], p2[1])
+	return [2]*big.Int{x, y}
+}
+
+func scalarMult(c elliptic.Curve, p [2]*big.Int, k *big.Int) [2]*big.Int {
+	x, y := c.ScalarMult(p[0], p[1], k.Bytes())
+	return [2]*big.Int{x, y}
+}
+
+func baseMult(c elliptic.Curve, k *big.Int) [2]*big.Int {
+	x, y := c.ScalarBaseMult(k.Bytes())
+	return [2]*big.Int{x, y}
+}
+
+// wipeInt zeroes the words of the secret k.
+func wipeInt(k *big.Int) {
+	if k == nil {
+		return
+	}
+	words := k.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	k.SetInt64(0)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package threshold
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+var (
+	errCommitments = errors.New("threshold: invalid set of commitments")
+	errNonceUsed   = errors.New("threshold: nonce already used")
+	errNonceOwner  = errors.New("threshold: nonce of another commitment")
+)
+
+// PartialError reports the party whose partial proof is invalid, so it can be excluded.
+type PartialError struct {
+	ID int
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("threshold: invalid partial proof of party %d", e.ID)
+}
+
+// Commitment is the first-round message of a party for the proof of an input.
+type Commitment struct {
+	ID int
+	// Gamma is x_ID*H.
+	Gamma [2]*big.Int
+	// D and E are d*B and e*B, DH and EH are d*H and e*H, of the nonces d and e.
+	D, E, DH, EH [2]*big.Int
+}
+
+// Nonce holds the nonces of a commitment, for a single Respond.
+type Nonce struct {
+	d, e       *big.Int
+	commitment *Commitment
+}
+
+// Partial is the second-round message of a party: its share of the response.
+type Partial struct {
+	ID int
+	S  *big.Int
+}
+
+// Commit draws the nonces of the party for the proof of alpha, and returns the commitment it sends
+// to the other parties of the proof.
+func (ks *KeyShare) Commit(v ecvrf.VRF, alpha []byte, rand io.Reader) (*Nonce, *Commitment, error) {
+	hx, hy, err := v.EncodeToCurve(ks.PublicKey, alpha)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := ks.Curve
+	H := [2]*big.Int{hx, hy}
+	d, err := randScalar(c, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	e, err := randScalar(c, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	cm := &Commitment{
+		ID:    ks.ID,
+		Gamma: scalarMult(c, H, ks.Secret),
+		D:     baseMult(c, d),
+		E:     baseMult(c, e),
+		DH:    scalarMult(c, H, d),
+		EH:    scalarMult(c, H, e),
+	}
+	return &Nonce{d: d, e: e, commitment: cm}, cm, nil
+}
+
+// Respond returns the partial proof of the party from the commitments of all the parties of the
+// proof, its own included. The nonce is wiped, as a second response would give away the share.
+func (ks *KeyShare) Respond(v ecvrf.VRF, alpha []byte, nonce *Nonce, commitments []*Commitment) (*Partial, error) {
+	if nonce.d == nil {
+		return nil, errNonceUsed
+	}
+	defer func() {
+		wipeInt(nonce.d)
+		wipeInt(nonce.e)
+		nonce.d, nonce.e = nil, nil
+	}()
+	s, err := ks.newSession(v, alpha, commitments)
+	if err != nil {
+		return nil, err
+	}
+	var own *Commitment
+	for _, cm := range s.commitments {
+		if cm.ID == ks.ID {
+			own = cm
+		}
+	}
+	if own == nil || !sameCommitment(own, nonce.commitment) {
+		return nil, errNonceOwner
+	}
+	q := ks.Curve.Params().N
+	// s_i = d + rho_i*e + c*lambda_i*x_i
+	r := new(big.Int).Mul(s.rho[ks.ID], nonce.e)
+	r.Add(r, nonce.d)
+	cx := new(big.Int).Mul(s.c, s.lambda[ks.ID])
+	cx.Mod(cx, q)
+	cx.Mul(cx, ks.Secret)
+	r.Add(r, cx)
+	r.Mod(r, q)
+	wipeInt(cx)
+	return &Partial{ID: ks.ID, S: r}, nil
+}
+
+// VerifyPartial checks the partial proof p against the commitment of its party, in the commitments
+// of the proof of alpha.
+func (pks *PublicKeySet) VerifyPartial(v ecvrf.VRF, alpha []byte, commitments []*Commitment, p *Partial) error {
+	s, err := pks.newSession(v, alpha, commitments)
+	if err != nil {
+		return err
+	}
+	return s.verify(p)
+}
+
+// Combine verifies the partial proofs of all the commitments, and sums them into the standard proof
+// of alpha by the group key, which is verified as well. An invalid partial is reported by a
+// *PartialError; the proof is then restarted without its party.
+func (pks *PublicKeySet) Combine(v ecvrf.VRF, alpha []byte, commitments []*Commitment, partials []*Partial) (beta, pi []byte, err error) {
+	s, err := pks.newSession(v, alpha, commitments)
+	if err != nil {
+		return
+	}
+	byID := make(map[int]*Partial, len(partials))
+	for _, p := range partials {
+		byID[p.ID] = p
+	}
+	q := pks.Curve.Params().N
+	sum := new(big.Int)
+	for _, cm := range s.commitments {
+		p := byID[cm.ID]
+		if p == nil {
+			return nil, nil, &PartialError{cm.ID}
+		}
+		if err = s.verify(p); err != nil {
+			return
+		}
+		sum.Add(sum, p.S)
+	}
+	sum.Mod(sum, q)
+	return ecvrf.AssembleProof(v, pks.PublicKey, alpha, s.gamma, s.c, sum)
+}
+
+// session is the state of a proof derived from the commitments, the same for all the parties.
+type session struct {
+	pks         *PublicKeySet
+	H           [2]*big.Int
+	commitments []*Commitment
+	lambda, rho map[int]*big.Int
+	// gamma, U and V are the ones of the proof
+	gamma, U, V [2]*big.Int
+	c           *big.Int
+}
+
+func (pks *PublicKeySet) newSession(v ecvrf.VRF, alpha []byte, commitments []*Commitment) (*session, error) {
+	c := pks.Curve
+	n := len(pks.Shares)
+	if len(commitments) < pks.T || len(commitments) > n {
+		return nil, errCommitments
+	}
+	sorted := append([]*Commitment(nil), commitments...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	for i, cm := range sorted {
+		if cm.ID < 1 || cm.ID > n || i > 0 && sorted[i-1].ID == cm.ID {
+			return nil, errCommitments
+		}
+		for _, pt := range [][2]*big.Int{cm.Gamma, cm.D, cm.E, cm.DH, cm.EH} {
+			if !onCurve(c, pt) {
+				return nil, &PartialError{cm.ID}
+			}
+		}
+	}
+	hx, hy, err := v.EncodeToCurve(pks.PublicKey, alpha)
+	if err != nil {
+		return nil, err
+	}
+	s := &session{
+		pks:         pks,
+		H:           [2]*big.Int{hx, hy},
+		commitments: sorted,
+		lambda:      lagrange(c.Params().N, sorted),
+		rho:         bindingFactors(pks, alpha, sorted),
+	}
+
+	for i, cm := range sorted {
+		gamma := scalarMult(c, cm.Gamma, s.lambda[cm.ID])
+		u := add(c, cm.D, scalarMult(c, cm.E, s.rho[cm.ID]))
+		w := add(c, cm.DH, scalarMult(c, cm.EH, s.rho[cm.ID]))
+		if i == 0 {
+			s.gamma, s.U, s.V = gamma, u, w
+		} else {
+			s.gamma, s.U, s.V = add(c, s.gamma, gamma), add(c, s.U, u), add(c, s.V, w)
+		}
+	}
+	if s.c, err = ecvrf.Challenge(v, pks.PublicKey, alpha, s.gamma, s.U, s.V); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// verify checks s_i*B = D + rho_i*E + c*lambda_i*X_i and s_i*H = DH + rho_i*EH + c*lambda_i*Gamma_i.
+func (s *session) verify(p *Partial) error {
+	var cm *Commitment
+	for _, c := range s.commitments {
+		if c.ID == p.ID {
+			cm = c
+		}
+	}
+	c := s.pks.Curve
+	q := c.Params().N
+	if cm == nil || p.S == nil || p.S.Sign() < 0 || p.S.Cmp(q) >= 0 {
+		return &PartialError{p.ID}
+	}
+	cl := new(big.Int).Mul(s.c, s.lambda[p.ID])
+	cl.Mod(cl, q)
+	rho := s.rho[p.ID]
+
+	wantB := add(c, add(c, cm.D, scalarMult(c, cm.E, rho)), scalarMult(c, s.pks.Shares[p.ID-1], cl))
+	wantH := add(c, add(c, cm.DH, scalarMult(c, cm.EH, rho)), scalarMult(c, cm.Gamma, cl))
+	if !equal(baseMult(c, p.S), wantB) || !equal(scalarMult(c, s.H, p.S), wantH) {
+		return &PartialError{p.ID}
+	}
+	return nil
+}
+
+// lagrange returns the Lagrange coefficients at 0 of the parties of the commitments.
+func lagrange(q *big.Int, commitments []*Commitment) map[int]*big.Int {
+	lambda := make(map[int]*big.Int, len(commitments))
+	for _, i := range commitments {
+		num, den := big.NewInt(1), big.NewInt(1)
+		for _, j := range commitments {
+			if j.ID == i.ID {
+				continue
+			}
+			num.Mul(num, big.NewInt(int64(j.ID)))
+			den.Mul(den, big.NewInt(int64(j.ID-i.ID)))
+		}
+		den.Mod(den, q)
+		num.Mul(num, den.ModInverse(den, q))
+		lambda[i.ID] = num.Mod(num, q)
+	}
+	return lambda
+}
+
+// bindingFactors returns rho_i = SHA-512(tag || Y || alpha || i || commitments) mod q of the parties.
+func bindingFactors(pks *PublicKeySet, alpha []byte, commitments []*Commitment) map[int]*big.Int {
+	c := pks.Curve
+	size := (c.Params().BitSize + 7) / 8
+	h := sha512.New()
+	h.Write([]byte("go-ecvrf threshold binding"))
+	writePoint(h, size, [2]*big.Int{pks.PublicKey.X, pks.PublicKey.Y})
+	writeUint(h, uint64(len(alpha)))
+	h.Write(alpha)
+	for _, cm := range commitments {
+		writeUint(h, uint64(cm.ID))
+		for _, pt := range [][2]*big.Int{cm.Gamma, cm.D, cm.E, cm.DH, cm.EH} {
+			writePoint(h, size, pt)
+		}
+	}
+	prefix := h.Sum(nil)
+
+	rho := make(map[int]*big.Int, len(commitments))
+	for _, cm := range commitments {
+		h.Reset()
+		h.Write(prefix)
+		writeUint(h, uint64(cm.ID))
+		r := new(big.Int).SetBytes(h.Sum(nil))
+		rho[cm.ID] = r.Mod(r, c.Params().N)
+	}
+	return rho
+}
+
+func writePoint(w io.Writer, size int, p [2]*big.Int) {
+	w.Write(p[0].FillBytes(make([]byte, size)))
+	w.Write(p[1].FillBytes(make([]byte, size)))
+}
+
+func writeUint(w io.Writer, n uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	w.Write(b[:])
+}
+
+func sameCommitment(a, b *Commitment) bool {
+	return a.ID == b.ID && equal(a.Gamma, b.Gamma) && equal(a.D, b.D) && equal(a.E, b.E) &&
+		equal(a.DH, b.DH) && equal(a.EH, b.EH)
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package ecvrf is the preview of the v2 API of go-ecvrf: one constructor for all the suites,
+// New(Suite, ...Option), keys of any suite behind crypto.PrivateKey and crypto.PublicKey, proofs
+// returned as a *Proof rather than positional results, and batches taking a context.Context.
+//
+// It's built on the v1 package, github.com/vechain/go-ecvrf, and gives the same proofs. FromV1,
+// FromV1Ed25519, V1 and V1Ed25519 convert between both APIs, so a program can move to v2 one
+// call site at a time: options of v1 without a v2 counterpart, such as WithMetrics, are passed
+// by WithV1Options in the meantime.
+package ecvrf
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"errors"
+
+	v1 "github.com/vechain/go-ecvrf"
+)
+
+var (
+	errUnknownSuite = errors.New("unknown suite")
+	errKeyType      = errors.New("key type of another suite")
+	errV1Options    = errors.New("v1 options only apply to the Weierstrass suites")
+	errSpecEd25519  = errors.New("spec version of an Ed25519 suite, which is named by its revision")
+)
+
+// Suite identifies a cipher suite.
+type Suite int
+
+// The suites of New. The Ed25519 suites take keys of crypto/ed25519, the others keys of
+// crypto/ecdsa, or the keys of the v1 package.
+const (
+	// Custom is the suite of the VRFs of FromV1 made by v1.New with another suite string.
+	Custom Suite = iota
+	Secp256k1Sha256Tai
+	P256Sha256Tai
+	// Edwards25519Sha512Ell2 is ECVRF-EDWARDS25519-SHA512-ELL2 of RFC9381.
+	Edwards25519Sha512Ell2
+	// Edwards25519Sha512Ell2BatchCompat is Edwards25519Sha512Ell2 with the batch-compatible
+	// proofs of v1.NewEd25519Sha512Ell2BatchCompat.
+	Edwards25519Sha512Ell2BatchCompat
+	// Ed25519Sha512Elligator2 is ECVRF-ED25519-SHA512-Elligator2 of draft-03, as libsodium.
+	Ed25519Sha512Elligator2
+)
+
+var suiteNames = [...]string{"Custom", "Secp256k1Sha256Tai", "P256Sha256Tai", "Edwards25519Sha512Ell2", "Edwards25519Sha512Ell2BatchCompat", "Ed25519Sha512Elligator2"}
+
+func (s Suite) String() string {
+	if s < 0 || int(s) >= len(suiteNames) {
+		return "Suite(?)"
+	}
+	return suiteNames[s]
+}
+
+func (s Suite) ed25519() bool {
+	return s >= Edwards25519Sha512Ell2
+}
+
+// SpecVersion, SuiteParams, BatchOption and their values are those of v1.
+type (
+	SpecVersion = v1.SpecVersion
+	SuiteParams = v1.SuiteParams
+	BatchOption = v1.BatchOption
+)
+
+// The revisions of the Weierstrass suites, for WithSpecVersion.
+const (
+	Draft06 = v1.Draft06
+	RFC9381 = v1.RFC9381
+)
+
+// WithParallelism and WithFailFast configure batches, as in v1.
+var (
+	WithParallelism = v1.WithParallelism
+	WithFailFast    = v1.WithFailFast
+)
+
+type config struct {
+	spec    *SpecVersion
+	v1Opts  []v1.Option
+	ed25519 bool
+}
+
+// Option configures a VRF of New.
+type Option func(*config)
+
+// WithSpecVersion sets the revision of the specification of a Weierstrass suite, Draft06 by
+// default as in v1. The Ed25519 suites are named by their revision instead.
+func WithSpecVersion(spec SpecVersion) Option {
+	return func(c *config) {
+		c.spec = &spec
+	}
+}
+
+// WithV1Options applies options of v1 to a Weierstrass suite, e.g. v1.WithMetrics, until they
+// have a v2 counterpart.
+func WithV1Options(opts ...v1.Option) Option {
+	return func(c *config) {
+		c.v1Opts = append(c.v1Opts, opts...)
+	}
+}
+
+// VRF proves and verifies with a suite. It's safe for concurrent use.
+type VRF struct {
+	suite Suite
+	ecdsa v1.VRF
+	ed    v1.Ed25519VRF
+}
+
+// Proof is a VRF proof and its output.
+type Proof struct {
+	// Pi is the encoded proof.
+	Pi []byte
+	// Beta is the output of the proof.
+	Beta []byte
+}
+
+// New returns the VRF of the suite.
+func New(suite Suite, opts ...Option) (*VRF, error) {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if suite.ed25519() {
+		if cfg.spec != nil {
+			return nil, errSpecEd25519
+		}
+		if len(cfg.v1Opts) > 0 {
+			return nil, errV1Options
+		}
+	}
+	v1Opts := cfg.v1Opts
+	if cfg.spec != nil {
+		v1Opts = append([]v1.Option{v1.WithSpecVersion(*cfg.spec)}, v1Opts...)
+	}
+	v := &VRF{suite: suite}
+	switch suite {
+	case Secp256k1Sha256Tai:
+		v.ecdsa = v1.NewSecp256k1Sha256Tai(v1Opts...)
+	case P256Sha256Tai:
+		v.ecdsa = v1.NewP256Sha256Tai(v1Opts...)
+	case Edwards25519Sha512Ell2:
+		v.ed = v1.NewEd25519Sha512Ell2()
+	case Edwards25519Sha512Ell2BatchCompat:
+		v.ed = v1.NewEd25519Sha512Ell2BatchCompat()
+	case Ed25519Sha512Elligator2:
+		v.ed = v1.NewEd25519Sha512Elligator2()
+	default:
+		return nil, errUnknownSuite
+	}
+	return v, nil
+}
+
+// FromV1 returns the v2 VRF of a VRF of v1, e.g. one made by v1.New with a custom config.
+func FromV1(v v1.VRF) *VRF {
+	suite := Custom
+	switch v.Params().SuiteString {
+	case 0xfe:
+		suite = Secp256k1Sha256Tai
+	case 0x01:
+		suite = P256Sha256Tai
+	}
+	return &VRF{suite: suite, ecdsa: v}
+}
+
+// FromV1Ed25519 returns the v2 VRF of an Ed25519VRF of v1.
+func FromV1Ed25519(v v1.Ed25519VRF) *VRF {
+	p := v.Params()
+	suite := Ed25519Sha512Elligator2
+	if p.Spec == RFC9381 {
+		suite = Edwards25519Sha512Ell2
+		if p.ProofSize > 80 {
+			suite = Edwards25519Sha512Ell2BatchCompat
+		}
+	}
+	return &VRF{suite: suite, ed: v}
+}
+
+// V1 returns the VRF of v1 of a Weierstrass suite, or nil for the Ed25519 suites.
+func (v *VRF) V1() v1.VRF {
+	return v.ecdsa
+}
+
+// V1Ed25519 returns the Ed25519VRF of v1 of an Ed25519 suite, or nil for the others.
+func (v *VRF) V1Ed25519() v1.Ed25519VRF {
+	return v.ed
+}
+
+// Suite returns the suite of v.
+func (v *VRF) Suite() Suite {
+	return v.suite
+}
+
+// Params returns the parameters of the suite.
+func (v *VRF) Params() SuiteParams {
+	if v.ed != nil {
+		return v.ed.Params()
+	}
+	return v.ecdsa.Params()
+}
+
+// Prove proves alpha with sk: an *ecdsa.PrivateKey or a *v1.PrivateKey for the Weierstrass
+// suites, an ed25519.PrivateKey, or its seed as one, for the Ed25519 ones.
+func (v *VRF) Prove(sk crypto.PrivateKey, alpha []byte) (*Proof, error) {
+	var (
+		p   Proof
+		err error
+	)
+	switch sk := sk.(type) {
+	case *ecdsa.PrivateKey:
+		if v.ecdsa == nil {
+			return nil, errKeyType
+		}
+		p.Beta, p.Pi, err = v.ecdsa.Prove(sk, alpha)
+	case *v1.PrivateKey:
+		if v.ecdsa == nil {
+			return nil, errKeyType
+		}
+		p.Beta, p.Pi, err = v.ecdsa.Prove(sk.ECDSA(), alpha)
+	case ed25519.PrivateKey:
+		if v.ed == nil {
+			return nil, errKeyType
+		}
+		p.Beta, p.Pi, err = v.ed.Prove(sk, alpha)
+	default:
+		return nil, errKeyType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Verify verifies the proof pi of alpha by pk, an *ecdsa.PublicKey or a *v1.PublicKey for the
+// Weierstrass suites, an ed25519.PublicKey for the Ed25519 ones, and returns it with its output.
+func (v *VRF) Verify(pk crypto.PublicKey, alpha, pi []byte) (*Proof, error) {
+	var (
+		beta []byte
+		err  error
+	)
+	switch pk := pk.(type) {
+	case *ecdsa.PublicKey:
+		if v.ecdsa == nil {
+			return nil, errKeyType
+		}
+		beta, err = v.ecdsa.Verify(pk, alpha, pi)
+	case *v1.PublicKey:
+		if v.ecdsa == nil {
+			return nil, errKeyType
+		}
+		beta, err = v.ecdsa.Verify(pk.ECDSA(), alpha, pi)
+	case ed25519.PublicKey:
+		if v.ed == nil {
+			return nil, errKeyType
+		}
+		beta, err = v.ed.Verify(pk, alpha, pi)
+	default:
+		return nil, errKeyType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{Pi: pi, Beta: beta}, nil
+}
+
+// BatchItem is an input of VerifyBatch.
+type BatchItem struct {
+	PublicKey crypto.PublicKey
+	Alpha     []byte
+	Pi        []byte
+}
+
+// BatchResult is the result of an item of a batch: its proof, or its error.
+type BatchResult struct {
+	Proof *Proof
+	Err   error
+}
+
+// ProveBatch proves the inputs with sk, as v1.ProveBatchContext: results are in the order of
+// the inputs, the inputs not proven once ctx is done get its error, and the error returned is
+// the one of the first failed input. The options only apply to the Weierstrass suites.
+func (v *VRF) ProveBatch(ctx context.Context, sk crypto.PrivateKey, alphas [][]byte, opts ...BatchOption) ([]BatchResult, error) {
+	if key, ok := sk.(*v1.PrivateKey); ok {
+		sk = key.ECDSA()
+	}
+	if key, ok := sk.(*ecdsa.PrivateKey); ok && v.ecdsa != nil {
+		v1Results, err := v1.ProveBatchContext(ctx, v.ecdsa, key, alphas, opts...)
+		results := make([]BatchResult, len(v1Results))
+		for i, r := range v1Results {
+			if results[i].Err = r.Err; r.Err == nil {
+				results[i].Proof = &Proof{Pi: r.Pi, Beta: r.Beta}
+			}
+		}
+		return results, err
+	}
+	return v.batch(ctx, len(alphas), func(i int) (*Proof, error) { return v.Prove(sk, alphas[i]) })
+}
+
+// VerifyBatch verifies the items, as v1.VerifyBatchContext: results are in the order of the
+// items, the items not verified once ctx is done get its error, and the error returned is the one
+// of the first failed item. Batch-compatible Ed25519 proofs are verified at once, as by
+// v1.VerifyEd25519Batch. The options only apply to the Weierstrass suites.
+func (v *VRF) VerifyBatch(ctx context.Context, items []BatchItem, opts ...BatchOption) ([]BatchResult, error) {
+	if edItems, ok := ed25519Items(items); ok && v.suite == Edwards25519Sha512Ell2BatchCompat && ctx.Err() == nil {
+		v1Results, err := v1.VerifyEd25519Batch(v.ed, edItems)
+		return convertResults(v1Results, items), err
+	}
+	if v.ecdsa != nil {
+		v1Items := make([]v1.BatchItem, len(items))
+		for i, item := range items {
+			switch pk := item.PublicKey.(type) {
+			case *ecdsa.PublicKey:
+				v1Items[i] = v1.BatchItem{PublicKey: pk, Alpha: item.Alpha, Pi: item.Pi}
+			case *v1.PublicKey:
+				v1Items[i] = v1.BatchItem{PublicKey: pk.ECDSA(), Alpha: item.Alpha, Pi: item.Pi}
+			default:
+				return v.batch(ctx, len(items), func(i int) (*Proof, error) { return v.Verify(items[i].PublicKey, items[i].Alpha, items[i].Pi) })
+			}
+		}
+		v1Results, err := v1.VerifyBatchContext(ctx, v.ecdsa, v1Items, opts...)
+		return convertResults(v1Results, items), err
+	}
+	return v.batch(ctx, len(items), func(i int) (*Proof, error) { return v.Verify(items[i].PublicKey, items[i].Alpha, items[i].Pi) })
+}
+
+// ed25519Items returns the items for v1.VerifyEd25519Batch, if all their keys are Ed25519 keys.
+func ed25519Items(items []BatchItem) ([]v1.Ed25519BatchItem, bool) {
+	edItems := make([]v1.Ed25519BatchItem, len(items))
+	for i, item := range items {
+		pk, ok := item.PublicKey.(ed25519.PublicKey)
+		if !ok {
+			return nil, false
+		}
+		edItems[i] = v1.Ed25519BatchItem{PublicKey: pk, Alpha: item.Alpha, Pi: item.Pi}
+	}
+	return edItems, true
+}
+
+// convertResults returns the v2 results of the items of a v1 batch.
+func convertResults(v1Results []v1.BatchResult, items []BatchItem) []BatchResult {
+	results := make([]BatchResult, len(v1Results))
+	for i, r := range v1Results {
+		if results[i].Err = r.Err; r.Err == nil {
+			results[i].Proof = &Proof{Pi: items[i].Pi, Beta: r.Beta}
+		}
+	}
+	return results
+}
+
+// batch runs the items one by one until ctx is done.
+func (v *VRF) batch(ctx context.Context, n int, do func(i int) (*Proof, error)) ([]BatchResult, error) {
+	var (
+		results  = make([]BatchResult, n)
+		firstErr error
+	)
+	for i := range results {
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+		} else {
+			results[i].Proof, results[i].Err = do(i)
+		}
+		if firstErr == nil {
+			firstErr = results[i].Err
+		}
+	}
+	return results, firstErr
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	v1 "github.com/vechain/go-ecvrf"
+	"github.com/vechain/go-ecvrf/v2"
+)
+
+func TestSuites(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edSK, _ := ed25519.GenerateKey(rand.Reader)
+	edPK := edSK.Public()
+	for _, tt := range []struct {
+		suite ecvrf.Suite
+		opts  []ecvrf.Option
+		sk    crypto.PrivateKey
+		pk    crypto.PublicKey
+		v1    func(alpha []byte) (beta, pi []byte, err error)
+	}{
+		{ecvrf.P256Sha256Tai, nil, p256, &p256.PublicKey, func(alpha []byte) ([]byte, []byte, error) { return v1.NewP256Sha256Tai().Prove(p256, alpha) }},
+		{ecvrf.P256Sha256Tai, []ecvrf.Option{ecvrf.WithSpecVersion(ecvrf.RFC9381)}, p256, &p256.PublicKey, func(alpha []byte) ([]byte, []byte, error) {
+			return v1.NewP256Sha256Tai(v1.WithSpecVersion(v1.RFC9381)).Prove(p256, alpha)
+		}},
+		{ecvrf.Edwards25519Sha512Ell2, nil, edSK, edPK, func(alpha []byte) ([]byte, []byte, error) { return v1.NewEd25519Sha512Ell2().Prove(edSK, alpha) }},
+		{ecvrf.Edwards25519Sha512Ell2BatchCompat, nil, edSK, edPK, func(alpha []byte) ([]byte, []byte, error) {
+			return v1.NewEd25519Sha512Ell2BatchCompat().Prove(edSK, alpha)
+		}},
+		{ecvrf.Ed25519Sha512Elligator2, nil, edSK, edPK, func(alpha []byte) ([]byte, []byte, error) { return v1.NewEd25519Sha512Elligator2().Prove(edSK, alpha) }},
+	} {
+		t.Run(tt.suite.String(), func(t *testing.T) {
+			v, err := ecvrf.New(tt.suite, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := v.Prove(tt.sk, []byte("alpha"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if beta, pi, _ := tt.v1([]byte("alpha")); !bytes.Equal(p.Pi, pi) || !bytes.Equal(p.Beta, beta) {
+				t.Fatalf("Prove() = %x, %x, want the proof of v1 %x, %x", p.Pi, p.Beta, pi, beta)
+			}
+			if got, err := v.Verify(tt.pk, []byte("alpha"), p.Pi); err != nil || !bytes.Equal(got.Beta, p.Beta) {
+				t.Fatalf("Verify() = %+v, %v", got, err)
+			}
+			if _, err := v.Verify(tt.pk, []byte("other"), p.Pi); err == nil {
+				t.Fatal("Verify() of another input succeeded")
+			}
+
+			// v1 and v2 round trip
+			var back *ecvrf.VRF
+			if v.V1() != nil {
+				back = ecvrf.FromV1(v.V1())
+			} else {
+				back = ecvrf.FromV1Ed25519(v.V1Ed25519())
+			}
+			if got := back.Suite(); got != tt.suite {
+				t.Fatalf("suite of the v1 VRF = %v, want %v", got, tt.suite)
+			}
+
+			alphas := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+			proved, err := v.ProveBatch(context.Background(), tt.sk, alphas)
+			if err != nil {
+				t.Fatal(err)
+			}
+			items := make([]ecvrf.BatchItem, len(alphas))
+			for i, r := range proved {
+				items[i] = ecvrf.BatchItem{PublicKey: tt.pk, Alpha: alphas[i], Pi: r.Proof.Pi}
+			}
+			items[1].Alpha = []byte("x")
+			results, err := v.VerifyBatch(context.Background(), items)
+			if err == nil || results[1].Err == nil || results[1].Proof != nil || !bytes.Equal(results[2].Proof.Beta, proved[2].Proof.Beta) {
+				t.Fatalf("VerifyBatch() = %+v, %v", results, err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if results, err := v.VerifyBatch(ctx, items[:1]); err != context.Canceled || results[0].Err != context.Canceled {
+				t.Fatalf("VerifyBatch() canceled = %+v, %v", results, err)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	v, _ := ecvrf.New(ecvrf.P256Sha256Tai)
+	sk, err := v1.GenerateKey(v.V1(), elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := v.Prove(sk, []byte("alpha"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(sk.PublicKey(), []byte("alpha"), p.Pi); err != nil {
+		t.Fatal(err)
+	}
+	_, edSK, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := v.Prove(edSK, nil); err == nil {
+		t.Fatal("Prove() with a key of another suite succeeded")
+	}
+	if _, err := v.Verify(edSK.Public(), nil, p.Pi); err == nil {
+		t.Fatal("Verify() with a key of another suite succeeded")
+	}
+
+	for _, bad := range []func() (*ecvrf.VRF, error){
+		func() (*ecvrf.VRF, error) { return ecvrf.New(ecvrf.Custom) },
+		func() (*ecvrf.VRF, error) {
+			return ecvrf.New(ecvrf.Edwards25519Sha512Ell2, ecvrf.WithSpecVersion(ecvrf.RFC9381))
+		},
+		func() (*ecvrf.VRF, error) {
+			return ecvrf.New(ecvrf.Ed25519Sha512Elligator2, ecvrf.WithV1Options(v1.WithSelfCheck()))
+		},
+	} {
+		if _, err := bad(); err == nil {
+			t.Fatal("New() of an invalid configuration succeeded")
+		}
+	}
+}
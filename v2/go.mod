@@ -0,0 +1,7 @@
+module github.com/vechain/go-ecvrf/v2
+
+go 1.15
+
+require github.com/vechain/go-ecvrf v0.0.0-20200305101714-4252ed3a3b96
+
+replace github.com/vechain/go-ecvrf => ../
@@ -0,0 +1,113 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+var (
+	errInvalidCurve      = errors.New("invalid curve parameters")
+	errCurveMismatch     = errors.New("curve doesn't match the y2 function of the config")
+	errInvalidPrivateKey = errors.New("invalid private key")
+	errNotFIPSApproved   = errors.New("curve is not approved in FIPS mode")
+)
+
+// checkCurve returns an error if the curve lacks the parameters used by the VRF, or if its base point
+// doesn't satisfy the equation given by the config. Results are cached for each curve.
+func (v *vrf) checkCurve(c elliptic.Curve) error {
+	return v.lookupCurve(c).err
+}
+
+// arith returns the fast arithmetic of the curve, nil if it's not supported or the curve is invalid.
+func (v *vrf) arith(c elliptic.Curve) *weierstrass {
+	return v.lookupCurve(c).w
+}
+
+// lookupCurve returns the check of the curve, cached for each curve.
+func (v *vrf) lookupCurve(c elliptic.Curve) curveCheck {
+	if c == nil {
+		return curveCheck{err: errInvalidCurve}
+	}
+	params := c.Params()
+	if params == nil {
+		return curveCheck{err: errInvalidCurve}
+	}
+	if r, ok := v.curves.Load(params); ok {
+		return r.(curveCheck)
+	}
+	r := v.checkCurveParams(c)
+	v.curves.Store(params, r)
+	return r
+}
+
+// curveCheck is the result of checkCurve cached for a curve, with the arithmetic of the curve.
+type curveCheck struct {
+	err error
+	w   *weierstrass
+}
+
+func (v *vrf) checkCurveParams(c elliptic.Curve) curveCheck {
+	params := c.Params()
+	if params.P == nil || params.N == nil || params.B == nil || params.Gx == nil || params.Gy == nil ||
+		params.P.Sign() <= 0 || params.N.Sign() <= 0 || params.BitSize <= 0 {
+		return curveCheck{err: errInvalidCurve}
+	}
+	if FIPSMode() && !isNISTCurve(c) {
+		return curveCheck{err: errNotFIPSApproved}
+	}
+	// the internal arithmetic is built only if the base point satisfies y2
+	if w := curveArith(c, v.cfg.Y2); w != nil {
+		return curveCheck{w: w}
+	}
+	// otherwise the curve's own methods are used, which panic on points off its equation
+	gy2 := new(big.Int).Mul(params.Gy, params.Gy)
+	if gy2.Mod(gy2, params.P).Cmp(new(big.Int).Mod(v.cfg.Y2(c, params.Gx), params.P)) != 0 ||
+		!c.IsOnCurve(params.Gx, params.Gy) {
+		return curveCheck{err: errCurveMismatch}
+	}
+	return curveCheck{}
+}
+
+// isNISTCurve returns whether c is one of the NIST curves of crypto/elliptic.
+func isNISTCurve(c elliptic.Curve) bool {
+	switch c {
+	case elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521():
+		return true
+	}
+	return false
+}
+
+// checkPublicKey returns an error if pk can't be handled without panicking, i.e. if it has nil fields
+// or a curve not usable with the config. Whether the key is valid is checked by ECVRF_validate_key afterwards.
+func (v *vrf) checkPublicKey(pk *ecdsa.PublicKey) error {
+	if pk == nil {
+		return errInvalidPublicKey
+	}
+	if err := v.checkCurve(pk.Curve); err != nil {
+		return err
+	}
+	if pk.X == nil || pk.Y == nil {
+		return errInvalidPublicKey
+	}
+	return nil
+}
+
+// checkPrivateKey is like checkPublicKey, and also requires the scalar of sk to be in [1, q).
+// The public key is not checked to match the scalar.
+func (v *vrf) checkPrivateKey(sk *ecdsa.PrivateKey) error {
+	if sk == nil {
+		return errInvalidPrivateKey
+	}
+	if err := v.checkPublicKey(&sk.PublicKey); err != nil {
+		return err
+	}
+	if sk.D == nil || sk.D.Sign() <= 0 || sk.D.Cmp(sk.Curve.Params().N) >= 0 {
+		return errInvalidPrivateKey
+	}
+	return nil
+}
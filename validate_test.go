@@ -0,0 +1,135 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestAdversarialInputs(t *testing.T) {
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	k1D, _ := rand.Int(rand.Reader, secp256k1.N)
+	k1D.Add(k1D, big.NewInt(1)).Mod(k1D, secp256k1.N)
+	k1Key := &ecdsa.PrivateKey{D: k1D}
+	k1Key.Curve = secp256k1
+	k1Key.X, k1Key.Y = refScalarMult(secp256k1.P, big.NewInt(0), secp256k1.Gx, secp256k1.Gy, k1D.Bytes())
+
+	type badKey struct {
+		sk      *ecdsa.PrivateKey
+		badPub  bool // whether the public key is invalid, or only the private scalar
+		badName string
+	}
+	badKeys := func(sk *ecdsa.PrivateKey, other elliptic.Curve) []badKey {
+		with := func(f func(k *ecdsa.PrivateKey)) *ecdsa.PrivateKey {
+			k := *sk
+			f(&k)
+			return &k
+		}
+		return []badKey{
+			{nil, true, "nil key"},
+			{with(func(k *ecdsa.PrivateKey) { k.Curve = nil }), true, "nil curve"},
+			{with(func(k *ecdsa.PrivateKey) { k.Curve = &elliptic.CurveParams{} }), true, "nil params"},
+			{with(func(k *ecdsa.PrivateKey) { k.X = nil }), true, "nil X"},
+			{with(func(k *ecdsa.PrivateKey) { k.Y = nil }), true, "nil Y"},
+			{with(func(k *ecdsa.PrivateKey) { k.Y = new(big.Int).Add(k.Y, big.NewInt(1)) }), true, "off curve"},
+			{with(func(k *ecdsa.PrivateKey) { k.X = new(big.Int).Neg(k.X) }), true, "negative X"},
+			{with(func(k *ecdsa.PrivateKey) { k.X = new(big.Int).Lsh(k.X, 300) }), true, "oversized X"},
+			{with(func(k *ecdsa.PrivateKey) { k.X, k.Y = new(big.Int), new(big.Int) }), true, "infinity"},
+			{with(func(k *ecdsa.PrivateKey) { k.Curve = other }), true, "other curve"},
+			{with(func(k *ecdsa.PrivateKey) { k.D = nil }), false, "nil D"},
+			{with(func(k *ecdsa.PrivateKey) { k.D = new(big.Int) }), false, "zero D"},
+			{with(func(k *ecdsa.PrivateKey) { k.D = k.Params().N }), false, "D = q"},
+			{with(func(k *ecdsa.PrivateKey) { k.D = big.NewInt(-1) }), false, "negative D"},
+		}
+	}
+	pis := [][]byte{nil, {}, {2}, {0}, make([]byte, 81), make([]byte, 200)}
+	for n := 1; n < 100; n += 7 {
+		pi := make([]byte, n)
+		rand.Read(pi)
+		pi[0] = 2 + pi[0]&1
+		pis = append(pis, pi)
+	}
+
+	tests := []struct {
+		name  string
+		vrf   VRF
+		sk    *ecdsa.PrivateKey
+		other elliptic.Curve
+	}{
+		{"p256", NewP256Sha256Tai(), p256Key, secp256k1},
+		{"secp256k1", NewSecp256k1Sha256Tai(), k1Key, elliptic.P256()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, goodPi, err := tt.vrf.Prove(tt.sk, []byte("alpha"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, k := range badKeys(tt.sk, tt.other) {
+				var (
+					sk, name = k.sk, k.badName
+					pk       *ecdsa.PublicKey
+				)
+				if sk != nil {
+					pk = &sk.PublicKey
+				}
+				noPanic(t, name, func() {
+					if _, _, err := tt.vrf.Prove(sk, []byte("alpha")); err == nil {
+						t.Errorf("Prove() with %s succeeded", name)
+					}
+					if _, err := ProveBatch(tt.vrf, sk, [][]byte{[]byte("alpha")}); err == nil {
+						t.Errorf("ProveBatch() with %s succeeded", name)
+					}
+					if !k.badPub {
+						if tt.vrf.GenerateNonce(sk, []byte("data")) != nil {
+							t.Errorf("GenerateNonce() with %s succeeded", name)
+						}
+						return
+					}
+					tt.vrf.GenerateNonce(sk, []byte("data"))
+					tt.vrf.EncodeToCurve(pk, []byte("alpha"))
+					if _, err := tt.vrf.Verify(pk, []byte("alpha"), goodPi); err == nil {
+						t.Errorf("Verify() with %s succeeded", name)
+					}
+					if _, err := tt.vrf.AppendVerify(nil, pk, []byte("alpha"), goodPi); err == nil {
+						t.Errorf("AppendVerify() with %s succeeded", name)
+					}
+					if _, err := tt.vrf.NewVerifier(pk).Verify([]byte("alpha"), goodPi); err == nil {
+						t.Errorf("verifier.Verify() with %s succeeded", name)
+					}
+					if err := tt.vrf.ValidatePublicKey(pk); err == nil {
+						t.Errorf("ValidatePublicKey() with %s succeeded", name)
+					}
+					if _, err := VerifyBatch(tt.vrf, []BatchItem{{PublicKey: pk, Alpha: []byte("alpha"), Pi: goodPi}}); err == nil {
+						t.Errorf("VerifyBatch() with %s succeeded", name)
+					}
+				})
+			}
+			for _, pi := range pis {
+				noPanic(t, "pi", func() {
+					if _, err := tt.vrf.Verify(&tt.sk.PublicKey, []byte("alpha"), pi); err == nil {
+						t.Errorf("Verify() accepted pi %x", pi)
+					}
+					if _, err := tt.vrf.NewVerifier(&tt.sk.PublicKey).Verify(nil, pi); err == nil {
+						t.Errorf("verifier.Verify() accepted pi %x", pi)
+					}
+				})
+			}
+		})
+	}
+}
+
+func noPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("%s: panic: %v", name, r)
+		}
+	}()
+	f()
+}
@@ -0,0 +1,3 @@
+module github.com/vechain/go-ecvrf/vectors
+
+go 1.16
@@ -0,0 +1,204 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package vectors embeds the test vectors of go-ecvrf, so other projects can check their use of
+// the library, or their own implementations, against the same cases, without copying the files.
+// It has no dependencies, not even on go-ecvrf.
+//
+// Each suite has a JSON file of cases, also available through Files for tools in other
+// languages: arrays of objects with sk, pk, alpha, pi and beta, and the intermediate values of
+// the proofs h, k, gamma, u, v, c and s, all hex encoded, as generated by cmd/vrfvectors.
+// The files ending in _invalid.json hold proofs that must be rejected, generated by
+// 'vrfvectors -invalid': objects with the reason, pk, alpha, pi, and the code of the error of
+// go-ecvrf.
+package vectors
+
+import (
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Files holds the JSON files of the vectors.
+//
+//go:embed *.json
+var Files embed.FS
+
+// Case is a test vector: the proof pi and output beta of alpha by the key sk of public key pk.
+// The public keys are compressed points, and the keys of the Ed25519 suites are the seeds and
+// public keys of crypto/ed25519.
+type Case struct {
+	SK, PK, Alpha, Pi, Beta []byte
+
+	// H is the point of hash_to_curve, k the nonce, U = k*B and V = k*H the announcements, and c
+	// and s the challenge and the response of the proof, whose first part is Gamma. Scalars are
+	// encoded as in the proofs, little-endian for Ed25519.
+	H, K, Gamma, U, V, C, S []byte
+}
+
+// hexCase is a case as encoded in the files.
+type hexCase struct {
+	SK    string `json:"sk"`
+	PK    string `json:"pk"`
+	Alpha string `json:"alpha"`
+	Pi    string `json:"pi"`
+	Beta  string `json:"beta"`
+	H     string `json:"h"`
+	K     string `json:"k"`
+	Gamma string `json:"gamma"`
+	U     string `json:"u"`
+	V     string `json:"v"`
+	C     string `json:"c"`
+	S     string `json:"s"`
+}
+
+// Secp256k1Sha256Tai returns the cases of NewSecp256k1Sha256Tai.
+func Secp256k1Sha256Tai() []Case { return load("secp256_k1_sha256_tai.json") }
+
+// Secp256k1Sha256TaiRFC9381 returns the cases of NewSecp256k1Sha256Tai with the RFC9381 spec
+// version, generated by cmd/vrfvectors.
+func Secp256k1Sha256TaiRFC9381() []Case { return load("secp256_k1_sha256_tai_rfc9381.json") }
+
+// P256Sha256Tai returns the cases of NewP256Sha256Tai.
+func P256Sha256Tai() []Case { return load("p256_sha256_tai.json") }
+
+// P256Sha256TaiRFC9381 returns the cases of NewP256Sha256Tai with the RFC9381 spec version, the
+// examples of RFC9381.
+func P256Sha256TaiRFC9381() []Case { return load("p256_sha256_tai_rfc9381.json") }
+
+// Ed25519Sha512Elligator2 returns the cases of NewEd25519Sha512Elligator2.
+func Ed25519Sha512Elligator2() []Case { return load("ed25519_sha512_elligator2.json") }
+
+// Ed25519Sha512Ell2 returns the cases of NewEd25519Sha512Ell2.
+func Ed25519Sha512Ell2() []Case { return load("ed25519_sha512_ell2.json") }
+
+// Ed25519Sha512Ell2BatchCompat returns the cases of NewEd25519Sha512Ell2BatchCompat.
+func Ed25519Sha512Ell2BatchCompat() []Case { return load("ed25519_sha512_ell2_batchcompat.json") }
+
+// InvalidCase is a proof pi of alpha by the public key pk that must be rejected. Reason tells how
+// the proof is invalid, e.g. "off-curve gamma" or "s >= q", and Code is the code of the error
+// returned by the Verify of go-ecvrf.
+type InvalidCase struct {
+	Reason        string
+	PK, Alpha, Pi []byte
+	Code          string
+}
+
+// The codes of the errors of invalid cases, one per sentinel error of go-ecvrf, e.g. CodeNotSquare
+// for ecvrf.ErrNotSquare. Unlike the messages, they don't change between versions.
+const (
+	CodeUnrecognizedPointEncoding = "unrecognized_point_encoding"
+	CodeInvalidPointLength        = "invalid_point_length"
+	CodeNotSquare                 = "not_square"
+	CodeNonCanonicalPoint         = "non_canonical_point"
+	CodeNotOnCurve                = "not_on_curve"
+	CodeInvalidProofLength        = "invalid_proof_length"
+	CodeInvalidProof              = "invalid_proof"
+	CodeInvalidProofScalar        = "invalid_proof_scalar"
+	CodeNonCanonicalProof         = "non_canonical_proof"
+)
+
+// hexInvalidCase is an invalid case as encoded in the files.
+type hexInvalidCase struct {
+	Reason string `json:"reason"`
+	PK     string `json:"pk"`
+	Alpha  string `json:"alpha"`
+	Pi     string `json:"pi"`
+	Code   string `json:"code"`
+}
+
+// Secp256k1Sha256TaiInvalid returns the invalid proofs of NewSecp256k1Sha256Tai.
+func Secp256k1Sha256TaiInvalid() []InvalidCase {
+	return loadInvalid("secp256_k1_sha256_tai_invalid.json")
+}
+
+// Secp256k1Sha256TaiRFC9381Invalid returns the invalid proofs of NewSecp256k1Sha256Tai with the
+// RFC9381 spec version.
+func Secp256k1Sha256TaiRFC9381Invalid() []InvalidCase {
+	return loadInvalid("secp256_k1_sha256_tai_rfc9381_invalid.json")
+}
+
+// P256Sha256TaiInvalid returns the invalid proofs of NewP256Sha256Tai.
+func P256Sha256TaiInvalid() []InvalidCase { return loadInvalid("p256_sha256_tai_invalid.json") }
+
+// P256Sha256TaiRFC9381Invalid returns the invalid proofs of NewP256Sha256Tai with the RFC9381
+// spec version.
+func P256Sha256TaiRFC9381Invalid() []InvalidCase {
+	return loadInvalid("p256_sha256_tai_rfc9381_invalid.json")
+}
+
+// Ed25519Sha512Elligator2Invalid returns the invalid proofs of NewEd25519Sha512Elligator2. There
+// is no case of s >= q, which draft-03 reduces modulo q like libsodium.
+func Ed25519Sha512Elligator2Invalid() []InvalidCase {
+	return loadInvalid("ed25519_sha512_elligator2_invalid.json")
+}
+
+// Ed25519Sha512Ell2Invalid returns the invalid proofs of NewEd25519Sha512Ell2.
+func Ed25519Sha512Ell2Invalid() []InvalidCase { return loadInvalid("ed25519_sha512_ell2_invalid.json") }
+
+// Ed25519Sha512Ell2BatchCompatInvalid returns the invalid proofs of NewEd25519Sha512Ell2BatchCompat.
+func Ed25519Sha512Ell2BatchCompatInvalid() []InvalidCase {
+	return loadInvalid("ed25519_sha512_ell2_batchcompat_invalid.json")
+}
+
+// load decodes the cases of the file, fresh for each call so callers may modify them. The files
+// are embedded, so an error is a bug of the package.
+func load(name string) []Case {
+	data, err := Files.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	var hexCases []hexCase
+	if err := json.Unmarshal(data, &hexCases); err != nil {
+		panic(fmt.Sprintf("vectors: %s: %v", name, err))
+	}
+	cases := make([]Case, len(hexCases))
+	for i, h := range hexCases {
+		c := &cases[i]
+		for _, f := range []struct {
+			dst *[]byte
+			src string
+		}{
+			{&c.SK, h.SK}, {&c.PK, h.PK}, {&c.Alpha, h.Alpha}, {&c.Pi, h.Pi}, {&c.Beta, h.Beta},
+			{&c.H, h.H}, {&c.K, h.K}, {&c.Gamma, h.Gamma}, {&c.U, h.U}, {&c.V, h.V}, {&c.C, h.C}, {&c.S, h.S},
+		} {
+			b, err := hex.DecodeString(f.src)
+			if err != nil {
+				panic(fmt.Sprintf("vectors: %s: case %d: %v", name, i, err))
+			}
+			if len(b) > 0 || f.dst == &c.Alpha {
+				*f.dst = b
+			}
+		}
+	}
+	return cases
+}
+
+// loadInvalid is load for the invalid cases.
+func loadInvalid(name string) []InvalidCase {
+	data, err := Files.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	var hexCases []hexInvalidCase
+	if err := json.Unmarshal(data, &hexCases); err != nil {
+		panic(fmt.Sprintf("vectors: %s: %v", name, err))
+	}
+	cases := make([]InvalidCase, len(hexCases))
+	for i, h := range hexCases {
+		c := &cases[i]
+		c.Reason, c.Code = h.Reason, h.Code
+		for _, f := range []struct {
+			dst *[]byte
+			src string
+		}{{&c.PK, h.PK}, {&c.Alpha, h.Alpha}, {&c.Pi, h.Pi}} {
+			b, err := hex.DecodeString(f.src)
+			if err != nil {
+				panic(fmt.Sprintf("vectors: %s: case %d: %v", name, i, err))
+			}
+			*f.dst = b
+		}
+	}
+	return cases
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package verifycache memoizes VRF verifications, for gossip layers which receive the same proof
+// from many peers: the result of the verification of (suite, pk, alpha, pi), beta or the error, is
+// kept in an LRU cache bounded in size and optionally in age.
+//
+// Entries are keyed by SHA-256 over the parameters of the suite, the curve and the canonical
+// encoding of the key, alpha and pi, so the cache never holds the inputs themselves. Keys which
+// are not valid for the VRF are never cached, so the cache returns the results of the VRF. VRFs with the same SuiteParams share entries, which is
+// only wrong for custom suites of New differing by other options than those of SuiteParams: give
+// them separate caches.
+package verifycache
+
+import (
+	"container/list"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// DefaultSize is the number of entries of a cache, if WithSize isn't given.
+const DefaultSize = 4096
+
+// Stats are the counters of a cache since its creation.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	// Evictions counts the entries dropped for the size limit, Expirations those dropped for their age.
+	Evictions   uint64
+	Expirations uint64
+	// Len is the current number of entries.
+	Len int
+}
+
+// HitRate is the ratio of hits over lookups, 0 if none.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type key [sha256.Size]byte
+
+type entry struct {
+	key     key
+	beta    []byte
+	err     error
+	expires time.Time
+}
+
+// Cache is an LRU cache of verifications. It's safe for concurrent use.
+type Cache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[key]*list.Element
+	stats Stats
+}
+
+// Option configures a Cache.
+type Option func(*Cache)
+
+// WithSize sets the maximum number of entries, the least recently used being evicted first.
+func WithSize(n int) Option {
+	return func(c *Cache) {
+		if n > 0 {
+			c.size = n
+		}
+	}
+}
+
+// WithTTL makes the entries expire after ttl from their verification. Entries never expire by default.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithClock sets the clock of the expiries, time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(c *Cache) {
+		c.now = now
+	}
+}
+
+// New creates a cache.
+func New(opts ...Option) *Cache {
+	c := &Cache{size: DefaultSize, now: time.Now, lru: list.New(), items: make(map[key]*list.Element)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Verify returns the result of v.Verify(pk, alpha, pi), from the cache if the proof was verified
+// before. Verifications are memoized whether they succeed or fail; concurrent verifications of a
+// proof not cached yet may all run.
+func (c *Cache) Verify(v ecvrf.VRF, pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error) {
+	if !cacheable(v, pk) {
+		return v.Verify(pk, alpha, pi)
+	}
+	k := keyOf(v, pk, alpha, pi)
+	if e := c.get(k); e != nil {
+		if e.err != nil {
+			return nil, e.err
+		}
+		return append([]byte(nil), e.beta...), nil
+	}
+	beta, err := v.Verify(pk, alpha, pi)
+	c.put(k, beta, err)
+	return beta, err
+}
+
+// Stats returns the counters of the cache.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Len = c.lru.Len()
+	return s
+}
+
+// Purge drops all the entries, e.g. after a key was revoked. The counters are kept.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.items = make(map[key]*list.Element)
+}
+
+// get returns the entry of k, nil if it's missing or expired. Entries are never modified once put.
+func (c *Cache) get(k key) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[k]
+	if !ok {
+		c.stats.Misses++
+		return nil
+	}
+	e := el.Value.(*entry)
+	if c.ttl > 0 && !c.now().Before(e.expires) {
+		c.remove(el)
+		c.stats.Expirations++
+		c.stats.Misses++
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	c.stats.Hits++
+	return e
+}
+
+func (c *Cache) put(k key, beta []byte, err error) {
+	e := &entry{key: k, beta: append([]byte(nil), beta...), err: err}
+	if c.ttl > 0 {
+		e.expires = c.now().Add(c.ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[k]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.items[k] = c.lru.PushFront(e)
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *Cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.items, el.Value.(*entry).key)
+}
+
+// cacheable reports whether pk is a valid key of v, whose coordinates in [0, P) have a canonical
+// encoding. Other keys may look the same as valid ones to keyOf, e.g. with -X for X.
+func cacheable(v ecvrf.VRF, pk *ecdsa.PublicKey) bool {
+	if pk == nil || pk.Curve == nil || pk.X == nil || pk.Y == nil {
+		return false
+	}
+	params := pk.Curve.Params()
+	if params == nil || params.P == nil || params.N == nil || params.B == nil || params.Gx == nil || params.Gy == nil {
+		return false
+	}
+	if pk.X.Sign() < 0 || pk.X.Cmp(params.P) >= 0 || pk.Y.Sign() < 0 || pk.Y.Cmp(params.P) >= 0 {
+		return false
+	}
+	return v.ValidatePublicKey(pk) == nil
+}
+
+// keyOf is SHA-256 over the suite parameters, the parameters of the curve, the coordinates of pk in
+// octets of the length of P, alpha and pi, each length-prefixed. pk must be cacheable.
+func keyOf(v ecvrf.VRF, pk *ecdsa.PublicKey, alpha, pi []byte) key {
+	p := v.Params()
+	h := sha256.New()
+	var ints [4 * 8]byte
+	binary.BigEndian.PutUint64(ints[0:], uint64(p.PublicKeySize))
+	binary.BigEndian.PutUint64(ints[8:], uint64(p.ProofSize))
+	binary.BigEndian.PutUint64(ints[16:], uint64(p.BetaSize))
+	binary.BigEndian.PutUint64(ints[24:], uint64(p.SecurityBits))
+	h.Write([]byte{p.SuiteString, byte(p.Spec)})
+	h.Write(ints[:])
+	var (
+		params = pk.Curve.Params()
+		size   = (params.P.BitLen() + 7) / 8
+	)
+	for _, field := range [][]byte{
+		[]byte(p.Name), []byte(p.Curve), []byte(p.Hash),
+		params.P.Bytes(), params.N.Bytes(), params.B.Bytes(), params.Gx.Bytes(), params.Gy.Bytes(),
+		pk.X.FillBytes(make([]byte, size)), pk.Y.FillBytes(make([]byte, size)), alpha, pi,
+	} {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(len(field)))
+		h.Write(n[:])
+		h.Write(field)
+	}
+	var k key
+	h.Sum(k[:0])
+	return k
+}
+
+// Wrap returns v with its verifications, including those of its verifiers, going through the cache.
+func (c *Cache) Wrap(v ecvrf.VRF) ecvrf.VRF {
+	return &cachedVRF{v, c}
+}
+
+type cachedVRF struct {
+	ecvrf.VRF
+	c *Cache
+}
+
+func (v *cachedVRF) Verify(pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error) {
+	return v.c.Verify(v.VRF, pk, alpha, pi)
+}
+
+func (v *cachedVRF) AppendVerify(dst []byte, pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error) {
+	beta, err := v.c.Verify(v.VRF, pk, alpha, pi)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, beta...), nil
+}
+
+func (v *cachedVRF) NewVerifier(pk *ecdsa.PublicKey) ecvrf.Verifier {
+	return &verifier{v, pk}
+}
+
+type verifier struct {
+	v  *cachedVRF
+	pk *ecdsa.PublicKey
+}
+
+func (vr *verifier) Verify(alpha, pi []byte) ([]byte, error) {
+	return vr.v.Verify(vr.pk, alpha, pi)
+}
+
+func (vr *verifier) AppendVerify(dst []byte, alpha, pi []byte) ([]byte, error) {
+	return vr.v.AppendVerify(dst, vr.pk, alpha, pi)
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	errOPRFInputTooLong = errors.New("oprf input too long")
+	errNoOPRFBlind      = errors.New("invalid oprf blind")
+)
+
+// oprfFinalizeFront is the domain separation octet of the OPRF outputs, after the suite string,
+// apart from those of the VRF, ring and DLEQ proofs.
+const oprfFinalizeFront = 0x13
+
+// OPRFBlind is the state of an OPRF client between BlindOPRF and FinalizeOPRF. It holds the blind,
+// which must stay secret, as it unblinds the element into the output of the input.
+type OPRFBlind struct {
+	curve   elliptic.Curve
+	input   []byte
+	blind   []byte
+	Element [2]*big.Int // the blinded element sent to the server
+}
+
+// BlindOPRF starts the verifiable oblivious evaluation of the input by a server of the suite of v,
+// over the curve c, in the flow of the VOPRF of RFC9497: the client sends the Element of the
+// returned blind to the server, which passes it to BlindEvaluateOPRF, then FinalizeOPRF unblinds
+// the result, and checks it against the public key of the server, without the server ever
+// learning the input or the output. The blind is read from rand, usually crypto/rand.Reader.
+//
+// The input is mapped by the hash_to_curve of the suite, with the generator in place of the public
+// key, and the outputs are hashed with the hash of the suite, so the outputs aren't those of the
+// suites of RFC9497. A key evaluating blinded elements computes x*P for any point P of the
+// clients, thus its VRF outputs are known to them: OPRF keys must not be VRF keys.
+// v must be a VRF object of this package.
+func BlindOPRF(v VRF, c elliptic.Curve, input []byte, rand io.Reader) (*OPRFBlind, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if err := impl.checkCurve(c); err != nil {
+		return nil, err
+	}
+	if len(input) > 0xffff {
+		return nil, errOPRFInputTooLong
+	}
+	core := impl.newCore(c)
+	defer core.release()
+	params := c.Params()
+	P, err := core.HashToCurve(&point{params.Gx, params.Gy}, input)
+	if err != nil {
+		return nil, err
+	}
+	r, err := core.sampleScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	E := core.ScalarMult(P, r)
+	return &OPRFBlind{
+		curve:   c,
+		input:   append([]byte(nil), input...),
+		blind:   r,
+		Element: [2]*big.Int{E.X, E.Y},
+	}, nil
+}
+
+// BlindEvaluateOPRF evaluates the blinded element of a client with the key sk of the server, and
+// proves it with a DLEQ proof, see ProveDLEQ. v must be a VRF object of this package.
+func BlindEvaluateOPRF(v VRF, sk *ecdsa.PrivateKey, element [2]*big.Int) (evaluated [2]*big.Int, proof []byte, err error) {
+	return ProveDLEQ(v, sk, element)
+}
+
+// FinalizeOPRF checks the evaluation of the blinded element of b by the server of the public key pk,
+// and returns the output of the input of b. The blind is wiped once the output is returned.
+// v must be a VRF object of this package.
+func FinalizeOPRF(v VRF, pk *ecdsa.PublicKey, b *OPRFBlind, evaluated [2]*big.Int, proof []byte) (output []byte, err error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if b == nil || b.blind == nil || pk == nil || pk.Curve != b.curve {
+		return nil, errNoOPRFBlind
+	}
+	if err = VerifyDLEQ(v, pk, b.Element, evaluated, proof); err != nil {
+		return
+	}
+	core := impl.newCore(pk.Curve)
+	defer core.release()
+	inv := core.invModQ(b.blind)
+	defer wipe(inv)
+	N := core.ScalarMult(&point{evaluated[0], evaluated[1]}, inv)
+	wipe(b.blind)
+	b.blind = nil
+	return oprfOutput(core, b.input, N), nil
+}
+
+// EvaluateOPRF computes the output of the input under the key of the server directly, e.g. to
+// check the tokens redeemed by clients. v must be a VRF object of this package.
+func EvaluateOPRF(v VRF, sk *ecdsa.PrivateKey, input []byte) ([]byte, error) {
+	impl, ok := v.(*vrf)
+	if !ok {
+		return nil, errUnsupportedVRF
+	}
+	if err := impl.checkPrivateKey(sk); err != nil {
+		return nil, err
+	}
+	if len(input) > 0xffff {
+		return nil, errOPRFInputTooLong
+	}
+	core := impl.newCore(sk.Curve)
+	defer core.release()
+	params := sk.Curve.Params()
+	P, err := core.HashToCurve(&point{params.Gx, params.Gy}, input)
+	if err != nil {
+		return nil, err
+	}
+	x := core.SecretOctets(sk.D)
+	defer wipe(x)
+	N, err := core.BlindScalarMult(P, x)
+	if err != nil {
+		return nil, err
+	}
+	return oprfOutput(core, input, N), nil
+}
+
+// oprfOutput hashes the input and its unblinded element into the output, like Finalize of RFC9497.
+func oprfOutput(core *core, input []byte, N *point) []byte {
+	var size [2]byte
+	binary.BigEndian.PutUint16(size[:], uint16(len(input)))
+	hasher := core.getCachedHasher()
+	hasher.Reset()
+	hasher.Write([]byte{core.SuiteString, oprfFinalizeFront})
+	hasher.Write(size[:])
+	hasher.Write(input)
+	hasher.Write(core.Marshal(N))
+	hasher.Write(core.domainBack())
+	return hasher.Sum(nil)
+}
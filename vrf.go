@@ -8,30 +8,91 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/sha256"
-	"errors"
 	"math/big"
+	"sync"
+	"time"
 )
 
-// VRF is the interface that wraps VRF methods.
-type VRF interface {
+// Prover makes VRF proofs. Components which only prove can take a Prover, rather than a VRF, so
+// their tests can mock it with a single method.
+type Prover interface {
 	// Prove constructs a VRF proof `pi` for the given input `alpha`,
 	// using the private key `sk`. The hash output is returned as `beta`.
 	Prove(sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error)
+}
 
+// ProofVerifier verifies VRF proofs against any public key, unlike Verifier which is bound to one.
+type ProofVerifier interface {
 	// Verify checks the proof `pi` of the message `alpha` against the given
 	// public key `pk`. The hash output is returned as `beta`.
+	// Only the canonical encoding of a proof is accepted, so `pi` can be used as a unique key,
+	// as long as proofs are made with deterministic nonces.
 	Verify(pk *ecdsa.PublicKey, alpha, pi []byte) (beta []byte, err error)
+
+	// AppendVerify is like Verify, but appends `beta` to `dst` and returns the extended buffer.
+	// For curves computed by the internal arithmetic, such as secp256k1, it makes no heap
+	// allocations if `dst` has enough capacity and the config has no XOF.
+	AppendVerify(dst []byte, pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error)
+}
+
+// Suite proves and verifies with the suite it describes, the part of VRF most applications use.
+type Suite interface {
+	Prover
+	ProofVerifier
+
+	// Params returns the parameters of the suite, such as the sizes of proofs and outputs.
+	Params() SuiteParams
+}
+
+// VRF is the interface that wraps VRF methods.
+type VRF interface {
+	Suite
+
+	// ValidatePublicKey checks that `pk` is a valid VRF public key: a point on the curve
+	// with canonical coordinates, which is neither the point at infinity nor of low order.
+	// Verify rejects invalid keys as well; calling it when keys are registered makes sure
+	// that weak keys are never accepted in the first place.
+	ValidatePublicKey(pk *ecdsa.PublicKey) error
+
+	// NewVerifier creates the Verifier of the public key `pk`, which precomputes
+	// multiples of the key point to speed up repeated verifications.
+	NewVerifier(pk *ecdsa.PublicKey) Verifier
+
+	// EncodeToCurve maps the input `alpha` to a point on the curve of the
+	// public key `pk`, using the same hash_to_curve algorithm as Prove and Verify.
+	EncodeToCurve(pk *ecdsa.PublicKey, alpha []byte) (x, y *big.Int, err error)
+
+	// GenerateNonce derives the deterministic nonce `k` from the private key `sk`
+	// and the input `data`, using the suite's hash function. Prove calls it with
+	// `data` set to the encoded point H. nil is returned if `sk` is invalid.
+	GenerateNonce(sk *ecdsa.PrivateKey, data []byte) *big.Int
+}
+
+// Verifier verifies proofs against a fixed public key. It's safe for concurrent use.
+type Verifier interface {
+	// Verify is like VRF.Verify, with the public key of the verifier.
+	Verify(alpha, pi []byte) (beta []byte, err error)
+
+	// AppendVerify is like VRF.AppendVerify, with the public key of the verifier.
+	AppendVerify(dst []byte, alpha, pi []byte) ([]byte, error)
 }
 
 // New creates and initializes a VRF object using customized config.
-func New(cfg *Config) VRF {
-	return &vrf{func(c elliptic.Curve) *core {
-		return &core{Config: cfg, curve: c}
-	}}
+// The config is copied before options are applied.
+func New(cfg *Config, opts ...Option) VRF {
+	cfgCopy := *cfg
+	for _, opt := range opts {
+		opt(&cfgCopy)
+	}
+	v := &vrf{cfg: cfgCopy, hashers: newHasherPool(cfgCopy.NewHasher)}
+	v.scratch.New = func() interface{} {
+		return &verifyScratch{hasher: v.cfg.NewHasher()}
+	}
+	return v
 }
 
 // NewSecp256k1Sha256Tai creates the VRF object configured with secp256k1/SHA256 and hash_to_curve_try_and_increment algorithm.
-func NewSecp256k1Sha256Tai() VRF {
+func NewSecp256k1Sha256Tai(opts ...Option) VRF {
 	return New(&Config{
 		SuiteString: 0xfe,
 		Cofactor:    0x01,
@@ -46,11 +107,11 @@ func NewSecp256k1Sha256Tai() VRF {
 			return x3
 		},
 		Sqrt: DefaultSqrt,
-	})
+	}, opts...)
 }
 
 // NewP256Sha256Tai creates the VRF object configured with P256/SHA256 and hash_to_curve_try_and_increment algorithm.
-func NewP256Sha256Tai() VRF {
+func NewP256Sha256Tai(opts ...Option) VRF {
 	return New(&Config{
 		SuiteString: 0x01,
 		Cofactor:    0x01,
@@ -69,24 +130,37 @@ func NewP256Sha256Tai() VRF {
 			return x3
 		},
 		Sqrt: DefaultSqrt,
-	})
+	}, opts...)
 }
 
 type vrf struct {
-	newCore func(c elliptic.Curve) *core
+	cfg     Config
+	hashers *hasherPool
+	scratch sync.Pool // *verifyScratch
+	curves  sync.Map  // *elliptic.CurveParams -> curveCheck
+}
+
+// newCore creates the core of the curve. It should be released after use.
+func (v *vrf) newCore(c elliptic.Curve) *core {
+	return &core{Config: &v.cfg, curve: c, hashers: v.hashers, arith: v.arith(c)}
 }
 
 // Prove constructs VRF proof following [draft-irtf-cfrg-vrf-06 section 5.1](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.1).
 func (v *vrf) Prove(sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err error) {
-	var (
-		core = v.newCore(sk.Curve)
-		q    = core.Q()
-	)
+	return AppendProve(nil, nil, v, sk, alpha)
+}
+
+// prove implements Prove with the given core and public key point, which can be reused across calls.
+func (v *vrf) prove(core *core, sk *ecdsa.PrivateKey, pk *point, alpha []byte) (beta, pi []byte, err error) {
+	return v.appendProve(core, nil, nil, sk, pk, alpha)
+}
+
+// appendProve is like prove, but appends beta to betaDst and pi to piDst.
+func (v *vrf) appendProve(core *core, betaDst, piDst []byte, sk *ecdsa.PrivateKey, pk *point, alpha []byte) (beta, pi []byte, err error) {
 	// step 1 is done by the caller.
 
 	// step 2: H = ECVRF_hash_to_curve(suite_string, Y, alpha_string)
-	// currently, try_and_increment algorithm is supported
-	H, err := core.HashToCurveTryAndIncrement(&point{sk.X, sk.Y}, alpha)
+	H, err := core.HashToCurve(pk, alpha)
 	if err != nil {
 		return
 	}
@@ -94,40 +168,124 @@ func (v *vrf) Prove(sk *ecdsa.PrivateKey, alpha []byte) (beta, pi []byte, err er
 	// step 3: h_string = point_to_string(H)
 	hbytes := core.Marshal(H)
 
+	// secret scalars are kept as octets of fixed length, so that the timing doesn't depend on them
+	x := core.SecretOctets(sk.D)
+	defer wipe(x)
+
 	// step 4: Gamma = x * H
-	gamma := core.ScalarMult(H, sk.D.Bytes())
+	gamma, err := core.BlindScalarMult(H, x)
+	if err != nil {
+		return
+	}
 
 	// step 5: k = ECVRF_nonce_generation(SK, h_string)
 	// it follows RFC6979
-	k := rfc6979nonce(sk.D, hbytes, core.Q(), core.NewHasher)
-	kbytes := k.Bytes()
-
-	// step 6: c = ECVRF_hash_points(H, Gamma, k*B, k*H)
-	kB := core.ScalarBaseMult(kbytes)
-	kH := core.ScalarMult(H, kbytes)
-	c := core.HashPoints(
-		H,
-		gamma,
-		kB,
-		kH)
+	kbytes, err := core.HedgedNonceOctets(x, hbytes)
+	if err != nil {
+		return
+	}
+	defer wipe(kbytes)
+
+	// step 6: c = ECVRF_hash_points(H, Gamma, k*B, k*H), or ECVRF_challenge_generation(Y, H, Gamma, k*B, k*H) in RFC9381
+	kB, err := core.BlindScalarBaseMult(kbytes)
+	if err != nil {
+		return
+	}
+	kH, err := core.BlindScalarMult(H, kbytes)
+	if err != nil {
+		return
+	}
+	c := core.Challenge(pk, H, gamma, kB, kH)
 
 	// step 7: s = (k + c*x) mod q
-	s := new(big.Int).Mul(c, sk.D)
-	s.Add(s, k)
-	s.Mod(s, q)
+	s, err := core.BlindScalarMulAdd(c, x, kbytes)
+	if err != nil {
+		return
+	}
 
 	// step 8: encode (gamma, c, s) as pi_string = point_to_string(Gamma) || int_to_string(c, n) || int_to_string(s, qLen)
-	pi = core.EncodeProof(gamma, c, s)
+	pi = core.AppendProof(piDst, gamma, c, s)
 
 	// step 9: Output pi_string
 	// here also returns beta
-	beta = core.GammaToHash(gamma)
+	beta = core.AppendGammaToHash(betaDst, gamma)
+	if v.cfg.SelfCheck {
+		if err = v.selfCheck(&sk.PublicKey, alpha, beta[len(betaDst):], pi[len(piDst):]); err != nil {
+			return nil, nil, err
+		}
+	}
 	return
 }
 
 // Verify checks the correctness of proof following [draft-irtf-cfrg-vrf-06 section 5.3](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.3).
 func (v *vrf) Verify(pk *ecdsa.PublicKey, alpha, pi []byte) (beta []byte, err error) {
+	return v.AppendVerify(nil, pk, alpha, pi)
+}
+
+// AppendVerify checks the proof like Verify, and appends beta to dst.
+func (v *vrf) AppendVerify(dst []byte, pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error) {
+	if v.cfg.Metrics == nil && v.cfg.Logger == nil {
+		return v.appendVerify(dst, pk, alpha, pi)
+	}
+	start := time.Now()
+	out, err := v.appendVerify(dst, pk, alpha, pi)
+	v.observeVerify(start, err)
+	v.logVerifyFailed(pk, alpha, err)
+	return out, err
+}
+
+// appendVerify implements AppendVerify, without metrics.
+func (v *vrf) appendVerify(dst []byte, pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error) {
+	if err := v.checkPublicKey(pk); err != nil {
+		return dst, err
+	}
+	if w := v.arith(pk.Curve); w != nil {
+		var key verifyKey
+		if prepareKey(w, &key, pk, v.cfg.Cofactor) {
+			if out, ok, err := v.appendVerifyArith(w, &key, dst, alpha, pi); ok {
+				return out, err
+			}
+		}
+	}
+	return v.appendVerifyGeneric(dst, pk, alpha, pi)
+}
+
+// ValidatePublicKey implements `ECVRF_validate_key` specified in [draft-irtf-cfrg-vrf-06 section 5.6.1](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.6.1).
+func (v *vrf) ValidatePublicKey(pk *ecdsa.PublicKey) error {
+	if err := v.checkPublicKey(pk); err != nil {
+		return err
+	}
 	core := v.newCore(pk.Curve)
+	defer core.release()
+	return core.ValidateKey(&point{pk.X, pk.Y})
+}
+
+// NewVerifier creates the Verifier of pk.
+func (v *vrf) NewVerifier(pk *ecdsa.PublicKey) Verifier {
+	return v.newVerifier(pk)
+}
+
+// appendVerifyGeneric appends beta of the generic implementation to dst.
+func (v *vrf) appendVerifyGeneric(dst []byte, pk *ecdsa.PublicKey, alpha, pi []byte) ([]byte, error) {
+	beta, err := v.verify(pk, alpha, pi)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, beta...), nil
+}
+
+// verify is the generic implementation of Verify, computed by big.Int.
+func (v *vrf) verify(pk *ecdsa.PublicKey, alpha, pi []byte) (beta []byte, err error) {
+	if err = v.checkPublicKey(pk); err != nil {
+		return
+	}
+	core := v.newCore(pk.Curve)
+	defer core.release()
+
+	// the public key is validated first, so that proofs of weak keys are never accepted
+	if err = core.ValidateKey(&point{pk.X, pk.Y}); err != nil {
+		return
+	}
 
 	// step 1: D = ECVRF_decode_proof(pi_string)
 	gamma, c, s, err := core.DecodeProof(pi)
@@ -139,30 +297,61 @@ func (v *vrf) Verify(pk *ecdsa.PublicKey, alpha, pi []byte) (beta []byte, err er
 	// step 3: (Gamma, c, s) = D
 
 	// step 4: H = ECVRF_hash_to_curve(suite_string, Y, alpha_string)
-	H, err := core.HashToCurveTryAndIncrement(&point{pk.X, pk.Y}, alpha)
+	H, err := core.HashToCurve(&point{pk.X, pk.Y}, alpha)
 	if err != nil {
 		return
 	}
 
 	// step 5: U = s*B - c*Y
 	sB := core.ScalarBaseMult(s.Bytes())
-	cY := core.ScalarMult(&point{pk.X, pk.Y}, c.Bytes())
+	cY := core.ScalarMultVartime(&point{pk.X, pk.Y}, c)
 	U := core.Sub(sB, cY)
 
 	// step 6: V = s*H - c*Gamma
-	sH := core.ScalarMult(H, s.Bytes())
-	cGamma := core.ScalarMult(gamma, c.Bytes())
-	V := core.Sub(sH, cGamma)
+	V := core.MulSubVartime(H, s, gamma, c)
 
-	// step 7: c' = ECVRF_hash_points(H, Gamma, U, V)
-	derivedC := core.HashPoints(H, gamma, U, V)
+	// step 7: c' = ECVRF_hash_points(H, Gamma, U, V), or ECVRF_challenge_generation(Y, H, Gamma, U, V) in RFC9381
+	derivedC := core.Challenge(&point{pk.X, pk.Y}, H, gamma, U, V)
 
 	// step 8: If c and c' are equal, output ("VALID", ECVRF_proof_to_hash(pi_string)); else output "INVALID"
 	if derivedC.Cmp(c) != 0 {
-		err = errors.New("invalid proof")
+		err = ErrInvalidProof
 		return
 	}
 
 	beta = core.GammaToHash(gamma)
 	return
 }
+
+// EncodeToCurve implements `ECVRF_hash_to_curve` specified in [draft-irtf-cfrg-vrf-06 section 5.4.1](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.4.1).
+func (v *vrf) EncodeToCurve(pk *ecdsa.PublicKey, alpha []byte) (x, y *big.Int, err error) {
+	if err = v.checkPublicKey(pk); err != nil {
+		return
+	}
+	core := v.newCore(pk.Curve)
+	defer core.release()
+	H, err := core.HashToCurve(&point{pk.X, pk.Y}, alpha)
+	if err != nil {
+		return
+	}
+	return H.X, H.Y, nil
+}
+
+// GenerateNonce implements `ECVRF_nonce_generation` specified in [draft-irtf-cfrg-vrf-06 section 5.4.2.1](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.4.2.1).
+// nil is returned if sk is invalid.
+func (v *vrf) GenerateNonce(sk *ecdsa.PrivateKey, data []byte) *big.Int {
+	if v.checkPrivateKey(sk) != nil {
+		return nil
+	}
+	core := v.newCore(sk.Curve)
+	defer core.release()
+	return core.GenerateNonce(sk.D, data)
+}
+
+// ProofToHash returns the output `beta` of the proof `pi` over the curve c, without verifying it,
+// following [draft-irtf-cfrg-vrf-06 section 5.2](https://tools.ietf.org/id/draft-irtf-cfrg-vrf-06.html#rfc.section.5.2).
+// It's meant for debugging and for proofs verified elsewhere: beta must only be trusted after Verify.
+// v must be a VRF object of this package.
+func ProofToHash(v VRF, c elliptic.Curve, pi []byte) (beta []byte, err error) {
+	return AppendProofToHash(nil, v, c, pi)
+}
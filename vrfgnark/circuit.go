@@ -0,0 +1,153 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package vrfgnark
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/vechain/go-ecvrf"
+)
+
+// Circuit proves that Beta is the output of Alpha under PublicKey, by a valid VRF proof kept
+// private, e.g. Circuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr] for NewSecp256k1Sha256Tai.
+// The public inputs are the key, alpha and beta, in this order.
+type Circuit[B, S emulated.FieldParams] struct {
+	PublicKey sw_emulated.AffinePoint[B] `gnark:",public"`
+	Alpha     []uints.U8                 `gnark:",public"`
+	Beta      [BetaSize]uints.U8         `gnark:",public"`
+	Proof     Proof[B, S]
+
+	Config Config `gnark:"-"`
+}
+
+// NewCircuit returns the circuit to compile for the inputs of alphaLen octets.
+func NewCircuit[B, S emulated.FieldParams](cfg Config, alphaLen int) *Circuit[B, S] {
+	return &Circuit[B, S]{Alpha: make([]uints.U8, alphaLen), Config: cfg}
+}
+
+// Define implements frontend.Circuit.
+func (c *Circuit[B, S]) Define(api frontend.API) error {
+	beta, err := Verify(api, c.Config, &c.PublicKey, c.Alpha, &c.Proof)
+	if err != nil {
+		return err
+	}
+	bytes, err := uints.NewBytes(api)
+	if err != nil {
+		return err
+	}
+	for i := range c.Beta {
+		bytes.AssertIsEqual(beta[i], c.Beta[i])
+	}
+	return nil
+}
+
+// Assign verifies the proof pi of alpha against pk with v, and returns the full witness of the
+// circuit, for alpha of the length of the circuit. The counter of try_and_increment is checked
+// against MaxCounter, so proofs which the circuit can't take are told apart.
+func Assign[B, S emulated.FieldParams](v ecvrf.VRF, cfg Config, pk *ecdsa.PublicKey, alpha, pi []byte) (*Circuit[B, S], error) {
+	if err := checkCurve[B](cfg); err != nil {
+		return nil, err
+	}
+	beta, err := v.Verify(pk, alpha, pi)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := ecvrf.ExportEVMProof(v, pk, alpha, pi)
+	if err != nil {
+		return nil, err
+	}
+	if ctr, err := counter(v, cfg, pk, alpha); err != nil {
+		return nil, err
+	} else if ctr >= cfg.maxCounter() {
+		return nil, errCounterTooLarge
+	}
+	a := &Circuit[B, S]{
+		PublicKey: sw_emulated.AffinePoint[B]{X: emulated.ValueOf[B](pk.X), Y: emulated.ValueOf[B](pk.Y)},
+		Alpha:     uints.NewU8Array(alpha),
+		Proof: Proof[B, S]{
+			Gamma: sw_emulated.AffinePoint[B]{X: emulated.ValueOf[B](proof.Gamma[0]), Y: emulated.ValueOf[B](proof.Gamma[1])},
+			C:     emulated.ValueOf[S](proof.C),
+			S:     emulated.ValueOf[S](proof.S),
+		},
+		Config: cfg,
+	}
+	copy(a.Beta[:], uints.NewU8Array(beta))
+	return a, nil
+}
+
+// counter returns the counter of try_and_increment of alpha, by trying them until the point of
+// EncodeToCurve is found.
+func counter(v ecvrf.VRF, cfg Config, pk *ecdsa.PublicKey, alpha []byte) (int, error) {
+	hx, _, err := v.EncodeToCurve(pk, alpha)
+	if err != nil {
+		return 0, err
+	}
+	for ctr := 0; ctr < 256; ctr++ {
+		if x := candidate(cfg, pk, alpha, ctr); x.Cmp(hx) == 0 {
+			return ctr, nil
+		}
+	}
+	return 0, errCounterTooLarge
+}
+
+// PublicInputs returns the public inputs of the circuit for the key, alpha and beta as elements of
+// the native field, in the order of the public witness of gnark, e.g. for the calldata of a Solidity
+// verifier: the limbs of the coordinates of the key, then the octets of alpha and of beta.
+func PublicInputs[B emulated.FieldParams](pk *ecdsa.PublicKey, alpha, beta []byte, field *big.Int) []*big.Int {
+	inputs := append(Limbs[B](pk.X, field), Limbs[B](pk.Y, field)...)
+	for _, octets := range [][]byte{alpha, beta} {
+		for _, o := range octets {
+			inputs = append(inputs, big.NewInt(int64(o)))
+		}
+	}
+	return inputs
+}
+
+// Limbs splits x into the limbs of the emulated field F over the native field, least significant first.
+func Limbs[F emulated.FieldParams](x, field *big.Int) []*big.Int {
+	nbLimbs, nbBits := emulated.GetEffectiveFieldParams[F](field)
+	var (
+		limbs = make([]*big.Int, nbLimbs)
+		mask  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), nbBits), big.NewInt(1))
+		rest  = new(big.Int).Set(x)
+	)
+	for i := range limbs {
+		limbs[i] = new(big.Int).And(rest, mask)
+		rest.Rsh(rest, nbBits)
+	}
+	return limbs
+}
+
+// candidate returns the x of the candidate of the counter:
+// Hash(suite_string || 0x01 || PK_string || alpha_string || ctr_string || [0x00]).
+func candidate(cfg Config, pk *ecdsa.PublicKey, alpha []byte, ctr int) *big.Int {
+	h := sha256.New()
+	h.Write([]byte{cfg.SuiteString, 0x01, byte(2 + pk.Y.Bit(0))})
+	h.Write(pk.X.FillBytes(make([]byte, pointSize-1)))
+	h.Write(alpha)
+	h.Write([]byte{byte(ctr)})
+	if cfg.Spec == ecvrf.RFC9381 {
+		h.Write([]byte{0})
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// checkCurve checks that B is the base field of the curve of the suite.
+func checkCurve[B emulated.FieldParams](cfg Config) error {
+	p := suiteModulus(cfg.SuiteString)
+	if p == nil {
+		return errUnsupportedSuite
+	}
+	var b B
+	if b.Modulus().Cmp(p) != 0 {
+		return errCurveMismatch
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package vrfgnark verifies the proofs of the TAI suites of ecvrf in gnark circuits, e.g. for rollups
+// checking a VRF-based leader election inside a SNARK, with the same Go code that made the proof.
+// The gadget checks in circuit the whole verification of the specification: try_and_increment,
+// including that no earlier counter gives a point, the challenge, and beta. The curves are emulated,
+// so the circuits run over any native field, e.g. BN254.
+//
+// Only NewSecp256k1Sha256Tai and NewP256Sha256Tai are supported, with either spec version and
+// otherwise their default options.
+package vrfgnark
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/vechain/go-ecvrf"
+)
+
+// DefaultMaxCounter is the number of counters of try_and_increment checked by the circuits, if
+// Config.MaxCounter is 0. Each counter fails with probability 1/2, so 1 input in 65536 can't be
+// proven in circuit; each counter costs a SHA-256 of the input.
+const DefaultMaxCounter = 16
+
+// BetaSize is the size of the outputs of the supported suites.
+const BetaSize = 32
+
+const (
+	cSize     = 16
+	pointSize = 33
+)
+
+var (
+	errUnsupportedSuite = errors.New("vrfgnark: unsupported suite")
+	errCurveMismatch    = errors.New("vrfgnark: suite of another curve")
+	errCounterTooLarge  = errors.New("vrfgnark: counter of try_and_increment above MaxCounter")
+)
+
+func init() {
+	solver.RegisterHint(tryAndIncrementHint)
+}
+
+// Config fixes the suite and the bound of the counter of a circuit, at compile time.
+type Config struct {
+	SuiteString byte
+	Spec        ecvrf.SpecVersion
+	// MaxCounter is the number of counters of try_and_increment checked, DefaultMaxCounter if 0.
+	MaxCounter int
+}
+
+// NewConfig returns the configuration of the suite of v, which must be NewSecp256k1Sha256Tai or
+// NewP256Sha256Tai.
+func NewConfig(v ecvrf.VRF) (Config, error) {
+	p := v.Params()
+	if p.Hash != "SHA-256" || p.BetaSize != BetaSize || p.ProofSize != pointSize+cSize+32 || suiteModulus(p.SuiteString) == nil {
+		return Config{}, errUnsupportedSuite
+	}
+	return Config{SuiteString: p.SuiteString, Spec: p.Spec}, nil
+}
+
+func (cfg *Config) maxCounter() int {
+	if cfg.MaxCounter > 0 {
+		return cfg.MaxCounter
+	}
+	return DefaultMaxCounter
+}
+
+func (cfg *Config) domainBack() []uints.U8 {
+	if cfg.Spec == ecvrf.RFC9381 {
+		return []uints.U8{uints.NewU8(0)}
+	}
+	return nil
+}
+
+// suiteModulus returns the modulus of the base field of the curve of the suite string, nil if
+// unsupported.
+func suiteModulus(suite byte) *big.Int {
+	switch suite {
+	case 0x01:
+		return elliptic.P256().Params().P
+	case 0xfe:
+		return secp256k1P
+	}
+	return nil
+}
+
+var secp256k1P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+
+// Proof is the private witness of a proof: Gamma, c and s.
+type Proof[B, S emulated.FieldParams] struct {
+	Gamma sw_emulated.AffinePoint[B]
+	C, S  emulated.Element[S]
+}
+
+// Verify asserts that proof is a valid proof of alpha under pk, and returns beta. B and S are the
+// emulated base and scalar fields of the curve of the suite, e.g. emulated.Secp256k1Fp and
+// emulated.Secp256k1Fr. alpha has a fixed length, the one of the circuit.
+func Verify[B, S emulated.FieldParams](api frontend.API, cfg Config, pk *sw_emulated.AffinePoint[B], alpha []uints.U8, proof *Proof[B, S]) ([]uints.U8, error) {
+	if err := checkCurve[B](cfg); err != nil {
+		return nil, err
+	}
+	g := &gadget[B, S]{api: api, cfg: cfg}
+	if err := g.init(); err != nil {
+		return nil, err
+	}
+
+	// the key and Gamma are valid points, the curves having a cofactor of 1
+	g.curve.AssertIsOnCurve(pk)
+	g.curve.AssertIsOnCurve(&proof.Gamma)
+	pkBytes := g.marshal(pk)
+
+	H, err := g.hashToCurve(pkBytes, alpha)
+	if err != nil {
+		return nil, err
+	}
+
+	// U = s*B - c*Y, V = s*H - c*Gamma
+	U := g.curve.JointScalarMulBase(g.curve.Neg(pk), &proof.C, &proof.S)
+	V, err := g.curve.MultiScalarMul(
+		[]*sw_emulated.AffinePoint[B]{H, g.curve.Neg(&proof.Gamma)},
+		[]*emulated.Element[S]{&proof.S, &proof.C})
+	if err != nil {
+		return nil, err
+	}
+
+	// c = Hash(suite_string || 0x02 || [PK_string] || H || Gamma || U || V || [0x00])[:16]
+	h, err := sha2.New(api)
+	if err != nil {
+		return nil, err
+	}
+	gammaBytes := g.marshal(&proof.Gamma)
+	h.Write([]uints.U8{uints.NewU8(cfg.SuiteString), uints.NewU8(0x02)})
+	if cfg.Spec == ecvrf.RFC9381 {
+		h.Write(pkBytes)
+	}
+	h.Write(g.marshal(H))
+	h.Write(gammaBytes)
+	h.Write(g.marshal(U))
+	h.Write(g.marshal(V))
+	h.Write(cfg.domainBack())
+	g.scalars.AssertIsEqual(g.scalars.FromBits(g.bitsBE(h.Sum()[:cSize])...), &proof.C)
+
+	// beta = Hash(suite_string || 0x03 || Gamma || [0x00])
+	h, err = sha2.New(api)
+	if err != nil {
+		return nil, err
+	}
+	h.Write([]uints.U8{uints.NewU8(cfg.SuiteString), uints.NewU8(0x03)})
+	h.Write(gammaBytes)
+	h.Write(cfg.domainBack())
+	return h.Sum(), nil
+}
+
+type gadget[B, S emulated.FieldParams] struct {
+	api     frontend.API
+	cfg     Config
+	curve   *sw_emulated.Curve[B, S]
+	base    *emulated.Field[B]
+	scalars *emulated.Field[S]
+	bytes   *uints.Bytes
+}
+
+func (g *gadget[B, S]) init() (err error) {
+	if g.curve, err = sw_emulated.New[B, S](g.api, sw_emulated.GetCurveParams[B]()); err != nil {
+		return
+	}
+	if g.base, err = emulated.NewField[B](g.api); err != nil {
+		return
+	}
+	if g.scalars, err = emulated.NewField[S](g.api); err != nil {
+		return
+	}
+	g.bytes, err = uints.NewBytes(g.api)
+	return
+}
+
+// hashToCurve is try_and_increment over the first MaxCounter counters: the hint finds the counter,
+// and the circuit checks that the candidates of the lower counters aren't on the curve, by a square
+// root of -(x^3 + a*x + b), as -1 isn't a square for the supported curves, p being 3 mod 4.
+// Candidates of at least p, of probability below 2^-223, aren't told apart from their value mod p.
+func (g *gadget[B, S]) hashToCurve(pkBytes, alpha []uints.U8) (*sw_emulated.AffinePoint[B], error) {
+	n := g.cfg.maxCounter()
+	params := sw_emulated.GetCurveParams[B]()
+	var (
+		xs  = make([]*emulated.Element[B], n)
+		rhs = make([]*emulated.Element[B], n)
+		a   = g.base.NewElement(params.A)
+		b   = g.base.NewElement(params.B)
+	)
+	for ctr := 0; ctr < n; ctr++ {
+		// hash_string = Hash(suite_string || 0x01 || PK_string || alpha_string || ctr_string || [0x00])
+		h, err := sha2.New(g.api)
+		if err != nil {
+			return nil, err
+		}
+		h.Write([]uints.U8{uints.NewU8(g.cfg.SuiteString), uints.NewU8(0x01)})
+		h.Write(pkBytes)
+		h.Write(alpha)
+		h.Write([]uints.U8{uints.NewU8(uint8(ctr))})
+		h.Write(g.cfg.domainBack())
+		xs[ctr] = g.base.FromBits(g.bitsBE(h.Sum())...)
+		x2 := g.base.Mul(xs[ctr], xs[ctr])
+		rhs[ctr] = g.base.Add(g.base.Mul(g.base.Add(x2, a), xs[ctr]), b)
+	}
+
+	nativeOut, out, err := g.base.NewHintGeneric(tryAndIncrementHint, 1, n+1, nil, rhs)
+	if err != nil {
+		return nil, err
+	}
+	counter, y, roots := nativeOut[0], out[0], out[1:]
+	g.api.AssertIsLessOrEqual(counter, n-1)
+
+	var reached frontend.Variable = 0
+	for i := 0; i < n; i++ {
+		reached = g.api.Add(reached, g.api.IsZero(g.api.Sub(counter, i)))
+		// -rhs is a square below the counter, rhs isn't
+		negRhs := g.base.Neg(rhs[i])
+		g.base.AssertIsEqual(g.base.Select(reached, negRhs, g.base.Mul(roots[i], roots[i])), negRhs)
+	}
+	// arbitrary_string_to_point(0x02 || hash_string): y is even
+	H := &sw_emulated.AffinePoint[B]{X: *g.base.Mux(counter, xs...), Y: *y}
+	g.curve.AssertIsOnCurve(H)
+	g.api.AssertIsEqual(g.base.ToBitsCanonical(y)[0], 0)
+	return H, nil
+}
+
+// marshal encodes pt compressed.
+func (g *gadget[B, S]) marshal(pt *sw_emulated.AffinePoint[B]) []uints.U8 {
+	xBits := g.base.ToBitsCanonical(&pt.X)
+	yBits := g.base.ToBitsCanonical(&pt.Y)
+	out := make([]uints.U8, pointSize)
+	out[0] = g.bytes.ValueOf(g.api.Add(2, yBits[0]))
+	for i := 1; i < pointSize; i++ {
+		lo := 8 * (pointSize - 1 - i)
+		out[i] = g.bytes.ValueOf(g.api.FromBinary(xBits[lo : lo+8]...))
+	}
+	return out
+}
+
+// bitsBE returns the bits of the big-endian integer of the octets, least significant first.
+func (g *gadget[B, S]) bitsBE(octets []uints.U8) []frontend.Variable {
+	bits := make([]frontend.Variable, 0, 8*len(octets))
+	for i := len(octets) - 1; i >= 0; i-- {
+		bits = append(bits, g.api.ToBinary(g.bytes.Value(octets[i]), 8)...)
+	}
+	return bits
+}
+
+// tryAndIncrementHint returns the first counter whose rhs is a square, the even square root y of
+// its rhs, and the square roots of -rhs of the counters below, 0 for the others.
+func tryAndIncrementHint(nativeMod *big.Int, inputs, outputs []*big.Int) error {
+	return emulated.UnwrapHintContext(nativeMod, inputs, outputs, func(hc emulated.HintContext) error {
+		moduli := hc.EmulatedModuli()
+		if len(moduli) != 1 {
+			return fmt.Errorf("try_and_increment hint: expected 1 emulated modulus, got %d", len(moduli))
+		}
+		p := moduli[0]
+		_, nativeOut := hc.NativeInputsOutputs()
+		rhs, out := hc.InputsOutputs(p)
+		if len(nativeOut) != 1 || len(out) != len(rhs)+1 {
+			return errors.New("try_and_increment hint: unexpected number of outputs")
+		}
+		for i, r := range rhs {
+			if y := new(big.Int).ModSqrt(r, p); y != nil {
+				if y.Bit(0) == 1 {
+					y.Sub(p, y)
+				}
+				nativeOut[0].SetInt64(int64(i))
+				out[0].Set(y)
+				return nil
+			}
+			neg := new(big.Int).Neg(r)
+			out[1+i].ModSqrt(neg.Mod(neg, p), p)
+		}
+		return errCounterTooLarge
+	})
+}
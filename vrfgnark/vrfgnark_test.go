@@ -0,0 +1,132 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package vrfgnark
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+func TestCircuitSecp256k1(t *testing.T) {
+	for _, spec := range []ecvrf.SpecVersion{ecvrf.Draft06, ecvrf.RFC9381} {
+		vrf := ecvrf.NewSecp256k1Sha256Tai(ecvrf.WithSpecVersion(spec))
+		cfg, err := NewConfig(vrf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+		alpha := []byte("slot 42")
+		beta, pi, err := vrf.Prove(sk, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		circuit := NewCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr](cfg, len(alpha))
+		assignment, err := Assign[emulated.Secp256k1Fp, emulated.Secp256k1Fr](vrf, cfg, &sk.PublicKey, alpha, pi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()); err != nil {
+			t.Fatalf("spec %v: IsSolved() error = %v", spec, err)
+		}
+
+		// another beta
+		bad, _ := Assign[emulated.Secp256k1Fp, emulated.Secp256k1Fr](vrf, cfg, &sk.PublicKey, alpha, pi)
+		bad.Beta[0].Val = uint8(beta[0] ^ 1)
+		if err := test.IsSolved(circuit, bad, ecc.BN254.ScalarField()); err == nil {
+			t.Errorf("spec %v: IsSolved() accepted another beta", spec)
+		}
+		// another alpha
+		bad, _ = Assign[emulated.Secp256k1Fp, emulated.Secp256k1Fr](vrf, cfg, &sk.PublicKey, alpha, pi)
+		bad.Alpha[0].Val = alpha[0] ^ 1
+		if err := test.IsSolved(circuit, bad, ecc.BN254.ScalarField()); err == nil {
+			t.Errorf("spec %v: IsSolved() accepted another alpha", spec)
+		}
+
+		// the public inputs are the ones of the public witness of gnark
+		w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+		if err != nil {
+			t.Fatal(err)
+		}
+		vec := w.Vector().(fr.Vector)
+		inputs := PublicInputs[emulated.Secp256k1Fp](&sk.PublicKey, alpha, beta, ecc.BN254.ScalarField())
+		if len(inputs) != len(vec) {
+			t.Fatalf("len(PublicInputs()) = %v, want %v", len(inputs), len(vec))
+		}
+		for i := range vec {
+			var e fr.Element
+			e.SetBigInt(inputs[i])
+			if !e.Equal(&vec[i]) {
+				t.Fatalf("PublicInputs()[%v] differs from the public witness", i)
+			}
+		}
+	}
+}
+
+func TestCircuitP256(t *testing.T) {
+	vrf := ecvrf.NewP256Sha256Tai()
+	cfg, err := NewConfig(vrf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	alpha := []byte("Hello VeChain")
+	_, pi, err := vrf.Prove(sk, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	circuit := NewCircuit[emulated.P256Fp, emulated.P256Fr](cfg, len(alpha))
+	assignment, err := Assign[emulated.P256Fp, emulated.P256Fr](vrf, cfg, &sk.PublicKey, alpha, pi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()); err != nil {
+		t.Fatalf("IsSolved() error = %v", err)
+	}
+	if _, err := Assign[emulated.Secp256k1Fp, emulated.Secp256k1Fr](vrf, cfg, &sk.PublicKey, alpha, pi); err == nil {
+		t.Errorf("Assign() accepted the fields of another curve")
+	}
+}
+
+func TestCounter(t *testing.T) {
+	// the counter of the hint is the one of EncodeToCurve, and Assign refuses those above MaxCounter
+	vrf := ecvrf.NewSecp256k1Sha256Tai()
+	cfg, _ := NewConfig(vrf)
+	sk, _ := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	for i := 0; i < 64; i++ {
+		alpha := []byte{byte(i)}
+		ctr, err := counter(vrf, cfg, &sk.PublicKey, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ctr == 0 {
+			continue
+		}
+		_, pi, _ := vrf.Prove(sk, alpha)
+		cfg.MaxCounter = ctr
+		if _, err := Assign[emulated.Secp256k1Fp, emulated.Secp256k1Fr](vrf, cfg, &sk.PublicKey, alpha, pi); err != errCounterTooLarge {
+			t.Fatalf("Assign() error = %v, want %v", err, errCounterTooLarge)
+		}
+		cfg.MaxCounter = ctr + 1
+		circuit := NewCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr](cfg, len(alpha))
+		assignment, err := Assign[emulated.Secp256k1Fp, emulated.Secp256k1Fr](vrf, cfg, &sk.PublicKey, alpha, pi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()); err != nil {
+			t.Fatalf("IsSolved() error = %v for the counter %v", err, ctr)
+		}
+		return
+	}
+	t.Fatal("no input of a counter above 0")
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Package vrfhttp is a net/http handler proving and verifying VRF outputs with JSON bodies,
+// for clients in other languages, e.g. behind a sidecar. Octet strings are hex encoded.
+//
+//	POST /prove  {"key_id": "k", "alpha": "..."}              -> {"beta": "...", "pi": "..."}
+//	POST /verify {"key_id": "k", "alpha": "...", "pi": "..."} -> {"valid": true, "beta": "..."}
+//
+// Invalid proofs are answered with 200 and "valid": false. Other failures have a 4xx status
+// and the body {"error": "..."}. The handler doesn't authenticate clients: it's meant to be
+// reachable only from the local host or network, or behind an authenticating proxy.
+package vrfhttp
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/vechain/go-ecvrf"
+)
+
+// Limits of the handler, if not changed by the options.
+const (
+	DefaultMaxBodyBytes  = 64 << 10
+	DefaultMaxAlphaBytes = 16 << 10
+)
+
+var (
+	errUnknownKey   = errors.New("unknown key")
+	errAlphaTooLong = errors.New("alpha too long")
+	errBadJSON      = errors.New("invalid JSON body")
+	errTooLarge     = errors.New("body too large")
+	errContentType  = errors.New("content type must be application/json")
+	errMethod       = errors.New("method not allowed")
+)
+
+// Option modifies the handler.
+type Option func(*handler)
+
+// WithMaxBodyBytes sets the size of request bodies above which they're refused with 413.
+func WithMaxBodyBytes(n int64) Option {
+	return func(h *handler) {
+		h.maxBody = n
+	}
+}
+
+// WithMaxAlphaBytes sets the length of decoded inputs above which they're refused with 400.
+func WithMaxAlphaBytes(n int) Option {
+	return func(h *handler) {
+		h.maxAlpha = n
+	}
+}
+
+type handler struct {
+	vrf      ecvrf.VRF
+	keys     map[string]*ecdsa.PrivateKey
+	maxBody  int64
+	maxAlpha int
+	mux      *http.ServeMux
+}
+
+// NewHandler returns the handler of /prove and /verify, proving with v under the keys and verifying
+// against their public keys, by their ids. The map must not be modified afterwards.
+func NewHandler(v ecvrf.VRF, keys map[string]*ecdsa.PrivateKey, opts ...Option) http.Handler {
+	h := &handler{
+		vrf:      v,
+		keys:     keys,
+		maxBody:  DefaultMaxBodyBytes,
+		maxAlpha: DefaultMaxAlphaBytes,
+		mux:      http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.mux.HandleFunc("/prove", h.post(h.prove))
+	h.mux.HandleFunc("/verify", h.post(h.verify))
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type proveRequest struct {
+	KeyID string   `json:"key_id"`
+	Alpha hexBytes `json:"alpha"`
+}
+
+type proveResponse struct {
+	Beta hexBytes `json:"beta"`
+	Pi   hexBytes `json:"pi"`
+}
+
+type verifyRequest struct {
+	KeyID string   `json:"key_id"`
+	Alpha hexBytes `json:"alpha"`
+	Pi    hexBytes `json:"pi"`
+}
+
+type verifyResponse struct {
+	Valid bool     `json:"valid"`
+	Beta  hexBytes `json:"beta,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (h *handler) prove(r *http.Request) (int, interface{}) {
+	var req proveRequest
+	if status, err := h.decode(r, &req); err != nil {
+		return status, err
+	}
+	sk, ok := h.keys[req.KeyID]
+	if !ok {
+		return http.StatusNotFound, errUnknownKey
+	}
+	beta, pi, err := h.vrf.Prove(sk, req.Alpha)
+	if err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, &proveResponse{beta, pi}
+}
+
+func (h *handler) verify(r *http.Request) (int, interface{}) {
+	var req verifyRequest
+	if status, err := h.decode(r, &req); err != nil {
+		return status, err
+	}
+	sk, ok := h.keys[req.KeyID]
+	if !ok {
+		return http.StatusNotFound, errUnknownKey
+	}
+	beta, err := h.vrf.Verify(&sk.PublicKey, req.Alpha, req.Pi)
+	if err != nil {
+		return http.StatusOK, &verifyResponse{Error: err.Error()}
+	}
+	return http.StatusOK, &verifyResponse{Valid: true, Beta: beta}
+}
+
+// post wraps the method f of the API: only POST of JSON bodies is accepted, and errors returned by f
+// are written as error responses.
+func (h *handler) post(f func(*http.Request) (int, interface{})) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			status int
+			resp   interface{}
+		)
+		switch ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); {
+		case r.Method != http.MethodPost:
+			w.Header().Set("Allow", http.MethodPost)
+			status, resp = http.StatusMethodNotAllowed, errMethod
+		case ct != "application/json":
+			status, resp = http.StatusUnsupportedMediaType, errContentType
+		default:
+			r.Body = &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, h.maxBody), limit: h.maxBody}
+			status, resp = f(r)
+		}
+		if err, ok := resp.(error); ok {
+			resp = &errorResponse{err.Error()}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// limitedBody is a body limited by http.MaxBytesReader, counting the octets read to tell when the
+// limit is hit, without http.MaxBytesError of Go 1.19.
+type limitedBody struct {
+	io.ReadCloser
+	n, limit int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// exceeded reports whether reads stopped at the limit.
+func (b *limitedBody) exceeded() bool {
+	return b.n >= b.limit
+}
+
+// decode reads the single JSON object of the body into v, rejecting unknown fields.
+func (h *handler) decode(r *http.Request, v interface{}) (int, error) {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if body, ok := r.Body.(*limitedBody); ok && body.exceeded() {
+			return http.StatusRequestEntityTooLarge, errTooLarge
+		}
+		return http.StatusBadRequest, errBadJSON
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return http.StatusBadRequest, errBadJSON
+	}
+	var alpha []byte
+	switch req := v.(type) {
+	case *proveRequest:
+		alpha = req.Alpha
+	case *verifyRequest:
+		alpha = req.Alpha
+	}
+	if len(alpha) > h.maxAlpha {
+		return http.StatusBadRequest, errAlphaTooLong
+	}
+	return 0, nil
+}
+
+// hexBytes is an octet string encoded as a hex JSON string.
+type hexBytes []byte
+
+func (b hexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(b))
+}
+
+func (b *hexBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Loads the VRF verifier of ecvrf.wasm, after wasm_exec.js of the Go distribution
+// ($(go env GOROOT)/lib/wasm/wasm_exec.js), which defines Go:
+//
+//   const vrf = await load("ecvrf.wasm");
+//   const beta = vrf.verify("secp256k1", pk, alpha, pi); // Uint8Array, or null
+//
+// The functions throw on unknown suites and arguments of other types.
+export async function load(url = "ecvrf.wasm") {
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch(url), go.importObject);
+  go.run(instance);
+  const api = globalThis.ecvrf;
+  const unwrap = (r) => {
+    if (r instanceof Error) {
+      throw r;
+    }
+    return r;
+  };
+  return {
+    suites: api.suites,
+    verify: (suite, pk, alpha, pi) => unwrap(api.verify(suite, pk, alpha, pi)),
+    proofToHash: (suite, pi) => unwrap(api.proofToHash(suite, pi)),
+  };
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build js && wasm
+
+package main
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+var errArgument = errors.New("ecvrf: expected a suite name and Uint8Arrays")
+
+func main() {
+	suites := make([]interface{}, len(suiteNames))
+	for i, name := range suiteNames {
+		suites[i] = name
+	}
+	js.Global().Set("ecvrf", js.ValueOf(map[string]interface{}{
+		"suites": suites,
+		"verify": js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			b, err := argBytes(args, 4)
+			if err != nil {
+				return jsError(err)
+			}
+			return result(verify(args[0].String(), b[1], b[2], b[3]))
+		}),
+		"proofToHash": js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			b, err := argBytes(args, 2)
+			if err != nil {
+				return jsError(err)
+			}
+			return result(proofToHash(args[0].String(), b[1]))
+		}),
+	}))
+	select {}
+}
+
+// argBytes copies the n-1 arguments following the suite name out of JavaScript.
+func argBytes(args []js.Value, n int) ([][]byte, error) {
+	if len(args) != n || args[0].Type() != js.TypeString {
+		return nil, errArgument
+	}
+	b := make([][]byte, n)
+	for i := 1; i < n; i++ {
+		switch {
+		case args[i].Type() == js.TypeString:
+			b[i] = []byte(args[i].String())
+		case args[i].InstanceOf(js.Global().Get("Uint8Array")):
+			b[i] = make([]byte, args[i].Length())
+			js.CopyBytesToGo(b[i], args[i])
+		default:
+			return nil, errArgument
+		}
+	}
+	return b, nil
+}
+
+func result(beta []byte, err error) interface{} {
+	if err != nil {
+		return jsError(err)
+	}
+	if beta == nil {
+		return js.Null()
+	}
+	arr := js.Global().Get("Uint8Array").New(len(beta))
+	js.CopyBytesToJS(arr, beta)
+	return arr
+}
+
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "the JavaScript bindings are built with GOOS=js GOARCH=wasm")
+	os.Exit(2)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+// Command wasm exposes the verification of VRF proofs to JavaScript, so browsers can check the
+// outputs of a lottery or a beacon locally. Built by
+//
+//	GOOS=js GOARCH=wasm go build -o ecvrf.wasm
+//
+// it sets the global ecvrf, which ecvrf.js wraps:
+//
+//	ecvrf.suites                          // ["p256", "secp256k1", "ed25519", "ed25519-draft03"]
+//	ecvrf.verify(suite, pk, alpha, pi)    // beta, or null if the proof is invalid
+//	ecvrf.proofToHash(suite, pi)          // beta, or null if the proof is malformed
+//
+// Keys, proofs and outputs are Uint8Arrays; alpha is a Uint8Array or a string, encoded in UTF-8.
+// Unknown suites and arguments of other types return an Error.
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/vechain/go-ecvrf"
+)
+
+var errUnknownSuite = errors.New("ecvrf: unknown suite")
+
+// suiteNames lists the suites of the bindings, as named by the ecvrf command.
+var suiteNames = []string{"p256", "secp256k1", "ed25519", "ed25519-draft03"}
+
+// verify returns the output of the proof pi of alpha by the public key pk of the suite, a compressed
+// or uncompressed point, or an Ed25519 public key. It returns nil if the key or the proof is invalid,
+// and only fails for an unknown suite.
+func verify(suite string, pk, alpha, pi []byte) ([]byte, error) {
+	switch suite {
+	case "p256", "secp256k1":
+		c, v := weierstrass(suite)
+		key, err := ecvrf.DecodePublicKey(v, c, pk)
+		if err != nil {
+			return nil, nil
+		}
+		beta, _ := v.Verify(key, alpha, pi)
+		return beta, nil
+	case "ed25519", "ed25519-draft03":
+		if len(pk) != ed25519.PublicKeySize {
+			return nil, nil
+		}
+		beta, _ := edwards(suite).Verify(ed25519.PublicKey(pk), alpha, pi)
+		return beta, nil
+	}
+	return nil, errUnknownSuite
+}
+
+// proofToHash returns the output of the proof pi of the suite without verifying it, or nil if it's
+// malformed, e.g. to index the proofs before verifying them.
+func proofToHash(suite string, pi []byte) ([]byte, error) {
+	switch suite {
+	case "p256", "secp256k1":
+		c, v := weierstrass(suite)
+		beta, _ := ecvrf.ProofToHash(v, c, pi)
+		return beta, nil
+	case "ed25519", "ed25519-draft03":
+		beta, _ := edwards(suite).ProofToHash(pi)
+		return beta, nil
+	}
+	return nil, errUnknownSuite
+}
+
+func weierstrass(suite string) (elliptic.Curve, ecvrf.VRF) {
+	if suite == "p256" {
+		return elliptic.P256(), ecvrf.NewP256Sha256Tai()
+	}
+	return secp256k1.S256(), ecvrf.NewSecp256k1Sha256Tai()
+}
+
+func edwards(suite string) ecvrf.Ed25519VRF {
+	if suite == "ed25519" {
+		return ecvrf.NewEd25519Sha512Ell2()
+	}
+	return ecvrf.NewEd25519Sha512Elligator2()
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestVerify(t *testing.T) {
+	alpha := []byte("round 42")
+	type proof struct{ pk, beta, pi []byte }
+	proofs := map[string]proof{}
+	for _, suite := range []string{"p256", "secp256k1"} {
+		c, v := weierstrass(suite)
+		sk, _ := ecdsa.GenerateKey(c, rand.Reader)
+		if suite == "secp256k1" {
+			k, _ := secp256k1.GeneratePrivateKey()
+			sk = k.ToECDSA()
+		}
+		beta, pi, err := v.Prove(sk, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proofs[suite] = proof{elliptic.Marshal(c, sk.X, sk.Y), beta, pi}
+	}
+	for _, suite := range []string{"ed25519", "ed25519-draft03"} {
+		pk, sk, _ := ed25519.GenerateKey(rand.Reader)
+		beta, pi, err := edwards(suite).Prove(sk, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proofs[suite] = proof{pk, beta, pi}
+	}
+
+	for _, suite := range suiteNames {
+		p := proofs[suite]
+		if beta, err := verify(suite, p.pk, alpha, p.pi); err != nil || !bytes.Equal(beta, p.beta) {
+			t.Fatal(suite, "valid proof rejected", err)
+		}
+		if beta, err := proofToHash(suite, p.pi); err != nil || !bytes.Equal(beta, p.beta) {
+			t.Fatal(suite, "wrong output", err)
+		}
+		if beta, err := verify(suite, p.pk, []byte("round 43"), p.pi); err != nil || beta != nil {
+			t.Fatal(suite, "invalid proof accepted", err)
+		}
+		if beta, err := verify(suite, p.pk[1:], alpha, p.pi); err != nil || beta != nil {
+			t.Fatal(suite, "malformed key accepted", err)
+		}
+	}
+	if _, err := verify("p384", nil, alpha, nil); err != errUnknownSuite {
+		t.Fatal("unknown suite accepted")
+	}
+}
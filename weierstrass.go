@@ -0,0 +1,762 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// projectivePoint is a point in homogeneous projective coordinates (X : Y : Z),
+// representing the affine point (X/Z, Y/Z). The point at infinity is (0 : 1 : 0).
+type projectivePoint struct {
+	x, y, z fieldElement
+}
+
+// affinePoint is a point in affine coordinates, which can't be the point at infinity.
+type affinePoint struct {
+	x, y fieldElement
+}
+
+// weierstrass implements the group law of a short Weierstrass curve y² = x³ + ax + b of prime order,
+// using the complete addition formulas from [Renes-Costello-Batina 2015](https://eprint.iacr.org/2015/1060).
+type weierstrass struct {
+	fp       *field
+	fq       *field       // the scalar field
+	a, b, b3 fieldElement // a, b and 3*b in Montgomery form
+	aKind    int          // aZero, aMinus3 or aOther
+	g        affinePoint  // the base point
+	sqrtExp  *big.Int     // (p+1)/4 if p = 3 mod 4, otherwise nil
+	glv      *glv         // the endomorphism, nil if not available
+
+	baseOnce  sync.Once
+	baseTable [][32]affinePoint // the fixed table of G
+}
+
+// baseWindows is the number of signed 6-bit windows of 256-bit scalars.
+const baseWindows = 43
+
+const (
+	aOther = iota
+	aZero
+	aMinus3
+)
+
+var weierstrassCache struct {
+	sync.Mutex
+	m map[*elliptic.CurveParams]*weierstrass
+}
+
+// curveArith returns the fast arithmetic for the curve, or nil if the curve is not supported or
+// its equation is not the one given by the y2 function. The arithmetic is shared by the VRF objects
+// of a curve, and built from the equation of the first y2 function to support it. Callers should
+// cache the result, as the equation is derived from y2 on each call.
+func curveArith(c elliptic.Curve, y2 func(c elliptic.Curve, x *big.Int) *big.Int) *weierstrass {
+	// the standard library has its own optimized implementations of NIST curves
+	if isNISTCurve(c) {
+		return nil
+	}
+	params := c.Params()
+
+	weierstrassCache.Lock()
+	w, ok := weierstrassCache.m[params]
+	if !ok {
+		if w = newWeierstrass(c, y2); w != nil {
+			if weierstrassCache.m == nil {
+				weierstrassCache.m = make(map[*elliptic.CurveParams]*weierstrass)
+			}
+			weierstrassCache.m[params] = w
+		}
+	}
+	weierstrassCache.Unlock()
+
+	// a y2 function of another curve must not use the arithmetic of this one
+	if w == nil || !ok {
+		return w
+	}
+	a, b := curveEquation(c, y2)
+	if w.fp.toBig(&w.a).Cmp(a) != 0 || w.fp.toBig(&w.b).Cmp(b) != 0 {
+		return nil
+	}
+	return w
+}
+
+// curveEquation returns the coefficients a and b of the equation y² = x³ + ax + b given by y2:
+// b = y2(0), a = y2(1) - 1 - b.
+func curveEquation(c elliptic.Curve, y2 func(c elliptic.Curve, x *big.Int) *big.Int) (a, b *big.Int) {
+	p := c.Params().P
+	b = new(big.Int).Mod(y2(c, new(big.Int)), p)
+	a = new(big.Int).Sub(y2(c, big.NewInt(1)), big.NewInt(1))
+	a.Sub(a, b).Mod(a, p)
+	return a, b
+}
+
+// newWeierstrass creates the arithmetic for curve c, whose equation is given by y2.
+// nil is returned if the curve is not supported.
+func newWeierstrass(c elliptic.Curve, y2 func(c elliptic.Curve, x *big.Int) *big.Int) *weierstrass {
+	params := c.Params()
+	fp := newField(params.P)
+	if fp == nil || params.N == nil {
+		return nil
+	}
+	fq := scalarField(params)
+	if fq == nil {
+		return nil
+	}
+	a, b := curveEquation(c, y2)
+
+	// the base point must satisfy the derived equation
+	gy2 := new(big.Int).Mul(params.Gy, params.Gy)
+	if gy2.Mod(gy2, params.P).Cmp(new(big.Int).Mod(y2(c, params.Gx), params.P)) != 0 {
+		return nil
+	}
+
+	w := &weierstrass{fp: fp, fq: fq}
+	switch {
+	case a.Sign() == 0:
+		w.aKind = aZero
+	case new(big.Int).Add(a, big.NewInt(3)).Cmp(params.P) == 0:
+		w.aKind = aMinus3
+	}
+	if params.P.Bit(0) == 1 && params.P.Bit(1) == 1 {
+		w.sqrtExp = new(big.Int).Add(params.P, big.NewInt(1))
+		w.sqrtExp.Rsh(w.sqrtExp, 2)
+	}
+	if w.aKind == aZero {
+		w.glv = secp256k1GLV(fp, fq, params.P, params.N)
+	}
+	fp.fromBig(&w.a, a)
+	fp.fromBig(&w.b, b)
+	fp.fromBig(&w.b3, b.Mul(b, big.NewInt(3)))
+	fp.fromBig(&w.g.x, params.Gx)
+	fp.fromBig(&w.g.y, params.Gy)
+	return w
+}
+
+// mulA sets z = a * x, avoiding the full multiplication for the common values of a.
+func (w *weierstrass) mulA(z, x *fieldElement) {
+	switch w.aKind {
+	case aZero:
+		*z = fieldElement{}
+	case aMinus3:
+		var t fieldElement
+		w.fp.add(&t, x, x)
+		w.fp.add(&t, &t, x)
+		w.fp.neg(z, &t)
+	default:
+		w.fp.mul(z, &w.a, x)
+	}
+}
+
+// identity sets p to the point at infinity.
+func (w *weierstrass) identity(p *projectivePoint) {
+	p.x = fieldElement{}
+	p.y = w.fp.one
+	p.z = fieldElement{}
+}
+
+// fromAffine sets p to the affine point q.
+func (w *weierstrass) fromAffine(p *projectivePoint, q *affinePoint) {
+	p.x = q.x
+	p.y = q.y
+	p.z = w.fp.one
+}
+
+// add sets r = p + q, using algorithm 1 of Renes-Costello-Batina.
+func (w *weierstrass) add(r, p, q *projectivePoint) {
+	var (
+		fp                     = w.fp
+		t0, t1, t2, t3, t4, t5 fieldElement
+		x3, y3, z3             fieldElement
+	)
+	fp.mul(&t0, &p.x, &q.x)
+	fp.mul(&t1, &p.y, &q.y)
+	fp.mul(&t2, &p.z, &q.z)
+	fp.add(&t3, &p.x, &p.y)
+	fp.add(&t4, &q.x, &q.y)
+	fp.mul(&t3, &t3, &t4)
+	fp.add(&t4, &t0, &t1)
+	fp.sub(&t3, &t3, &t4)
+	fp.add(&t4, &p.x, &p.z)
+	fp.add(&t5, &q.x, &q.z)
+	fp.mul(&t4, &t4, &t5)
+	fp.add(&t5, &t0, &t2)
+	fp.sub(&t4, &t4, &t5)
+	fp.add(&t5, &p.y, &p.z)
+	fp.add(&x3, &q.y, &q.z)
+	fp.mul(&t5, &t5, &x3)
+	fp.add(&x3, &t1, &t2)
+	fp.sub(&t5, &t5, &x3)
+	w.mulA(&z3, &t4)
+	fp.mul(&x3, &w.b3, &t2)
+	fp.add(&z3, &x3, &z3)
+	fp.sub(&x3, &t1, &z3)
+	fp.add(&z3, &t1, &z3)
+	fp.mul(&y3, &x3, &z3)
+	fp.add(&t1, &t0, &t0)
+	fp.add(&t1, &t1, &t0)
+	w.mulA(&t2, &t2)
+	fp.mul(&t4, &w.b3, &t4)
+	fp.add(&t1, &t1, &t2)
+	fp.sub(&t2, &t0, &t2)
+	w.mulA(&t2, &t2)
+	fp.add(&t4, &t4, &t2)
+	fp.mul(&t0, &t1, &t4)
+	fp.add(&y3, &y3, &t0)
+	fp.mul(&t0, &t5, &t4)
+	fp.mul(&x3, &t3, &x3)
+	fp.sub(&x3, &x3, &t0)
+	fp.mul(&t0, &t3, &t1)
+	fp.mul(&z3, &t5, &z3)
+	fp.add(&z3, &z3, &t0)
+
+	r.x, r.y, r.z = x3, y3, z3
+}
+
+// addAffine sets r = p + q for affine q, using algorithm 2 of Renes-Costello-Batina.
+func (w *weierstrass) addAffine(r, p *projectivePoint, q *affinePoint) {
+	var (
+		fp                     = w.fp
+		t0, t1, t2, t3, t4, t5 fieldElement
+		x3, y3, z3             fieldElement
+	)
+	fp.mul(&t0, &p.x, &q.x)
+	fp.mul(&t1, &p.y, &q.y)
+	fp.add(&t3, &q.x, &q.y)
+	fp.add(&t4, &p.x, &p.y)
+	fp.mul(&t3, &t3, &t4)
+	fp.add(&t4, &t0, &t1)
+	fp.sub(&t3, &t3, &t4)
+	fp.mul(&t4, &q.x, &p.z)
+	fp.add(&t4, &t4, &p.x)
+	fp.mul(&t5, &q.y, &p.z)
+	fp.add(&t5, &t5, &p.y)
+	w.mulA(&z3, &t4)
+	fp.mul(&x3, &w.b3, &p.z)
+	fp.add(&z3, &x3, &z3)
+	fp.sub(&x3, &t1, &z3)
+	fp.add(&z3, &t1, &z3)
+	fp.mul(&y3, &x3, &z3)
+	fp.add(&t1, &t0, &t0)
+	fp.add(&t1, &t1, &t0)
+	w.mulA(&t2, &p.z)
+	fp.mul(&t4, &w.b3, &t4)
+	fp.add(&t1, &t1, &t2)
+	fp.sub(&t2, &t0, &t2)
+	w.mulA(&t2, &t2)
+	fp.add(&t4, &t4, &t2)
+	fp.mul(&t0, &t1, &t4)
+	fp.add(&y3, &y3, &t0)
+	fp.mul(&t0, &t5, &t4)
+	fp.mul(&x3, &t3, &x3)
+	fp.sub(&x3, &x3, &t0)
+	fp.mul(&t0, &t3, &t1)
+	fp.mul(&z3, &t5, &z3)
+	fp.add(&z3, &z3, &t0)
+
+	r.x, r.y, r.z = x3, y3, z3
+}
+
+// double sets r = 2 * p, using algorithm 3 of Renes-Costello-Batina,
+// or algorithm 9 if a = 0.
+func (w *weierstrass) double(r, p *projectivePoint) {
+	if w.aKind == aZero {
+		w.doubleA0(r, p)
+		return
+	}
+	var (
+		fp             = w.fp
+		t0, t1, t2, t3 fieldElement
+		x3, y3, z3     fieldElement
+	)
+	fp.sqr(&t0, &p.x)
+	fp.sqr(&t1, &p.y)
+	fp.sqr(&t2, &p.z)
+	fp.mul(&t3, &p.x, &p.y)
+	fp.add(&t3, &t3, &t3)
+	fp.mul(&z3, &p.x, &p.z)
+	fp.add(&z3, &z3, &z3)
+	w.mulA(&x3, &z3)
+	fp.mul(&y3, &w.b3, &t2)
+	fp.add(&y3, &x3, &y3)
+	fp.sub(&x3, &t1, &y3)
+	fp.add(&y3, &t1, &y3)
+	fp.mul(&y3, &x3, &y3)
+	fp.mul(&x3, &t3, &x3)
+	fp.mul(&z3, &w.b3, &z3)
+	w.mulA(&t2, &t2)
+	fp.sub(&t3, &t0, &t2)
+	w.mulA(&t3, &t3)
+	fp.add(&t3, &t3, &z3)
+	fp.add(&z3, &t0, &t0)
+	fp.add(&t0, &z3, &t0)
+	fp.add(&t0, &t0, &t2)
+	fp.mul(&t0, &t0, &t3)
+	fp.add(&y3, &y3, &t0)
+	fp.mul(&t2, &p.y, &p.z)
+	fp.add(&t2, &t2, &t2)
+	fp.mul(&t0, &t2, &t3)
+	fp.sub(&x3, &x3, &t0)
+	fp.mul(&z3, &t2, &t1)
+	fp.add(&z3, &z3, &z3)
+	fp.add(&z3, &z3, &z3)
+
+	r.x, r.y, r.z = x3, y3, z3
+}
+
+// doubleA0 sets r = 2 * p for curves of a = 0, using algorithm 9 of Renes-Costello-Batina.
+func (w *weierstrass) doubleA0(r, p *projectivePoint) {
+	var (
+		fp         = w.fp
+		t0, t1, t2 fieldElement
+		x3, y3, z3 fieldElement
+	)
+	fp.sqr(&t0, &p.y)
+	fp.add(&z3, &t0, &t0)
+	fp.add(&z3, &z3, &z3)
+	fp.add(&z3, &z3, &z3)
+	fp.mul(&t1, &p.y, &p.z)
+	fp.sqr(&t2, &p.z)
+	fp.mul(&t2, &w.b3, &t2)
+	fp.mul(&x3, &t2, &z3)
+	fp.add(&y3, &t0, &t2)
+	fp.mul(&z3, &t1, &z3)
+	fp.add(&t1, &t2, &t2)
+	fp.add(&t2, &t1, &t2)
+	fp.sub(&t0, &t0, &t2)
+	fp.mul(&y3, &t0, &y3)
+	fp.add(&y3, &x3, &y3)
+	fp.mul(&t1, &p.x, &p.y)
+	fp.mul(&x3, &t0, &t1)
+	fp.add(&x3, &x3, &x3)
+
+	r.x, r.y, r.z = x3, y3, z3
+}
+
+// selectPoint sets r = p if cond is 1, or r = q if cond is 0.
+func selectPoint(r, p, q *projectivePoint, cond uint64) {
+	selectElement(&r.x, &p.x, &q.x, cond)
+	selectElement(&r.y, &p.y, &q.y, cond)
+	selectElement(&r.z, &p.z, &q.z, cond)
+}
+
+// toAffine converts p to big.Int affine coordinates. (0, 0) is returned for the point at infinity,
+// following the convention of crypto/elliptic.
+func (w *weierstrass) toAffine(p *projectivePoint) (x, y *big.Int) {
+	var zinv, ax, ay fieldElement
+	w.fp.inv(&zinv, &p.z)
+	w.fp.mul(&ax, &p.x, &zinv)
+	w.fp.mul(&ay, &p.y, &zinv)
+	return w.fp.toBig(&ax), w.fp.toBig(&ay)
+}
+
+// table returns the precomputed multiples of the base point, building it at the first call.
+func (w *weierstrass) table() [][32]affinePoint {
+	w.baseOnce.Do(func() {
+		w.baseTable = w.fixedTable(&w.g, baseWindows)
+	})
+	return w.baseTable
+}
+
+// fixedTable returns the table of multiples of p used by fixedMult, where table[i][j] = (j+1) * 2^(6i) * p.
+// It works for scalars up to 6*windows - 1 bits. p must not be the point at infinity.
+func (w *weierstrass) fixedTable(p *affinePoint, windows int) [][32]affinePoint {
+	var (
+		table  = make([][32]affinePoint, windows)
+		points = make([]projectivePoint, 0, windows*32)
+		base   projectivePoint
+		acc    projectivePoint
+	)
+	w.fromAffine(&base, p)
+	for i := 0; i < windows; i++ {
+		// base = 2^(6i) * p
+		acc = base
+		for j := 0; j < 32; j++ {
+			points = append(points, acc)
+			w.add(&acc, &acc, &base)
+		}
+		for j := 0; j < 6; j++ {
+			w.double(&base, &base)
+		}
+	}
+	w.batchToAffine(points)
+	for i := range table {
+		for j := range table[i] {
+			pt := &points[i*32+j]
+			table[i][j] = affinePoint{pt.x, pt.y}
+		}
+	}
+	return table
+}
+
+// batchToAffine normalizes the points in place so that Z = 1, with a single inversion.
+// None of the points may be the point at infinity.
+func (w *weierstrass) batchToAffine(points []projectivePoint) {
+	fp := w.fp
+	// prods[i] = z0 * z1 * ... * zi
+	prods := make([]fieldElement, len(points))
+	acc := fp.one
+	for i := range points {
+		fp.mul(&acc, &acc, &points[i].z)
+		prods[i] = acc
+	}
+	var inv fieldElement
+	fp.inv(&inv, &acc)
+	for i := len(points) - 1; i >= 0; i-- {
+		var zinv fieldElement
+		if i > 0 {
+			fp.mul(&zinv, &inv, &prods[i-1])
+		} else {
+			zinv = inv
+		}
+		fp.mul(&inv, &inv, &points[i].z)
+
+		fp.mul(&points[i].x, &points[i].x, &zinv)
+		fp.mul(&points[i].y, &points[i].y, &zinv)
+		points[i].z = fp.one
+	}
+}
+
+// scalarBaseMult sets r = k * G for a big-endian scalar k of at most 32 octets,
+// using the precomputed table.
+func (w *weierstrass) scalarBaseMult(r *projectivePoint, k []byte) {
+	w.fixedMult(r, w.table(), k)
+}
+
+// fixedMult sets r = k * p for a big-endian scalar k of at most 32 octets, using the table of p.
+// The scalar is recoded into signed 6-bit digits, and table lookups are done in constant time.
+// If k is too long for the table, the result is undefined.
+func (w *weierstrass) fixedMult(r *projectivePoint, table [][32]affinePoint, k []byte) {
+	var (
+		kbuf  [32]byte
+		acc   projectivePoint
+		sum   projectivePoint
+		entry affinePoint
+		negY  fieldElement
+		carry uint64
+	)
+	copy(kbuf[32-len(k):], k)
+	w.identity(&acc)
+
+	for i := range table {
+		// d is the i-th signed digit in [-31, 32]
+		v := scalarWindow(&kbuf, uint(i*6), 6) + carry
+		carry = (v + 31) >> 6
+		d := int64(v) - int64(carry<<6)
+
+		neg := uint64(d) >> 63
+		abs := uint64((d ^ -int64(neg)) + int64(neg))
+
+		// entry = table[i][abs-1], or table[i][0] as dummy if abs is 0
+		entry = table[i][0]
+		for j := uint64(2); j <= 32; j++ {
+			eq := 1 ^ (((abs ^ j) | -(abs ^ j)) >> 63)
+			selectElement(&entry.x, &table[i][j-1].x, &entry.x, eq)
+			selectElement(&entry.y, &table[i][j-1].y, &entry.y, eq)
+		}
+		w.fp.neg(&negY, &entry.y)
+		selectElement(&entry.y, &negY, &entry.y, neg)
+
+		w.addAffine(&sum, &acc, &entry)
+		selectPoint(&acc, &sum, &acc, (abs|-abs)>>63)
+	}
+	*r = acc
+}
+
+// scalarWindow returns n (<= 8) bits of the 32-octet big-endian scalar, starting from the bit at offset.
+func scalarWindow(k *[32]byte, offset, n uint) uint64 {
+	var v uint64
+	for i := uint(0); i < 2; i++ {
+		if j := offset/8 + i; j < 32 {
+			v |= uint64(k[31-j]) << (8 * i)
+		}
+	}
+	return (v >> (offset % 8)) & (1<<n - 1)
+}
+
+// fromBigAffine sets p to the affine point (x, y) given as big.Int.
+func (w *weierstrass) fromBigAffine(p *projectivePoint, x, y *big.Int) {
+	w.fp.fromBig(&p.x, x)
+	w.fp.fromBig(&p.y, y)
+	p.z = w.fp.one
+}
+
+// scalarMult sets r = k * p for a big-endian scalar k, using 4-bit fixed windows.
+// Table lookups are done in constant time, and the running time depends only on the length of k.
+func (w *weierstrass) scalarMult(r, p *projectivePoint, k []byte) {
+	// table[i] = i * p
+	var table [16]projectivePoint
+	w.identity(&table[0])
+	table[1] = *p
+	for i := 2; i < 16; i += 2 {
+		w.double(&table[i], &table[i/2])
+		w.add(&table[i+1], &table[i], p)
+	}
+
+	var acc, entry projectivePoint
+	w.identity(&acc)
+	for _, b := range k {
+		for _, d := range [2]uint64{uint64(b >> 4), uint64(b & 0xf)} {
+			w.double(&acc, &acc)
+			w.double(&acc, &acc)
+			w.double(&acc, &acc)
+			w.double(&acc, &acc)
+
+			entry = table[0]
+			for j := uint64(1); j < 16; j++ {
+				eq := 1 ^ (((d ^ j) | -(d ^ j)) >> 63)
+				selectPoint(&entry, &table[j], &entry, eq)
+			}
+			w.add(&acc, &acc, &entry)
+		}
+	}
+	*r = acc
+}
+
+// decompress sets p to the point with the given x coordinate and the parity of y.
+// x is a big-endian integer of at most 32 octets.
+// false is returned if x is not less than p, or if there's no such point.
+// Only fields of p = 3 mod 4 are supported, see canDecompress.
+func (w *weierstrass) decompress(p *projectivePoint, x []byte, odd uint) bool {
+	var (
+		fp            = w.fp
+		buf           [32]byte
+		fx, y2, y, yy fieldElement
+	)
+	if len(x) > len(buf) {
+		return false
+	}
+	copy(buf[len(buf)-len(x):], x)
+	fx = limbsFromBytes(&buf)
+	if !lessThan(&fx, &fp.p) {
+		return false
+	}
+	fp.fromLimbs(&fx, &fx)
+	w.rhs(&y2, &fx)
+
+	// y = (y²)^((p+1)/4)
+	fp.exp(&y, &y2, w.sqrtExp)
+	fp.sqr(&yy, &y)
+	if fp.equal(&yy, &y2) != 1 {
+		return false
+	}
+
+	fp.bytes(&buf, &y)
+	if uint(buf[31]&1) != odd {
+		fp.neg(&y, &y)
+	}
+	p.x, p.y, p.z = fx, y, fp.one
+	return true
+}
+
+// rhs sets z = x³ + ax + b, the right-hand side of the curve equation.
+func (w *weierstrass) rhs(z, x *fieldElement) {
+	var ax fieldElement
+	w.fp.sqr(z, x)
+	w.fp.mul(z, z, x)
+	w.mulA(&ax, x)
+	w.fp.add(z, z, &ax)
+	w.fp.add(z, z, &w.b)
+}
+
+// isOnCurve returns whether (x, y) is a point on the curve with coordinates in [0, p).
+func (w *weierstrass) isOnCurve(x, y *big.Int) bool {
+	p := w.fp.modulus
+	if x.Sign() < 0 || x.Cmp(p) >= 0 || y.Sign() < 0 || y.Cmp(p) >= 0 {
+		return false
+	}
+	var fx, fy, y2, yy fieldElement
+	w.fp.fromBig(&fx, x)
+	w.fp.fromBig(&fy, y)
+	w.rhs(&y2, &fx)
+	w.fp.sqr(&yy, &fy)
+	return w.fp.equal(&yy, &y2) == 1
+}
+
+// encode writes the compressed form of p to out, which is of 1 + byteLen octets.
+// The point at infinity is encoded as (0, 0), following toAffine.
+func (w *weierstrass) encode(out []byte, p *projectivePoint) {
+	var (
+		fp             = w.fp
+		zinv, ax, ay   fieldElement
+		xbytes, ybytes [32]byte
+	)
+	fp.inv(&zinv, &p.z)
+	fp.mul(&ax, &p.x, &zinv)
+	fp.mul(&ay, &p.y, &zinv)
+	fp.bytes(&xbytes, &ax)
+	fp.bytes(&ybytes, &ay)
+
+	// compress format, 3 for odd y
+	out[0] = 2 + ybytes[31]&1
+	copy(out[1:], xbytes[len(xbytes)-fp.byteLen:])
+}
+
+// canDecompress returns whether decompress is supported.
+func (w *weierstrass) canDecompress() bool {
+	return w.sqrtExp != nil
+}
+
+// neg sets r = -p.
+func (w *weierstrass) neg(r, p *projectivePoint) {
+	r.x = p.x
+	w.fp.neg(&r.y, &p.y)
+	r.z = p.z
+}
+
+// wnafWidth is the window width of wNAF recoding used by variable-time multiplications.
+const wnafWidth = 5
+
+// wnafMaxLen is the maximum number of wNAF digits of 256-bit scalars.
+const wnafMaxLen = 257
+
+// wnaf writes the width-5 non-adjacent form of the plain integer k to out, least significant digit first,
+// and returns the number of digits.
+func wnaf(out *[wnafMaxLen]int8, k *fieldElement) int {
+	var (
+		v = [5]uint64{k[0], k[1], k[2], k[3]}
+		n int
+	)
+	for v[0]|v[1]|v[2]|v[3]|v[4] != 0 {
+		var digit int64
+		if v[0]&1 == 1 {
+			digit = int64(v[0] & (1<<wnafWidth - 1))
+			if digit >= 1<<(wnafWidth-1) {
+				digit -= 1 << wnafWidth
+			}
+			// v -= digit, which only clears the low bits if digit is positive
+			if digit > 0 {
+				v[0] -= uint64(digit)
+			} else {
+				var carry uint64
+				v[0], carry = bits.Add64(v[0], uint64(-digit), 0)
+				for i := 1; i < len(v); i++ {
+					v[i], carry = bits.Add64(v[i], 0, carry)
+				}
+			}
+		}
+		out[n] = int8(digit)
+		n++
+		for i := 0; i < len(v)-1; i++ {
+			v[i] = v[i]>>1 | v[i+1]<<63
+		}
+		v[len(v)-1] >>= 1
+	}
+	return n
+}
+
+// vartimeTerm is a term of variable-time multi-scalar multiplications, which is k * p,
+// or -k * p if neg is set. k is a plain integer less than 2^256.
+type vartimeTerm struct {
+	p   projectivePoint
+	k   fieldElement
+	neg bool
+}
+
+// straussTerms is the maximum number of terms handled at once by strauss.
+const straussTerms = 4
+
+// multiScalarMultVartime sets r to the sum of terms, splitting scalars by the endomorphism if available.
+// It runs in variable time, so must only be used with public inputs.
+func (w *weierstrass) multiScalarMultVartime(r *projectivePoint, terms []vartimeTerm) {
+	var (
+		acc, part projectivePoint
+		batch     [straussTerms]vartimeTerm
+		n         int
+		width     = 1 // number of batch entries per term
+		started   bool
+	)
+	if w.glv != nil {
+		width = 2
+	}
+	w.identity(&acc)
+	for i := range terms {
+		t := &terms[i]
+		if w.glv != nil {
+			k1, k2, neg1, neg2 := w.glv.split(&t.k)
+			batch[n] = vartimeTerm{t.p, k1, t.neg != neg1}
+			batch[n+1].k, batch[n+1].neg = k2, t.neg != neg2
+			w.glv.endomorphism(w.fp, &batch[n+1].p, &t.p)
+			n += 2
+		} else {
+			batch[n] = *t
+			n++
+		}
+		if n+width <= straussTerms && i+1 < len(terms) {
+			continue
+		}
+		w.strauss(&part, batch[:n])
+		if started {
+			w.add(&acc, &acc, &part)
+		} else {
+			acc, started = part, true
+		}
+		n = 0
+	}
+	*r = acc
+}
+
+// strauss sets r to the sum of at most straussTerms terms, using Strauss' method over wNAF.
+func (w *weierstrass) strauss(r *projectivePoint, terms []vartimeTerm) {
+	var (
+		tables [straussTerms][1 << (wnafWidth - 2)]projectivePoint
+		digits [straussTerms][wnafMaxLen]int8
+		lens   [straussTerms]int
+		maxLen int
+	)
+	for i := range terms {
+		// tables[i][j] = (2j+1) * p
+		var p, p2 projectivePoint
+		p = terms[i].p
+		if terms[i].neg {
+			w.neg(&p, &p)
+		}
+		tables[i][0] = p
+		w.double(&p2, &p)
+		for j := 1; j < len(tables[i]); j++ {
+			w.add(&tables[i][j], &tables[i][j-1], &p2)
+		}
+		lens[i] = wnaf(&digits[i], &terms[i].k)
+		if lens[i] > maxLen {
+			maxLen = lens[i]
+		}
+	}
+
+	var acc, neg projectivePoint
+	w.identity(&acc)
+	for bit := maxLen - 1; bit >= 0; bit-- {
+		w.double(&acc, &acc)
+		for i := range terms {
+			if bit >= lens[i] {
+				continue
+			}
+			switch d := digits[i][bit]; {
+			case d > 0:
+				w.add(&acc, &acc, &tables[i][d/2])
+			case d < 0:
+				w.neg(&neg, &tables[i][(-d)/2])
+				w.add(&acc, &acc, &neg)
+			}
+		}
+	}
+	*r = acc
+}
+
+// scalarMultVartime sets r = k * p for a plain integer k less than 2^256.
+// It runs in variable time, so must only be used with public inputs.
+func (w *weierstrass) scalarMultVartime(r, p *projectivePoint, k *fieldElement) {
+	w.multiScalarMultVartime(r, []vartimeTerm{{p: *p, k: *k}})
+}
+
+// lessThan returns whether the plain integer x is less than y. It runs in variable time.
+func lessThan(x, y *fieldElement) bool {
+	for i := len(x) - 1; i >= 0; i-- {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// secp256k1 is the secp256k1 curve, the params are only used for testing the arithmetic of a = 0.
+var secp256k1 = func() *elliptic.CurveParams {
+	p := &elliptic.CurveParams{Name: "secp256k1", BitSize: 256, P: secp256k1P, B: big.NewInt(7)}
+	p.N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	p.Gx, _ = new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+	p.Gy, _ = new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+	return p
+}()
+
+// refScalarMult is the reference double-and-add over affine coordinates of y² = x³ + ax + b.
+func refScalarMult(p, a, x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	var rx, ry *big.Int // nil for the point at infinity
+	add := func(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+		if x1 == nil {
+			return x2, y2
+		}
+		if x2 == nil {
+			return x1, y1
+		}
+		var l *big.Int
+		if x1.Cmp(x2) == 0 {
+			if new(big.Int).Add(y1, y2).Mod(new(big.Int).Add(y1, y2), p).Sign() == 0 {
+				return nil, nil
+			}
+			// l = (3x² + a) / 2y
+			l = new(big.Int).Mul(x1, x1)
+			l.Mul(l, big.NewInt(3)).Add(l, a)
+			l.Mul(l, new(big.Int).ModInverse(new(big.Int).Lsh(y1, 1), p))
+		} else {
+			// l = (y2 - y1) / (x2 - x1)
+			l = new(big.Int).Sub(y2, y1)
+			l.Mul(l, new(big.Int).ModInverse(new(big.Int).Mod(new(big.Int).Sub(x2, x1), p), p))
+		}
+		l.Mod(l, p)
+		x3 := new(big.Int).Mul(l, l)
+		x3.Sub(x3, x1).Sub(x3, x2).Mod(x3, p)
+		y3 := new(big.Int).Sub(x1, x3)
+		y3.Mul(y3, l).Sub(y3, y1).Mod(y3, p)
+		return x3, y3
+	}
+	for _, b := range k {
+		for i := 7; i >= 0; i-- {
+			rx, ry = add(rx, ry, rx, ry)
+			if (b>>uint(i))&1 == 1 {
+				rx, ry = add(rx, ry, x, y)
+			}
+		}
+	}
+	if rx == nil {
+		return new(big.Int), new(big.Int)
+	}
+	return rx, ry
+}
+
+var weierstrassTests = func() []struct {
+	name   string
+	params *elliptic.CurveParams
+	a      *big.Int
+} {
+	p256 := *elliptic.P256().Params()
+	return []struct {
+		name   string
+		params *elliptic.CurveParams
+		a      *big.Int
+	}{
+		{"a=-3", &p256, big.NewInt(-3)},
+		{"a=0", secp256k1, big.NewInt(0)},
+	}
+}()
+
+func newTestWeierstrass(t *testing.T, params *elliptic.CurveParams, a *big.Int) *weierstrass {
+	w := newWeierstrass(params, func(c elliptic.Curve, x *big.Int) *big.Int {
+		// y² = x³ + ax + b
+		x3 := new(big.Int).Mul(x, x)
+		x3.Mul(x3, x)
+		x3.Add(x3, new(big.Int).Mul(a, x))
+		x3.Add(x3, c.Params().B)
+		return x3.Mod(x3, c.Params().P)
+	})
+	if w == nil {
+		t.Fatal("curve not supported")
+	}
+	return w
+}
+
+func TestWeierstrassScalarBaseMult(t *testing.T) {
+	for _, tt := range weierstrassTests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.a
+			w := newTestWeierstrass(t, tt.params, a)
+
+			var (
+				n  = tt.params.N
+				nm = new(big.Int).Sub(n, big.NewInt(1))
+			)
+			scalars := [][]byte{{}, {0}, {1}, {2}, n.Bytes(), nm.Bytes(), new(big.Int).Add(n, big.NewInt(1)).Bytes()}
+			for i := 0; i < 20; i++ {
+				k := make([]byte, 32)
+				rand.Read(k)
+				scalars = append(scalars, k)
+			}
+			for _, k := range scalars {
+				var r projectivePoint
+				w.scalarBaseMult(&r, k)
+				x, y := w.toAffine(&r)
+
+				wantX, wantY := refScalarMult(tt.params.P, a, tt.params.Gx, tt.params.Gy, k)
+				if x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+					t.Errorf("scalarBaseMult(%x) = (%v, %v), want (%v, %v)", k, x, y, wantX, wantY)
+				}
+			}
+		})
+	}
+}
+
+func TestWeierstrassScalarMult(t *testing.T) {
+	for _, tt := range weierstrassTests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWeierstrass(t, tt.params, tt.a)
+			for i := 0; i < 10; i++ {
+				r := make([]byte, 32)
+				rand.Read(r)
+				px, py := refScalarMult(tt.params.P, tt.a, tt.params.Gx, tt.params.Gy, r)
+
+				k := make([]byte, 32)
+				rand.Read(k)
+				if i == 0 {
+					k = tt.params.N.Bytes()
+				}
+				wantX, wantY := refScalarMult(tt.params.P, tt.a, px, py, k)
+
+				var p projectivePoint
+				w.fromBigAffine(&p, px, py)
+				w.scalarMult(&p, &p, k)
+				if x, y := w.toAffine(&p); x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+					t.Errorf("scalarMult(%x) = (%v, %v), want (%v, %v)", k, x, y, wantX, wantY)
+				}
+			}
+		})
+	}
+}
+
+func TestWeierstrassAdd(t *testing.T) {
+	for _, tt := range weierstrassTests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWeierstrass(t, tt.params, tt.a)
+
+			k := make([]byte, 32)
+			rand.Read(k)
+			px, py := refScalarMult(tt.params.P, tt.a, tt.params.Gx, tt.params.Gy, k)
+
+			var p, neg, r projectivePoint
+			w.fromBigAffine(&p, px, py)
+			w.fromBigAffine(&neg, px, new(big.Int).Sub(tt.params.P, py))
+
+			// p + p = 2p
+			w.add(&r, &p, &p)
+			wantX, wantY := refScalarMult(tt.params.P, tt.a, px, py, []byte{2})
+			if x, y := w.toAffine(&r); x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+				t.Errorf("add(p, p) = (%v, %v), want (%v, %v)", x, y, wantX, wantY)
+			}
+			w.double(&r, &p)
+			if x, y := w.toAffine(&r); x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+				t.Errorf("double(p) = (%v, %v), want (%v, %v)", x, y, wantX, wantY)
+			}
+
+			// p + (-p) = O
+			w.add(&r, &p, &neg)
+			if w.fp.isZero(&r.z) != 1 {
+				t.Errorf("add(p, -p) is not the point at infinity")
+			}
+
+			// O + p = p
+			w.identity(&r)
+			w.addAffine(&r, &r, &affinePoint{p.x, p.y})
+			if x, y := w.toAffine(&r); x.Cmp(px) != 0 || y.Cmp(py) != 0 {
+				t.Errorf("addAffine(O, p) = (%v, %v), want (%v, %v)", x, y, px, py)
+			}
+		})
+	}
+}
+
+func TestWeierstrassDecompress(t *testing.T) {
+	for _, tt := range weierstrassTests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWeierstrass(t, tt.params, tt.a)
+			for i := 0; i < 10; i++ {
+				k := make([]byte, 32)
+				rand.Read(k)
+				px, py := refScalarMult(tt.params.P, tt.a, tt.params.Gx, tt.params.Gy, k)
+
+				var p projectivePoint
+				if !w.decompress(&p, px.Bytes(), py.Bit(0)) {
+					t.Fatalf("decompress() failed")
+				}
+				if x, y := w.toAffine(&p); x.Cmp(px) != 0 || y.Cmp(py) != 0 {
+					t.Errorf("decompress() = (%v, %v), want (%v, %v)", x, y, px, py)
+				}
+			}
+		})
+	}
+}
+
+func TestCurveArithEquation(t *testing.T) {
+	v := NewSecp256k1Sha256Tai().(*vrf)
+	if curveArith(secp256k1, v.cfg.Y2) == nil {
+		t.Fatal("curve not supported")
+	}
+	// the arithmetic of y² = x³ + 7 is not used for y² = x³ + x + 7, even if G were on it
+	other := func(c elliptic.Curve, x *big.Int) *big.Int {
+		y2 := new(big.Int).Add(v.cfg.Y2(c, x), x)
+		return y2.Mod(y2, c.Params().P)
+	}
+	if curveArith(secp256k1, other) != nil {
+		t.Fatal("arithmetic of another equation")
+	}
+	if curveArith(secp256k1, v.cfg.Y2) != v.arith(secp256k1) {
+		t.Fatal("arithmetic not shared")
+	}
+}
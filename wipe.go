@@ -0,0 +1,32 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+package ecvrf
+
+import "hash"
+
+// wipe zeroes the buffer of secret values. Keys are wiped by PrivateKey.Wipe.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// zeroBlock is the input of wipeHasher, long enough for the block sizes of standard hashes.
+var zeroBlock [256]byte
+
+// wipeHasher resets h and overwrites its buffer of pending input, which may keep secret data
+// after Reset. It relies on block hashes buffering partial blocks like those of the standard library.
+func wipeHasher(h hash.Hash) {
+	zeros := zeroBlock[:]
+	if n := h.BlockSize(); n <= len(zeros) {
+		zeros = zeros[:n]
+	} else {
+		zeros = make([]byte, n)
+	}
+	h.Reset()
+	// a partial block is copied into the buffer, and the last octet completes it
+	h.Write(zeros[1:])
+	h.Write(zeros[:1])
+	h.Reset()
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2020 vechain.org.
+// Licensed under the MIT license.
+
+//go:build go1.24
+// +build go1.24
+
+package ecvrf
+
+import "crypto/sha3"
+
+// NewShake256 creates the SHAKE256 extendable-output function.
+func NewShake256() XOF {
+	return sha3.NewSHAKE256()
+}
+
+// WithShake256Beta makes proof_to_hash output `size` octets of beta using SHAKE256.
+func WithShake256Beta(size int) Option {
+	return func(cfg *Config) {
+		cfg.NewXOF = NewShake256
+		cfg.BetaSize = size
+	}
+}